@@ -13,20 +13,28 @@ import (
 	"github.com/saransh1220/blueprint-audio/internal/gateway"
 	gatewayMiddleware "github.com/saransh1220/blueprint-audio/internal/gateway/middleware"
 	"github.com/saransh1220/blueprint-audio/internal/gateway/openapi"
+	"github.com/saransh1220/blueprint-audio/internal/jobs"
 	"github.com/saransh1220/blueprint-audio/internal/modules/admin"
 	"github.com/saransh1220/blueprint-audio/internal/modules/analytics"
 	"github.com/saransh1220/blueprint-audio/internal/modules/auth"
 	"github.com/saransh1220/blueprint-audio/internal/modules/catalog"
 	catalogApplication "github.com/saransh1220/blueprint-audio/internal/modules/catalog/application"
 	catalogPersistence "github.com/saransh1220/blueprint-audio/internal/modules/catalog/infrastructure/persistence/postgres"
+	"github.com/saransh1220/blueprint-audio/internal/modules/currency"
+	currencyApplication "github.com/saransh1220/blueprint-audio/internal/modules/currency/application"
+	"github.com/saransh1220/blueprint-audio/internal/modules/earnings"
+	earningsApplication "github.com/saransh1220/blueprint-audio/internal/modules/earnings/application"
 	"github.com/saransh1220/blueprint-audio/internal/modules/filestorage"
 	"github.com/saransh1220/blueprint-audio/internal/modules/notification"
+	"github.com/saransh1220/blueprint-audio/internal/modules/notification/infrastructure/webpush"
 	"github.com/saransh1220/blueprint-audio/internal/modules/payment"
 	paymentApplication "github.com/saransh1220/blueprint-audio/internal/modules/payment/application"
+	paymentPersistence "github.com/saransh1220/blueprint-audio/internal/modules/payment/infrastructure/persistence/postgres"
 	"github.com/saransh1220/blueprint-audio/internal/modules/user"
 	"github.com/saransh1220/blueprint-audio/internal/shared/infrastructure/config"
 	"github.com/saransh1220/blueprint-audio/internal/shared/infrastructure/database"
 	sharedemail "github.com/saransh1220/blueprint-audio/internal/shared/infrastructure/email"
+	"github.com/saransh1220/blueprint-audio/internal/shared/infrastructure/scheduler"
 	"github.com/saransh1220/blueprint-audio/pkg/migration"
 )
 
@@ -58,14 +66,14 @@ func main() {
 			DB:       cfg.Redis.DB,
 		})
 		if err := redisClient.Ping(ctx).Err(); err != nil {
-			log.Printf("Warning: Failed to connect to Redis; cache disabled: %v", err)
+			log.Printf("Warning: Failed to connect to Redis; cache and cross-instance realtime disabled: %v", err)
 			redisClient.Close()
 			redisClient = nil
 		} else {
 			defer redisClient.Close()
 		}
 	} else {
-		log.Printf("Redis disabled; running without cache")
+		log.Printf("Redis disabled; running without cache or cross-instance realtime")
 	}
 
 	// 4. Initialize Modules
@@ -87,7 +95,7 @@ func main() {
 	}
 
 	// Auth Module
-	authModule, err := auth.NewModule(db, cfg.JWT.Secret, cfg.JWT.Expiry, cfg.JWT.RefreshExpiry, fsModule.Service(), cfg.Google.ClientID, cfg.Server.SecureCookies, emailSender, cfg.AppBaseURL)
+	authModule, err := auth.NewModule(db, redisClient, cfg.JWT.Secret, cfg.JWT.Expiry, cfg.JWT.RefreshExpiry, cfg.JWT.SessionCacheTTL, fsModule.Service(), cfg.Google.ClientID, cfg.Server.SecureCookies, emailSender, cfg.AppBaseURL)
 	if err != nil {
 		log.Fatalf("Failed to initialize auth module: %v", err)
 	}
@@ -100,10 +108,10 @@ func main() {
 
 	// User Module
 	userModule := user.NewModule(authModule.UserRepository(), fsModule.Service())
-	adminModule := admin.NewModule(db, authModule.UserRepository())
+	adminModule := admin.NewModule(db, authModule.UserRepository(), authModule.Service())
 
 	// Notification Module
-	notificationModule := notification.NewModule(db)
+	notificationModule := notification.NewModule(db, redisClient, authModule.UserFinder(), emailSender, cfg.AppBaseURL, webPushConfig(cfg))
 
 	// Catalog Module Prerequisites
 	// We need to instantiate the SpecRepository explicitly to share it between Catalog and Analytics
@@ -115,11 +123,22 @@ func main() {
 	// Catalog Module
 	catalogModule := catalog.NewModule(db, specRepo, fsModule.Service(), analyticsModule.AnalyticsService, notificationModule.Service(), redisClient)
 
+	// Earnings Module (payment posts sales and refunds to its ledger)
+	earningsModule := earnings.NewModule(db, authModule.UserFinder(), notificationModule.Service(), earningsApplication.Config{
+		PlatformFeeBPS: cfg.Earnings.PlatformFeeBPS,
+		ClearanceDays:  cfg.Earnings.ClearanceDays,
+	})
+
+	// Currency Module (exchange rates payment prices orders with)
+	currencyModule := currency.NewModule(db, cfg.Currency.RatesFile)
+
 	// Payment Module
-	paymentModule := payment.NewModule(db, catalogModule.SpecFinder(), authModule.UserFinder(), fsModule.Service(), emailSender, cfg.AppBaseURL, paymentAppDodoConfig(cfg))
+	paymentModule := payment.NewModule(db, catalogModule.SpecFinder(), authModule.UserFinder(), fsModule.Service(), notificationModule.Service(), earningsModule.Service(), emailSender, cfg.AppBaseURL, cfg.APIBaseURL, paymentAppDodoConfig(cfg))
 
 	// 5. Middleware
 	authMiddleware := gatewayMiddleware.NewAuthMiddleware(cfg.JWT.Secret)
+	authMiddleware.SetSessionChecker(authModule.Service())
+	authMiddleware.SetMFAPolicy(authModule.Service())
 
 	// Favorites strict server (OpenAPI-generated contract implementation)
 	favoritesServer := openapi.NewFavoritesServer(analyticsModule.AnalyticsService)
@@ -130,11 +149,14 @@ func main() {
 		AuthMiddleware:      authMiddleware,
 		SpecHandler:         catalogModule.HTTPHandler(),
 		SpecUploadHandler:   catalogModule.UploadHTTPHandler(),
+		RankingHandler:      catalogModule.RankingProfileHTTPHandler(),
 		UserHandler:         userModule.HTTPHandler(),
 		PaymentHandler:      paymentModule.HTTPHandler(),
 		AnalyticsHandler:    analyticsModule.AnalyticsHandler,
 		NotificationHandler: notificationModule.HTTPHandler(),
 		AdminHandler:        adminModule.HTTPHandler(),
+		EarningsHandler:     earningsModule.HTTPHandler(),
+		CurrencyHandler:     currencyModule.HTTPHandler(),
 		FavoritesServer:     favoritesServer,
 		DisableAPIDocs:      !cfg.Server.APIDocsEnabled,
 	})
@@ -152,7 +174,28 @@ func main() {
 		uploadRepo := catalogPersistence.NewSpecUploadRepository(db)
 		processor := catalogApplication.NewSpecUploadProcessor(uploadRepo, fsModule.Service(), notificationModule.Service())
 		go catalogApplication.StartUploadWorker(workerCtx, processor, cfg.Worker)
+
+		// Scheduled jobs elect a single runner through Postgres, so they can
+		// run in every instance as well as in the standalone worker.
+		if cfg.Worker.SchedulerEnabled {
+			jobScheduler := scheduler.New(scheduler.NewPgStore(db), catalogApplication.WorkerName(cfg.Worker))
+			if err := jobs.Register(jobScheduler, jobs.Dependencies{
+				Rankings:        catalogModule.RankingProfileService(),
+				Recommendations: catalogApplication.NewRecommender(catalogModule.Repository()),
+				Uploads:         processor,
+				Orders:          paymentApplication.NewOrderExpirer(paymentPersistence.NewOrderRepository(db)),
+				Notifications:   notificationModule.Service(),
+				DigestInterval:  cfg.Notification.DigestInterval,
+			}); err != nil {
+				log.Fatalf("Failed to register scheduled jobs: %v", err)
+			}
+			go jobScheduler.Start(workerCtx)
+		}
 	}
+	// Ledger postings are idempotent, so every API instance can reconcile.
+	go earningsApplication.StartLedgerReconciler(workerCtx, earningsModule.Service(), cfg.Earnings.ReconcileInterval)
+	// Unchanged rates are not stored again, so every instance can refresh.
+	go currencyApplication.StartRateRefresher(workerCtx, currencyModule.Service(), cfg.Currency.RefreshInterval)
 
 	// 9. Start Server
 	srv := gateway.NewServer(cfg.Server.Port, handler)
@@ -184,3 +227,11 @@ func postgresURL(cfg database.PostgresConfig) string {
 
 	return u.String()
 }
+
+func webPushConfig(cfg config.Config) webpush.Config {
+	return webpush.Config{
+		PublicKey:  cfg.Notification.VAPIDPublicKey,
+		PrivateKey: cfg.Notification.VAPIDPrivateKey,
+		Subject:    cfg.Notification.VAPIDSubject,
+	}
+}
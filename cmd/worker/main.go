@@ -3,46 +3,31 @@ package main
 import (
 	"context"
 	"log"
+	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
-	"time"
 
-	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/saransh1220/blueprint-audio/internal/jobs"
+	authPersistence "github.com/saransh1220/blueprint-audio/internal/modules/auth/infrastructure/persistence/postgres"
 	"github.com/saransh1220/blueprint-audio/internal/modules/catalog/application"
 	catalogPersistence "github.com/saransh1220/blueprint-audio/internal/modules/catalog/infrastructure/persistence/postgres"
 	"github.com/saransh1220/blueprint-audio/internal/modules/filestorage"
-	notificationDomain "github.com/saransh1220/blueprint-audio/internal/modules/notification/domain"
+	"github.com/saransh1220/blueprint-audio/internal/modules/notification"
+	notificationApplication "github.com/saransh1220/blueprint-audio/internal/modules/notification/application"
 	notificationPersistence "github.com/saransh1220/blueprint-audio/internal/modules/notification/infrastructure/persistence/postgres"
+	"github.com/saransh1220/blueprint-audio/internal/modules/notification/infrastructure/webpush"
+	"github.com/saransh1220/blueprint-audio/internal/modules/notification/infrastructure/websocket"
+	paymentApplication "github.com/saransh1220/blueprint-audio/internal/modules/payment/application"
+	paymentPersistence "github.com/saransh1220/blueprint-audio/internal/modules/payment/infrastructure/persistence/postgres"
 	"github.com/saransh1220/blueprint-audio/internal/shared/infrastructure/config"
 	"github.com/saransh1220/blueprint-audio/internal/shared/infrastructure/database"
+	sharedemail "github.com/saransh1220/blueprint-audio/internal/shared/infrastructure/email"
+	"github.com/saransh1220/blueprint-audio/internal/shared/infrastructure/scheduler"
 )
 
-type databaseNotifier struct {
-	repository notificationDomain.NotificationRepository
-}
-
-func (n *databaseNotifier) Create(
-	ctx context.Context,
-	userID uuid.UUID,
-	title, message string,
-	notificationType notificationDomain.NotificationType,
-) error {
-	id, err := uuid.NewV7()
-	if err != nil {
-		return err
-	}
-	return n.repository.Create(ctx, &notificationDomain.Notification{
-		ID:        id,
-		UserID:    userID,
-		Title:     title,
-		Message:   message,
-		Type:      notificationType,
-		IsRead:    false,
-		CreatedAt: time.Now().UTC(),
-	})
-}
-
 func main() {
 	cfg := config.Load()
 	db, err := database.NewPostgresDB(cfg.Database)
@@ -59,10 +44,71 @@ func main() {
 		log.Fatalf("initialize file storage: %v", err)
 	}
 	uploads := catalogPersistence.NewSpecUploadRepository(db)
-	notifier := &databaseNotifier{
-		repository: notificationPersistence.NewPgNotificationRepository(db),
+
+	// Notifications reach users connected to the API through Redis pub/sub.
+	// Without Redis they are only stored.
+	publisher := websocket.NewPublisher(nil)
+	if cfg.Redis.Enabled {
+		redisClient, err := database.NewRedis(cfg.Redis)
+		if err != nil {
+			log.Printf("Warning: Redis unavailable; notifications will not be pushed in realtime: %v", err)
+		} else {
+			defer redisClient.Close()
+			publisher = websocket.NewPublisher(websocket.NewRedisBroker(redisClient))
+		}
 	}
+	emailSender := sharedemail.NewSender(sharedemail.Config{
+		APIKey:  cfg.Email.ResendAPIKey,
+		From:    cfg.Email.From,
+		ReplyTo: cfg.Email.ReplyTo,
+		Enabled: cfg.Email.Enabled,
+	})
+	notifier := notificationApplication.NewNotificationService(
+		notificationPersistence.NewPgNotificationRepository(db),
+		notificationPersistence.NewPgEventRepository(db),
+		notificationPersistence.NewPgPreferenceRepository(db),
+		publisher,
+		authPersistence.NewUserRepository(db),
+		notificationApplication.EmailConfig{Sender: emailSender, AppBaseURL: cfg.AppBaseURL},
+	)
+	notification.EnablePush(notifier, db, webpush.Config{
+		PublicKey:  cfg.Notification.VAPIDPublicKey,
+		PrivateKey: cfg.Notification.VAPIDPrivateKey,
+		Subject:    cfg.Notification.VAPIDSubject,
+	})
 	processor := application.NewSpecUploadProcessor(uploads, files.Service(), notifier)
 
+	if cfg.Worker.MetricsAddr != "" {
+		metrics := http.NewServeMux()
+		metrics.Handle("/metrics", promhttp.Handler())
+		go func() {
+			if err := http.ListenAndServe(cfg.Worker.MetricsAddr, metrics); err != nil {
+				log.Printf("Warning: worker metrics server stopped: %v", err)
+			}
+		}()
+	}
+
+	var wg sync.WaitGroup
+	if cfg.Worker.SchedulerEnabled {
+		jobScheduler := scheduler.New(scheduler.NewPgStore(db), application.WorkerName(cfg.Worker))
+		specRepo := catalogPersistence.NewSpecRepository(db)
+		if err := jobs.Register(jobScheduler, jobs.Dependencies{
+			Rankings:        application.NewRankingProfileService(specRepo),
+			Recommendations: application.NewRecommender(specRepo),
+			Uploads:         processor,
+			Orders:          paymentApplication.NewOrderExpirer(paymentPersistence.NewOrderRepository(db)),
+			Notifications:   notifier,
+			DigestInterval:  cfg.Notification.DigestInterval,
+		}); err != nil {
+			log.Fatalf("register scheduled jobs: %v", err)
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			jobScheduler.Start(ctx)
+		}()
+	}
+
 	application.StartUploadWorker(ctx, processor, cfg.Worker)
+	wg.Wait()
 }
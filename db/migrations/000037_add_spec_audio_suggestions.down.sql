@@ -0,0 +1,5 @@
+ALTER TABLE specs
+    DROP COLUMN IF EXISTS suggested_key_confidence,
+    DROP COLUMN IF EXISTS suggested_key,
+    DROP COLUMN IF EXISTS suggested_bpm_confidence,
+    DROP COLUMN IF EXISTS suggested_bpm;
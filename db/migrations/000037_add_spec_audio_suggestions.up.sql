@@ -0,0 +1,8 @@
+ALTER TABLE specs
+    ADD COLUMN IF NOT EXISTS suggested_bpm INTEGER
+        CHECK (suggested_bpm IS NULL OR (suggested_bpm >= 60 AND suggested_bpm <= 300)),
+    ADD COLUMN IF NOT EXISTS suggested_bpm_confidence REAL
+        CHECK (suggested_bpm_confidence IS NULL OR (suggested_bpm_confidence >= 0 AND suggested_bpm_confidence <= 1)),
+    ADD COLUMN IF NOT EXISTS suggested_key VARCHAR(20),
+    ADD COLUMN IF NOT EXISTS suggested_key_confidence REAL
+        CHECK (suggested_key_confidence IS NULL OR (suggested_key_confidence >= 0 AND suggested_key_confidence <= 1));
@@ -0,0 +1,7 @@
+DROP TRIGGER IF EXISTS refresh_producer_spec_search_documents ON users;
+DROP TRIGGER IF EXISTS refresh_spec_search_document ON specs;
+DROP FUNCTION IF EXISTS refresh_producer_spec_search_documents();
+DROP FUNCTION IF EXISTS refresh_spec_search_document();
+DROP FUNCTION IF EXISTS spec_search_headline(TEXT, TSQUERY, TEXT);
+DROP FUNCTION IF EXISTS build_spec_search_document(TEXT, TEXT[], TEXT[], TEXT, TEXT);
+DROP TABLE IF EXISTS spec_search_documents;
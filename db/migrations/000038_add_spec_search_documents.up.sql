@@ -0,0 +1,84 @@
+-- Full-text search documents for specs. They live beside specs rather than on
+-- it so the many `SELECT s.*` readers do not need to scan a tsvector column.
+CREATE TABLE IF NOT EXISTS spec_search_documents (
+    spec_id UUID PRIMARY KEY REFERENCES specs(id) ON DELETE CASCADE,
+    document TSVECTOR NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_spec_search_documents_document
+    ON spec_search_documents USING GIN (document);
+
+-- Weights: A = title, B = tags and moods, C = producer name, D = description.
+CREATE OR REPLACE FUNCTION build_spec_search_document(
+    p_title TEXT,
+    p_tags TEXT[],
+    p_moods TEXT[],
+    p_producer_name TEXT,
+    p_description TEXT
+) RETURNS TSVECTOR AS $$
+    SELECT setweight(to_tsvector('english', COALESCE(p_title, '')), 'A')
+        || setweight(to_tsvector('english',
+               COALESCE(array_to_string(p_tags, ' '), '') || ' ' ||
+               COALESCE(array_to_string(p_moods, ' '), '')), 'B')
+        || setweight(to_tsvector('english', COALESCE(p_producer_name, '')), 'C')
+        || setweight(to_tsvector('english', COALESCE(p_description, '')), 'D');
+$$ LANGUAGE sql IMMUTABLE;
+
+-- ts_headline copies the source text verbatim, so escape it first and let the
+-- only markup in the snippet be the <mark> delimiters.
+CREATE OR REPLACE FUNCTION spec_search_headline(p_text TEXT, p_query TSQUERY, p_options TEXT)
+RETURNS TEXT AS $$
+    SELECT ts_headline(
+        'english',
+        replace(replace(replace(COALESCE(p_text, ''), '&', '&amp;'), '<', '&lt;'), '>', '&gt;'),
+        p_query,
+        'StartSel="<mark>", StopSel="</mark>", ' || p_options
+    );
+$$ LANGUAGE sql IMMUTABLE;
+
+CREATE OR REPLACE FUNCTION refresh_spec_search_document()
+RETURNS TRIGGER AS $$
+BEGIN
+    INSERT INTO spec_search_documents (spec_id, document, updated_at)
+    SELECT NEW.id,
+           build_spec_search_document(NEW.title, NEW.tags, NEW.moods, u.display_name, NEW.description),
+           NOW()
+    FROM users u
+    WHERE u.id = NEW.producer_id
+    ON CONFLICT (spec_id) DO UPDATE
+        SET document = EXCLUDED.document,
+            updated_at = EXCLUDED.updated_at;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION refresh_producer_spec_search_documents()
+RETURNS TRIGGER AS $$
+BEGIN
+    UPDATE spec_search_documents d
+    SET document = build_spec_search_document(s.title, s.tags, s.moods, NEW.display_name, s.description),
+        updated_at = NOW()
+    FROM specs s
+    WHERE s.id = d.spec_id
+      AND s.producer_id = NEW.id;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER refresh_spec_search_document
+    AFTER INSERT OR UPDATE OF title, tags, moods, description, producer_id ON specs
+    FOR EACH ROW
+    EXECUTE FUNCTION refresh_spec_search_document();
+
+CREATE TRIGGER refresh_producer_spec_search_documents
+    AFTER UPDATE OF display_name ON users
+    FOR EACH ROW
+    WHEN (OLD.display_name IS DISTINCT FROM NEW.display_name)
+    EXECUTE FUNCTION refresh_producer_spec_search_documents();
+
+INSERT INTO spec_search_documents (spec_id, document)
+SELECT s.id, build_spec_search_document(s.title, s.tags, s.moods, u.display_name, s.description)
+FROM specs s
+JOIN users u ON u.id = s.producer_id
+ON CONFLICT (spec_id) DO NOTHING;
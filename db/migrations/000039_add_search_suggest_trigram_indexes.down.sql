@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_genres_name_trgm;
+DROP INDEX IF EXISTS idx_users_display_name_trgm;
+DROP INDEX IF EXISTS idx_specs_title_trgm;
+-- pg_trgm is left installed; other objects may depend on it.
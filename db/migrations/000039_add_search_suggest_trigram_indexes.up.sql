@@ -0,0 +1,11 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS idx_specs_title_trgm
+    ON specs USING GIN (lower(title) gin_trgm_ops)
+    WHERE is_deleted = FALSE AND processing_status = 'completed';
+
+CREATE INDEX IF NOT EXISTS idx_users_display_name_trgm
+    ON users USING GIN (lower(display_name) gin_trgm_ops);
+
+CREATE INDEX IF NOT EXISTS idx_genres_name_trgm
+    ON genres USING GIN (lower(name) gin_trgm_ops);
@@ -0,0 +1,17 @@
+CREATE TABLE order_refunds (
+    id UUID PRIMARY KEY,
+    order_id UUID NOT NULL UNIQUE REFERENCES orders(id) ON DELETE CASCADE,
+    payment_id UUID REFERENCES payments(id) ON DELETE SET NULL,
+    provider VARCHAR(50) NOT NULL,
+    provider_refund_id VARCHAR(255),
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('refund', 'chargeback')),
+    amount INTEGER NOT NULL CHECK (amount >= 0),
+    currency VARCHAR(3) NOT NULL,
+    reason TEXT,
+    initiated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX idx_order_refunds_provider_refund
+    ON order_refunds (provider, provider_refund_id)
+    WHERE provider_refund_id IS NOT NULL;
@@ -0,0 +1,8 @@
+-- Fails while multi-item orders exist; they cannot be represented without
+-- their line items.
+ALTER TABLE orders
+    ALTER COLUMN spec_id SET NOT NULL,
+    ALTER COLUMN license_type SET NOT NULL;
+
+DROP TABLE IF EXISTS order_items;
+DROP TABLE IF EXISTS cart_items;
@@ -0,0 +1,45 @@
+-- A user's cart holds at most one license option per spec; adding the same
+-- spec again swaps the license.
+CREATE TABLE cart_items (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    spec_id UUID NOT NULL REFERENCES specs(id) ON DELETE CASCADE,
+    license_option_id UUID NOT NULL REFERENCES license_options(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, spec_id)
+);
+
+-- Line items of an order, priced at checkout. Item amounts are in the order's
+-- currency and sum to orders.amount.
+CREATE TABLE order_items (
+    id UUID PRIMARY KEY,
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    spec_id UUID NOT NULL REFERENCES specs(id) ON DELETE RESTRICT,
+    license_option_id UUID NOT NULL REFERENCES license_options(id) ON DELETE RESTRICT,
+    license_type VARCHAR(50) NOT NULL CHECK (license_type IN ('Basic', 'Premium', 'Trackout', 'Unlimited')),
+    license_name VARCHAR(100) NOT NULL DEFAULT '',
+    spec_title VARCHAR(200) NOT NULL DEFAULT '',
+    amount INTEGER NOT NULL CHECK (amount > 0),
+    currency VARCHAR(3) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (order_id, spec_id)
+);
+
+CREATE INDEX idx_order_items_spec_id ON order_items(spec_id);
+
+-- Every existing order bought exactly one license, recorded in its notes.
+INSERT INTO order_items (
+    id, order_id, spec_id, license_option_id, license_type,
+    license_name, spec_title, amount, currency, created_at
+)
+SELECT
+    gen_random_uuid(), o.id, o.spec_id, lo.id, o.license_type,
+    lo.name, s.title, o.amount, o.currency, o.created_at
+FROM orders o
+JOIN specs s ON s.id = o.spec_id
+JOIN license_options lo ON lo.id::text = o.notes->>'license_option_id';
+
+-- spec_id and license_type now only describe single-item orders.
+ALTER TABLE orders
+    ALTER COLUMN spec_id DROP NOT NULL,
+    ALTER COLUMN license_type DROP NOT NULL;
@@ -0,0 +1,8 @@
+ALTER TABLE order_items DROP COLUMN IF EXISTS discount_amount;
+
+DROP INDEX IF EXISTS idx_orders_coupon_id;
+ALTER TABLE orders
+    DROP COLUMN IF EXISTS discount_amount,
+    DROP COLUMN IF EXISTS coupon_id;
+
+DROP TABLE IF EXISTS coupons;
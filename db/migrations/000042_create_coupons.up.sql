@@ -0,0 +1,36 @@
+-- Producer-owned discount codes. Codes are stored uppercase and are unique
+-- across producers because buyers enter them without any producer context.
+CREATE TABLE coupons (
+    id UUID PRIMARY KEY,
+    producer_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    code VARCHAR(32) NOT NULL UNIQUE CHECK (code = UPPER(code)),
+    discount_type VARCHAR(10) NOT NULL CHECK (discount_type IN ('percentage', 'fixed')),
+    percent_off INTEGER CHECK (percent_off BETWEEN 1 AND 100),
+    amount_off INTEGER CHECK (amount_off > 0),
+    currency VARCHAR(3) CHECK (currency IN ('INR', 'USD')),
+    spec_ids UUID[] NOT NULL DEFAULT '{}',
+    license_types TEXT[] NOT NULL DEFAULT '{}',
+    max_redemptions INTEGER CHECK (max_redemptions > 0),
+    per_user_limit INTEGER CHECK (per_user_limit > 0),
+    expires_at TIMESTAMPTZ,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (
+        (discount_type = 'percentage' AND percent_off IS NOT NULL AND amount_off IS NULL AND currency IS NULL) OR
+        (discount_type = 'fixed' AND amount_off IS NOT NULL AND currency IS NOT NULL AND percent_off IS NULL)
+    )
+);
+
+CREATE INDEX idx_coupons_producer_id ON coupons(producer_id);
+
+-- Item amounts stay net of the discount, so revenue queries need no change;
+-- discount_amount records what was taken off.
+ALTER TABLE orders
+    ADD COLUMN coupon_id UUID REFERENCES coupons(id) ON DELETE SET NULL,
+    ADD COLUMN discount_amount INTEGER NOT NULL DEFAULT 0 CHECK (discount_amount >= 0);
+
+CREATE INDEX idx_orders_coupon_id ON orders(coupon_id) WHERE coupon_id IS NOT NULL;
+
+ALTER TABLE order_items
+    ADD COLUMN discount_amount INTEGER NOT NULL DEFAULT 0 CHECK (discount_amount >= 0);
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS ledger_entries;
+DROP TABLE IF EXISTS ledger_transactions;
+DROP TABLE IF EXISTS payouts;
+
+ALTER TABLE payments DROP COLUMN IF EXISTS provider_fee;
@@ -0,0 +1,58 @@
+-- Fee the provider kept from a captured payment, in minor units of the payment
+-- currency, as reported by the provider. Zero when the provider reports none.
+ALTER TABLE payments
+    ADD COLUMN provider_fee INTEGER NOT NULL DEFAULT 0 CHECK (provider_fee >= 0);
+
+-- Money paid to a producer outside the platform (bank transfer, UPI),
+-- recorded by an admin once it has been sent.
+CREATE TABLE payouts (
+    id UUID PRIMARY KEY,
+    producer_id UUID NOT NULL REFERENCES users(id) ON DELETE RESTRICT,
+    amount INTEGER NOT NULL CHECK (amount > 0),
+    currency VARCHAR(3) NOT NULL CHECK (currency IN ('INR', 'USD')),
+    method VARCHAR(40) NOT NULL DEFAULT '',
+    reference VARCHAR(120),
+    note TEXT,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    paid_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_payouts_producer_id ON payouts(producer_id, paid_at DESC);
+
+-- Double-entry earnings ledger. Every transaction's entries sum to zero:
+-- debits are positive, credits negative. An order is posted at most once as a
+-- sale and at most once as a refund.
+CREATE TABLE ledger_transactions (
+    id UUID PRIMARY KEY,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('sale', 'refund', 'payout')),
+    order_id UUID REFERENCES orders(id) ON DELETE RESTRICT,
+    payout_id UUID UNIQUE REFERENCES payouts(id) ON DELETE RESTRICT,
+    currency VARCHAR(3) NOT NULL,
+    occurred_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (order_id, kind),
+    CHECK ((kind = 'payout') = (payout_id IS NOT NULL)),
+    CHECK ((kind = 'payout') = (order_id IS NULL))
+);
+
+CREATE INDEX idx_ledger_transactions_occurred_at ON ledger_transactions(occurred_at);
+
+-- Every entry is attributed to the producer whose sale or payout caused it, so
+-- a producer's statement can show the fees taken from each sale. Balances are
+-- read from the producer_payable account; an entry only counts towards the
+-- available balance from available_at on.
+CREATE TABLE ledger_entries (
+    id UUID PRIMARY KEY,
+    transaction_id UUID NOT NULL REFERENCES ledger_transactions(id) ON DELETE CASCADE,
+    account VARCHAR(30) NOT NULL CHECK (account IN ('gateway', 'provider_fees', 'platform_revenue', 'producer_payable')),
+    producer_id UUID NOT NULL REFERENCES users(id) ON DELETE RESTRICT,
+    order_item_id UUID REFERENCES order_items(id) ON DELETE RESTRICT,
+    amount INTEGER NOT NULL CHECK (amount <> 0),
+    currency VARCHAR(3) NOT NULL,
+    available_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_ledger_entries_transaction_id ON ledger_entries(transaction_id);
+CREATE INDEX idx_ledger_entries_producer ON ledger_entries(producer_id, currency, account);
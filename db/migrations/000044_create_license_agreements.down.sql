@@ -0,0 +1,3 @@
+ALTER TABLE licenses DROP COLUMN IF EXISTS agreement_key;
+
+DROP TABLE IF EXISTS license_agreement_templates;
@@ -0,0 +1,14 @@
+-- Producer-customised agreement terms per license option. Options without a
+-- row use the defaults for their license type.
+CREATE TABLE license_agreement_templates (
+    license_option_id UUID PRIMARY KEY REFERENCES license_options(id) ON DELETE CASCADE,
+    stream_limit INTEGER CHECK (stream_limit >= 0),
+    distribution_limit INTEGER CHECK (distribution_limit >= 0),
+    credit_required BOOLEAN NOT NULL DEFAULT TRUE,
+    terms TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Storage key of the agreement PDF rendered when the license was issued.
+ALTER TABLE licenses ADD COLUMN agreement_key TEXT;
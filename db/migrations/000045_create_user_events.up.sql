@@ -0,0 +1,17 @@
+-- The seq of each user's latest realtime event. Bumping it with an upsert
+-- serialises concurrent appends per user, so seqs have no gaps.
+CREATE TABLE user_event_sequences (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    last_seq BIGINT NOT NULL CHECK (last_seq > 0)
+);
+
+-- Realtime events sent over /ws, kept so a reconnecting client can replay the
+-- ones it missed. Only each user's latest events are kept.
+CREATE TABLE user_events (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    seq BIGINT NOT NULL,
+    type VARCHAR(50) NOT NULL,
+    payload JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, seq)
+);
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS notification_preferences;
+
+DROP INDEX IF EXISTS idx_notifications_digest_pending;
+
+ALTER TABLE notifications
+    DROP COLUMN IF EXISTS digest_pending,
+    DROP COLUMN IF EXISTS category;
@@ -0,0 +1,24 @@
+-- What a notification is about, so users can choose how each kind reaches
+-- them. Notifications created before categories existed count as account
+-- notifications, except the upload results recognisable by their titles.
+ALTER TABLE notifications
+    ADD COLUMN category VARCHAR(30) NOT NULL DEFAULT 'account',
+    -- Set while the notification waits for the user's daily email digest.
+    ADD COLUMN digest_pending BOOLEAN NOT NULL DEFAULT FALSE;
+
+UPDATE notifications SET category = 'upload_processed' WHERE title = 'Upload Complete';
+UPDATE notifications SET category = 'upload_failed' WHERE title = 'Upload Failed';
+
+CREATE INDEX idx_notifications_digest_pending ON notifications(user_id, created_at) WHERE digest_pending;
+
+-- A user's delivery choices per category. Categories without a row use the
+-- defaults in the notification module.
+CREATE TABLE notification_preferences (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    category VARCHAR(30) NOT NULL,
+    in_app BOOLEAN NOT NULL,
+    email VARCHAR(20) NOT NULL CHECK (email IN ('off', 'instant', 'daily_digest')),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, category),
+    CHECK (email <> 'daily_digest' OR in_app)
+);
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_user_sessions_user_device;
+
+ALTER TABLE user_sessions
+    DROP COLUMN IF EXISTS last_used_at,
+    DROP COLUMN IF EXISTS location,
+    DROP COLUMN IF EXISTS ip_address,
+    DROP COLUMN IF EXISTS user_agent,
+    DROP COLUMN IF EXISTS device;
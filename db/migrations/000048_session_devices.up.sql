@@ -0,0 +1,12 @@
+-- Where each session is used from, for the active sessions list and new
+-- device sign-in alerts.
+ALTER TABLE user_sessions
+    ADD COLUMN device VARCHAR(100) NOT NULL DEFAULT '',
+    ADD COLUMN user_agent VARCHAR(512) NOT NULL DEFAULT '',
+    ADD COLUMN ip_address VARCHAR(45) NOT NULL DEFAULT '',
+    ADD COLUMN location VARCHAR(255) NOT NULL DEFAULT '',
+    ADD COLUMN last_used_at TIMESTAMP NOT NULL DEFAULT NOW();
+
+UPDATE user_sessions SET last_used_at = updated_at;
+
+CREATE INDEX IF NOT EXISTS idx_user_sessions_user_device ON user_sessions(user_id, device);
@@ -0,0 +1,9 @@
+-- Refresh tokens rotated out of their session. Presenting one again means it
+-- was copied, so the session is revoked.
+CREATE TABLE IF NOT EXISTS superseded_refresh_tokens (
+    refresh_token_digest VARCHAR(64) PRIMARY KEY,
+    session_id UUID NOT NULL REFERENCES user_sessions(id) ON DELETE CASCADE,
+    superseded_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_superseded_refresh_tokens_session_id ON superseded_refresh_tokens(session_id);
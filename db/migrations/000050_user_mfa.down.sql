@@ -0,0 +1,5 @@
+ALTER TABLE user_sessions DROP COLUMN IF EXISTS mfa_verified;
+
+DROP TABLE IF EXISTS system_role_mfa_policies;
+DROP TABLE IF EXISTS mfa_recovery_codes;
+DROP TABLE IF EXISTS user_totp;
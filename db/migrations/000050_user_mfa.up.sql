@@ -0,0 +1,33 @@
+-- TOTP second factor. enabled_at stays NULL until the user confirms a code
+-- from their authenticator app.
+CREATE TABLE IF NOT EXISTS user_totp (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    secret VARCHAR(64) NOT NULL,
+    enabled_at TIMESTAMP,
+    -- The last 30-second time step a code was accepted for, so a code cannot
+    -- be used twice.
+    last_used_step BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS mfa_recovery_codes (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    code_digest VARCHAR(64) NOT NULL,
+    used_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_mfa_recovery_codes_user_id ON mfa_recovery_codes(user_id);
+
+-- System roles whose members must sign in with a second factor to use the
+-- endpoints that need the role.
+CREATE TABLE IF NOT EXISTS system_role_mfa_policies (
+    system_role VARCHAR(50) PRIMARY KEY,
+    mfa_required BOOLEAN NOT NULL DEFAULT false,
+    updated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE user_sessions ADD COLUMN mfa_verified BOOLEAN NOT NULL DEFAULT false;
@@ -0,0 +1,28 @@
+-- WebAuthn credentials (passkeys). public_key is the COSE_Key the
+-- authenticator registered.
+CREATE TABLE IF NOT EXISTS user_passkeys (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    credential_id BYTEA NOT NULL UNIQUE,
+    public_key BYTEA NOT NULL,
+    -- The authenticator's signature counter; a counter that does not go up
+    -- suggests a cloned authenticator. Synced passkeys always report 0.
+    sign_count BIGINT NOT NULL DEFAULT 0,
+    name VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    last_used_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_passkeys_user_id ON user_passkeys(user_id);
+
+-- Challenges issued to begin a ceremony. Each is used at most once.
+CREATE TABLE IF NOT EXISTS webauthn_challenges (
+    challenge VARCHAR(64) PRIMARY KEY,
+    -- Set for registration; sign-in does not know the user until it ends.
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    purpose VARCHAR(20) NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_webauthn_challenges_expires_at ON webauthn_challenges(expires_at);
@@ -0,0 +1,2 @@
+ALTER TABLE orders DROP COLUMN IF EXISTS exchange_rate_snapshot_id;
+DROP TABLE IF EXISTS exchange_rate_snapshots;
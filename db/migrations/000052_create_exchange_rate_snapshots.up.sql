@@ -0,0 +1,18 @@
+-- Exchange rates as fetched from the configured provider. A snapshot is only
+-- stored when the rates change, so the table doubles as their history.
+CREATE TABLE IF NOT EXISTS exchange_rate_snapshots (
+    id UUID PRIMARY KEY,
+    base_currency VARCHAR(3) NOT NULL,
+    -- Units of each currency per unit of base_currency, keyed by ISO 4217 code.
+    rates JSONB NOT NULL,
+    source VARCHAR(255) NOT NULL,
+    as_of TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_exchange_rate_snapshots_created_at ON exchange_rate_snapshots(created_at DESC);
+
+-- The rates an order was priced with. NULL for orders placed before snapshots
+-- existed or priced with the INR_USD_RATE fallback.
+ALTER TABLE orders
+    ADD COLUMN IF NOT EXISTS exchange_rate_snapshot_id UUID REFERENCES exchange_rate_snapshots(id);
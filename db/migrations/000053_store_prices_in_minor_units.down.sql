@@ -0,0 +1,15 @@
+ALTER TABLE license_options DROP CONSTRAINT IF EXISTS license_options_price_minor_check;
+ALTER TABLE license_options
+    ALTER COLUMN price_minor TYPE DECIMAL(10,2)
+    USING price_minor::DECIMAL / CASE price_currency WHEN 'JPY' THEN 1 ELSE 100 END;
+ALTER TABLE license_options RENAME COLUMN price_minor TO price;
+ALTER TABLE license_options
+    ADD CONSTRAINT license_options_price_check CHECK (price >= 0);
+
+ALTER TABLE specs DROP CONSTRAINT IF EXISTS specs_base_price_minor_check;
+ALTER TABLE specs
+    ALTER COLUMN base_price_minor TYPE DECIMAL(10,2)
+    USING base_price_minor::DECIMAL / CASE price_currency WHEN 'JPY' THEN 1 ELSE 100 END;
+ALTER TABLE specs RENAME COLUMN base_price_minor TO base_price;
+ALTER TABLE specs
+    ADD CONSTRAINT specs_base_price_check CHECK (base_price >= 0);
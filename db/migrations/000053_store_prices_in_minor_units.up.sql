@@ -0,0 +1,20 @@
+-- Prices are stored in integer minor units (cents, paise, whole yen), like
+-- order and license amounts, instead of DECIMAL major units.
+ALTER TABLE specs DROP CONSTRAINT IF EXISTS specs_base_price_check;
+ALTER TABLE specs RENAME COLUMN base_price TO base_price_minor;
+ALTER TABLE specs
+    ALTER COLUMN base_price_minor TYPE BIGINT
+    USING ROUND(base_price_minor * CASE price_currency WHEN 'JPY' THEN 1 ELSE 100 END);
+ALTER TABLE specs
+    ADD CONSTRAINT specs_base_price_minor_check CHECK (base_price_minor >= 0);
+
+ALTER TABLE license_options DROP CONSTRAINT IF EXISTS license_options_price_check;
+ALTER TABLE license_options RENAME COLUMN price TO price_minor;
+ALTER TABLE license_options
+    ALTER COLUMN price_minor TYPE BIGINT
+    USING ROUND(price_minor * CASE price_currency WHEN 'JPY' THEN 1 ELSE 100 END);
+ALTER TABLE license_options
+    ADD CONSTRAINT license_options_price_minor_check CHECK (price_minor >= 0);
+
+-- Draft metadata in spec_upload_sessions keeps the legacy "price" field next
+-- to "price_minor", so it needs no conversion in either direction.
@@ -0,0 +1,34 @@
+-- Scoring profiles for the algorithmic homepage sections. While a section has
+-- no active profile its built-in formula is used. Weights multiply the counts
+-- over the ranking window; revenue is weighted per major currency unit.
+CREATE TABLE IF NOT EXISTS ranking_profiles (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(100) NOT NULL,
+    section VARCHAR(40) NOT NULL CHECK (section IN ('trending', 'top_charts')),
+    plays_weight DOUBLE PRECISION NOT NULL DEFAULT 0 CHECK (plays_weight >= 0),
+    unique_listeners_weight DOUBLE PRECISION NOT NULL DEFAULT 0 CHECK (unique_listeners_weight >= 0),
+    favorites_weight DOUBLE PRECISION NOT NULL DEFAULT 0 CHECK (favorites_weight >= 0),
+    downloads_weight DOUBLE PRECISION NOT NULL DEFAULT 0 CHECK (downloads_weight >= 0),
+    purchases_weight DOUBLE PRECISION NOT NULL DEFAULT 0 CHECK (purchases_weight >= 0),
+    revenue_weight DOUBLE PRECISION NOT NULL DEFAULT 0 CHECK (revenue_weight >= 0),
+    -- Age in hours at which a beat's score is halved; 0 disables decay.
+    recency_half_life_hours DOUBLE PRECISION NOT NULL DEFAULT 0 CHECK (recency_half_life_hours >= 0),
+    -- Most beats one producer may place in a section; 0 means no cap.
+    max_per_producer INTEGER NOT NULL DEFAULT 0 CHECK (max_per_producer >= 0),
+    is_active BOOLEAN NOT NULL DEFAULT FALSE,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    activated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    activated_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_ranking_profiles_active_section ON ranking_profiles(section) WHERE is_active;
+CREATE INDEX IF NOT EXISTS idx_ranking_profiles_section_created_at ON ranking_profiles(section, created_at DESC);
+
+-- Starting points that mirror the base weights of the built-in formulas.
+-- They are inactive, so rankings do not change until an admin activates one.
+INSERT INTO ranking_profiles (name, section, plays_weight, unique_listeners_weight, favorites_weight, downloads_weight, purchases_weight, revenue_weight, recency_half_life_hours, max_per_producer)
+VALUES
+    ('Trending baseline', 'trending', 1.0, 1.5, 4.0, 6.0, 15.0, 0.15, 168, 0),
+    ('Top charts baseline', 'top_charts', 1.0, 0, 3.0, 4.0, 20.0, 0.25, 0, 0);
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_spec_upload_sessions_unpurged;
+
+ALTER TABLE spec_upload_sessions
+    DROP COLUMN IF EXISTS objects_purged_at;
+
+DROP TABLE IF EXISTS scheduled_job_runs;
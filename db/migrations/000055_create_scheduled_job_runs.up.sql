@@ -0,0 +1,26 @@
+CREATE TABLE scheduled_job_runs (
+    id UUID PRIMARY KEY,
+    job_name VARCHAR(100) NOT NULL,
+    scheduled_for TIMESTAMPTZ NOT NULL,
+    instance_id VARCHAR(120) NOT NULL,
+    status VARCHAR(20) NOT NULL
+        CHECK (status IN ('running', 'succeeded', 'failed')),
+    error_message TEXT,
+    started_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    finished_at TIMESTAMPTZ,
+    duration_ms BIGINT,
+    -- One run per activation, however many replicas woke up for it.
+    UNIQUE (job_name, scheduled_for)
+);
+
+CREATE INDEX idx_scheduled_job_runs_job
+    ON scheduled_job_runs (job_name, started_at DESC);
+
+-- Set once the staging and final objects of an expired or failed upload have
+-- been deleted from storage.
+ALTER TABLE spec_upload_sessions
+    ADD COLUMN objects_purged_at TIMESTAMPTZ;
+
+CREATE INDEX idx_spec_upload_sessions_unpurged
+    ON spec_upload_sessions (updated_at)
+    WHERE status IN ('expired', 'failed') AND objects_purged_at IS NULL;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_analytics_events_user_plays;
+
+DROP TABLE IF EXISTS user_recommendations;
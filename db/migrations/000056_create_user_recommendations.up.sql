@@ -0,0 +1,19 @@
+-- Personalized "For you" beats, recalculated per user by a scheduled job.
+-- metrics holds the points each signal added to the score.
+CREATE TABLE user_recommendations (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    spec_id UUID NOT NULL REFERENCES specs(id) ON DELETE CASCADE,
+    rank INTEGER NOT NULL CHECK (rank > 0),
+    score DOUBLE PRECISION NOT NULL,
+    metrics JSONB NOT NULL DEFAULT '{}'::jsonb,
+    calculated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, spec_id)
+);
+
+CREATE INDEX idx_user_recommendations_rank
+    ON user_recommendations (user_id, rank);
+
+-- Listening history of signed-in users, read when building their profile.
+CREATE INDEX idx_analytics_events_user_plays
+    ON analytics_events (user_id, created_at DESC)
+    WHERE user_id IS NOT NULL AND event_type = 'play';
@@ -0,0 +1,4 @@
+ALTER TABLE user_totp
+    DROP COLUMN IF EXISTS locked_until,
+    DROP COLUMN IF EXISTS lockouts,
+    DROP COLUMN IF EXISTS failed_attempts;
@@ -0,0 +1,7 @@
+-- Second factor attempts. failed_attempts counts attempts since the last
+-- success or lockout; lockouts counts lockouts since the last success, so
+-- each one lasts longer.
+ALTER TABLE user_totp
+    ADD COLUMN failed_attempts INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN lockouts INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN locked_until TIMESTAMP;
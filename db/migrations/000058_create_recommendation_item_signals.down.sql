@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS recommendation_item_stats;
+
+DROP TABLE IF EXISTS recommendation_item_pairs;
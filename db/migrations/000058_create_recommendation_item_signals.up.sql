@@ -0,0 +1,18 @@
+-- Beat-to-beat co-occurrence and per-beat counts, rebuilt once at the start
+-- of each "For you" run and shared by every user's recalculation.
+-- co_users is how many users played, favorited or bought both beats.
+CREATE TABLE recommendation_item_pairs (
+    spec_id UUID NOT NULL,
+    other_spec_id UUID NOT NULL,
+    co_users INTEGER NOT NULL,
+    PRIMARY KEY (spec_id, other_spec_id)
+);
+
+-- users counts the listeners of a beat over the same signals; plays and
+-- favorites cover the last 30 days.
+CREATE TABLE recommendation_item_stats (
+    spec_id UUID PRIMARY KEY,
+    users INTEGER NOT NULL DEFAULT 0,
+    plays INTEGER NOT NULL DEFAULT 0,
+    favorites INTEGER NOT NULL DEFAULT 0
+);
@@ -0,0 +1,5 @@
+ALTER TABLE licenses
+    DROP COLUMN IF EXISTS agreement_terms,
+    DROP COLUMN IF EXISTS agreement_credit_required,
+    DROP COLUMN IF EXISTS agreement_distribution_limit,
+    DROP COLUMN IF EXISTS agreement_stream_limit;
@@ -0,0 +1,9 @@
+-- The agreement template a license was sold under, copied when the license
+-- is issued. Agreements are only ever rendered from this copy, so later
+-- template edits never change what a buyer agreed to. agreement_terms is
+-- NULL for licenses issued before the copy was kept.
+ALTER TABLE licenses
+    ADD COLUMN agreement_stream_limit INTEGER,
+    ADD COLUMN agreement_distribution_limit INTEGER,
+    ADD COLUMN agreement_credit_required BOOLEAN NOT NULL DEFAULT TRUE,
+    ADD COLUMN agreement_terms TEXT;
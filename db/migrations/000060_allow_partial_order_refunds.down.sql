@@ -0,0 +1,16 @@
+-- Orders refunded in several parts cannot go back to one refund row each
+-- without losing the provider refund ids, so refuse to revert once any exist.
+DO $$
+BEGIN
+    IF EXISTS (
+        SELECT 1 FROM order_refunds
+        GROUP BY order_id
+        HAVING COUNT(*) > 1
+    ) THEN
+        RAISE EXCEPTION 'cannot revert 000060: some orders have more than one refund';
+    END IF;
+END $$;
+
+DROP INDEX IF EXISTS idx_order_refunds_order;
+
+ALTER TABLE order_refunds ADD CONSTRAINT order_refunds_order_id_key UNIQUE (order_id);
@@ -0,0 +1,5 @@
+-- An order can be refunded in several parts. It is marked refunded and its
+-- licenses revoked once its refunds add up to the order amount.
+ALTER TABLE order_refunds DROP CONSTRAINT IF EXISTS order_refunds_order_id_key;
+
+CREATE INDEX idx_order_refunds_order ON order_refunds (order_id, created_at);
@@ -0,0 +1,17 @@
+DO $$
+BEGIN
+    IF EXISTS (
+        SELECT 1 FROM ledger_transactions
+        WHERE kind = 'refund'
+        GROUP BY order_id
+        HAVING COUNT(*) > 1
+    ) THEN
+        RAISE EXCEPTION 'cannot revert 000061: some orders have more than one refund posting in the ledger';
+    END IF;
+END $$;
+
+DROP INDEX IF EXISTS idx_ledger_transactions_order_sale;
+
+ALTER TABLE ledger_transactions ADD CONSTRAINT ledger_transactions_order_id_kind_key UNIQUE (order_id, kind);
+
+ALTER TABLE ledger_transactions DROP COLUMN IF EXISTS refund_id;
@@ -0,0 +1,21 @@
+-- Every refund of an order is posted to the ledger as its own transaction, so
+-- refund postings are unique per order_refunds row instead of per order.
+-- Postings made before this only reversed fully refunded orders; they are
+-- attributed to the order's latest refund.
+ALTER TABLE ledger_transactions
+    ADD COLUMN refund_id UUID UNIQUE REFERENCES order_refunds(id) ON DELETE RESTRICT;
+
+UPDATE ledger_transactions t
+SET refund_id = (
+    SELECT r.id FROM order_refunds r
+    WHERE r.order_id = t.order_id
+    ORDER BY r.created_at DESC, r.id DESC
+    LIMIT 1
+)
+WHERE t.kind = 'refund';
+
+ALTER TABLE ledger_transactions DROP CONSTRAINT IF EXISTS ledger_transactions_order_id_kind_key;
+
+CREATE UNIQUE INDEX idx_ledger_transactions_order_sale
+    ON ledger_transactions (order_id)
+    WHERE kind = 'sale';
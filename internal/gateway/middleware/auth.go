@@ -2,9 +2,11 @@ package middleware
 
 import (
 	"context"
+	"log"
 	"net/http"
 	"strings"
 
+	"github.com/google/uuid"
 	"github.com/saransh1220/blueprint-audio/internal/shared/utils"
 )
 
@@ -14,10 +16,32 @@ const (
 	ContextKeyUserId     contextKey = "user_id"
 	ContextKeyRole       contextKey = "role"
 	ContextKeySystemRole contextKey = "system_role"
+	ContextKeySessionID  contextKey = "session_id"
+	// ContextKeyMFA holds whether the session was signed in with a second
+	// factor.
+	ContextKeyMFA contextKey = "mfa"
+	// ContextKeySessionActive holds a func(context.Context) bool reporting
+	// whether the request's session is still active, for long-lived
+	// connections to check again later.
+	ContextKeySessionActive contextKey = "session_active"
 )
 
+// SessionChecker reports whether the session an access token was issued for
+// is still active.
+type SessionChecker interface {
+	SessionActive(ctx context.Context, sessionID uuid.UUID) (bool, error)
+}
+
+// MFAPolicy reports whether a system role must have signed in with a second
+// factor to use its permissions.
+type MFAPolicy interface {
+	MFARequired(ctx context.Context, systemRole string) (bool, error)
+}
+
 type AuthMiddleWare struct {
 	jwtSecret string
+	sessions  SessionChecker
+	mfaPolicy MFAPolicy
 }
 
 // NewAuthMiddleware creates and returns a new instance of AuthMiddleWare.
@@ -27,6 +51,18 @@ func NewAuthMiddleware(jwtSecret string) *AuthMiddleWare {
 	return &AuthMiddleWare{jwtSecret: jwtSecret}
 }
 
+// SetSessionChecker makes the middleware reject access tokens whose session
+// was revoked or whose user was suspended, and tokens without a session.
+func (m *AuthMiddleWare) SetSessionChecker(sessions SessionChecker) {
+	m.sessions = sessions
+}
+
+// SetMFAPolicy makes RequirePermission and RequireSystemRole reject tokens
+// without a second factor for system roles the policy requires it for.
+func (m *AuthMiddleWare) SetMFAPolicy(policy MFAPolicy) {
+	m.mfaPolicy = policy
+}
+
 // RequireAuth is a middleware function that enforces authentication on HTTP requests.
 // It validates the presence and format of a Bearer token in the Authorization header,
 // verifies the token's validity and expiration using the stored JWT secret, and injects
@@ -61,10 +97,18 @@ func (m *AuthMiddleWare) RequireAuth(next http.Handler) http.Handler {
 			http.Error(w, `{"error": "invalid or expired token"}`, http.StatusUnauthorized)
 			return
 		}
+		active, err := m.sessionActive(r.Context(), claims)
+		if err != nil {
+			log.Printf("AuthMiddleWare.RequireAuth session check failed. session_id=%s err=%v", claims.SessionID, err)
+			http.Error(w, `{"error": "unable to verify session"}`, http.StatusServiceUnavailable)
+			return
+		}
+		if !active {
+			http.Error(w, `{"error": "session revoked"}`, http.StatusUnauthorized)
+			return
+		}
 		//  Inject Identity & Role into Context
-		ctx := context.WithValue(r.Context(), ContextKeyUserId, claims.UserID)
-		ctx = context.WithValue(ctx, ContextKeyRole, claims.Role) // <--- Crucial for RBAC
-		ctx = context.WithValue(ctx, ContextKeySystemRole, claims.SystemRole)
+		ctx := m.withIdentity(r.Context(), claims)
 
 		next.ServeHTTP(w, r.WithContext(ctx))
 
@@ -95,12 +139,66 @@ func (m *AuthMiddleWare) FlexibleAuth(next http.Handler) http.Handler {
 			next.ServeHTTP(w, r)
 			return
 		}
+		if active, err := m.sessionActive(r.Context(), claims); err != nil || !active {
+			// Session revoked or unverifiable - proceed as guest
+			next.ServeHTTP(w, r)
+			return
+		}
 
 		// Inject Identity & Role into Context
-		ctx := context.WithValue(r.Context(), ContextKeyUserId, claims.UserID)
-		ctx = context.WithValue(ctx, ContextKeyRole, claims.Role)
-		ctx = context.WithValue(ctx, ContextKeySystemRole, claims.SystemRole)
+		ctx := m.withIdentity(r.Context(), claims)
 
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
+
+// sessionActive reports whether the token's session is still active. Every
+// token is accepted without a session checker.
+func (m *AuthMiddleWare) sessionActive(ctx context.Context, claims *utils.Claims) (bool, error) {
+	if m.sessions == nil {
+		return true, nil
+	}
+	if claims.SessionID == uuid.Nil {
+		return false, nil
+	}
+	return m.sessions.SessionActive(ctx, claims.SessionID)
+}
+
+// mfaSatisfied reports whether the request meets the second factor policy
+// for the system role it acts with, answering the request when it does not.
+func (m *AuthMiddleWare) mfaSatisfied(w http.ResponseWriter, r *http.Request, systemRole string) bool {
+	if m.mfaPolicy == nil {
+		return true
+	}
+	if mfa, _ := r.Context().Value(ContextKeyMFA).(bool); mfa {
+		return true
+	}
+	required, err := m.mfaPolicy.MFARequired(r.Context(), systemRole)
+	if err != nil {
+		log.Printf("AuthMiddleWare MFA policy check failed. system_role=%s err=%v", systemRole, err)
+		http.Error(w, `{"error": "unable to verify two-factor policy"}`, http.StatusServiceUnavailable)
+		return false
+	}
+	if required {
+		http.Error(w, `{"error":"two-factor authentication required"}`, http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+func (m *AuthMiddleWare) withIdentity(ctx context.Context, claims *utils.Claims) context.Context {
+	ctx = context.WithValue(ctx, ContextKeyUserId, claims.UserID)
+	ctx = context.WithValue(ctx, ContextKeyRole, claims.Role) // <--- Crucial for RBAC
+	ctx = context.WithValue(ctx, ContextKeySystemRole, claims.SystemRole)
+	ctx = context.WithValue(ctx, ContextKeySessionID, claims.SessionID)
+	ctx = context.WithValue(ctx, ContextKeyMFA, claims.MFA)
+	return context.WithValue(ctx, ContextKeySessionActive, func(ctx context.Context) bool {
+		active, err := m.sessionActive(ctx, claims)
+		if err != nil {
+			// Keep long-lived connections open through transient failures.
+			log.Printf("AuthMiddleWare session recheck failed. session_id=%s err=%v", claims.SessionID, err)
+			return true
+		}
+		return active
+	})
+}
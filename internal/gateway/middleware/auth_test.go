@@ -1,11 +1,14 @@
 package middleware
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
 
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 	"github.com/saransh1220/blueprint-audio/internal/shared/utils"
 	"github.com/stretchr/testify/assert"
@@ -179,3 +182,82 @@ func TestFlexibleAuth_WithInvalidToken(t *testing.T) {
 	assert.True(t, nextCalled)
 	assert.Equal(t, http.StatusOK, rec.Code)
 }
+
+type sessionCheckerStub map[uuid.UUID]bool
+
+func (s sessionCheckerStub) SessionActive(_ context.Context, sessionID uuid.UUID) (bool, error) {
+	active, ok := s[sessionID]
+	if !ok {
+		return false, errors.New("lookup failed")
+	}
+	return active, nil
+}
+
+func sessionToken(t *testing.T, userID, sessionID uuid.UUID) string {
+	t.Helper()
+	claims := utils.Claims{
+		UserID:     userID,
+		SessionID:  sessionID,
+		Role:       "artist",
+		SystemRole: "user",
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(testSecret))
+	require.NoError(t, err)
+	return token
+}
+
+func TestRequireAuth_ChecksSession(t *testing.T) {
+	userID := uuid.New()
+	active, revoked, unknown := uuid.New(), uuid.New(), uuid.New()
+	sessions := sessionCheckerStub{active: true, revoked: false}
+	middleware := NewAuthMiddleware(testSecret)
+	middleware.SetSessionChecker(sessions)
+
+	var recheck func(context.Context) bool
+	handler := middleware.RequireAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, active, r.Context().Value(ContextKeySessionID))
+		recheck, _ = r.Context().Value(ContextKeySessionActive).(func(context.Context) bool)
+	}))
+	serve := func(token string) int {
+		req := httptest.NewRequest("GET", "/test", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusOK, serve(sessionToken(t, userID, active)))
+	assert.Equal(t, http.StatusUnauthorized, serve(sessionToken(t, userID, revoked)))
+	assert.Equal(t, http.StatusServiceUnavailable, serve(sessionToken(t, userID, unknown)))
+	// Tokens issued before sessions were tracked are rejected.
+	legacy, err := utils.GenerateToken(userID, "test@example.com", "user", "user", testSecret, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, serve(legacy))
+
+	require.NotNil(t, recheck)
+	assert.True(t, recheck(context.Background()))
+	sessions[active] = false
+	assert.False(t, recheck(context.Background()))
+	assert.Equal(t, http.StatusUnauthorized, serve(sessionToken(t, userID, active)))
+}
+
+func TestFlexibleAuth_RevokedSessionIsGuest(t *testing.T) {
+	sessionID := uuid.New()
+	middleware := NewAuthMiddleware(testSecret)
+	middleware.SetSessionChecker(sessionCheckerStub{sessionID: false})
+
+	req := httptest.NewRequest("GET", "/test", nil)
+	req.Header.Set("Authorization", "Bearer "+sessionToken(t, uuid.New(), sessionID))
+	rec := httptest.NewRecorder()
+	nextCalled := false
+	middleware.FlexibleAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		nextCalled = true
+		assert.Nil(t, r.Context().Value(ContextKeyUserId))
+	})).ServeHTTP(rec, req)
+
+	assert.True(t, nextCalled)
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
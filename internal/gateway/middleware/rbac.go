@@ -36,6 +36,9 @@ func (m *AuthMiddleWare) RequirePermission(permission Permission, next http.Hand
 			http.Error(w, `{"error":"forbidden"}`, http.StatusForbidden)
 			return
 		}
+		if !m.mfaSatisfied(w, r, systemRole) {
+			return
+		}
 		next.ServeHTTP(w, r)
 	}))
 }
@@ -47,7 +50,9 @@ func (m *AuthMiddleWare) RequireSystemRole(roles []string, next http.Handler) ht
 		systemRole, _ := r.Context().Value(ContextKeySystemRole).(string)
 		for _, role := range roles {
 			if systemRole == role {
-				next.ServeHTTP(w, r)
+				if m.mfaSatisfied(w, r, systemRole) {
+					next.ServeHTTP(w, r)
+				}
 				return
 			}
 		}
@@ -1,15 +1,19 @@
 package middleware
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
 
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 	"github.com/saransh1220/blueprint-audio/internal/modules/auth/domain"
 	"github.com/saransh1220/blueprint-audio/internal/shared/utils"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestHasPermission(t *testing.T) {
@@ -90,3 +94,57 @@ func TestRequireUserRole(t *testing.T) {
 		assert.Equal(t, http.StatusNoContent, rec.Code)
 	})
 }
+
+type mfaPolicyStub map[string]bool
+
+func (p mfaPolicyStub) MFARequired(_ context.Context, systemRole string) (bool, error) {
+	required, ok := p[systemRole]
+	if !ok {
+		return false, errors.New("policy unavailable")
+	}
+	return required, nil
+}
+
+func TestRequirePermission_MFAPolicy(t *testing.T) {
+	m := NewAuthMiddleware(testSecret)
+	m.SetMFAPolicy(mfaPolicyStub{"super_admin": true})
+	token := func(systemRole string, mfa bool) string {
+		claims := utils.Claims{
+			UserID:           uuid.New(),
+			Role:             "artist",
+			SystemRole:       systemRole,
+			MFA:              mfa,
+			RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour))},
+		}
+		signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(testSecret))
+		require.NoError(t, err)
+		return signed
+	}
+	next := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusNoContent) })
+	serve := func(h http.Handler, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	for name, h := range map[string]http.Handler{
+		"permission":  m.RequirePermission(PermissionSuperAdmin, next),
+		"system role": m.RequireSystemRole([]string{"super_admin", "moderator"}, next),
+	} {
+		t.Run(name, func(t *testing.T) {
+			rec := serve(h, token("super_admin", false))
+			assert.Equal(t, http.StatusForbidden, rec.Code)
+			assert.Contains(t, rec.Body.String(), "two-factor authentication required")
+
+			assert.Equal(t, http.StatusNoContent, serve(h, token("super_admin", true)).Code)
+		})
+	}
+
+	// A failing lookup fails closed.
+	roles := m.RequireSystemRole([]string{"moderator"}, next)
+	assert.Equal(t, http.StatusServiceUnavailable, serve(roles, token("moderator", false)).Code)
+	m.SetMFAPolicy(mfaPolicyStub{"moderator": false})
+	assert.Equal(t, http.StatusNoContent, serve(roles, token("moderator", false)).Code)
+}
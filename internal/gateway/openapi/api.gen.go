@@ -129,11 +129,17 @@ type LicenseOption struct {
 	FileTypes         []string           `json:"file_types"`
 	Id                openapi_types.UUID `json:"id"`
 	Name              string             `json:"name"`
-	Price             float64            `json:"price"`
-	PriceMoney        Money              `json:"price_money"`
-	SpecId            openapi_types.UUID `json:"spec_id"`
-	Type              LicenseOptionType  `json:"type"`
-	UpdatedAt         time.Time          `json:"updated_at"`
+
+	// Price price_minor in major units
+	// Deprecated: this property has been marked as deprecated upstream, but no `x-deprecated-reason` was set
+	Price float64 `json:"price"`
+
+	// PriceMinor Price in the minor units of price_money's currency
+	PriceMinor int64              `json:"price_minor"`
+	PriceMoney Money              `json:"price_money"`
+	SpecId     openapi_types.UUID `json:"spec_id"`
+	Type       LicenseOptionType  `json:"type"`
+	UpdatedAt  time.Time          `json:"updated_at"`
 }
 
 // LicenseOptionType defines model for LicenseOption.Type.
@@ -164,6 +170,12 @@ type PublicAnalytics struct {
 	TotalDownloadCount int  `json:"total_download_count"`
 }
 
+// SearchHighlights Present only on search results. Snippets are HTML-escaped with matches wrapped in `<mark>`.
+type SearchHighlights struct {
+	Description *string `json:"description,omitempty"`
+	Title       *string `json:"title,omitempty"`
+}
+
 // Spec defines model for Spec.
 type Spec struct {
 	Analytics         *PublicAnalytics `json:"analytics,omitempty"`
@@ -174,17 +186,26 @@ type Spec struct {
 	DisplayPriceMoney Money            `json:"display_price_money"`
 
 	// Duration Seconds
-	Duration         int                  `json:"duration"`
-	FreeMp3Enabled   bool                 `json:"free_mp3_enabled"`
-	Genres           *[]Genre             `json:"genres,omitempty"`
-	Id               openapi_types.UUID   `json:"id"`
-	ImageUrl         string               `json:"image_url"`
-	Instruments      *[]string            `json:"instruments,omitempty"`
-	Key              string               `json:"key"`
-	Licenses         *[]LicenseOption     `json:"licenses,omitempty"`
-	Moods            *[]string            `json:"moods,omitempty"`
-	PreviewUrl       string               `json:"preview_url"`
-	Price            float64              `json:"price"`
+	Duration       int      `json:"duration"`
+	FreeMp3Enabled bool     `json:"free_mp3_enabled"`
+	Genres         *[]Genre `json:"genres,omitempty"`
+
+	// Highlights Present only on search results. Snippets are HTML-escaped with matches wrapped in `<mark>`.
+	Highlights  *SearchHighlights  `json:"highlights,omitempty"`
+	Id          openapi_types.UUID `json:"id"`
+	ImageUrl    string             `json:"image_url"`
+	Instruments *[]string          `json:"instruments,omitempty"`
+	Key         string             `json:"key"`
+	Licenses    *[]LicenseOption   `json:"licenses,omitempty"`
+	Moods       *[]string          `json:"moods,omitempty"`
+	PreviewUrl  string             `json:"preview_url"`
+
+	// Price price_minor in major units
+	// Deprecated: this property has been marked as deprecated upstream, but no `x-deprecated-reason` was set
+	Price float64 `json:"price"`
+
+	// PriceMinor Starting price in the minor units of price_money's currency
+	PriceMinor       int64                `json:"price_minor"`
 	PriceMoney       Money                `json:"price_money"`
 	ProcessingStatus SpecProcessingStatus `json:"processing_status"`
 	ProducerId       openapi_types.UUID   `json:"producer_id"`
@@ -233,10 +254,10 @@ type ListMyFavoritesParams struct {
 	// Cursor Opaque cursor returned by the previous response.
 	Cursor *string `form:"cursor,omitempty" json:"cursor,omitempty"`
 
-	// CFIPCountry Cloudflare two-letter country code. IN selects INR display prices; every other value selects USD.
+	// CFIPCountry Cloudflare two-letter country code, which selects the display currency (e.g. IN for INR, DE for EUR). Countries without their own supported currency see USD.
 	CFIPCountry *CloudflareCountry `json:"CF-IPCountry,omitempty"`
 
-	// XCountryCode Fallback country code when CF-IPCountry is absent. IN selects INR display prices; every other value selects USD.
+	// XCountryCode Fallback country code when CF-IPCountry is absent, selecting the display currency the same way.
 	XCountryCode *CountryCode `json:"X-Country-Code,omitempty"`
 }
 
@@ -650,50 +671,60 @@ func (sh *strictHandler) ListMyFavorites(w http.ResponseWriter, r *http.Request,
 // const string: with thousands of chunks the chained `+` fold is several
 // times slower for the Go compiler than parsing a slice literal.
 var swaggerSpec = []string{
-	"1FpZbxs58v8qBP9/YICgJTnH7IP2ybHHu5rNYfjALBAHSqlZkhizyQ4P2VpD331Bsm+1bDnWLDBPdpPF",
-	"Yl2s+hWpB5qqLFcSpTV0/EBz0JChRR2+jo1BOzn1/3JJxzQHu6QJlZAhHVMoZhOq8YfjGhkdW+0woSZd",
-	"YgZ+2VzpDCwdU+c4owm169wvNVZzuaCbTUJPhHJsLkDjiXLS6rVfxtCkmueWK79tTULsnRoItBY1SSM5",
-	"SRXDIZl8IgYFptaQyacLwrjJBaxJrnmK5u8EV6jXRNklarIC4bCivr48HdIk6rdEYKhrDU/OBpPzUqym",
-	"WngPWS48yeQTTWgG9x9QLuySjt8kNOOy8dWjcWR4ohhu63oGQswgvW2pR+6WKElTGsINgZlBaf8k1f89",
-	"KHYaBDkPpnyMprbOF2iU0ymS6+vJ6ZCcgAWhFkQodetyAsIoAmmKuTUEiFkqbaNVlCZGuEWlQzs8Q7zt",
-	"jsyuaBtPbHIlDYbYfw/sAn84NNZ/pUpalOFfyHPBU/Cyj74br8BDg+3/a5zTMf2/UX2uRnHWjH7TWulg",
-	"BYv3dpQL4M9Yfe7Jr/Delmw2SceOE7kCwRnhMneWemNLi1qCiCv+KmpcS7zPMbXIiEG9Qk0wkib0WoKz",
-	"S6X5f7xL/yL6fOTGcLnw4coLD3ktUNpC3rB3wc9vV7kr1ypHbXmMSIYWuDA9wZtQLJfUh7PcS8coJjPF",
-	"1r0ZuD4jXwo+XysyNfuOaYilM1gpzS1OLGbB3oxxLzyI84aUcxAGk47g82Ipm4JtFQUGFgeWZ7gtV0JN",
-	"julTxr/0NF0dWtsVfB7T6BwW+EyNlmCmmdLY8MVMKYEgPWNuMQtk1T+P6dAy7KaSE7SGtf+WeG+nqdMm",
-	"OrgdW+cafRGIBQJkzPI5LDAUiBVwATOBwyf9HiVNasX6DPYPlFHltjFSjfBc53K2BzooU3lPvPu835fF",
-	"O1qxshwUK5KmsH0qfuApSoOfC/seQtWiHk9DPZ5mSuL6qZD4GIg2CZ0jWKexHUxbO3QjZs4FTv3gM9e9",
-	"1ClBw7ZhlJuJhlWky2aoK9pnWsOf5OmeQsaBB4rSZT4U3oPhKU38gcm4y2hCrzSkt8r5FHEtBc98xmjE",
-	"RM3K5eyZTu8Lw1L4griKy2i0tkH6g6YRDi0ft4K6JW5fhH8sTd6ObMg83Jtm8L0vzZxiyjMQJFIRLold",
-	"Ikmd1ijT9S+GhHXESe4l2MP95XZc9m13vGsbk4EQvph1d+LS/u1dvRGXFhdxp3J1F7lePOm2loxJ20IN",
-	"vn1G7oCCLQXDcKjI5E5za1GS2ZpItKOltfkvhvg/w0A1JJcqQ7IEyQRqU1jDijXJnSW/X37+NPBA2QMM",
-	"D1+i0bghAcYMwpDfx5eA52KDhJ67meDpsQSxtjw122FT1tppaFcaWaHhAW6mVUnuL5gh1B/hYJUFMWXq",
-	"TgoFbDdlx4ENtklX0h1MO9L2+faywCWd89O00aN4sWPSTUJnedavdwoWFyq2xGUim2FENdGVffnqp4pU",
-	"MzwfDlvEmNNQMm4fg0tMlWSm99zONeI0y99OUXoIsyNyFh6S7I+1IoL5+dLHM1jg1GnRpta8l1gaq11W",
-	"Xq3sX4dvo3G36EQEJ/ur20YzPTtlSrFnCpdrXHG829cIfzYmyLVKMfRXU2PBOtM8KzlK5uVokvnUrfzZ",
-	"8efbpwUudtT9XCvmUtT7Qo6KfjdqXSptp2lx8bMvqE2ohcUzvWS5FfgI5cMhYE5C72CFnnKaI9y2Rdyj",
-	"NLeF7gNNTRd0DVwq2UiTFbbyGTWeo3Zqa57gdig/D4hVKa0nT+2GY33Rul1hfCBg6jS360sf7fG8zxA0",
-	"6mNnl/XXWWni3/+4ot1bh+PU70SsukVJNFqnJTKPMoRacEmUJhrnGs1yWF7uhdQaGNfe8jiEBs8E2hOP",
-	"NHpuLf95dXU+UFKsS6bEeDWVJGlYQQzaau/qvi7O1Td2xdppkLmWAXL+L3/YNyGnzlV58QNpiNRi8R+w",
-	"wjVoRn6TCy4ReytbEJQcn0/IXOkALqtlvq6SDPQt2lxAisMbeSOvDZJXr47LO6dXr4hVJNdqxRkSkOFa",
-	"sjIyGPLtfbAfuXFHR2/TMBz+xW9hw1wrGy62bqTHDiGEzJBcFDYL9IaARmKs0sg8mANJaut+a5noW2Hd",
-	"4Y2sTkPDEMfnE5rQFWoTdX89PBoeeZOoHCXknI7p2+HR8K2PS7DLEGYjYBmXowrNjNQKtT8kdPywSapp",
-	"x7gdCLVojTbKUz2oNIuPCfWQ74R6RkYPnG26bxBf+stATTKanNLN15qVM93twsgBmQdWo7rYHIzj2ljM",
-	"BlrFxP2zbHd6rpqwKh+0fODscjRXeqHsIAdj7pRmzamFUosgUzUi1MI3zo2RIi7bQwYlG6xQ83l50dmZ",
-	"7t8vrFgPMAMuivE0PgmMliorBVkiCFtu2Am98jMatoT4L/FWSFwF90qEDEdlqxB4L9D2PW741GtI1VSE",
-	"TGNIOBgxI5dTJBQOnzV9vU2IxDvfnM25NnZ4I69iL2yUJtwQlcMP55MQI5nzLRzWWd7JdAlyEbmngnu1",
-	"YpKo0s6E0TH9wI39uD6rdEi65ulcZMM9z1xGImIjal5J7lNgsX2V3X84DCW5yM/hhqX1jsRwDk5YOn5z",
-	"FF6RPG86/vUoPCLFj9dJT4/XFetztERhmmap8wk+FHnlDClfd3YJWFyxPvZGlDwZMNuvmfssajwIbr52",
-	"3qHeHB0d7KWjdePd81Jx1o5RXyvexe37uFZijhpvZWHJ66eXtF5yNgn9dZ992u9ZTaQUorWJkb589ZY0",
-	"LsvA988h1EM8NJ5e/EkxqH/ZOpy0hNxfaPEYSb9u4om3mleJUypbpbbesZFGYAMQonfSyTBd3mZsE4Ts",
-	"5WlekLha9Td+jEoc3R59YYXMYR1a3SJ9F7w1Lrix1Va+7AxcXmbj7tBLZdjiNSpbvYMynXOB5vAcRw/F",
-	"LypeKvbTGaf8YccueTK0wMDCC5Vs+vggzi3Ea122vZxdCRAGvpc7DMsyoRyGm+9AX8ApgsxcKx9mhUta",
-	"YyNYgQXdOzUDKbE9FWUK95gvlipC3yJWfpbVHc6WSt2aEVNMFaLeheALJUKv+vHMSbxKD/3gUpnQrWtB",
-	"x3QUynBRArZ63QA8A/BSM88cZlxwuyYoWa64DOWjwBSfqy6PbuOWi5Ab43xSNswmIU3EnIR9SpRMNKYe",
-	"1jdQy3HnlwRbu8T75tjeFjA68iyKQDVYd6SNH0AV1W+b7anTMBNIMicsHxiLec0xphKS8xwFl40G/7rI",
-	"/Nvsrg2G9jgkwihflLti6jt/nZW3LiXD0O31QMJQ0hJSFKWOScv2IBq3bg8qtudFLdslaKtMBybaBwXP",
-	"kBg3a143FQw/tZBCj5MErE1Sw+mk7aI639V+bzwgbF2ruxz1IDSYJAMJCwxG8CxD597r6mNPHuL+fhCB",
-	"1OAJcHU/KLq+NmHnqqikNRYkA80G4Scm4bT/r7Dlu6O3Ty86U3rGGUP502h0898AAAD//w==",
+	"1Fp7bxu3sv8qBO8Fcm+wq3WS9gDH/7lxcuo2DyOO0QMkgTLaHe2y5pIMybWsGvruByT3rZUfkXrQ/iUt",
+	"ORwOh8OZ3wx5S1NZKilQWEOPb6kCDSVa1P7rxBi0Z6fuLxP0mCqwBY2ogBLpMYW6N6Iav1VMY0aPra4w",
+	"oiYtsAQ3bCl1CZYe06piGY2oXSs31FjNRE43m4i+5LLKlhw0vpSVsHrthmVoUs2UZdJN25EQu5IxR2tR",
+	"kzSQk1RmGJFVwdKCGOSYWkNsgSRjRnFYk7TSGkW6Jv+Hs3xGzt6RpdTk7N2HiJy+8v9fXX74/xkJ0zM0",
+	"ZMVsISvruDBN5EoQUykltcWs42YQyeXF6YxGQTcFQoa6087L1/HZebOkvkrwBkrFHcnZOxrREm7eoMht",
+	"QY+fR7Rkovc1oa3A8KXMcFtPr4HzBaRXA9WQVYGC9KUhzBBYGBQ2qhXGRD6tMtdooESygvXOlf47rhnH",
+	"XqyDrfXsdHuJH9DISqdILi/PTmfkJVjgMidcyqtKEeBGEkhTVNYQIKaQ2gYlSE0Mr/J2DUNLZtmdRjwW",
+	"beOIjZLCoD8mP0H2Ab9VaKz7SqWwKPxfUIqzFJzsye/GLeC2x/Z/NS7pMf2fpDuCSeg1ySutpfZasHhj",
+	"E8WBPWL0uSP/iDe2YbOJRno8E9fAWUaYUJWlTtnCohbAw4i/yzIuBd4oTN3BNKivURMMpBG9FFDZQmr2",
+	"B2Z/m/W8Zca4wyg1YfUOuVWgsLW8fu6an5uu3S6lpUJtWbDIDC0wbiaMN6LYDOkOZzOXDlZMFjJbTzrr",
+	"7ox8qvl8acnk4ndMvS29hmupmcUzi6XXd5YxJzzw856US+AGo5Hgy3poNgc7iB8ZWIwtK3Fbrogahel9",
+	"yr9wNOM1DKar+dy1onPI8ZErKsDMS6mxtxcLKTmCcIyZxdKTtX/uWsNAsZtWTtAa1u5b4I2dp5U2YYOH",
+	"tnWu0aCwIR6AkLZATRTk6OPBNTAOC46ze/c9SBp1C5tS2L9QhCUPlZFqhMduLsseACQaVz5h787vT3SM",
+	"V5U14aAeEfWFnVriG5aiMPi+1u8hllrH37nSLMV5KQWu7zOJt55oE9Elgq00Do1pa4axxSwZx7lrfOS4",
+	"fTfFrzDYqNKYgu3i7tBqa1Uw4V0iKeF3qUklmDU06mlVVgveU6moygXqdqIwfupIsBQdW4dzwhyeNZFL",
+	"0tuDJ6ZFRP1JmbD/+KGbkwmL+WDSR+2f8z3zB6o1NNxSFFXpjPcnMCylkTviJatKGtGPGtIrWVnqAiFn",
+	"pfNx9MsEq0pljzTTqYPTCF8Ttyepr/36iw7VM230PXMe2OjgUA6Enzqhb5sNGJ5MKB1cnXtT2raJU0xZ",
+	"CZwEqsY4mv1/Ynom+DALbKabNsGTXdOYEjh3wXg8026za0aPkfeHezdxIGM01FCP75SSR6Bma4G+2SMK",
+	"stLMWhRksSYCbVJYq54Y4n5mnmpGLmSJpACRcdTNobN8TVRlyS8X79/FDuj7bAVvaqUxQzwMi32Tm8eF",
+	"sMdim4ieVwvO0hMBfG1ZarbNpsEKc59d9bxabweYmTdk2XTA96Z+BwcrLfB5JleCS8h2U442sMc2Gku6",
+	"g+lI2qm9vUDQafEzywvO8sKa3aBCCr4mUhDjRxCNpuLWzMiFYEqhy8Y0kp8/vn0To0lBYeaTbFKCTQuX",
+	"cWtQrpEJ8vVzdXT0Ii1BX/l/+HVGoy1425NhKloxy3E65m+vscaOwxmgbwd3YvqR2WwiulDl9N66MJdL",
+	"ve677gUG5BnMdcpDfxeQuEdB+wCNrNLQMB7MQy8wlSIzk75pqRHnpXoxR+Fg5o7TkaMYQ5i7ZAoocwKe",
+	"FAOTvTMtGJv4w8ENKyHHeaX5kFqzSWJhrK7Kps72cKR1FbZmi44H+PlwZQ3x6sRMpZTZI4VTGq8Zrh6q",
+	"hL8G6ruwoH3JS/2F4J/SMkWf/M+NBVuZvpNQKDKnwj4ZjXzplqNTovP5jO+AeErLrEpRPxRdtvS7UypX",
+	"WJunMtvRPZ1xRdRC/kgD2+XJOwx8AEQb0RVco6OcK4SroYgP2O+h0FP4uL8FYwU3i+zFhxZGu1ASXMDw",
+	"hPSdz/AU7oO5W88+4a53I+8p290GE84sMK00s+sLZ/vBcS0QNOqTyhbd1+tG4b/89pGOHcNJ6mYiVl6h",
+	"IBptpQVmDlBymTPh6mYalxpNMWvq0D7CeMbd3jnISf0+edqXDlRO1NN//vjxPPbIpiYkxi1TCpL6EcSg",
+	"beduS8uhrysu12PnXuZOBlDsV3f0Nz44LGVTo4TU2209+De4xjXojLwSOROIkwHeC0pOzs/8bYZzZu0w",
+	"By+Iw1JoFYcUZ5/FZ3FpkDx9etKUR58+JVYSpeU1y5CAINBXMhjy9SevPxKQmW+uoZmfUGlpfQ32s3AQ",
+	"ypuQmZEPtc48fQCAxkodUB4I0mn360BFX2vtzj6L9mz0FHFyfkYjeo3ahLU/mx3NjpxKpEIBitFj+mJ2",
+	"NHvh7BJs4c0sgaxkImlBXSKvUbsjQ49vN1HbXWXMxlzmg1a8SQsQOcYaLJo7upJblm3Gd2ifpt1/R5Kc",
+	"ndLNl45nL6x3jeUSYiU5S9eDZqkzP8lWkxcl0bisRLa3RArWsrLDaTQIl4bFSkuXnd/deRjFTHJNILXs",
+	"Giz+Sewz/JMnqF333tyNwnS4C77lMKqvzNjMfMsBmQdlCGnZsr7oMIdk3CGqg3FcG4tlrCXfyzJ2OqS2",
+	"w0oVDza3skWylDqXNlZgzErqrN+VS5lz7LdwmcvK9lvKJYw+E42pE2EdO1Bnxr1WWpVkzDgoMNmHYmeX",
+	"QVupcc81arZc91vrEDBsMiiy2NPWVjHuntZBHaXNVFui8VpeYeyvP6Yp9jVrxywsMMYSGO9PssKF+xVJ",
+	"qjFDYRlwc1//QQRqGXuwkiwwZ2Jy4tC/ZIKZYpJAY86MRX0Hj5ZkwCYFbXt/k7TAUKPutTWwe9iQ3NYF",
+	"5ik1TNznd+Xo73+Z8iWI4N8XJIUsG+tOZaU626q/9t2iOr9k7dFD0IKJfPw5CsZts7Fg0RcXkttSClts",
+	"aooCgdtmA2psEUuPG5sImmv0Q/cQfwRams/DT1BH5bqQuY9H92Zey9vubYlJUxD1vHO0U09QXNZhSEMZ",
+	"QLYhHnqFZKTpIj5ncgmDSzwjInCFxpIl08bOPouPoeJvXJHDEKngW+Xwd0bKyhWqsUtwKhGQpueecuaW",
+	"FfBxi7jPMnpM3zBj365ft2uIxuoZPTeAG1ZWJQmFE1f2aJfvUoIwfZvYfKtQr7tD5m+VBq99MlxCxS09",
+	"fn7k3/o43vT4xyP/1Cd8PIsmKtljsd4HTdSq6Wd5LrfxkElWhjRvcHYJGMbTu17yRPcazPbztIcM6r3S",
+	"2nwZvRZ6fnR0sPcog3cJE+9JXg9tlG4i+sPR0S6urZhJ70WTH/Ls/iGD9zabiP74kHmGr476RQJvrf3y",
+	"wKcvTpOmKkvQ69rUvT30Hsi4k2JQP9k6nLSpPX2i9ZMx+mUTTrzVrAVXYwC61ebw+hKdq8Yd/ZUpYlMt",
+	"2k14KFmi/EVCHMq92yM0QhYD55OdlfDdzWXRNkGdD8I+2eAg2wwfSVPJGrbuGQwVrEMoG6DEBlPUn+Gi",
+	"KTFVnod3d75RYRpXqokN46Z9BdvilTQV2IMybTLqA3NMbusHu/uKfb//a94N75KnRAsZWNhzkf09Psjm",
+	"1uINLv/2Z9fAlXipEQ/DsnFvh+HmSsF7cAppcV3XqLdk0JbANVjQk10LEAKHXUEm7w73lsrzamzle1kF",
+	"P9QDofW/X3E9ZXHd24M3Zy/jo2f/PFr+uHg+/fK44/SoF8herBUuCimvTJLJTNYabNs0/CG1gsZ7rvxZ",
+	"8fFVXzeCjl7Zh9cWvo5cSOOr/JrTY5p4DFPHz60auU8wPGqVC8ccFowzuyYoMiWZsKZb7Pu2Oky3Qd8H",
+	"799Df9QU2k1E+ul/5OdpUn7SFC26GU5Gj2W3ZgnX9aEsXid3gWcdyNrGrpLde9If+ibYnlbaFUBIWXHL",
+	"YmNRdRyD5yOKKeRM9C4GLutAtc3u0qAm9QkxQb4gd8vU3RjosrmtaRg6k59g996H5YjUgXWk0sasg3K7",
+	"3Kplex6GTXE+b+RpMlHCMctRR6ROVANTn5LyNenS1I77q3rkLjUMgIznpp3JsRJJH0B1DN/1B0zJzGFt",
+	"oi7TiYYG0Dn/zqpE97pj6zK5UqhjXx8kJQjI/fo8S3+fMGlIJ47cn6qbOGDc+B7cexPXBbIh4egCq6E1",
+	"FkQGOov9G23v+v5bsP+Hoxf3D3ot9YJlGYrvThQ2/xkA",
 }
 
 // decodeSpec returns the embedded OpenAPI spec as raw JSON bytes,
@@ -111,8 +111,8 @@ func mapFavoritePage(page *analyticsDomain.FavoritePage, displayCurrency string)
 
 // mapSpec converts a catalog domain Spec into the generated openapi Spec type.
 func mapSpec(s *catalogDomain.Spec, displayCurrency string) Spec {
-	priceMoney := money.DisplayPrice(s.BasePrice, s.PriceCurrency, s.PriceCurrency)
-	displayPriceMoney := money.DisplayPrice(s.BasePrice, s.PriceCurrency, displayCurrency)
+	priceMoney := money.DisplayPrice(s.BasePrice(), s.PriceCurrency)
+	displayPriceMoney := money.DisplayPrice(s.BasePrice(), displayCurrency)
 
 	spec := Spec{
 		Id:           openapi_types.UUID(s.ID),
@@ -126,7 +126,8 @@ func mapSpec(s *catalogDomain.Spec, displayCurrency string) Spec {
 		Description:  s.Description,
 		ImageUrl:     s.ImageUrl,
 		PreviewUrl:   s.PreviewUrl,
-		Price:        s.BasePrice,
+		PriceMinor:   s.BasePriceMinor,
+		Price:        s.BasePrice().Major(),
 		PriceMoney: Money{
 			AmountMinor: int64(priceMoney.AmountMinor),
 			AmountMajor: priceMoney.AmountMajor,
@@ -167,14 +168,15 @@ func mapSpec(s *catalogDomain.Spec, displayCurrency string) Spec {
 	if len(s.Licenses) > 0 {
 		licenses := make([]LicenseOption, 0, len(s.Licenses))
 		for _, l := range s.Licenses {
-			lPriceMoney := money.DisplayPrice(l.Price, l.PriceCurrency, l.PriceCurrency)
-			lDisplayPriceMoney := money.DisplayPrice(l.Price, l.PriceCurrency, displayCurrency)
+			lPriceMoney := money.DisplayPrice(l.Price(), l.PriceCurrency)
+			lDisplayPriceMoney := money.DisplayPrice(l.Price(), displayCurrency)
 			licenses = append(licenses, LicenseOption{
-				Id:     openapi_types.UUID(l.ID),
-				SpecId: openapi_types.UUID(l.SpecID),
-				Type:   LicenseOptionType(l.LicenseType),
-				Name:   l.Name,
-				Price:  l.Price,
+				Id:         openapi_types.UUID(l.ID),
+				SpecId:     openapi_types.UUID(l.SpecID),
+				Type:       LicenseOptionType(l.LicenseType),
+				Name:       l.Name,
+				PriceMinor: l.PriceMinor,
+				Price:      l.Price().Major(),
 				PriceMoney: Money{
 					AmountMinor: int64(lPriceMoney.AmountMinor),
 					AmountMajor: lPriceMoney.AmountMajor,
@@ -210,14 +212,14 @@ func mapSpec(s *catalogDomain.Spec, displayCurrency string) Spec {
 	return spec
 }
 
-// resolveDisplayCurrency determines the display currency from the request headers.
-// IN country code → INR; everything else → USD.
+// resolveDisplayCurrency determines the display currency from the request
+// headers, preferring Cloudflare's country over X-Country-Code.
 func resolveDisplayCurrency(cfCountry *CloudflareCountry, xCountry *CountryCode) string {
-	if cfCountry != nil && *cfCountry == "IN" {
-		return money.CurrencyINR
+	if cfCountry != nil && *cfCountry != "" {
+		return money.CurrencyForCountry(*cfCountry)
 	}
-	if xCountry != nil && *xCountry == "IN" {
-		return money.CurrencyINR
+	if xCountry != nil {
+		return money.CurrencyForCountry(*xCountry)
 	}
 	return money.CurrencyUSD
 }
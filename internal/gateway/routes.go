@@ -13,6 +13,8 @@ import (
 	authDomain "github.com/saransh1220/blueprint-audio/internal/modules/auth/domain"
 	auth_http "github.com/saransh1220/blueprint-audio/internal/modules/auth/interfaces/http"
 	catalog_http "github.com/saransh1220/blueprint-audio/internal/modules/catalog/interfaces/http"
+	currency_http "github.com/saransh1220/blueprint-audio/internal/modules/currency/interfaces/http"
+	earnings_http "github.com/saransh1220/blueprint-audio/internal/modules/earnings/interfaces/http"
 	notification_http "github.com/saransh1220/blueprint-audio/internal/modules/notification/interfaces/http"
 	payment_http "github.com/saransh1220/blueprint-audio/internal/modules/payment/interfaces/http"
 	user_http "github.com/saransh1220/blueprint-audio/internal/modules/user/interfaces/http"
@@ -24,11 +26,14 @@ type RouterConfig struct {
 	AuthMiddleware      *middleware.AuthMiddleWare
 	SpecHandler         *catalog_http.SpecHandler
 	SpecUploadHandler   *catalog_http.SpecUploadHandler
+	RankingHandler      *catalog_http.RankingProfileHandler
 	UserHandler         *user_http.UserHandler
 	PaymentHandler      *payment_http.PaymentHandler
 	AnalyticsHandler    *analytics_http.AnalyticsHandler
 	NotificationHandler *notification_http.NotificationHandler
 	AdminHandler        *admin_http.AdminHandler
+	EarningsHandler     *earnings_http.EarningsHandler
+	CurrencyHandler     *currency_http.CurrencyHandler
 	// FavoritesServer implements the oapi-codegen StrictServerInterface for /me/favorites.
 	FavoritesServer *openapi.FavoritesServer
 	DisableAPIDocs  bool
@@ -73,6 +78,8 @@ func SetupRoutes(config RouterConfig) *http.ServeMux {
 
 	// Auth Routes
 	emailActionLimiter := middleware.RateLimitMiddleware(3, 15*time.Minute)
+	mfaLimiter := middleware.RateLimitMiddleware(5, 15*time.Minute)
+	passkeyLimiter := middleware.RateLimitMiddleware(10, 15*time.Minute)
 
 	mux.HandleFunc("POST /register", config.AuthHandler.Register)
 	mux.HandleFunc("POST /login", config.AuthHandler.Login)
@@ -83,15 +90,32 @@ func SetupRoutes(config RouterConfig) *http.ServeMux {
 	mux.HandleFunc("POST /auth/resend-verification", emailActionLimiter(config.AuthHandler.ResendVerification))
 	mux.HandleFunc("POST /auth/forgot-password", emailActionLimiter(config.AuthHandler.ForgotPassword))
 	mux.HandleFunc("POST /auth/reset-password", emailActionLimiter(config.AuthHandler.ResetPassword))
+	mux.HandleFunc("POST /auth/mfa/verify", mfaLimiter(config.AuthHandler.VerifyMFA))
+	mux.HandleFunc("POST /auth/webauthn/login/begin", passkeyLimiter(config.AuthHandler.BeginPasskeyLogin))
+	mux.HandleFunc("POST /auth/webauthn/login/finish", passkeyLimiter(config.AuthHandler.FinishPasskeyLogin))
 	mux.Handle("GET /me", config.AuthMiddleware.RequireAuth(http.HandlerFunc(config.AuthHandler.Me)))
+	mux.Handle("GET /auth/sessions", config.AuthMiddleware.RequireAuth(http.HandlerFunc(config.AuthHandler.ListSessions)))
+	mux.Handle("DELETE /auth/sessions/{id}", config.AuthMiddleware.RequireAuth(http.HandlerFunc(config.AuthHandler.RevokeSession)))
+	mux.Handle("POST /auth/sessions/revoke-others", config.AuthMiddleware.RequireAuth(http.HandlerFunc(config.AuthHandler.RevokeOtherSessions)))
+	mux.Handle("GET /auth/mfa", config.AuthMiddleware.RequireAuth(http.HandlerFunc(config.AuthHandler.MFAStatus)))
+	mux.Handle("POST /auth/mfa/totp/setup", config.AuthMiddleware.RequireAuth(http.HandlerFunc(config.AuthHandler.SetupTOTP)))
+	mux.Handle("POST /auth/mfa/totp/enable", config.AuthMiddleware.RequireAuth(http.HandlerFunc(config.AuthHandler.EnableTOTP)))
+	mux.Handle("POST /auth/mfa/totp/disable", config.AuthMiddleware.RequireAuth(http.HandlerFunc(config.AuthHandler.DisableTOTP)))
+	mux.Handle("POST /auth/mfa/recovery-codes", config.AuthMiddleware.RequireAuth(http.HandlerFunc(config.AuthHandler.RegenerateRecoveryCodes)))
+	mux.Handle("POST /auth/webauthn/register/begin", config.AuthMiddleware.RequireAuth(http.HandlerFunc(config.AuthHandler.BeginPasskeyRegistration)))
+	mux.Handle("POST /auth/webauthn/register/finish", config.AuthMiddleware.RequireAuth(http.HandlerFunc(config.AuthHandler.FinishPasskeyRegistration)))
+	mux.Handle("GET /auth/webauthn/credentials", config.AuthMiddleware.RequireAuth(http.HandlerFunc(config.AuthHandler.ListPasskeys)))
+	mux.Handle("DELETE /auth/webauthn/credentials/{id}", config.AuthMiddleware.RequireAuth(http.HandlerFunc(config.AuthHandler.DeletePasskey)))
+
+	producerOnly := []authDomain.UserRole{authDomain.RoleProducer}
 
 	// Catalog/Spec Routes
 	mux.Handle("GET /catalog/home", config.AuthMiddleware.FlexibleAuth(http.HandlerFunc(config.SpecHandler.Home)))
 	mux.Handle("GET /specs", config.AuthMiddleware.FlexibleAuth(http.HandlerFunc(config.SpecHandler.List)))
 	mux.Handle("GET /specs/{id}", config.AuthMiddleware.FlexibleAuth(http.HandlerFunc(config.SpecHandler.Get)))
+	mux.HandleFunc("GET /search/suggest", config.SpecHandler.Suggest)
 	mux.Handle("POST /specs", config.AuthMiddleware.RequireAuth(http.HandlerFunc(config.SpecHandler.CreateGone)))
 	if config.SpecUploadHandler != nil {
-		producerOnly := []authDomain.UserRole{authDomain.RoleProducer}
 		mux.Handle("POST /spec-uploads", config.AuthMiddleware.RequireUserRole(producerOnly, http.HandlerFunc(config.SpecUploadHandler.Initiate)))
 		mux.Handle("PUT /spec-uploads/{id}/metadata", config.AuthMiddleware.RequireUserRole(producerOnly, http.HandlerFunc(config.SpecUploadHandler.SaveMetadata)))
 		mux.Handle("POST /spec-uploads/{id}/files", config.AuthMiddleware.RequireUserRole(producerOnly, http.HandlerFunc(config.SpecUploadHandler.PrepareFile)))
@@ -116,15 +140,64 @@ func SetupRoutes(config RouterConfig) *http.ServeMux {
 	mux.Handle("GET /orders/{id}", config.AuthMiddleware.RequireAuth(http.HandlerFunc(config.PaymentHandler.GetOrder)))
 	mux.Handle("POST /payments/verify", config.AuthMiddleware.RequireAuth(http.HandlerFunc(config.PaymentHandler.VerifyPayment)))
 	mux.HandleFunc("POST /webhooks/dodo", config.PaymentHandler.DodoWebhook)
+	mux.HandleFunc("POST /webhooks/razorpay", config.PaymentHandler.RazorpayWebhook)
 	mux.Handle("GET /licenses", config.AuthMiddleware.RequireAuth(http.HandlerFunc(config.PaymentHandler.GetUserLicenses)))
 	mux.Handle("GET /licenses/{id}/downloads", config.AuthMiddleware.RequireAuth(http.HandlerFunc(config.PaymentHandler.GetLicenseDownloads)))
+	mux.Handle("GET /licenses/{id}/agreement", config.AuthMiddleware.RequireAuth(http.HandlerFunc(config.PaymentHandler.GetLicenseAgreement)))
+	// License verification is public and printed on agreements. It lives under
+	// /verify because GET /licenses/verify/{licenseKey} would conflict with
+	// GET /licenses/{id}/downloads in the mux.
+	licenseVerifyLimiter := middleware.RateLimitMiddleware(30, time.Minute)
+	mux.HandleFunc("GET /verify/licenses/{licenseKey}", licenseVerifyLimiter(config.PaymentHandler.VerifyLicense))
 	mux.Handle("GET /orders/producer", config.AuthMiddleware.RequireAuth(http.HandlerFunc(config.PaymentHandler.GetProducerOrders)))
+	mux.Handle("GET /cart", config.AuthMiddleware.RequireAuth(http.HandlerFunc(config.PaymentHandler.GetCart)))
+	mux.Handle("DELETE /cart", config.AuthMiddleware.RequireAuth(http.HandlerFunc(config.PaymentHandler.ClearCart)))
+	mux.Handle("POST /cart/items", config.AuthMiddleware.RequireAuth(http.HandlerFunc(config.PaymentHandler.AddCartItem)))
+	mux.Handle("DELETE /cart/items/{spec_id}", config.AuthMiddleware.RequireAuth(http.HandlerFunc(config.PaymentHandler.RemoveCartItem)))
+	mux.Handle("POST /cart/checkout", config.AuthMiddleware.RequireAuth(http.HandlerFunc(config.PaymentHandler.Checkout)))
+	mux.Handle("POST /coupons", config.AuthMiddleware.RequireUserRole(producerOnly, http.HandlerFunc(config.PaymentHandler.CreateCoupon)))
+	mux.Handle("GET /coupons", config.AuthMiddleware.RequireUserRole(producerOnly, http.HandlerFunc(config.PaymentHandler.ListCoupons)))
+	mux.Handle("PATCH /coupons/{id}", config.AuthMiddleware.RequireUserRole(producerOnly, http.HandlerFunc(config.PaymentHandler.UpdateCoupon)))
+	mux.Handle("DELETE /coupons/{id}", config.AuthMiddleware.RequireUserRole(producerOnly, http.HandlerFunc(config.PaymentHandler.DeleteCoupon)))
+	mux.Handle("GET /license-options/{id}/agreement", config.AuthMiddleware.RequireUserRole(producerOnly, http.HandlerFunc(config.PaymentHandler.GetAgreementTemplate)))
+	mux.Handle("PUT /license-options/{id}/agreement", config.AuthMiddleware.RequireUserRole(producerOnly, http.HandlerFunc(config.PaymentHandler.UpdateAgreementTemplate)))
+
+	// Earnings Routes
+	if config.EarningsHandler != nil {
+		mux.Handle("GET /earnings", config.AuthMiddleware.RequireUserRole(producerOnly, http.HandlerFunc(config.EarningsHandler.GetSummary)))
+		mux.Handle("GET /earnings/payouts", config.AuthMiddleware.RequireUserRole(producerOnly, http.HandlerFunc(config.EarningsHandler.ListPayouts)))
+		mux.Handle("GET /earnings/statements/{month}", config.AuthMiddleware.RequireUserRole(producerOnly, http.HandlerFunc(config.EarningsHandler.GetStatement)))
+		mux.Handle("GET /admin/payouts", config.AuthMiddleware.RequirePermission(middleware.PermissionSuperAdmin, http.HandlerFunc(config.EarningsHandler.AdminListPayouts)))
+		mux.Handle("POST /admin/payouts", config.AuthMiddleware.RequirePermission(middleware.PermissionSuperAdmin, http.HandlerFunc(config.EarningsHandler.AdminCreatePayout)))
+	}
+
+	// Ranking Profile Routes
+	if config.RankingHandler != nil {
+		mux.Handle("GET /admin/ranking-profiles", config.AuthMiddleware.RequirePermission(middleware.PermissionSuperAdmin, http.HandlerFunc(config.RankingHandler.AdminListProfiles)))
+		mux.Handle("POST /admin/ranking-profiles", config.AuthMiddleware.RequirePermission(middleware.PermissionSuperAdmin, http.HandlerFunc(config.RankingHandler.AdminCreateProfile)))
+		mux.Handle("GET /admin/ranking-profiles/{id}", config.AuthMiddleware.RequirePermission(middleware.PermissionSuperAdmin, http.HandlerFunc(config.RankingHandler.AdminGetProfile)))
+		mux.Handle("GET /admin/ranking-profiles/{id}/preview", config.AuthMiddleware.RequirePermission(middleware.PermissionSuperAdmin, http.HandlerFunc(config.RankingHandler.AdminPreviewProfile)))
+		mux.Handle("POST /admin/ranking-profiles/{id}/activate", config.AuthMiddleware.RequirePermission(middleware.PermissionSuperAdmin, http.HandlerFunc(config.RankingHandler.AdminActivateProfile)))
+		mux.Handle("POST /admin/ranking-profiles/{id}/deactivate", config.AuthMiddleware.RequirePermission(middleware.PermissionSuperAdmin, http.HandlerFunc(config.RankingHandler.AdminDeactivateProfile)))
+	}
+
+	// Currency Routes
+	if config.CurrencyHandler != nil {
+		mux.HandleFunc("GET /currencies", config.CurrencyHandler.ListCurrencies)
+		mux.Handle("GET /admin/exchange-rates", config.AuthMiddleware.RequirePermission(middleware.PermissionSuperAdmin, http.HandlerFunc(config.CurrencyHandler.AdminListSnapshots)))
+		mux.Handle("GET /admin/exchange-rates/{id}", config.AuthMiddleware.RequirePermission(middleware.PermissionSuperAdmin, http.HandlerFunc(config.CurrencyHandler.AdminGetSnapshot)))
+	}
 
 	// Notification Routes
 	mux.Handle("GET /notifications", config.AuthMiddleware.RequireAuth(http.HandlerFunc(config.NotificationHandler.ListNotifications)))
 	mux.Handle("PATCH /notifications/{id}/read", config.AuthMiddleware.RequireAuth(http.HandlerFunc(config.NotificationHandler.MarkAsRead)))
 	mux.Handle("PATCH /notifications/read-all", config.AuthMiddleware.RequireAuth(http.HandlerFunc(config.NotificationHandler.MarkAllAsRead)))
 	mux.Handle("GET /notifications/unread-count", config.AuthMiddleware.RequireAuth(http.HandlerFunc(config.NotificationHandler.UnreadCount)))
+	mux.Handle("GET /notifications/preferences", config.AuthMiddleware.RequireAuth(http.HandlerFunc(config.NotificationHandler.GetPreferences)))
+	mux.Handle("PUT /notifications/preferences", config.AuthMiddleware.RequireAuth(http.HandlerFunc(config.NotificationHandler.UpdatePreferences)))
+	mux.Handle("GET /notifications/push-subscriptions/public-key", config.AuthMiddleware.RequireAuth(http.HandlerFunc(config.NotificationHandler.PushPublicKey)))
+	mux.Handle("POST /notifications/push-subscriptions", config.AuthMiddleware.RequireAuth(http.HandlerFunc(config.NotificationHandler.SubscribePush)))
+	mux.Handle("DELETE /notifications/push-subscriptions", config.AuthMiddleware.RequireAuth(http.HandlerFunc(config.NotificationHandler.UnsubscribePush)))
 	mux.Handle("GET /ws", config.AuthMiddleware.RequireAuth(http.HandlerFunc(config.NotificationHandler.Subscribe)))
 
 	// Analytics Routes
@@ -140,13 +213,17 @@ func SetupRoutes(config RouterConfig) *http.ServeMux {
 		mux.Handle("GET /admin/users/{id}", config.AuthMiddleware.RequirePermission(middleware.PermissionSuperAdmin, http.HandlerFunc(config.AdminHandler.GetUser)))
 		mux.Handle("PATCH /admin/users/{id}/system-role", config.AuthMiddleware.RequirePermission(middleware.PermissionSuperAdmin, http.HandlerFunc(config.AdminHandler.UpdateUserSystemRole)))
 		mux.Handle("PATCH /admin/users/{id}/status", config.AuthMiddleware.RequirePermission(middleware.PermissionSuperAdmin, http.HandlerFunc(config.AdminHandler.UpdateUserStatus)))
+		mux.Handle("POST /admin/users/{id}/notifications", config.AuthMiddleware.RequirePermission(middleware.PermissionSuperAdmin, http.HandlerFunc(config.NotificationHandler.SendAdminMessage)))
 		mux.Handle("GET /admin/specs", config.AuthMiddleware.RequirePermission(middleware.PermissionSuperAdmin, http.HandlerFunc(config.AdminHandler.ListSpecs)))
 		mux.Handle("PATCH /admin/specs/{id}", config.AuthMiddleware.RequirePermission(middleware.PermissionSuperAdmin, http.HandlerFunc(config.AdminHandler.UpdateSpec)))
 		mux.Handle("DELETE /admin/specs/{id}", config.AuthMiddleware.RequirePermission(middleware.PermissionSuperAdmin, http.HandlerFunc(config.AdminHandler.DeleteSpec)))
 		mux.Handle("GET /admin/orders", config.AuthMiddleware.RequirePermission(middleware.PermissionSuperAdmin, http.HandlerFunc(config.AdminHandler.ListOrders)))
+		mux.Handle("POST /admin/orders/{id}/refund", config.AuthMiddleware.RequirePermission(middleware.PermissionSuperAdmin, http.HandlerFunc(config.PaymentHandler.RefundOrder)))
 		mux.Handle("GET /admin/licenses", config.AuthMiddleware.RequirePermission(middleware.PermissionSuperAdmin, http.HandlerFunc(config.AdminHandler.ListLicenses)))
 		mux.Handle("GET /admin/analytics/overview", config.AuthMiddleware.RequirePermission(middleware.PermissionSuperAdmin, http.HandlerFunc(config.AdminHandler.AnalyticsOverview)))
 		mux.Handle("GET /admin/audit-log", config.AuthMiddleware.RequirePermission(middleware.PermissionSuperAdmin, http.HandlerFunc(config.AdminHandler.ListAuditLog)))
+		mux.Handle("GET /admin/mfa-policy", config.AuthMiddleware.RequirePermission(middleware.PermissionSuperAdmin, http.HandlerFunc(config.AdminHandler.ListMFAPolicies)))
+		mux.Handle("PUT /admin/mfa-policy", config.AuthMiddleware.RequirePermission(middleware.PermissionSuperAdmin, http.HandlerFunc(config.AdminHandler.UpdateMFAPolicy)))
 	}
 
 	return mux
@@ -10,6 +10,8 @@ import (
 	analytics_http "github.com/saransh1220/blueprint-audio/internal/modules/analytics/interfaces/http"
 	auth_http "github.com/saransh1220/blueprint-audio/internal/modules/auth/interfaces/http"
 	catalog_http "github.com/saransh1220/blueprint-audio/internal/modules/catalog/interfaces/http"
+	currency_http "github.com/saransh1220/blueprint-audio/internal/modules/currency/interfaces/http"
+	earnings_http "github.com/saransh1220/blueprint-audio/internal/modules/earnings/interfaces/http"
 	notification_http "github.com/saransh1220/blueprint-audio/internal/modules/notification/interfaces/http"
 	payment_http "github.com/saransh1220/blueprint-audio/internal/modules/payment/interfaces/http"
 	user_http "github.com/saransh1220/blueprint-audio/internal/modules/user/interfaces/http"
@@ -24,7 +26,9 @@ func TestSetupRoutesRegistersAdminRoutes(t *testing.T) {
 		PaymentHandler:      &payment_http.PaymentHandler{},
 		AnalyticsHandler:    &analytics_http.AnalyticsHandler{},
 		NotificationHandler: &notification_http.NotificationHandler{},
-		AdminHandler:        admin_http.NewAdminHandler(nil, nil),
+		AdminHandler:        admin_http.NewAdminHandler(nil, nil, nil),
+		EarningsHandler:     earnings_http.NewEarningsHandler(nil),
+		CurrencyHandler:     currency_http.NewCurrencyHandler(nil),
 	})
 
 	for _, path := range []string{
@@ -34,6 +38,11 @@ func TestSetupRoutesRegistersAdminRoutes(t *testing.T) {
 		"/admin/licenses",
 		"/admin/analytics/overview",
 		"/admin/audit-log",
+		"/admin/payouts",
+		"/admin/exchange-rates",
+		"/earnings",
+		"/earnings/payouts",
+		"/earnings/statements/2026-05",
 	} {
 		req := httptest.NewRequest(http.MethodGet, path, nil)
 		response := httptest.NewRecorder()
@@ -30,7 +30,7 @@ func TestSpecUploadRoutesRequireProducer(t *testing.T) {
 		PaymentHandler:      &payment_http.PaymentHandler{},
 		AnalyticsHandler:    &analytics_http.AnalyticsHandler{},
 		NotificationHandler: &notification_http.NotificationHandler{},
-		AdminHandler:        admin_http.NewAdminHandler(nil, nil),
+		AdminHandler:        admin_http.NewAdminHandler(nil, nil, nil),
 	})
 
 	artistToken, err := utils.GenerateToken(
@@ -0,0 +1,142 @@
+// Package jobs registers the platform's recurring background jobs with the
+// scheduler. Schedules are cron expressions evaluated in UTC.
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	catalogApplication "github.com/saransh1220/blueprint-audio/internal/modules/catalog/application"
+	catalogDomain "github.com/saransh1220/blueprint-audio/internal/modules/catalog/domain"
+	notificationApplication "github.com/saransh1220/blueprint-audio/internal/modules/notification/application"
+	paymentApplication "github.com/saransh1220/blueprint-audio/internal/modules/payment/application"
+	"github.com/saransh1220/blueprint-audio/internal/shared/infrastructure/scheduler"
+)
+
+const (
+	JobTrendingRankings  = "rankings.trending"
+	JobTopChartsRankings = "rankings.top_charts"
+	JobExpireUploads     = "uploads.expire_sessions"
+	JobPurgeUploads      = "uploads.purge_orphaned_objects"
+	JobExpireOrders      = "orders.expire"
+	JobSendDigests       = "notifications.digests"
+	JobRecommendations   = "recommendations.for_you"
+)
+
+// Dependencies are the services the jobs call. Jobs whose dependency is nil
+// are not registered.
+type Dependencies struct {
+	Rankings        catalogApplication.RankingProfileService
+	Recommendations *catalogApplication.Recommender
+	Uploads         *catalogApplication.SpecUploadProcessor
+	Orders          *paymentApplication.OrderExpirer
+	Notifications   *notificationApplication.NotificationService
+	// DigestInterval is how often due digests are looked for.
+	DigestInterval time.Duration
+}
+
+// Register adds the jobs to s.
+func Register(s *scheduler.Scheduler, deps Dependencies) error {
+	var jobs []scheduler.Job
+	if deps.Rankings != nil {
+		jobs = append(jobs,
+			scheduler.Job{
+				Name:     JobTrendingRankings,
+				Schedule: "*/15 * * * *",
+				Run: func(ctx context.Context) error {
+					return deps.Rankings.RefreshSection(ctx, catalogDomain.HomeSectionTrending)
+				},
+			},
+			scheduler.Job{
+				Name:     JobTopChartsRankings,
+				Schedule: "5 * * * *",
+				Run: func(ctx context.Context) error {
+					return deps.Rankings.RefreshSection(ctx, catalogDomain.HomeSectionTopCharts)
+				},
+			},
+		)
+	}
+	if deps.Recommendations != nil {
+		jobs = append(jobs, scheduler.Job{
+			Name:     JobRecommendations,
+			Schedule: "40 * * * *",
+			Timeout:  30 * time.Minute,
+			Run: func(ctx context.Context) error {
+				refreshed, err := deps.Recommendations.RefreshRecommendations(ctx)
+				if refreshed > 0 {
+					log.Printf("refreshed recommendations of %d users", refreshed)
+				}
+				return err
+			},
+		})
+	}
+	if deps.Uploads != nil {
+		jobs = append(jobs,
+			scheduler.Job{
+				Name:     JobExpireUploads,
+				Schedule: "*/5 * * * *",
+				Timeout:  time.Minute,
+				Run: func(ctx context.Context) error {
+					expired, err := deps.Uploads.ExpireUploads(ctx)
+					if expired > 0 {
+						log.Printf("expired %d abandoned upload sessions", expired)
+					}
+					return err
+				},
+			},
+			scheduler.Job{
+				Name:     JobPurgeUploads,
+				Schedule: "20 * * * *",
+				Timeout:  30 * time.Minute,
+				Run: func(ctx context.Context) error {
+					purged, err := deps.Uploads.PurgeOrphanedObjects(ctx)
+					if purged > 0 {
+						log.Printf("purged objects of %d expired or failed uploads", purged)
+					}
+					return err
+				},
+			},
+		)
+	}
+	if deps.Orders != nil {
+		jobs = append(jobs, scheduler.Job{
+			Name:     JobExpireOrders,
+			Schedule: "*/10 * * * *",
+			Timeout:  time.Minute,
+			Run: func(ctx context.Context) error {
+				expired, err := deps.Orders.ExpireOrders(ctx)
+				if expired > 0 {
+					log.Printf("failed %d expired orders", expired)
+				}
+				return err
+			},
+		})
+	}
+	if deps.Notifications != nil {
+		interval := deps.DigestInterval
+		if interval <= 0 {
+			interval = time.Hour
+		}
+		jobs = append(jobs, scheduler.Job{
+			Name:     JobSendDigests,
+			Schedule: "@every " + interval.String(),
+			Timeout:  30 * time.Minute,
+			Run: func(ctx context.Context) error {
+				sent, err := deps.Notifications.SendDigests(ctx, time.Now())
+				if sent > 0 {
+					log.Printf("sent %d notification digests", sent)
+				}
+				return err
+			},
+		})
+	}
+
+	for _, job := range jobs {
+		if err := s.Add(job); err != nil {
+			return fmt.Errorf("register job: %w", err)
+		}
+	}
+	return nil
+}
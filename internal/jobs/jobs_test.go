@@ -0,0 +1,29 @@
+package jobs_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/saransh1220/blueprint-audio/internal/jobs"
+	catalogApplication "github.com/saransh1220/blueprint-audio/internal/modules/catalog/application"
+	notificationApplication "github.com/saransh1220/blueprint-audio/internal/modules/notification/application"
+	paymentApplication "github.com/saransh1220/blueprint-audio/internal/modules/payment/application"
+	"github.com/saransh1220/blueprint-audio/internal/shared/infrastructure/scheduler"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegister(t *testing.T) {
+	deps := jobs.Dependencies{
+		Rankings:        catalogApplication.NewRankingProfileService(nil),
+		Recommendations: catalogApplication.NewRecommender(nil),
+		Uploads:         catalogApplication.NewSpecUploadProcessor(nil, nil, nil),
+		Orders:          paymentApplication.NewOrderExpirer(nil),
+		Notifications:   &notificationApplication.NotificationService{},
+	}
+	require.NoError(t, jobs.Register(scheduler.New(nil, "test"), deps))
+	require.NoError(t, jobs.Register(scheduler.New(nil, "test"), jobs.Dependencies{}))
+
+	deps.DigestInterval = 10 * time.Second
+	assert.ErrorContains(t, jobs.Register(scheduler.New(nil, "test"), deps), jobs.JobSendDigests)
+}
@@ -14,11 +14,18 @@ import (
 	"github.com/jmoiron/sqlx"
 	"github.com/saransh1220/blueprint-audio/internal/gateway/middleware"
 	authDomain "github.com/saransh1220/blueprint-audio/internal/modules/auth/domain"
+	"github.com/saransh1220/blueprint-audio/internal/shared/clientinfo"
 )
 
+// SessionRevoker signs a user out everywhere.
+type SessionRevoker interface {
+	RevokeUserSessions(ctx context.Context, userID uuid.UUID) error
+}
+
 type AdminHandler struct {
 	db       *sqlx.DB
 	userRepo authDomain.UserRepository
+	sessions SessionRevoker
 }
 
 type adminUser struct {
@@ -34,6 +41,13 @@ type adminUser struct {
 	UpdatedAt     time.Time             `json:"updated_at" db:"updated_at"`
 }
 
+type mfaPolicy struct {
+	SystemRole  authDomain.SystemRole `json:"system_role" db:"system_role"`
+	MFARequired bool                  `json:"mfa_required" db:"mfa_required"`
+	UpdatedBy   *uuid.UUID            `json:"updated_by" db:"updated_by"`
+	UpdatedAt   *time.Time            `json:"updated_at" db:"updated_at"`
+}
+
 type auditLog struct {
 	ID           uuid.UUID       `json:"id" db:"id"`
 	ActorID      *uuid.UUID      `json:"actor_id" db:"actor_id"`
@@ -48,8 +62,10 @@ type auditLog struct {
 	CreatedAt    time.Time       `json:"created_at" db:"created_at"`
 }
 
-func NewAdminHandler(db *sqlx.DB, userRepo authDomain.UserRepository) *AdminHandler {
-	return &AdminHandler{db: db, userRepo: userRepo}
+// NewAdminHandler creates the admin handler. Suspended users are signed out
+// through sessions, if set.
+func NewAdminHandler(db *sqlx.DB, userRepo authDomain.UserRepository, sessions SessionRevoker) *AdminHandler {
+	return &AdminHandler{db: db, userRepo: userRepo, sessions: sessions}
 }
 
 func (h *AdminHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
@@ -170,6 +186,15 @@ func (h *AdminHandler) UpdateUserStatus(w http.ResponseWriter, r *http.Request)
 			return
 		}
 	}
+	// Sign the user out first so a failure leaves them active rather than
+	// suspended with working tokens. Suspended users cannot sign in or
+	// refresh again once the status is stored.
+	if req.Status == authDomain.UserStatusSuspended && h.sessions != nil {
+		if err := h.sessions.RevokeUserSessions(r.Context(), id); err != nil {
+			http.Error(w, `{"error":"failed to sign out user"}`, http.StatusInternalServerError)
+			return
+		}
+	}
 	if err := h.userRepo.UpdateStatus(r.Context(), id, req.Status); err != nil {
 		http.Error(w, `{"error":"failed to update status"}`, http.StatusInternalServerError)
 		return
@@ -182,7 +207,7 @@ func (h *AdminHandler) UpdateUserStatus(w http.ResponseWriter, r *http.Request)
 func (h *AdminHandler) ListSpecs(w http.ResponseWriter, r *http.Request) {
 	limit, offset := pagination(r, 50, 100)
 	var specs []map[string]any
-	query := `SELECT s.id, s.producer_id, u.email AS producer_email, COALESCE(u.display_name, u.name) AS producer_name, s.title, s.category, s.base_price, s.processing_status, s.is_deleted, s.created_at, s.updated_at FROM specs s JOIN users u ON s.producer_id = u.id ORDER BY s.created_at DESC LIMIT $1 OFFSET $2`
+	query := `SELECT s.id, s.producer_id, u.email AS producer_email, COALESCE(u.display_name, u.name) AS producer_name, s.title, s.category, s.base_price_minor, s.price_currency, s.base_price_minor::NUMERIC / CASE s.price_currency WHEN 'JPY' THEN 1 ELSE 100 END AS base_price, s.processing_status, s.is_deleted, s.created_at, s.updated_at FROM specs s JOIN users u ON s.producer_id = u.id ORDER BY s.created_at DESC LIMIT $1 OFFSET $2`
 	rows, err := h.db.QueryxContext(r.Context(), query, limit, offset)
 	if err != nil {
 		http.Error(w, `{"error":"failed to list specs"}`, http.StatusInternalServerError)
@@ -210,9 +235,10 @@ func (h *AdminHandler) UpdateSpec(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	var req struct {
-		Title     *string  `json:"title"`
-		BasePrice *float64 `json:"base_price"`
-		IsDeleted *bool    `json:"is_deleted"`
+		Title          *string  `json:"title"`
+		BasePriceMinor *int64   `json:"base_price_minor"`
+		BasePrice      *float64 `json:"base_price"` // legacy, major units
+		IsDeleted      *bool    `json:"is_deleted"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, `{"error":"invalid request body"}`, http.StatusBadRequest)
@@ -228,8 +254,10 @@ func (h *AdminHandler) UpdateSpec(w http.ResponseWriter, r *http.Request) {
 	if req.Title != nil {
 		title = *req.Title
 	}
-	var basePrice any
-	if req.BasePrice != nil {
+	var basePriceMinor, basePrice any
+	if req.BasePriceMinor != nil {
+		basePriceMinor = *req.BasePriceMinor
+	} else if req.BasePrice != nil {
 		basePrice = *req.BasePrice
 	}
 	var isDeleted any
@@ -241,12 +269,13 @@ func (h *AdminHandler) UpdateSpec(w http.ResponseWriter, r *http.Request) {
 		r.Context(),
 		`UPDATE specs
 		 SET title = COALESCE($1, title),
-		     base_price = COALESCE($2, base_price),
-		     is_deleted = COALESCE($3, is_deleted),
-		     deleted_at = CASE WHEN COALESCE($3, is_deleted) THEN COALESCE(deleted_at, NOW()) ELSE NULL END,
+		     base_price_minor = COALESCE($2, ROUND($3::NUMERIC * CASE price_currency WHEN 'JPY' THEN 1 ELSE 100 END), base_price_minor),
+		     is_deleted = COALESCE($4, is_deleted),
+		     deleted_at = CASE WHEN COALESCE($4, is_deleted) THEN COALESCE(deleted_at, NOW()) ELSE NULL END,
 		     updated_at = NOW()
-		 WHERE id = $4`,
+		 WHERE id = $5`,
 		title,
+		basePriceMinor,
 		basePrice,
 		isDeleted,
 		id,
@@ -291,7 +320,8 @@ func (h *AdminHandler) DeleteSpec(w http.ResponseWriter, r *http.Request) {
 func (h *AdminHandler) ListOrders(w http.ResponseWriter, r *http.Request) {
 	limit, offset := pagination(r, 50, 100)
 	rows := []map[string]any{}
-	query := `SELECT o.id, o.user_id, u.email AS buyer_email, o.spec_id, s.title AS spec_title, o.license_type, o.amount, o.currency, o.status, o.created_at FROM orders o JOIN users u ON o.user_id = u.id JOIN specs s ON o.spec_id = s.id ORDER BY o.created_at DESC LIMIT $1 OFFSET $2`
+	// Cart orders have no spec_id of their own; their title is the order summary.
+	query := `SELECT o.id, o.user_id, u.email AS buyer_email, o.spec_id, COALESCE(s.title, o.notes->>'spec_title') AS spec_title, o.license_type, (SELECT COUNT(*) FROM order_items oi WHERE oi.order_id = o.id) AS item_count, o.amount, o.currency, o.status, o.created_at FROM orders o JOIN users u ON o.user_id = u.id LEFT JOIN specs s ON o.spec_id = s.id ORDER BY o.created_at DESC LIMIT $1 OFFSET $2`
 	if err := h.selectMaps(r.Context(), &rows, query, limit, offset); err != nil {
 		http.Error(w, `{"error":"failed to list orders"}`, http.StatusInternalServerError)
 		return
@@ -346,6 +376,63 @@ func (h *AdminHandler) ListAuditLog(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, pageResponse(logs, total, limit, offset))
 }
 
+// ListMFAPolicies returns whether each system role must sign in with a
+// second factor to use it.
+func (h *AdminHandler) ListMFAPolicies(w http.ResponseWriter, r *http.Request) {
+	var policies []mfaPolicy
+	query := `SELECT r.system_role, COALESCE(p.mfa_required, false) AS mfa_required, p.updated_by, p.updated_at FROM (VALUES ($1), ($2)) AS r(system_role) LEFT JOIN system_role_mfa_policies p ON p.system_role = r.system_role ORDER BY r.system_role`
+	if err := h.db.SelectContext(r.Context(), &policies, query, authDomain.SystemRoleSuperAdmin, authDomain.SystemRoleUser); err != nil {
+		http.Error(w, `{"error":"failed to list mfa policies"}`, http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"data": policies})
+}
+
+func (h *AdminHandler) UpdateMFAPolicy(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		SystemRole  authDomain.SystemRole `json:"system_role"`
+		MFARequired bool                  `json:"mfa_required"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"error":"invalid request body"}`, http.StatusBadRequest)
+		return
+	}
+	if req.SystemRole != authDomain.SystemRoleUser && req.SystemRole != authDomain.SystemRoleSuperAdmin {
+		http.Error(w, `{"error":"invalid system role"}`, http.StatusBadRequest)
+		return
+	}
+	// Requiring a second factor from a session without one would lock the
+	// admin out of the next request.
+	if mfa, _ := r.Context().Value(middleware.ContextKeyMFA).(bool); req.MFARequired && !mfa {
+		http.Error(w, `{"error":"sign in with two-factor authentication first"}`, http.StatusBadRequest)
+		return
+	}
+	actorID, _ := r.Context().Value(middleware.ContextKeyUserId).(uuid.UUID)
+
+	before, err := h.getMFAPolicy(r.Context(), req.SystemRole)
+	if err != nil {
+		http.Error(w, `{"error":"failed to load mfa policy"}`, http.StatusInternalServerError)
+		return
+	}
+	_, err = h.db.ExecContext(r.Context(), `INSERT INTO system_role_mfa_policies (system_role, mfa_required, updated_by, updated_at) VALUES ($1, $2, $3, NOW()) ON CONFLICT (system_role) DO UPDATE SET mfa_required = EXCLUDED.mfa_required, updated_by = EXCLUDED.updated_by, updated_at = EXCLUDED.updated_at`, req.SystemRole, req.MFARequired, actorID)
+	if err != nil {
+		http.Error(w, `{"error":"failed to update mfa policy"}`, http.StatusInternalServerError)
+		return
+	}
+	after, _ := h.getMFAPolicy(r.Context(), req.SystemRole)
+	h.audit(r, "mfa_policy.update", "system_role", nil, before, after)
+	writeJSON(w, http.StatusOK, after)
+}
+
+func (h *AdminHandler) getMFAPolicy(ctx context.Context, role authDomain.SystemRole) (*mfaPolicy, error) {
+	policy := mfaPolicy{SystemRole: role}
+	err := h.db.GetContext(ctx, &policy, `SELECT system_role, mfa_required, updated_by, updated_at FROM system_role_mfa_policies WHERE system_role = $1`, role)
+	if errors.Is(err, sql.ErrNoRows) {
+		return &policy, nil
+	}
+	return &policy, err
+}
+
 func (h *AdminHandler) getUser(ctx context.Context, id uuid.UUID) (*adminUser, error) {
 	var user adminUser
 	err := h.db.GetContext(ctx, &user, `SELECT id, email, name, display_name, role, system_role, status, email_verified, created_at, updated_at FROM users WHERE id = $1`, id)
@@ -370,14 +457,14 @@ func (h *AdminHandler) audit(r *http.Request, action, resourceType string, resou
 	}
 	beforeJSON, _ := json.Marshal(before)
 	afterJSON, _ := json.Marshal(after)
-	ip := clientIP(r)
+	ip := clientinfo.IP(r)
 	userAgent := r.UserAgent()
 	_, _ = h.db.ExecContext(r.Context(), `INSERT INTO admin_audit_logs (actor_id, action, resource_type, resource_id, before_state, after_state, ip_address, user_agent) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`, actorID, action, resourceType, resourceID, beforeJSON, afterJSON, ip, userAgent)
 }
 
 func (h *AdminHandler) getSpecState(ctx context.Context, id uuid.UUID) map[string]any {
 	rows := []map[string]any{}
-	_ = h.selectMaps(ctx, &rows, `SELECT id, producer_id, title, category, base_price, processing_status, is_deleted, deleted_at, updated_at FROM specs WHERE id = $1`, id)
+	_ = h.selectMaps(ctx, &rows, `SELECT id, producer_id, title, category, base_price_minor, price_currency, base_price_minor::NUMERIC / CASE price_currency WHEN 'JPY' THEN 1 ELSE 100 END AS base_price, processing_status, is_deleted, deleted_at, updated_at FROM specs WHERE id = $1`, id)
 	if len(rows) == 0 {
 		return nil
 	}
@@ -445,10 +532,3 @@ func writeJSON(w http.ResponseWriter, status int, data any) {
 	w.WriteHeader(status)
 	_ = json.NewEncoder(w).Encode(data)
 }
-
-func clientIP(r *http.Request) string {
-	if forwarded := strings.TrimSpace(r.Header.Get("X-Forwarded-For")); forwarded != "" {
-		return strings.TrimSpace(strings.Split(forwarded, ",")[0])
-	}
-	return r.RemoteAddr
-}
@@ -13,6 +13,7 @@ import (
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/saransh1220/blueprint-audio/internal/gateway/middleware"
 	auth "github.com/saransh1220/blueprint-audio/internal/modules/auth/domain"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -37,7 +38,7 @@ func newHandler(t *testing.T) (*AdminHandler, sqlmock.Sqlmock, func()) {
 	t.Helper()
 	db, mock, err := sqlmock.New()
 	require.NoError(t, err)
-	return NewAdminHandler(sqlx.NewDb(db, "sqlmock"), userRepoStub{}), mock, func() { db.Close() }
+	return NewAdminHandler(sqlx.NewDb(db, "sqlmock"), userRepoStub{}, nil), mock, func() { db.Close() }
 }
 
 func TestAdminHandlerInvalidIDs(t *testing.T) {
@@ -94,11 +95,6 @@ func TestAdminHelpers(t *testing.T) {
 	limit, offset := pagination(req, 50, 100)
 	assert.Equal(t, 100, limit)
 	assert.Equal(t, 200, offset)
-	assert.Equal(t, "1.2.3.4", clientIP(func() *http.Request {
-		r := httptest.NewRequest(http.MethodGet, "/", nil)
-		r.Header.Set("X-Forwarded-For", "1.2.3.4, 5.6.7.8")
-		return r
-	}()))
 	row := map[string]any{"value": []byte("text")}
 	normalizeMap(row)
 	assert.Equal(t, "text", row["value"])
@@ -148,7 +144,7 @@ func TestUpdateAndDeleteSpec(t *testing.T) {
 	defer closeDB()
 	id := uuid.New()
 	state := func() *sqlmock.Rows {
-		return sqlmock.NewRows([]string{"id", "producer_id", "title", "category", "base_price", "processing_status", "is_deleted", "deleted_at", "updated_at"}).AddRow(id, uuid.New(), "old", "beat", 10, "ready", false, nil, time.Now())
+		return sqlmock.NewRows([]string{"id", "producer_id", "title", "category", "base_price_minor", "price_currency", "base_price", "processing_status", "is_deleted", "deleted_at", "updated_at"}).AddRow(id, uuid.New(), "old", "beat", 1000, "USD", "10.00", "ready", false, nil, time.Now())
 	}
 	mock.ExpectQuery("SELECT id, producer_id").WillReturnRows(state())
 	mock.ExpectExec("UPDATE specs").WillReturnResult(sqlmock.NewResult(0, 1))
@@ -159,6 +155,14 @@ func TestUpdateAndDeleteSpec(t *testing.T) {
 	h.UpdateSpec(w, r)
 	assert.Equal(t, http.StatusOK, w.Code)
 	mock.ExpectQuery("SELECT id, producer_id").WillReturnRows(state())
+	mock.ExpectExec("UPDATE specs").WithArgs(nil, int64(1299), nil, nil, id).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("SELECT id, producer_id").WillReturnRows(state())
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest(http.MethodPatch, "/", strings.NewReader(`{"base_price_minor":1299,"base_price":99}`))
+	r.SetPathValue("id", id.String())
+	h.UpdateSpec(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+	mock.ExpectQuery("SELECT id, producer_id").WillReturnRows(state())
 	mock.ExpectExec("UPDATE specs SET is_deleted").WillReturnResult(sqlmock.NewResult(0, 1))
 	w = httptest.NewRecorder()
 	r = httptest.NewRequest(http.MethodDelete, "/", nil)
@@ -190,3 +194,116 @@ func TestAdminQueryFailures(t *testing.T) {
 	assert.Equal(t, http.StatusNotFound, w.Code)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+type sessionRevokerStub struct {
+	revoked []uuid.UUID
+	err     error
+}
+
+func (s *sessionRevokerStub) RevokeUserSessions(_ context.Context, userID uuid.UUID) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.revoked = append(s.revoked, userID)
+	return nil
+}
+
+type statusRepoStub struct {
+	userRepoStub
+	updated []auth.UserStatus
+}
+
+func (s *statusRepoStub) UpdateStatus(_ context.Context, _ uuid.UUID, status auth.UserStatus) error {
+	s.updated = append(s.updated, status)
+	return nil
+}
+
+func TestUpdateUserStatusRevokesSessionsOnSuspension(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+	sessions := &sessionRevokerStub{}
+	h := NewAdminHandler(sqlx.NewDb(db, "sqlmock"), userRepoStub{}, sessions)
+	id := uuid.New()
+	columns := []string{"id", "email", "name", "display_name", "role", "system_role", "status", "email_verified", "created_at", "updated_at"}
+	now := time.Now()
+
+	for _, status := range []string{"suspended", "active"} {
+		mock.ExpectQuery("SELECT id, email").WithArgs(id).WillReturnRows(sqlmock.NewRows(columns).AddRow(id, "a@b.com", "A", nil, "artist", "user", "active", true, now, now))
+		mock.ExpectQuery("SELECT id, email").WithArgs(id).WillReturnRows(sqlmock.NewRows(columns).AddRow(id, "a@b.com", "A", nil, "artist", "user", status, true, now, now))
+		r := httptest.NewRequest(http.MethodPatch, "/", strings.NewReader(`{"status":"`+status+`"}`))
+		r.SetPathValue("id", id.String())
+		w := httptest.NewRecorder()
+		h.UpdateUserStatus(w, r)
+		assert.Equal(t, http.StatusOK, w.Code)
+	}
+	// Reactivating does not sign the user out again.
+	assert.Equal(t, []uuid.UUID{id}, sessions.revoked)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateUserStatusKeepsUserActiveWhenSignOutFails(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+	users := &statusRepoStub{}
+	h := NewAdminHandler(sqlx.NewDb(db, "sqlmock"), users, &sessionRevokerStub{err: errors.New("redis down")})
+	id := uuid.New()
+	columns := []string{"id", "email", "name", "display_name", "role", "system_role", "status", "email_verified", "created_at", "updated_at"}
+	now := time.Now()
+
+	mock.ExpectQuery("SELECT id, email").WithArgs(id).WillReturnRows(sqlmock.NewRows(columns).AddRow(id, "a@b.com", "A", nil, "artist", "user", "active", true, now, now))
+	r := httptest.NewRequest(http.MethodPatch, "/", strings.NewReader(`{"status":"suspended"}`))
+	r.SetPathValue("id", id.String())
+	w := httptest.NewRecorder()
+	h.UpdateUserStatus(w, r)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Empty(t, users.updated)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMFAPolicies(t *testing.T) {
+	h, mock, closeDB := newHandler(t)
+	defer closeDB()
+	actorID := uuid.New()
+	columns := []string{"system_role", "mfa_required", "updated_by", "updated_at"}
+	request := func(body string, mfa bool) *http.Request {
+		r := httptest.NewRequest(http.MethodPut, "/admin/mfa-policy", strings.NewReader(body))
+		ctx := context.WithValue(r.Context(), middleware.ContextKeyUserId, actorID)
+		return r.WithContext(context.WithValue(ctx, middleware.ContextKeyMFA, mfa))
+	}
+
+	mock.ExpectQuery("SELECT r.system_role").WithArgs(auth.SystemRoleSuperAdmin, auth.SystemRoleUser).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow("super_admin", true, actorID, time.Now()).AddRow("user", false, nil, nil))
+	w := httptest.NewRecorder()
+	h.ListMFAPolicies(w, httptest.NewRequest(http.MethodGet, "/admin/mfa-policy", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"system_role":"super_admin","mfa_required":true`)
+
+	for name, tc := range map[string]struct {
+		body string
+		mfa  bool
+	}{
+		"invalid body":         {body: `{`},
+		"unknown role":         {body: `{"system_role":"owner","mfa_required":false}`},
+		"enabling without 2FA": {body: `{"system_role":"super_admin","mfa_required":true}`},
+	} {
+		t.Run(name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			h.UpdateMFAPolicy(w, request(tc.body, tc.mfa))
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+
+	mock.ExpectQuery("SELECT system_role, mfa_required").WithArgs(auth.SystemRoleSuperAdmin).WillReturnError(sql.ErrNoRows)
+	mock.ExpectExec("INSERT INTO system_role_mfa_policies").WithArgs(auth.SystemRoleSuperAdmin, true, actorID).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("SELECT system_role, mfa_required").WithArgs(auth.SystemRoleSuperAdmin).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow("super_admin", true, actorID, time.Now()))
+	mock.ExpectExec("INSERT INTO admin_audit_logs").WithArgs(actorID, "mfa_policy.update", "system_role", sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+	w = httptest.NewRecorder()
+	h.UpdateMFAPolicy(w, request(`{"system_role":"super_admin","mfa_required":true}`, true))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"mfa_required":true`)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
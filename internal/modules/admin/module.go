@@ -10,9 +10,9 @@ type Module struct {
 	handler *admin_http.AdminHandler
 }
 
-func NewModule(db *sqlx.DB, userRepo authDomain.UserRepository) *Module {
+func NewModule(db *sqlx.DB, userRepo authDomain.UserRepository, sessions admin_http.SessionRevoker) *Module {
 	return &Module{
-		handler: admin_http.NewAdminHandler(db, userRepo),
+		handler: admin_http.NewAdminHandler(db, userRepo, sessions),
 	}
 }
 
@@ -19,6 +19,8 @@ type AnalyticsService interface {
 
 	ToggleFavorite(ctx context.Context, userID, specID uuid.UUID) (bool, error)
 	IsFavorited(ctx context.Context, userID, specID uuid.UUID) (bool, error)
+	// ListFavoritedBy returns the users who favorited the spec.
+	ListFavoritedBy(ctx context.Context, specID uuid.UUID) ([]uuid.UUID, error)
 
 	// ListMyFavorites returns a cursor-paginated page of the authenticated user's favorites.
 	// encodedCursor is opaque base64-encoded JSON; pass nil or empty for the first page.
@@ -73,6 +75,10 @@ func (s *analyticsService) IsFavorited(ctx context.Context, userID, specID uuid.
 	return s.repo.IsFavorited(ctx, userID, specID)
 }
 
+func (s *analyticsService) ListFavoritedBy(ctx context.Context, specID uuid.UUID) ([]uuid.UUID, error) {
+	return s.repo.ListFavoritedBy(ctx, specID)
+}
+
 func (s *analyticsService) GetPublicAnalytics(ctx context.Context, specID uuid.UUID, userID *uuid.UUID) (*domain.PublicAnalytics, error) {
 	analytics, err := s.repo.GetSpecAnalytics(ctx, specID)
 	if err != nil {
@@ -164,7 +170,14 @@ func (s *analyticsService) GetStatsOverview(ctx context.Context, producerID uuid
 		log.Printf("[Analytics Service] GetStatsOverview: Error getting total revenue: %v", err)
 		return nil, err
 	}
-	log.Printf("[Analytics Service] GetStatsOverview: TotalRevenue=%f", totalRevenue)
+	log.Printf("[Analytics Service] GetStatsOverview: TotalRevenue=%v", totalRevenue)
+
+	totalDiscounts, err := s.repo.GetTotalDiscounts(ctx, producerID, days)
+	if err != nil {
+		log.Printf("[Analytics Service] GetStatsOverview: Error getting total discounts: %v", err)
+		return nil, err
+	}
+	log.Printf("[Analytics Service] GetStatsOverview: TotalDiscounts=%v", totalDiscounts)
 
 	log.Printf("[Analytics Service] GetStatsOverview: Calling GetPlaysByDay with days=%d", days)
 	playsByDay, err := s.repo.GetPlaysByDay(ctx, producerID, days)
@@ -209,6 +222,7 @@ func (s *analyticsService) GetStatsOverview(ctx context.Context, producerID uuid
 		TotalPlays:       totalPlays,
 		TotalFavorites:   totalFavorites,
 		TotalRevenue:     totalRevenue,
+		TotalDiscounts:   totalDiscounts,
 		TotalDownloads:   totalDownloads,
 		PlaysByDay:       playsByDay,
 		DownloadsByDay:   downloadsByDay,
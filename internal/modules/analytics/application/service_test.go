@@ -8,6 +8,7 @@ import (
 	"github.com/google/uuid"
 	analyticsDomain "github.com/saransh1220/blueprint-audio/internal/modules/analytics/domain"
 	catalogDomain "github.com/saransh1220/blueprint-audio/internal/modules/catalog/domain"
+	"github.com/saransh1220/blueprint-audio/internal/shared/money"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -41,6 +42,13 @@ func (m *mockAnalyticsRepository) IsFavorited(ctx context.Context, userID, specI
 	args := m.Called(ctx, userID, specID)
 	return args.Bool(0), args.Error(1)
 }
+func (m *mockAnalyticsRepository) ListFavoritedBy(ctx context.Context, specID uuid.UUID) ([]uuid.UUID, error) {
+	args := m.Called(ctx, specID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]uuid.UUID), args.Error(1)
+}
 func (m *mockAnalyticsRepository) ListUserFavorites(ctx context.Context, userID uuid.UUID, limit int, cursor *analyticsDomain.FavoriteCursor) (*analyticsDomain.FavoritePage, error) {
 	args := m.Called(ctx, userID, limit, cursor)
 	if args.Get(0) == nil {
@@ -67,16 +75,20 @@ func (m *mockAnalyticsRepository) GetTotalDownloads(ctx context.Context, produce
 	args := m.Called(ctx, producerID, days)
 	return args.Int(0), args.Error(1)
 }
-func (m *mockAnalyticsRepository) GetTotalRevenue(ctx context.Context, producerID uuid.UUID, days int) (float64, error) {
+func (m *mockAnalyticsRepository) GetTotalRevenue(ctx context.Context, producerID uuid.UUID, days int) (money.Amounts, error) {
 	args := m.Called(ctx, producerID, days)
-	return args.Get(0).(float64), args.Error(1)
+	return args.Get(0).(money.Amounts), args.Error(1)
 }
-func (m *mockAnalyticsRepository) GetRevenueByLicenseGlobal(ctx context.Context, producerID uuid.UUID, days int) (map[string]float64, error) {
+func (m *mockAnalyticsRepository) GetTotalDiscounts(ctx context.Context, producerID uuid.UUID, days int) (money.Amounts, error) {
+	args := m.Called(ctx, producerID, days)
+	return args.Get(0).(money.Amounts), args.Error(1)
+}
+func (m *mockAnalyticsRepository) GetRevenueByLicenseGlobal(ctx context.Context, producerID uuid.UUID, days int) (map[string]money.Amounts, error) {
 	args := m.Called(ctx, producerID, days)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).(map[string]float64), args.Error(1)
+	return args.Get(0).(map[string]money.Amounts), args.Error(1)
 }
 func (m *mockAnalyticsRepository) GetPlaysByDay(ctx context.Context, producerID uuid.UUID, days int) ([]analyticsDomain.DailyStat, error) {
 	args := m.Called(ctx, producerID, days)
@@ -188,18 +200,22 @@ func (m *mockSpecRepository) GetRankedSpecs(ctx context.Context, section, period
 	}
 	return args.Get(0).([]catalogDomain.RankingRow), args.Error(1)
 }
-func (m *mockSpecRepository) GetRankingFreshness(ctx context.Context, section, period string) (*catalogDomain.RankingFreshness, error) {
-	args := m.Called(ctx, section, period)
+func (m *mockSpecRepository) GetRecommendedSpecs(ctx context.Context, userID uuid.UUID, limit int) ([]catalogDomain.RankingRow, error) {
+	args := m.Called(ctx, userID, limit)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).(*catalogDomain.RankingFreshness), args.Error(1)
+	return args.Get(0).([]catalogDomain.RankingRow), args.Error(1)
 }
 func (m *mockSpecRepository) RecalculateBeatRankings(ctx context.Context, section, period string) error {
 	args := m.Called(ctx, section, period)
 	return args.Error(0)
 }
 
+func (m *mockSpecRepository) SuggestSearch(ctx context.Context, query string, limit int) ([]catalogDomain.SearchSuggestion, error) {
+	return nil, nil
+}
+
 func TestAnalyticsService_ToggleFavorite(t *testing.T) {
 	ctx := context.Background()
 	ar := new(mockAnalyticsRepository)
@@ -274,15 +290,17 @@ func TestAnalyticsService_TrackOverviewAndTop(t *testing.T) {
 	ar.On("GetTotalPlays", ctx, userID, 30).Return(1, nil).Once()
 	ar.On("GetTotalFavorites", ctx, userID, 30).Return(1, nil).Once()
 	ar.On("GetTotalDownloads", ctx, userID, 30).Return(1, nil).Once()
-	ar.On("GetTotalRevenue", ctx, userID, 30).Return(1.0, nil).Once()
+	ar.On("GetTotalRevenue", ctx, userID, 30).Return(money.Amounts{money.NewAmount(100, money.CurrencyINR)}, nil).Once()
+	ar.On("GetTotalDiscounts", ctx, userID, 30).Return(money.Amounts{money.NewAmount(50, money.CurrencyINR)}, nil).Once()
 	ar.On("GetPlaysByDay", ctx, userID, 30).Return([]analyticsDomain.DailyStat{}, nil).Once()
 	ar.On("GetDownloadsByDay", ctx, userID, 30).Return([]analyticsDomain.DailyStat{}, nil).Once()
 	ar.On("GetRevenueByDay", ctx, userID, 30).Return([]analyticsDomain.DailyRevenueStat{}, nil).Once()
 	ar.On("GetTopSpecs", ctx, userID, 5, "").Return([]analyticsDomain.TopSpecStat{}, nil).Once()
-	ar.On("GetRevenueByLicenseGlobal", ctx, userID, 30).Return(map[string]float64{}, nil).Once()
+	ar.On("GetRevenueByLicenseGlobal", ctx, userID, 30).Return(map[string]money.Amounts{}, nil).Once()
 	overview, err := svc.GetStatsOverview(ctx, userID, 30, "")
 	assert.NoError(t, err)
 	assert.NotNil(t, overview)
+	assert.Equal(t, int64(50), overview.TotalDiscounts.Get(money.CurrencyINR).Minor)
 
 	ar.On("GetTopSpecs", ctx, userID, 3, "revenue").Return([]analyticsDomain.TopSpecStat{{SpecID: specID, Title: "X"}}, nil).Once()
 	top, err := svc.GetTopSpecs(ctx, userID, 3, "revenue")
@@ -293,12 +311,13 @@ func TestAnalyticsService_TrackOverviewAndTop(t *testing.T) {
 	ar.On("GetTotalPlays", ctx, userID, 1).Return(0, nil).Once()
 	ar.On("GetTotalFavorites", ctx, userID, 1).Return(0, nil).Once()
 	ar.On("GetTotalDownloads", ctx, userID, 1).Return(0, nil).Once()
-	ar.On("GetTotalRevenue", ctx, userID, 1).Return(0.0, nil).Once()
+	ar.On("GetTotalRevenue", ctx, userID, 1).Return(money.Amounts(nil), nil).Once()
+	ar.On("GetTotalDiscounts", ctx, userID, 1).Return(money.Amounts(nil), nil).Once()
 	ar.On("GetPlaysByDay", ctx, userID, 1).Return([]analyticsDomain.DailyStat{}, nil).Once()
 	ar.On("GetDownloadsByDay", ctx, userID, 1).Return([]analyticsDomain.DailyStat{}, nil).Once()
 	ar.On("GetRevenueByDay", ctx, userID, 1).Return([]analyticsDomain.DailyRevenueStat{}, nil).Once()
 	ar.On("GetTopSpecs", ctx, userID, 5, "").Return([]analyticsDomain.TopSpecStat{}, nil).Once()
-	ar.On("GetRevenueByLicenseGlobal", ctx, userID, 1).Return(map[string]float64{}, nil).Once()
+	ar.On("GetRevenueByLicenseGlobal", ctx, userID, 1).Return(map[string]money.Amounts{}, nil).Once()
 	_, err = svc.GetStatsOverview(ctx, userID, 0, "")
 	assert.NoError(t, err)
 }
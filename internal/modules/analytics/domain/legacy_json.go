@@ -0,0 +1,37 @@
+package domain
+
+import "encoding/json"
+
+// Revenue used to be reported as a single float in major units. Until every
+// client reads the per-currency "_amounts" fields, the old fields are still
+// written, summed across currencies the way they always were.
+
+func (s DailyRevenueStat) MarshalJSON() ([]byte, error) {
+	type dailyRevenueStat DailyRevenueStat
+	return json.Marshal(struct {
+		dailyRevenueStat
+		Revenue float64 `json:"revenue"`
+	}{dailyRevenueStat(s), s.Revenue.LegacyMajor()})
+}
+
+func (s TopSpecStat) MarshalJSON() ([]byte, error) {
+	type topSpecStat TopSpecStat
+	return json.Marshal(struct {
+		topSpecStat
+		Revenue float64 `json:"revenue"`
+	}{topSpecStat(s), s.Revenue.LegacyMajor()})
+}
+
+func (r AnalyticsOverviewResponse) MarshalJSON() ([]byte, error) {
+	type analyticsOverviewResponse AnalyticsOverviewResponse
+	revenueByLicense := make(map[string]float64, len(r.RevenueByLicense))
+	for license, revenue := range r.RevenueByLicense {
+		revenueByLicense[license] = revenue.LegacyMajor()
+	}
+	return json.Marshal(struct {
+		analyticsOverviewResponse
+		TotalRevenue     float64            `json:"total_revenue"`
+		TotalDiscounts   float64            `json:"total_discounts"`
+		RevenueByLicense map[string]float64 `json:"revenue_by_license"`
+	}{analyticsOverviewResponse(r), r.TotalRevenue.LegacyMajor(), r.TotalDiscounts.LegacyMajor(), revenueByLicense})
+}
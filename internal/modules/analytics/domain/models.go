@@ -6,6 +6,7 @@ import (
 
 	"github.com/google/uuid"
 	catalogDomain "github.com/saransh1220/blueprint-audio/internal/modules/catalog/domain"
+	"github.com/saransh1220/blueprint-audio/internal/shared/money"
 )
 
 // SpecAnalytics represents analytics data for a spec
@@ -35,16 +36,16 @@ type DailyStat struct {
 }
 
 type DailyRevenueStat struct {
-	Date    string  `json:"date" db:"date"`
-	Revenue float64 `json:"revenue" db:"revenue"`
+	Date    string        `json:"date"`
+	Revenue money.Amounts `json:"revenue_amounts"`
 }
 
 type TopSpecStat struct {
-	SpecID    uuid.UUID `json:"spec_id" db:"spec_id"`
-	Title     string    `json:"title" db:"title"`
-	Plays     int       `json:"plays" db:"plays"`
-	Downloads int       `json:"downloads" db:"downloads"`
-	Revenue   float64   `json:"revenue" db:"revenue"`
+	SpecID    uuid.UUID     `json:"spec_id" db:"spec_id"`
+	Title     string        `json:"title" db:"title"`
+	Plays     int           `json:"plays" db:"plays"`
+	Downloads int           `json:"downloads" db:"downloads"`
+	Revenue   money.Amounts `json:"revenue_amounts" db:"-"`
 }
 
 type PublicAnalytics struct {
@@ -63,15 +64,16 @@ type ProducerAnalytics struct {
 }
 
 type AnalyticsOverviewResponse struct {
-	TotalPlays       int                `json:"total_plays"`
-	TotalFavorites   int                `json:"total_favorites"`
-	TotalRevenue     float64            `json:"total_revenue"`
-	TotalDownloads   int                `json:"total_downloads"`
-	PlaysByDay       []DailyStat        `json:"plays_by_day"`
-	DownloadsByDay   []DailyStat        `json:"downloads_by_day"`
-	RevenueByDay     []DailyRevenueStat `json:"revenue_by_day"`
-	TopSpecs         []TopSpecStat      `json:"top_specs"`
-	RevenueByLicense map[string]float64 `json:"revenue_by_license"`
+	TotalPlays       int                      `json:"total_plays"`
+	TotalFavorites   int                      `json:"total_favorites"`
+	TotalRevenue     money.Amounts            `json:"total_revenue_amounts"`
+	TotalDiscounts   money.Amounts            `json:"total_discounts_amounts"`
+	TotalDownloads   int                      `json:"total_downloads"`
+	PlaysByDay       []DailyStat              `json:"plays_by_day"`
+	DownloadsByDay   []DailyStat              `json:"downloads_by_day"`
+	RevenueByDay     []DailyRevenueStat       `json:"revenue_by_day"`
+	TopSpecs         []TopSpecStat            `json:"top_specs"`
+	RevenueByLicense map[string]money.Amounts `json:"revenue_by_license_amounts"`
 }
 
 // AnalyticsRepository defines the contract for analytics data access
@@ -83,6 +85,8 @@ type AnalyticsRepository interface {
 	AddFavorite(ctx context.Context, userID, specID uuid.UUID) error
 	RemoveFavorite(ctx context.Context, userID, specID uuid.UUID) error
 	IsFavorited(ctx context.Context, userID, specID uuid.UUID) (bool, error)
+	// ListFavoritedBy returns the users who favorited the spec.
+	ListFavoritedBy(ctx context.Context, specID uuid.UUID) ([]uuid.UUID, error)
 
 	// ListUserFavorites returns a cursor-paginated page of the user's favorited specs.
 	ListUserFavorites(ctx context.Context, userID uuid.UUID, limit int, cursor *FavoriteCursor) (*FavoritePage, error)
@@ -93,8 +97,10 @@ type AnalyticsRepository interface {
 	GetTotalPlays(ctx context.Context, producerID uuid.UUID, days int) (int, error)
 	GetTotalFavorites(ctx context.Context, producerID uuid.UUID, days int) (int, error)
 	GetTotalDownloads(ctx context.Context, producerID uuid.UUID, days int) (int, error)
-	GetTotalRevenue(ctx context.Context, producerID uuid.UUID, days int) (float64, error)
-	GetRevenueByLicenseGlobal(ctx context.Context, producerID uuid.UUID, days int) (map[string]float64, error)
+	GetTotalRevenue(ctx context.Context, producerID uuid.UUID, days int) (money.Amounts, error)
+	// GetTotalDiscounts sums the coupon discounts given on the producer's paid sales.
+	GetTotalDiscounts(ctx context.Context, producerID uuid.UUID, days int) (money.Amounts, error)
+	GetRevenueByLicenseGlobal(ctx context.Context, producerID uuid.UUID, days int) (map[string]money.Amounts, error)
 	GetPlaysByDay(ctx context.Context, producerID uuid.UUID, days int) ([]DailyStat, error)
 	GetDownloadsByDay(ctx context.Context, producerID uuid.UUID, days int) ([]DailyStat, error)
 	GetRevenueByDay(ctx context.Context, producerID uuid.UUID, days int) ([]DailyRevenueStat, error)
@@ -9,8 +9,10 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/saransh1220/blueprint-audio/internal/modules/analytics/domain"
 	catalogDomain "github.com/saransh1220/blueprint-audio/internal/modules/catalog/domain"
+	"github.com/saransh1220/blueprint-audio/internal/shared/money"
 )
 
 type PgAnalyticsRepository struct {
@@ -223,6 +225,18 @@ func (r *PgAnalyticsRepository) IsFavorited(ctx context.Context, userID, specID
 	return exists, nil
 }
 
+// ListFavoritedBy returns the users who favorited a spec
+func (r *PgAnalyticsRepository) ListFavoritedBy(ctx context.Context, specID uuid.UUID) ([]uuid.UUID, error) {
+	userIDs := []uuid.UUID{}
+	query := `SELECT user_id FROM user_favorites WHERE spec_id = $1`
+
+	if err := r.db.SelectContext(ctx, &userIDs, query, specID); err != nil {
+		return nil, fmt.Errorf("failed to list favoriting users: %w", err)
+	}
+
+	return userIDs, nil
+}
+
 // ListUserFavorites returns a cursor-paginated page of the user's favorited specs.
 //
 // The cursor encodes (favorited_at DESC, spec_id DESC) so pages are stable even
@@ -244,7 +258,7 @@ func (r *PgAnalyticsRepository) ListUserFavorites(ctx context.Context, userID uu
 			uf.created_at  AS favorited_at,
 			s.id, s.producer_id, s.title, s.category, s.type, s.bpm, s.key,
 			s.image_url, s.preview_url, s.wav_url, s.stems_url,
-			s.base_price, s.price_currency, s.description, s.duration,
+			s.base_price_minor, s.price_currency, s.description, s.duration,
 			s.free_mp3_enabled, s.created_at, s.updated_at, s.deleted_at,
 			s.is_deleted, s.moods, s.instruments, s.waveform_peaks,
 			s.slug, s.short_code, s.processing_status,
@@ -352,12 +366,13 @@ func (r *PgAnalyticsRepository) GetLicensePurchaseCounts(ctx context.Context, sp
 
 	query := `
 		SELECT 
-			o.license_type,
+			oi.license_type,
 			COUNT(*) as count
-		FROM orders o
-		WHERE o.spec_id = $1 
+		FROM order_items oi
+		JOIN orders o ON o.id = oi.order_id
+		WHERE oi.spec_id = $1 
 		  AND o.status = 'paid'
-		GROUP BY o.license_type`
+		GROUP BY oi.license_type`
 
 	var results []licenseCount
 	err := r.db.SelectContext(ctx, &results, query, specID)
@@ -423,47 +438,87 @@ func (r *PgAnalyticsRepository) GetTotalDownloads(ctx context.Context, producerI
 	return total, err
 }
 
-func (r *PgAnalyticsRepository) GetTotalRevenue(ctx context.Context, producerID uuid.UUID, days int) (float64, error) {
+// currencyTotal is the sum of order item amounts in one currency, in minor
+// units. Revenue is summed per currency so totals never mix currencies.
+type currencyTotal struct {
+	Currency string `db:"currency"`
+	Amount   int64  `db:"amount"`
+}
+
+func sumByCurrency(rows []currencyTotal) money.Amounts {
+	var totals money.Amounts
+	for _, row := range rows {
+		totals = totals.Add(money.NewAmount(row.Amount, row.Currency))
+	}
+	return totals
+}
+
+func (r *PgAnalyticsRepository) GetTotalRevenue(ctx context.Context, producerID uuid.UUID, days int) (money.Amounts, error) {
 	if days <= 0 {
 		days = 30
 	}
-	var total float64
+	var rows []currencyTotal
 	query := `
-		SELECT COALESCE(SUM(amount), 0) / 100.0
-		FROM orders o
-		JOIN specs s ON o.spec_id = s.id
+		SELECT oi.currency, SUM(oi.amount) AS amount
+		FROM order_items oi
+		JOIN orders o ON o.id = oi.order_id
+		JOIN specs s ON oi.spec_id = s.id
 		WHERE s.producer_id = $1 
 		  AND o.status = 'paid'
-		  AND o.created_at > NOW() - ($2 || ' days')::INTERVAL`
-	err := r.db.GetContext(ctx, &total, query, producerID, days)
-	return total, err
+		  AND o.created_at > NOW() - ($2 || ' days')::INTERVAL
+		GROUP BY oi.currency`
+	if err := r.db.SelectContext(ctx, &rows, query, producerID, days); err != nil {
+		return nil, err
+	}
+	return sumByCurrency(rows), nil
+}
+
+func (r *PgAnalyticsRepository) GetTotalDiscounts(ctx context.Context, producerID uuid.UUID, days int) (money.Amounts, error) {
+	if days <= 0 {
+		days = 30
+	}
+	var rows []currencyTotal
+	query := `
+		SELECT oi.currency, SUM(oi.discount_amount) AS amount
+		FROM order_items oi
+		JOIN orders o ON o.id = oi.order_id
+		JOIN specs s ON oi.spec_id = s.id
+		WHERE s.producer_id = $1
+		  AND o.status = 'paid'
+		  AND o.created_at > NOW() - ($2 || ' days')::INTERVAL
+		GROUP BY oi.currency`
+	if err := r.db.SelectContext(ctx, &rows, query, producerID, days); err != nil {
+		return nil, err
+	}
+	return sumByCurrency(rows), nil
 }
 
-func (r *PgAnalyticsRepository) GetRevenueByLicenseGlobal(ctx context.Context, producerID uuid.UUID, days int) (map[string]float64, error) {
+func (r *PgAnalyticsRepository) GetRevenueByLicenseGlobal(ctx context.Context, producerID uuid.UUID, days int) (map[string]money.Amounts, error) {
 	if days <= 0 {
 		days = 30
 	}
 	type licenseRev struct {
-		LicenseType string  `db:"license_type"`
-		Revenue     float64 `db:"revenue"`
+		LicenseType string `db:"license_type"`
+		currencyTotal
 	}
 	query := `
-		SELECT o.license_type, COALESCE(SUM(o.amount), 0) / 100.0 as revenue
-		FROM orders o
-		JOIN specs s ON o.spec_id = s.id
+		SELECT oi.license_type, oi.currency, SUM(oi.amount) AS amount
+		FROM order_items oi
+		JOIN orders o ON o.id = oi.order_id
+		JOIN specs s ON oi.spec_id = s.id
 		WHERE s.producer_id = $1 
 		  AND o.status = 'paid'
 		  AND o.created_at > NOW() - ($2 || ' days')::INTERVAL
-		GROUP BY o.license_type`
+		GROUP BY oi.license_type, oi.currency`
 	var rows []licenseRev
 	err := r.db.SelectContext(ctx, &rows, query, producerID, days)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get global revenue by license: %w", err)
 	}
 
-	result := make(map[string]float64)
+	result := make(map[string]money.Amounts)
 	for _, row := range rows {
-		result[row.LicenseType] = row.Revenue
+		result[row.LicenseType] = result[row.LicenseType].Add(money.NewAmount(row.Amount, row.Currency))
 	}
 	return result, nil
 }
@@ -544,22 +599,36 @@ func (r *PgAnalyticsRepository) GetRevenueByDay(ctx context.Context, producerID
 	query := `
 		SELECT 
 			to_char(date_trunc('day', o.created_at), 'YYYY-MM-DD') as date,
-			COALESCE(SUM(o.amount), 0) / 100.0 as revenue
-		FROM orders o
-		JOIN specs s ON o.spec_id = s.id
+			oi.currency,
+			SUM(oi.amount) as amount
+		FROM order_items oi
+		JOIN orders o ON o.id = oi.order_id
+		JOIN specs s ON oi.spec_id = s.id
 		WHERE s.producer_id = $1
 		  AND o.status = 'paid'
 		  AND o.created_at > NOW() - ($2 || ' days')::INTERVAL
-		GROUP BY 1
+		GROUP BY 1, 2
 		ORDER BY 1 ASC
 	`
 	log.Printf("[Analytics Repo] GetRevenueByDay: Executing query with producerID=%s, days=%d", producerID, days)
-	var stats []domain.DailyRevenueStat
-	err := r.db.SelectContext(ctx, &stats, query, producerID, days)
+	type dayRev struct {
+		Date string `db:"date"`
+		currencyTotal
+	}
+	var rows []dayRev
+	err := r.db.SelectContext(ctx, &rows, query, producerID, days)
 	if err != nil {
 		log.Printf("[Analytics Repo] GetRevenueByDay: Query error: %v", err)
 		return nil, fmt.Errorf("failed to get revenue by day: %w", err)
 	}
+	var stats []domain.DailyRevenueStat
+	for _, row := range rows {
+		if len(stats) == 0 || stats[len(stats)-1].Date != row.Date {
+			stats = append(stats, domain.DailyRevenueStat{Date: row.Date})
+		}
+		last := &stats[len(stats)-1]
+		last.Revenue = last.Revenue.Add(money.NewAmount(row.Amount, row.Currency))
+	}
 	log.Printf("[Analytics Repo] GetRevenueByDay: Query returned %d rows", len(stats))
 	return stats, nil
 }
@@ -570,7 +639,9 @@ func (r *PgAnalyticsRepository) GetTopSpecs(ctx context.Context, producerID uuid
 	orderBy := "sa.play_count DESC"
 	switch sortBy {
 	case "revenue":
-		orderBy = "revenue DESC"
+		// Ranked by minor units summed across currencies, as before
+		// revenue was kept per currency.
+		orderBy = "COALESCE(SUM(oi.amount), 0) DESC"
 	case "downloads":
 		orderBy = "downloads DESC"
 	case "plays":
@@ -582,11 +653,10 @@ func (r *PgAnalyticsRepository) GetTopSpecs(ctx context.Context, producerID uuid
 			s.id as spec_id, 
 			s.title, 
 			COALESCE(sa.play_count, 0) as plays,
-			COALESCE(sa.free_download_count, 0) as downloads,
-			COALESCE(SUM(o.amount), 0) / 100.0 as revenue
+			COALESCE(sa.free_download_count, 0) as downloads
 		FROM specs s
 		left JOIN spec_analytics sa ON s.id = sa.spec_id
-		left JOIN orders o ON s.id = o.spec_id AND o.status = 'paid'
+		left JOIN (order_items oi JOIN orders o ON o.id = oi.order_id AND o.status = 'paid') ON s.id = oi.spec_id
 		WHERE s.producer_id = $1
 		GROUP BY s.id, s.title, sa.play_count, sa.free_download_count
 		ORDER BY %s
@@ -596,5 +666,34 @@ func (r *PgAnalyticsRepository) GetTopSpecs(ctx context.Context, producerID uuid
 	if err != nil {
 		return nil, fmt.Errorf("failed to get top specs: %w", err)
 	}
+	if len(stats) == 0 {
+		return stats, nil
+	}
+
+	specIDs := make([]uuid.UUID, len(stats))
+	for i, stat := range stats {
+		specIDs[i] = stat.SpecID
+	}
+	type specRev struct {
+		SpecID uuid.UUID `db:"spec_id"`
+		currencyTotal
+	}
+	var rows []specRev
+	err = r.db.SelectContext(ctx, &rows, `
+		SELECT oi.spec_id, oi.currency, SUM(oi.amount) AS amount
+		FROM order_items oi
+		JOIN orders o ON o.id = oi.order_id AND o.status = 'paid'
+		WHERE oi.spec_id = ANY($1)
+		GROUP BY oi.spec_id, oi.currency`, pq.Array(specIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get top spec revenue: %w", err)
+	}
+	revenue := make(map[uuid.UUID]money.Amounts, len(stats))
+	for _, row := range rows {
+		revenue[row.SpecID] = revenue[row.SpecID].Add(money.NewAmount(row.Amount, row.Currency))
+	}
+	for i := range stats {
+		stats[i].Revenue = revenue[stats[i].SpecID]
+	}
 	return stats, nil
 }
@@ -9,6 +9,7 @@ import (
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/google/uuid"
 	analyticsPostgres "github.com/saransh1220/blueprint-audio/internal/modules/analytics/infrastructure/persistence/postgres"
+	"github.com/saransh1220/blueprint-audio/internal/shared/money"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -39,6 +40,13 @@ func TestPGAnalyticsRepository_GetSpecAnalyticsAndIsFavorited(t *testing.T) {
 	fav, err := repo.IsFavorited(ctx, userID, specID)
 	require.NoError(t, err)
 	assert.True(t, fav)
+
+	mock.ExpectQuery("SELECT user_id FROM user_favorites WHERE spec_id = \\$1").
+		WithArgs(specID).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(userID))
+	favoritedBy, err := repo.ListFavoritedBy(ctx, specID)
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{userID}, favoritedBy)
 }
 
 func TestPGAnalyticsRepository_IncrementAndFavoriteTx(t *testing.T) {
@@ -100,19 +108,25 @@ func TestPGAnalyticsRepository_OverviewQueries(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, 10, plays)
 
-	mock.ExpectQuery("SELECT o\\.license_type, COALESCE\\(SUM\\(o\\.amount\\), 0\\) / 100\\.0 as revenue").
+	mock.ExpectQuery("SELECT oi\\.license_type, oi\\.currency, SUM\\(oi\\.amount\\) AS amount FROM order_items oi .* GROUP BY oi\\.license_type, oi\\.currency").
 		WithArgs(producerID, 30).
-		WillReturnRows(sqlmock.NewRows([]string{"license_type", "revenue"}).AddRow("Basic", 10.5))
+		WillReturnRows(sqlmock.NewRows([]string{"license_type", "currency", "amount"}).
+			AddRow("Basic", "INR", 1050).
+			AddRow("Basic", "USD", 999))
 	rev, err := repo.GetRevenueByLicenseGlobal(ctx, producerID, 30)
 	require.NoError(t, err)
-	assert.Equal(t, 10.5, rev["Basic"])
+	assert.Equal(t, money.Amounts{money.NewAmount(1050, money.CurrencyINR), money.NewAmount(999, money.CurrencyUSD)}, rev["Basic"])
 
-	mock.ExpectQuery("SELECT s\\.id as spec_id, s\\.title, COALESCE\\(sa\\.play_count, 0\\) as plays, COALESCE\\(sa\\.free_download_count, 0\\) as downloads, COALESCE\\(SUM\\(o\\.amount\\), 0\\) / 100\\.0 as revenue").
+	mock.ExpectQuery("SELECT s\\.id as spec_id, s\\.title, COALESCE\\(sa\\.play_count, 0\\) as plays, COALESCE\\(sa\\.free_download_count, 0\\) as downloads FROM specs s").
 		WithArgs(producerID, 5).
-		WillReturnRows(sqlmock.NewRows([]string{"spec_id", "title", "plays", "downloads", "revenue"}).AddRow(specID.String(), "Track", 9, 5, 20.0))
+		WillReturnRows(sqlmock.NewRows([]string{"spec_id", "title", "plays", "downloads"}).AddRow(specID.String(), "Track", 9, 5))
+	mock.ExpectQuery("SELECT oi\\.spec_id, oi\\.currency, SUM\\(oi\\.amount\\) AS amount FROM order_items oi").
+		WithArgs(sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"spec_id", "currency", "amount"}).AddRow(specID.String(), "INR", 2000))
 	top, err := repo.GetTopSpecs(ctx, producerID, 5, "plays")
 	require.NoError(t, err)
-	assert.Len(t, top, 1)
+	require.Len(t, top, 1)
+	assert.Equal(t, money.Amounts{money.NewAmount(2000, money.CurrencyINR)}, top[0].Revenue)
 }
 
 func TestPGAnalyticsRepository_AdditionalCoverage(t *testing.T) {
@@ -123,7 +137,7 @@ func TestPGAnalyticsRepository_AdditionalCoverage(t *testing.T) {
 	producerID := uuid.New()
 	specID := uuid.New()
 
-	mock.ExpectQuery("SELECT\\s+o\\.license_type,\\s+COUNT\\(\\*\\) as count").
+	mock.ExpectQuery("SELECT\\s+oi\\.license_type,\\s+COUNT\\(\\*\\) as count\\s+FROM order_items oi").
 		WithArgs(specID).
 		WillReturnRows(sqlmock.NewRows([]string{"license_type", "count"}).AddRow("Basic", 2))
 	counts, err := repo.GetLicensePurchaseCounts(ctx, specID)
@@ -144,12 +158,19 @@ func TestPGAnalyticsRepository_AdditionalCoverage(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, 4, v)
 
-	mock.ExpectQuery("SELECT COALESCE\\(SUM\\(amount\\), 0\\) / 100\\.0").
+	mock.ExpectQuery("SELECT oi\\.currency, SUM\\(oi\\.amount\\) AS amount FROM order_items oi").
 		WithArgs(producerID, 30).
-		WillReturnRows(sqlmock.NewRows([]string{"coalesce"}).AddRow(12.5))
+		WillReturnRows(sqlmock.NewRows([]string{"currency", "amount"}).AddRow("USD", 1250).AddRow("INR", 83000))
 	rev, err := repo.GetTotalRevenue(ctx, producerID, 0)
 	require.NoError(t, err)
-	assert.Equal(t, 12.5, rev)
+	assert.Equal(t, money.Amounts{money.NewAmount(83000, money.CurrencyINR), money.NewAmount(1250, money.CurrencyUSD)}, rev)
+
+	mock.ExpectQuery("SELECT oi\\.currency, SUM\\(oi\\.discount_amount\\) AS amount FROM order_items oi").
+		WithArgs(producerID, 30).
+		WillReturnRows(sqlmock.NewRows([]string{"currency", "amount"}).AddRow("USD", 250))
+	discounts, err := repo.GetTotalDiscounts(ctx, producerID, 0)
+	require.NoError(t, err)
+	assert.Equal(t, money.Amounts{money.NewAmount(250, money.CurrencyUSD)}, discounts)
 
 	mock.ExpectQuery("SELECT\\s+to_char\\(date_trunc\\('day', ae\\.created_at\\), 'YYYY-MM-DD'\\) as date,\\s+COUNT\\(\\*\\) as count").
 		WithArgs(producerID, 30).
@@ -167,18 +188,26 @@ func TestPGAnalyticsRepository_AdditionalCoverage(t *testing.T) {
 	require.Len(t, downloads, 1)
 	assert.Equal(t, 5, downloads[0].Count)
 
-	mock.ExpectQuery("SELECT\\s+to_char\\(date_trunc\\('day', o\\.created_at\\), 'YYYY-MM-DD'\\) as date,\\s+COALESCE\\(SUM\\(o\\.amount\\), 0\\) / 100\\.0 as revenue").
+	mock.ExpectQuery("SELECT\\s+to_char\\(date_trunc\\('day', o\\.created_at\\), 'YYYY-MM-DD'\\) as date,\\s+oi\\.currency,\\s+SUM\\(oi\\.amount\\) as amount").
 		WithArgs(producerID, 30).
-		WillReturnRows(sqlmock.NewRows([]string{"date", "revenue"}).AddRow("2026-02-15", 33.3))
+		WillReturnRows(sqlmock.NewRows([]string{"date", "currency", "amount"}).
+			AddRow("2026-02-15", "INR", 3330).
+			AddRow("2026-02-15", "USD", 500).
+			AddRow("2026-02-16", "INR", 100))
 	revenueByDay, err := repo.GetRevenueByDay(ctx, producerID, 0)
 	require.NoError(t, err)
-	require.Len(t, revenueByDay, 1)
-	assert.Equal(t, 33.3, revenueByDay[0].Revenue)
+	require.Len(t, revenueByDay, 2)
+	assert.Equal(t, money.Amounts{money.NewAmount(3330, money.CurrencyINR), money.NewAmount(500, money.CurrencyUSD)}, revenueByDay[0].Revenue)
+	assert.Equal(t, "2026-02-16", revenueByDay[1].Date)
 
 	mock.ExpectQuery("SELECT\\s+s\\.id as spec_id,\\s+s\\.title").
 		WithArgs(producerID, 2).
-		WillReturnRows(sqlmock.NewRows([]string{"spec_id", "title", "plays", "downloads", "revenue"}).AddRow(specID.String(), "Track", 2, 1, 9.9))
+		WillReturnRows(sqlmock.NewRows([]string{"spec_id", "title", "plays", "downloads"}).AddRow(specID.String(), "Track", 2, 1))
+	mock.ExpectQuery("SELECT oi\\.spec_id, oi\\.currency").
+		WithArgs(sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"spec_id", "currency", "amount"}))
 	top, err := repo.GetTopSpecs(ctx, producerID, 2, "downloads")
 	require.NoError(t, err)
-	assert.Len(t, top, 1)
+	require.Len(t, top, 1)
+	assert.Nil(t, top[0].Revenue)
 }
@@ -36,6 +36,13 @@ func (m *mockAnalyticsService) IsFavorited(ctx context.Context, userID, specID u
 	args := m.Called(ctx, userID, specID)
 	return args.Bool(0), args.Error(1)
 }
+func (m *mockAnalyticsService) ListFavoritedBy(ctx context.Context, specID uuid.UUID) ([]uuid.UUID, error) {
+	args := m.Called(ctx, specID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]uuid.UUID), args.Error(1)
+}
 func (m *mockAnalyticsService) GetPublicAnalytics(ctx context.Context, specID uuid.UUID, userID *uuid.UUID) (*analyticsDomain.PublicAnalytics, error) {
 	args := m.Called(ctx, specID, userID)
 	if args.Get(0) == nil {
@@ -109,13 +116,17 @@ func (m *mockSpecRepo) GetNewestBeats(ctx context.Context, limit int) ([]catalog
 func (m *mockSpecRepo) GetRankedSpecs(ctx context.Context, section, period string, limit int) ([]catalogDomain.RankingRow, error) {
 	return nil, nil
 }
-func (m *mockSpecRepo) GetRankingFreshness(ctx context.Context, section, period string) (*catalogDomain.RankingFreshness, error) {
-	return &catalogDomain.RankingFreshness{}, nil
+func (m *mockSpecRepo) GetRecommendedSpecs(ctx context.Context, userID uuid.UUID, limit int) ([]catalogDomain.RankingRow, error) {
+	return nil, nil
 }
 func (m *mockSpecRepo) RecalculateBeatRankings(ctx context.Context, section, period string) error {
 	return nil
 }
 
+func (m *mockSpecRepo) SuggestSearch(ctx context.Context, query string, limit int) ([]catalogDomain.SearchSuggestion, error) {
+	return nil, nil
+}
+
 type mockFileService struct{ mock.Mock }
 
 func (m *mockFileService) GetPresignedDownloadURL(ctx context.Context, key string, filename string, expiration time.Duration) (string, error) {
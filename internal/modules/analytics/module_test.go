@@ -51,12 +51,15 @@ func (s *specRepoStub) GetNewestBeats(ctx context.Context, limit int) ([]catalog
 func (s *specRepoStub) GetRankedSpecs(ctx context.Context, section, period string, limit int) ([]catalogDomain.RankingRow, error) {
 	return nil, nil
 }
-func (s *specRepoStub) GetRankingFreshness(ctx context.Context, section, period string) (*catalogDomain.RankingFreshness, error) {
-	return &catalogDomain.RankingFreshness{}, nil
+func (s *specRepoStub) GetRecommendedSpecs(ctx context.Context, userID uuid.UUID, limit int) ([]catalogDomain.RankingRow, error) {
+	return nil, nil
 }
 func (s *specRepoStub) RecalculateBeatRankings(ctx context.Context, section, period string) error {
 	return nil
 }
+func (s *specRepoStub) SuggestSearch(ctx context.Context, query string, limit int) ([]catalogDomain.SearchSuggestion, error) {
+	return nil, nil
+}
 
 type fileSvcStub struct{}
 
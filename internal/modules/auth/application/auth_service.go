@@ -15,6 +15,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/saransh1220/blueprint-audio/internal/modules/auth/domain"
 	"github.com/saransh1220/blueprint-audio/internal/modules/auth/infrastructure/jwt"
+	"github.com/saransh1220/blueprint-audio/internal/modules/auth/infrastructure/webauthn"
 	sharedemail "github.com/saransh1220/blueprint-audio/internal/shared/infrastructure/email"
 	"github.com/saransh1220/blueprint-audio/internal/shared/utils"
 	"golang.org/x/crypto/bcrypt"
@@ -25,8 +26,19 @@ var (
 	ErrGoogleAuthFailed            = errors.New("google authentication failed")
 	ErrGoogleClientIDNotConfigured = errors.New("google oauth client id is not configured")
 	ErrAccountSuspended            = errors.New("account suspended")
+	// ErrRefreshTokenSuperseded is a refresh token that was just rotated,
+	// most likely by a concurrent refresh from another tab.
+	ErrRefreshTokenSuperseded = errors.New("refresh token has been rotated")
+	// ErrRefreshTokenReused is a rotated refresh token presented again. Its
+	// session has been revoked.
+	ErrRefreshTokenReused = errors.New("refresh token reused")
 )
 
+// refreshReuseGrace is how long a rotated refresh token is rejected without
+// revoking its session. Tabs sharing the refresh cookie can refresh at the
+// same moment, and only one of them wins the rotation.
+const refreshReuseGrace = 10 * time.Second
+
 type googleAuthError struct {
 	msg string
 }
@@ -101,12 +113,17 @@ type AuthService struct {
 	userRepo             domain.UserRepository
 	sessionRepo          domain.SessionRepository
 	tokenRepo            domain.EmailActionTokenRepository
+	mfaRepo              domain.MFARepository
 	jwtSecret            string
 	jwtExpiry            time.Duration
 	jwtRefreshExpiry     time.Duration
 	googleTokenValidator func(ctx context.Context, token string, audience string) (*idtoken.Payload, error)
 	emailSender          sharedemail.Sender
 	appBaseURL           string
+	sessionCache         domain.SessionCache
+	sessionCacheTTL      time.Duration
+	passkeyRepo          domain.PasskeyRepository
+	relyingParty         webauthn.RelyingParty
 }
 
 type GoogleLoginRequest struct {
@@ -116,9 +133,12 @@ type GoogleLoginRequest struct {
 type TokenPair struct {
 	AccessToken  string
 	RefreshToken string
+	// MFAToken is set instead of the other tokens when the user still has
+	// to pass a second factor with VerifyMFA.
+	MFAToken string
 }
 
-func NewAuthService(userRepo domain.UserRepository, sessionRepo domain.SessionRepository, tokenRepo domain.EmailActionTokenRepository, emailSender sharedemail.Sender, appBaseURL string, jwtSecret string, jwtExpiry time.Duration, jwtRefreshExpiry time.Duration) *AuthService {
+func NewAuthService(userRepo domain.UserRepository, sessionRepo domain.SessionRepository, tokenRepo domain.EmailActionTokenRepository, mfaRepo domain.MFARepository, emailSender sharedemail.Sender, appBaseURL string, jwtSecret string, jwtExpiry time.Duration, jwtRefreshExpiry time.Duration) *AuthService {
 	if emailSender == nil {
 		emailSender = sharedemail.NewSender(sharedemail.Config{})
 	}
@@ -126,6 +146,7 @@ func NewAuthService(userRepo domain.UserRepository, sessionRepo domain.SessionRe
 		userRepo:             userRepo,
 		sessionRepo:          sessionRepo,
 		tokenRepo:            tokenRepo,
+		mfaRepo:              mfaRepo,
 		jwtSecret:            jwtSecret,
 		jwtExpiry:            jwtExpiry,
 		jwtRefreshExpiry:     jwtRefreshExpiry,
@@ -217,7 +238,7 @@ func (s *AuthService) Login(ctx context.Context, req LoginRequest) (*TokenPair,
 		return nil, ErrAccountSuspended
 	}
 
-	return s.generateSession(ctx, user)
+	return s.signIn(ctx, user)
 }
 
 func (s *AuthService) GetUser(ctx context.Context, id uuid.UUID) (*domain.User, error) {
@@ -301,8 +322,11 @@ func (s *AuthService) GoogleLogin(ctx context.Context, googleClientID string, re
 	} else {
 		log.Printf("AuthService.GoogleLogin user found. account=%s user_id=%s", accountKey, user.ID)
 	}
+	if user.Status == domain.UserStatusSuspended {
+		return nil, ErrAccountSuspended
+	}
 
-	tokens, err := s.generateSession(ctx, user)
+	tokens, err := s.signIn(ctx, user)
 	if err != nil {
 		log.Printf("AuthService.GoogleLogin failed to generate session. user_id=%s err=%v", user.ID, err)
 		return nil, err
@@ -390,7 +414,7 @@ func (s *AuthService) ResetPassword(ctx context.Context, req ResetPasswordReques
 	if err != nil {
 		return err
 	}
-	if err := s.sessionRepo.RevokeAllForUser(ctx, token.UserID); err != nil {
+	if err := s.RevokeUserSessions(ctx, token.UserID); err != nil {
 		return fmt.Errorf("failed to revoke old sessions: %w", err)
 	}
 	if err := s.userRepo.UpdatePassword(ctx, token.UserID, string(hashedPass)); err != nil {
@@ -399,8 +423,15 @@ func (s *AuthService) ResetPassword(ctx context.Context, req ResetPasswordReques
 	return nil
 }
 
-func (s *AuthService) generateSession(ctx context.Context, user *domain.User) (*TokenPair, error) {
-	accessToken, err := jwt.GenerateToken(s.jwtSecret, s.jwtExpiry, user.ID, string(user.Role), string(user.SystemRole))
+// generateSession starts a session for a user who passed sign-in, with a
+// second factor when mfaVerified is set.
+func (s *AuthService) generateSession(ctx context.Context, user *domain.User, mfaVerified bool) (*TokenPair, error) {
+	sessionID, err := uuid.NewV7()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate uuid: %w", err)
+	}
+
+	accessToken, err := jwt.GenerateToken(s.jwtSecret, s.jwtExpiry, user.ID, sessionID, string(user.Role), string(user.SystemRole), mfaVerified)
 	if err != nil {
 		return nil, err
 	}
@@ -411,16 +442,19 @@ func (s *AuthService) generateSession(ctx context.Context, user *domain.User) (*
 	}
 
 	expiresAt := time.Now().Add(s.jwtRefreshExpiry)
-	sessionID, err := uuid.NewV7()
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate uuid: %w", err)
-	}
+	client := domain.ClientInfoFromContext(ctx)
+	newDevice := s.isNewDevice(ctx, user.ID, client.Device())
 
 	session := &domain.UserSession{
 		ID:           sessionID,
 		UserID:       user.ID,
 		RefreshToken: refreshTokenString,
 		IsRevoked:    false,
+		MFAVerified:  mfaVerified,
+		Device:       client.Device(),
+		UserAgent:    client.UserAgent,
+		IPAddress:    client.IPAddress,
+		Location:     client.Location,
 		ExpiresAt:    expiresAt,
 		CreatedAt:    time.Now(),
 		UpdatedAt:    time.Now(),
@@ -430,49 +464,96 @@ func (s *AuthService) generateSession(ctx context.Context, user *domain.User) (*
 		return nil, err
 	}
 
+	if newDevice {
+		if err := s.sendSessionAlert(ctx, sharedemail.BuildNewSignInEmail, user, session, session.CreatedAt); err != nil {
+			log.Printf("AuthService.generateSession failed to send new sign-in alert. user_id=%s err=%v", user.ID, err)
+		}
+	}
+
 	return &TokenPair{
 		AccessToken:  accessToken,
 		RefreshToken: refreshTokenString,
 	}, nil
 }
 
-func (s *AuthService) RefreshSession(ctx context.Context, refreshToken string) (string, error) {
+// RefreshSession issues a new access token and rotates the refresh token,
+// so each refresh token can be used once.
+func (s *AuthService) RefreshSession(ctx context.Context, refreshToken string) (*TokenPair, error) {
 	if refreshToken == "" {
-		return "", errors.New("refresh token is required")
+		return nil, errors.New("refresh token is required")
 	}
 
 	session, err := s.sessionRepo.GetByToken(ctx, refreshToken)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	if session == nil {
-		return "", errors.New("invalid refresh token")
+		superseded, err := s.sessionRepo.GetSuperseded(ctx, refreshToken)
+		if err != nil {
+			return nil, err
+		}
+		if superseded != nil {
+			return nil, s.revokeReusedSession(ctx, superseded)
+		}
+		return nil, errors.New("invalid refresh token")
 	}
 	if session.IsRevoked {
-		return "", errors.New("session has been revoked")
+		return nil, errors.New("session has been revoked")
 	}
 	if time.Now().After(session.ExpiresAt) {
-		return "", errors.New("refresh token expired")
+		return nil, errors.New("refresh token expired")
 	}
 
 	user, err := s.userRepo.GetByID(ctx, session.UserID)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+	if user.Status == domain.UserStatusSuspended {
+		return nil, ErrAccountSuspended
 	}
 
-	newAccessToken, err := jwt.GenerateToken(s.jwtSecret, s.jwtExpiry, user.ID, string(user.Role), string(user.SystemRole))
+	newRefreshToken, err := jwt.GenerateRefreshToken()
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+	rotated, err := s.sessionRepo.Rotate(ctx, session.ID, refreshToken, newRefreshToken)
+	if err != nil {
+		return nil, err
+	}
+	if !rotated {
+		return nil, ErrRefreshTokenSuperseded
+	}
+
+	newAccessToken, err := jwt.GenerateToken(s.jwtSecret, s.jwtExpiry, user.ID, session.ID, string(user.Role), string(user.SystemRole), session.MFAVerified)
+	if err != nil {
+		return nil, err
 	}
 
-	return newAccessToken, nil
+	if err := s.sessionRepo.Touch(ctx, session.ID, domain.ClientInfoFromContext(ctx)); err != nil {
+		log.Printf("AuthService.RefreshSession failed to record session use. session_id=%s err=%v", session.ID, err)
+	}
+
+	return &TokenPair{
+		AccessToken:  newAccessToken,
+		RefreshToken: newRefreshToken,
+	}, nil
 }
 
 func (s *AuthService) Logout(ctx context.Context, refreshToken string) error {
 	if refreshToken == "" {
 		return nil
 	}
-	return s.sessionRepo.Revoke(ctx, refreshToken)
+	session, err := s.sessionRepo.GetByToken(ctx, refreshToken)
+	if err != nil {
+		return err
+	}
+	if err := s.sessionRepo.Revoke(ctx, refreshToken); err != nil {
+		return err
+	}
+	if session != nil {
+		s.denySessions(ctx, session.ID)
+	}
+	return nil
 }
 
 func (s *AuthService) sendVerificationCode(ctx context.Context, user *domain.User) error {
@@ -69,11 +69,48 @@ func (m *mockSessionRepository) GetByToken(ctx context.Context, token string) (*
 	}
 	return args.Get(0).(*domain.UserSession), args.Error(1)
 }
+func (m *mockSessionRepository) Rotate(ctx context.Context, sessionID uuid.UUID, oldToken, newToken string) (bool, error) {
+	args := m.Called(ctx, sessionID, oldToken, newToken)
+	return args.Bool(0), args.Error(1)
+}
+func (m *mockSessionRepository) GetSuperseded(ctx context.Context, token string) (*domain.SupersededRefreshToken, error) {
+	args := m.Called(ctx, token)
+	superseded, _ := args.Get(0).(*domain.SupersededRefreshToken)
+	return superseded, args.Error(1)
+}
 func (m *mockSessionRepository) Revoke(ctx context.Context, token string) error {
 	return m.Called(ctx, token).Error(0)
 }
-func (m *mockSessionRepository) RevokeAllForUser(ctx context.Context, userID uuid.UUID) error {
-	return m.Called(ctx, userID).Error(0)
+func (m *mockSessionRepository) RevokeAllForUser(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error) {
+	args := m.Called(ctx, userID)
+	ids, _ := args.Get(0).([]uuid.UUID)
+	return ids, args.Error(1)
+}
+func (m *mockSessionRepository) IsActive(ctx context.Context, sessionID uuid.UUID) (bool, error) {
+	args := m.Called(ctx, sessionID)
+	return args.Bool(0), args.Error(1)
+}
+func (m *mockSessionRepository) Touch(ctx context.Context, sessionID uuid.UUID, client domain.ClientInfo) error {
+	return m.Called(ctx, sessionID, client).Error(0)
+}
+func (m *mockSessionRepository) ListActiveByUserID(ctx context.Context, userID uuid.UUID) ([]domain.UserSession, error) {
+	args := m.Called(ctx, userID)
+	sessions, _ := args.Get(0).([]domain.UserSession)
+	return sessions, args.Error(1)
+}
+func (m *mockSessionRepository) RevokeByID(ctx context.Context, userID, sessionID uuid.UUID) (bool, error) {
+	args := m.Called(ctx, userID, sessionID)
+	return args.Bool(0), args.Error(1)
+}
+func (m *mockSessionRepository) RevokeOthers(ctx context.Context, userID, keepID uuid.UUID) ([]uuid.UUID, error) {
+	args := m.Called(ctx, userID, keepID)
+	ids, _ := args.Get(0).([]uuid.UUID)
+	return ids, args.Error(1)
+}
+func (m *mockSessionRepository) KnownDevices(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	args := m.Called(ctx, userID)
+	devices, _ := args.Get(0).([]string)
+	return devices, args.Error(1)
 }
 
 type mockTokenRepository struct{ mock.Mock }
@@ -104,15 +141,19 @@ func newAuthServiceHarness(t *testing.T) (*AuthService, *mockUserRepository, *mo
 	repo := new(mockUserRepository)
 	sessionRepo := new(mockSessionRepository)
 	tokenRepo := new(mockTokenRepository)
+	mfaRepo := new(mockMFARepository)
+	mfaRepo.On("ClaimMFAAttempt", mock.Anything, mock.Anything).Return(true, nil).Maybe()
+	mfaRepo.On("ResetMFAAttempts", mock.Anything, mock.Anything).Return(nil).Maybe()
 	emailSender := new(mockEmailSender)
 	t.Cleanup(func() {
 		repo.AssertExpectations(t)
 		sessionRepo.AssertExpectations(t)
 		tokenRepo.AssertExpectations(t)
+		mfaRepo.AssertExpectations(t)
 		emailSender.AssertExpectations(t)
 	})
 
-	return NewAuthService(repo, sessionRepo, tokenRepo, emailSender, "http://localhost:4200", "secret", time.Hour, time.Hour*720), repo, sessionRepo, tokenRepo, emailSender
+	return NewAuthService(repo, sessionRepo, tokenRepo, mfaRepo, emailSender, "http://localhost:4200", "secret", time.Hour, time.Hour*720), repo, sessionRepo, tokenRepo, emailSender
 }
 
 func TestRegister_Success(t *testing.T) {
@@ -256,6 +297,7 @@ func TestLogin(t *testing.T) {
 		assert.NoError(t, err)
 		user := &domain.User{ID: uuid.New(), Email: "a@a.com", PasswordHash: string(hash), Role: domain.RoleProducer, EmailVerified: true}
 		repo.On("GetByEmail", ctx, "a@a.com").Return(user, nil).Once()
+		mfaRepoOf(svc).On("GetTOTP", ctx, user.ID).Return(nil, nil).Once()
 		sessionRepo.On("Create", mock.Anything, mock.AnythingOfType("*domain.UserSession")).
 			Run(func(args mock.Arguments) {
 				session := args.Get(1).(*domain.UserSession)
@@ -304,7 +346,7 @@ func TestVerifyEmailAndResetPassword(t *testing.T) {
 		tokenRepo.On("Consume", ctx, "user@example.com", domain.TokenPurposeResetPassword, "654321").
 			Return(&domain.EmailActionToken{UserID: userID}, nil).Once()
 		repo.On("UpdatePassword", ctx, userID, mock.AnythingOfType("string")).Return(nil).Once()
-		sessionRepo.On("RevokeAllForUser", ctx, userID).Return(nil).Once()
+		sessionRepo.On("RevokeAllForUser", ctx, userID).Return([]uuid.UUID{uuid.New()}, nil).Once()
 
 		err := svc.ResetPassword(ctx, ResetPasswordRequest{Email: "user@example.com", Code: "654321", NewPassword: "newpassword123"})
 		assert.NoError(t, err)
@@ -409,6 +451,7 @@ func TestGoogleLogin_CreateUserSuccess(t *testing.T) {
 
 	repo.On("GetByEmail", mock.Anything, "new2@example.com").Return(nil, domain.ErrUserNotFound).Once()
 	repo.On("Create", mock.Anything, mock.AnythingOfType("*domain.User")).Return(nil).Once()
+	mfaRepoOf(svc).On("GetTOTP", mock.Anything, mock.AnythingOfType("uuid.UUID")).Return(nil, nil).Once()
 
 	token, err := svc.GoogleLogin(context.Background(), "google-client", GoogleLoginRequest{Token: "token"})
 	assert.NoError(t, err)
@@ -433,6 +476,7 @@ func TestGoogleLogin_ExistingUserSuccess(t *testing.T) {
 
 	existing := &domain.User{ID: uuid.New(), Email: "existing@example.com", Role: domain.RoleProducer, EmailVerified: true}
 	repo.On("GetByEmail", mock.Anything, "existing@example.com").Return(existing, nil).Once()
+	mfaRepoOf(svc).On("GetTOTP", mock.Anything, existing.ID).Return(nil, nil).Once()
 
 	token, err := svc.GoogleLogin(context.Background(), "google-client", GoogleLoginRequest{Token: "token"})
 	assert.NoError(t, err)
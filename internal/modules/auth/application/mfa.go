@@ -0,0 +1,309 @@
+package application
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/saransh1220/blueprint-audio/internal/modules/auth/domain"
+	"github.com/saransh1220/blueprint-audio/internal/modules/auth/infrastructure/jwt"
+	"github.com/saransh1220/blueprint-audio/internal/modules/auth/infrastructure/totp"
+)
+
+const (
+	// mfaChallengeTTL is how long a user has to enter their second factor
+	// after their password.
+	mfaChallengeTTL   = 5 * time.Minute
+	recoveryCodeCount = 10
+	totpIssuer        = "Blueprint"
+)
+
+type TOTPSetup struct {
+	Secret string `json:"secret"`
+	// ProvisioningURI is the otpauth:// URI to show as a QR code.
+	ProvisioningURI string `json:"otpauth_uri"`
+}
+
+type MFAStatus struct {
+	TOTPEnabled            bool `json:"totp_enabled"`
+	RecoveryCodesRemaining int  `json:"recovery_codes_remaining"`
+	// Required is set when the user's system role requires a second factor.
+	Required bool `json:"required"`
+}
+
+type VerifyMFARequest struct {
+	MFAToken string `json:"mfa_token"`
+	// Code is a code from the authenticator app or a recovery code.
+	Code string `json:"code"`
+}
+
+type MFACodeRequest struct {
+	Code string `json:"code"`
+}
+
+// signIn finishes the first sign-in factor. Users with a second factor get
+// a challenge to complete with VerifyMFA instead of a session.
+func (s *AuthService) signIn(ctx context.Context, user *domain.User) (*TokenPair, error) {
+	enrollment, err := s.mfaRepo.GetTOTP(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !enrollment.Enabled() {
+		return s.generateSession(ctx, user, false)
+	}
+
+	challenge, err := jwt.GenerateMFAChallenge(s.jwtSecret, mfaChallengeTTL, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &TokenPair{MFAToken: challenge}, nil
+}
+
+// VerifyMFA completes a sign-in challenged for a second factor.
+func (s *AuthService) VerifyMFA(ctx context.Context, req VerifyMFARequest) (*TokenPair, error) {
+	userID, err := jwt.ValidateMFAChallenge(req.MFAToken, s.jwtSecret)
+	if err != nil {
+		return nil, domain.ErrInvalidMFAChallenge
+	}
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Status == domain.UserStatusSuspended {
+		return nil, ErrAccountSuspended
+	}
+	enrollment, err := s.mfaRepo.GetTOTP(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !enrollment.Enabled() {
+		// Disabled since the challenge was issued.
+		return nil, domain.ErrInvalidMFAChallenge
+	}
+	if err := s.verifySecondFactor(ctx, enrollment, req.Code); err != nil {
+		return nil, err
+	}
+	return s.generateSession(ctx, user, true)
+}
+
+func (s *AuthService) MFAStatus(ctx context.Context, userID uuid.UUID) (*MFAStatus, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	required, err := s.mfaRepo.MFARequired(ctx, user.SystemRole)
+	if err != nil {
+		return nil, err
+	}
+	enrollment, err := s.mfaRepo.GetTOTP(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	status := &MFAStatus{TOTPEnabled: enrollment.Enabled(), Required: required}
+	if status.TOTPEnabled {
+		if status.RecoveryCodesRemaining, err = s.mfaRepo.CountRecoveryCodes(ctx, userID); err != nil {
+			return nil, err
+		}
+	}
+	return status, nil
+}
+
+// SetupTOTP generates a new authenticator secret. It protects sign-in once
+// confirmed with EnableTOTP.
+func (s *AuthService) SetupTOTP(ctx context.Context, userID uuid.UUID) (*TOTPSetup, error) {
+	enrollment, err := s.mfaRepo.GetTOTP(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if enrollment.Enabled() {
+		return nil, domain.ErrMFAAlreadyEnabled
+	}
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.mfaRepo.SaveTOTPSecret(ctx, userID, secret); err != nil {
+		return nil, err
+	}
+	return &TOTPSetup{
+		Secret:          secret,
+		ProvisioningURI: totp.ProvisioningURI(totpIssuer, user.Email, secret),
+	}, nil
+}
+
+// EnableTOTP confirms the authenticator app with a code and returns the
+// recovery codes, which are only ever shown this once.
+func (s *AuthService) EnableTOTP(ctx context.Context, userID uuid.UUID, code string) ([]string, error) {
+	enrollment, err := s.mfaRepo.GetTOTP(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if enrollment == nil {
+		return nil, domain.ErrMFANotSetUp
+	}
+	if enrollment.Enabled() {
+		return nil, domain.ErrMFAAlreadyEnabled
+	}
+	step, ok := totp.Validate(enrollment.Secret, code, time.Now())
+	if !ok {
+		return nil, domain.ErrInvalidMFACode
+	}
+
+	codes, digests, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.mfaRepo.EnableTOTP(ctx, userID, step, digests); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// DisableTOTP turns the second factor off, unless the user's system role
+// requires one.
+func (s *AuthService) DisableTOTP(ctx context.Context, userID uuid.UUID, code string) error {
+	enrollment, err := s.enabledTOTP(ctx, userID)
+	if err != nil {
+		return err
+	}
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	required, err := s.mfaRepo.MFARequired(ctx, user.SystemRole)
+	if err != nil {
+		return err
+	}
+	if required {
+		return domain.ErrMFARequiredForRole
+	}
+	if err := s.verifySecondFactor(ctx, enrollment, code); err != nil {
+		return err
+	}
+	return s.mfaRepo.DisableTOTP(ctx, userID)
+}
+
+// RegenerateRecoveryCodes replaces the user's recovery codes, used or not.
+func (s *AuthService) RegenerateRecoveryCodes(ctx context.Context, userID uuid.UUID, code string) ([]string, error) {
+	enrollment, err := s.enabledTOTP(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.verifySecondFactor(ctx, enrollment, code); err != nil {
+		return nil, err
+	}
+	codes, digests, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.mfaRepo.ReplaceRecoveryCodes(ctx, userID, digests); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// MFARequired reports whether members of the system role must have signed
+// in with a second factor to act with it.
+func (s *AuthService) MFARequired(ctx context.Context, systemRole string) (bool, error) {
+	return s.mfaRepo.MFARequired(ctx, domain.SystemRole(systemRole))
+}
+
+func (s *AuthService) enabledTOTP(ctx context.Context, userID uuid.UUID) (*domain.TOTPEnrollment, error) {
+	enrollment, err := s.mfaRepo.GetTOTP(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !enrollment.Enabled() {
+		return nil, domain.ErrMFANotEnabled
+	}
+	return enrollment, nil
+}
+
+// verifySecondFactor accepts a current authenticator code that has not been
+// used yet, or an unused recovery code. Attempts are counted per user, so
+// guesses spread over several challenges or addresses still lock the second
+// factor. A lockout outlasts mfaChallengeTTL, so it also ends every challenge
+// issued before it.
+func (s *AuthService) verifySecondFactor(ctx context.Context, enrollment *domain.TOTPEnrollment, code string) error {
+	allowed, err := s.mfaRepo.ClaimMFAAttempt(ctx, enrollment.UserID)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return domain.ErrMFALocked
+	}
+	if err := s.checkSecondFactor(ctx, enrollment, code); err != nil {
+		return err
+	}
+	return s.mfaRepo.ResetMFAAttempts(ctx, enrollment.UserID)
+}
+
+func (s *AuthService) checkSecondFactor(ctx context.Context, enrollment *domain.TOTPEnrollment, code string) error {
+	code = strings.TrimSpace(code)
+	if step, ok := totp.Validate(enrollment.Secret, code, time.Now()); ok {
+		fresh, err := s.mfaRepo.UseTOTPStep(ctx, enrollment.UserID, step)
+		if err != nil {
+			return err
+		}
+		if !fresh {
+			return domain.ErrInvalidMFACode
+		}
+		return nil
+	}
+
+	normalized := normalizeRecoveryCode(code)
+	if len(normalized) != recoveryCodeLength {
+		return domain.ErrInvalidMFACode
+	}
+	used, err := s.mfaRepo.UseRecoveryCode(ctx, enrollment.UserID, digestRecoveryCode(normalized))
+	if err != nil {
+		return err
+	}
+	if !used {
+		return domain.ErrInvalidMFACode
+	}
+	return nil
+}
+
+// recoveryCodeLength is the number of characters in a recovery code,
+// shown as two dash-separated halves.
+const recoveryCodeLength = 10
+
+var recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// generateRecoveryCodes returns new recovery codes and the digests to store.
+func generateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	digests := make([]string, 0, recoveryCodeCount)
+	for range recoveryCodeCount {
+		b := make([]byte, 8)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, err
+		}
+		raw := strings.ToLower(recoveryCodeEncoding.EncodeToString(b))[:recoveryCodeLength]
+		codes = append(codes, raw[:recoveryCodeLength/2]+"-"+raw[recoveryCodeLength/2:])
+		digests = append(digests, digestRecoveryCode(raw))
+	}
+	return codes, digests, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	code = strings.ReplaceAll(code, "-", "")
+	return strings.ReplaceAll(code, " ", "")
+}
+
+func digestRecoveryCode(normalized string) string {
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
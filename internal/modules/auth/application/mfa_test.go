@@ -0,0 +1,321 @@
+package application
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/saransh1220/blueprint-audio/internal/modules/auth/domain"
+	"github.com/saransh1220/blueprint-audio/internal/modules/auth/infrastructure/jwt"
+	"github.com/saransh1220/blueprint-audio/internal/modules/auth/infrastructure/totp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type mockMFARepository struct{ mock.Mock }
+
+func (m *mockMFARepository) GetTOTP(ctx context.Context, userID uuid.UUID) (*domain.TOTPEnrollment, error) {
+	args := m.Called(ctx, userID)
+	enrollment, _ := args.Get(0).(*domain.TOTPEnrollment)
+	return enrollment, args.Error(1)
+}
+func (m *mockMFARepository) SaveTOTPSecret(ctx context.Context, userID uuid.UUID, secret string) error {
+	return m.Called(ctx, userID, secret).Error(0)
+}
+func (m *mockMFARepository) EnableTOTP(ctx context.Context, userID uuid.UUID, step int64, digests []string) error {
+	return m.Called(ctx, userID, step, digests).Error(0)
+}
+func (m *mockMFARepository) DisableTOTP(ctx context.Context, userID uuid.UUID) error {
+	return m.Called(ctx, userID).Error(0)
+}
+func (m *mockMFARepository) UseTOTPStep(ctx context.Context, userID uuid.UUID, step int64) (bool, error) {
+	args := m.Called(ctx, userID, step)
+	return args.Bool(0), args.Error(1)
+}
+func (m *mockMFARepository) ClaimMFAAttempt(ctx context.Context, userID uuid.UUID) (bool, error) {
+	args := m.Called(ctx, userID)
+	return args.Bool(0), args.Error(1)
+}
+func (m *mockMFARepository) ResetMFAAttempts(ctx context.Context, userID uuid.UUID) error {
+	return m.Called(ctx, userID).Error(0)
+}
+func (m *mockMFARepository) ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, digests []string) error {
+	return m.Called(ctx, userID, digests).Error(0)
+}
+func (m *mockMFARepository) UseRecoveryCode(ctx context.Context, userID uuid.UUID, digest string) (bool, error) {
+	args := m.Called(ctx, userID, digest)
+	return args.Bool(0), args.Error(1)
+}
+func (m *mockMFARepository) CountRecoveryCodes(ctx context.Context, userID uuid.UUID) (int, error) {
+	args := m.Called(ctx, userID)
+	return args.Int(0), args.Error(1)
+}
+func (m *mockMFARepository) MFARequired(ctx context.Context, role domain.SystemRole) (bool, error) {
+	args := m.Called(ctx, role)
+	return args.Bool(0), args.Error(1)
+}
+
+func mfaRepoOf(svc *AuthService) *mockMFARepository {
+	return svc.mfaRepo.(*mockMFARepository)
+}
+
+func enabledEnrollment(t *testing.T, userID uuid.UUID) *domain.TOTPEnrollment {
+	t.Helper()
+	secret, err := totp.GenerateSecret()
+	require.NoError(t, err)
+	enabledAt := time.Now().Add(-time.Hour)
+	return &domain.TOTPEnrollment{UserID: userID, Secret: secret, EnabledAt: &enabledAt}
+}
+
+func currentCode(t *testing.T, secret string) (string, int64) {
+	t.Helper()
+	step := totp.Step(time.Now())
+	code, err := totp.Code(secret, step)
+	require.NoError(t, err)
+	return code, step
+}
+
+func TestLoginWithTOTPRequiresSecondFactor(t *testing.T) {
+	ctx := context.Background()
+	svc, users, sessions, _, _ := newAuthServiceHarness(t)
+	mfa := mfaRepoOf(svc)
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	require.NoError(t, err)
+	user := &domain.User{ID: uuid.New(), Email: "a@a.com", PasswordHash: string(hash), Role: domain.RoleProducer, SystemRole: domain.SystemRoleSuperAdmin, EmailVerified: true}
+	enrollment := enabledEnrollment(t, user.ID)
+	users.On("GetByEmail", ctx, "a@a.com").Return(user, nil).Once()
+	mfa.On("GetTOTP", ctx, user.ID).Return(enrollment, nil)
+
+	tokens, err := svc.Login(ctx, LoginRequest{Email: "a@a.com", Password: "password123"})
+	require.NoError(t, err)
+	assert.Empty(t, tokens.AccessToken)
+	assert.Empty(t, tokens.RefreshToken)
+	require.NotEmpty(t, tokens.MFAToken)
+
+	t.Run("rejects a bad challenge", func(t *testing.T) {
+		_, err := svc.VerifyMFA(ctx, VerifyMFARequest{MFAToken: "nope", Code: "123456"})
+		assert.ErrorIs(t, err, domain.ErrInvalidMFAChallenge)
+
+		// An access token is not a challenge.
+		access, err := jwt.GenerateToken("secret", time.Hour, user.ID, uuid.New(), "producer", "", false)
+		require.NoError(t, err)
+		_, err = svc.VerifyMFA(ctx, VerifyMFARequest{MFAToken: access, Code: "123456"})
+		assert.ErrorIs(t, err, domain.ErrInvalidMFAChallenge)
+	})
+
+	t.Run("rejects a wrong code", func(t *testing.T) {
+		users.On("GetByID", ctx, user.ID).Return(user, nil).Once()
+		mfa.On("UseRecoveryCode", ctx, user.ID, mock.Anything).Return(false, nil).Once()
+
+		_, err := svc.VerifyMFA(ctx, VerifyMFARequest{MFAToken: tokens.MFAToken, Code: "abcde-fghij"})
+		assert.ErrorIs(t, err, domain.ErrInvalidMFACode)
+	})
+
+	t.Run("rejects a replayed code", func(t *testing.T) {
+		code, step := currentCode(t, enrollment.Secret)
+		users.On("GetByID", ctx, user.ID).Return(user, nil).Once()
+		mfa.On("UseTOTPStep", ctx, user.ID, step).Return(false, nil).Once()
+
+		_, err := svc.VerifyMFA(ctx, VerifyMFARequest{MFAToken: tokens.MFAToken, Code: code})
+		assert.ErrorIs(t, err, domain.ErrInvalidMFACode)
+	})
+
+	t.Run("issues an MFA session for a valid code", func(t *testing.T) {
+		code, step := currentCode(t, enrollment.Secret)
+		users.On("GetByID", ctx, user.ID).Return(user, nil).Once()
+		mfa.On("UseTOTPStep", ctx, user.ID, step).Return(true, nil).Once()
+		sessions.On("Create", ctx, mock.MatchedBy(func(s *domain.UserSession) bool { return s.MFAVerified })).Return(nil).Once()
+
+		pair, err := svc.VerifyMFA(ctx, VerifyMFARequest{MFAToken: tokens.MFAToken, Code: code})
+		require.NoError(t, err)
+		claims, err := jwt.ValidateToken(pair.AccessToken, "secret")
+		require.NoError(t, err)
+		assert.True(t, claims.MFA)
+		assert.Equal(t, "super_admin", claims.SystemRole)
+	})
+
+	t.Run("accepts a recovery code", func(t *testing.T) {
+		users.On("GetByID", ctx, user.ID).Return(user, nil).Once()
+		mfa.On("UseRecoveryCode", ctx, user.ID, digestRecoveryCode("abcdefghij")).Return(true, nil).Once()
+		sessions.On("Create", ctx, mock.AnythingOfType("*domain.UserSession")).Return(nil).Once()
+
+		_, err := svc.VerifyMFA(ctx, VerifyMFARequest{MFAToken: tokens.MFAToken, Code: " ABCDE-FGHIJ "})
+		require.NoError(t, err)
+	})
+}
+
+func TestVerifyMFALocksAfterRepeatedFailures(t *testing.T) {
+	ctx := context.Background()
+	svc, users, _, _, _ := newAuthServiceHarness(t)
+	mfa := new(mockMFARepository)
+	svc.mfaRepo = mfa
+	user := &domain.User{ID: uuid.New()}
+	enrollment := enabledEnrollment(t, user.ID)
+	challenge, err := jwt.GenerateMFAChallenge("secret", time.Minute, user.ID)
+	require.NoError(t, err)
+	users.On("GetByID", ctx, user.ID).Return(user, nil)
+	mfa.On("GetTOTP", ctx, user.ID).Return(enrollment, nil)
+
+	mfa.On("ClaimMFAAttempt", ctx, user.ID).Return(true, nil).Once()
+	mfa.On("UseRecoveryCode", ctx, user.ID, mock.Anything).Return(false, nil).Once()
+	_, err = svc.VerifyMFA(ctx, VerifyMFARequest{MFAToken: challenge, Code: "abcde-fghij"})
+	assert.ErrorIs(t, err, domain.ErrInvalidMFACode)
+
+	// Once locked, even a valid code is not checked.
+	code, _ := currentCode(t, enrollment.Secret)
+	mfa.On("ClaimMFAAttempt", ctx, user.ID).Return(false, nil).Once()
+	_, err = svc.VerifyMFA(ctx, VerifyMFARequest{MFAToken: challenge, Code: code})
+	assert.ErrorIs(t, err, domain.ErrMFALocked)
+
+	mfa.AssertExpectations(t)
+	mfa.AssertNotCalled(t, "UseTOTPStep", mock.Anything, mock.Anything, mock.Anything)
+	mfa.AssertNotCalled(t, "ResetMFAAttempts", mock.Anything, mock.Anything)
+}
+
+func TestVerifyMFARejectsSuspendedUsers(t *testing.T) {
+	ctx := context.Background()
+	svc, users, _, _, _ := newAuthServiceHarness(t)
+	user := &domain.User{ID: uuid.New(), Status: domain.UserStatusSuspended}
+	challenge, err := jwt.GenerateMFAChallenge("secret", time.Minute, user.ID)
+	require.NoError(t, err)
+	users.On("GetByID", ctx, user.ID).Return(user, nil).Once()
+
+	_, err = svc.VerifyMFA(ctx, VerifyMFARequest{MFAToken: challenge, Code: "123456"})
+	assert.ErrorIs(t, err, ErrAccountSuspended)
+}
+
+func TestTOTPEnrollment(t *testing.T) {
+	ctx := context.Background()
+	user := &domain.User{ID: uuid.New(), Email: "producer@example.com"}
+
+	t.Run("setup", func(t *testing.T) {
+		svc, users, _, _, _ := newAuthServiceHarness(t)
+		mfa := mfaRepoOf(svc)
+		mfa.On("GetTOTP", ctx, user.ID).Return(nil, nil).Once()
+		users.On("GetByID", ctx, user.ID).Return(user, nil).Once()
+		var saved string
+		mfa.On("SaveTOTPSecret", ctx, user.ID, mock.Anything).Run(func(args mock.Arguments) {
+			saved = args.String(2)
+		}).Return(nil).Once()
+
+		setup, err := svc.SetupTOTP(ctx, user.ID)
+		require.NoError(t, err)
+		assert.Equal(t, saved, setup.Secret)
+		assert.Contains(t, setup.ProvisioningURI, "otpauth://totp/Blueprint:producer@example.com?")
+		assert.Contains(t, setup.ProvisioningURI, "secret="+saved)
+	})
+
+	t.Run("setup when already enabled", func(t *testing.T) {
+		svc, _, _, _, _ := newAuthServiceHarness(t)
+		mfaRepoOf(svc).On("GetTOTP", ctx, user.ID).Return(enabledEnrollment(t, user.ID), nil).Once()
+
+		_, err := svc.SetupTOTP(ctx, user.ID)
+		assert.ErrorIs(t, err, domain.ErrMFAAlreadyEnabled)
+	})
+
+	t.Run("enable", func(t *testing.T) {
+		svc, _, _, _, _ := newAuthServiceHarness(t)
+		mfa := mfaRepoOf(svc)
+		secret, err := totp.GenerateSecret()
+		require.NoError(t, err)
+		pending := &domain.TOTPEnrollment{UserID: user.ID, Secret: secret}
+		mfa.On("GetTOTP", ctx, user.ID).Return(pending, nil).Twice()
+
+		_, err = svc.EnableTOTP(ctx, user.ID, "12345")
+		assert.ErrorIs(t, err, domain.ErrInvalidMFACode)
+
+		code, step := currentCode(t, secret)
+		var digests []string
+		mfa.On("EnableTOTP", ctx, user.ID, step, mock.Anything).Run(func(args mock.Arguments) {
+			digests = args.Get(3).([]string)
+		}).Return(nil).Once()
+		codes, err := svc.EnableTOTP(ctx, user.ID, code)
+		require.NoError(t, err)
+		require.Len(t, codes, recoveryCodeCount)
+		require.Len(t, digests, recoveryCodeCount)
+		for i, c := range codes {
+			assert.Len(t, c, recoveryCodeLength+1)
+			assert.Equal(t, "-", c[5:6])
+			assert.Equal(t, digestRecoveryCode(strings.ReplaceAll(c, "-", "")), digests[i])
+		}
+	})
+
+	t.Run("enable without setup", func(t *testing.T) {
+		svc, _, _, _, _ := newAuthServiceHarness(t)
+		mfaRepoOf(svc).On("GetTOTP", ctx, user.ID).Return(nil, nil).Once()
+
+		_, err := svc.EnableTOTP(ctx, user.ID, "123456")
+		assert.ErrorIs(t, err, domain.ErrMFANotSetUp)
+	})
+}
+
+func TestDisableTOTPAndRecoveryCodes(t *testing.T) {
+	ctx := context.Background()
+	admin := &domain.User{ID: uuid.New(), SystemRole: domain.SystemRoleSuperAdmin}
+	user := &domain.User{ID: uuid.New(), SystemRole: domain.SystemRoleUser}
+
+	t.Run("not enabled", func(t *testing.T) {
+		svc, _, _, _, _ := newAuthServiceHarness(t)
+		mfaRepoOf(svc).On("GetTOTP", ctx, user.ID).Return(nil, nil).Twice()
+
+		assert.ErrorIs(t, svc.DisableTOTP(ctx, user.ID, "123456"), domain.ErrMFANotEnabled)
+		_, err := svc.RegenerateRecoveryCodes(ctx, user.ID, "123456")
+		assert.ErrorIs(t, err, domain.ErrMFANotEnabled)
+	})
+
+	t.Run("required for role", func(t *testing.T) {
+		svc, users, _, _, _ := newAuthServiceHarness(t)
+		mfa := mfaRepoOf(svc)
+		mfa.On("GetTOTP", ctx, admin.ID).Return(enabledEnrollment(t, admin.ID), nil).Once()
+		users.On("GetByID", ctx, admin.ID).Return(admin, nil).Once()
+		mfa.On("MFARequired", ctx, domain.SystemRoleSuperAdmin).Return(true, nil).Once()
+
+		assert.ErrorIs(t, svc.DisableTOTP(ctx, admin.ID, "123456"), domain.ErrMFARequiredForRole)
+	})
+
+	t.Run("disable", func(t *testing.T) {
+		svc, users, _, _, _ := newAuthServiceHarness(t)
+		mfa := mfaRepoOf(svc)
+		enrollment := enabledEnrollment(t, user.ID)
+		code, step := currentCode(t, enrollment.Secret)
+		mfa.On("GetTOTP", ctx, user.ID).Return(enrollment, nil).Once()
+		users.On("GetByID", ctx, user.ID).Return(user, nil).Once()
+		mfa.On("MFARequired", ctx, domain.SystemRoleUser).Return(false, nil).Once()
+		mfa.On("UseTOTPStep", ctx, user.ID, step).Return(true, nil).Once()
+		mfa.On("DisableTOTP", ctx, user.ID).Return(nil).Once()
+
+		require.NoError(t, svc.DisableTOTP(ctx, user.ID, code))
+	})
+
+	t.Run("regenerate recovery codes", func(t *testing.T) {
+		svc, _, _, _, _ := newAuthServiceHarness(t)
+		mfa := mfaRepoOf(svc)
+		enrollment := enabledEnrollment(t, user.ID)
+		code, step := currentCode(t, enrollment.Secret)
+		mfa.On("GetTOTP", ctx, user.ID).Return(enrollment, nil).Once()
+		mfa.On("UseTOTPStep", ctx, user.ID, step).Return(true, nil).Once()
+		mfa.On("ReplaceRecoveryCodes", ctx, user.ID, mock.MatchedBy(func(d []string) bool { return len(d) == recoveryCodeCount })).Return(nil).Once()
+
+		codes, err := svc.RegenerateRecoveryCodes(ctx, user.ID, code)
+		require.NoError(t, err)
+		assert.Len(t, codes, recoveryCodeCount)
+	})
+
+	t.Run("status", func(t *testing.T) {
+		svc, users, _, _, _ := newAuthServiceHarness(t)
+		mfa := mfaRepoOf(svc)
+		users.On("GetByID", ctx, admin.ID).Return(admin, nil).Once()
+		mfa.On("MFARequired", ctx, domain.SystemRoleSuperAdmin).Return(true, nil).Once()
+		mfa.On("GetTOTP", ctx, admin.ID).Return(enabledEnrollment(t, admin.ID), nil).Once()
+		mfa.On("CountRecoveryCodes", ctx, admin.ID).Return(7, nil).Once()
+
+		status, err := svc.MFAStatus(ctx, admin.ID)
+		require.NoError(t, err)
+		assert.Equal(t, &MFAStatus{TOTPEnabled: true, RecoveryCodesRemaining: 7, Required: true}, status)
+	})
+}
@@ -0,0 +1,299 @@
+package application
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/saransh1220/blueprint-audio/internal/modules/auth/domain"
+	"github.com/saransh1220/blueprint-audio/internal/modules/auth/infrastructure/webauthn"
+)
+
+const (
+	// passkeyChallengeTTL is how long the browser has to finish a ceremony.
+	passkeyChallengeTTL   = 5 * time.Minute
+	maxPasskeyNameLength  = 100
+	defaultPasskeyName    = "Passkey"
+	passkeyCredentialType = "public-key"
+)
+
+type PasskeyRelyingParty struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type PasskeyUser struct {
+	// ID is the user handle authenticators store with the passkey.
+	ID          webauthn.Base64URL `json:"id"`
+	Name        string             `json:"name"`
+	DisplayName string             `json:"displayName"`
+}
+
+type PasskeyCredentialParameter struct {
+	Type string `json:"type"`
+	Alg  int64  `json:"alg"`
+}
+
+type PasskeyDescriptor struct {
+	Type string             `json:"type"`
+	ID   webauthn.Base64URL `json:"id"`
+}
+
+type PasskeyAuthenticatorSelection struct {
+	ResidentKey        string `json:"residentKey"`
+	RequireResidentKey bool   `json:"requireResidentKey"`
+	UserVerification   string `json:"userVerification"`
+}
+
+// PasskeyCreationOptions are the publicKey options for
+// navigator.credentials.create().
+type PasskeyCreationOptions struct {
+	Challenge              string                        `json:"challenge"`
+	RP                     PasskeyRelyingParty           `json:"rp"`
+	User                   PasskeyUser                   `json:"user"`
+	PubKeyCredParams       []PasskeyCredentialParameter  `json:"pubKeyCredParams"`
+	Timeout                int64                         `json:"timeout"`
+	ExcludeCredentials     []PasskeyDescriptor           `json:"excludeCredentials"`
+	AuthenticatorSelection PasskeyAuthenticatorSelection `json:"authenticatorSelection"`
+	Attestation            string                        `json:"attestation"`
+}
+
+// PasskeyRequestOptions are the publicKey options for
+// navigator.credentials.get(). No credentials are listed, so the browser
+// offers every passkey it has for the site.
+type PasskeyRequestOptions struct {
+	Challenge        string              `json:"challenge"`
+	RPID             string              `json:"rpId"`
+	Timeout          int64               `json:"timeout"`
+	UserVerification string              `json:"userVerification"`
+	AllowCredentials []PasskeyDescriptor `json:"allowCredentials"`
+}
+
+type PasskeyCredentialResponse struct {
+	ClientDataJSON    webauthn.Base64URL `json:"clientDataJSON"`
+	AttestationObject webauthn.Base64URL `json:"attestationObject,omitempty"`
+	AuthenticatorData webauthn.Base64URL `json:"authenticatorData,omitempty"`
+	Signature         webauthn.Base64URL `json:"signature,omitempty"`
+	UserHandle        webauthn.Base64URL `json:"userHandle,omitempty"`
+}
+
+// PasskeyCredential is the JSON form of the browser's PublicKeyCredential.
+type PasskeyCredential struct {
+	RawID    webauthn.Base64URL        `json:"rawId"`
+	Response PasskeyCredentialResponse `json:"response"`
+}
+
+type PasskeyRegistrationRequest struct {
+	// Name labels the passkey in the user's list; it defaults to the device
+	// it was registered on.
+	Name       string            `json:"name"`
+	Credential PasskeyCredential `json:"credential"`
+}
+
+type PasskeyLoginRequest struct {
+	Credential PasskeyCredential `json:"credential"`
+}
+
+// SetPasskeys enables passkey sign-in for the relying party.
+func (s *AuthService) SetPasskeys(repo domain.PasskeyRepository, rp webauthn.RelyingParty) {
+	s.passkeyRepo = repo
+	s.relyingParty = rp
+}
+
+// BeginPasskeyRegistration starts adding a passkey to the user's account.
+func (s *AuthService) BeginPasskeyRegistration(ctx context.Context, userID uuid.UUID) (*PasskeyCreationOptions, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := s.passkeyRepo.ListByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	challenge, err := s.issuePasskeyChallenge(ctx, domain.WebAuthnPurposeRegister, &userID)
+	if err != nil {
+		return nil, err
+	}
+
+	displayName := user.Name
+	if user.DisplayName != nil && *user.DisplayName != "" {
+		displayName = *user.DisplayName
+	}
+	options := &PasskeyCreationOptions{
+		Challenge: challenge,
+		RP:        PasskeyRelyingParty{ID: s.relyingParty.ID, Name: s.relyingParty.Name},
+		User:      PasskeyUser{ID: user.ID[:], Name: user.Email, DisplayName: displayName},
+		Timeout:   passkeyChallengeTTL.Milliseconds(),
+		// Registering the same authenticator twice is refused by the browser.
+		ExcludeCredentials: make([]PasskeyDescriptor, 0, len(existing)),
+		AuthenticatorSelection: PasskeyAuthenticatorSelection{
+			ResidentKey:        "required",
+			RequireResidentKey: true,
+			UserVerification:   "required",
+		},
+		Attestation: "none",
+	}
+	for _, alg := range webauthn.SupportedAlgorithms {
+		options.PubKeyCredParams = append(options.PubKeyCredParams, PasskeyCredentialParameter{Type: passkeyCredentialType, Alg: alg})
+	}
+	for _, passkey := range existing {
+		options.ExcludeCredentials = append(options.ExcludeCredentials, PasskeyDescriptor{Type: passkeyCredentialType, ID: passkey.CredentialID})
+	}
+	return options, nil
+}
+
+// FinishPasskeyRegistration verifies the browser's new credential and adds
+// it to the user's passkeys.
+func (s *AuthService) FinishPasskeyRegistration(ctx context.Context, userID uuid.UUID, req PasskeyRegistrationRequest) (*domain.Passkey, error) {
+	response := req.Credential.Response
+	challenge, err := s.consumePasskeyChallenge(ctx, response.ClientDataJSON, domain.WebAuthnPurposeRegister)
+	if err != nil {
+		return nil, err
+	}
+	if challenge.UserID == nil || *challenge.UserID != userID {
+		return nil, domain.ErrInvalidPasskeyChallenge
+	}
+
+	credential, err := s.relyingParty.VerifyRegistration(response.ClientDataJSON, response.AttestationObject, challenge.Challenge)
+	if err != nil {
+		log.Printf("AuthService.FinishPasskeyRegistration rejected credential. user_id=%s err=%v", userID, err)
+		return nil, domain.ErrInvalidPasskey
+	}
+
+	passkey := &domain.Passkey{
+		UserID:       userID,
+		CredentialID: credential.ID,
+		PublicKey:    credential.PublicKey,
+		SignCount:    int64(credential.SignCount),
+		Name:         passkeyName(ctx, req.Name),
+	}
+	if err := s.passkeyRepo.Create(ctx, passkey); err != nil {
+		return nil, err
+	}
+	return passkey, nil
+}
+
+// BeginPasskeyLogin starts signing in with a passkey.
+func (s *AuthService) BeginPasskeyLogin(ctx context.Context) (*PasskeyRequestOptions, error) {
+	challenge, err := s.issuePasskeyChallenge(ctx, domain.WebAuthnPurposeLogin, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &PasskeyRequestOptions{
+		Challenge:        challenge,
+		RPID:             s.relyingParty.ID,
+		Timeout:          passkeyChallengeTTL.Milliseconds(),
+		UserVerification: "required",
+		AllowCredentials: []PasskeyDescriptor{},
+	}, nil
+}
+
+// FinishPasskeyLogin verifies a passkey assertion and starts a session.
+// Passkeys are verified with the device's PIN or biometric as well, so the
+// session counts as signed in with two factors.
+func (s *AuthService) FinishPasskeyLogin(ctx context.Context, req PasskeyLoginRequest) (*TokenPair, error) {
+	response := req.Credential.Response
+	challenge, err := s.consumePasskeyChallenge(ctx, response.ClientDataJSON, domain.WebAuthnPurposeLogin)
+	if err != nil {
+		return nil, err
+	}
+	passkey, err := s.passkeyRepo.GetByCredentialID(ctx, req.Credential.RawID)
+	if err != nil {
+		return nil, err
+	}
+	if passkey == nil {
+		return nil, domain.ErrInvalidPasskey
+	}
+	if len(response.UserHandle) > 0 && !bytes.Equal(response.UserHandle, passkey.UserID[:]) {
+		return nil, domain.ErrInvalidPasskey
+	}
+
+	signCount, err := s.relyingParty.VerifyAssertion(response.ClientDataJSON, response.AuthenticatorData, response.Signature, challenge.Challenge, passkey.PublicKey)
+	if err != nil {
+		log.Printf("AuthService.FinishPasskeyLogin rejected assertion. passkey_id=%s err=%v", passkey.ID, err)
+		return nil, domain.ErrInvalidPasskey
+	}
+	// Authenticators that keep a counter increase it on every use; synced
+	// passkeys report 0 throughout.
+	if (signCount != 0 || passkey.SignCount != 0) && int64(signCount) <= passkey.SignCount {
+		log.Printf("AuthService.FinishPasskeyLogin signature counter did not increase. passkey_id=%s stored=%d received=%d", passkey.ID, passkey.SignCount, signCount)
+		return nil, domain.ErrPasskeyCloned
+	}
+
+	user, err := s.userRepo.GetByID(ctx, passkey.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Status == domain.UserStatusSuspended {
+		return nil, ErrAccountSuspended
+	}
+	if err := s.passkeyRepo.RecordUse(ctx, passkey.ID, int64(signCount)); err != nil {
+		return nil, err
+	}
+	return s.generateSession(ctx, user, true)
+}
+
+func (s *AuthService) ListPasskeys(ctx context.Context, userID uuid.UUID) ([]domain.Passkey, error) {
+	return s.passkeyRepo.ListByUserID(ctx, userID)
+}
+
+func (s *AuthService) DeletePasskey(ctx context.Context, userID, passkeyID uuid.UUID) error {
+	deleted, err := s.passkeyRepo.Delete(ctx, userID, passkeyID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return domain.ErrPasskeyNotFound
+	}
+	return nil
+}
+
+func (s *AuthService) issuePasskeyChallenge(ctx context.Context, purpose domain.WebAuthnPurpose, userID *uuid.UUID) (string, error) {
+	challenge, err := webauthn.NewChallenge()
+	if err != nil {
+		return "", err
+	}
+	err = s.passkeyRepo.CreateChallenge(ctx, &domain.WebAuthnChallenge{
+		Challenge: challenge,
+		UserID:    userID,
+		Purpose:   purpose,
+		ExpiresAt: time.Now().Add(passkeyChallengeTTL),
+	})
+	if err != nil {
+		return "", err
+	}
+	return challenge, nil
+}
+
+// consumePasskeyChallenge uses up the challenge the client data answers.
+func (s *AuthService) consumePasskeyChallenge(ctx context.Context, clientDataJSON []byte, purpose domain.WebAuthnPurpose) (*domain.WebAuthnChallenge, error) {
+	value, err := webauthn.Challenge(clientDataJSON)
+	if err != nil {
+		return nil, domain.ErrInvalidPasskey
+	}
+	challenge, err := s.passkeyRepo.ConsumeChallenge(ctx, value, purpose)
+	if err != nil {
+		return nil, err
+	}
+	if challenge == nil {
+		return nil, domain.ErrInvalidPasskeyChallenge
+	}
+	return challenge, nil
+}
+
+func passkeyName(ctx context.Context, name string) string {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = domain.ClientInfoFromContext(ctx).Device()
+	}
+	if name == "" {
+		return defaultPasskeyName
+	}
+	if runes := []rune(name); len(runes) > maxPasskeyNameLength {
+		name = string(runes[:maxPasskeyNameLength])
+	}
+	return name
+}
@@ -0,0 +1,325 @@
+package application
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/saransh1220/blueprint-audio/internal/modules/auth/domain"
+	"github.com/saransh1220/blueprint-audio/internal/modules/auth/infrastructure/jwt"
+	"github.com/saransh1220/blueprint-audio/internal/modules/auth/infrastructure/webauthn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type mockPasskeyRepository struct{ mock.Mock }
+
+func (m *mockPasskeyRepository) CreateChallenge(ctx context.Context, challenge *domain.WebAuthnChallenge) error {
+	return m.Called(ctx, challenge).Error(0)
+}
+func (m *mockPasskeyRepository) ConsumeChallenge(ctx context.Context, challenge string, purpose domain.WebAuthnPurpose) (*domain.WebAuthnChallenge, error) {
+	args := m.Called(ctx, challenge, purpose)
+	consumed, _ := args.Get(0).(*domain.WebAuthnChallenge)
+	return consumed, args.Error(1)
+}
+func (m *mockPasskeyRepository) Create(ctx context.Context, passkey *domain.Passkey) error {
+	return m.Called(ctx, passkey).Error(0)
+}
+func (m *mockPasskeyRepository) GetByCredentialID(ctx context.Context, credentialID []byte) (*domain.Passkey, error) {
+	args := m.Called(ctx, credentialID)
+	passkey, _ := args.Get(0).(*domain.Passkey)
+	return passkey, args.Error(1)
+}
+func (m *mockPasskeyRepository) ListByUserID(ctx context.Context, userID uuid.UUID) ([]domain.Passkey, error) {
+	args := m.Called(ctx, userID)
+	passkeys, _ := args.Get(0).([]domain.Passkey)
+	return passkeys, args.Error(1)
+}
+func (m *mockPasskeyRepository) RecordUse(ctx context.Context, id uuid.UUID, signCount int64) error {
+	return m.Called(ctx, id, signCount).Error(0)
+}
+func (m *mockPasskeyRepository) Delete(ctx context.Context, userID, id uuid.UUID) (bool, error) {
+	args := m.Called(ctx, userID, id)
+	return args.Bool(0), args.Error(1)
+}
+
+func withPasskeys(t *testing.T, svc *AuthService) (*mockPasskeyRepository, webauthn.RelyingParty) {
+	t.Helper()
+	repo := new(mockPasskeyRepository)
+	t.Cleanup(func() { repo.AssertExpectations(t) })
+	rp, err := webauthn.NewRelyingParty("Blueprint", "http://localhost:4200")
+	require.NoError(t, err)
+	svc.SetPasskeys(repo, rp)
+	return repo, rp
+}
+
+// fakePasskey is an ES256 authenticator producing the same bytes a browser
+// would send.
+type fakePasskey struct {
+	key          *ecdsa.PrivateKey
+	credentialID []byte
+	signCount    uint32
+}
+
+func newFakePasskey(t *testing.T) *fakePasskey {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	return &fakePasskey{key: key, credentialID: []byte("credential-1")}
+}
+
+func (p *fakePasskey) clientData(t *testing.T, ceremony, challenge string) []byte {
+	t.Helper()
+	data, err := json.Marshal(map[string]string{"type": ceremony, "challenge": challenge, "origin": "http://localhost:4200"})
+	require.NoError(t, err)
+	return data
+}
+
+func (p *fakePasskey) authData(attested bool) []byte {
+	rpIDHash := sha256.Sum256([]byte("localhost"))
+	flags := byte(0x05) // user present and verified
+	if attested {
+		flags |= 0x40
+	}
+	data := binary.BigEndian.AppendUint32(append(rpIDHash[:], flags), p.signCount)
+	if !attested {
+		return data
+	}
+	data = append(data, make([]byte, 16)...)
+	data = binary.BigEndian.AppendUint16(data, uint16(len(p.credentialID)))
+	data = append(data, p.credentialID...)
+	// COSE key {1: 2, 3: -7, -1: 1, -2: x, -3: y}
+	x, y := make([]byte, 32), make([]byte, 32)
+	p.key.X.FillBytes(x)
+	p.key.Y.FillBytes(y)
+	data = append(data, 0xa5, 0x01, 0x02, 0x03, 0x26, 0x20, 0x01, 0x21, 0x58, 0x20)
+	data = append(append(data, x...), 0x22, 0x58, 0x20)
+	return append(data, y...)
+}
+
+func (p *fakePasskey) register(t *testing.T, challenge string) PasskeyCredential {
+	t.Helper()
+	authData := p.authData(true)
+	// {"fmt": "none", "attStmt": {}, "authData": authData}
+	attestation := []byte{0xa3, 0x63, 'f', 'm', 't', 0x64, 'n', 'o', 'n', 'e', 0x67, 'a', 't', 't', 'S', 't', 'm', 't', 0xa0, 0x68, 'a', 'u', 't', 'h', 'D', 'a', 't', 'a', 0x59}
+	attestation = binary.BigEndian.AppendUint16(attestation, uint16(len(authData)))
+	return PasskeyCredential{
+		RawID: p.credentialID,
+		Response: PasskeyCredentialResponse{
+			ClientDataJSON:    p.clientData(t, webauthn.TypeCreate, challenge),
+			AttestationObject: append(attestation, authData...),
+		},
+	}
+}
+
+func (p *fakePasskey) assert(t *testing.T, challenge string, userHandle []byte) PasskeyCredential {
+	t.Helper()
+	p.signCount++
+	clientData := p.clientData(t, webauthn.TypeGet, challenge)
+	authData := p.authData(false)
+	clientDataHash := sha256.Sum256(clientData)
+	digest := sha256.Sum256(append(append([]byte{}, authData...), clientDataHash[:]...))
+	signature, err := ecdsa.SignASN1(rand.Reader, p.key, digest[:])
+	require.NoError(t, err)
+	return PasskeyCredential{
+		RawID: p.credentialID,
+		Response: PasskeyCredentialResponse{
+			ClientDataJSON:    clientData,
+			AuthenticatorData: authData,
+			Signature:         signature,
+			UserHandle:        userHandle,
+		},
+	}
+}
+
+func TestPasskeyRegistration(t *testing.T) {
+	ctx := context.Background()
+	svc, users, _, _, _ := newAuthServiceHarness(t)
+	passkeys, _ := withPasskeys(t, svc)
+	authenticator := newFakePasskey(t)
+	user := &domain.User{ID: uuid.New(), Email: "a@a.com", Name: "Ada"}
+	existing := domain.Passkey{ID: uuid.New(), UserID: user.ID, CredentialID: []byte("old")}
+
+	var issued *domain.WebAuthnChallenge
+	users.On("GetByID", ctx, user.ID).Return(user, nil).Once()
+	passkeys.On("ListByUserID", ctx, user.ID).Return([]domain.Passkey{existing}, nil).Once()
+	passkeys.On("CreateChallenge", ctx, mock.AnythingOfType("*domain.WebAuthnChallenge")).
+		Run(func(args mock.Arguments) { issued = args.Get(1).(*domain.WebAuthnChallenge) }).Return(nil).Once()
+
+	options, err := svc.BeginPasskeyRegistration(ctx, user.ID)
+	require.NoError(t, err)
+	require.NotNil(t, issued)
+	assert.Equal(t, issued.Challenge, options.Challenge)
+	assert.Equal(t, domain.WebAuthnPurposeRegister, issued.Purpose)
+	assert.Equal(t, user.ID, *issued.UserID)
+	assert.Equal(t, "localhost", options.RP.ID)
+	assert.Equal(t, webauthn.Base64URL(user.ID[:]), options.User.ID)
+	assert.Equal(t, "Ada", options.User.DisplayName)
+	assert.Equal(t, []PasskeyDescriptor{{Type: "public-key", ID: []byte("old")}}, options.ExcludeCredentials)
+	assert.Equal(t, "required", options.AuthenticatorSelection.UserVerification)
+
+	t.Run("rejects another user's challenge", func(t *testing.T) {
+		otherUser := uuid.New()
+		passkeys.On("ConsumeChallenge", ctx, issued.Challenge, domain.WebAuthnPurposeRegister).
+			Return(&domain.WebAuthnChallenge{Challenge: issued.Challenge, UserID: &otherUser}, nil).Once()
+
+		_, err := svc.FinishPasskeyRegistration(ctx, user.ID, PasskeyRegistrationRequest{Credential: authenticator.register(t, issued.Challenge)})
+		assert.ErrorIs(t, err, domain.ErrInvalidPasskeyChallenge)
+	})
+
+	t.Run("rejects an expired challenge", func(t *testing.T) {
+		passkeys.On("ConsumeChallenge", ctx, issued.Challenge, domain.WebAuthnPurposeRegister).Return(nil, nil).Once()
+
+		_, err := svc.FinishPasskeyRegistration(ctx, user.ID, PasskeyRegistrationRequest{Credential: authenticator.register(t, issued.Challenge)})
+		assert.ErrorIs(t, err, domain.ErrInvalidPasskeyChallenge)
+	})
+
+	t.Run("rejects a tampered credential", func(t *testing.T) {
+		passkeys.On("ConsumeChallenge", ctx, issued.Challenge, domain.WebAuthnPurposeRegister).Return(issued, nil).Once()
+		credential := authenticator.register(t, issued.Challenge)
+		credential.Response.AttestationObject = credential.Response.AttestationObject[:40]
+
+		_, err := svc.FinishPasskeyRegistration(ctx, user.ID, PasskeyRegistrationRequest{Credential: credential})
+		assert.ErrorIs(t, err, domain.ErrInvalidPasskey)
+	})
+
+	t.Run("saves the passkey", func(t *testing.T) {
+		ctx := domain.WithClientInfo(ctx, domain.ClientInfo{UserAgent: "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0 Safari/537.36"})
+		passkeys.On("ConsumeChallenge", ctx, issued.Challenge, domain.WebAuthnPurposeRegister).Return(issued, nil).Once()
+		passkeys.On("Create", ctx, mock.MatchedBy(func(p *domain.Passkey) bool {
+			return p.UserID == user.ID && string(p.CredentialID) == "credential-1" && len(p.PublicKey) > 0
+		})).Return(nil).Once()
+
+		passkey, err := svc.FinishPasskeyRegistration(ctx, user.ID, PasskeyRegistrationRequest{Credential: authenticator.register(t, issued.Challenge)})
+		require.NoError(t, err)
+		assert.Equal(t, "Chrome on macOS", passkey.Name)
+	})
+}
+
+func TestPasskeyLogin(t *testing.T) {
+	ctx := context.Background()
+	svc, users, sessions, _, _ := newAuthServiceHarness(t)
+	passkeys, _ := withPasskeys(t, svc)
+	authenticator := newFakePasskey(t)
+	user := &domain.User{ID: uuid.New(), Email: "a@a.com", Role: domain.RoleProducer, SystemRole: domain.SystemRoleUser}
+
+	// The stored public key comes from a real registration.
+	challenge := &domain.WebAuthnChallenge{Challenge: "register-challenge", UserID: &user.ID, Purpose: domain.WebAuthnPurposeRegister}
+	var stored *domain.Passkey
+	passkeys.On("ConsumeChallenge", ctx, challenge.Challenge, domain.WebAuthnPurposeRegister).Return(challenge, nil).Once()
+	passkeys.On("Create", ctx, mock.AnythingOfType("*domain.Passkey")).
+		Run(func(args mock.Arguments) { stored = args.Get(1).(*domain.Passkey) }).Return(nil).Once()
+	_, err := svc.FinishPasskeyRegistration(ctx, user.ID, PasskeyRegistrationRequest{Name: "Phone", Credential: authenticator.register(t, challenge.Challenge)})
+	require.NoError(t, err)
+	stored.ID = uuid.New()
+
+	passkeys.On("CreateChallenge", ctx, mock.MatchedBy(func(c *domain.WebAuthnChallenge) bool {
+		return c.Purpose == domain.WebAuthnPurposeLogin && c.UserID == nil
+	})).Return(nil).Once()
+	options, err := svc.BeginPasskeyLogin(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "localhost", options.RPID)
+	assert.Empty(t, options.AllowCredentials)
+	login := &domain.WebAuthnChallenge{Challenge: options.Challenge, Purpose: domain.WebAuthnPurposeLogin}
+
+	t.Run("rejects an unknown passkey", func(t *testing.T) {
+		passkeys.On("ConsumeChallenge", ctx, login.Challenge, domain.WebAuthnPurposeLogin).Return(login, nil).Once()
+		passkeys.On("GetByCredentialID", ctx, []byte("credential-1")).Return(nil, nil).Once()
+
+		_, err := svc.FinishPasskeyLogin(ctx, PasskeyLoginRequest{Credential: authenticator.assert(t, login.Challenge, nil)})
+		assert.ErrorIs(t, err, domain.ErrInvalidPasskey)
+	})
+
+	t.Run("rejects another user's handle", func(t *testing.T) {
+		other := uuid.New()
+		passkeys.On("ConsumeChallenge", ctx, login.Challenge, domain.WebAuthnPurposeLogin).Return(login, nil).Once()
+		passkeys.On("GetByCredentialID", ctx, []byte("credential-1")).Return(stored, nil).Once()
+
+		_, err := svc.FinishPasskeyLogin(ctx, PasskeyLoginRequest{Credential: authenticator.assert(t, login.Challenge, other[:])})
+		assert.ErrorIs(t, err, domain.ErrInvalidPasskey)
+	})
+
+	t.Run("rejects a bad signature", func(t *testing.T) {
+		passkeys.On("ConsumeChallenge", ctx, login.Challenge, domain.WebAuthnPurposeLogin).Return(login, nil).Once()
+		passkeys.On("GetByCredentialID", ctx, []byte("credential-1")).Return(stored, nil).Once()
+		credential := authenticator.assert(t, login.Challenge, user.ID[:])
+		credential.Response.Signature[len(credential.Response.Signature)-1] ^= 0xff
+
+		_, err := svc.FinishPasskeyLogin(ctx, PasskeyLoginRequest{Credential: credential})
+		assert.ErrorIs(t, err, domain.ErrInvalidPasskey)
+	})
+
+	t.Run("rejects a counter that went backwards", func(t *testing.T) {
+		passkeys.On("ConsumeChallenge", ctx, login.Challenge, domain.WebAuthnPurposeLogin).Return(login, nil).Once()
+		cloned := *stored
+		cloned.SignCount = 100
+		passkeys.On("GetByCredentialID", ctx, []byte("credential-1")).Return(&cloned, nil).Once()
+
+		_, err := svc.FinishPasskeyLogin(ctx, PasskeyLoginRequest{Credential: authenticator.assert(t, login.Challenge, user.ID[:])})
+		assert.ErrorIs(t, err, domain.ErrPasskeyCloned)
+	})
+
+	t.Run("rejects a suspended user", func(t *testing.T) {
+		suspended := *user
+		suspended.Status = domain.UserStatusSuspended
+		passkeys.On("ConsumeChallenge", ctx, login.Challenge, domain.WebAuthnPurposeLogin).Return(login, nil).Once()
+		passkeys.On("GetByCredentialID", ctx, []byte("credential-1")).Return(stored, nil).Once()
+		users.On("GetByID", ctx, user.ID).Return(&suspended, nil).Once()
+
+		_, err := svc.FinishPasskeyLogin(ctx, PasskeyLoginRequest{Credential: authenticator.assert(t, login.Challenge, user.ID[:])})
+		assert.ErrorIs(t, err, ErrAccountSuspended)
+	})
+
+	t.Run("starts a two-factor session", func(t *testing.T) {
+		passkeys.On("ConsumeChallenge", ctx, login.Challenge, domain.WebAuthnPurposeLogin).Return(login, nil).Once()
+		passkeys.On("GetByCredentialID", ctx, []byte("credential-1")).Return(stored, nil).Once()
+		users.On("GetByID", ctx, user.ID).Return(user, nil).Once()
+		passkeys.On("RecordUse", ctx, stored.ID, int64(authenticator.signCount+1)).Return(nil).Once()
+		sessions.On("Create", ctx, mock.MatchedBy(func(s *domain.UserSession) bool {
+			return s.UserID == user.ID && s.MFAVerified
+		})).Return(nil).Once()
+
+		tokens, err := svc.FinishPasskeyLogin(ctx, PasskeyLoginRequest{Credential: authenticator.assert(t, login.Challenge, user.ID[:])})
+		require.NoError(t, err)
+		assert.NotEmpty(t, tokens.RefreshToken)
+		claims, err := jwt.ValidateToken(tokens.AccessToken, "secret")
+		require.NoError(t, err)
+		assert.True(t, claims.MFA)
+	})
+}
+
+func TestPasskeyManagement(t *testing.T) {
+	ctx := context.Background()
+	svc, _, _, _, _ := newAuthServiceHarness(t)
+	passkeys, _ := withPasskeys(t, svc)
+	userID, passkeyID := uuid.New(), uuid.New()
+
+	passkeys.On("ListByUserID", ctx, userID).Return([]domain.Passkey{{ID: passkeyID}}, nil).Once()
+	list, err := svc.ListPasskeys(ctx, userID)
+	require.NoError(t, err)
+	assert.Len(t, list, 1)
+
+	passkeys.On("Delete", ctx, userID, passkeyID).Return(true, nil).Once()
+	require.NoError(t, svc.DeletePasskey(ctx, userID, passkeyID))
+
+	passkeys.On("Delete", ctx, userID, passkeyID).Return(false, nil).Once()
+	assert.ErrorIs(t, svc.DeletePasskey(ctx, userID, passkeyID), domain.ErrPasskeyNotFound)
+}
+
+func TestPasskeyName(t *testing.T) {
+	ctx := context.Background()
+	assert.Equal(t, "Passkey", passkeyName(ctx, "  "))
+	assert.Equal(t, "YubiKey", passkeyName(ctx, " YubiKey "))
+	long := make([]rune, 150)
+	for i := range long {
+		long[i] = 'é'
+	}
+	assert.Len(t, []rune(passkeyName(ctx, string(long))), maxPasskeyNameLength)
+}
@@ -0,0 +1,158 @@
+package application
+
+import (
+	"context"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/saransh1220/blueprint-audio/internal/modules/auth/domain"
+	sharedemail "github.com/saransh1220/blueprint-audio/internal/shared/infrastructure/email"
+)
+
+// SetSessionCache makes SessionActive remember a session's state for ttl
+// instead of querying the database on every request. Revocations through
+// the service are written to the cache immediately.
+func (s *AuthService) SetSessionCache(cache domain.SessionCache, ttl time.Duration) {
+	s.sessionCache = cache
+	s.sessionCacheTTL = ttl
+}
+
+// SessionActive reports whether access tokens issued for the session are
+// still honoured: the session is not revoked or expired and its user is not
+// suspended.
+func (s *AuthService) SessionActive(ctx context.Context, sessionID uuid.UUID) (bool, error) {
+	if s.sessionCache != nil {
+		active, found, err := s.sessionCache.Get(ctx, sessionID)
+		if err != nil {
+			log.Printf("AuthService.SessionActive cache read failed. session_id=%s err=%v", sessionID, err)
+		} else if found {
+			return active, nil
+		}
+	}
+
+	active, err := s.sessionRepo.IsActive(ctx, sessionID)
+	if err != nil {
+		return false, err
+	}
+	if s.sessionCache != nil {
+		if err := s.sessionCache.Set(ctx, sessionID, active, s.sessionCacheTTL); err != nil {
+			log.Printf("AuthService.SessionActive cache write failed. session_id=%s err=%v", sessionID, err)
+		}
+	}
+	return active, nil
+}
+
+// RevokeUserSessions signs the user out everywhere. Their refresh tokens
+// stop working and so do the access tokens issued with them.
+func (s *AuthService) RevokeUserSessions(ctx context.Context, userID uuid.UUID) error {
+	sessionIDs, err := s.sessionRepo.RevokeAllForUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	s.denySessions(ctx, sessionIDs...)
+	return nil
+}
+
+// denySessions caches the sessions as revoked for as long as access tokens
+// issued for them could still be valid.
+func (s *AuthService) denySessions(ctx context.Context, sessionIDs ...uuid.UUID) {
+	if s.sessionCache == nil {
+		return
+	}
+	for _, sessionID := range sessionIDs {
+		if err := s.sessionCache.Set(ctx, sessionID, false, s.jwtExpiry); err != nil {
+			log.Printf("AuthService.denySessions cache write failed. session_id=%s err=%v", sessionID, err)
+		}
+	}
+}
+
+// ListSessions returns the user's active sessions, most recently used first.
+func (s *AuthService) ListSessions(ctx context.Context, userID uuid.UUID) ([]domain.UserSession, error) {
+	return s.sessionRepo.ListActiveByUserID(ctx, userID)
+}
+
+// RevokeSession signs one of the user's sessions out.
+func (s *AuthService) RevokeSession(ctx context.Context, userID, sessionID uuid.UUID) error {
+	revoked, err := s.sessionRepo.RevokeByID(ctx, userID, sessionID)
+	if err != nil {
+		return err
+	}
+	if !revoked {
+		return domain.ErrSessionNotFound
+	}
+	s.denySessions(ctx, sessionID)
+	return nil
+}
+
+// RevokeOtherSessions signs the user out everywhere except the current
+// session and returns how many sessions were revoked.
+func (s *AuthService) RevokeOtherSessions(ctx context.Context, userID, currentSessionID uuid.UUID) (int, error) {
+	sessionIDs, err := s.sessionRepo.RevokeOthers(ctx, userID, currentSessionID)
+	if err != nil {
+		return 0, err
+	}
+	s.denySessions(ctx, sessionIDs...)
+	return len(sessionIDs), nil
+}
+
+// isNewDevice reports whether the user has signed in before, but never from
+// device. The first sign-in of an account is not alerted on.
+func (s *AuthService) isNewDevice(ctx context.Context, userID uuid.UUID, device string) bool {
+	if device == "" {
+		return false
+	}
+	known, err := s.sessionRepo.KnownDevices(ctx, userID)
+	if err != nil {
+		log.Printf("AuthService.isNewDevice failed to load known devices. user_id=%s err=%v", userID, err)
+		return false
+	}
+	return len(known) > 0 && !slices.Contains(known, device)
+}
+
+// revokeReusedSession handles a refresh token presented after it was
+// rotated out: whoever holds it copied it, so the session is signed out on
+// every device and the user is told.
+func (s *AuthService) revokeReusedSession(ctx context.Context, superseded *domain.SupersededRefreshToken) error {
+	if time.Since(superseded.SupersededAt) < refreshReuseGrace {
+		return ErrRefreshTokenSuperseded
+	}
+
+	revoked, err := s.sessionRepo.RevokeByID(ctx, superseded.UserID, superseded.ID)
+	if err != nil {
+		return err
+	}
+	s.denySessions(ctx, superseded.ID)
+	if !revoked {
+		// Already signed out, so the token was useless to whoever held it.
+		return ErrRefreshTokenReused
+	}
+	log.Printf("AuthService.RefreshSession revoked session after refresh token reuse. user_id=%s session_id=%s", superseded.UserID, superseded.ID)
+
+	user, err := s.userRepo.GetByID(ctx, superseded.UserID)
+	if err != nil {
+		log.Printf("AuthService.RefreshSession failed to load user for reuse alert. user_id=%s err=%v", superseded.UserID, err)
+		return ErrRefreshTokenReused
+	}
+	if err := s.sendSessionAlert(ctx, sharedemail.BuildSessionRevokedEmail, user, &superseded.UserSession, time.Now()); err != nil {
+		log.Printf("AuthService.RefreshSession failed to send reuse alert. user_id=%s err=%v", user.ID, err)
+	}
+	return ErrRefreshTokenReused
+}
+
+func (s *AuthService) sendSessionAlert(ctx context.Context, build func(sharedemail.SessionAlertData, string) sharedemail.Message, user *domain.User, session *domain.UserSession, when time.Time) error {
+	name := user.Name
+	if user.DisplayName != nil && strings.TrimSpace(*user.DisplayName) != "" {
+		name = *user.DisplayName
+	}
+	return s.emailSender.Send(ctx, build(sharedemail.SessionAlertData{
+		RecipientName:  name,
+		RecipientEmail: user.Email,
+		Device:         session.Device,
+		Location:       session.Location,
+		IPAddress:      session.IPAddress,
+		When:           when.UTC().Format("Jan 2, 15:04 UTC"),
+	}, s.appBaseURL))
+}
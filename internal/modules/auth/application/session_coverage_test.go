@@ -3,11 +3,14 @@ package application
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/saransh1220/blueprint-audio/internal/modules/auth/domain"
+	"github.com/saransh1220/blueprint-audio/internal/modules/auth/infrastructure/sessioncache"
+	sharedemail "github.com/saransh1220/blueprint-audio/internal/shared/infrastructure/email"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -74,6 +77,7 @@ func TestRefreshSessionAndLogoutBranches(t *testing.T) {
 	t.Run("unknown token", func(t *testing.T) {
 		service, _, sessions, _, _ := newAuthServiceHarness(t)
 		sessions.On("GetByToken", ctx, "unknown").Return(nil, nil).Once()
+		sessions.On("GetSuperseded", ctx, "unknown").Return(nil, nil).Once()
 		_, err := service.RefreshSession(ctx, "unknown")
 		require.EqualError(t, err, "invalid refresh token")
 	})
@@ -101,16 +105,240 @@ func TestRefreshSessionAndLogoutBranches(t *testing.T) {
 		require.EqualError(t, err, "user read failed")
 	})
 
+	t.Run("suspended user", func(t *testing.T) {
+		service, users, sessions, _, _ := newAuthServiceHarness(t)
+		userID := uuid.New()
+		sessions.On("GetByToken", ctx, "suspended").Return(&domain.UserSession{UserID: userID, ExpiresAt: time.Now().Add(time.Hour)}, nil).Once()
+		users.On("GetByID", ctx, userID).Return(&domain.User{ID: userID, Status: domain.UserStatusSuspended}, nil).Once()
+		_, err := service.RefreshSession(ctx, "suspended")
+		require.ErrorIs(t, err, ErrAccountSuspended)
+	})
+
 	t.Run("success and logout", func(t *testing.T) {
 		service, users, sessions, _, _ := newAuthServiceHarness(t)
+		cache := sessioncache.NewMemoryCache()
+		service.SetSessionCache(cache, time.Minute)
 		userID := uuid.New()
-		sessions.On("GetByToken", ctx, "valid").Return(&domain.UserSession{UserID: userID, ExpiresAt: time.Now().Add(time.Hour)}, nil).Once()
+		session := &domain.UserSession{ID: uuid.New(), UserID: userID, ExpiresAt: time.Now().Add(time.Hour)}
+		sessions.On("GetByToken", ctx, "valid").Return(session, nil).Once()
 		users.On("GetByID", ctx, userID).Return(&domain.User{ID: userID, Role: domain.RoleProducer, SystemRole: domain.SystemRoleUser}, nil).Once()
-		accessToken, err := service.RefreshSession(ctx, "valid")
+		var rotatedTo string
+		sessions.On("Rotate", ctx, session.ID, "valid", mock.AnythingOfType("string")).
+			Run(func(args mock.Arguments) { rotatedTo = args.String(3) }).
+			Return(true, nil).Once()
+		sessions.On("Touch", ctx, session.ID, domain.ClientInfo{}).Return(nil).Once()
+		tokens, err := service.RefreshSession(ctx, "valid")
+		require.NoError(t, err)
+		assert.NotEmpty(t, tokens.AccessToken)
+		assert.Equal(t, rotatedTo, tokens.RefreshToken)
+		assert.NotEqual(t, "valid", tokens.RefreshToken)
+		claims, err := service.ValidateToken(tokens.AccessToken)
+		require.NoError(t, err)
+		assert.Equal(t, session.ID, claims.SessionID)
+
+		sessions.On("GetByToken", ctx, tokens.RefreshToken).Return(session, nil).Once()
+		sessions.On("Revoke", ctx, tokens.RefreshToken).Return(nil).Once()
+		require.NoError(t, service.Logout(ctx, tokens.RefreshToken))
+		active, err := service.SessionActive(ctx, session.ID)
+		require.NoError(t, err)
+		assert.False(t, active)
+	})
+
+	t.Run("lost rotation race", func(t *testing.T) {
+		service, users, sessions, _, _ := newAuthServiceHarness(t)
+		userID := uuid.New()
+		session := &domain.UserSession{ID: uuid.New(), UserID: userID, ExpiresAt: time.Now().Add(time.Hour)}
+		sessions.On("GetByToken", ctx, "racing").Return(session, nil).Once()
+		users.On("GetByID", ctx, userID).Return(&domain.User{ID: userID}, nil).Once()
+		sessions.On("Rotate", ctx, session.ID, "racing", mock.AnythingOfType("string")).Return(false, nil).Once()
+		_, err := service.RefreshSession(ctx, "racing")
+		require.ErrorIs(t, err, ErrRefreshTokenSuperseded)
+	})
+}
+
+func TestRefreshSessionReuseDetection(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	superseded := func(age time.Duration) *domain.SupersededRefreshToken {
+		return &domain.SupersededRefreshToken{
+			UserSession:  domain.UserSession{ID: uuid.New(), UserID: userID, Device: "Chrome on macOS", ExpiresAt: time.Now().Add(time.Hour)},
+			SupersededAt: time.Now().Add(-age),
+		}
+	}
+
+	t.Run("concurrent refresh within the grace period", func(t *testing.T) {
+		service, _, sessions, _, _ := newAuthServiceHarness(t)
+		sessions.On("GetByToken", ctx, "old").Return(nil, nil).Once()
+		sessions.On("GetSuperseded", ctx, "old").Return(superseded(time.Second), nil).Once()
+		_, err := service.RefreshSession(ctx, "old")
+		require.ErrorIs(t, err, ErrRefreshTokenSuperseded)
+	})
+
+	t.Run("reuse revokes the session and alerts the user", func(t *testing.T) {
+		service, users, sessions, _, email := newAuthServiceHarness(t)
+		service.SetSessionCache(sessioncache.NewMemoryCache(), time.Minute)
+		token := superseded(time.Hour)
+		sessions.On("GetByToken", ctx, "stolen").Return(nil, nil).Once()
+		sessions.On("GetSuperseded", ctx, "stolen").Return(token, nil).Once()
+		sessions.On("RevokeByID", ctx, userID, token.ID).Return(true, nil).Once()
+		users.On("GetByID", ctx, userID).Return(&domain.User{ID: userID, Email: "producer@example.com"}, nil).Once()
+		email.On("Send", ctx, mock.MatchedBy(func(msg sharedemail.Message) bool {
+			return msg.To[0] == "producer@example.com" && strings.Contains(msg.Text, "Chrome on macOS")
+		})).Return(nil).Once()
+
+		_, err := service.RefreshSession(ctx, "stolen")
+		require.ErrorIs(t, err, ErrRefreshTokenReused)
+		active, err := service.SessionActive(ctx, token.ID)
+		require.NoError(t, err)
+		assert.False(t, active)
+	})
+
+	t.Run("reuse of an already revoked session is not alerted again", func(t *testing.T) {
+		service, _, sessions, _, _ := newAuthServiceHarness(t)
+		token := superseded(time.Hour)
+		sessions.On("GetByToken", ctx, "stolen").Return(nil, nil).Once()
+		sessions.On("GetSuperseded", ctx, "stolen").Return(token, nil).Once()
+		sessions.On("RevokeByID", ctx, userID, token.ID).Return(false, nil).Once()
+		_, err := service.RefreshSession(ctx, "stolen")
+		require.ErrorIs(t, err, ErrRefreshTokenReused)
+	})
+
+	t.Run("lookup error", func(t *testing.T) {
+		service, _, sessions, _, _ := newAuthServiceHarness(t)
+		sessions.On("GetByToken", ctx, "old").Return(nil, nil).Once()
+		sessions.On("GetSuperseded", ctx, "old").Return(nil, errors.New("read failed")).Once()
+		_, err := service.RefreshSession(ctx, "old")
+		require.EqualError(t, err, "read failed")
+	})
+}
+
+func TestSessionActive(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("cached after the first lookup", func(t *testing.T) {
+		service, _, sessions, _, _ := newAuthServiceHarness(t)
+		service.SetSessionCache(sessioncache.NewMemoryCache(), time.Minute)
+		sessionID := uuid.New()
+		sessions.On("IsActive", ctx, sessionID).Return(true, nil).Once()
+
+		for range 3 {
+			active, err := service.SessionActive(ctx, sessionID)
+			require.NoError(t, err)
+			assert.True(t, active)
+		}
+	})
+
+	t.Run("without a cache", func(t *testing.T) {
+		service, _, sessions, _, _ := newAuthServiceHarness(t)
+		sessionID := uuid.New()
+		sessions.On("IsActive", ctx, sessionID).Return(false, nil).Once()
+		sessions.On("IsActive", ctx, sessionID).Return(false, errors.New("read failed")).Once()
+
+		active, err := service.SessionActive(ctx, sessionID)
+		require.NoError(t, err)
+		assert.False(t, active)
+		_, err = service.SessionActive(ctx, sessionID)
+		require.EqualError(t, err, "read failed")
+	})
+
+	t.Run("revoking a user's sessions takes effect immediately", func(t *testing.T) {
+		service, _, sessions, _, _ := newAuthServiceHarness(t)
+		service.SetSessionCache(sessioncache.NewMemoryCache(), time.Minute)
+		userID, first, second := uuid.New(), uuid.New(), uuid.New()
+		sessions.On("IsActive", ctx, first).Return(true, nil).Once()
+		active, err := service.SessionActive(ctx, first)
+		require.NoError(t, err)
+		require.True(t, active)
+
+		sessions.On("RevokeAllForUser", ctx, userID).Return([]uuid.UUID{first, second}, nil).Once()
+		require.NoError(t, service.RevokeUserSessions(ctx, userID))
+		for _, sessionID := range []uuid.UUID{first, second} {
+			active, err := service.SessionActive(ctx, sessionID)
+			require.NoError(t, err)
+			assert.False(t, active)
+		}
+
+		sessions.On("RevokeAllForUser", ctx, userID).Return(nil, errors.New("write failed")).Once()
+		require.EqualError(t, service.RevokeUserSessions(ctx, userID), "write failed")
+	})
+}
+
+func TestGenerateSessionRecordsClientAndAlertsNewDevices(t *testing.T) {
+	client := domain.ClientInfo{
+		UserAgent: "Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:125.0) Gecko/20100101 Firefox/125.0",
+		IPAddress: "203.0.113.7",
+		Location:  "Pune, IN",
+	}
+	ctx := domain.WithClientInfo(context.Background(), client)
+	user := &domain.User{ID: uuid.New(), Email: "producer@example.com", Name: "Producer", Role: domain.RoleProducer}
+	isClientSession := mock.MatchedBy(func(session *domain.UserSession) bool {
+		return session.Device == "Firefox on Windows" && session.IPAddress == client.IPAddress && session.Location == client.Location
+	})
+
+	for _, tc := range []struct {
+		name   string
+		known  []string
+		alerts bool
+	}{
+		{name: "first sign-in", known: nil},
+		{name: "known device", known: []string{"Firefox on Windows", "Safari on iOS"}},
+		{name: "new device", known: []string{"Safari on iOS"}, alerts: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			service, _, sessions, _, email := newAuthServiceHarness(t)
+			sessions.On("KnownDevices", ctx, user.ID).Return(tc.known, nil).Once()
+			sessions.On("Create", ctx, isClientSession).Return(nil).Once()
+			if tc.alerts {
+				email.On("Send", ctx, mock.MatchedBy(func(msg sharedemail.Message) bool {
+					return msg.Subject == "New sign-in to your Blueprint account" && strings.Contains(msg.Text, "Firefox on Windows")
+				})).Return(errors.New("smtp down")).Once()
+			}
+
+			_, err := service.generateSession(ctx, user, false)
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestSessionManagement(t *testing.T) {
+	ctx := context.Background()
+	userID, current, other := uuid.New(), uuid.New(), uuid.New()
+
+	t.Run("list", func(t *testing.T) {
+		service, _, sessions, _, _ := newAuthServiceHarness(t)
+		expected := []domain.UserSession{{ID: current, UserID: userID, Device: "Chrome on macOS"}}
+		sessions.On("ListActiveByUserID", ctx, userID).Return(expected, nil).Once()
+		got, err := service.ListSessions(ctx, userID)
+		require.NoError(t, err)
+		assert.Equal(t, expected, got)
+	})
+
+	t.Run("revoke one", func(t *testing.T) {
+		service, _, sessions, _, _ := newAuthServiceHarness(t)
+		service.SetSessionCache(sessioncache.NewMemoryCache(), time.Minute)
+		sessions.On("RevokeByID", ctx, userID, other).Return(true, nil).Once()
+		require.NoError(t, service.RevokeSession(ctx, userID, other))
+		active, err := service.SessionActive(ctx, other)
+		require.NoError(t, err)
+		assert.False(t, active)
+
+		sessions.On("RevokeByID", ctx, userID, other).Return(false, nil).Once()
+		require.ErrorIs(t, service.RevokeSession(ctx, userID, other), domain.ErrSessionNotFound)
+	})
+
+	t.Run("revoke others", func(t *testing.T) {
+		service, _, sessions, _, _ := newAuthServiceHarness(t)
+		service.SetSessionCache(sessioncache.NewMemoryCache(), time.Minute)
+		sessions.On("RevokeOthers", ctx, userID, current).Return([]uuid.UUID{other}, nil).Once()
+		count, err := service.RevokeOtherSessions(ctx, userID, current)
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+		active, err := service.SessionActive(ctx, other)
 		require.NoError(t, err)
-		assert.NotEmpty(t, accessToken)
+		assert.False(t, active)
 
-		sessions.On("Revoke", ctx, "valid").Return(nil).Once()
-		require.NoError(t, service.Logout(ctx, "valid"))
+		sessions.On("RevokeOthers", ctx, userID, current).Return(nil, errors.New("write failed")).Once()
+		_, err = service.RevokeOtherSessions(ctx, userID, current)
+		require.EqualError(t, err, "write failed")
 	})
 }
@@ -0,0 +1,80 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrMFANotSetUp         = errors.New("two-factor authentication has not been set up")
+	ErrMFAAlreadyEnabled   = errors.New("two-factor authentication is already enabled")
+	ErrMFANotEnabled       = errors.New("two-factor authentication is not enabled")
+	ErrInvalidMFACode      = errors.New("invalid two-factor code")
+	ErrMFARequiredForRole  = errors.New("two-factor authentication is required for your role")
+	ErrInvalidMFAChallenge = errors.New("invalid or expired two-factor challenge")
+	ErrMFALocked           = errors.New("too many two-factor attempts, try again later")
+)
+
+const (
+	// MFAMaxAttempts is how many second factor attempts a user gets before
+	// the second factor is locked.
+	MFAMaxAttempts = 5
+	// MFALockout is how long the first lockout lasts. Each further lockout
+	// before a successful attempt lasts twice as long, up to MFAMaxLockout.
+	MFALockout    = 15 * time.Minute
+	MFAMaxLockout = 24 * time.Hour
+)
+
+// TOTPEnrollment is a user's authenticator app secret. It only protects
+// sign-in once EnabledAt is set.
+type TOTPEnrollment struct {
+	UserID       uuid.UUID  `db:"user_id"`
+	Secret       string     `db:"secret"`
+	EnabledAt    *time.Time `db:"enabled_at"`
+	LastUsedStep int64      `db:"last_used_step"`
+	CreatedAt    time.Time  `db:"created_at"`
+	UpdatedAt    time.Time  `db:"updated_at"`
+
+	FailedAttempts int        `db:"failed_attempts"`
+	Lockouts       int        `db:"lockouts"`
+	LockedUntil    *time.Time `db:"locked_until"`
+}
+
+func (e *TOTPEnrollment) Enabled() bool {
+	return e != nil && e.EnabledAt != nil
+}
+
+// MFARepository stores second factors and which system roles require them.
+type MFARepository interface {
+	// GetTOTP returns the user's enrollment, or nil if there is none.
+	GetTOTP(ctx context.Context, userID uuid.UUID) (*TOTPEnrollment, error)
+	// SaveTOTPSecret starts a new enrollment, replacing one not yet enabled.
+	SaveTOTPSecret(ctx context.Context, userID uuid.UUID, secret string) error
+	// EnableTOTP turns the enrollment on, records step as used and replaces
+	// the user's recovery codes.
+	EnableTOTP(ctx context.Context, userID uuid.UUID, step int64, recoveryCodeDigests []string) error
+	// DisableTOTP removes the enrollment and the recovery codes.
+	DisableTOTP(ctx context.Context, userID uuid.UUID) error
+	// UseTOTPStep records a code's time step as used and reports false if it
+	// or a later step was used already.
+	UseTOTPStep(ctx context.Context, userID uuid.UUID, step int64) (bool, error)
+	// ClaimMFAAttempt counts a second factor attempt before the code is
+	// checked, so concurrent guesses are all counted, and reports false while
+	// the user is locked out. The MFAMaxAttempts-th attempt since the last
+	// success locks the second factor.
+	ClaimMFAAttempt(ctx context.Context, userID uuid.UUID) (bool, error)
+	// ResetMFAAttempts clears the attempt and lockout counts after a
+	// successful attempt.
+	ResetMFAAttempts(ctx context.Context, userID uuid.UUID) error
+	ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, digests []string) error
+	// UseRecoveryCode marks an unused recovery code as used and reports
+	// whether there was one.
+	UseRecoveryCode(ctx context.Context, userID uuid.UUID, digest string) (bool, error)
+	CountRecoveryCodes(ctx context.Context, userID uuid.UUID) (int, error)
+	// MFARequired reports whether members of the system role must sign in
+	// with a second factor.
+	MFARequired(ctx context.Context, role SystemRole) (bool, error)
+}
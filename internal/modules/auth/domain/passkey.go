@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrPasskeyNotFound         = errors.New("passkey not found")
+	ErrPasskeyAlreadyExists    = errors.New("passkey is already registered")
+	ErrInvalidPasskey          = errors.New("passkey could not be verified")
+	ErrInvalidPasskeyChallenge = errors.New("invalid or expired passkey challenge")
+	// ErrPasskeyCloned means the authenticator's signature counter went
+	// backwards, so the credential may have been copied.
+	ErrPasskeyCloned = errors.New("passkey signature counter did not increase")
+)
+
+type WebAuthnPurpose string
+
+const (
+	WebAuthnPurposeRegister WebAuthnPurpose = "register"
+	WebAuthnPurposeLogin    WebAuthnPurpose = "login"
+)
+
+// Passkey is a WebAuthn credential a user signs in with.
+type Passkey struct {
+	ID           uuid.UUID  `db:"id"`
+	UserID       uuid.UUID  `db:"user_id"`
+	CredentialID []byte     `db:"credential_id"`
+	PublicKey    []byte     `db:"public_key"`
+	SignCount    int64      `db:"sign_count"`
+	Name         string     `db:"name"`
+	CreatedAt    time.Time  `db:"created_at"`
+	LastUsedAt   *time.Time `db:"last_used_at"`
+}
+
+// WebAuthnChallenge is a challenge issued to begin a ceremony.
+type WebAuthnChallenge struct {
+	Challenge string          `db:"challenge"`
+	UserID    *uuid.UUID      `db:"user_id"`
+	Purpose   WebAuthnPurpose `db:"purpose"`
+	ExpiresAt time.Time       `db:"expires_at"`
+	CreatedAt time.Time       `db:"created_at"`
+}
+
+type PasskeyRepository interface {
+	CreateChallenge(ctx context.Context, challenge *WebAuthnChallenge) error
+	// ConsumeChallenge deletes an unexpired challenge issued for purpose and
+	// returns it, or nil if there is none.
+	ConsumeChallenge(ctx context.Context, challenge string, purpose WebAuthnPurpose) (*WebAuthnChallenge, error)
+	Create(ctx context.Context, passkey *Passkey) error
+	// GetByCredentialID returns the passkey, or nil if there is none.
+	GetByCredentialID(ctx context.Context, credentialID []byte) (*Passkey, error)
+	ListByUserID(ctx context.Context, userID uuid.UUID) ([]Passkey, error)
+	RecordUse(ctx context.Context, id uuid.UUID, signCount int64) error
+	// Delete removes one of the user's passkeys and reports whether it existed.
+	Delete(ctx context.Context, userID, id uuid.UUID) (bool, error)
+}
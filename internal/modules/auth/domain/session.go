@@ -2,11 +2,15 @@ package domain
 
 import (
 	"context"
+	"errors"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+var ErrSessionNotFound = errors.New("session not found")
+
 // UserSession represents a user's refresh token session
 type UserSession struct {
 	ID                 uuid.UUID `json:"id" db:"id"`
@@ -14,15 +18,133 @@ type UserSession struct {
 	RefreshToken       string    `json:"-" db:"-"`
 	RefreshTokenDigest string    `json:"-" db:"refresh_token_digest"`
 	IsRevoked          bool      `json:"is_revoked" db:"is_revoked"`
-	ExpiresAt          time.Time `json:"expires_at" db:"expires_at"`
-	CreatedAt          time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt          time.Time `json:"updated_at" db:"updated_at"`
+	// MFAVerified is set when the session was signed in to with a second
+	// factor.
+	MFAVerified bool      `json:"mfa_verified" db:"mfa_verified"`
+	Device      string    `json:"device" db:"device"`
+	UserAgent   string    `json:"user_agent" db:"user_agent"`
+	IPAddress   string    `json:"ip_address" db:"ip_address"`
+	Location    string    `json:"location" db:"location"`
+	LastUsedAt  time.Time `json:"last_used_at" db:"last_used_at"`
+	ExpiresAt   time.Time `json:"expires_at" db:"expires_at"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// SupersededRefreshToken is a refresh token that was replaced by a newer one
+// of the same session.
+type SupersededRefreshToken struct {
+	UserSession
+	SupersededAt time.Time `db:"superseded_at"`
 }
 
 // SessionRepository defines the contract for session data access
 type SessionRepository interface {
 	Create(ctx context.Context, session *UserSession) error
 	GetByToken(ctx context.Context, token string) (*UserSession, error)
+	// Rotate replaces the session's refresh token and records the old one as
+	// superseded. It reports false if the session no longer has oldToken,
+	// because it was rotated concurrently or revoked.
+	Rotate(ctx context.Context, sessionID uuid.UUID, oldToken, newToken string) (bool, error)
+	// GetSuperseded returns the superseded token and its session, or nil if
+	// the token was never superseded.
+	GetSuperseded(ctx context.Context, token string) (*SupersededRefreshToken, error)
 	Revoke(ctx context.Context, token string) error
-	RevokeAllForUser(ctx context.Context, userID uuid.UUID) error
+	// RevokeAllForUser revokes the user's active sessions and returns their IDs.
+	RevokeAllForUser(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error)
+	// IsActive reports whether the session exists, is neither revoked nor
+	// expired, and belongs to an active user.
+	IsActive(ctx context.Context, sessionID uuid.UUID) (bool, error)
+	// Touch records that the session was used from client.
+	Touch(ctx context.Context, sessionID uuid.UUID, client ClientInfo) error
+	// ListActiveByUserID returns the user's unrevoked, unexpired sessions,
+	// most recently used first.
+	ListActiveByUserID(ctx context.Context, userID uuid.UUID) ([]UserSession, error)
+	// RevokeByID revokes one of the user's active sessions and reports
+	// whether there was one to revoke.
+	RevokeByID(ctx context.Context, userID, sessionID uuid.UUID) (bool, error)
+	// RevokeOthers revokes the user's active sessions except keepID and
+	// returns their IDs.
+	RevokeOthers(ctx context.Context, userID, keepID uuid.UUID) ([]uuid.UUID, error)
+	// KnownDevices returns the devices of all the user's sessions, including
+	// revoked and expired ones.
+	KnownDevices(ctx context.Context, userID uuid.UUID) ([]string, error)
+}
+
+// SessionCache remembers whether sessions are active, so access tokens can
+// be checked on every request without a database query.
+type SessionCache interface {
+	// Get returns whether the session is active, and false for found if the
+	// cache does not know.
+	Get(ctx context.Context, sessionID uuid.UUID) (active bool, found bool, err error)
+	Set(ctx context.Context, sessionID uuid.UUID, active bool, ttl time.Duration) error
+}
+
+// ClientInfo describes the client a request came from.
+type ClientInfo struct {
+	UserAgent string
+	IPAddress string
+	// Location is approximate, such as "Mumbai, MH, IN", and empty when
+	// unknown.
+	Location string
+}
+
+type clientInfoKey struct{}
+
+// WithClientInfo returns a context carrying the client of the request, so
+// sessions started or refreshed with it record where they are used from.
+func WithClientInfo(ctx context.Context, client ClientInfo) context.Context {
+	return context.WithValue(ctx, clientInfoKey{}, client)
+}
+
+// ClientInfoFromContext returns the client stored by WithClientInfo.
+func ClientInfoFromContext(ctx context.Context) ClientInfo {
+	client, _ := ctx.Value(clientInfoKey{}).(ClientInfo)
+	return client
+}
+
+// Device names the browser and operating system of a user agent, such as
+// "Chrome on macOS". Version numbers are left out, so a browser update is
+// not a new device.
+func (c ClientInfo) Device() string {
+	ua := c.UserAgent
+	if strings.TrimSpace(ua) == "" {
+		return ""
+	}
+
+	browser := "Unknown browser"
+	for _, candidate := range []struct{ token, name string }{
+		// Order matters: Edge and Opera also claim to be Chrome, and Chrome
+		// claims to be Safari.
+		{"Edg/", "Edge"},
+		{"OPR/", "Opera"},
+		{"Firefox/", "Firefox"},
+		{"FxiOS/", "Firefox"},
+		{"CriOS/", "Chrome"},
+		{"Chrome/", "Chrome"},
+		{"Safari/", "Safari"},
+	} {
+		if strings.Contains(ua, candidate.token) {
+			browser = candidate.name
+			break
+		}
+	}
+
+	os := "unknown OS"
+	for _, candidate := range []struct{ token, name string }{
+		{"iPhone", "iOS"},
+		{"iPad", "iPadOS"},
+		{"Android", "Android"},
+		{"Windows", "Windows"},
+		{"Mac OS X", "macOS"},
+		{"CrOS", "ChromeOS"},
+		{"Linux", "Linux"},
+	} {
+		if strings.Contains(ua, candidate.token) {
+			os = candidate.name
+			break
+		}
+	}
+
+	return browser + " on " + os
 }
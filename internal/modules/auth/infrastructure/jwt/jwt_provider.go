@@ -12,26 +12,40 @@ import (
 
 type CustomClaims struct {
 	UserID     uuid.UUID `json:"user_id"`
+	SessionID  uuid.UUID `json:"sid"`
 	Role       string    `json:"role"`
 	SystemRole string    `json:"system_role"`
+	// MFA is set when the session was signed in to with a second factor.
+	MFA bool `json:"mfa,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// MFAChallengeClaims identify a user who passed the first sign-in factor
+// and still has to pass the second.
+type MFAChallengeClaims struct {
+	UserID uuid.UUID `json:"user_id"`
 	jwt.RegisteredClaims
 }
 
 // GenerateToken creates a JWT token string signed with the provided secret.
-// The token includes custom claims for the user's UUID and role, and standard
-// registered claims for expiration, issued at, and not before times.
+// The token includes custom claims for the user's UUID, the session it was
+// issued for, roles and whether it used a second factor, and standard
+// registered claims for a unique token ID, expiration, issued at, and not
+// before times. An empty systemRole means the default user role.
 // The duration parameter specifies how long the token is valid.
 // Returns the signed JWT token string or an error if signing fails.
-func GenerateToken(secret string, duration time.Duration, userID uuid.UUID, role string, systemRole ...string) (string, error) {
-	roleClaim := string(domain.SystemRoleUser)
-	if len(systemRole) > 0 && systemRole[0] != "" {
-		roleClaim = systemRole[0]
+func GenerateToken(secret string, duration time.Duration, userID, sessionID uuid.UUID, role, systemRole string, mfa bool) (string, error) {
+	if systemRole == "" {
+		systemRole = string(domain.SystemRoleUser)
 	}
 	claims := CustomClaims{
 		UserID:     userID,
+		SessionID:  sessionID,
 		Role:       role,
-		SystemRole: roleClaim,
+		SystemRole: systemRole,
+		MFA:        mfa,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(duration)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
@@ -64,6 +78,46 @@ func ValidateToken(tokenStr string, secret string) (*CustomClaims, error) {
 	return nil, jwt.ErrTokenMalformed
 }
 
+// mfaChallengeKey derives the key challenge tokens are signed with, so they
+// can never pass as access tokens.
+func mfaChallengeKey(secret string) []byte {
+	return []byte("mfa-challenge:" + secret)
+}
+
+// GenerateMFAChallenge returns a short-lived token that lets the user
+// complete sign-in with a second factor.
+func GenerateMFAChallenge(secret string, duration time.Duration, userID uuid.UUID) (string, error) {
+	claims := MFAChallengeClaims{
+		UserID: userID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(duration)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			NotBefore: jwt.NewNumericDate(time.Now()),
+		},
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(mfaChallengeKey(secret))
+}
+
+// ValidateMFAChallenge returns the user a challenge token was issued to.
+func ValidateMFAChallenge(tokenStr string, secret string) (uuid.UUID, error) {
+	token, err := jwt.ParseWithClaims(tokenStr, &MFAChallengeClaims{}, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, jwt.ErrTokenSignatureInvalid
+		}
+		return mfaChallengeKey(secret), nil
+	})
+	if err != nil {
+		return uuid.Nil, err
+	}
+	claims, ok := token.Claims.(*MFAChallengeClaims)
+	if !ok || !token.Valid || claims.UserID == uuid.Nil {
+		return uuid.Nil, jwt.ErrTokenMalformed
+	}
+	return claims.UserID, nil
+}
+
 // GenerateRefreshToken creates a secure random string to be used as a refresh token
 func GenerateRefreshToken() (string, error) {
 	b := make([]byte, 32)
@@ -11,13 +11,24 @@ import (
 func TestGenerateAndValidateToken(t *testing.T) {
 	secret := "secret"
 	uid := uuid.New()
-	tok, err := GenerateToken(secret, time.Hour, uid, "producer")
+	sid := uuid.New()
+	tok, err := GenerateToken(secret, time.Hour, uid, sid, "producer", "", false)
 	require.NoError(t, err)
 
 	claims, err := ValidateToken(tok, secret)
 	require.NoError(t, err)
 	require.Equal(t, uid, claims.UserID)
+	require.Equal(t, sid, claims.SessionID)
 	require.Equal(t, "producer", claims.Role)
+	require.Equal(t, "user", claims.SystemRole)
+	require.False(t, claims.MFA)
+	require.NotEmpty(t, claims.ID)
+
+	other, err := GenerateToken(secret, time.Hour, uid, sid, "producer", "", false)
+	require.NoError(t, err)
+	otherClaims, err := ValidateToken(other, secret)
+	require.NoError(t, err)
+	require.NotEqual(t, claims.ID, otherClaims.ID)
 
 	_, err = ValidateToken(tok, "wrong")
 	require.Error(t, err)
@@ -25,3 +36,30 @@ func TestGenerateAndValidateToken(t *testing.T) {
 	_, err = ValidateToken("not-a-token", secret)
 	require.Error(t, err)
 }
+
+func TestMFAChallenge(t *testing.T) {
+	secret := "secret"
+	uid := uuid.New()
+	challenge, err := GenerateMFAChallenge(secret, time.Minute, uid)
+	require.NoError(t, err)
+
+	got, err := ValidateMFAChallenge(challenge, secret)
+	require.NoError(t, err)
+	require.Equal(t, uid, got)
+
+	// Challenges and access tokens are not interchangeable.
+	_, err = ValidateToken(challenge, secret)
+	require.Error(t, err)
+	access, err := GenerateToken(secret, time.Hour, uid, uuid.New(), "producer", "super_admin", true)
+	require.NoError(t, err)
+	_, err = ValidateMFAChallenge(access, secret)
+	require.Error(t, err)
+	claims, err := ValidateToken(access, secret)
+	require.NoError(t, err)
+	require.True(t, claims.MFA)
+
+	expired, err := GenerateMFAChallenge(secret, -time.Minute, uid)
+	require.NoError(t, err)
+	_, err = ValidateMFAChallenge(expired, secret)
+	require.Error(t, err)
+}
@@ -0,0 +1,208 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/saransh1220/blueprint-audio/internal/modules/auth/domain"
+)
+
+type PgMFARepository struct {
+	db *sqlx.DB
+}
+
+func NewMFARepository(db *sqlx.DB) *PgMFARepository {
+	return &PgMFARepository{db: db}
+}
+
+func (r *PgMFARepository) GetTOTP(ctx context.Context, userID uuid.UUID) (*domain.TOTPEnrollment, error) {
+	enrollment := &domain.TOTPEnrollment{}
+	err := r.db.GetContext(ctx, enrollment, `SELECT * FROM user_totp WHERE user_id = $1`, userID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return enrollment, nil
+}
+
+func (r *PgMFARepository) SaveTOTPSecret(ctx context.Context, userID uuid.UUID, secret string) error {
+	query := `
+		INSERT INTO user_totp (user_id, secret, created_at, updated_at)
+		VALUES ($1, $2, $3, $3)
+		ON CONFLICT (user_id) DO UPDATE
+		SET secret = EXCLUDED.secret, last_used_step = 0, updated_at = EXCLUDED.updated_at
+		WHERE user_totp.enabled_at IS NULL
+	`
+	_, err := r.db.ExecContext(ctx, query, userID, secret, time.Now())
+	return err
+}
+
+func (r *PgMFARepository) EnableTOTP(ctx context.Context, userID uuid.UUID, step int64, recoveryCodeDigests []string) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	result, err := tx.ExecContext(ctx, `
+		UPDATE user_totp SET enabled_at = $1, last_used_step = $2, updated_at = $1
+		WHERE user_id = $3 AND enabled_at IS NULL
+	`, now, step, userID)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return domain.ErrMFAAlreadyEnabled
+	}
+	if err := replaceRecoveryCodes(ctx, tx, userID, recoveryCodeDigests, now); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *PgMFARepository) DisableTOTP(ctx context.Context, userID uuid.UUID) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM mfa_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM user_totp WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *PgMFARepository) UseTOTPStep(ctx context.Context, userID uuid.UUID, step int64) (bool, error) {
+	query := `
+		UPDATE user_totp SET last_used_step = $1, updated_at = $2
+		WHERE user_id = $3 AND last_used_step < $1
+	`
+	result, err := r.db.ExecContext(ctx, query, step, time.Now(), userID)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+// ClaimMFAAttempt takes the row lock while counting, so concurrent attempts
+// are serialized. Locking resets failed_attempts, and each lockout doubles
+// the next one.
+func (r *PgMFARepository) ClaimMFAAttempt(ctx context.Context, userID uuid.UUID) (bool, error) {
+	query := `
+		UPDATE user_totp
+		SET failed_attempts = CASE WHEN failed_attempts + 1 >= $2 THEN 0 ELSE failed_attempts + 1 END,
+		    lockouts = CASE WHEN failed_attempts + 1 >= $2 THEN lockouts + 1 ELSE lockouts END,
+		    locked_until = CASE
+		        WHEN failed_attempts + 1 >= $2
+		        THEN $3::timestamp + LEAST($4::float8 * POWER(2, lockouts), $5::float8) * INTERVAL '1 second'
+		        ELSE locked_until
+		    END,
+		    updated_at = $3
+		WHERE user_id = $1 AND (locked_until IS NULL OR locked_until <= $3)
+	`
+	result, err := r.db.ExecContext(ctx, query, userID, domain.MFAMaxAttempts, time.Now(),
+		domain.MFALockout.Seconds(), domain.MFAMaxLockout.Seconds())
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+func (r *PgMFARepository) ResetMFAAttempts(ctx context.Context, userID uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE user_totp SET failed_attempts = 0, lockouts = 0, locked_until = NULL, updated_at = $1
+		WHERE user_id = $2 AND (failed_attempts > 0 OR lockouts > 0)
+	`, time.Now(), userID)
+	return err
+}
+
+func (r *PgMFARepository) ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, digests []string) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := replaceRecoveryCodes(ctx, tx, userID, digests, time.Now()); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func replaceRecoveryCodes(ctx context.Context, tx *sqlx.Tx, userID uuid.UUID, digests []string, now time.Time) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM mfa_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+	for _, digest := range digests {
+		id, err := uuid.NewV7()
+		if err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO mfa_recovery_codes (id, user_id, code_digest, created_at)
+			VALUES ($1, $2, $3, $4)
+		`, id, userID, digest, now)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *PgMFARepository) UseRecoveryCode(ctx context.Context, userID uuid.UUID, digest string) (bool, error) {
+	query := `
+		UPDATE mfa_recovery_codes SET used_at = $1
+		WHERE id = (
+			SELECT id FROM mfa_recovery_codes
+			WHERE user_id = $2 AND code_digest = $3 AND used_at IS NULL
+			LIMIT 1
+			FOR UPDATE
+		)
+	`
+	result, err := r.db.ExecContext(ctx, query, time.Now(), userID, digest)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+func (r *PgMFARepository) CountRecoveryCodes(ctx context.Context, userID uuid.UUID) (int, error) {
+	var count int
+	err := r.db.GetContext(ctx, &count, `SELECT COUNT(*) FROM mfa_recovery_codes WHERE user_id = $1 AND used_at IS NULL`, userID)
+	return count, err
+}
+
+func (r *PgMFARepository) MFARequired(ctx context.Context, role domain.SystemRole) (bool, error) {
+	var required bool
+	err := r.db.GetContext(ctx, &required, `SELECT mfa_required FROM system_role_mfa_policies WHERE system_role = $1`, role)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	return required, err
+}
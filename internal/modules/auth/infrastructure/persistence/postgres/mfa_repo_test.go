@@ -0,0 +1,146 @@
+package postgres_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/saransh1220/blueprint-audio/internal/modules/auth/domain"
+	"github.com/saransh1220/blueprint-audio/internal/modules/auth/infrastructure/persistence/postgres"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPgMFARepository_TOTP(t *testing.T) {
+	db, mock, cleanup := newMockDB(t)
+	defer cleanup()
+	repo := postgres.NewMFARepository(db)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	mock.ExpectQuery("SELECT \\* FROM user_totp").WithArgs(userID).WillReturnError(sql.ErrNoRows)
+	enrollment, err := repo.GetTOTP(ctx, userID)
+	require.NoError(t, err)
+	assert.Nil(t, enrollment)
+
+	now := time.Now()
+	mock.ExpectQuery("SELECT \\* FROM user_totp").WithArgs(userID).WillReturnRows(
+		sqlmock.NewRows([]string{"user_id", "secret", "enabled_at", "last_used_step", "created_at", "updated_at"}).
+			AddRow(userID, "SECRET", now, 42, now, now))
+	enrollment, err = repo.GetTOTP(ctx, userID)
+	require.NoError(t, err)
+	assert.True(t, enrollment.Enabled())
+	assert.Equal(t, int64(42), enrollment.LastUsedStep)
+
+	mock.ExpectExec("INSERT INTO user_totp .* WHERE user_totp.enabled_at IS NULL").
+		WithArgs(userID, "SECRET", sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+	require.NoError(t, repo.SaveTOTPSecret(ctx, userID, "SECRET"))
+
+	mock.ExpectExec("UPDATE user_totp SET last_used_step").WithArgs(int64(7), sqlmock.AnyArg(), userID).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE user_totp SET last_used_step").WithArgs(int64(7), sqlmock.AnyArg(), userID).WillReturnResult(sqlmock.NewResult(0, 0))
+	fresh, err := repo.UseTOTPStep(ctx, userID, 7)
+	require.NoError(t, err)
+	assert.True(t, fresh)
+	fresh, err = repo.UseTOTPStep(ctx, userID, 7)
+	require.NoError(t, err)
+	assert.False(t, fresh)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM mfa_recovery_codes").WithArgs(userID).WillReturnResult(sqlmock.NewResult(0, 10))
+	mock.ExpectExec("DELETE FROM user_totp").WithArgs(userID).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	require.NoError(t, repo.DisableTOTP(ctx, userID))
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPgMFARepository_MFAAttempts(t *testing.T) {
+	db, mock, cleanup := newMockDB(t)
+	defer cleanup()
+	repo := postgres.NewMFARepository(db)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	claim := "UPDATE user_totp\\s+SET failed_attempts = CASE WHEN failed_attempts \\+ 1 >= \\$2[\\s\\S]*WHERE user_id = \\$1 AND \\(locked_until IS NULL OR locked_until <= \\$3\\)"
+	mock.ExpectExec(claim).WithArgs(userID, domain.MFAMaxAttempts, sqlmock.AnyArg(), domain.MFALockout.Seconds(), domain.MFAMaxLockout.Seconds()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	allowed, err := repo.ClaimMFAAttempt(ctx, userID)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+
+	// Locked out.
+	mock.ExpectExec(claim).WillReturnResult(sqlmock.NewResult(0, 0))
+	allowed, err = repo.ClaimMFAAttempt(ctx, userID)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+
+	mock.ExpectExec("UPDATE user_totp SET failed_attempts = 0, lockouts = 0, locked_until = NULL").
+		WithArgs(sqlmock.AnyArg(), userID).WillReturnResult(sqlmock.NewResult(0, 1))
+	require.NoError(t, repo.ResetMFAAttempts(ctx, userID))
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPgMFARepository_EnableTOTP(t *testing.T) {
+	db, mock, cleanup := newMockDB(t)
+	defer cleanup()
+	repo := postgres.NewMFARepository(db)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE user_totp SET enabled_at").WithArgs(sqlmock.AnyArg(), int64(9), userID).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM mfa_recovery_codes").WithArgs(userID).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO mfa_recovery_codes").WithArgs(sqlmock.AnyArg(), userID, "a", sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO mfa_recovery_codes").WithArgs(sqlmock.AnyArg(), userID, "b", sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	require.NoError(t, repo.EnableTOTP(ctx, userID, 9, []string{"a", "b"}))
+
+	// Enabled concurrently by another request.
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE user_totp SET enabled_at").WithArgs(sqlmock.AnyArg(), int64(9), userID).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+	assert.ErrorIs(t, repo.EnableTOTP(ctx, userID, 9, []string{"a"}), domain.ErrMFAAlreadyEnabled)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPgMFARepository_RecoveryCodesAndPolicy(t *testing.T) {
+	db, mock, cleanup := newMockDB(t)
+	defer cleanup()
+	repo := postgres.NewMFARepository(db)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM mfa_recovery_codes").WithArgs(userID).WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec("INSERT INTO mfa_recovery_codes").WithArgs(sqlmock.AnyArg(), userID, "c", sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	require.NoError(t, repo.ReplaceRecoveryCodes(ctx, userID, []string{"c"}))
+
+	mock.ExpectExec("UPDATE mfa_recovery_codes SET used_at .* used_at IS NULL").
+		WithArgs(sqlmock.AnyArg(), userID, "c").WillReturnResult(sqlmock.NewResult(0, 1))
+	used, err := repo.UseRecoveryCode(ctx, userID, "c")
+	require.NoError(t, err)
+	assert.True(t, used)
+
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM mfa_recovery_codes").WithArgs(userID).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(9))
+	count, err := repo.CountRecoveryCodes(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, 9, count)
+
+	mock.ExpectQuery("SELECT mfa_required FROM system_role_mfa_policies").WithArgs(domain.SystemRoleSuperAdmin).
+		WillReturnRows(sqlmock.NewRows([]string{"mfa_required"}).AddRow(true))
+	mock.ExpectQuery("SELECT mfa_required FROM system_role_mfa_policies").WithArgs(domain.SystemRoleUser).WillReturnError(sql.ErrNoRows)
+	required, err := repo.MFARequired(ctx, domain.SystemRoleSuperAdmin)
+	require.NoError(t, err)
+	assert.True(t, required)
+	required, err = repo.MFARequired(ctx, domain.SystemRoleUser)
+	require.NoError(t, err)
+	assert.False(t, required)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,111 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/saransh1220/blueprint-audio/internal/modules/auth/domain"
+)
+
+type PgPasskeyRepository struct {
+	db *sqlx.DB
+}
+
+func NewPasskeyRepository(db *sqlx.DB) *PgPasskeyRepository {
+	return &PgPasskeyRepository{db: db}
+}
+
+func (r *PgPasskeyRepository) CreateChallenge(ctx context.Context, challenge *domain.WebAuthnChallenge) error {
+	if challenge.CreatedAt.IsZero() {
+		challenge.CreatedAt = time.Now()
+	}
+	// Challenges that were never finished are cleared as new ones are issued.
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM webauthn_challenges WHERE expires_at <= $1`, challenge.CreatedAt); err != nil {
+		return err
+	}
+	query := `INSERT INTO webauthn_challenges (challenge, user_id, purpose, expires_at, created_at)
+			  VALUES (:challenge, :user_id, :purpose, :expires_at, :created_at)`
+	_, err := r.db.NamedExecContext(ctx, query, challenge)
+	return err
+}
+
+func (r *PgPasskeyRepository) ConsumeChallenge(ctx context.Context, challenge string, purpose domain.WebAuthnPurpose) (*domain.WebAuthnChallenge, error) {
+	query := `
+		DELETE FROM webauthn_challenges
+		WHERE challenge = $1 AND purpose = $2 AND expires_at > $3
+		RETURNING challenge, user_id, purpose, expires_at, created_at
+	`
+	consumed := &domain.WebAuthnChallenge{}
+	err := r.db.GetContext(ctx, consumed, query, challenge, purpose, time.Now())
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return consumed, nil
+}
+
+func (r *PgPasskeyRepository) Create(ctx context.Context, passkey *domain.Passkey) error {
+	if passkey.ID == uuid.Nil {
+		id, err := uuid.NewV7()
+		if err != nil {
+			return fmt.Errorf("failed to generate uuid: %w", err)
+		}
+		passkey.ID = id
+	}
+	if passkey.CreatedAt.IsZero() {
+		passkey.CreatedAt = time.Now()
+	}
+	query := `INSERT INTO user_passkeys (id, user_id, credential_id, public_key, sign_count, name, created_at)
+			  VALUES (:id, :user_id, :credential_id, :public_key, :sign_count, :name, :created_at)`
+	if _, err := r.db.NamedExecContext(ctx, query, passkey); err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" { // Unique violation
+			return domain.ErrPasskeyAlreadyExists
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *PgPasskeyRepository) GetByCredentialID(ctx context.Context, credentialID []byte) (*domain.Passkey, error) {
+	passkey := &domain.Passkey{}
+	err := r.db.GetContext(ctx, passkey, `SELECT * FROM user_passkeys WHERE credential_id = $1`, credentialID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return passkey, nil
+}
+
+func (r *PgPasskeyRepository) ListByUserID(ctx context.Context, userID uuid.UUID) ([]domain.Passkey, error) {
+	passkeys := []domain.Passkey{}
+	if err := r.db.SelectContext(ctx, &passkeys, `SELECT * FROM user_passkeys WHERE user_id = $1 ORDER BY created_at DESC`, userID); err != nil {
+		return nil, err
+	}
+	return passkeys, nil
+}
+
+func (r *PgPasskeyRepository) RecordUse(ctx context.Context, id uuid.UUID, signCount int64) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE user_passkeys SET sign_count = $1, last_used_at = $2 WHERE id = $3`, signCount, time.Now(), id)
+	return err
+}
+
+func (r *PgPasskeyRepository) Delete(ctx context.Context, userID, id uuid.UUID) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM user_passkeys WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
@@ -0,0 +1,109 @@
+package postgres_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/saransh1220/blueprint-audio/internal/modules/auth/domain"
+	"github.com/saransh1220/blueprint-audio/internal/modules/auth/infrastructure/persistence/postgres"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPgPasskeyRepository_Challenges(t *testing.T) {
+	db, mock, cleanup := newMockDB(t)
+	defer cleanup()
+	repo := postgres.NewPasskeyRepository(db)
+	ctx := context.Background()
+	userID := uuid.New()
+	now := time.Now()
+
+	mock.ExpectExec("DELETE FROM webauthn_challenges WHERE expires_at").WithArgs(sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec("INSERT INTO webauthn_challenges").
+		WithArgs("abc", &userID, domain.WebAuthnPurposeRegister, now.Add(time.Minute), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	require.NoError(t, repo.CreateChallenge(ctx, &domain.WebAuthnChallenge{
+		Challenge: "abc",
+		UserID:    &userID,
+		Purpose:   domain.WebAuthnPurposeRegister,
+		ExpiresAt: now.Add(time.Minute),
+	}))
+
+	mock.ExpectQuery("DELETE FROM webauthn_challenges .* RETURNING").
+		WithArgs("abc", domain.WebAuthnPurposeRegister, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"challenge", "user_id", "purpose", "expires_at", "created_at"}).
+			AddRow("abc", userID, "register", now.Add(time.Minute), now))
+	challenge, err := repo.ConsumeChallenge(ctx, "abc", domain.WebAuthnPurposeRegister)
+	require.NoError(t, err)
+	require.NotNil(t, challenge.UserID)
+	assert.Equal(t, userID, *challenge.UserID)
+
+	// Used up or expired.
+	mock.ExpectQuery("DELETE FROM webauthn_challenges .* RETURNING").
+		WithArgs("abc", domain.WebAuthnPurposeRegister, sqlmock.AnyArg()).
+		WillReturnError(sql.ErrNoRows)
+	challenge, err = repo.ConsumeChallenge(ctx, "abc", domain.WebAuthnPurposeRegister)
+	require.NoError(t, err)
+	assert.Nil(t, challenge)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPgPasskeyRepository_Passkeys(t *testing.T) {
+	db, mock, cleanup := newMockDB(t)
+	defer cleanup()
+	repo := postgres.NewPasskeyRepository(db)
+	ctx := context.Background()
+	userID, passkeyID := uuid.New(), uuid.New()
+	now := time.Now()
+	columns := []string{"id", "user_id", "credential_id", "public_key", "sign_count", "name", "created_at", "last_used_at"}
+
+	passkey := &domain.Passkey{UserID: userID, CredentialID: []byte{1, 2}, PublicKey: []byte{3}, SignCount: 4, Name: "Phone"}
+	mock.ExpectExec("INSERT INTO user_passkeys").
+		WithArgs(sqlmock.AnyArg(), userID, []byte{1, 2}, []byte{3}, int64(4), "Phone", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	require.NoError(t, repo.Create(ctx, passkey))
+	assert.NotEqual(t, uuid.Nil, passkey.ID)
+	assert.False(t, passkey.CreatedAt.IsZero())
+
+	mock.ExpectExec("INSERT INTO user_passkeys").WillReturnError(&pq.Error{Code: "23505"})
+	assert.ErrorIs(t, repo.Create(ctx, &domain.Passkey{UserID: userID, CredentialID: []byte{1, 2}}), domain.ErrPasskeyAlreadyExists)
+
+	mock.ExpectQuery("SELECT \\* FROM user_passkeys WHERE credential_id").WithArgs([]byte{1, 2}).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(passkeyID, userID, []byte{1, 2}, []byte{3}, 4, "Phone", now, nil))
+	found, err := repo.GetByCredentialID(ctx, []byte{1, 2})
+	require.NoError(t, err)
+	assert.Equal(t, passkeyID, found.ID)
+	assert.Nil(t, found.LastUsedAt)
+
+	mock.ExpectQuery("SELECT \\* FROM user_passkeys WHERE credential_id").WithArgs([]byte{9}).WillReturnError(sql.ErrNoRows)
+	found, err = repo.GetByCredentialID(ctx, []byte{9})
+	require.NoError(t, err)
+	assert.Nil(t, found)
+
+	mock.ExpectQuery("SELECT \\* FROM user_passkeys WHERE user_id .* ORDER BY created_at DESC").WithArgs(userID).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(passkeyID, userID, []byte{1, 2}, []byte{3}, 4, "Phone", now, now))
+	passkeys, err := repo.ListByUserID(ctx, userID)
+	require.NoError(t, err)
+	require.Len(t, passkeys, 1)
+	require.NotNil(t, passkeys[0].LastUsedAt)
+
+	mock.ExpectExec("UPDATE user_passkeys SET sign_count").WithArgs(int64(5), sqlmock.AnyArg(), passkeyID).WillReturnResult(sqlmock.NewResult(0, 1))
+	require.NoError(t, repo.RecordUse(ctx, passkeyID, 5))
+
+	mock.ExpectExec("DELETE FROM user_passkeys").WithArgs(passkeyID, userID).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM user_passkeys").WithArgs(passkeyID, userID).WillReturnResult(sqlmock.NewResult(0, 0))
+	deleted, err := repo.Delete(ctx, userID, passkeyID)
+	require.NoError(t, err)
+	assert.True(t, deleted)
+	deleted, err = repo.Delete(ctx, userID, passkeyID)
+	require.NoError(t, err)
+	assert.False(t, deleted)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -27,8 +27,8 @@ func digestRefreshToken(token string) string {
 }
 
 func (r *PgSessionRepository) Create(ctx context.Context, session *domain.UserSession) error {
-	query := `INSERT INTO user_sessions (id, user_id, refresh_token_digest, is_revoked, expires_at, created_at, updated_at) 
-			  VALUES (:id, :user_id, :refresh_token_digest, :is_revoked, :expires_at, :created_at, :updated_at)`
+	query := `INSERT INTO user_sessions (id, user_id, refresh_token_digest, is_revoked, mfa_verified, device, user_agent, ip_address, location, last_used_at, expires_at, created_at, updated_at) 
+			  VALUES (:id, :user_id, :refresh_token_digest, :is_revoked, :mfa_verified, :device, :user_agent, :ip_address, :location, :last_used_at, :expires_at, :created_at, :updated_at)`
 
 	if session.ID == uuid.Nil {
 		sessionID, err := uuid.NewV7()
@@ -44,6 +44,9 @@ func (r *PgSessionRepository) Create(ctx context.Context, session *domain.UserSe
 	if session.UpdatedAt.IsZero() {
 		session.UpdatedAt = time.Now()
 	}
+	if session.LastUsedAt.IsZero() {
+		session.LastUsedAt = session.CreatedAt
+	}
 
 	persistedSession := *session
 	persistedSession.RefreshTokenDigest = digestRefreshToken(session.RefreshToken)
@@ -68,14 +71,143 @@ func (r *PgSessionRepository) GetByToken(ctx context.Context, token string) (*do
 	return session, nil
 }
 
+func (r *PgSessionRepository) Rotate(ctx context.Context, sessionID uuid.UUID, oldToken, newToken string) (bool, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	oldDigest := digestRefreshToken(oldToken)
+	now := time.Now()
+	result, err := tx.ExecContext(ctx, `
+		UPDATE user_sessions SET refresh_token_digest = $1, updated_at = $2
+		WHERE id = $3 AND refresh_token_digest = $4 AND is_revoked = false
+	`, digestRefreshToken(newToken), now, sessionID, oldDigest)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if rows == 0 {
+		return false, nil
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO superseded_refresh_tokens (refresh_token_digest, session_id, superseded_at)
+		VALUES ($1, $2, $3)
+	`, oldDigest, sessionID, now)
+	if err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+func (r *PgSessionRepository) GetSuperseded(ctx context.Context, token string) (*domain.SupersededRefreshToken, error) {
+	superseded := &domain.SupersededRefreshToken{}
+	query := `
+		SELECT s.*, t.superseded_at
+		FROM superseded_refresh_tokens t
+		JOIN user_sessions s ON s.id = t.session_id
+		WHERE t.refresh_token_digest = $1
+	`
+	err := r.db.GetContext(ctx, superseded, query, digestRefreshToken(token))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return superseded, nil
+}
+
 func (r *PgSessionRepository) Revoke(ctx context.Context, token string) error {
 	query := `UPDATE user_sessions SET is_revoked = true, updated_at = $1 WHERE refresh_token_digest = $2`
 	_, err := r.db.ExecContext(ctx, query, time.Now(), digestRefreshToken(token))
 	return err
 }
 
-func (r *PgSessionRepository) RevokeAllForUser(ctx context.Context, userID uuid.UUID) error {
-	query := `UPDATE user_sessions SET is_revoked = true, updated_at = $1 WHERE user_id = $2 AND is_revoked = false`
-	_, err := r.db.ExecContext(ctx, query, time.Now(), userID)
+func (r *PgSessionRepository) RevokeAllForUser(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error) {
+	query := `UPDATE user_sessions SET is_revoked = true, updated_at = $1 WHERE user_id = $2 AND is_revoked = false RETURNING id`
+	var ids []uuid.UUID
+	if err := r.db.SelectContext(ctx, &ids, query, time.Now(), userID); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+func (r *PgSessionRepository) IsActive(ctx context.Context, sessionID uuid.UUID) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM user_sessions s
+			JOIN users u ON u.id = s.user_id
+			WHERE s.id = $1 AND s.is_revoked = false AND s.expires_at > NOW() AND u.status = 'active'
+		)
+	`
+	var active bool
+	err := r.db.GetContext(ctx, &active, query, sessionID)
+	return active, err
+}
+
+func (r *PgSessionRepository) Touch(ctx context.Context, sessionID uuid.UUID, client domain.ClientInfo) error {
+	query := `
+		UPDATE user_sessions
+		SET last_used_at = $1,
+			device = COALESCE(NULLIF($2, ''), device),
+			user_agent = COALESCE(NULLIF($3, ''), user_agent),
+			ip_address = COALESCE(NULLIF($4, ''), ip_address),
+			location = COALESCE(NULLIF($5, ''), location)
+		WHERE id = $6
+	`
+	_, err := r.db.ExecContext(ctx, query, time.Now(), client.Device(), client.UserAgent, client.IPAddress, client.Location, sessionID)
 	return err
 }
+
+func (r *PgSessionRepository) ListActiveByUserID(ctx context.Context, userID uuid.UUID) ([]domain.UserSession, error) {
+	query := `
+		SELECT * FROM user_sessions
+		WHERE user_id = $1 AND is_revoked = false AND expires_at > NOW()
+		ORDER BY last_used_at DESC
+	`
+	sessions := []domain.UserSession{}
+	if err := r.db.SelectContext(ctx, &sessions, query, userID); err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+func (r *PgSessionRepository) RevokeByID(ctx context.Context, userID, sessionID uuid.UUID) (bool, error) {
+	query := `
+		UPDATE user_sessions SET is_revoked = true, updated_at = $1
+		WHERE id = $2 AND user_id = $3 AND is_revoked = false AND expires_at > NOW()
+	`
+	result, err := r.db.ExecContext(ctx, query, time.Now(), sessionID, userID)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+func (r *PgSessionRepository) RevokeOthers(ctx context.Context, userID, keepID uuid.UUID) ([]uuid.UUID, error) {
+	query := `UPDATE user_sessions SET is_revoked = true, updated_at = $1 WHERE user_id = $2 AND id <> $3 AND is_revoked = false RETURNING id`
+	var ids []uuid.UUID
+	if err := r.db.SelectContext(ctx, &ids, query, time.Now(), userID, keepID); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+func (r *PgSessionRepository) KnownDevices(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	query := `SELECT DISTINCT device FROM user_sessions WHERE user_id = $1 AND device <> ''`
+	var devices []string
+	if err := r.db.SelectContext(ctx, &devices, query, userID); err != nil {
+		return nil, err
+	}
+	return devices, nil
+}
@@ -5,9 +5,11 @@ import (
 	"database/sql"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/google/uuid"
+	"github.com/saransh1220/blueprint-audio/internal/modules/auth/domain"
 	"github.com/saransh1220/blueprint-audio/internal/modules/auth/infrastructure/persistence/postgres"
 	"github.com/stretchr/testify/require"
 )
@@ -29,12 +31,163 @@ func TestPgSessionRepositoryMissingAndErrorBranches(t *testing.T) {
 	require.Nil(t, session)
 
 	userID := uuid.New()
+	revokedIDs := []uuid.UUID{uuid.New(), uuid.New()}
+	mockDB.ExpectQuery("UPDATE user_sessions SET is_revoked = true.* RETURNING id").
+		WithArgs(sqlmock.AnyArg(), userID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(revokedIDs[0]).AddRow(revokedIDs[1]))
+	ids, err := repo.RevokeAllForUser(ctx, userID)
+	require.NoError(t, err)
+	require.Equal(t, revokedIDs, ids)
+
+	mockDB.ExpectQuery("UPDATE user_sessions SET is_revoked = true").
+		WithArgs(sqlmock.AnyArg(), userID).WillReturnError(errors.New("write failed"))
+	_, err = repo.RevokeAllForUser(ctx, userID)
+	require.EqualError(t, err, "write failed")
+	require.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestPgSessionRepositoryIsActive(t *testing.T) {
+	db, mockDB, cleanup := newMockDB(t)
+	defer cleanup()
+	repo := postgres.NewSessionRepository(db)
+	ctx := context.Background()
+	sessionID := uuid.New()
+
+	mockDB.ExpectQuery("SELECT EXISTS .* FROM user_sessions s\\s+JOIN users u .* u.status = 'active'").
+		WithArgs(sessionID).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	active, err := repo.IsActive(ctx, sessionID)
+	require.NoError(t, err)
+	require.True(t, active)
+
+	mockDB.ExpectQuery("SELECT EXISTS").
+		WithArgs(sessionID).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	active, err = repo.IsActive(ctx, sessionID)
+	require.NoError(t, err)
+	require.False(t, active)
+
+	mockDB.ExpectQuery("SELECT EXISTS").WithArgs(sessionID).WillReturnError(errors.New("read failed"))
+	_, err = repo.IsActive(ctx, sessionID)
+	require.EqualError(t, err, "read failed")
+	require.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestPgSessionRepositoryDeviceSessions(t *testing.T) {
+	db, mockDB, cleanup := newMockDB(t)
+	defer cleanup()
+	repo := postgres.NewSessionRepository(db)
+	ctx := context.Background()
+	userID := uuid.New()
+	sessionID := uuid.New()
+
+	client := domain.ClientInfo{
+		UserAgent: "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0 Safari/537.36",
+		IPAddress: "203.0.113.7",
+		Location:  "Mumbai, IN",
+	}
+	mockDB.ExpectExec("UPDATE user_sessions\\s+SET last_used_at").
+		WithArgs(sqlmock.AnyArg(), "Chrome on macOS", client.UserAgent, client.IPAddress, client.Location, sessionID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	require.NoError(t, repo.Touch(ctx, sessionID, client))
+
+	mockDB.ExpectQuery("SELECT \\* FROM user_sessions\\s+WHERE user_id = \\$1 AND is_revoked = false AND expires_at > NOW\\(\\)\\s+ORDER BY last_used_at DESC").
+		WithArgs(userID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "device", "ip_address"}).AddRow(sessionID, userID, "Chrome on macOS", "203.0.113.7"))
+	sessions, err := repo.ListActiveByUserID(ctx, userID)
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	require.Equal(t, "Chrome on macOS", sessions[0].Device)
+
 	mockDB.ExpectExec("UPDATE user_sessions SET is_revoked = true").
-		WithArgs(sqlmock.AnyArg(), userID).WillReturnResult(sqlmock.NewResult(0, 2))
-	require.NoError(t, repo.RevokeAllForUser(ctx, userID))
+		WithArgs(sqlmock.AnyArg(), sessionID, userID).WillReturnResult(sqlmock.NewResult(0, 1))
+	revoked, err := repo.RevokeByID(ctx, userID, sessionID)
+	require.NoError(t, err)
+	require.True(t, revoked)
 
 	mockDB.ExpectExec("UPDATE user_sessions SET is_revoked = true").
-		WithArgs(sqlmock.AnyArg(), userID).WillReturnError(errors.New("write failed"))
-	require.EqualError(t, repo.RevokeAllForUser(ctx, userID), "write failed")
+		WithArgs(sqlmock.AnyArg(), sessionID, userID).WillReturnResult(sqlmock.NewResult(0, 0))
+	revoked, err = repo.RevokeByID(ctx, userID, sessionID)
+	require.NoError(t, err)
+	require.False(t, revoked)
+
+	otherID := uuid.New()
+	mockDB.ExpectQuery("UPDATE user_sessions SET is_revoked = true.* id <> \\$3 .* RETURNING id").
+		WithArgs(sqlmock.AnyArg(), userID, sessionID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(otherID))
+	ids, err := repo.RevokeOthers(ctx, userID, sessionID)
+	require.NoError(t, err)
+	require.Equal(t, []uuid.UUID{otherID}, ids)
+
+	mockDB.ExpectQuery("SELECT DISTINCT device FROM user_sessions").
+		WithArgs(userID).
+		WillReturnRows(sqlmock.NewRows([]string{"device"}).AddRow("Chrome on macOS").AddRow("Safari on iOS"))
+	devices, err := repo.KnownDevices(ctx, userID)
+	require.NoError(t, err)
+	require.Equal(t, []string{"Chrome on macOS", "Safari on iOS"}, devices)
+	require.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestPgSessionRepositoryRotate(t *testing.T) {
+	db, mockDB, cleanup := newMockDB(t)
+	defer cleanup()
+	repo := postgres.NewSessionRepository(db)
+	ctx := context.Background()
+	sessionID := uuid.New()
+
+	mockDB.ExpectBegin()
+	mockDB.ExpectExec("UPDATE user_sessions SET refresh_token_digest = \\$1").
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sessionID, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mockDB.ExpectExec("INSERT INTO superseded_refresh_tokens").
+		WithArgs(sqlmock.AnyArg(), sessionID, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mockDB.ExpectCommit()
+	rotated, err := repo.Rotate(ctx, sessionID, "old", "new")
+	require.NoError(t, err)
+	require.True(t, rotated)
+
+	// Someone else rotated first: nothing is recorded.
+	mockDB.ExpectBegin()
+	mockDB.ExpectExec("UPDATE user_sessions SET refresh_token_digest").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mockDB.ExpectRollback()
+	rotated, err = repo.Rotate(ctx, sessionID, "old", "new")
+	require.NoError(t, err)
+	require.False(t, rotated)
+
+	mockDB.ExpectBegin()
+	mockDB.ExpectExec("UPDATE user_sessions SET refresh_token_digest").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mockDB.ExpectExec("INSERT INTO superseded_refresh_tokens").WillReturnError(errors.New("write failed"))
+	mockDB.ExpectRollback()
+	_, err = repo.Rotate(ctx, sessionID, "old", "new")
+	require.EqualError(t, err, "write failed")
+	require.NoError(t, mockDB.ExpectationsWereMet())
+}
+
+func TestPgSessionRepositoryGetSuperseded(t *testing.T) {
+	db, mockDB, cleanup := newMockDB(t)
+	defer cleanup()
+	repo := postgres.NewSessionRepository(db)
+	ctx := context.Background()
+	sessionID, userID := uuid.New(), uuid.New()
+
+	mockDB.ExpectQuery("SELECT s.\\*, t.superseded_at\\s+FROM superseded_refresh_tokens t\\s+JOIN user_sessions s").
+		WithArgs(sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "device", "superseded_at"}).
+			AddRow(sessionID, userID, "Chrome on macOS", time.Now()))
+	superseded, err := repo.GetSuperseded(ctx, "old")
+	require.NoError(t, err)
+	require.Equal(t, sessionID, superseded.ID)
+	require.Equal(t, userID, superseded.UserID)
+	require.False(t, superseded.SupersededAt.IsZero())
+
+	mockDB.ExpectQuery("FROM superseded_refresh_tokens").WithArgs(sqlmock.AnyArg()).WillReturnError(sql.ErrNoRows)
+	superseded, err = repo.GetSuperseded(ctx, "unknown")
+	require.NoError(t, err)
+	require.Nil(t, superseded)
+
+	mockDB.ExpectQuery("FROM superseded_refresh_tokens").WithArgs(sqlmock.AnyArg()).WillReturnError(errors.New("read failed"))
+	_, err = repo.GetSuperseded(ctx, "error")
+	require.EqualError(t, err, "read failed")
 	require.NoError(t, mockDB.ExpectationsWereMet())
 }
@@ -0,0 +1,90 @@
+// Package sessioncache remembers whether sessions are active. The Redis cache
+// is shared by every API replica; the in-memory one is used without Redis.
+package sessioncache
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+const keyPrefix = "auth:session:"
+
+// RedisCache stores session state in Redis, so a revocation on one replica
+// takes effect on all of them.
+type RedisCache struct {
+	client *redis.Client
+}
+
+func NewRedisCache(client *redis.Client) *RedisCache {
+	return &RedisCache{client: client}
+}
+
+func (c *RedisCache) Get(ctx context.Context, sessionID uuid.UUID) (bool, bool, error) {
+	value, err := c.client.Get(ctx, keyPrefix+sessionID.String()).Result()
+	if err == redis.Nil {
+		return false, false, nil
+	}
+	if err != nil {
+		return false, false, err
+	}
+	return value == "1", true, nil
+}
+
+func (c *RedisCache) Set(ctx context.Context, sessionID uuid.UUID, active bool, ttl time.Duration) error {
+	value := "0"
+	if active {
+		value = "1"
+	}
+	return c.client.Set(ctx, keyPrefix+sessionID.String(), value, ttl).Err()
+}
+
+type memoryEntry struct {
+	active    bool
+	expiresAt time.Time
+}
+
+// MemoryCache stores session state in this process. Other replicas see a
+// revocation once their own entry for the session expires.
+type MemoryCache struct {
+	mu         sync.Mutex
+	entries    map[uuid.UUID]memoryEntry
+	lastPruned time.Time
+	now        func() time.Time
+}
+
+// pruneInterval is how often expired entries are dropped.
+const pruneInterval = time.Minute
+
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{entries: map[uuid.UUID]memoryEntry{}, now: time.Now}
+}
+
+func (c *MemoryCache) Get(_ context.Context, sessionID uuid.UUID) (bool, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[sessionID]
+	if !ok || !c.now().Before(entry.expiresAt) {
+		return false, false, nil
+	}
+	return entry.active, true, nil
+}
+
+func (c *MemoryCache) Set(_ context.Context, sessionID uuid.UUID, active bool, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	if now.Sub(c.lastPruned) >= pruneInterval {
+		for id, entry := range c.entries {
+			if !now.Before(entry.expiresAt) {
+				delete(c.entries, id)
+			}
+		}
+		c.lastPruned = now
+	}
+	c.entries[sessionID] = memoryEntry{active: active, expiresAt: now.Add(ttl)}
+	return nil
+}
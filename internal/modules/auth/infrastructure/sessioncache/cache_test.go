@@ -0,0 +1,43 @@
+package sessioncache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryCache(t *testing.T) {
+	now := time.Now()
+	cache := NewMemoryCache()
+	cache.now = func() time.Time { return now }
+	ctx := context.Background()
+	active, revoked := uuid.New(), uuid.New()
+
+	_, found, err := cache.Get(ctx, active)
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	require.NoError(t, cache.Set(ctx, active, true, 5*time.Second))
+	require.NoError(t, cache.Set(ctx, revoked, false, time.Hour))
+	isActive, found, err := cache.Get(ctx, active)
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.True(t, isActive)
+	isActive, found, _ = cache.Get(ctx, revoked)
+	assert.True(t, found)
+	assert.False(t, isActive)
+
+	// Entries expire, and expired ones are pruned on a later write.
+	now = now.Add(2 * pruneInterval)
+	_, found, _ = cache.Get(ctx, active)
+	assert.False(t, found)
+	_, found, _ = cache.Get(ctx, revoked)
+	assert.True(t, found)
+	require.NoError(t, cache.Set(ctx, uuid.New(), true, time.Second))
+	assert.Len(t, cache.entries, 2)
+	assert.NotContains(t, cache.entries, active)
+}
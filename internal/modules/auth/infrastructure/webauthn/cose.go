@@ -0,0 +1,115 @@
+package webauthn
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// COSE algorithm identifiers offered when registering, in order of
+// preference.
+const (
+	AlgES256 int64 = -7
+	AlgEdDSA int64 = -8
+	AlgRS256 int64 = -257
+)
+
+// SupportedAlgorithms are the public key algorithms passkeys may use.
+var SupportedAlgorithms = []int64{AlgES256, AlgEdDSA, AlgRS256}
+
+// COSE key parameters, RFC 9053.
+const (
+	coseKty = 1
+	coseAlg = 3
+	coseCrv = -1
+	coseX   = -2
+	coseY   = -3
+	coseN   = -1
+	coseE   = -2
+
+	ktyOKP = 1
+	ktyEC2 = 2
+	ktyRSA = 3
+
+	crvP256    = 1
+	crvEd25519 = 6
+)
+
+// publicKey verifies signatures made with a credential's private key.
+type publicKey interface {
+	verify(data, signature []byte) bool
+}
+
+type ecdsaKey struct{ key *ecdsa.PublicKey }
+
+func (k ecdsaKey) verify(data, signature []byte) bool {
+	digest := sha256.Sum256(data)
+	return ecdsa.VerifyASN1(k.key, digest[:], signature)
+}
+
+type ed25519Key struct{ key ed25519.PublicKey }
+
+func (k ed25519Key) verify(data, signature []byte) bool {
+	return ed25519.Verify(k.key, data, signature)
+}
+
+type rsaKey struct{ key *rsa.PublicKey }
+
+func (k rsaKey) verify(data, signature []byte) bool {
+	digest := sha256.Sum256(data)
+	return rsa.VerifyPKCS1v15(k.key, crypto.SHA256, digest[:], signature) == nil
+}
+
+// parsePublicKey parses a COSE_Key with one of SupportedAlgorithms.
+func parsePublicKey(raw []byte) (publicKey, error) {
+	item, rest, err := decodeCBOR(raw)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) != 0 {
+		return nil, errors.New("trailing data after public key")
+	}
+	m, ok := item.(map[any]any)
+	if !ok {
+		return nil, errors.New("public key is not a map")
+	}
+	kty, _ := m[int64(coseKty)].(int64)
+	alg, _ := m[int64(coseAlg)].(int64)
+
+	switch {
+	case kty == ktyEC2 && alg == AlgES256:
+		crv, _ := m[int64(coseCrv)].(int64)
+		x, _ := m[int64(coseX)].([]byte)
+		y, _ := m[int64(coseY)].([]byte)
+		if crv != crvP256 || len(x) != 32 || len(y) != 32 {
+			return nil, errors.New("invalid P-256 public key")
+		}
+		key := &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !key.Curve.IsOnCurve(key.X, key.Y) {
+			return nil, errors.New("P-256 public key is not on the curve")
+		}
+		return ecdsaKey{key}, nil
+	case kty == ktyOKP && alg == AlgEdDSA:
+		crv, _ := m[int64(coseCrv)].(int64)
+		x, _ := m[int64(coseX)].([]byte)
+		if crv != crvEd25519 || len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 public key")
+		}
+		return ed25519Key{ed25519.PublicKey(x)}, nil
+	case kty == ktyRSA && alg == AlgRS256:
+		n, _ := m[int64(coseN)].([]byte)
+		e, _ := m[int64(coseE)].([]byte)
+		if len(n) < 256 || len(e) == 0 || len(e) > 4 {
+			return nil, errors.New("invalid RSA public key")
+		}
+		exponent := int(new(big.Int).SetBytes(e).Int64())
+		return rsaKey{&rsa.PublicKey{N: new(big.Int).SetBytes(n), E: exponent}}, nil
+	}
+	return nil, fmt.Errorf("unsupported public key type %d with algorithm %d", kty, alg)
+}
@@ -0,0 +1,229 @@
+// Package webauthn verifies the passkey (WebAuthn Level 2) registration and
+// authentication ceremonies for a single relying party.
+//
+// Attestation statements are not checked: registration asks for "none", so
+// a credential proves possession of its key but not its make or model.
+// Every ceremony requires user verification (a device PIN or biometric).
+package webauthn
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+const (
+	TypeCreate = "webauthn.create"
+	TypeGet    = "webauthn.get"
+)
+
+// Authenticator data flags.
+const (
+	flagUserPresent      = 0x01
+	flagUserVerified     = 0x04
+	flagAttestedCredData = 0x40
+)
+
+// ErrVerification is wrapped by every error from a ceremony that did not
+// verify.
+var ErrVerification = errors.New("webauthn: verification failed")
+
+// Base64URL is binary data carried in JSON as unpadded base64url, as the
+// browser's PublicKeyCredential JSON serialization does.
+type Base64URL []byte
+
+func (b Base64URL) MarshalJSON() ([]byte, error) {
+	return json.Marshal(base64.RawURLEncoding.EncodeToString(b))
+}
+
+func (b *Base64URL) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	decoded, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+	if err != nil {
+		return fmt.Errorf("invalid base64url: %w", err)
+	}
+	*b = decoded
+	return nil
+}
+
+// RelyingParty is the site passkeys are registered for.
+type RelyingParty struct {
+	// ID is the domain credentials are scoped to.
+	ID   string
+	Name string
+	// Origin is the only origin ceremonies are accepted from.
+	Origin string
+}
+
+// NewRelyingParty returns the relying party for the web app served at
+// appBaseURL.
+func NewRelyingParty(name, appBaseURL string) (RelyingParty, error) {
+	u, err := url.Parse(appBaseURL)
+	if err != nil || u.Scheme == "" || u.Hostname() == "" {
+		return RelyingParty{}, fmt.Errorf("invalid app base URL %q", appBaseURL)
+	}
+	return RelyingParty{ID: u.Hostname(), Name: name, Origin: u.Scheme + "://" + u.Host}, nil
+}
+
+// NewChallenge returns a random challenge, base64url encoded.
+func NewChallenge() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+type clientData struct {
+	Type      string `json:"type"`
+	Challenge string `json:"challenge"`
+	Origin    string `json:"origin"`
+}
+
+// Challenge returns the challenge a ceremony's client data answers, so the
+// caller can look up what it was issued for before verifying it.
+func Challenge(clientDataJSON []byte) (string, error) {
+	var data clientData
+	if err := json.Unmarshal(clientDataJSON, &data); err != nil || data.Challenge == "" {
+		return "", fmt.Errorf("%w: invalid client data", ErrVerification)
+	}
+	return data.Challenge, nil
+}
+
+// Credential is a newly registered public key credential.
+type Credential struct {
+	ID []byte
+	// PublicKey is the credential's COSE_Key.
+	PublicKey []byte
+	SignCount uint32
+}
+
+// VerifyRegistration verifies a navigator.credentials.create() response to
+// challenge and returns the new credential.
+func (rp RelyingParty) VerifyRegistration(clientDataJSON, attestationObject []byte, challenge string) (*Credential, error) {
+	if err := rp.verifyClientData(clientDataJSON, TypeCreate, challenge); err != nil {
+		return nil, err
+	}
+	item, _, err := decodeCBOR(attestationObject)
+	if err != nil {
+		return nil, fmt.Errorf("%w: attestation object: %v", ErrVerification, err)
+	}
+	attestation, _ := item.(map[any]any)
+	rawAuthData, ok := attestation["authData"].([]byte)
+	if !ok {
+		return nil, fmt.Errorf("%w: attestation object has no authenticator data", ErrVerification)
+	}
+
+	authData, err := rp.parseAuthenticatorData(rawAuthData)
+	if err != nil {
+		return nil, err
+	}
+	if authData.flags&flagAttestedCredData == 0 {
+		return nil, fmt.Errorf("%w: no attested credential", ErrVerification)
+	}
+	if _, err := parsePublicKey(authData.publicKey); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrVerification, err)
+	}
+	return &Credential{ID: authData.credentialID, PublicKey: authData.publicKey, SignCount: authData.signCount}, nil
+}
+
+// VerifyAssertion verifies a navigator.credentials.get() response to
+// challenge, signed by the credential with publicKey, and returns the
+// authenticator's signature counter.
+func (rp RelyingParty) VerifyAssertion(clientDataJSON, authenticatorData, signature []byte, challenge string, publicKey []byte) (uint32, error) {
+	if err := rp.verifyClientData(clientDataJSON, TypeGet, challenge); err != nil {
+		return 0, err
+	}
+	authData, err := rp.parseAuthenticatorData(authenticatorData)
+	if err != nil {
+		return 0, err
+	}
+	key, err := parsePublicKey(publicKey)
+	if err != nil {
+		return 0, fmt.Errorf("%w: stored public key: %v", ErrVerification, err)
+	}
+
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	signed := append(bytes.Clone(authenticatorData), clientDataHash[:]...)
+	if !key.verify(signed, signature) {
+		return 0, fmt.Errorf("%w: bad signature", ErrVerification)
+	}
+	return authData.signCount, nil
+}
+
+func (rp RelyingParty) verifyClientData(clientDataJSON []byte, ceremony, challenge string) error {
+	var data clientData
+	if err := json.Unmarshal(clientDataJSON, &data); err != nil {
+		return fmt.Errorf("%w: invalid client data", ErrVerification)
+	}
+	if data.Type != ceremony {
+		return fmt.Errorf("%w: client data type is %q, not %q", ErrVerification, data.Type, ceremony)
+	}
+	if subtle.ConstantTimeCompare([]byte(data.Challenge), []byte(challenge)) != 1 {
+		return fmt.Errorf("%w: challenge mismatch", ErrVerification)
+	}
+	if data.Origin != rp.Origin {
+		return fmt.Errorf("%w: unexpected origin %q", ErrVerification, data.Origin)
+	}
+	return nil
+}
+
+type authenticatorData struct {
+	flags        byte
+	signCount    uint32
+	credentialID []byte
+	publicKey    []byte
+}
+
+// parseAuthenticatorData parses authenticator data scoped to rp and with the
+// user present and verified.
+func (rp RelyingParty) parseAuthenticatorData(data []byte) (*authenticatorData, error) {
+	if len(data) < 37 {
+		return nil, fmt.Errorf("%w: authenticator data too short", ErrVerification)
+	}
+	rpIDHash := sha256.Sum256([]byte(rp.ID))
+	if subtle.ConstantTimeCompare(data[:32], rpIDHash[:]) != 1 {
+		return nil, fmt.Errorf("%w: credential is for another relying party", ErrVerification)
+	}
+	parsed := &authenticatorData{flags: data[32], signCount: binary.BigEndian.Uint32(data[33:37])}
+	if parsed.flags&flagUserPresent == 0 {
+		return nil, fmt.Errorf("%w: user not present", ErrVerification)
+	}
+	if parsed.flags&flagUserVerified == 0 {
+		return nil, fmt.Errorf("%w: user not verified", ErrVerification)
+	}
+	if parsed.flags&flagAttestedCredData == 0 {
+		return parsed, nil
+	}
+
+	// Attested credential data: AAGUID, credential ID length and ID, then
+	// the COSE public key.
+	rest := data[37:]
+	if len(rest) < 18 {
+		return nil, fmt.Errorf("%w: attested credential data too short", ErrVerification)
+	}
+	idLength := int(binary.BigEndian.Uint16(rest[16:18]))
+	rest = rest[18:]
+	if idLength == 0 || idLength > 1023 || len(rest) < idLength {
+		return nil, fmt.Errorf("%w: invalid credential ID", ErrVerification)
+	}
+	parsed.credentialID = rest[:idLength]
+	rest = rest[idLength:]
+	_, after, err := decodeCBOR(rest)
+	if err != nil {
+		return nil, fmt.Errorf("%w: credential public key: %v", ErrVerification, err)
+	}
+	parsed.publicKey = rest[:len(rest)-len(after)]
+	return parsed, nil
+}
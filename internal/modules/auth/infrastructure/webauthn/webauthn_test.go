@@ -0,0 +1,292 @@
+package webauthn
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// encodeCBOR encodes the values the tests need, with map keys in a
+// deterministic order.
+func encodeCBOR(t *testing.T, v any) []byte {
+	t.Helper()
+	head := func(major byte, n uint64) []byte {
+		switch {
+		case n < 24:
+			return []byte{major<<5 | byte(n)}
+		case n < 1<<8:
+			return []byte{major<<5 | 24, byte(n)}
+		case n < 1<<16:
+			return binary.BigEndian.AppendUint16([]byte{major<<5 | 25}, uint16(n))
+		default:
+			return binary.BigEndian.AppendUint32([]byte{major<<5 | 26}, uint32(n))
+		}
+	}
+	switch v := v.(type) {
+	case int:
+		if v < 0 {
+			return head(1, uint64(-1-v))
+		}
+		return head(0, uint64(v))
+	case []byte:
+		return append(head(2, uint64(len(v))), v...)
+	case string:
+		return append(head(3, uint64(len(v))), v...)
+	case bool:
+		if v {
+			return []byte{0xf5}
+		}
+		return []byte{0xf4}
+	case []any:
+		out := head(4, uint64(len(v)))
+		for _, item := range v {
+			out = append(out, encodeCBOR(t, item)...)
+		}
+		return out
+	case map[any]any:
+		keys := make([][]byte, 0, len(v))
+		encoded := map[string][]byte{}
+		for key, value := range v {
+			k := encodeCBOR(t, key)
+			keys = append(keys, k)
+			encoded[string(k)] = encodeCBOR(t, value)
+		}
+		sort.Slice(keys, func(i, j int) bool { return string(keys[i]) < string(keys[j]) })
+		out := head(5, uint64(len(v)))
+		for _, k := range keys {
+			out = append(append(out, k...), encoded[string(k)]...)
+		}
+		return out
+	}
+	t.Fatalf("cannot encode %T", v)
+	return nil
+}
+
+type testAuthenticator struct {
+	credentialID []byte
+	coseKey      []byte
+	sign         func(data []byte) []byte
+	signCount    uint32
+}
+
+func newES256Authenticator(t *testing.T) *testAuthenticator {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	x, y := make([]byte, 32), make([]byte, 32)
+	key.X.FillBytes(x)
+	key.Y.FillBytes(y)
+	return &testAuthenticator{
+		credentialID: []byte("es256-credential"),
+		coseKey:      encodeCBOR(t, map[any]any{1: 2, 3: -7, -1: 1, -2: x, -3: y}),
+		sign: func(data []byte) []byte {
+			digest := sha256.Sum256(data)
+			sig, err := ecdsa.SignASN1(rand.Reader, key, digest[:])
+			require.NoError(t, err)
+			return sig
+		},
+	}
+}
+
+func newEd25519Authenticator(t *testing.T) *testAuthenticator {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	return &testAuthenticator{
+		credentialID: []byte("ed25519-credential"),
+		coseKey:      encodeCBOR(t, map[any]any{1: 1, 3: -8, -1: 6, -2: []byte(pub)}),
+		sign:         func(data []byte) []byte { return ed25519.Sign(priv, data) },
+	}
+}
+
+func newRS256Authenticator(t *testing.T) *testAuthenticator {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	return &testAuthenticator{
+		credentialID: []byte("rs256-credential"),
+		coseKey:      encodeCBOR(t, map[any]any{1: 3, 3: -257, -1: key.N.Bytes(), -2: []byte{1, 0, 1}}),
+		sign: func(data []byte) []byte {
+			digest := sha256.Sum256(data)
+			sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+			require.NoError(t, err)
+			return sig
+		},
+	}
+}
+
+func (a *testAuthenticator) authData(rpID string, flags byte, attested bool) []byte {
+	hash := sha256.Sum256([]byte(rpID))
+	data := append(hash[:], flags)
+	data = binary.BigEndian.AppendUint32(data, a.signCount)
+	if attested {
+		data = append(data, make([]byte, 16)...)
+		data = binary.BigEndian.AppendUint16(data, uint16(len(a.credentialID)))
+		data = append(append(data, a.credentialID...), a.coseKey...)
+	}
+	return data
+}
+
+func clientDataJSON(t *testing.T, ceremony, challenge, origin string) []byte {
+	t.Helper()
+	data, err := json.Marshal(map[string]any{"type": ceremony, "challenge": challenge, "origin": origin, "crossOrigin": false})
+	require.NoError(t, err)
+	return data
+}
+
+func (a *testAuthenticator) create(t *testing.T, rp RelyingParty, challenge string, flags byte) ([]byte, []byte) {
+	t.Helper()
+	attestation := encodeCBOR(t, map[any]any{"fmt": "none", "attStmt": map[any]any{}, "authData": a.authData(rp.ID, flags, true)})
+	return clientDataJSON(t, TypeCreate, challenge, rp.Origin), attestation
+}
+
+func (a *testAuthenticator) get(t *testing.T, rp RelyingParty, challenge string) ([]byte, []byte, []byte) {
+	t.Helper()
+	a.signCount++
+	clientData := clientDataJSON(t, TypeGet, challenge, rp.Origin)
+	authData := a.authData(rp.ID, flagUserPresent|flagUserVerified, false)
+	hash := sha256.Sum256(clientData)
+	return clientData, authData, a.sign(append(append([]byte{}, authData...), hash[:]...))
+}
+
+func testRelyingParty(t *testing.T) RelyingParty {
+	t.Helper()
+	rp, err := NewRelyingParty("Blueprint", "https://app.example.com/home")
+	require.NoError(t, err)
+	return rp
+}
+
+func TestNewRelyingParty(t *testing.T) {
+	rp := testRelyingParty(t)
+	assert.Equal(t, RelyingParty{ID: "app.example.com", Name: "Blueprint", Origin: "https://app.example.com"}, rp)
+
+	local, err := NewRelyingParty("Blueprint", "http://localhost:4200")
+	require.NoError(t, err)
+	assert.Equal(t, "localhost", local.ID)
+	assert.Equal(t, "http://localhost:4200", local.Origin)
+
+	_, err = NewRelyingParty("Blueprint", "not a url")
+	assert.Error(t, err)
+}
+
+func TestRegistrationAndAssertion(t *testing.T) {
+	rp := testRelyingParty(t)
+	for name, authenticator := range map[string]*testAuthenticator{
+		"ES256":   newES256Authenticator(t),
+		"Ed25519": newEd25519Authenticator(t),
+		"RS256":   newRS256Authenticator(t),
+	} {
+		t.Run(name, func(t *testing.T) {
+			challenge, err := NewChallenge()
+			require.NoError(t, err)
+			clientData, attestation := authenticator.create(t, rp, challenge, flagUserPresent|flagUserVerified|flagAttestedCredData)
+
+			got, err := Challenge(clientData)
+			require.NoError(t, err)
+			assert.Equal(t, challenge, got)
+
+			credential, err := rp.VerifyRegistration(clientData, attestation, challenge)
+			require.NoError(t, err)
+			assert.Equal(t, authenticator.credentialID, credential.ID)
+			assert.Equal(t, authenticator.coseKey, credential.PublicKey)
+
+			clientData, authData, signature := authenticator.get(t, rp, challenge)
+			count, err := rp.VerifyAssertion(clientData, authData, signature, challenge, credential.PublicKey)
+			require.NoError(t, err)
+			assert.Equal(t, uint32(1), count)
+
+			signature[len(signature)-1] ^= 0xff
+			_, err = rp.VerifyAssertion(clientData, authData, signature, challenge, credential.PublicKey)
+			assert.ErrorIs(t, err, ErrVerification)
+		})
+	}
+}
+
+func TestVerificationFailures(t *testing.T) {
+	rp := testRelyingParty(t)
+	authenticator := newES256Authenticator(t)
+	allFlags := byte(flagUserPresent | flagUserVerified | flagAttestedCredData)
+
+	for name, tc := range map[string]struct {
+		clientData  []byte
+		attestation func() []byte
+	}{
+		"wrong ceremony":  {clientData: clientDataJSON(t, TypeGet, "c", rp.Origin)},
+		"wrong challenge": {clientData: clientDataJSON(t, TypeCreate, "other", rp.Origin)},
+		"wrong origin":    {clientData: clientDataJSON(t, TypeCreate, "c", "https://evil.example.com")},
+		"wrong relying party": {attestation: func() []byte {
+			return encodeCBOR(t, map[any]any{"fmt": "none", "authData": authenticator.authData("evil.example.com", allFlags, true)})
+		}},
+		"user not verified": {attestation: func() []byte {
+			_, attestation := authenticator.create(t, rp, "c", flagUserPresent|flagAttestedCredData)
+			return attestation
+		}},
+		"no credential": {attestation: func() []byte {
+			_, attestation := authenticator.create(t, rp, "c", flagUserPresent|flagUserVerified)
+			return attestation
+		}},
+		"not cbor": {attestation: func() []byte { return []byte{0xff} }},
+	} {
+		t.Run(name, func(t *testing.T) {
+			clientData, attestation := authenticator.create(t, rp, "c", allFlags)
+			if tc.clientData != nil {
+				clientData = tc.clientData
+			}
+			if tc.attestation != nil {
+				attestation = tc.attestation()
+			}
+			_, err := rp.VerifyRegistration(clientData, attestation, "c")
+			assert.ErrorIs(t, err, ErrVerification)
+		})
+	}
+
+	_, err := Challenge([]byte("{"))
+	assert.ErrorIs(t, err, ErrVerification)
+}
+
+func TestDecodeCBOR(t *testing.T) {
+	item, rest, err := decodeCBOR(append(encodeCBOR(t, []any{1, -300, "a", []byte{1}, true, map[any]any{"k": 70000}}), 0x00))
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0x00}, rest)
+	assert.Equal(t, []any{int64(1), int64(-300), "a", []byte{1}, true, map[any]any{"k": int64(70000)}}, item)
+
+	for name, data := range map[string][]byte{
+		"empty":             nil,
+		"truncated string":  {0x43, 0x01},
+		"huge array":        {0x9b, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+		"huge map":          {0xbb, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+		"indefinite length": {0x5f},
+		"array map key":     {0xa1, 0x80, 0x00},
+		"float":             {0xf9, 0x00, 0x00},
+		"deep nesting":      {0x81, 0x81, 0x81, 0x81, 0x81, 0x81, 0x81, 0x81, 0x81, 0x81, 0x00},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, _, err := decodeCBOR(data)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestBase64URL(t *testing.T) {
+	var b Base64URL
+	require.NoError(t, json.Unmarshal([]byte(`"_-8"`), &b))
+	assert.Equal(t, Base64URL{0xff, 0xef}, b)
+	require.NoError(t, json.Unmarshal([]byte(`"_-8="`), &b))
+	assert.Equal(t, Base64URL{0xff, 0xef}, b)
+	assert.Error(t, json.Unmarshal([]byte(`"+/"`), &b))
+
+	out, err := json.Marshal(b)
+	require.NoError(t, err)
+	assert.Equal(t, `"_-8"`, string(out))
+}
@@ -20,12 +20,27 @@ type AuthService interface {
 	Login(ctx context.Context, req application.LoginRequest) (*application.TokenPair, error)
 	GetUser(ctx context.Context, id uuid.UUID) (*domain.User, error)
 	GoogleLogin(ctx context.Context, googleClientID string, req application.GoogleLoginRequest) (*application.TokenPair, error)
-	RefreshSession(ctx context.Context, refreshToken string) (string, error)
+	RefreshSession(ctx context.Context, refreshToken string) (*application.TokenPair, error)
 	Logout(ctx context.Context, refreshToken string) error
 	VerifyEmail(ctx context.Context, req application.VerifyEmailRequest) error
 	ResendVerification(ctx context.Context, req application.ResendVerificationRequest) error
 	ForgotPassword(ctx context.Context, req application.ForgotPasswordRequest) error
 	ResetPassword(ctx context.Context, req application.ResetPasswordRequest) error
+	ListSessions(ctx context.Context, userID uuid.UUID) ([]domain.UserSession, error)
+	RevokeSession(ctx context.Context, userID, sessionID uuid.UUID) error
+	RevokeOtherSessions(ctx context.Context, userID, currentSessionID uuid.UUID) (int, error)
+	VerifyMFA(ctx context.Context, req application.VerifyMFARequest) (*application.TokenPair, error)
+	MFAStatus(ctx context.Context, userID uuid.UUID) (*application.MFAStatus, error)
+	SetupTOTP(ctx context.Context, userID uuid.UUID) (*application.TOTPSetup, error)
+	EnableTOTP(ctx context.Context, userID uuid.UUID, code string) ([]string, error)
+	DisableTOTP(ctx context.Context, userID uuid.UUID, code string) error
+	RegenerateRecoveryCodes(ctx context.Context, userID uuid.UUID, code string) ([]string, error)
+	BeginPasskeyRegistration(ctx context.Context, userID uuid.UUID) (*application.PasskeyCreationOptions, error)
+	FinishPasskeyRegistration(ctx context.Context, userID uuid.UUID, req application.PasskeyRegistrationRequest) (*domain.Passkey, error)
+	BeginPasskeyLogin(ctx context.Context) (*application.PasskeyRequestOptions, error)
+	FinishPasskeyLogin(ctx context.Context, req application.PasskeyLoginRequest) (*application.TokenPair, error)
+	ListPasskeys(ctx context.Context, userID uuid.UUID) ([]domain.Passkey, error)
+	DeletePasskey(ctx context.Context, userID, passkeyID uuid.UUID) error
 }
 
 // FileService defines the interface for file operations
@@ -107,7 +122,7 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	tokens, err := h.service.Login(r.Context(), req)
+	tokens, err := h.service.Login(withClientInfo(r), req)
 	if err != nil {
 		if err == domain.ErrInvalidCredentials {
 			http.Error(w, `{"error": "invalid credentials"}`, http.StatusUnauthorized)
@@ -124,6 +139,10 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, `{"error": "`+err.Error()+`"}`, http.StatusInternalServerError)
 		return
 	}
+	if tokens.MFAToken != "" {
+		writeMFAChallenge(w, tokens.MFAToken)
+		return
+	}
 
 	// Set HTTP-Only Cookie for the Refresh Token
 	h.setRefreshCookie(w, tokens.RefreshToken, time.Now().Add(h.refreshExpiry), 0)
@@ -177,7 +196,7 @@ func (h *AuthHandler) GoogleLogin(w http.ResponseWriter, r *http.Request) {
 
 	log.Printf("GoogleLogin Request Received: token length = %d", len(req.Token))
 
-	tokens, err := h.service.GoogleLogin(r.Context(), h.googleClientID, req)
+	tokens, err := h.service.GoogleLogin(withClientInfo(r), h.googleClientID, req)
 	if err != nil {
 		log.Printf("GoogleLogin Auth Service Error: %v", err)
 		if errors.Is(err, application.ErrGoogleAuthFailed) {
@@ -187,6 +206,10 @@ func (h *AuthHandler) GoogleLogin(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, `{"error":"internal server error"}`, http.StatusInternalServerError)
 		return
 	}
+	if tokens.MFAToken != "" {
+		writeMFAChallenge(w, tokens.MFAToken)
+		return
+	}
 
 	log.Printf("GoogleLogin Success!")
 	// Set HTTP-Only Cookie for the Refresh Token
@@ -212,17 +235,23 @@ func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	newAccessToken, err := h.service.RefreshSession(r.Context(), cookie.Value)
+	tokens, err := h.service.RefreshSession(withClientInfo(r), cookie.Value)
 	if err != nil {
 		log.Printf("Refresh Error (Service): %v", err)
-		// Specifically check for specific domain errors if necessary
+		if errors.Is(err, application.ErrRefreshTokenReused) {
+			// The session is gone; stop the browser sending its token.
+			h.setRefreshCookie(w, "", time.Unix(0, 0), -1)
+		}
 		http.Error(w, `{"error": "invalid or expired refresh token"}`, http.StatusUnauthorized)
 		return
 	}
 
+	// The refresh token is single use; the cookie carries its replacement.
+	h.setRefreshCookie(w, tokens.RefreshToken, time.Now().Add(h.refreshExpiry), 0)
+
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]string{"token": newAccessToken})
+	json.NewEncoder(w).Encode(map[string]string{"token": tokens.AccessToken})
 }
 
 func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
@@ -108,13 +108,36 @@ func TestAuthHandler_RefreshAndLogout(t *testing.T) {
 	w = httptest.NewRecorder()
 	h.Refresh(w, httptest.NewRequest(http.MethodPost, "/refresh", nil))
 	assert.Equal(t, http.StatusUnauthorized, w.Code)
-	service.On("RefreshSession", mock.Anything, "old").Return("new-access", nil).Once()
+	service.On("RefreshSession", mock.Anything, "old").Return(&application.TokenPair{AccessToken: "new-access", RefreshToken: "rotated"}, nil).Once()
 	req := httptest.NewRequest(http.MethodPost, "/refresh", nil)
 	req.AddCookie(&http.Cookie{Name: "refresh_token", Value: "old"})
 	w = httptest.NewRecorder()
 	h.Refresh(w, req)
 	assert.Equal(t, http.StatusOK, w.Code)
 	assert.Contains(t, w.Body.String(), "new-access")
+	if cookies := w.Result().Cookies(); assert.Len(t, cookies, 1) {
+		assert.Equal(t, "rotated", cookies[0].Value)
+		assert.True(t, cookies[0].HttpOnly)
+	}
+
+	// A reused token's session is revoked, so its cookie is cleared.
+	service.On("RefreshSession", mock.Anything, "old").Return(nil, application.ErrRefreshTokenReused).Once()
+	req = httptest.NewRequest(http.MethodPost, "/refresh", nil)
+	req.AddCookie(&http.Cookie{Name: "refresh_token", Value: "old"})
+	w = httptest.NewRecorder()
+	h.Refresh(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	if cookies := w.Result().Cookies(); assert.Len(t, cookies, 1) {
+		assert.Equal(t, -1, cookies[0].MaxAge)
+	}
+
+	service.On("RefreshSession", mock.Anything, "racing").Return(nil, application.ErrRefreshTokenSuperseded).Once()
+	req = httptest.NewRequest(http.MethodPost, "/refresh", nil)
+	req.AddCookie(&http.Cookie{Name: "refresh_token", Value: "racing"})
+	w = httptest.NewRecorder()
+	h.Refresh(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Empty(t, w.Result().Cookies())
 	service.On("Logout", mock.Anything, "old").Return(nil).Once()
 	req = httptest.NewRequest(http.MethodPost, "/logout", nil)
 	req.AddCookie(&http.Cookie{Name: "refresh_token", Value: "old"})
@@ -55,9 +55,10 @@ func (m *MockAuthService) GoogleLogin(ctx context.Context, googleClientID string
 	return args.Get(0).(*application.TokenPair), args.Error(1)
 }
 
-func (m *MockAuthService) RefreshSession(ctx context.Context, refreshToken string) (string, error) {
+func (m *MockAuthService) RefreshSession(ctx context.Context, refreshToken string) (*application.TokenPair, error) {
 	args := m.Called(ctx, refreshToken)
-	return args.String(0), args.Error(1)
+	tokens, _ := args.Get(0).(*application.TokenPair)
+	return tokens, args.Error(1)
 }
 
 func (m *MockAuthService) Logout(ctx context.Context, refreshToken string) error {
@@ -85,6 +86,90 @@ func (m *MockAuthService) ResetPassword(ctx context.Context, req application.Res
 	return args.Error(0)
 }
 
+func (m *MockAuthService) ListSessions(ctx context.Context, userID uuid.UUID) ([]domain.UserSession, error) {
+	args := m.Called(ctx, userID)
+	sessions, _ := args.Get(0).([]domain.UserSession)
+	return sessions, args.Error(1)
+}
+
+func (m *MockAuthService) RevokeSession(ctx context.Context, userID, sessionID uuid.UUID) error {
+	args := m.Called(ctx, userID, sessionID)
+	return args.Error(0)
+}
+
+func (m *MockAuthService) RevokeOtherSessions(ctx context.Context, userID, currentSessionID uuid.UUID) (int, error) {
+	args := m.Called(ctx, userID, currentSessionID)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockAuthService) VerifyMFA(ctx context.Context, req application.VerifyMFARequest) (*application.TokenPair, error) {
+	args := m.Called(ctx, req)
+	tokens, _ := args.Get(0).(*application.TokenPair)
+	return tokens, args.Error(1)
+}
+
+func (m *MockAuthService) MFAStatus(ctx context.Context, userID uuid.UUID) (*application.MFAStatus, error) {
+	args := m.Called(ctx, userID)
+	status, _ := args.Get(0).(*application.MFAStatus)
+	return status, args.Error(1)
+}
+
+func (m *MockAuthService) SetupTOTP(ctx context.Context, userID uuid.UUID) (*application.TOTPSetup, error) {
+	args := m.Called(ctx, userID)
+	setup, _ := args.Get(0).(*application.TOTPSetup)
+	return setup, args.Error(1)
+}
+
+func (m *MockAuthService) EnableTOTP(ctx context.Context, userID uuid.UUID, code string) ([]string, error) {
+	args := m.Called(ctx, userID, code)
+	codes, _ := args.Get(0).([]string)
+	return codes, args.Error(1)
+}
+
+func (m *MockAuthService) DisableTOTP(ctx context.Context, userID uuid.UUID, code string) error {
+	return m.Called(ctx, userID, code).Error(0)
+}
+
+func (m *MockAuthService) RegenerateRecoveryCodes(ctx context.Context, userID uuid.UUID, code string) ([]string, error) {
+	args := m.Called(ctx, userID, code)
+	codes, _ := args.Get(0).([]string)
+	return codes, args.Error(1)
+}
+
+func (m *MockAuthService) BeginPasskeyRegistration(ctx context.Context, userID uuid.UUID) (*application.PasskeyCreationOptions, error) {
+	args := m.Called(ctx, userID)
+	options, _ := args.Get(0).(*application.PasskeyCreationOptions)
+	return options, args.Error(1)
+}
+
+func (m *MockAuthService) FinishPasskeyRegistration(ctx context.Context, userID uuid.UUID, req application.PasskeyRegistrationRequest) (*domain.Passkey, error) {
+	args := m.Called(ctx, userID, req)
+	passkey, _ := args.Get(0).(*domain.Passkey)
+	return passkey, args.Error(1)
+}
+
+func (m *MockAuthService) BeginPasskeyLogin(ctx context.Context) (*application.PasskeyRequestOptions, error) {
+	args := m.Called(ctx)
+	options, _ := args.Get(0).(*application.PasskeyRequestOptions)
+	return options, args.Error(1)
+}
+
+func (m *MockAuthService) FinishPasskeyLogin(ctx context.Context, req application.PasskeyLoginRequest) (*application.TokenPair, error) {
+	args := m.Called(ctx, req)
+	tokens, _ := args.Get(0).(*application.TokenPair)
+	return tokens, args.Error(1)
+}
+
+func (m *MockAuthService) ListPasskeys(ctx context.Context, userID uuid.UUID) ([]domain.Passkey, error) {
+	args := m.Called(ctx, userID)
+	passkeys, _ := args.Get(0).([]domain.Passkey)
+	return passkeys, args.Error(1)
+}
+
+func (m *MockAuthService) DeletePasskey(ctx context.Context, userID, passkeyID uuid.UUID) error {
+	return m.Called(ctx, userID, passkeyID).Error(0)
+}
+
 // Mock FileService
 type MockFileService struct {
 	mock.Mock
@@ -0,0 +1,167 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/saransh1220/blueprint-audio/internal/gateway/middleware"
+	"github.com/saransh1220/blueprint-audio/internal/modules/auth/application"
+	"github.com/saransh1220/blueprint-audio/internal/modules/auth/domain"
+)
+
+// writeMFAChallenge answers a sign-in that still needs a second factor. No
+// session exists yet, so no refresh cookie is set.
+func writeMFAChallenge(w http.ResponseWriter, mfaToken string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]any{"mfa_required": true, "mfa_token": mfaToken})
+}
+
+// writeMFAError maps second factor errors to responses and reports whether
+// err was one of them.
+func writeMFAError(w http.ResponseWriter, err error) bool {
+	switch {
+	case errors.Is(err, domain.ErrInvalidMFACode):
+		http.Error(w, `{"error":"invalid two-factor code"}`, http.StatusBadRequest)
+	case errors.Is(err, domain.ErrMFALocked):
+		http.Error(w, `{"error":"too many two-factor attempts, try again later"}`, http.StatusTooManyRequests)
+	case errors.Is(err, domain.ErrMFANotSetUp):
+		http.Error(w, `{"error":"two-factor authentication has not been set up"}`, http.StatusBadRequest)
+	case errors.Is(err, domain.ErrMFAAlreadyEnabled):
+		http.Error(w, `{"error":"two-factor authentication is already enabled"}`, http.StatusConflict)
+	case errors.Is(err, domain.ErrMFANotEnabled):
+		http.Error(w, `{"error":"two-factor authentication is not enabled"}`, http.StatusConflict)
+	case errors.Is(err, domain.ErrMFARequiredForRole):
+		http.Error(w, `{"error":"two-factor authentication is required for your role"}`, http.StatusForbidden)
+	default:
+		return false
+	}
+	return true
+}
+
+func (h *AuthHandler) VerifyMFA(w http.ResponseWriter, r *http.Request) {
+	var req application.VerifyMFARequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"error":"invalid request body"}`, http.StatusBadRequest)
+		return
+	}
+
+	tokens, err := h.service.VerifyMFA(withClientInfo(r), req)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidMFAChallenge) {
+			http.Error(w, `{"error":"invalid or expired two-factor challenge, sign in again"}`, http.StatusUnauthorized)
+			return
+		}
+		if errors.Is(err, application.ErrAccountSuspended) {
+			http.Error(w, `{"error": "account suspended"}`, http.StatusForbidden)
+			return
+		}
+		if writeMFAError(w, err) {
+			return
+		}
+		log.Printf("VerifyMFA error: %v", err)
+		http.Error(w, `{"error":"internal server error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	h.setRefreshCookie(w, tokens.RefreshToken, time.Now().Add(h.refreshExpiry), 0)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"token": tokens.AccessToken})
+}
+
+func (h *AuthHandler) MFAStatus(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.ContextKeyUserId).(uuid.UUID)
+	if !ok {
+		http.Error(w, `{"error": "user not authenticated"}`, http.StatusUnauthorized)
+		return
+	}
+
+	status, err := h.service.MFAStatus(r.Context(), userID)
+	if err != nil {
+		log.Printf("MFAStatus error: %v", err)
+		http.Error(w, `{"error":"internal server error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(status)
+}
+
+func (h *AuthHandler) SetupTOTP(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.ContextKeyUserId).(uuid.UUID)
+	if !ok {
+		http.Error(w, `{"error": "user not authenticated"}`, http.StatusUnauthorized)
+		return
+	}
+
+	setup, err := h.service.SetupTOTP(r.Context(), userID)
+	if err != nil {
+		if writeMFAError(w, err) {
+			return
+		}
+		log.Printf("SetupTOTP error: %v", err)
+		http.Error(w, `{"error":"internal server error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(setup)
+}
+
+func (h *AuthHandler) EnableTOTP(w http.ResponseWriter, r *http.Request) {
+	h.withMFACode(w, r, "EnableTOTP", func(userID uuid.UUID, code string) (any, error) {
+		codes, err := h.service.EnableTOTP(r.Context(), userID, code)
+		return map[string][]string{"recovery_codes": codes}, err
+	})
+}
+
+func (h *AuthHandler) DisableTOTP(w http.ResponseWriter, r *http.Request) {
+	h.withMFACode(w, r, "DisableTOTP", func(userID uuid.UUID, code string) (any, error) {
+		return nil, h.service.DisableTOTP(r.Context(), userID, code)
+	})
+}
+
+func (h *AuthHandler) RegenerateRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	h.withMFACode(w, r, "RegenerateRecoveryCodes", func(userID uuid.UUID, code string) (any, error) {
+		codes, err := h.service.RegenerateRecoveryCodes(r.Context(), userID, code)
+		return map[string][]string{"recovery_codes": codes}, err
+	})
+}
+
+// withMFACode runs an action confirmed with a two-factor code from the
+// request body. A nil result is answered with 204.
+func (h *AuthHandler) withMFACode(w http.ResponseWriter, r *http.Request, name string, action func(userID uuid.UUID, code string) (any, error)) {
+	userID, ok := r.Context().Value(middleware.ContextKeyUserId).(uuid.UUID)
+	if !ok {
+		http.Error(w, `{"error": "user not authenticated"}`, http.StatusUnauthorized)
+		return
+	}
+	var req application.MFACodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Code == "" {
+		http.Error(w, `{"error":"code is required"}`, http.StatusBadRequest)
+		return
+	}
+
+	result, err := action(userID, req.Code)
+	if err != nil {
+		if writeMFAError(w, err) {
+			return
+		}
+		log.Printf("%s error: %v", name, err)
+		http.Error(w, `{"error":"internal server error"}`, http.StatusInternalServerError)
+		return
+	}
+	if result == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
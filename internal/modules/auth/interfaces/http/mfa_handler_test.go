@@ -0,0 +1,170 @@
+package http_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/saransh1220/blueprint-audio/internal/gateway/middleware"
+	"github.com/saransh1220/blueprint-audio/internal/modules/auth/application"
+	"github.com/saransh1220/blueprint-audio/internal/modules/auth/domain"
+	auth_http "github.com/saransh1220/blueprint-audio/internal/modules/auth/interfaces/http"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newMFAHandler(t *testing.T) (*auth_http.AuthHandler, *MockAuthService) {
+	t.Helper()
+	service := new(MockAuthService)
+	t.Cleanup(func() { service.AssertExpectations(t) })
+	return auth_http.NewAuthHandler(service, new(MockFileService), "client", time.Hour, false), service
+}
+
+func TestAuthHandler_LoginAsksForSecondFactor(t *testing.T) {
+	h, service := newMFAHandler(t)
+	service.On("Login", mock.Anything, application.LoginRequest{Email: "x", Password: "y"}).
+		Return(&application.TokenPair{MFAToken: "challenge"}, nil).Once()
+
+	rr := httptest.NewRecorder()
+	h.Login(rr, httptest.NewRequest(http.MethodPost, "/auth/login", bytes.NewBufferString(`{"email":"x","password":"y"}`)))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"mfa_required":true,"mfa_token":"challenge"}`, rr.Body.String())
+	assert.Empty(t, rr.Result().Cookies())
+}
+
+func TestAuthHandler_VerifyMFA(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{name: "success", status: http.StatusOK},
+		{name: "bad challenge", err: domain.ErrInvalidMFAChallenge, status: http.StatusUnauthorized},
+		{name: "bad code", err: domain.ErrInvalidMFACode, status: http.StatusBadRequest},
+		{name: "locked", err: domain.ErrMFALocked, status: http.StatusTooManyRequests},
+		{name: "suspended", err: application.ErrAccountSuspended, status: http.StatusForbidden},
+		{name: "failure", err: errors.New("db down"), status: http.StatusInternalServerError},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			h, service := newMFAHandler(t)
+			req := application.VerifyMFARequest{MFAToken: "challenge", Code: "123456"}
+			if tc.err != nil {
+				service.On("VerifyMFA", mock.Anything, req).Return(nil, tc.err).Once()
+			} else {
+				service.On("VerifyMFA", mock.Anything, req).Return(&application.TokenPair{AccessToken: "access", RefreshToken: "refresh"}, nil).Once()
+			}
+
+			rr := httptest.NewRecorder()
+			h.VerifyMFA(rr, httptest.NewRequest(http.MethodPost, "/auth/mfa/verify", bytes.NewBufferString(`{"mfa_token":"challenge","code":"123456"}`)))
+
+			assert.Equal(t, tc.status, rr.Code)
+			if tc.err == nil {
+				assert.JSONEq(t, `{"token":"access"}`, rr.Body.String())
+				cookies := rr.Result().Cookies()
+				require.Len(t, cookies, 1)
+				assert.Equal(t, "refresh", cookies[0].Value)
+			}
+		})
+	}
+
+	t.Run("invalid body", func(t *testing.T) {
+		h, _ := newMFAHandler(t)
+		rr := httptest.NewRecorder()
+		h.VerifyMFA(rr, httptest.NewRequest(http.MethodPost, "/auth/mfa/verify", bytes.NewBufferString(`{`)))
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
+
+func TestAuthHandler_TOTPManagement(t *testing.T) {
+	userID := uuid.New()
+	authed := func(method, path, body string) *http.Request {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		return req.WithContext(context.WithValue(req.Context(), middleware.ContextKeyUserId, userID))
+	}
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		h, _ := newMFAHandler(t)
+		for _, handle := range []http.HandlerFunc{h.MFAStatus, h.SetupTOTP, h.EnableTOTP, h.DisableTOTP, h.RegenerateRecoveryCodes} {
+			rr := httptest.NewRecorder()
+			handle(rr, httptest.NewRequest(http.MethodPost, "/auth/mfa", nil))
+			assert.Equal(t, http.StatusUnauthorized, rr.Code)
+		}
+	})
+
+	t.Run("status", func(t *testing.T) {
+		h, service := newMFAHandler(t)
+		service.On("MFAStatus", mock.Anything, userID).Return(&application.MFAStatus{TOTPEnabled: true, RecoveryCodesRemaining: 3}, nil).Once()
+
+		rr := httptest.NewRecorder()
+		h.MFAStatus(rr, authed(http.MethodGet, "/auth/mfa", ""))
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, `{"totp_enabled":true,"recovery_codes_remaining":3,"required":false}`, rr.Body.String())
+	})
+
+	t.Run("setup", func(t *testing.T) {
+		h, service := newMFAHandler(t)
+		service.On("SetupTOTP", mock.Anything, userID).Return(&application.TOTPSetup{Secret: "ABC", ProvisioningURI: "otpauth://totp/x"}, nil).Once()
+		service.On("SetupTOTP", mock.Anything, userID).Return(nil, domain.ErrMFAAlreadyEnabled).Once()
+
+		rr := httptest.NewRecorder()
+		h.SetupTOTP(rr, authed(http.MethodPost, "/auth/mfa/totp/setup", ""))
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, `{"secret":"ABC","otpauth_uri":"otpauth://totp/x"}`, rr.Body.String())
+
+		rr = httptest.NewRecorder()
+		h.SetupTOTP(rr, authed(http.MethodPost, "/auth/mfa/totp/setup", ""))
+		assert.Equal(t, http.StatusConflict, rr.Code)
+	})
+
+	t.Run("enable", func(t *testing.T) {
+		h, service := newMFAHandler(t)
+		service.On("EnableTOTP", mock.Anything, userID, "123456").Return([]string{"aaaaa-bbbbb"}, nil).Once()
+
+		rr := httptest.NewRecorder()
+		h.EnableTOTP(rr, authed(http.MethodPost, "/auth/mfa/totp/enable", `{"code":"123456"}`))
+		assert.Equal(t, http.StatusOK, rr.Code)
+		var body map[string][]string
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+		assert.Equal(t, []string{"aaaaa-bbbbb"}, body["recovery_codes"])
+
+		rr = httptest.NewRecorder()
+		h.EnableTOTP(rr, authed(http.MethodPost, "/auth/mfa/totp/enable", `{}`))
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("disable", func(t *testing.T) {
+		h, service := newMFAHandler(t)
+		service.On("DisableTOTP", mock.Anything, userID, "123456").Return(nil).Once()
+		service.On("DisableTOTP", mock.Anything, userID, "654321").Return(domain.ErrMFARequiredForRole).Once()
+
+		rr := httptest.NewRecorder()
+		h.DisableTOTP(rr, authed(http.MethodPost, "/auth/mfa/totp/disable", `{"code":"123456"}`))
+		assert.Equal(t, http.StatusNoContent, rr.Code)
+
+		rr = httptest.NewRecorder()
+		h.DisableTOTP(rr, authed(http.MethodPost, "/auth/mfa/totp/disable", `{"code":"654321"}`))
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+	})
+
+	t.Run("recovery codes", func(t *testing.T) {
+		h, service := newMFAHandler(t)
+		service.On("RegenerateRecoveryCodes", mock.Anything, userID, "123456").Return(nil, domain.ErrMFANotEnabled).Once()
+		service.On("RegenerateRecoveryCodes", mock.Anything, userID, "654321").Return(nil, errors.New("db down")).Once()
+
+		rr := httptest.NewRecorder()
+		h.RegenerateRecoveryCodes(rr, authed(http.MethodPost, "/auth/mfa/recovery-codes", `{"code":"123456"}`))
+		assert.Equal(t, http.StatusConflict, rr.Code)
+
+		rr = httptest.NewRecorder()
+		h.RegenerateRecoveryCodes(rr, authed(http.MethodPost, "/auth/mfa/recovery-codes", `{"code":"654321"}`))
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	})
+}
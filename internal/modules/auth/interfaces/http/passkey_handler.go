@@ -0,0 +1,184 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/saransh1220/blueprint-audio/internal/gateway/middleware"
+	"github.com/saransh1220/blueprint-audio/internal/modules/auth/application"
+	"github.com/saransh1220/blueprint-audio/internal/modules/auth/domain"
+)
+
+type passkeyResponse struct {
+	ID         uuid.UUID  `json:"id"`
+	Name       string     `json:"name"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+}
+
+func newPasskeyResponse(passkey domain.Passkey) passkeyResponse {
+	return passkeyResponse{
+		ID:         passkey.ID,
+		Name:       passkey.Name,
+		CreatedAt:  passkey.CreatedAt,
+		LastUsedAt: passkey.LastUsedAt,
+	}
+}
+
+// writePasskeyError maps failed ceremonies to responses and reports whether
+// err was one of them.
+func writePasskeyError(w http.ResponseWriter, err error) bool {
+	switch {
+	case errors.Is(err, domain.ErrInvalidPasskeyChallenge):
+		http.Error(w, `{"error":"invalid or expired passkey challenge, try again"}`, http.StatusUnauthorized)
+	case errors.Is(err, domain.ErrInvalidPasskey), errors.Is(err, domain.ErrPasskeyCloned):
+		http.Error(w, `{"error":"passkey could not be verified"}`, http.StatusUnauthorized)
+	default:
+		return false
+	}
+	return true
+}
+
+func (h *AuthHandler) BeginPasskeyRegistration(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.ContextKeyUserId).(uuid.UUID)
+	if !ok {
+		http.Error(w, `{"error": "user not authenticated"}`, http.StatusUnauthorized)
+		return
+	}
+
+	options, err := h.service.BeginPasskeyRegistration(r.Context(), userID)
+	if err != nil {
+		log.Printf("BeginPasskeyRegistration error: %v", err)
+		http.Error(w, `{"error":"internal server error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{"publicKey": options})
+}
+
+func (h *AuthHandler) FinishPasskeyRegistration(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.ContextKeyUserId).(uuid.UUID)
+	if !ok {
+		http.Error(w, `{"error": "user not authenticated"}`, http.StatusUnauthorized)
+		return
+	}
+	var req application.PasskeyRegistrationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"error":"invalid request body"}`, http.StatusBadRequest)
+		return
+	}
+
+	passkey, err := h.service.FinishPasskeyRegistration(withClientInfo(r), userID, req)
+	if err != nil {
+		if errors.Is(err, domain.ErrPasskeyAlreadyExists) {
+			http.Error(w, `{"error":"passkey is already registered"}`, http.StatusConflict)
+			return
+		}
+		// The user is signed in; a failed ceremony is a bad request rather
+		// than a failed authentication.
+		if errors.Is(err, domain.ErrInvalidPasskeyChallenge) || errors.Is(err, domain.ErrInvalidPasskey) {
+			http.Error(w, `{"error":"passkey could not be verified"}`, http.StatusBadRequest)
+			return
+		}
+		log.Printf("FinishPasskeyRegistration error: %v", err)
+		http.Error(w, `{"error":"internal server error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(newPasskeyResponse(*passkey))
+}
+
+func (h *AuthHandler) BeginPasskeyLogin(w http.ResponseWriter, r *http.Request) {
+	options, err := h.service.BeginPasskeyLogin(r.Context())
+	if err != nil {
+		log.Printf("BeginPasskeyLogin error: %v", err)
+		http.Error(w, `{"error":"internal server error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{"publicKey": options})
+}
+
+func (h *AuthHandler) FinishPasskeyLogin(w http.ResponseWriter, r *http.Request) {
+	var req application.PasskeyLoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"error":"invalid request body"}`, http.StatusBadRequest)
+		return
+	}
+
+	tokens, err := h.service.FinishPasskeyLogin(withClientInfo(r), req)
+	if err != nil {
+		if writePasskeyError(w, err) {
+			return
+		}
+		if errors.Is(err, application.ErrAccountSuspended) {
+			http.Error(w, `{"error": "account suspended"}`, http.StatusForbidden)
+			return
+		}
+		log.Printf("FinishPasskeyLogin error: %v", err)
+		http.Error(w, `{"error":"internal server error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	h.setRefreshCookie(w, tokens.RefreshToken, time.Now().Add(h.refreshExpiry), 0)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"token": tokens.AccessToken})
+}
+
+func (h *AuthHandler) ListPasskeys(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.ContextKeyUserId).(uuid.UUID)
+	if !ok {
+		http.Error(w, `{"error": "user not authenticated"}`, http.StatusUnauthorized)
+		return
+	}
+
+	passkeys, err := h.service.ListPasskeys(r.Context(), userID)
+	if err != nil {
+		log.Printf("ListPasskeys error: %v", err)
+		http.Error(w, `{"error":"internal server error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	response := make([]passkeyResponse, 0, len(passkeys))
+	for _, passkey := range passkeys {
+		response = append(response, newPasskeyResponse(passkey))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{"passkeys": response})
+}
+
+func (h *AuthHandler) DeletePasskey(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.ContextKeyUserId).(uuid.UUID)
+	if !ok {
+		http.Error(w, `{"error": "user not authenticated"}`, http.StatusUnauthorized)
+		return
+	}
+	passkeyID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, `{"error":"invalid passkey id"}`, http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.DeletePasskey(r.Context(), userID, passkeyID); err != nil {
+		if errors.Is(err, domain.ErrPasskeyNotFound) {
+			http.Error(w, `{"error":"passkey not found"}`, http.StatusNotFound)
+			return
+		}
+		log.Printf("DeletePasskey error: %v", err)
+		http.Error(w, `{"error":"internal server error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,158 @@
+package http_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/saransh1220/blueprint-audio/internal/gateway/middleware"
+	"github.com/saransh1220/blueprint-audio/internal/modules/auth/application"
+	"github.com/saransh1220/blueprint-audio/internal/modules/auth/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthHandler_PasskeyLogin(t *testing.T) {
+	t.Run("begin", func(t *testing.T) {
+		h, service := newMFAHandler(t)
+		service.On("BeginPasskeyLogin", mock.Anything).
+			Return(&application.PasskeyRequestOptions{Challenge: "abc", RPID: "example.com", Timeout: 1000, UserVerification: "required", AllowCredentials: []application.PasskeyDescriptor{}}, nil).Once()
+
+		rr := httptest.NewRecorder()
+		h.BeginPasskeyLogin(rr, httptest.NewRequest(http.MethodPost, "/auth/webauthn/login/begin", nil))
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, `{"publicKey":{"challenge":"abc","rpId":"example.com","timeout":1000,"userVerification":"required","allowCredentials":[]}}`, rr.Body.String())
+	})
+
+	for _, tc := range []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{name: "success", status: http.StatusOK},
+		{name: "expired challenge", err: domain.ErrInvalidPasskeyChallenge, status: http.StatusUnauthorized},
+		{name: "bad signature", err: domain.ErrInvalidPasskey, status: http.StatusUnauthorized},
+		{name: "cloned", err: domain.ErrPasskeyCloned, status: http.StatusUnauthorized},
+		{name: "suspended", err: application.ErrAccountSuspended, status: http.StatusForbidden},
+		{name: "failure", err: errors.New("db down"), status: http.StatusInternalServerError},
+	} {
+		t.Run("finish "+tc.name, func(t *testing.T) {
+			h, service := newMFAHandler(t)
+			req := application.PasskeyLoginRequest{Credential: application.PasskeyCredential{RawID: []byte{1, 2, 3}}}
+			if tc.err != nil {
+				service.On("FinishPasskeyLogin", mock.Anything, req).Return(nil, tc.err).Once()
+			} else {
+				service.On("FinishPasskeyLogin", mock.Anything, req).Return(&application.TokenPair{AccessToken: "access", RefreshToken: "refresh"}, nil).Once()
+			}
+
+			rr := httptest.NewRecorder()
+			h.FinishPasskeyLogin(rr, httptest.NewRequest(http.MethodPost, "/auth/webauthn/login/finish", bytes.NewBufferString(`{"credential":{"rawId":"AQID","response":{}}}`)))
+
+			assert.Equal(t, tc.status, rr.Code)
+			if tc.err == nil {
+				assert.JSONEq(t, `{"token":"access"}`, rr.Body.String())
+				cookies := rr.Result().Cookies()
+				require.Len(t, cookies, 1)
+				assert.Equal(t, "refresh", cookies[0].Value)
+			}
+		})
+	}
+
+	t.Run("finish invalid body", func(t *testing.T) {
+		h, _ := newMFAHandler(t)
+		rr := httptest.NewRecorder()
+		h.FinishPasskeyLogin(rr, httptest.NewRequest(http.MethodPost, "/auth/webauthn/login/finish", bytes.NewBufferString(`{"credential":{"rawId":"!!"}}`)))
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
+
+func TestAuthHandler_PasskeyManagement(t *testing.T) {
+	userID, passkeyID := uuid.New(), uuid.New()
+	authed := func(method, path, body string) *http.Request {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		return req.WithContext(context.WithValue(req.Context(), middleware.ContextKeyUserId, userID))
+	}
+	createdAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		h, _ := newMFAHandler(t)
+		for _, handle := range []http.HandlerFunc{h.BeginPasskeyRegistration, h.FinishPasskeyRegistration, h.ListPasskeys, h.DeletePasskey} {
+			rr := httptest.NewRecorder()
+			handle(rr, httptest.NewRequest(http.MethodPost, "/auth/webauthn", nil))
+			assert.Equal(t, http.StatusUnauthorized, rr.Code)
+		}
+	})
+
+	t.Run("begin registration", func(t *testing.T) {
+		h, service := newMFAHandler(t)
+		service.On("BeginPasskeyRegistration", mock.Anything, userID).Return(&application.PasskeyCreationOptions{Challenge: "abc"}, nil).Once()
+		service.On("BeginPasskeyRegistration", mock.Anything, userID).Return(nil, errors.New("db down")).Once()
+
+		rr := httptest.NewRecorder()
+		h.BeginPasskeyRegistration(rr, authed(http.MethodPost, "/auth/webauthn/register/begin", ""))
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, rr.Body.String(), `"publicKey":{"challenge":"abc"`)
+
+		rr = httptest.NewRecorder()
+		h.BeginPasskeyRegistration(rr, authed(http.MethodPost, "/auth/webauthn/register/begin", ""))
+		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	})
+
+	t.Run("finish registration", func(t *testing.T) {
+		h, service := newMFAHandler(t)
+		req := application.PasskeyRegistrationRequest{Name: "Laptop"}
+		service.On("FinishPasskeyRegistration", mock.Anything, userID, req).
+			Return(&domain.Passkey{ID: passkeyID, UserID: userID, Name: "Laptop", CreatedAt: createdAt}, nil).Once()
+		service.On("FinishPasskeyRegistration", mock.Anything, userID, req).Return(nil, domain.ErrPasskeyAlreadyExists).Once()
+		service.On("FinishPasskeyRegistration", mock.Anything, userID, req).Return(nil, domain.ErrInvalidPasskey).Once()
+
+		rr := httptest.NewRecorder()
+		h.FinishPasskeyRegistration(rr, authed(http.MethodPost, "/auth/webauthn/register/finish", `{"name":"Laptop"}`))
+		assert.Equal(t, http.StatusCreated, rr.Code)
+		assert.JSONEq(t, `{"id":"`+passkeyID.String()+`","name":"Laptop","created_at":"2026-01-02T03:04:05Z","last_used_at":null}`, rr.Body.String())
+
+		rr = httptest.NewRecorder()
+		h.FinishPasskeyRegistration(rr, authed(http.MethodPost, "/auth/webauthn/register/finish", `{"name":"Laptop"}`))
+		assert.Equal(t, http.StatusConflict, rr.Code)
+
+		rr = httptest.NewRecorder()
+		h.FinishPasskeyRegistration(rr, authed(http.MethodPost, "/auth/webauthn/register/finish", `{"name":"Laptop"}`))
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("list", func(t *testing.T) {
+		h, service := newMFAHandler(t)
+		service.On("ListPasskeys", mock.Anything, userID).
+			Return([]domain.Passkey{{ID: passkeyID, UserID: userID, Name: "Phone", CreatedAt: createdAt, LastUsedAt: &createdAt}}, nil).Once()
+
+		rr := httptest.NewRecorder()
+		h.ListPasskeys(rr, authed(http.MethodGet, "/auth/webauthn/credentials", ""))
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, `{"passkeys":[{"id":"`+passkeyID.String()+`","name":"Phone","created_at":"2026-01-02T03:04:05Z","last_used_at":"2026-01-02T03:04:05Z"}]}`, rr.Body.String())
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		h, service := newMFAHandler(t)
+		remove := func(id string) int {
+			req := authed(http.MethodDelete, "/auth/webauthn/credentials/"+id, "")
+			req.SetPathValue("id", id)
+			rr := httptest.NewRecorder()
+			h.DeletePasskey(rr, req)
+			return rr.Code
+		}
+
+		assert.Equal(t, http.StatusBadRequest, remove("not-a-uuid"))
+
+		service.On("DeletePasskey", mock.Anything, userID, passkeyID).Return(nil).Once()
+		assert.Equal(t, http.StatusNoContent, remove(passkeyID.String()))
+
+		service.On("DeletePasskey", mock.Anything, userID, passkeyID).Return(domain.ErrPasskeyNotFound).Once()
+		assert.Equal(t, http.StatusNotFound, remove(passkeyID.String()))
+	})
+}
@@ -0,0 +1,120 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/saransh1220/blueprint-audio/internal/gateway/middleware"
+	"github.com/saransh1220/blueprint-audio/internal/modules/auth/domain"
+	"github.com/saransh1220/blueprint-audio/internal/shared/clientinfo"
+)
+
+type sessionResponse struct {
+	ID         uuid.UUID `json:"id"`
+	Device     string    `json:"device"`
+	UserAgent  string    `json:"user_agent"`
+	IPAddress  string    `json:"ip_address"`
+	Location   string    `json:"location"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	CreatedAt  time.Time `json:"created_at"`
+	// Current marks the session the request was made with.
+	Current bool `json:"current"`
+}
+
+func (h *AuthHandler) ListSessions(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.ContextKeyUserId).(uuid.UUID)
+	if !ok {
+		http.Error(w, `{"error": "user not authenticated"}`, http.StatusUnauthorized)
+		return
+	}
+	currentID, _ := r.Context().Value(middleware.ContextKeySessionID).(uuid.UUID)
+
+	sessions, err := h.service.ListSessions(r.Context(), userID)
+	if err != nil {
+		log.Printf("ListSessions error: %v", err)
+		http.Error(w, `{"error":"internal server error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	response := make([]sessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		response = append(response, sessionResponse{
+			ID:         session.ID,
+			Device:     session.Device,
+			UserAgent:  session.UserAgent,
+			IPAddress:  session.IPAddress,
+			Location:   session.Location,
+			LastUsedAt: session.LastUsedAt,
+			CreatedAt:  session.CreatedAt,
+			Current:    session.ID == currentID,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{"sessions": response})
+}
+
+func (h *AuthHandler) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.ContextKeyUserId).(uuid.UUID)
+	if !ok {
+		http.Error(w, `{"error": "user not authenticated"}`, http.StatusUnauthorized)
+		return
+	}
+	sessionID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, `{"error":"invalid session id"}`, http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.RevokeSession(r.Context(), userID, sessionID); err != nil {
+		if errors.Is(err, domain.ErrSessionNotFound) {
+			http.Error(w, `{"error":"session not found"}`, http.StatusNotFound)
+			return
+		}
+		log.Printf("RevokeSession error: %v", err)
+		http.Error(w, `{"error":"internal server error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *AuthHandler) RevokeOtherSessions(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.ContextKeyUserId).(uuid.UUID)
+	if !ok {
+		http.Error(w, `{"error": "user not authenticated"}`, http.StatusUnauthorized)
+		return
+	}
+	// Without knowing the current session every session would be revoked,
+	// including the caller's.
+	currentID, ok := r.Context().Value(middleware.ContextKeySessionID).(uuid.UUID)
+	if !ok || currentID == uuid.Nil {
+		http.Error(w, `{"error":"current session unknown, sign in again"}`, http.StatusBadRequest)
+		return
+	}
+
+	revoked, err := h.service.RevokeOtherSessions(r.Context(), userID, currentID)
+	if err != nil {
+		log.Printf("RevokeOtherSessions error: %v", err)
+		http.Error(w, `{"error":"internal server error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]int{"revoked": revoked})
+}
+
+// withClientInfo returns the request context carrying where the request
+// came from, for the session it starts or refreshes.
+func withClientInfo(r *http.Request) context.Context {
+	return domain.WithClientInfo(r.Context(), domain.ClientInfo{
+		UserAgent: clientinfo.UserAgent(r),
+		IPAddress: clientinfo.IP(r),
+		Location:  clientinfo.Location(r),
+	})
+}
@@ -0,0 +1,153 @@
+package http_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/saransh1220/blueprint-audio/internal/gateway/middleware"
+	"github.com/saransh1220/blueprint-audio/internal/modules/auth/application"
+	"github.com/saransh1220/blueprint-audio/internal/modules/auth/domain"
+	auth_http "github.com/saransh1220/blueprint-audio/internal/modules/auth/interfaces/http"
+	"github.com/saransh1220/blueprint-audio/internal/shared/clientinfo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthHandler_LoginRecordsClient(t *testing.T) {
+	service := new(MockAuthService)
+	h := auth_http.NewAuthHandler(service, new(MockFileService), "client", time.Hour, true)
+	t.Cleanup(func() { service.AssertExpectations(t) })
+
+	expected := domain.ClientInfo{
+		UserAgent: "Mozilla/5.0 (iPhone; CPU iPhone OS 17_4 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.4 Mobile/15E148 Safari/604.1",
+		IPAddress: "198.51.100.4",
+		Location:  "Mumbai, Maharashtra, IN",
+	}
+	service.On("Login", mock.MatchedBy(func(ctx context.Context) bool {
+		return domain.ClientInfoFromContext(ctx) == expected
+	}), application.LoginRequest{Email: "x", Password: "y"}).Return(&application.TokenPair{AccessToken: "token", RefreshToken: "refresh"}, nil).Once()
+
+	req := httptest.NewRequest(http.MethodPost, "/auth/login", bytes.NewBufferString(`{"email":"x","password":"y"}`))
+	req.Header.Set("User-Agent", expected.UserAgent)
+	req.Header.Set("X-Forwarded-For", "198.51.100.4, 10.0.0.1")
+	req.Header.Set("CF-IPCity", "Mumbai")
+	req.Header.Set("CF-Region", "Maharashtra")
+	req.Header.Set("CF-IPCountry", "IN")
+	w := httptest.NewRecorder()
+	h.Login(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestAuthHandler_LoginIgnoresOversizedClientHeaders(t *testing.T) {
+	service := new(MockAuthService)
+	h := auth_http.NewAuthHandler(service, new(MockFileService), "client", time.Hour, true)
+	t.Cleanup(func() { service.AssertExpectations(t) })
+
+	service.On("Login", mock.MatchedBy(func(ctx context.Context) bool {
+		info := domain.ClientInfoFromContext(ctx)
+		return info.IPAddress == "192.0.2.1" && len([]rune(info.Location)) == clientinfo.MaxLocationLength
+	}), application.LoginRequest{Email: "x", Password: "y"}).Return(&application.TokenPair{AccessToken: "token", RefreshToken: "refresh"}, nil).Once()
+
+	req := httptest.NewRequest(http.MethodPost, "/auth/login", bytes.NewBufferString(`{"email":"x","password":"y"}`))
+	req.RemoteAddr = "192.0.2.1:1234"
+	req.Header.Set("X-Forwarded-For", strings.Repeat("f", 100))
+	req.Header.Set("CF-IPCity", strings.Repeat("a", 400))
+	w := httptest.NewRecorder()
+	h.Login(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestAuthHandler_ListSessions(t *testing.T) {
+	service := new(MockAuthService)
+	h := auth_http.NewAuthHandler(service, new(MockFileService), "client", time.Hour, true)
+	t.Cleanup(func() { service.AssertExpectations(t) })
+	userID, current, other := uuid.New(), uuid.New(), uuid.New()
+
+	w := httptest.NewRecorder()
+	h.ListSessions(w, httptest.NewRequest(http.MethodGet, "/auth/sessions", nil))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	ctx := context.WithValue(context.Background(), middleware.ContextKeyUserId, userID)
+	ctx = context.WithValue(ctx, middleware.ContextKeySessionID, current)
+	service.On("ListSessions", mock.Anything, userID).Return([]domain.UserSession{
+		{ID: current, Device: "Chrome on macOS", IPAddress: "203.0.113.7"},
+		{ID: other, Device: "Safari on iOS", Location: "Pune, IN"},
+	}, nil).Once()
+	w = httptest.NewRecorder()
+	h.ListSessions(w, httptest.NewRequest(http.MethodGet, "/auth/sessions", nil).WithContext(ctx))
+	require.Equal(t, http.StatusOK, w.Code)
+	var body struct {
+		Sessions []struct {
+			ID       uuid.UUID `json:"id"`
+			Device   string    `json:"device"`
+			Location string    `json:"location"`
+			Current  bool      `json:"current"`
+		} `json:"sessions"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	require.Len(t, body.Sessions, 2)
+	assert.True(t, body.Sessions[0].Current)
+	assert.Equal(t, "Chrome on macOS", body.Sessions[0].Device)
+	assert.False(t, body.Sessions[1].Current)
+	assert.Equal(t, "Pune, IN", body.Sessions[1].Location)
+
+	service.On("ListSessions", mock.Anything, userID).Return(nil, errors.New("db down")).Once()
+	w = httptest.NewRecorder()
+	h.ListSessions(w, httptest.NewRequest(http.MethodGet, "/auth/sessions", nil).WithContext(ctx))
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestAuthHandler_RevokeSession(t *testing.T) {
+	service := new(MockAuthService)
+	h := auth_http.NewAuthHandler(service, new(MockFileService), "client", time.Hour, true)
+	t.Cleanup(func() { service.AssertExpectations(t) })
+	userID, sessionID := uuid.New(), uuid.New()
+	ctx := context.WithValue(context.Background(), middleware.ContextKeyUserId, userID)
+	revoke := func(id string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodDelete, "/auth/sessions/"+id, nil).WithContext(ctx)
+		req.SetPathValue("id", id)
+		w := httptest.NewRecorder()
+		h.RevokeSession(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusBadRequest, revoke("not-a-uuid").Code)
+
+	service.On("RevokeSession", mock.Anything, userID, sessionID).Return(nil).Once()
+	assert.Equal(t, http.StatusNoContent, revoke(sessionID.String()).Code)
+
+	service.On("RevokeSession", mock.Anything, userID, sessionID).Return(domain.ErrSessionNotFound).Once()
+	assert.Equal(t, http.StatusNotFound, revoke(sessionID.String()).Code)
+
+	service.On("RevokeSession", mock.Anything, userID, sessionID).Return(errors.New("db down")).Once()
+	assert.Equal(t, http.StatusInternalServerError, revoke(sessionID.String()).Code)
+}
+
+func TestAuthHandler_RevokeOtherSessions(t *testing.T) {
+	service := new(MockAuthService)
+	h := auth_http.NewAuthHandler(service, new(MockFileService), "client", time.Hour, true)
+	t.Cleanup(func() { service.AssertExpectations(t) })
+	userID, current := uuid.New(), uuid.New()
+	ctx := context.WithValue(context.Background(), middleware.ContextKeyUserId, userID)
+
+	// Tokens issued before sessions were tracked carry no session ID.
+	w := httptest.NewRecorder()
+	h.RevokeOtherSessions(w, httptest.NewRequest(http.MethodPost, "/auth/sessions/revoke-others", nil).WithContext(ctx))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	ctx = context.WithValue(ctx, middleware.ContextKeySessionID, current)
+	service.On("RevokeOtherSessions", mock.Anything, userID, current).Return(2, nil).Once()
+	w = httptest.NewRecorder()
+	h.RevokeOtherSessions(w, httptest.NewRequest(http.MethodPost, "/auth/sessions/revoke-others", nil).WithContext(ctx))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"revoked":2}`, w.Body.String())
+}
@@ -1,12 +1,16 @@
 package auth
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/redis/go-redis/v9"
 	"github.com/saransh1220/blueprint-audio/internal/modules/auth/application"
 	"github.com/saransh1220/blueprint-audio/internal/modules/auth/domain"
 	"github.com/saransh1220/blueprint-audio/internal/modules/auth/infrastructure/persistence/postgres"
+	"github.com/saransh1220/blueprint-audio/internal/modules/auth/infrastructure/sessioncache"
+	"github.com/saransh1220/blueprint-audio/internal/modules/auth/infrastructure/webauthn"
 	auth_http "github.com/saransh1220/blueprint-audio/internal/modules/auth/interfaces/http"
 	fileApp "github.com/saransh1220/blueprint-audio/internal/modules/filestorage/application"
 	sharedemail "github.com/saransh1220/blueprint-audio/internal/shared/infrastructure/email"
@@ -21,12 +25,26 @@ type Module struct {
 	handler     *auth_http.AuthHandler
 }
 
-// NewModule creates and initializes the Auth module
-func NewModule(db *sqlx.DB, jwtSecret string, jwtExpiry time.Duration, jwtRefreshExpiry time.Duration, fileService *fileApp.FileService, googleClientID string, secureCookie bool, emailSender sharedemail.Sender, appBaseURL string) (*Module, error) {
+// NewModule creates and initializes the Auth module. Whether sessions are
+// active is cached for sessionCacheTTL, in Redis when redisClient is set and
+// in memory otherwise.
+func NewModule(db *sqlx.DB, redisClient *redis.Client, jwtSecret string, jwtExpiry time.Duration, jwtRefreshExpiry time.Duration, sessionCacheTTL time.Duration, fileService *fileApp.FileService, googleClientID string, secureCookie bool, emailSender sharedemail.Sender, appBaseURL string) (*Module, error) {
 	userRepo := postgres.NewUserRepository(db)
 	sessionRepo := postgres.NewSessionRepository(db)
 	tokenRepo := postgres.NewEmailActionTokenRepository(db)
-	service := application.NewAuthService(userRepo, sessionRepo, tokenRepo, emailSender, appBaseURL, jwtSecret, jwtExpiry, jwtRefreshExpiry)
+	mfaRepo := postgres.NewMFARepository(db)
+	service := application.NewAuthService(userRepo, sessionRepo, tokenRepo, mfaRepo, emailSender, appBaseURL, jwtSecret, jwtExpiry, jwtRefreshExpiry)
+	var sessionCache domain.SessionCache = sessioncache.NewMemoryCache()
+	if redisClient != nil {
+		sessionCache = sessioncache.NewRedisCache(redisClient)
+	}
+	service.SetSessionCache(sessionCache, sessionCacheTTL)
+	// Passkeys are bound to the site the app is served from.
+	relyingParty, err := webauthn.NewRelyingParty("Blueprint", appBaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("configure passkeys: %w", err)
+	}
+	service.SetPasskeys(postgres.NewPasskeyRepository(db), relyingParty)
 	handler := auth_http.NewAuthHandler(service, fileService, googleClientID, jwtRefreshExpiry, secureCookie)
 
 	return &Module{
@@ -32,7 +32,7 @@ var _ domain.FileStorage = noopStorage{}
 
 func TestNewModuleAndAccessors(t *testing.T) {
 	fs := fileapp.NewFileService(noopStorage{})
-	m, err := NewModule(&sqlx.DB{}, "secret", time.Hour, time.Hour*720, fs, "test-client-id", false, sharedemail.NewSender(sharedemail.Config{}), "http://localhost:4200")
+	m, err := NewModule(&sqlx.DB{}, nil, "secret", time.Hour, time.Hour*720, 5*time.Second, fs, "test-client-id", false, sharedemail.NewSender(sharedemail.Config{}), "http://localhost:4200")
 	require.NoError(t, err)
 	require.NotNil(t, m)
 	require.NotNil(t, m.Service())
@@ -41,3 +41,9 @@ func TestNewModuleAndAccessors(t *testing.T) {
 	require.NotNil(t, m.HTTPHandler())
 	_ = uuid.New()
 }
+
+func TestNewModuleRejectsInvalidAppBaseURL(t *testing.T) {
+	fs := fileapp.NewFileService(noopStorage{})
+	_, err := NewModule(&sqlx.DB{}, nil, "secret", time.Hour, time.Hour*720, 5*time.Second, fs, "test-client-id", false, sharedemail.NewSender(sharedemail.Config{}), "localhost")
+	require.Error(t, err)
+}
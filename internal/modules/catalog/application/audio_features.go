@@ -0,0 +1,378 @@
+package application
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// TempoEstimate is a BPM suggestion derived from the onset envelope.
+type TempoEstimate struct {
+	BPM        int
+	Confidence float64
+}
+
+// KeyEstimate is a musical key suggestion in the marketplace "C# MINOR" form.
+type KeyEstimate struct {
+	Key        string
+	Confidence float64
+}
+
+const (
+	featureTargetRate = 11_025
+
+	onsetFrameSize  = 512
+	onsetHopSize    = 128
+	chromaFrameSize = 4096
+	chromaHopSize   = 2048
+
+	minChromaFrequency = 65.0
+	maxChromaFrequency = 2100.0
+
+	minTempoBPM             = 60.0
+	maxTempoBPM             = 200.0
+	tempoPriorBPM           = 120.0
+	minTempoAnalysisSeconds = 6.0
+
+	// keySoftmaxTemperature converts profile correlations into a probability
+	// spread. Correlations between neighbouring keys differ by a few hundredths,
+	// so a small temperature keeps a clear winner near 1 and ambiguous material
+	// near 1/24.
+	keySoftmaxTemperature = 0.05
+)
+
+var pitchClassNames = [12]string{"C", "C#", "D", "D#", "E", "F", "F#", "G", "G#", "A", "A#", "B"}
+
+// Krumhansl-Kessler key profiles, indexed from the tonic.
+var (
+	majorKeyProfile = [12]float64{6.35, 2.23, 3.48, 2.33, 4.38, 4.09, 2.52, 5.19, 2.39, 3.66, 2.29, 2.88}
+	minorKeyProfile = [12]float64{6.33, 2.68, 3.52, 5.38, 2.60, 3.53, 2.54, 4.75, 3.98, 2.69, 3.34, 3.17}
+)
+
+// audioFeatureExtractor consumes mono PCM one sample at a time and keeps only
+// bounded analysis state: a short sliding window, the onset envelope and a
+// twelve-bin chroma sum. It shares AnalyzeMP3's single decode pass so tempo and
+// key detection never require a second read of the preview.
+type audioFeatureExtractor struct {
+	decimation int
+	rate       float64
+
+	accumulator float64
+	accumulated int
+
+	window      []float64 // ring buffer of the last chromaFrameSize samples
+	written     int64
+	sinceOnset  int
+	sinceChroma int
+
+	onsetWindow  []float64
+	chromaWindow []float64
+	onsetBuffer  []complex128
+	chromaBuffer []complex128
+
+	previousLogMagnitude []float64
+	hasPreviousSpectrum  bool
+	onsetEnvelope        []float32
+
+	chromaBins   []int // FFT bin -> MIDI note, or -1 outside the chroma range
+	noteLevels   []float64
+	chroma       [12]float64
+	chromaFrames int
+}
+
+func newAudioFeatureExtractor(sampleRate int) *audioFeatureExtractor {
+	decimation := sampleRate / featureTargetRate
+	if decimation < 1 {
+		decimation = 1
+	}
+	rate := float64(sampleRate) / float64(decimation)
+
+	chromaBins := make([]int, chromaFrameSize/2)
+	for bin := range chromaBins {
+		chromaBins[bin] = -1
+		frequency := float64(bin) * rate / chromaFrameSize
+		if frequency < minChromaFrequency || frequency > maxChromaFrequency {
+			continue
+		}
+		chromaBins[bin] = int(math.Round(12*math.Log2(frequency/440) + 69))
+	}
+
+	return &audioFeatureExtractor{
+		decimation:           decimation,
+		rate:                 rate,
+		window:               make([]float64, chromaFrameSize),
+		onsetWindow:          hannWindow(onsetFrameSize),
+		chromaWindow:         hannWindow(chromaFrameSize),
+		onsetBuffer:          make([]complex128, onsetFrameSize),
+		chromaBuffer:         make([]complex128, chromaFrameSize),
+		previousLogMagnitude: make([]float64, onsetFrameSize/2),
+		onsetEnvelope:        make([]float32, 0, 4096),
+		chromaBins:           chromaBins,
+		noteLevels:           make([]float64, 128),
+	}
+}
+
+// Add accepts one mono sample normalized to [-1, 1] at the decoder's rate.
+func (e *audioFeatureExtractor) Add(sample float64) {
+	e.accumulator += sample
+	e.accumulated++
+	if e.accumulated < e.decimation {
+		return
+	}
+	// A boxcar average is a crude low-pass filter, but the features below only
+	// look at content under ~2 kHz and transient energy, both of which survive.
+	value := e.accumulator / float64(e.accumulated)
+	e.accumulator = 0
+	e.accumulated = 0
+
+	e.window[e.written%chromaFrameSize] = value
+	e.written++
+	e.sinceOnset++
+	e.sinceChroma++
+
+	if e.written >= onsetFrameSize && e.sinceOnset >= onsetHopSize {
+		e.sinceOnset = 0
+		e.onsetFrame()
+	}
+	if e.written >= chromaFrameSize && e.sinceChroma >= chromaHopSize {
+		e.sinceChroma = 0
+		e.chromaFrame()
+	}
+}
+
+func (e *audioFeatureExtractor) fillFrame(buffer []complex128, taper []float64) {
+	size := int64(len(buffer))
+	start := e.written - size
+	for i := int64(0); i < size; i++ {
+		buffer[i] = complex(e.window[(start+i)%chromaFrameSize]*taper[i], 0)
+	}
+	fft(buffer)
+}
+
+// onsetFrame appends half-wave rectified log-spectral flux to the envelope.
+func (e *audioFeatureExtractor) onsetFrame() {
+	e.fillFrame(e.onsetBuffer, e.onsetWindow)
+	flux := 0.0
+	for bin := range e.previousLogMagnitude {
+		magnitude := math.Log1p(100 * cmplx.Abs(e.onsetBuffer[bin]))
+		if e.hasPreviousSpectrum && magnitude > e.previousLogMagnitude[bin] {
+			flux += magnitude - e.previousLogMagnitude[bin]
+		}
+		e.previousLogMagnitude[bin] = magnitude
+	}
+	if e.hasPreviousSpectrum {
+		e.onsetEnvelope = append(e.onsetEnvelope, float32(flux))
+	}
+	e.hasPreviousSpectrum = true
+}
+
+// chromaFrame folds the frame's spectrum into the running chroma sum. Each
+// semitone contributes its loudest bin rather than the sum of its bins: higher
+// octaves span more FFT bins, and summing would let broadband drum energy
+// favour whichever pitch classes happen to own the most bins.
+func (e *audioFeatureExtractor) chromaFrame() {
+	e.fillFrame(e.chromaBuffer, e.chromaWindow)
+	clear(e.noteLevels)
+	for bin, note := range e.chromaBins {
+		if note < 0 {
+			continue
+		}
+		e.noteLevels[note] = max(e.noteLevels[note], cmplx.Abs(e.chromaBuffer[bin]))
+	}
+	for note, level := range e.noteLevels {
+		if level > 0 {
+			e.chroma[note%12] += math.Log1p(level)
+		}
+	}
+	e.chromaFrames++
+}
+
+// Tempo picks the strongest onset-envelope autocorrelation lag between 60 and
+// 200 BPM, weighted towards 120 BPM to settle half/double-time ambiguity the
+// way listeners usually would. It returns nil for previews that are too short
+// or have no rhythmic content.
+func (e *audioFeatureExtractor) Tempo() *TempoEstimate {
+	framesPerSecond := e.rate / onsetHopSize
+	if float64(len(e.onsetEnvelope)) < minTempoAnalysisSeconds*framesPerSecond {
+		return nil
+	}
+
+	mean := 0.0
+	for _, value := range e.onsetEnvelope {
+		mean += float64(value)
+	}
+	mean /= float64(len(e.onsetEnvelope))
+	envelope := make([]float64, len(e.onsetEnvelope))
+	for i, value := range e.onsetEnvelope {
+		envelope[i] = float64(value) - mean
+	}
+
+	minLag := int(math.Floor(60 * framesPerSecond / maxTempoBPM))
+	maxLag := int(math.Ceil(60 * framesPerSecond / minTempoBPM))
+	if minLag < 2 || maxLag+1 >= len(envelope) {
+		return nil
+	}
+	autocorrelation := make([]float64, maxLag+2)
+	for lag := range autocorrelation {
+		if lag != 0 && lag < minLag-1 {
+			continue
+		}
+		sum := 0.0
+		for i := lag; i < len(envelope); i++ {
+			sum += envelope[i] * envelope[i-lag]
+		}
+		autocorrelation[lag] = sum / float64(len(envelope)-lag)
+	}
+	if autocorrelation[0] <= 0 {
+		return nil
+	}
+
+	bestLag := -1
+	bestScore := 0.0
+	for lag := minLag; lag <= maxLag; lag++ {
+		value := autocorrelation[lag]
+		if value <= 0 || value < autocorrelation[lag-1] || value < autocorrelation[lag+1] {
+			continue
+		}
+		bpm := 60 * framesPerSecond / float64(lag)
+		octaves := math.Log2(bpm / tempoPriorBPM)
+		score := value * math.Exp(-0.5*octaves*octaves)
+		if score > bestScore {
+			bestScore = score
+			bestLag = lag
+		}
+	}
+	if bestLag < 0 {
+		return nil
+	}
+
+	// Parabolic interpolation recovers sub-frame lag precision; at ~86 onset
+	// frames per second a whole-frame lag is several BPM wide near 150 BPM.
+	refinedLag := float64(bestLag)
+	previous, current, next := autocorrelation[bestLag-1], autocorrelation[bestLag], autocorrelation[bestLag+1]
+	if denominator := previous - 2*current + next; denominator < 0 {
+		refinedLag += 0.5 * (previous - next) / denominator
+	}
+	bpm := int(math.Round(60 * framesPerSecond / refinedLag))
+	if bpm < int(minTempoBPM) || bpm > int(maxTempoBPM) {
+		return nil
+	}
+	return &TempoEstimate{
+		BPM:        bpm,
+		Confidence: roundConfidence(current / autocorrelation[0]),
+	}
+}
+
+// Key correlates the accumulated chroma vector with all 24 rotated
+// Krumhansl-Kessler profiles and returns the best match. Confidence is the
+// softmax probability of the winner, so relative minor/major confusion shows
+// up as a low score rather than a confident wrong answer.
+func (e *audioFeatureExtractor) Key() *KeyEstimate {
+	if e.chromaFrames == 0 {
+		return nil
+	}
+	total := 0.0
+	for _, value := range e.chroma {
+		total += value
+	}
+	if total <= 0 {
+		return nil
+	}
+
+	var correlations [24]float64
+	for tonic := 0; tonic < 12; tonic++ {
+		correlations[tonic] = rotatedProfileCorrelation(e.chroma, majorKeyProfile, tonic)
+		correlations[12+tonic] = rotatedProfileCorrelation(e.chroma, minorKeyProfile, tonic)
+	}
+	best := 0
+	for i, value := range correlations {
+		if value > correlations[best] {
+			best = i
+		}
+	}
+	if math.IsNaN(correlations[best]) || correlations[best] <= 0 {
+		return nil
+	}
+	denominator := 0.0
+	for _, value := range correlations {
+		denominator += math.Exp((value - correlations[best]) / keySoftmaxTemperature)
+	}
+
+	mode := "MAJOR"
+	if best >= 12 {
+		mode = "MINOR"
+	}
+	return &KeyEstimate{
+		Key:        pitchClassNames[best%12] + " " + mode,
+		Confidence: roundConfidence(1 / denominator),
+	}
+}
+
+func rotatedProfileCorrelation(chroma, profile [12]float64, tonic int) float64 {
+	var chromaMean, profileMean float64
+	for i := 0; i < 12; i++ {
+		chromaMean += chroma[i]
+		profileMean += profile[i]
+	}
+	chromaMean /= 12
+	profileMean /= 12
+
+	var covariance, chromaVariance, profileVariance float64
+	for pitchClass := 0; pitchClass < 12; pitchClass++ {
+		x := chroma[pitchClass] - chromaMean
+		y := profile[(pitchClass-tonic+12)%12] - profileMean
+		covariance += x * y
+		chromaVariance += x * x
+		profileVariance += y * y
+	}
+	if chromaVariance == 0 || profileVariance == 0 {
+		return 0
+	}
+	return covariance / math.Sqrt(chromaVariance*profileVariance)
+}
+
+func roundConfidence(value float64) float64 {
+	if math.IsNaN(value) || value < 0 {
+		return 0
+	}
+	if value > 1 {
+		return 1
+	}
+	return math.Round(value*1000) / 1000
+}
+
+func hannWindow(size int) []float64 {
+	window := make([]float64, size)
+	for i := range window {
+		window[i] = 0.5 - 0.5*math.Cos(2*math.Pi*float64(i)/float64(size-1))
+	}
+	return window
+}
+
+// fft is an in-place iterative radix-2 Cooley-Tukey transform. len(values)
+// must be a power of two, which every frame size above is.
+func fft(values []complex128) {
+	n := len(values)
+	for i, j := 1, 0; i < n; i++ {
+		bit := n >> 1
+		for ; j&bit != 0; bit >>= 1 {
+			j ^= bit
+		}
+		j ^= bit
+		if i < j {
+			values[i], values[j] = values[j], values[i]
+		}
+	}
+	for size := 2; size <= n; size <<= 1 {
+		step := cmplx.Exp(complex(0, -2*math.Pi/float64(size)))
+		for start := 0; start < n; start += size {
+			twiddle := complex(1, 0)
+			for k := 0; k < size/2; k++ {
+				even := values[start+k]
+				odd := values[start+k+size/2] * twiddle
+				values[start+k] = even + odd
+				values[start+k+size/2] = even - odd
+				twiddle *= step
+			}
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package application
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func synthesizeBeat(sampleRate int, seconds, bpm float64, frequencies []float64) []float64 {
+	total := int(seconds * float64(sampleRate))
+	beatSamples := 60 / bpm * float64(sampleRate)
+	samples := make([]float64, total)
+	noise := uint32(1)
+	for i := range samples {
+		t := float64(i) / float64(sampleRate)
+		tone := 0.0
+		for _, frequency := range frequencies {
+			tone += math.Sin(2*math.Pi*frequency*t) / float64(len(frequencies))
+		}
+		sinceBeat := math.Mod(float64(i), beatSamples) / float64(sampleRate)
+		// A decaying noise burst stands in for a drum hit without adding a
+		// pitched partial that would skew the chroma profile.
+		noise = noise*1_664_525 + 1_013_904_223
+		click := math.Exp(-sinceBeat*40) * (float64(noise)/math.MaxUint32*2 - 1)
+		samples[i] = 0.3*tone + 0.6*click
+	}
+	return samples
+}
+
+func extractFeatures(sampleRate int, samples []float64) *audioFeatureExtractor {
+	extractor := newAudioFeatureExtractor(sampleRate)
+	for _, sample := range samples {
+		extractor.Add(sample)
+	}
+	return extractor
+}
+
+func TestAudioFeatureExtractorEstimatesTempo(t *testing.T) {
+	for _, bpm := range []float64{90, 120, 140} {
+		extractor := extractFeatures(44_100, synthesizeBeat(44_100, 20, bpm, []float64{220}))
+		tempo := extractor.Tempo()
+		require.NotNil(t, tempo, "bpm %v", bpm)
+		assert.InDelta(t, bpm, tempo.BPM, 1, "bpm %v", bpm)
+		assert.Greater(t, tempo.Confidence, 0.2)
+		assert.LessOrEqual(t, tempo.Confidence, 1.0)
+	}
+}
+
+func TestAudioFeatureExtractorEstimatesKey(t *testing.T) {
+	tests := []struct {
+		name        string
+		frequencies []float64
+		want        string
+	}{
+		{name: "C major triad", frequencies: []float64{261.63, 329.63, 392.00}, want: "C MAJOR"},
+		{name: "A minor triad", frequencies: []float64{220.00, 261.63, 329.63}, want: "A MINOR"},
+		{name: "F# minor triad", frequencies: []float64{185.00, 220.00, 277.18}, want: "F# MINOR"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			extractor := extractFeatures(48_000, synthesizeBeat(48_000, 8, 120, test.frequencies))
+			key := extractor.Key()
+			require.NotNil(t, key)
+			assert.Equal(t, test.want, key.Key)
+			assert.Greater(t, key.Confidence, 0.0)
+		})
+	}
+}
+
+func TestAudioFeatureExtractorSkipsUnusableAudio(t *testing.T) {
+	silence := extractFeatures(44_100, make([]float64, 44_100*10))
+	assert.Nil(t, silence.Tempo())
+	assert.Nil(t, silence.Key())
+
+	short := extractFeatures(44_100, synthesizeBeat(44_100, 2, 120, []float64{220}))
+	assert.Nil(t, short.Tempo())
+}
+
+func TestFFTMatchesSingleTone(t *testing.T) {
+	values := make([]complex128, 64)
+	for i := range values {
+		values[i] = complex(math.Cos(2*math.Pi*4*float64(i)/64), 0)
+	}
+	fft(values)
+	for bin, value := range values {
+		magnitude := math.Hypot(real(value), imag(value))
+		if bin == 4 || bin == 60 {
+			assert.InDelta(t, 32, magnitude, 1e-9)
+			continue
+		}
+		assert.InDelta(t, 0, magnitude, 1e-9)
+	}
+}
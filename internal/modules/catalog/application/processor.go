@@ -29,9 +29,34 @@ type UploadNotifier interface {
 	Create(
 		ctx context.Context,
 		userID uuid.UUID,
+		category notificationDomain.Category,
 		title, message string,
 		notificationType notificationDomain.NotificationType,
 	) error
+	// Publish pushes a realtime event to the user's open connections.
+	Publish(ctx context.Context, userID uuid.UUID, eventType notificationDomain.EventType, payload any) error
+}
+
+// Upload processing stages reported in UploadProgress.
+const (
+	UploadStageStarted        = "started"
+	UploadStageAssetsVerified = "assets_verified"
+	UploadStageCoverProcessed = "cover_processed"
+	UploadStageAudioAnalyzed  = "audio_analyzed"
+	UploadStageFilesValidated = "files_validated"
+	UploadStageCompleted      = "completed"
+	UploadStageFailed         = "failed"
+)
+
+// UploadProgress is the payload of upload.progress events, sent to the
+// producer as the worker processes an upload.
+type UploadProgress struct {
+	UploadID uuid.UUID               `json:"upload_id"`
+	SpecID   uuid.UUID               `json:"spec_id"`
+	Status   domain.ProcessingStatus `json:"status"`
+	Stage    string                  `json:"stage"`
+	Percent  int                     `json:"percent"`
+	Error    *string                 `json:"error,omitempty"`
 }
 
 type SpecUploadProcessor struct {
@@ -56,6 +81,61 @@ func (p *SpecUploadProcessor) ExpireUploads(ctx context.Context) (int64, error)
 	return p.uploads.ExpireUploadSessions(ctx, time.Now().UTC())
 }
 
+// OrphanRetention is how long the objects of an expired or failed upload are
+// kept before PurgeOrphanedObjects deletes them.
+const OrphanRetention = 24 * time.Hour
+
+const purgeBatchSize = 100
+
+// PurgeOrphanedObjects deletes the staging and final objects of uploads that
+// expired or failed more than OrphanRetention ago and returns how many
+// sessions were purged. Final objects are kept while the session's spec still
+// points at processed files. A session whose objects could not all be deleted
+// is left for the next run.
+func (p *SpecUploadProcessor) PurgeOrphanedObjects(ctx context.Context) (int, error) {
+	purged := 0
+	for {
+		sessions, err := p.uploads.ListUnpurgedSessions(ctx, time.Now().UTC().Add(-OrphanRetention), purgeBatchSize)
+		if err != nil {
+			return purged, fmt.Errorf("list unpurged upload sessions: %w", err)
+		}
+		var failed []error
+		for _, session := range sessions {
+			if err := p.purgeSession(ctx, session); err != nil {
+				failed = append(failed, fmt.Errorf("upload %s: %w", session.ID, err))
+				continue
+			}
+			purged++
+		}
+		// Failed sessions would be listed again, so stop rather than retry
+		// them in the same run.
+		if len(sessions) < purgeBatchSize || len(failed) > 0 {
+			return purged, errors.Join(failed...)
+		}
+	}
+}
+
+func (p *SpecUploadProcessor) purgeSession(ctx context.Context, session domain.SpecUploadSession) error {
+	inUse, err := p.uploads.SpecHasProcessedFiles(ctx, session.SpecID)
+	if err != nil {
+		return fmt.Errorf("check spec %s: %w", session.SpecID, err)
+	}
+	for _, asset := range session.Assets {
+		keys := []string{asset.ObjectKey}
+		if !inUse {
+			keys = append(keys, asset.FinalObjectKey)
+		}
+		// Deleting a missing object succeeds, so keys that were already
+		// cleaned up are harmless.
+		for _, key := range keys {
+			if err := p.objects.Delete(ctx, key); err != nil {
+				return fmt.Errorf("delete %s: %w", key, err)
+			}
+		}
+	}
+	return p.uploads.MarkObjectsPurged(ctx, session.ID)
+}
+
 // ProcessNext claims and processes at most one job. A processing failure is
 // persisted as a failed job and does not stop the worker loop. The heartbeat
 // keeps long audio/archive operations from being reclaimed by another worker.
@@ -72,6 +152,8 @@ func (p *SpecUploadProcessor) ProcessNext(
 		return false, err
 	}
 
+	p.progress(ctx, bundle, UploadProgress{Status: domain.ProcessingStatusProcessing, Stage: UploadStageStarted})
+
 	processCtx, stopProcessing := context.WithCancel(ctx)
 	heartbeatDone := make(chan error, 1)
 	go p.heartbeat(processCtx, stopProcessing, heartbeatDone, bundle.Job.ID, workerID, heartbeatInterval)
@@ -98,9 +180,17 @@ func (p *SpecUploadProcessor) ProcessNext(
 		// Cleanup is safe only after the fenced state transition proves that
 		// this worker still owns the job.
 		p.cleanup(context.Background(), cleanupKeys)
+		reason := err.Error()
+		p.progress(context.Background(), bundle, UploadProgress{
+			Status:  domain.ProcessingStatusFailed,
+			Stage:   UploadStageFailed,
+			Percent: 100,
+			Error:   &reason,
+		})
 		p.notify(
 			context.Background(),
 			bundle.Spec.ProducerID,
+			notificationDomain.CategoryUploadFailed,
 			"Upload Failed",
 			fmt.Sprintf("Processing for '%s' failed. Please try again.", bundle.Spec.Title),
 			notificationDomain.NotificationTypeError,
@@ -121,9 +211,15 @@ func (p *SpecUploadProcessor) ProcessNext(
 			_ = p.objects.Delete(context.Background(), asset.FinalObjectKey)
 		}
 	}
+	p.progress(context.Background(), bundle, UploadProgress{
+		Status:  domain.ProcessingStatusCompleted,
+		Stage:   UploadStageCompleted,
+		Percent: 100,
+	})
 	p.notify(
 		context.Background(),
 		bundle.Spec.ProducerID,
+		notificationDomain.CategoryUploadProcessed,
 		"Upload Complete",
 		fmt.Sprintf("'%s' is now live!", bundle.Spec.Title),
 		notificationDomain.NotificationTypeSuccess,
@@ -206,6 +302,8 @@ func (p *SpecUploadProcessor) process(
 		assets[asset.Kind] = asset
 	}
 
+	p.progress(ctx, bundle, UploadProgress{Status: domain.ProcessingStatusProcessing, Stage: UploadStageAssetsVerified, Percent: 30})
+
 	imageAsset, ok := assets[domain.UploadAssetImage]
 	if !ok {
 		return domain.ProcessedSpecFiles{}, cleanupKeys, errors.New("cover image is missing")
@@ -228,6 +326,8 @@ func (p *SpecUploadProcessor) process(
 		return domain.ProcessedSpecFiles{}, cleanupKeys, fmt.Errorf("upload processed cover: %w", err)
 	}
 
+	p.progress(ctx, bundle, UploadProgress{Status: domain.ProcessingStatusProcessing, Stage: UploadStageCoverProcessed, Percent: 45})
+
 	previewReader, err := p.objects.OpenObject(ctx, previewAsset.FinalObjectKey)
 	if err != nil {
 		return domain.ProcessedSpecFiles{}, cleanupKeys, fmt.Errorf("open preview: %w", err)
@@ -240,6 +340,7 @@ func (p *SpecUploadProcessor) process(
 	if closeErr != nil {
 		return domain.ProcessedSpecFiles{}, cleanupKeys, fmt.Errorf("close preview: %w", closeErr)
 	}
+	p.progress(ctx, bundle, UploadProgress{Status: domain.ProcessingStatusProcessing, Stage: UploadStageAudioAnalyzed, Percent: 70})
 
 	var wavURL *string
 	var stemsURL *string
@@ -273,6 +374,8 @@ func (p *SpecUploadProcessor) process(
 		stemsURL = &url
 	}
 
+	p.progress(ctx, bundle, UploadProgress{Status: domain.ProcessingStatusProcessing, Stage: UploadStageFilesValidated, Percent: 90})
+
 	imageURL, err := p.objects.ObjectURL(imageKey)
 	if err != nil {
 		return domain.ProcessedSpecFiles{}, cleanupKeys, err
@@ -288,9 +391,27 @@ func (p *SpecUploadProcessor) process(
 		StemsURL:      stemsURL,
 		Duration:      analysis.Duration,
 		WaveformPeaks: pq.Int64Array(analysis.WaveformPeaks),
+		Suggestions:   audioSuggestions(analysis),
 	}, cleanupKeys, nil
 }
 
+func audioSuggestions(analysis MP3Analysis) domain.AudioSuggestions {
+	var suggestions domain.AudioSuggestions
+	if analysis.Tempo != nil {
+		bpm := analysis.Tempo.BPM
+		confidence := analysis.Tempo.Confidence
+		suggestions.BPM = &bpm
+		suggestions.BPMConfidence = &confidence
+	}
+	if analysis.Key != nil {
+		key := analysis.Key.Key
+		confidence := analysis.Key.Confidence
+		suggestions.Key = &key
+		suggestions.KeyConfidence = &confidence
+	}
+	return suggestions
+}
+
 func normalizeCoverImage(imageBytes []byte) ([]byte, error) {
 	config, format, err := image.DecodeConfig(bytes.NewReader(imageBytes))
 	if err != nil || (format != "jpeg" && format != "png") {
@@ -507,10 +628,22 @@ func (p *SpecUploadProcessor) cleanup(ctx context.Context, keys []string) {
 func (p *SpecUploadProcessor) notify(
 	ctx context.Context,
 	userID uuid.UUID,
+	category notificationDomain.Category,
 	title, message string,
 	notificationType notificationDomain.NotificationType,
 ) {
 	if p.notifier != nil {
-		_ = p.notifier.Create(ctx, userID, title, message, notificationType)
+		_ = p.notifier.Create(ctx, userID, category, title, message, notificationType)
+	}
+}
+
+// progress reports a processing step to the producer. Progress is best
+// effort; the upload status endpoint stays authoritative.
+func (p *SpecUploadProcessor) progress(ctx context.Context, bundle *domain.ProcessingBundle, update UploadProgress) {
+	if p.notifier == nil {
+		return
 	}
+	update.UploadID = bundle.Session.ID
+	update.SpecID = bundle.Spec.ID
+	_ = p.notifier.Publish(ctx, bundle.Spec.ProducerID, notificationDomain.EventUploadProgress, update)
 }
@@ -18,6 +18,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/saransh1220/blueprint-audio/internal/modules/catalog/domain"
 	filestorageDomain "github.com/saransh1220/blueprint-audio/internal/modules/filestorage/domain"
+	notificationDomain "github.com/saransh1220/blueprint-audio/internal/modules/notification/domain"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -142,6 +143,67 @@ func TestSpecUploadProcessor_ProcessFailureCleansOnlyAfterFencedFailure(t *testi
 	})
 }
 
+type uploadNotifierStub struct {
+	mu       sync.Mutex
+	created  []string
+	progress []UploadProgress
+}
+
+func (n *uploadNotifierStub) Create(_ context.Context, _ uuid.UUID, _ notificationDomain.Category, title, _ string, _ notificationDomain.NotificationType) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.created = append(n.created, title)
+	return nil
+}
+
+func (n *uploadNotifierStub) Publish(_ context.Context, _ uuid.UUID, eventType notificationDomain.EventType, payload any) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if eventType == notificationDomain.EventUploadProgress {
+		n.progress = append(n.progress, payload.(UploadProgress))
+	}
+	return nil
+}
+
+func TestSpecUploadProcessor_ReportsProgress(t *testing.T) {
+	t.Parallel()
+
+	bundle := failingProcessingBundle()
+	bundle.Session.ID = uuid.New()
+	uploads := &uploadRepositoryStub{
+		claimNextJobFn: func(context.Context, string) (*domain.ProcessingBundle, error) {
+			return bundle, nil
+		},
+		failJobFn: func(context.Context, uuid.UUID, string, string) error { return nil },
+	}
+	objects := &objectStoreStub{
+		statObjectFn: func(context.Context, string) (filestorageDomain.ObjectInfo, error) {
+			return filestorageDomain.ObjectInfo{}, errors.New("source unavailable")
+		},
+		deleteFn: func(context.Context, string) error { return nil },
+	}
+	notifier := &uploadNotifierStub{}
+
+	processed, err := NewSpecUploadProcessor(uploads, objects, notifier).
+		ProcessNext(context.Background(), "worker-d", time.Hour)
+	require.NoError(t, err)
+	assert.True(t, processed)
+
+	require.Len(t, notifier.progress, 2)
+	assert.Equal(t, UploadProgress{
+		UploadID: bundle.Session.ID,
+		SpecID:   bundle.Spec.ID,
+		Status:   domain.ProcessingStatusProcessing,
+		Stage:    UploadStageStarted,
+	}, notifier.progress[0])
+	failed := notifier.progress[1]
+	assert.Equal(t, domain.ProcessingStatusFailed, failed.Status)
+	assert.Equal(t, 100, failed.Percent)
+	require.NotNil(t, failed.Error)
+	assert.Contains(t, *failed.Error, "source unavailable")
+	assert.Equal(t, []string{"Upload Failed"}, notifier.created)
+}
+
 func TestSpecUploadProcessor_HeartbeatLossDoesNotMutateOrCleanup(t *testing.T) {
 	t.Parallel()
 
@@ -246,6 +308,51 @@ func TestSpecUploadProcessor_ValidateWAVRejectsMagicOnlyFile(t *testing.T) {
 	}
 }
 
+func TestSpecUploadProcessor_PurgeOrphanedObjects(t *testing.T) {
+	t.Parallel()
+
+	purgedID, brokenID, liveID := uuid.New(), uuid.New(), uuid.New()
+	liveSpecID := uuid.New()
+	var cutoff time.Time
+	var purged []uuid.UUID
+	uploads := &uploadRepositoryStub{
+		listUnpurgedFn: func(_ context.Context, finishedBefore time.Time, limit int) ([]domain.SpecUploadSession, error) {
+			cutoff = finishedBefore
+			assert.Equal(t, purgeBatchSize, limit)
+			return []domain.SpecUploadSession{
+				{ID: purgedID, Assets: []domain.SpecUploadAsset{{ObjectKey: "staging/ok", FinalObjectKey: "final/ok"}}},
+				{ID: liveID, SpecID: liveSpecID, Assets: []domain.SpecUploadAsset{{ObjectKey: "staging/live", FinalObjectKey: "final/live"}}},
+				{ID: brokenID, Assets: []domain.SpecUploadAsset{{ObjectKey: "staging/broken", FinalObjectKey: "final/broken"}}},
+			}, nil
+		},
+		markPurgedFn: func(_ context.Context, sessionID uuid.UUID) error {
+			purged = append(purged, sessionID)
+			return nil
+		},
+		specFilesFn: func(_ context.Context, specID uuid.UUID) (bool, error) {
+			return specID == liveSpecID, nil
+		},
+	}
+	var deleted []string
+	objects := &objectStoreStub{
+		deleteFn: func(_ context.Context, key string) error {
+			if key == "final/broken" {
+				return errors.New("storage unavailable")
+			}
+			deleted = append(deleted, key)
+			return nil
+		},
+	}
+
+	count, err := NewSpecUploadProcessor(uploads, objects, nil).PurgeOrphanedObjects(context.Background())
+	require.ErrorContains(t, err, "storage unavailable")
+	assert.Equal(t, 2, count)
+	assert.Equal(t, []uuid.UUID{purgedID, liveID}, purged, "a session with undeleted objects must be retried")
+	assert.Equal(t, []string{"staging/ok", "final/ok", "staging/live", "staging/broken"}, deleted,
+		"final objects a spec still points at must be kept")
+	assert.WithinDuration(t, time.Now().Add(-OrphanRetention), cutoff, time.Minute)
+}
+
 func failingProcessingBundle() *domain.ProcessingBundle {
 	specID := uuid.New()
 	size := int64(1024)
@@ -0,0 +1,292 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/saransh1220/blueprint-audio/internal/modules/catalog/domain"
+)
+
+const (
+	defaultRankingPreviewLimit = 20
+	maxRankingPreviewLimit     = 100
+
+	maxRankingWeight          = 1000
+	maxRecencyHalfLifeHours   = 24 * 365
+	maxRankingMaxPerProducer  = 100
+	maxRankingProfileNameSize = 100
+)
+
+// rankingPeriods are recalculated together, on schedule and whenever a section
+// changes formula, so no period keeps serving rankings from the previous one.
+var rankingPeriods = []string{domain.HomePeriod24H, domain.HomePeriod7D, domain.HomePeriod30D}
+
+type RankingProfileService interface {
+	ListProfiles(ctx context.Context, section string) ([]domain.RankingProfile, error)
+	GetProfile(ctx context.Context, id uuid.UUID) (*domain.RankingProfile, error)
+	CreateProfile(ctx context.Context, input CreateRankingProfileInput) (*domain.RankingProfile, error)
+	PreviewProfile(ctx context.Context, id uuid.UUID, period string, limit int) (*RankingPreview, error)
+	ActivateProfile(ctx context.Context, id, actorID uuid.UUID) (*domain.RankingProfile, error)
+	DeactivateProfile(ctx context.Context, id uuid.UUID) (*domain.RankingProfile, error)
+	// RefreshSection recalculates the stored rankings of a section for every
+	// period with its current formula.
+	RefreshSection(ctx context.Context, section string) error
+}
+
+// CreateRankingProfileInput describes a new, inactive ranking profile.
+type CreateRankingProfileInput struct {
+	Name                 string                `json:"name"`
+	Section              string                `json:"section"`
+	Weights              domain.RankingWeights `json:"weights"`
+	RecencyHalfLifeHours float64               `json:"recency_half_life_hours"`
+	MaxPerProducer       int                   `json:"max_per_producer"`
+	ActorID              uuid.UUID             `json:"-"`
+}
+
+// RankingPreview is a dry run of a profile against the stored rankings of its
+// section. Items cover the top Limit of both rankings.
+type RankingPreview struct {
+	Profile     domain.RankingProfile `json:"profile"`
+	Period      string                `json:"period"`
+	Limit       int                   `json:"limit"`
+	Summary     RankingPreviewSummary `json:"summary"`
+	Items       []RankingPreviewItem  `json:"items"`
+	GeneratedAt time.Time             `json:"generated_at"`
+}
+
+type RankingPreviewSummary struct {
+	Entered   int `json:"entered"`
+	Dropped   int `json:"dropped"`
+	MovedUp   int `json:"moved_up"`
+	MovedDown int `json:"moved_down"`
+	Unchanged int `json:"unchanged"`
+}
+
+// RankingPreviewItem is one beat of the diff. Change is "new" when the beat
+// enters the top Limit, "dropped" when it leaves it, otherwise "up", "down"
+// or "-". RankDelta is positive when the beat climbs.
+type RankingPreviewItem struct {
+	SpecID        uuid.UUID                  `json:"spec_id"`
+	Title         string                     `json:"title"`
+	ProducerID    uuid.UUID                  `json:"producer_id"`
+	ProposedRank  *int                       `json:"proposed_rank"`
+	ProposedScore *float64                   `json:"proposed_score"`
+	CurrentRank   *int                       `json:"current_rank"`
+	CurrentScore  *float64                   `json:"current_score"`
+	Change        string                     `json:"change"`
+	RankDelta     *int                       `json:"rank_delta,omitempty"`
+	Metrics       *domain.BeatRankingMetrics `json:"metrics,omitempty"`
+}
+
+type rankingProfileService struct {
+	repo domain.RankingProfileRepository
+}
+
+func NewRankingProfileService(repo domain.RankingProfileRepository) RankingProfileService {
+	return &rankingProfileService{repo: repo}
+}
+
+func (s *rankingProfileService) ListProfiles(ctx context.Context, section string) ([]domain.RankingProfile, error) {
+	if section != "" && !isRankedSection(section) {
+		return nil, fmt.Errorf("%w: unsupported section %q", domain.ErrInvalidRankingProfile, section)
+	}
+	return s.repo.ListRankingProfiles(ctx, section)
+}
+
+func (s *rankingProfileService) GetProfile(ctx context.Context, id uuid.UUID) (*domain.RankingProfile, error) {
+	return s.repo.GetRankingProfile(ctx, id)
+}
+
+func (s *rankingProfileService) CreateProfile(ctx context.Context, input CreateRankingProfileInput) (*domain.RankingProfile, error) {
+	profile := &domain.RankingProfile{
+		Name:                 strings.TrimSpace(input.Name),
+		Section:              strings.TrimSpace(input.Section),
+		RankingWeights:       input.Weights,
+		RecencyHalfLifeHours: input.RecencyHalfLifeHours,
+		MaxPerProducer:       input.MaxPerProducer,
+	}
+	if input.ActorID != uuid.Nil {
+		actorID := input.ActorID
+		profile.CreatedBy = &actorID
+	}
+	if err := validateRankingProfile(profile); err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateRankingProfile(ctx, profile); err != nil {
+		return nil, err
+	}
+	log.Printf("[Catalog Ranking] created profile=%s section=%s", profile.ID, profile.Section)
+	return profile, nil
+}
+
+func (s *rankingProfileService) PreviewProfile(ctx context.Context, id uuid.UUID, period string, limit int) (*RankingPreview, error) {
+	profile, err := s.repo.GetRankingProfile(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if period == "" && profile.Section == domain.HomeSectionTrending {
+		// The homepage only shows the 24h trending ranking.
+		period = domain.HomePeriod24H
+	} else {
+		period = normalizeHomePeriod(period)
+	}
+	if limit <= 0 {
+		limit = defaultRankingPreviewLimit
+	}
+	if limit > maxRankingPreviewLimit {
+		limit = maxRankingPreviewLimit
+	}
+
+	rows, err := s.repo.PreviewRankingProfile(ctx, profile, period, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	preview := &RankingPreview{
+		Profile:     *profile,
+		Period:      period,
+		Limit:       limit,
+		Items:       make([]RankingPreviewItem, len(rows)),
+		GeneratedAt: time.Now().UTC(),
+	}
+	for i, row := range rows {
+		item := RankingPreviewItem{
+			SpecID:        row.SpecID,
+			Title:         row.Title,
+			ProducerID:    row.ProducerID,
+			ProposedRank:  row.ProposedRank,
+			ProposedScore: row.ProposedScore,
+			CurrentRank:   row.CurrentRank,
+			CurrentScore:  row.CurrentScore,
+			Change:        previewChange(row.ProposedRank, row.CurrentRank, limit),
+			Metrics:       metricsPointer(parseRankingMetrics(row.MetricsJSON), row.MetricsJSON),
+		}
+		if row.ProposedRank != nil && row.CurrentRank != nil {
+			delta := *row.CurrentRank - *row.ProposedRank
+			item.RankDelta = &delta
+		}
+		switch item.Change {
+		case "new":
+			preview.Summary.Entered++
+		case "dropped":
+			preview.Summary.Dropped++
+		case "up":
+			preview.Summary.MovedUp++
+		case "down":
+			preview.Summary.MovedDown++
+		default:
+			preview.Summary.Unchanged++
+		}
+		preview.Items[i] = item
+	}
+	return preview, nil
+}
+
+func (s *rankingProfileService) ActivateProfile(ctx context.Context, id, actorID uuid.UUID) (*domain.RankingProfile, error) {
+	profile, err := s.repo.ActivateRankingProfile(ctx, id, actorID)
+	if err != nil {
+		return nil, err
+	}
+	s.recalculateSection(ctx, profile.Section)
+	return profile, nil
+}
+
+// DeactivateProfile returns the section to its built-in formula.
+func (s *rankingProfileService) DeactivateProfile(ctx context.Context, id uuid.UUID) (*domain.RankingProfile, error) {
+	profile, err := s.repo.DeactivateRankingProfile(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	s.recalculateSection(ctx, profile.Section)
+	return profile, nil
+}
+
+func (s *rankingProfileService) RefreshSection(ctx context.Context, section string) error {
+	if !isRankedSection(section) {
+		return fmt.Errorf("%w: unknown section %q", domain.ErrInvalidRankingProfile, section)
+	}
+	var errs []error
+	for _, period := range rankingPeriods {
+		if err := s.repo.RecalculateBeatRankings(ctx, section, period); err != nil {
+			errs = append(errs, fmt.Errorf("period %s: %w", period, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// recalculateSection refreshes the stored rankings right away. Failures are
+// only logged: the profile change is already saved and the scheduled refresh
+// picks it up on its next run.
+func (s *rankingProfileService) recalculateSection(ctx context.Context, section string) {
+	if err := s.RefreshSection(ctx, section); err != nil {
+		log.Printf("[Catalog Ranking] recalculation after profile change failed section=%s err=%v", section, err)
+	}
+}
+
+func previewChange(proposedRank, currentRank *int, limit int) string {
+	inProposed := proposedRank != nil && *proposedRank <= limit
+	inCurrent := currentRank != nil && *currentRank <= limit
+	switch {
+	case !inCurrent:
+		return "new"
+	case !inProposed:
+		return "dropped"
+	default:
+		return movementForRanks(*proposedRank, currentRank)
+	}
+}
+
+func isRankedSection(section string) bool {
+	return section == domain.HomeSectionTrending || section == domain.HomeSectionTopCharts
+}
+
+func validateRankingProfile(profile *domain.RankingProfile) error {
+	nameLength := utf8.RuneCountInString(profile.Name)
+	if nameLength == 0 {
+		return fmt.Errorf("%w: name is required", domain.ErrInvalidRankingProfile)
+	}
+	if nameLength > maxRankingProfileNameSize {
+		return fmt.Errorf("%w: name must be at most %d characters", domain.ErrInvalidRankingProfile, maxRankingProfileNameSize)
+	}
+	if !isRankedSection(profile.Section) {
+		return fmt.Errorf("%w: section must be %s or %s", domain.ErrInvalidRankingProfile, domain.HomeSectionTrending, domain.HomeSectionTopCharts)
+	}
+
+	weights := []struct {
+		name  string
+		value float64
+	}{
+		{"plays", profile.Plays},
+		{"unique_listeners", profile.UniqueListeners},
+		{"favorites", profile.Favorites},
+		{"downloads", profile.Downloads},
+		{"purchases", profile.Purchases},
+		{"revenue", profile.Revenue},
+	}
+	var total float64
+	for _, weight := range weights {
+		if math.IsNaN(weight.value) || weight.value < 0 || weight.value > maxRankingWeight {
+			return fmt.Errorf("%w: %s weight must be between 0 and %d", domain.ErrInvalidRankingProfile, weight.name, maxRankingWeight)
+		}
+		total += weight.value
+	}
+	if total == 0 {
+		return fmt.Errorf("%w: at least one weight must be positive", domain.ErrInvalidRankingProfile)
+	}
+
+	if math.IsNaN(profile.RecencyHalfLifeHours) || profile.RecencyHalfLifeHours < 0 || profile.RecencyHalfLifeHours > maxRecencyHalfLifeHours {
+		return fmt.Errorf("%w: recency_half_life_hours must be between 0 and %d", domain.ErrInvalidRankingProfile, maxRecencyHalfLifeHours)
+	}
+	if profile.MaxPerProducer < 0 || profile.MaxPerProducer > maxRankingMaxPerProducer {
+		return fmt.Errorf("%w: max_per_producer must be between 0 and %d", domain.ErrInvalidRankingProfile, maxRankingMaxPerProducer)
+	}
+	return nil
+}
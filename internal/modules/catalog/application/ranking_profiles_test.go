@@ -0,0 +1,189 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/saransh1220/blueprint-audio/internal/modules/catalog/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockRankingRepo struct {
+	created     *domain.RankingProfile
+	profile     *domain.RankingProfile
+	previewRows []domain.RankingPreviewRow
+	previewArgs []any
+	recalcFn    func(section, period string) error
+	recalcCalls []string
+}
+
+func (m *mockRankingRepo) CreateRankingProfile(_ context.Context, profile *domain.RankingProfile) error {
+	profile.ID = uuid.New()
+	m.created = profile
+	return nil
+}
+func (m *mockRankingRepo) GetRankingProfile(_ context.Context, id uuid.UUID) (*domain.RankingProfile, error) {
+	if m.profile == nil || m.profile.ID != id {
+		return nil, domain.ErrRankingProfileNotFound
+	}
+	return m.profile, nil
+}
+func (m *mockRankingRepo) ListRankingProfiles(context.Context, string) ([]domain.RankingProfile, error) {
+	return []domain.RankingProfile{}, nil
+}
+func (m *mockRankingRepo) GetActiveRankingProfile(context.Context, string) (*domain.RankingProfile, error) {
+	return nil, nil
+}
+func (m *mockRankingRepo) ActivateRankingProfile(_ context.Context, id, actorID uuid.UUID) (*domain.RankingProfile, error) {
+	if m.profile == nil || m.profile.ID != id {
+		return nil, domain.ErrRankingProfileNotFound
+	}
+	m.profile.IsActive = true
+	m.profile.ActivatedBy = &actorID
+	return m.profile, nil
+}
+func (m *mockRankingRepo) DeactivateRankingProfile(_ context.Context, id uuid.UUID) (*domain.RankingProfile, error) {
+	m.profile.IsActive = false
+	return m.profile, nil
+}
+func (m *mockRankingRepo) PreviewRankingProfile(_ context.Context, profile *domain.RankingProfile, period string, limit int) ([]domain.RankingPreviewRow, error) {
+	m.previewArgs = []any{profile.ID, period, limit}
+	return m.previewRows, nil
+}
+func (m *mockRankingRepo) RecalculateBeatRankings(_ context.Context, section, period string) error {
+	m.recalcCalls = append(m.recalcCalls, section+":"+period)
+	if m.recalcFn != nil {
+		return m.recalcFn(section, period)
+	}
+	return nil
+}
+
+func intPtr(v int) *int { return &v }
+
+func TestRankingProfileService_CreateValidates(t *testing.T) {
+	repo := &mockRankingRepo{}
+	svc := NewRankingProfileService(repo)
+	valid := CreateRankingProfileInput{
+		Name:                 " Purchases first ",
+		Section:              domain.HomeSectionTopCharts,
+		Weights:              domain.RankingWeights{Plays: 1, Purchases: 25},
+		RecencyHalfLifeHours: 72,
+		MaxPerProducer:       2,
+		ActorID:              uuid.New(),
+	}
+
+	profile, err := svc.CreateProfile(context.Background(), valid)
+	require.NoError(t, err)
+	assert.Equal(t, "Purchases first", profile.Name)
+	assert.Equal(t, valid.ActorID, *profile.CreatedBy)
+	assert.Same(t, profile, repo.created)
+
+	for name, mutate := range map[string]func(*CreateRankingProfileInput){
+		"missing name":     func(in *CreateRankingProfileInput) { in.Name = "  " },
+		"unknown section":  func(in *CreateRankingProfileInput) { in.Section = domain.HomeSectionFeatured },
+		"negative weight":  func(in *CreateRankingProfileInput) { in.Weights.Revenue = -1 },
+		"all zero weights": func(in *CreateRankingProfileInput) { in.Weights = domain.RankingWeights{} },
+		"negative decay":   func(in *CreateRankingProfileInput) { in.RecencyHalfLifeHours = -1 },
+		"cap too large":    func(in *CreateRankingProfileInput) { in.MaxPerProducer = 101 },
+	} {
+		t.Run(name, func(t *testing.T) {
+			input := valid
+			mutate(&input)
+			_, err := svc.CreateProfile(context.Background(), input)
+			assert.ErrorIs(t, err, domain.ErrInvalidRankingProfile)
+		})
+	}
+
+	_, err = svc.ListProfiles(context.Background(), "weekly")
+	assert.ErrorIs(t, err, domain.ErrInvalidRankingProfile)
+}
+
+func TestRankingProfileService_PreviewDiff(t *testing.T) {
+	profile := &domain.RankingProfile{ID: uuid.New(), Section: domain.HomeSectionTrending}
+	repo := &mockRankingRepo{
+		profile: profile,
+		previewRows: []domain.RankingPreviewRow{
+			{SpecID: uuid.New(), ProposedRank: intPtr(1), CurrentRank: intPtr(3), MetricsJSON: []byte(`{"plays":5,"profile_id":"` + profile.ID.String() + `","contributions":{"plays":5},"decay":0.5}`)},
+			{SpecID: uuid.New(), ProposedRank: intPtr(2)},
+			{SpecID: uuid.New(), ProposedRank: intPtr(3), CurrentRank: intPtr(2)},
+			{SpecID: uuid.New(), ProposedRank: intPtr(4), CurrentRank: intPtr(4)},
+			{SpecID: uuid.New(), ProposedRank: intPtr(30), CurrentRank: intPtr(1)},
+			{SpecID: uuid.New(), CurrentRank: intPtr(5)},
+		},
+	}
+	svc := NewRankingProfileService(repo)
+
+	preview, err := svc.PreviewProfile(context.Background(), profile.ID, "", 0)
+	require.NoError(t, err)
+	assert.Equal(t, []any{profile.ID, domain.HomePeriod24H, defaultRankingPreviewLimit}, repo.previewArgs)
+	assert.Equal(t, RankingPreviewSummary{Entered: 1, Dropped: 2, MovedUp: 1, MovedDown: 1, Unchanged: 1}, preview.Summary)
+
+	changes := make([]string, len(preview.Items))
+	for i, item := range preview.Items {
+		changes[i] = item.Change
+	}
+	assert.Equal(t, []string{"up", "new", "down", "-", "dropped", "dropped"}, changes)
+	assert.Equal(t, 2, *preview.Items[0].RankDelta)
+	assert.Nil(t, preview.Items[1].RankDelta)
+	require.NotNil(t, preview.Items[0].Metrics)
+	assert.Equal(t, profile.ID, *preview.Items[0].Metrics.ProfileID)
+	assert.Equal(t, 5.0, preview.Items[0].Metrics.Contributions["plays"])
+	assert.Equal(t, 0.5, *preview.Items[0].Metrics.Decay)
+
+	_, err = svc.PreviewProfile(context.Background(), profile.ID, domain.HomePeriod7D, 500)
+	require.NoError(t, err)
+	assert.Equal(t, []any{profile.ID, domain.HomePeriod7D, maxRankingPreviewLimit}, repo.previewArgs)
+
+	_, err = svc.PreviewProfile(context.Background(), uuid.New(), "", 0)
+	assert.ErrorIs(t, err, domain.ErrRankingProfileNotFound)
+}
+
+func TestRankingProfileService_ActivateRecalculatesSection(t *testing.T) {
+	profile := &domain.RankingProfile{ID: uuid.New(), Section: domain.HomeSectionTopCharts}
+	repo := &mockRankingRepo{
+		profile: profile,
+		recalcFn: func(_, period string) error {
+			if period == domain.HomePeriod7D {
+				return errors.New("lock busy")
+			}
+			return nil
+		},
+	}
+	svc := NewRankingProfileService(repo)
+	actorID := uuid.New()
+
+	activated, err := svc.ActivateProfile(context.Background(), profile.ID, actorID)
+	require.NoError(t, err, "a failed refresh must not fail the activation")
+	assert.True(t, activated.IsActive)
+	assert.Equal(t, []string{"top_charts:24h", "top_charts:7d", "top_charts:30d"}, repo.recalcCalls)
+
+	repo.recalcCalls = nil
+	deactivated, err := svc.DeactivateProfile(context.Background(), profile.ID)
+	require.NoError(t, err)
+	assert.False(t, deactivated.IsActive)
+	assert.Len(t, repo.recalcCalls, 3)
+
+	_, err = svc.ActivateProfile(context.Background(), uuid.New(), actorID)
+	assert.ErrorIs(t, err, domain.ErrRankingProfileNotFound)
+}
+
+func TestRankingProfileService_RefreshSection(t *testing.T) {
+	repo := &mockRankingRepo{
+		recalcFn: func(_, period string) error {
+			if period == domain.HomePeriod24H {
+				return errors.New("lock busy")
+			}
+			return nil
+		},
+	}
+	svc := NewRankingProfileService(repo)
+
+	err := svc.RefreshSection(context.Background(), domain.HomeSectionTrending)
+	assert.ErrorContains(t, err, "period 24h: lock busy")
+	assert.Equal(t, []string{"trending:24h", "trending:7d", "trending:30d"}, repo.recalcCalls, "one failed period must not stop the others")
+
+	assert.ErrorIs(t, svc.RefreshSection(context.Background(), domain.HomeSectionFeatured), domain.ErrInvalidRankingProfile)
+}
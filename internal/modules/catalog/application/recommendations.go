@@ -0,0 +1,61 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/saransh1220/blueprint-audio/internal/modules/catalog/domain"
+)
+
+const (
+	// RecommendationActiveWindow is how recently a user must have played,
+	// favorited or bought a beat for their recommendations to be refreshed.
+	// Everyone else keeps the beats last calculated for them.
+	RecommendationActiveWindow = 30 * 24 * time.Hour
+	// recommendationsPerUser is more than the home page shows, so beats
+	// bought after a refresh can be dropped without emptying the section.
+	recommendationsPerUser = 50
+)
+
+// Recommender rebuilds the stored "For you" section of each active user.
+type Recommender struct {
+	repo domain.RecommendationRepository
+	now  func() time.Time
+}
+
+func NewRecommender(repo domain.RecommendationRepository) *Recommender {
+	return &Recommender{repo: repo, now: time.Now}
+}
+
+// RefreshRecommendations rebuilds the shared beat signals, then recalculates
+// the recommendations of every active user and returns how many users were
+// refreshed. A failure for one user does not stop the others.
+func (r *Recommender) RefreshRecommendations(ctx context.Context) (int, error) {
+	users, err := r.repo.ListRecommendationUsers(ctx, r.now().Add(-RecommendationActiveWindow))
+	if err != nil {
+		return 0, fmt.Errorf("list recommendation users: %w", err)
+	}
+	if len(users) == 0 {
+		return 0, nil
+	}
+	if err := r.repo.RebuildRecommendationSignals(ctx); err != nil {
+		return 0, fmt.Errorf("rebuild recommendation signals: %w", err)
+	}
+
+	refreshed := 0
+	var errs []error
+	for _, userID := range users {
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, err)
+			break
+		}
+		if _, err := r.repo.RecalculateUserRecommendations(ctx, userID, recommendationsPerUser); err != nil {
+			errs = append(errs, fmt.Errorf("user %s: %w", userID, err))
+			continue
+		}
+		refreshed++
+	}
+	return refreshed, errors.Join(errs...)
+}
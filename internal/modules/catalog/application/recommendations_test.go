@@ -0,0 +1,88 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recommendationRepoStub struct {
+	listFn        func(context.Context, time.Time) ([]uuid.UUID, error)
+	rebuildFn     func(context.Context) error
+	recalculateFn func(context.Context, uuid.UUID, int) (int, error)
+}
+
+func (s recommendationRepoStub) RebuildRecommendationSignals(ctx context.Context) error {
+	return s.rebuildFn(ctx)
+}
+
+func (s recommendationRepoStub) ListRecommendationUsers(ctx context.Context, activeSince time.Time) ([]uuid.UUID, error) {
+	return s.listFn(ctx, activeSince)
+}
+
+func (s recommendationRepoStub) RecalculateUserRecommendations(ctx context.Context, userID uuid.UUID, limit int) (int, error) {
+	return s.recalculateFn(ctx, userID, limit)
+}
+
+func TestRecommender_RefreshRecommendations(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	failing, healthy := uuid.New(), uuid.New()
+	var refreshed []uuid.UUID
+	rebuilds := 0
+	recommender := NewRecommender(recommendationRepoStub{
+		listFn: func(_ context.Context, activeSince time.Time) ([]uuid.UUID, error) {
+			assert.Equal(t, now.Add(-RecommendationActiveWindow), activeSince)
+			return []uuid.UUID{failing, healthy}, nil
+		},
+		rebuildFn: func(context.Context) error {
+			rebuilds++
+			return nil
+		},
+		recalculateFn: func(_ context.Context, userID uuid.UUID, limit int) (int, error) {
+			assert.Equal(t, 1, rebuilds, "signals are rebuilt once, before any user")
+			assert.Equal(t, recommendationsPerUser, limit)
+			if userID == failing {
+				return 0, errors.New("boom")
+			}
+			refreshed = append(refreshed, userID)
+			return 10, nil
+		},
+	})
+	recommender.now = func() time.Time { return now }
+
+	count, err := recommender.RefreshRecommendations(context.Background())
+	assert.Equal(t, 1, count)
+	assert.Equal(t, []uuid.UUID{healthy}, refreshed)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), failing.String())
+}
+
+func TestRecommender_RefreshRecommendationsListFailure(t *testing.T) {
+	recommender := NewRecommender(recommendationRepoStub{
+		listFn: func(context.Context, time.Time) ([]uuid.UUID, error) {
+			return nil, errors.New("db down")
+		},
+	})
+	count, err := recommender.RefreshRecommendations(context.Background())
+	assert.Zero(t, count)
+	assert.EqualError(t, err, "list recommendation users: db down")
+}
+
+func TestRecommender_RefreshRecommendationsRebuildFailure(t *testing.T) {
+	recommender := NewRecommender(recommendationRepoStub{
+		listFn: func(context.Context, time.Time) ([]uuid.UUID, error) {
+			return []uuid.UUID{uuid.New()}, nil
+		},
+		rebuildFn: func(context.Context) error {
+			return errors.New("db down")
+		},
+	})
+	count, err := recommender.RefreshRecommendations(context.Background())
+	assert.Zero(t, count)
+	assert.EqualError(t, err, "rebuild recommendation signals: db down")
+}
@@ -9,9 +9,11 @@ import (
 	"strings"
 	"time"
 	"unicode"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
 	"github.com/saransh1220/blueprint-audio/internal/modules/catalog/domain"
+	"github.com/saransh1220/blueprint-audio/internal/shared/money"
 )
 
 const (
@@ -19,12 +21,15 @@ const (
 	defaultSpecLimit = 20
 	maxSpecLimit     = 50
 	shortCodeLength  = 8
-	currencyINR      = "INR"
-	currencyUSD      = "USD"
 
 	defaultHomeLimit = 8
 	maxHomeLimit     = 20
 	homeCacheTTL     = 900
+
+	defaultSuggestLimit   = 8
+	maxSuggestLimit       = 20
+	minSuggestQueryLength = 2
+	maxSuggestQueryLength = 64
 )
 
 type SpecService interface {
@@ -38,6 +43,7 @@ type SpecService interface {
 	GetSpecByShortCode(ctx context.Context, code string) (*domain.Spec, error)
 	GetSpecBySlug(ctx context.Context, slug string) (*domain.Spec, error)
 	GetHome(ctx context.Context, params domain.HomepageParams) (*domain.HomepageData, error)
+	SuggestSearch(ctx context.Context, query string, limit int) ([]domain.SearchSuggestion, error)
 }
 
 type specService struct {
@@ -121,10 +127,20 @@ func (s *specService) ListSpecs(ctx context.Context, filter domain.SpecFilter) (
 	return s.repo.List(ctx, filter)
 }
 
+// SuggestSearch returns autocomplete suggestions for the search bar. Queries
+// shorter than two characters return nothing rather than the whole catalog.
+func (s *specService) SuggestSearch(ctx context.Context, query string, limit int) ([]domain.SearchSuggestion, error) {
+	query = NormalizeSuggestQuery(query)
+	if utf8.RuneCountInString(query) < minSuggestQueryLength {
+		return []domain.SearchSuggestion{}, nil
+	}
+	return s.repo.SuggestSearch(ctx, query, NormalizeSuggestLimit(limit))
+}
+
 func (s *specService) GetHome(ctx context.Context, params domain.HomepageParams) (*domain.HomepageData, error) {
 	limit := normalizeHomeLimit(params.Limit)
 	period := normalizeHomePeriod(params.Period)
-	sections := normalizeHomeSections(params.Sections)
+	sections := normalizeHomeSections(params.Sections, params.UserID != nil)
 	log.Printf("[Catalog Home] building homepage limit=%d period=%s sections=%v", limit, period, sections)
 
 	stats, err := s.repo.GetHomepageStats(ctx)
@@ -222,6 +238,37 @@ func (s *specService) GetHome(ctx context.Context, params domain.HomepageParams)
 				Period: period,
 				Items:  items,
 			}
+		case domain.HomeSectionForYou:
+			if params.UserID == nil {
+				log.Printf("[Catalog Home] section=%s skipped for anonymous visitor", section)
+				continue
+			}
+			source := "personalized"
+			items, err := s.getRecommendedHomeItems(ctx, *params.UserID, limit)
+			if err != nil {
+				log.Printf("[Catalog Home] section=%s unavailable, using fallback: %v", section, err)
+			}
+			// Users without listening history yet see what is trending.
+			if len(items) == 0 {
+				source = "fallback"
+				items, err = s.getRankedHomeItems(ctx, domain.HomeSectionTrending, domain.HomePeriod24H, limit)
+				if err != nil {
+					log.Printf("[Catalog Home] section=%s trending fallback unavailable: %v", section, err)
+				}
+			}
+			if len(items) == 0 {
+				specs, err := getFallback()
+				if err != nil {
+					return nil, err
+				}
+				items = specsToRankedItems(specs)
+			}
+			log.Printf("[Catalog Home] section=%s source=%s items=%d", section, source, len(items))
+			data.Sections[section] = domain.HomepageSection{
+				Title:  "For you",
+				Source: source,
+				Items:  items,
+			}
 		}
 	}
 
@@ -231,16 +278,24 @@ func (s *specService) GetHome(ctx context.Context, params domain.HomepageParams)
 
 func (s *specService) getRankedHomeItems(ctx context.Context, section, period string, limit int) ([]domain.RankedSpec, error) {
 	log.Printf("[Catalog Home] loading ranked items section=%s period=%s limit=%d", section, period, limit)
-	if err := s.ensureRankingFresh(ctx, section, period); err != nil {
-		log.Printf("[Catalog Home] ranking refresh failed section=%s period=%s err=%v", section, period, err)
-	}
-
 	rows, err := s.repo.GetRankedSpecs(ctx, section, period, limit)
 	if err != nil {
 		log.Printf("[Catalog Home] ranked read failed section=%s period=%s err=%v", section, period, err)
 		return nil, err
 	}
 	log.Printf("[Catalog Home] ranked read succeeded section=%s period=%s rows=%d", section, period, len(rows))
+	return rankingRowsToItems(rows), nil
+}
+
+func (s *specService) getRecommendedHomeItems(ctx context.Context, userID uuid.UUID, limit int) ([]domain.RankedSpec, error) {
+	rows, err := s.repo.GetRecommendedSpecs(ctx, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	return rankingRowsToItems(rows), nil
+}
+
+func rankingRowsToItems(rows []domain.RankingRow) []domain.RankedSpec {
 	items := make([]domain.RankedSpec, len(rows))
 	for i, row := range rows {
 		items[i] = domain.RankedSpec{
@@ -252,27 +307,7 @@ func (s *specService) getRankedHomeItems(ctx context.Context, section, period st
 			CalculatedAt: row.CalculatedAt,
 		}
 	}
-	return items, nil
-}
-
-func (s *specService) ensureRankingFresh(ctx context.Context, section, period string) error {
-	freshness, err := s.repo.GetRankingFreshness(ctx, section, period)
-	if err != nil {
-		log.Printf("[Catalog Home] ranking freshness check failed section=%s period=%s err=%v", section, period, err)
-		return err
-	}
-
-	maxAge := time.Hour
-	if section == domain.HomeSectionTrending {
-		maxAge = 15 * time.Minute
-	}
-
-	if freshness.Count > 0 && !freshness.CalculatedAt.IsZero() && time.Since(freshness.CalculatedAt) < maxAge {
-		log.Printf("[Catalog Home] ranking fresh section=%s period=%s count=%d calculated_at=%s", section, period, freshness.Count, freshness.CalculatedAt.Format(time.RFC3339))
-		return nil
-	}
-	log.Printf("[Catalog Home] ranking stale/missing section=%s period=%s count=%d calculated_at=%s; recalculating", section, period, freshness.Count, freshness.CalculatedAt.Format(time.RFC3339))
-	return s.repo.RecalculateBeatRankings(ctx, section, period)
+	return items
 }
 
 func (s *specService) DeleteSpec(ctx context.Context, id uuid.UUID, producerId uuid.UUID) error {
@@ -355,6 +390,26 @@ func normalizePageAndLimit(page, limit int) (int, int) {
 	return page, limit
 }
 
+// NormalizeSuggestQuery lower-cases the query, collapses whitespace and caps its
+// length. Callers caching suggestions should key on the normalized form.
+func NormalizeSuggestQuery(query string) string {
+	query = strings.Join(strings.Fields(strings.ToLower(query)), " ")
+	if runes := []rune(query); len(runes) > maxSuggestQueryLength {
+		query = strings.TrimSpace(string(runes[:maxSuggestQueryLength]))
+	}
+	return query
+}
+
+func NormalizeSuggestLimit(limit int) int {
+	if limit <= 0 {
+		return defaultSuggestLimit
+	}
+	if limit > maxSuggestLimit {
+		return maxSuggestLimit
+	}
+	return limit
+}
+
 func normalizeHomeLimit(limit int) int {
 	if limit <= 0 {
 		return defaultHomeLimit
@@ -378,14 +433,20 @@ func normalizeHomePeriod(period string) string {
 	}
 }
 
-func normalizeHomeSections(sections []string) []string {
+// normalizeHomeSections drops unknown and repeated sections. Signed-in users
+// get for_you first when no sections are requested.
+func normalizeHomeSections(sections []string, signedIn bool) []string {
 	if len(sections) == 0 {
-		return []string{
+		defaults := []string{
 			domain.HomeSectionFeatured,
 			domain.HomeSectionTrending,
 			domain.HomeSectionTopCharts,
 			domain.HomeSectionNewReleases,
 		}
+		if signedIn {
+			defaults = append([]string{domain.HomeSectionForYou}, defaults...)
+		}
+		return defaults
 	}
 
 	allowed := map[string]bool{
@@ -393,6 +454,7 @@ func normalizeHomeSections(sections []string) []string {
 		domain.HomeSectionTrending:    true,
 		domain.HomeSectionTopCharts:   true,
 		domain.HomeSectionNewReleases: true,
+		domain.HomeSectionForYou:      true,
 	}
 	seen := make(map[string]bool, len(sections))
 	normalized := make([]string, 0, len(sections))
@@ -404,7 +466,7 @@ func normalizeHomeSections(sections []string) []string {
 		}
 	}
 	if len(normalized) == 0 {
-		return normalizeHomeSections(nil)
+		return normalizeHomeSections(nil, signedIn)
 	}
 	return normalized
 }
@@ -450,7 +512,7 @@ func parseRankingMetrics(raw []byte) domain.BeatRankingMetrics {
 }
 
 func normalizeSpecCurrencies(spec *domain.Spec) error {
-	currency, err := normalizeCurrency(spec.PriceCurrency, currencyINR)
+	currency, err := normalizeCurrency(spec.PriceCurrency, money.CurrencyINR)
 	if err != nil {
 		return err
 	}
@@ -471,7 +533,12 @@ func normalizeCurrency(value, fallback string) (string, error) {
 	if currency == "" {
 		currency = fallback
 	}
-	if currency != currencyINR && currency != currencyUSD {
+	if !money.IsSupported(currency) {
+		return "", domain.ErrInvalidCurrency
+	}
+	// Other currencies can only be listed in while rates convert them into
+	// the currencies orders are charged in; otherwise nobody could buy.
+	if !money.IsChargeable(currency) && !money.CurrentRates().CanCharge(currency) {
 		return "", domain.ErrInvalidCurrency
 	}
 	return currency, nil
@@ -3,10 +3,12 @@ package application
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
 
 	"github.com/google/uuid"
 	"github.com/saransh1220/blueprint-audio/internal/modules/catalog/domain"
+	"github.com/saransh1220/blueprint-audio/internal/shared/money"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -23,6 +25,8 @@ type mockRepo struct {
 	statsFn          func(context.Context) (*domain.HomepageStats, error)
 	newestFn         func(context.Context, int) ([]domain.Spec, error)
 	rankedFn         func(context.Context, string, string, int) ([]domain.RankingRow, error)
+	recommendedFn    func(context.Context, uuid.UUID, int) ([]domain.RankingRow, error)
+	suggestFn        func(context.Context, string, int) ([]domain.SearchSuggestion, error)
 }
 
 func (m mockRepo) Create(ctx context.Context, s *domain.Spec) error { return m.createFn(ctx, s) }
@@ -73,12 +77,21 @@ func (m mockRepo) GetRankedSpecs(ctx context.Context, section, period string, li
 	}
 	return []domain.RankingRow{}, nil
 }
-func (m mockRepo) GetRankingFreshness(context.Context, string, string) (*domain.RankingFreshness, error) {
-	return &domain.RankingFreshness{}, nil
+func (m mockRepo) GetRecommendedSpecs(ctx context.Context, userID uuid.UUID, limit int) ([]domain.RankingRow, error) {
+	if m.recommendedFn != nil {
+		return m.recommendedFn(ctx, userID, limit)
+	}
+	return []domain.RankingRow{}, nil
 }
 func (m mockRepo) RecalculateBeatRankings(context.Context, string, string) error {
 	return nil
 }
+func (m mockRepo) SuggestSearch(ctx context.Context, query string, limit int) ([]domain.SearchSuggestion, error) {
+	if m.suggestFn != nil {
+		return m.suggestFn(ctx, query, limit)
+	}
+	return []domain.SearchSuggestion{}, nil
+}
 
 func TestSpecService_CreateSpecValidation(t *testing.T) {
 	svc := NewSpecService(mockRepo{createFn: func(context.Context, *domain.Spec) error { return nil }})
@@ -87,15 +100,15 @@ func TestSpecService_CreateSpecValidation(t *testing.T) {
 	err := svc.CreateSpec(ctx, &domain.Spec{})
 	require.EqualError(t, err, "title is required")
 
-	err = svc.CreateSpec(ctx, &domain.Spec{Title: "x", BasePrice: -1})
+	err = svc.CreateSpec(ctx, &domain.Spec{Title: "x", BasePriceMinor: -100})
 	require.EqualError(t, err, "price cannot be negative")
 
-	err = svc.CreateSpec(ctx, &domain.Spec{Title: "x", BasePrice: 1, Category: domain.CategoryBeat, BPM: 20})
+	err = svc.CreateSpec(ctx, &domain.Spec{Title: "x", BasePriceMinor: 100, Category: domain.CategoryBeat, BPM: 20})
 	require.EqualError(t, err, "BPM must be between 60 and 300")
 
 	stems := "stems"
 	beat := domain.Spec{
-		Title: "x", BasePrice: 1, Category: domain.CategoryBeat, BPM: 120, Key: "C MAJOR",
+		Title: "x", BasePriceMinor: 100, Category: domain.CategoryBeat, BPM: 120, Key: "C MAJOR",
 		Genres:   []domain.Genre{{Name: "TRAP"}},
 		Licenses: []domain.LicenseOption{{LicenseType: domain.LicenseBasic, Name: "Basic"}},
 	}
@@ -109,7 +122,7 @@ func TestSpecService_CreateSpecValidation(t *testing.T) {
 	err = svc.CreateSpec(ctx, &beat)
 	require.EqualError(t, err, "stems file is mandatory for beats")
 
-	err = svc.CreateSpec(ctx, &domain.Spec{Title: "ok", BasePrice: 1, Category: domain.CategorySample})
+	err = svc.CreateSpec(ctx, &domain.Spec{Title: "ok", BasePriceMinor: 100, Category: domain.CategorySample})
 	require.NoError(t, err)
 }
 
@@ -137,6 +150,76 @@ func TestSpecService_GetHomeBuildsAllSections(t *testing.T) {
 	assert.Equal(t, "algorithmic", home.Sections[domain.HomeSectionTopCharts].Source)
 }
 
+func TestSpecService_GetHomeForYou(t *testing.T) {
+	userID := uuid.New()
+	trending := func(_ context.Context, section, _ string, _ int) ([]domain.RankingRow, error) {
+		if section == domain.HomeSectionTrending {
+			return []domain.RankingRow{{Spec: domain.Spec{ID: uuid.New(), Title: "Trending"}, Rank: 1}}, nil
+		}
+		return []domain.RankingRow{}, nil
+	}
+
+	t.Run("personalized", func(t *testing.T) {
+		repo := mockRepo{
+			rankedFn: trending,
+			recommendedFn: func(_ context.Context, id uuid.UUID, limit int) ([]domain.RankingRow, error) {
+				assert.Equal(t, userID, id)
+				assert.Equal(t, 8, limit)
+				return []domain.RankingRow{{
+					Spec:        domain.Spec{ID: uuid.New(), Title: "Recommended"},
+					Rank:        1,
+					Score:       72.5,
+					MetricsJSON: []byte(`{"plays":4,"favorites":1,"contributions":{"co_occurrence":60}}`),
+				}}, nil
+			},
+		}
+		home, err := NewSpecService(repo).GetHome(context.Background(), domain.HomepageParams{UserID: &userID})
+		require.NoError(t, err)
+		assert.Len(t, home.Sections, 5)
+		section := home.Sections[domain.HomeSectionForYou]
+		assert.Equal(t, "For you", section.Title)
+		assert.Equal(t, "personalized", section.Source)
+		require.Len(t, section.Items, 1)
+		assert.Equal(t, "Recommended", section.Items[0].Spec.Title)
+		assert.Equal(t, "-", section.Items[0].Movement)
+		require.NotNil(t, section.Items[0].Metrics)
+		assert.Equal(t, 60.0, section.Items[0].Metrics.Contributions["co_occurrence"])
+	})
+
+	t.Run("falls back to trending without recommendations", func(t *testing.T) {
+		repo := mockRepo{
+			rankedFn: trending,
+			recommendedFn: func(context.Context, uuid.UUID, int) ([]domain.RankingRow, error) {
+				return nil, errors.New("db down")
+			},
+		}
+		home, err := NewSpecService(repo).GetHome(context.Background(), domain.HomepageParams{
+			UserID:   &userID,
+			Sections: []string{domain.HomeSectionForYou},
+		})
+		require.NoError(t, err)
+		section := home.Sections[domain.HomeSectionForYou]
+		assert.Equal(t, "fallback", section.Source)
+		require.Len(t, section.Items, 1)
+		assert.Equal(t, "Trending", section.Items[0].Spec.Title)
+	})
+
+	t.Run("skipped for anonymous visitors", func(t *testing.T) {
+		repo := mockRepo{
+			recommendedFn: func(context.Context, uuid.UUID, int) ([]domain.RankingRow, error) {
+				t.Fatal("recommendations read for anonymous visitor")
+				return nil, nil
+			},
+		}
+		home, err := NewSpecService(repo).GetHome(context.Background(), domain.HomepageParams{
+			Sections: []string{domain.HomeSectionForYou, domain.HomeSectionTrending},
+		})
+		require.NoError(t, err)
+		assert.Len(t, home.Sections, 1)
+		assert.NotContains(t, home.Sections, domain.HomeSectionForYou)
+	})
+}
+
 func TestCatalogHelpers(t *testing.T) {
 	assert.Equal(t, 1, func() int { p, _ := normalizePageAndLimit(0, 0); return p }())
 	_, limit := normalizePageAndLimit(2, 999)
@@ -144,7 +227,7 @@ func TestCatalogHelpers(t *testing.T) {
 	assert.Equal(t, 8, normalizeHomeLimit(0))
 	assert.Equal(t, 20, normalizeHomeLimit(99))
 	assert.Equal(t, domain.HomePeriod30D, normalizeHomePeriod("wrong"))
-	assert.Len(t, normalizeHomeSections([]string{"invalid", domain.HomeSectionTrending}), 1)
+	assert.Len(t, normalizeHomeSections([]string{"invalid", domain.HomeSectionTrending}, false), 1)
 	assert.Equal(t, "down", movementForRanks(1, func() *int { n := 0; return &n }()))
 	assert.Equal(t, "up", movementForRanks(1, func() *int { n := 2; return &n }()))
 	assert.Equal(t, "down", movementForRanks(2, func() *int { n := 1; return &n }()))
@@ -160,19 +243,40 @@ func TestSpecService_CreateSpecRejectsInvalidCurrency(t *testing.T) {
 	}})
 	ctx := context.Background()
 
-	err := svc.CreateSpec(ctx, &domain.Spec{Title: "bad", BasePrice: 1, Category: domain.CategorySample, PriceCurrency: "eur"})
+	err := svc.CreateSpec(ctx, &domain.Spec{Title: "bad", BasePriceMinor: 100, Category: domain.CategorySample, PriceCurrency: "brl"})
 	require.ErrorIs(t, err, domain.ErrInvalidCurrency)
 
 	err = svc.CreateSpec(ctx, &domain.Spec{
-		Title:         "bad license",
-		BasePrice:     1,
-		Category:      domain.CategorySample,
-		PriceCurrency: "USD",
-		Licenses:      []domain.LicenseOption{{PriceCurrency: "gbp"}},
+		Title:          "bad license",
+		BasePriceMinor: 100,
+		Category:       domain.CategorySample,
+		PriceCurrency:  "USD",
+		Licenses:       []domain.LicenseOption{{PriceCurrency: "chf"}},
 	})
 	require.ErrorIs(t, err, domain.ErrInvalidCurrency)
 }
 
+func TestSpecService_CreateSpecNeedsRatesForNonChargeCurrencies(t *testing.T) {
+	created := 0
+	svc := NewSpecService(mockRepo{createFn: func(context.Context, *domain.Spec) error {
+		created++
+		return nil
+	}})
+	ctx := context.Background()
+	spec := func() *domain.Spec {
+		return &domain.Spec{Title: "euro", BasePriceMinor: 100, Category: domain.CategorySample, PriceCurrency: "EUR"}
+	}
+
+	// The fallback rates only convert between INR and USD.
+	money.SetRates(nil)
+	require.ErrorIs(t, svc.CreateSpec(ctx, spec()), domain.ErrInvalidCurrency)
+
+	money.SetRates(&money.Rates{Base: money.CurrencyUSD, Rates: map[string]float64{money.CurrencyINR: 80, money.CurrencyEUR: 0.9}})
+	defer money.SetRates(nil)
+	require.NoError(t, svc.CreateSpec(ctx, spec()))
+	assert.Equal(t, 1, created)
+}
+
 func TestSpecService_CreateSpecNormalizesCurrencies(t *testing.T) {
 	svc := NewSpecService(mockRepo{createFn: func(_ context.Context, spec *domain.Spec) error {
 		assert.Equal(t, "USD", spec.PriceCurrency)
@@ -184,10 +288,10 @@ func TestSpecService_CreateSpecNormalizesCurrencies(t *testing.T) {
 	ctx := context.Background()
 
 	err := svc.CreateSpec(ctx, &domain.Spec{
-		Title:         "ok",
-		BasePrice:     1,
-		Category:      domain.CategorySample,
-		PriceCurrency: " usd ",
+		Title:          "ok",
+		BasePriceMinor: 100,
+		Category:       domain.CategorySample,
+		PriceCurrency:  " usd ",
 		Licenses: []domain.LicenseOption{
 			{},
 			{PriceCurrency: " inr "},
@@ -227,16 +331,16 @@ func TestSpecService_DelegatesAndUpdate(t *testing.T) {
 	require.NoError(t, svc.DeleteSpec(ctx, specID, owner))
 	_, _, err = svc.GetUserSpecs(ctx, owner, 1, -1)
 
-	upd := &domain.Spec{ID: specID, Title: "new", BasePrice: 10, Category: domain.CategorySample}
+	upd := &domain.Spec{ID: specID, Title: "new", BasePriceMinor: 1000, Category: domain.CategorySample}
 	require.NoError(t, svc.UpdateSpec(ctx, upd, owner))
 
-	err = svc.UpdateSpec(ctx, &domain.Spec{ID: specID, Title: "", BasePrice: 10}, owner)
+	err = svc.UpdateSpec(ctx, &domain.Spec{ID: specID, Title: "", BasePriceMinor: 1000}, owner)
 	require.EqualError(t, err, "title is required")
 
-	err = svc.UpdateSpec(ctx, &domain.Spec{ID: specID, Title: "a", BasePrice: -1}, owner)
+	err = svc.UpdateSpec(ctx, &domain.Spec{ID: specID, Title: "a", BasePriceMinor: -100}, owner)
 	require.EqualError(t, err, "price cannot be negative")
 
-	err = svc.UpdateSpec(ctx, &domain.Spec{ID: specID, Title: "a", BasePrice: 1, Category: domain.CategoryBeat, BPM: 400}, owner)
+	err = svc.UpdateSpec(ctx, &domain.Spec{ID: specID, Title: "a", BasePriceMinor: 100, Category: domain.CategoryBeat, BPM: 400}, owner)
 	require.EqualError(t, err, "BPM must be between 60 and 300")
 }
 
@@ -248,15 +352,15 @@ func TestSpecService_UpdateSpecGuards(t *testing.T) {
 	}}
 	svc := NewSpecService(repo)
 
-	err := svc.UpdateSpec(context.Background(), &domain.Spec{ID: specID, Title: "x", BasePrice: 1}, other)
+	err := svc.UpdateSpec(context.Background(), &domain.Spec{ID: specID, Title: "x", BasePriceMinor: 100}, other)
 	require.EqualError(t, err, "unauthorized: you can only update your own specs")
 
 	svc = NewSpecService(mockRepo{getByIDFn: func(context.Context, uuid.UUID) (*domain.Spec, error) { return nil, nil }})
-	err = svc.UpdateSpec(context.Background(), &domain.Spec{ID: specID, Title: "x", BasePrice: 1}, other)
+	err = svc.UpdateSpec(context.Background(), &domain.Spec{ID: specID, Title: "x", BasePriceMinor: 100}, other)
 	require.EqualError(t, err, "spec not found")
 
 	svc = NewSpecService(mockRepo{getByIDFn: func(context.Context, uuid.UUID) (*domain.Spec, error) { return nil, errors.New("db") }})
-	err = svc.UpdateSpec(context.Background(), &domain.Spec{ID: specID, Title: "x", BasePrice: 1}, other)
+	err = svc.UpdateSpec(context.Background(), &domain.Spec{ID: specID, Title: "x", BasePriceMinor: 100}, other)
 	require.EqualError(t, err, "db")
 }
 
@@ -273,7 +377,7 @@ func TestSpecService_UpdateSpecRejectsInvalidCurrency(t *testing.T) {
 	}
 	svc := NewSpecService(repo)
 
-	err := svc.UpdateSpec(context.Background(), &domain.Spec{ID: specID, Title: "x", BasePrice: 1, Category: domain.CategorySample, PriceCurrency: "JPY"}, owner)
+	err := svc.UpdateSpec(context.Background(), &domain.Spec{ID: specID, Title: "x", BasePriceMinor: 100, Category: domain.CategorySample, PriceCurrency: "BRL"}, owner)
 	require.ErrorIs(t, err, domain.ErrInvalidCurrency)
 }
 
@@ -307,3 +411,32 @@ func TestLegacyGenreSlugPreservesRAndBCompatibility(t *testing.T) {
 	require.Equal(t, "r&b", legacyGenreSlug("R&B"))
 	require.Equal(t, "hip-hop", legacyGenreSlug("HIP-HOP"))
 }
+
+func TestSpecService_SuggestSearchNormalizesQuery(t *testing.T) {
+	var gotQuery string
+	var gotLimit int
+	svc := NewSpecService(mockRepo{
+		suggestFn: func(_ context.Context, query string, limit int) ([]domain.SearchSuggestion, error) {
+			gotQuery, gotLimit = query, limit
+			return []domain.SearchSuggestion{{Type: domain.SuggestionTag, Value: "trap", Score: 0.5}}, nil
+		},
+	})
+
+	out, err := svc.SuggestSearch(context.Background(), "  Dark   TRAPP ", 0)
+	require.NoError(t, err)
+	require.Len(t, out, 1)
+	assert.Equal(t, "dark trapp", gotQuery)
+	assert.Equal(t, defaultSuggestLimit, gotLimit)
+
+	_, err = svc.SuggestSearch(context.Background(), "trp", 500)
+	require.NoError(t, err)
+	assert.Equal(t, maxSuggestLimit, gotLimit)
+
+	gotQuery = ""
+	out, err = svc.SuggestSearch(context.Background(), " t ", 5)
+	require.NoError(t, err)
+	assert.Empty(t, out)
+	assert.Empty(t, gotQuery, "single-character queries should not reach the repository")
+
+	assert.Len(t, []rune(NormalizeSuggestQuery(strings.Repeat("é", 100))), maxSuggestQueryLength)
+}
@@ -288,6 +288,10 @@ func (s *specUploadService) prepareUploadSpec(
 	spec.StemsUrl = nil
 	spec.Duration = 0
 	spec.WaveformPeaks = nil
+	spec.SuggestedBPM = nil
+	spec.SuggestedBPMConfidence = nil
+	spec.SuggestedKey = nil
+	spec.SuggestedKeyConfidence = nil
 	spec.CreatedAt = time.Time{}
 	spec.UpdatedAt = time.Time{}
 	spec.DeletedAt = nil
@@ -351,7 +355,7 @@ func deriveBeatUploadMetadata(spec *domain.Spec) error {
 		return errors.New("at least one license is required")
 	}
 	currency := ""
-	minimumPrice := spec.Licenses[0].Price
+	minimumPrice := spec.Licenses[0].PriceMinor
 	for i := range spec.Licenses {
 		licenseCurrency, err := normalizeCurrency(spec.Licenses[i].PriceCurrency, "")
 		if err != nil {
@@ -363,11 +367,11 @@ func deriveBeatUploadMetadata(spec *domain.Spec) error {
 			return errors.New("all licenses must use the same currency")
 		}
 		spec.Licenses[i].PriceCurrency = licenseCurrency
-		if spec.Licenses[i].Price < minimumPrice {
-			minimumPrice = spec.Licenses[i].Price
+		if spec.Licenses[i].PriceMinor < minimumPrice {
+			minimumPrice = spec.Licenses[i].PriceMinor
 		}
 	}
-	spec.BasePrice = minimumPrice
+	spec.BasePriceMinor = minimumPrice
 	spec.PriceCurrency = currency
 	for i := range spec.Genres {
 		spec.Genres[i].Name = strings.ToUpper(strings.TrimSpace(spec.Genres[i].Name))
@@ -440,6 +444,10 @@ func (s *specUploadService) Complete(
 	spec.StemsUrl = nil
 	spec.Duration = 0
 	spec.WaveformPeaks = nil
+	spec.SuggestedBPM = nil
+	spec.SuggestedBPMConfidence = nil
+	spec.SuggestedKey = nil
+	spec.SuggestedKeyConfidence = nil
 
 	jobID, err := uuid.NewV7()
 	if err != nil {
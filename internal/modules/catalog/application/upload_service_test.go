@@ -34,6 +34,9 @@ type uploadRepositoryStub struct {
 	heartbeatJobFn func(context.Context, uuid.UUID, string) error
 	completeJobFn  func(context.Context, uuid.UUID, string, domain.ProcessedSpecFiles) error
 	failJobFn      func(context.Context, uuid.UUID, string, string) error
+	listUnpurgedFn func(context.Context, time.Time, int) ([]domain.SpecUploadSession, error)
+	markPurgedFn   func(context.Context, uuid.UUID) error
+	specFilesFn    func(context.Context, uuid.UUID) (bool, error)
 }
 
 func (s *uploadRepositoryStub) CreateSession(
@@ -154,6 +157,31 @@ func (s *uploadRepositoryStub) FailJob(
 	return s.failJobFn(ctx, jobID, workerID, reason)
 }
 
+func (s *uploadRepositoryStub) ListUnpurgedSessions(
+	ctx context.Context,
+	finishedBefore time.Time,
+	limit int,
+) ([]domain.SpecUploadSession, error) {
+	if s.listUnpurgedFn == nil {
+		return nil, errors.New("unexpected ListUnpurgedSessions call")
+	}
+	return s.listUnpurgedFn(ctx, finishedBefore, limit)
+}
+
+func (s *uploadRepositoryStub) MarkObjectsPurged(ctx context.Context, sessionID uuid.UUID) error {
+	if s.markPurgedFn == nil {
+		return errors.New("unexpected MarkObjectsPurged call")
+	}
+	return s.markPurgedFn(ctx, sessionID)
+}
+
+func (s *uploadRepositoryStub) SpecHasProcessedFiles(ctx context.Context, specID uuid.UUID) (bool, error) {
+	if s.specFilesFn == nil {
+		return false, errors.New("unexpected SpecHasProcessedFiles call")
+	}
+	return s.specFilesFn(ctx, specID)
+}
+
 type uploadSpecRepositoryStub struct {
 	domain.SpecRepository
 
@@ -383,7 +411,7 @@ func TestSpecUploadService_StagedDraftFlow(t *testing.T) {
 				{
 					LicenseType:   domain.LicenseBasic,
 					Name:          "Basic",
-					Price:         19,
+					PriceMinor:    1900,
 					PriceCurrency: "USD",
 					Features:      []string{},
 					FileTypes:     []string{"MP3"},
@@ -2,7 +2,6 @@ package application
 
 import (
 	"fmt"
-	"math"
 	"strings"
 	"unicode/utf8"
 
@@ -42,7 +41,7 @@ func validateSpec(spec *domain.Spec) error {
 	if utf8.RuneCountInString(spec.Description) > 500 {
 		return fmt.Errorf("description must be at most 500 characters")
 	}
-	if math.IsNaN(spec.BasePrice) || math.IsInf(spec.BasePrice, 0) || spec.BasePrice < 0 {
+	if spec.BasePriceMinor < 0 {
 		return fmt.Errorf("price cannot be negative")
 	}
 	if spec.Category != domain.CategoryBeat && spec.Category != domain.CategorySample {
@@ -89,7 +88,7 @@ func validateSpec(spec *domain.Spec) error {
 		if n := utf8.RuneCountInString(strings.TrimSpace(license.Name)); n < 1 || n > 100 {
 			return fmt.Errorf("license name must be between 1 and 100 characters")
 		}
-		if math.IsNaN(license.Price) || math.IsInf(license.Price, 0) || license.Price < 0 {
+		if license.PriceMinor < 0 {
 			return fmt.Errorf("license price cannot be negative")
 		}
 		if err := validateStringList(
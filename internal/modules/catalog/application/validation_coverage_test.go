@@ -1,7 +1,6 @@
 package application
 
 import (
-	"math"
 	"strings"
 	"testing"
 
@@ -11,19 +10,19 @@ import (
 
 func validBeatForValidation() domain.Spec {
 	return domain.Spec{
-		Title:       "Valid beat",
-		Category:    domain.CategoryBeat,
-		BasePrice:   10,
-		BPM:         120,
-		Key:         "C MAJOR",
-		Tags:        []string{"tag"},
-		Moods:       []string{"Dark"},
-		Instruments: []string{"Piano"},
-		Genres:      []domain.Genre{{Name: "TRAP"}},
+		Title:          "Valid beat",
+		Category:       domain.CategoryBeat,
+		BasePriceMinor: 1000,
+		BPM:            120,
+		Key:            "C MAJOR",
+		Tags:           []string{"tag"},
+		Moods:          []string{"Dark"},
+		Instruments:    []string{"Piano"},
+		Genres:         []domain.Genre{{Name: "TRAP"}},
 		Licenses: []domain.LicenseOption{{
 			LicenseType: domain.LicenseBasic,
 			Name:        "Basic",
-			Price:       10,
+			PriceMinor:  1000,
 		}},
 	}
 }
@@ -36,8 +35,7 @@ func TestValidateSpecCoversEveryValidationRule(t *testing.T) {
 	}{
 		{"title too long", func(s *domain.Spec) { s.Title = strings.Repeat("x", 101) }, "title must be at most 100 characters"},
 		{"description too long", func(s *domain.Spec) { s.Description = strings.Repeat("x", 501) }, "description must be at most 500 characters"},
-		{"nan price", func(s *domain.Spec) { s.BasePrice = math.NaN() }, "price cannot be negative"},
-		{"infinite price", func(s *domain.Spec) { s.BasePrice = math.Inf(1) }, "price cannot be negative"},
+		{"negative price", func(s *domain.Spec) { s.BasePriceMinor = -1 }, "price cannot be negative"},
 		{"invalid category", func(s *domain.Spec) { s.Category = domain.Category("other") }, "invalid category"},
 		{"too many tags", func(s *domain.Spec) { s.Tags = []string{"a", "b", "c", "d"} }, "maximum 3 tags allowed"},
 		{"empty tag", func(s *domain.Spec) { s.Tags = []string{" "} }, "invalid tags"},
@@ -56,8 +54,7 @@ func TestValidateSpecCoversEveryValidationRule(t *testing.T) {
 		{"duplicate license", func(s *domain.Spec) { s.Licenses = append(s.Licenses, s.Licenses[0]) }, "duplicate license type"},
 		{"empty license name", func(s *domain.Spec) { s.Licenses[0].Name = " " }, "license name must be between 1 and 100 characters"},
 		{"long license name", func(s *domain.Spec) { s.Licenses[0].Name = strings.Repeat("x", 101) }, "license name must be between 1 and 100 characters"},
-		{"nan license price", func(s *domain.Spec) { s.Licenses[0].Price = math.NaN() }, "license price cannot be negative"},
-		{"infinite license price", func(s *domain.Spec) { s.Licenses[0].Price = math.Inf(-1) }, "license price cannot be negative"},
+		{"negative license price", func(s *domain.Spec) { s.Licenses[0].PriceMinor = -1 }, "license price cannot be negative"},
 		{"too many license features", func(s *domain.Spec) {
 			s.Licenses[0].Features = make([]string, maxLicenseFeatures+1)
 			for i := range s.Licenses[0].Features {
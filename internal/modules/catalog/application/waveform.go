@@ -12,14 +12,19 @@ import (
 type MP3Analysis struct {
 	WaveformPeaks []int64
 	Duration      int
+	// Tempo and Key are nil when the preview is too short, silent or lacks
+	// enough rhythmic or tonal content to produce a meaningful suggestion.
+	Tempo *TempoEstimate
+	Key   *KeyEstimate
 }
 
 const maxPreviewDurationSeconds = 30 * 60
 
-// AnalyzeMP3 decodes an MP3 once, deriving both its duration and normalized
-// waveform without retaining the decoded PCM in memory. It intentionally does
-// not use Decoder.Length because object-storage response bodies are streams,
-// not io.Seeker values, and go-mp3 reports an unknown length for them.
+// AnalyzeMP3 decodes an MP3 once, deriving its duration, normalized waveform
+// and tempo/key suggestions without retaining the decoded PCM in memory. It
+// intentionally does not use Decoder.Length because object-storage response
+// bodies are streams, not io.Seeker values, and go-mp3 reports an unknown
+// length for them.
 func AnalyzeMP3(source io.Reader, barCount int) (MP3Analysis, error) {
 	if barCount <= 0 || barCount > 512 {
 		return MP3Analysis{}, fmt.Errorf("invalid waveform bar count")
@@ -45,6 +50,7 @@ func AnalyzeMP3(source io.Reader, barCount int) (MP3Analysis, error) {
 	chunks := make([]waveformChunk, 0, 512)
 	var chunk waveformChunk
 	var totalFrames int64
+	features := newAudioFeatureExtractor(sampleRate)
 
 	// Preserve incomplete stereo frames across decoder.Read calls.
 	buffer := make([]byte, 32*1024+3)
@@ -83,6 +89,7 @@ func AnalyzeMP3(source io.Reader, barCount int) (MP3Analysis, error) {
 		for offset := 0; offset < complete; offset += 4 {
 			left := int64(int16(binary.LittleEndian.Uint16(buffer[offset : offset+2])))
 			right := int64(int16(binary.LittleEndian.Uint16(buffer[offset+2 : offset+4])))
+			features.Add(float64(left+right) / (2 * 32768))
 			if left < 0 {
 				left = -left
 			}
@@ -154,7 +161,12 @@ func AnalyzeMP3(source io.Reader, barCount int) (MP3Analysis, error) {
 	if duration < 1 {
 		duration = 1
 	}
-	return MP3Analysis{WaveformPeaks: result, Duration: duration}, nil
+	return MP3Analysis{
+		WaveformPeaks: result,
+		Duration:      duration,
+		Tempo:         features.Tempo(),
+		Key:           features.Key(),
+	}, nil
 }
 
 func validateDecodedMP3FrameCount(frameCount int64, sampleRate int) error {
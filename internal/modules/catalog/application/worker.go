@@ -17,13 +17,7 @@ func StartUploadWorker(
 	processor *SpecUploadProcessor,
 	cfg config.WorkerConfig,
 ) {
-	workerPrefix := strings.TrimSpace(cfg.ID)
-	if workerPrefix == "" {
-		host, _ := os.Hostname()
-		workerPrefix = host
-	}
-	workerPrefix = NormalizeWorkerPrefix(workerPrefix)
-	workerID := workerPrefix + "-" + uuid.NewString()
+	workerID := WorkerName(cfg) + "-" + uuid.NewString()
 
 	pollInterval := cfg.PollInterval
 	if pollInterval <= 0 {
@@ -43,12 +37,6 @@ func StartUploadWorker(
 	for ctx.Err() == nil {
 		now := time.Now()
 		if nextRequeue.IsZero() || now.After(nextRequeue) {
-			expired, err := processor.ExpireUploads(ctx)
-			if err != nil && ctx.Err() == nil {
-				log.Printf("expire abandoned upload sessions: %v", err)
-			} else if expired > 0 {
-				log.Printf("expired %d abandoned upload sessions", expired)
-			}
 			count, err := processor.RequeueStale(ctx, lease)
 			if err != nil && ctx.Err() == nil {
 				log.Printf("requeue stale upload jobs: %v", err)
@@ -75,6 +63,16 @@ func StartUploadWorker(
 	log.Printf("spec upload worker stopped (id=%s)", workerID)
 }
 
+// WorkerName names this process in job claims and run history: the configured
+// worker ID, or the host name when none is set.
+func WorkerName(cfg config.WorkerConfig) string {
+	name := strings.TrimSpace(cfg.ID)
+	if name == "" {
+		name, _ = os.Hostname()
+	}
+	return NormalizeWorkerPrefix(name)
+}
+
 func NormalizeWorkerPrefix(value string) string {
 	const maxPrefixLength = 83 // 120-column limit minus "-" and UUID.
 	var normalized strings.Builder
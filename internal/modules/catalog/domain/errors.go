@@ -17,4 +17,7 @@ var (
 	ErrUploadExpired   = errors.New("upload session expired")
 	ErrUploadState     = errors.New("upload session is not in the required state")
 	ErrNoProcessingJob = errors.New("no processing job available")
+
+	ErrRankingProfileNotFound = errors.New("ranking profile not found")
+	ErrInvalidRankingProfile  = errors.New("invalid ranking profile")
 )
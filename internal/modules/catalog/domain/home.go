@@ -3,6 +3,8 @@ package domain
 import (
 	"encoding/json"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 const (
@@ -10,6 +12,7 @@ const (
 	HomeSectionTrending    = "trending"
 	HomeSectionTopCharts   = "top_charts"
 	HomeSectionNewReleases = "new_releases"
+	HomeSectionForYou      = "for_you"
 
 	HomePeriod24H = "24h"
 	HomePeriod7D  = "7d"
@@ -20,6 +23,9 @@ type HomepageParams struct {
 	Limit    int
 	Sections []string
 	Period   string
+	// UserID is the signed-in visitor, if any; the for_you section is only
+	// built for them.
+	UserID *uuid.UUID
 }
 
 type HomepageStats struct {
@@ -28,6 +34,9 @@ type HomepageStats struct {
 	TotalProducers int `json:"total_producers" db:"total_producers"`
 }
 
+// BeatRankingMetrics are the signals a beat was ranked on. Contributions
+// breaks the score down into the points each signal added; together with
+// Decay they explain the score: (sum of contributions) * decay.
 type BeatRankingMetrics struct {
 	Plays           int     `json:"plays"`
 	UniqueListeners int     `json:"unique_listeners,omitempty"`
@@ -35,6 +44,12 @@ type BeatRankingMetrics struct {
 	Downloads       int     `json:"downloads"`
 	Purchases       int     `json:"purchases"`
 	Revenue         float64 `json:"revenue"`
+
+	// ProfileID and ProfileName are empty when the built-in formula was used.
+	ProfileID     *uuid.UUID         `json:"profile_id,omitempty"`
+	ProfileName   string             `json:"profile_name,omitempty"`
+	Contributions map[string]float64 `json:"contributions,omitempty"`
+	Decay         *float64           `json:"decay,omitempty"`
 }
 
 type RankedSpec struct {
@@ -46,11 +61,6 @@ type RankedSpec struct {
 	CalculatedAt time.Time
 }
 
-type RankingFreshness struct {
-	Count        int
-	CalculatedAt time.Time
-}
-
 type RankingRow struct {
 	Spec         Spec
 	Rank         int
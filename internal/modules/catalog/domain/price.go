@@ -0,0 +1,78 @@
+package domain
+
+import (
+	"encoding/json"
+
+	"github.com/saransh1220/blueprint-audio/internal/shared/money"
+)
+
+// BasePrice returns the spec's starting price in its own currency.
+func (s Spec) BasePrice() money.Amount {
+	return money.NewAmount(s.BasePriceMinor, s.PriceCurrency)
+}
+
+// Price returns the license price in its own currency.
+func (l LicenseOption) Price() money.Amount {
+	return money.NewAmount(l.PriceMinor, l.PriceCurrency)
+}
+
+// Prices used to be major unit floats under "price". Until every client sends
+// "price_minor", specs and licenses are written with both fields and read from
+// either, preferring "price_minor".
+
+func (s Spec) MarshalJSON() ([]byte, error) {
+	type spec Spec
+	return json.Marshal(struct {
+		spec
+		Price float64 `json:"price"`
+	}{spec(s), s.BasePrice().Major()})
+}
+
+func (s *Spec) UnmarshalJSON(data []byte) error {
+	type spec Spec
+	aux := struct {
+		*spec
+		PriceMinor *int64   `json:"price_minor"`
+		Price      *float64 `json:"price"`
+	}{spec: (*spec)(s)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	s.BasePriceMinor = PriceMinor(aux.PriceMinor, aux.Price, s.PriceCurrency)
+	return nil
+}
+
+func (l LicenseOption) MarshalJSON() ([]byte, error) {
+	type licenseOption LicenseOption
+	return json.Marshal(struct {
+		licenseOption
+		Price float64 `json:"price"`
+	}{licenseOption(l), l.Price().Major()})
+}
+
+func (l *LicenseOption) UnmarshalJSON(data []byte) error {
+	type licenseOption LicenseOption
+	aux := struct {
+		*licenseOption
+		PriceMinor *int64   `json:"price_minor"`
+		Price      *float64 `json:"price"`
+	}{licenseOption: (*licenseOption)(l)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	l.PriceMinor = PriceMinor(aux.PriceMinor, aux.Price, l.PriceCurrency)
+	return nil
+}
+
+// PriceMinor resolves a price sent either in minor units or, by older
+// clients, as a major unit amount in currency.
+func PriceMinor(minor *int64, major *float64, currency string) int64 {
+	switch {
+	case minor != nil:
+		return *minor
+	case major != nil:
+		return money.AmountFromMajor(*major, currency).Minor
+	default:
+		return 0
+	}
+}
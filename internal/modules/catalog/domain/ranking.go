@@ -0,0 +1,67 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RankingWeights are the per-signal multipliers of a ranking profile. Each
+// signal is counted over the ranking period; revenue is in major units.
+type RankingWeights struct {
+	Plays           float64 `json:"plays" db:"plays_weight"`
+	UniqueListeners float64 `json:"unique_listeners" db:"unique_listeners_weight"`
+	Favorites       float64 `json:"favorites" db:"favorites_weight"`
+	Downloads       float64 `json:"downloads" db:"downloads_weight"`
+	Purchases       float64 `json:"purchases" db:"purchases_weight"`
+	Revenue         float64 `json:"revenue" db:"revenue_weight"`
+}
+
+// RankingProfile replaces the built-in scoring formula of a homepage section
+// while it is active. At most one profile per section is active at a time.
+type RankingProfile struct {
+	ID             uuid.UUID `json:"id" db:"id"`
+	Name           string    `json:"name" db:"name"`
+	Section        string    `json:"section" db:"section"`
+	RankingWeights `json:"weights"`
+	// RecencyHalfLifeHours halves a beat's score every time it ages by this
+	// many hours since release. Zero disables decay.
+	RecencyHalfLifeHours float64 `json:"recency_half_life_hours" db:"recency_half_life_hours"`
+	// MaxPerProducer caps how many beats of one producer are ranked. Zero
+	// means no cap.
+	MaxPerProducer int        `json:"max_per_producer" db:"max_per_producer"`
+	IsActive       bool       `json:"is_active" db:"is_active"`
+	CreatedBy      *uuid.UUID `json:"created_by,omitempty" db:"created_by"`
+	ActivatedBy    *uuid.UUID `json:"activated_by,omitempty" db:"activated_by"`
+	ActivatedAt    *time.Time `json:"activated_at,omitempty" db:"activated_at"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// RankingPreviewRow compares a beat's rank under a profile with its rank in
+// the stored beat_rankings. Either rank is nil when the beat is only on one
+// side of the comparison.
+type RankingPreviewRow struct {
+	SpecID        uuid.UUID
+	Title         string
+	ProducerID    uuid.UUID
+	ProposedRank  *int
+	ProposedScore *float64
+	CurrentRank   *int
+	CurrentScore  *float64
+	MetricsJSON   []byte
+}
+
+// RankingProfileRepository stores ranking profiles and evaluates them against
+// the live analytics without touching beat_rankings.
+type RankingProfileRepository interface {
+	CreateRankingProfile(ctx context.Context, profile *RankingProfile) error
+	GetRankingProfile(ctx context.Context, id uuid.UUID) (*RankingProfile, error)
+	ListRankingProfiles(ctx context.Context, section string) ([]RankingProfile, error)
+	GetActiveRankingProfile(ctx context.Context, section string) (*RankingProfile, error)
+	ActivateRankingProfile(ctx context.Context, id, actorID uuid.UUID) (*RankingProfile, error)
+	DeactivateRankingProfile(ctx context.Context, id uuid.UUID) (*RankingProfile, error)
+	PreviewRankingProfile(ctx context.Context, profile *RankingProfile, period string, limit int) ([]RankingPreviewRow, error)
+	RecalculateBeatRankings(ctx context.Context, section, period string) error
+}
@@ -0,0 +1,22 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RecommendationRepository rebuilds the stored "For you" recommendations.
+type RecommendationRepository interface {
+	// ListRecommendationUsers returns the users who played, favorited or
+	// bought a beat since activeSince.
+	ListRecommendationUsers(ctx context.Context, activeSince time.Time) ([]uuid.UUID, error)
+	// RebuildRecommendationSignals recalculates the beat co-occurrence and
+	// popularity every user's recommendations are scored against.
+	RebuildRecommendationSignals(ctx context.Context) error
+	// RecalculateUserRecommendations replaces the user's stored
+	// recommendations with at most limit beats and returns how many were
+	// stored.
+	RecalculateUserRecommendations(ctx context.Context, userID uuid.UUID, limit int) (int, error)
+}
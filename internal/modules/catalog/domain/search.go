@@ -0,0 +1,23 @@
+package domain
+
+import "github.com/google/uuid"
+
+type SearchSuggestionType string
+
+const (
+	SuggestionSpec     SearchSuggestionType = "spec"
+	SuggestionProducer SearchSuggestionType = "producer"
+	SuggestionGenre    SearchSuggestionType = "genre"
+	SuggestionTag      SearchSuggestionType = "tag"
+	SuggestionMood     SearchSuggestionType = "mood"
+)
+
+// SearchSuggestion is one autocomplete entry for the catalog search bar.
+// ID is set for specs and producers, Slug for specs and genres.
+type SearchSuggestion struct {
+	Type  SearchSuggestionType `json:"type" db:"type"`
+	Value string               `json:"value" db:"value"`
+	ID    *uuid.UUID           `json:"id,omitempty" db:"id"`
+	Slug  *string              `json:"slug,omitempty" db:"slug"`
+	Score float64              `json:"score" db:"score"`
+}
@@ -37,7 +37,7 @@ type Spec struct {
 	PreviewUrl     string         `json:"preview_url" db:"preview_url"`
 	WavUrl         *string        `json:"wav_url,omitempty" db:"wav_url"`
 	StemsUrl       *string        `json:"stems_url,omitempty" db:"stems_url"`
-	BasePrice      float64        `json:"price" db:"base_price"`
+	BasePriceMinor int64          `json:"price_minor" db:"base_price_minor"`
 	PriceCurrency  string         `json:"price_currency" db:"price_currency"`
 	Description    string         `json:"description" db:"description"`
 	Duration       int            `json:"duration" db:"duration"`
@@ -53,6 +53,13 @@ type Spec struct {
 	ShortCode      *string        `json:"short_code" db:"short_code"`
 	ProducerHandle string         `json:"producer_handle" db:"producer_handle"`
 
+	// Suggested tempo and key detected from the preview audio. They sit beside
+	// the producer's declared BPM and Key and never replace them.
+	SuggestedBPM           *int     `json:"suggested_bpm,omitempty" db:"suggested_bpm"`
+	SuggestedBPMConfidence *float64 `json:"suggested_bpm_confidence,omitempty" db:"suggested_bpm_confidence"`
+	SuggestedKey           *string  `json:"suggested_key,omitempty" db:"suggested_key"`
+	SuggestedKeyConfidence *float64 `json:"suggested_key_confidence,omitempty" db:"suggested_key_confidence"`
+
 	// Processing Status
 	ProcessingStatus ProcessingStatus `json:"processing_status" db:"processing_status"`
 
@@ -60,6 +67,16 @@ type Spec struct {
 	Licenses []LicenseOption `json:"licenses,omitempty"`
 	Genres   []Genre         `json:"genres,omitempty"`
 	Tags     pq.StringArray  `json:"tags,omitempty" db:"tags"`
+
+	// Highlights is only set on specs returned by a full-text search.
+	Highlights *SearchHighlights `json:"highlights,omitempty" db:"-"`
+}
+
+// SearchHighlights holds HTML-escaped snippets of the fields that matched a
+// search, with each matched term wrapped in <mark></mark>.
+type SearchHighlights struct {
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
 }
 
 type ProcessingStatus string
@@ -77,7 +94,7 @@ type LicenseOption struct {
 	SpecID        uuid.UUID      `json:"spec_id" db:"spec_id"`
 	LicenseType   LicenseType    `json:"type" db:"license_type"`
 	Name          string         `json:"name" db:"name"`
-	Price         float64        `json:"price" db:"price"`
+	PriceMinor    int64          `json:"price_minor" db:"price_minor"`
 	PriceCurrency string         `json:"price_currency" db:"price_currency"`
 	Features      pq.StringArray `json:"features" db:"features"`
 	FileTypes     pq.StringArray `json:"file_types" db:"file_types"`
@@ -102,8 +119,8 @@ type SpecFilter struct {
 	Search      string
 	MinBPM      int
 	MaxBPM      int
-	MinPrice    float64
-	MaxPrice    float64
+	MinPrice    int64 // minor units
+	MaxPrice    int64 // minor units
 	Key         string
 	Limit       int
 	Offset      int
@@ -130,8 +147,11 @@ type SpecRepository interface {
 	GetHomepageStats(ctx context.Context) (*HomepageStats, error)
 	GetNewestBeats(ctx context.Context, limit int) ([]Spec, error)
 	GetRankedSpecs(ctx context.Context, section, period string, limit int) ([]RankingRow, error)
-	GetRankingFreshness(ctx context.Context, section, period string) (*RankingFreshness, error)
+	// GetRecommendedSpecs returns the user's stored "For you" beats in rank
+	// order, leaving out any bought since they were calculated.
+	GetRecommendedSpecs(ctx context.Context, userID uuid.UUID, limit int) ([]RankingRow, error)
 	RecalculateBeatRankings(ctx context.Context, section, period string) error
+	SuggestSearch(ctx context.Context, query string, limit int) ([]SearchSuggestion, error)
 }
 
 // SpecFinder provides spec lookup capabilities for other modules (Payment, Analytics)
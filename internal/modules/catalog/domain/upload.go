@@ -96,6 +96,17 @@ type ProcessedSpecFiles struct {
 	StemsURL      *string
 	Duration      int
 	WaveformPeaks pq.Int64Array
+	Suggestions   AudioSuggestions
+}
+
+// AudioSuggestions holds the tempo and key estimated from the decoded preview.
+// Each value is nil when the audio did not support a meaningful estimate, and
+// confidences are in the range [0, 1].
+type AudioSuggestions struct {
+	BPM           *int
+	BPMConfidence *float64
+	Key           *string
+	KeyConfidence *float64
 }
 
 type SpecUploadStatus struct {
@@ -105,6 +116,9 @@ type SpecUploadStatus struct {
 	Status           UploadStatus
 	ProcessingStatus ProcessingStatus
 	ErrorMessage     *string
+	DeclaredBPM      *int
+	DeclaredKey      *string
+	Suggestions      AudioSuggestions
 	CreatedAt        time.Time
 	UpdatedAt        time.Time
 	ExpiresAt        time.Time
@@ -133,6 +147,11 @@ type SpecUploadRepository interface {
 	MarkSessionFailed(ctx context.Context, uploadID uuid.UUID, reason string) error
 	FinalizeUpload(ctx context.Context, session *SpecUploadSession, spec *Spec, job *SpecProcessingJob) error
 	ExpireUploadSessions(ctx context.Context, expiredBefore time.Time) (int64, error)
+	ListUnpurgedSessions(ctx context.Context, finishedBefore time.Time, limit int) ([]SpecUploadSession, error)
+	MarkObjectsPurged(ctx context.Context, sessionID uuid.UUID) error
+	// SpecHasProcessedFiles reports whether the spec row points at processed
+	// files, whose objects must outlive the upload session.
+	SpecHasProcessedFiles(ctx context.Context, specID uuid.UUID) (bool, error)
 	RequeueStaleJobs(ctx context.Context, staleBefore time.Time) (int64, error)
 	ClaimNextJob(ctx context.Context, workerID string) (*ProcessingBundle, error)
 	HeartbeatJob(ctx context.Context, jobID uuid.UUID, workerID string) error
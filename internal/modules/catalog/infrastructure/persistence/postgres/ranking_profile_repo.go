@@ -0,0 +1,368 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+
+	"github.com/google/uuid"
+	"github.com/saransh1220/blueprint-audio/internal/modules/catalog/domain"
+)
+
+// profileRankingQuery scores every live beat with a ranking profile and ends
+// in a "ranked" CTE, so recalculation and preview only differ in what they do
+// with it. Parameters: $1 interval, $2-$7 signal weights, $8 recency half-life
+// in hours, $9 per-producer cap, $10 profile id, $11 profile name.
+//
+// The per-producer cap is applied before the overall rank so a capped
+// producer's beats leave no gaps in the ranking.
+const profileRankingQuery = `
+	WITH event_metrics AS (
+		SELECT
+			s.id AS spec_id,
+			COUNT(*) FILTER (WHERE ae.event_type = 'play') AS plays,
+			COUNT(DISTINCT ae.user_id) FILTER (WHERE ae.user_id IS NOT NULL) AS unique_listeners,
+			COUNT(*) FILTER (WHERE ae.event_type = 'favorite') AS favorites,
+			COUNT(*) FILTER (WHERE ae.event_type = 'download') AS downloads
+		FROM specs s
+		LEFT JOIN analytics_events ae
+			ON ae.spec_id = s.id
+		   AND ae.created_at >= NOW() - $1::interval
+		WHERE s.category = 'beat'
+		  AND s.processing_status = 'completed'
+		  AND s.is_deleted = FALSE
+		GROUP BY s.id
+	),
+	order_metrics AS (
+		SELECT
+			s.id AS spec_id,
+			COUNT(oi.id) AS purchases,
+			COALESCE(SUM(oi.amount), 0) / 100.0 AS revenue
+		FROM specs s
+		LEFT JOIN (order_items oi
+			JOIN orders o
+				ON o.id = oi.order_id
+			   AND o.status = 'paid'
+			   AND o.created_at >= NOW() - $1::interval)
+			ON oi.spec_id = s.id
+		WHERE s.category = 'beat'
+		  AND s.processing_status = 'completed'
+		  AND s.is_deleted = FALSE
+		GROUP BY s.id
+	),
+	weighted AS (
+		SELECT
+			s.id AS spec_id,
+			s.producer_id,
+			s.created_at,
+			em.plays,
+			em.unique_listeners,
+			em.favorites,
+			em.downloads,
+			om.purchases,
+			om.revenue,
+			em.plays * $2::float8 AS plays_points,
+			em.unique_listeners * $3::float8 AS unique_listeners_points,
+			em.favorites * $4::float8 AS favorites_points,
+			em.downloads * $5::float8 AS downloads_points,
+			om.purchases * $6::float8 AS purchases_points,
+			om.revenue::float8 * $7::float8 AS revenue_points,
+			CASE
+				WHEN $8::float8 > 0
+				THEN POWER(0.5, GREATEST(EXTRACT(EPOCH FROM (NOW() - s.created_at))::float8, 0) / 3600.0 / $8::float8)
+				ELSE 1
+			END AS decay
+		FROM specs s
+		JOIN event_metrics em ON em.spec_id = s.id
+		JOIN order_metrics om ON om.spec_id = s.id
+	),
+	scored AS (
+		SELECT
+			weighted.*,
+			(
+				plays_points
+				+ unique_listeners_points
+				+ favorites_points
+				+ downloads_points
+				+ purchases_points
+				+ revenue_points
+			) * decay AS score
+		FROM weighted
+	),
+	capped AS (
+		SELECT
+			scored.*,
+			ROW_NUMBER() OVER (
+				PARTITION BY producer_id
+				ORDER BY score DESC, purchases DESC, plays DESC, created_at DESC, spec_id
+			) AS producer_rank
+		FROM scored
+		WHERE score > 0
+	),
+	ranked AS (
+		SELECT
+			spec_id,
+			ROW_NUMBER() OVER (
+				ORDER BY score DESC, purchases DESC, plays DESC, created_at DESC, spec_id
+			) AS rank,
+			score,
+			jsonb_build_object(
+				'plays', plays,
+				'unique_listeners', unique_listeners,
+				'favorites', favorites,
+				'downloads', downloads,
+				'purchases', purchases,
+				'revenue', revenue,
+				'profile_id', $10::text,
+				'profile_name', $11::text,
+				'contributions', jsonb_build_object(
+					'plays', plays_points,
+					'unique_listeners', unique_listeners_points,
+					'favorites', favorites_points,
+					'downloads', downloads_points,
+					'purchases', purchases_points,
+					'revenue', revenue_points
+				),
+				'decay', decay
+			) AS metrics
+		FROM capped
+		WHERE $9::int = 0 OR producer_rank <= $9::int
+	)`
+
+func profileRankingArgs(profile *domain.RankingProfile, interval string) []any {
+	return []any{
+		interval,
+		profile.Plays,
+		profile.UniqueListeners,
+		profile.Favorites,
+		profile.Downloads,
+		profile.Purchases,
+		profile.Revenue,
+		profile.RecencyHalfLifeHours,
+		profile.MaxPerProducer,
+		profile.ID.String(),
+		profile.Name,
+	}
+}
+
+func (r *PgSpecRepository) CreateRankingProfile(ctx context.Context, profile *domain.RankingProfile) error {
+	query := `
+		INSERT INTO ranking_profiles (
+			name, section, plays_weight, unique_listeners_weight, favorites_weight,
+			downloads_weight, purchases_weight, revenue_weight,
+			recency_half_life_hours, max_per_producer, created_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING *`
+	return r.db.GetContext(ctx, profile, query,
+		profile.Name,
+		profile.Section,
+		profile.Plays,
+		profile.UniqueListeners,
+		profile.Favorites,
+		profile.Downloads,
+		profile.Purchases,
+		profile.Revenue,
+		profile.RecencyHalfLifeHours,
+		profile.MaxPerProducer,
+		profile.CreatedBy,
+	)
+}
+
+func (r *PgSpecRepository) GetRankingProfile(ctx context.Context, id uuid.UUID) (*domain.RankingProfile, error) {
+	var profile domain.RankingProfile
+	if err := r.db.GetContext(ctx, &profile, `SELECT * FROM ranking_profiles WHERE id = $1`, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrRankingProfileNotFound
+		}
+		return nil, err
+	}
+	return &profile, nil
+}
+
+func (r *PgSpecRepository) ListRankingProfiles(ctx context.Context, section string) ([]domain.RankingProfile, error) {
+	profiles := []domain.RankingProfile{}
+	query := `
+		SELECT * FROM ranking_profiles
+		WHERE ($1 = '' OR section = $1)
+		ORDER BY section, is_active DESC, created_at DESC`
+	if err := r.db.SelectContext(ctx, &profiles, query, section); err != nil {
+		return nil, err
+	}
+	return profiles, nil
+}
+
+// GetActiveRankingProfile returns nil when the section uses its built-in
+// formula.
+func (r *PgSpecRepository) GetActiveRankingProfile(ctx context.Context, section string) (*domain.RankingProfile, error) {
+	var profile domain.RankingProfile
+	if err := r.db.GetContext(ctx, &profile, `SELECT * FROM ranking_profiles WHERE section = $1 AND is_active`, section); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &profile, nil
+}
+
+// ActivateRankingProfile makes the profile the only active one of its section.
+func (r *PgSpecRepository) ActivateRankingProfile(ctx context.Context, id, actorID uuid.UUID) (*domain.RankingProfile, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var section string
+	if err := tx.GetContext(ctx, &section, `SELECT section FROM ranking_profiles WHERE id = $1 FOR UPDATE`, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrRankingProfileNotFound
+		}
+		return nil, err
+	}
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE ranking_profiles
+		SET is_active = FALSE, updated_at = NOW()
+		WHERE section = $1 AND is_active AND id <> $2`, section, id); err != nil {
+		return nil, err
+	}
+
+	var profile domain.RankingProfile
+	query := `
+		UPDATE ranking_profiles
+		SET is_active = TRUE, activated_by = $2, activated_at = NOW(), updated_at = NOW()
+		WHERE id = $1
+		RETURNING *`
+	if err := tx.GetContext(ctx, &profile, query, id, actorID); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	log.Printf("[CatalogRepo.Ranking] activated profile=%s section=%s actor=%s", id, section, actorID)
+	return &profile, nil
+}
+
+func (r *PgSpecRepository) DeactivateRankingProfile(ctx context.Context, id uuid.UUID) (*domain.RankingProfile, error) {
+	var profile domain.RankingProfile
+	query := `
+		UPDATE ranking_profiles
+		SET is_active = FALSE, updated_at = NOW()
+		WHERE id = $1
+		RETURNING *`
+	if err := r.db.GetContext(ctx, &profile, query, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrRankingProfileNotFound
+		}
+		return nil, err
+	}
+	log.Printf("[CatalogRepo.Ranking] deactivated profile=%s section=%s", id, profile.Section)
+	return &profile, nil
+}
+
+// PreviewRankingProfile ranks with the profile without writing anything and
+// returns every beat in the top limit of either the proposed or the stored
+// ranking, so beats that would drop out are listed too.
+func (r *PgSpecRepository) PreviewRankingProfile(ctx context.Context, profile *domain.RankingProfile, period string, limit int) ([]domain.RankingPreviewRow, error) {
+	interval, err := rankingInterval(period)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []struct {
+		SpecID        uuid.UUID       `db:"spec_id"`
+		Title         string          `db:"title"`
+		ProducerID    uuid.UUID       `db:"producer_id"`
+		ProposedRank  sql.NullInt64   `db:"proposed_rank"`
+		ProposedScore sql.NullFloat64 `db:"proposed_score"`
+		CurrentRank   sql.NullInt64   `db:"current_rank"`
+		CurrentScore  sql.NullFloat64 `db:"current_score"`
+		MetricsJSON   []byte          `db:"metrics"`
+	}
+	query := profileRankingQuery + `,
+	current_rankings AS (
+		SELECT spec_id, rank, score
+		FROM beat_rankings
+		WHERE section = $12::text
+		  AND period = $13::text
+		  AND ($12::text <> 'top_charts' OR metrics->>'algorithm_version' = '3' OR metrics->>'profile_id' IS NOT NULL)
+	)
+	SELECT
+		s.id AS spec_id,
+		s.title,
+		s.producer_id,
+		ranked.rank AS proposed_rank,
+		ranked.score AS proposed_score,
+		cr.rank AS current_rank,
+		cr.score::float8 AS current_score,
+		ranked.metrics
+	FROM ranked
+	FULL OUTER JOIN current_rankings cr ON cr.spec_id = ranked.spec_id
+	JOIN specs s ON s.id = COALESCE(ranked.spec_id, cr.spec_id)
+	WHERE ranked.rank <= $14 OR cr.rank <= $14
+	ORDER BY ranked.rank ASC NULLS LAST, cr.rank ASC`
+
+	args := append(profileRankingArgs(profile, interval), profile.Section, period, limit)
+	if err := r.db.SelectContext(ctx, &rows, query, args...); err != nil {
+		log.Printf("[CatalogRepo.Ranking] preview failed profile=%s period=%s err=%v", profile.ID, period, err)
+		return nil, err
+	}
+
+	result := make([]domain.RankingPreviewRow, len(rows))
+	for i, row := range rows {
+		result[i] = domain.RankingPreviewRow{
+			SpecID:      row.SpecID,
+			Title:       row.Title,
+			ProducerID:  row.ProducerID,
+			MetricsJSON: row.MetricsJSON,
+		}
+		if row.ProposedRank.Valid {
+			rank := int(row.ProposedRank.Int64)
+			score := row.ProposedScore.Float64
+			result[i].ProposedRank = &rank
+			result[i].ProposedScore = &score
+		}
+		if row.CurrentRank.Valid {
+			rank := int(row.CurrentRank.Int64)
+			score := row.CurrentScore.Float64
+			result[i].CurrentRank = &rank
+			result[i].CurrentScore = &score
+		}
+	}
+	log.Printf("[CatalogRepo.Ranking] preview profile=%s period=%s rows=%d", profile.ID, period, len(result))
+	return result, nil
+}
+
+func (r *PgSpecRepository) recalculateProfileRankings(ctx context.Context, profile *domain.RankingProfile, period, interval string) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err = lockRankingRefresh(ctx, tx, profile.Section, period); err != nil {
+		return err
+	}
+
+	query := profileRankingQuery + `,
+	old AS (
+		SELECT spec_id, rank
+		FROM beat_rankings
+		WHERE section = $12::text AND period = $13::text
+	),
+	deleted AS (
+		DELETE FROM beat_rankings
+		WHERE section = $12::text AND period = $13::text
+	)
+	INSERT INTO beat_rankings (section, period, spec_id, rank, score, previous_rank, metrics, calculated_at)
+	SELECT $12::text, $13::text, ranked.spec_id, ranked.rank, ranked.score, old.rank, ranked.metrics, NOW()
+	FROM ranked
+	LEFT JOIN old ON old.spec_id = ranked.spec_id`
+
+	args := append(profileRankingArgs(profile, interval), profile.Section, period)
+	if _, err = tx.ExecContext(ctx, query, args...); err != nil {
+		log.Printf("[CatalogRepo.Home] profile ranking recalculation failed section=%s profile=%s period=%s err=%v", profile.Section, profile.ID, period, err)
+		return err
+	}
+	log.Printf("[CatalogRepo.Home] profile ranking recalculation complete section=%s profile=%s period=%s", profile.Section, profile.ID, period)
+	return tx.Commit()
+}
@@ -0,0 +1,126 @@
+package postgres_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/saransh1220/blueprint-audio/internal/modules/catalog/domain"
+	"github.com/saransh1220/blueprint-audio/internal/modules/catalog/infrastructure/persistence/postgres"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var rankingProfileColumns = []string{"id", "name", "section", "plays_weight", "unique_listeners_weight", "favorites_weight", "downloads_weight", "purchases_weight", "revenue_weight", "recency_half_life_hours", "max_per_producer", "is_active", "created_by", "activated_by", "activated_at", "created_at", "updated_at"}
+
+func rankingProfileRow(id uuid.UUID, section string, active bool) *sqlmock.Rows {
+	now := time.Now()
+	return sqlmock.NewRows(rankingProfileColumns).
+		AddRow(id, "Profile", section, 1.0, 1.5, 4.0, 6.0, 15.0, 0.15, 168.0, 2, active, nil, nil, nil, now, now)
+}
+
+func TestPGSpecRepository_RankingProfileLookups(t *testing.T) {
+	db, mock, cleanup := newMockDB(t)
+	defer cleanup()
+	repo := postgres.NewSpecRepository(db)
+	ctx := context.Background()
+	id := uuid.New()
+
+	mock.ExpectQuery("SELECT \\* FROM ranking_profiles WHERE id = \\$1").WithArgs(id).WillReturnRows(rankingProfileRow(id, "trending", false))
+	profile, err := repo.GetRankingProfile(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, 6.0, profile.Downloads)
+	assert.Equal(t, 168.0, profile.RecencyHalfLifeHours)
+	assert.Equal(t, 2, profile.MaxPerProducer)
+
+	mock.ExpectQuery("SELECT \\* FROM ranking_profiles WHERE id = \\$1").WithArgs(id).WillReturnRows(sqlmock.NewRows(rankingProfileColumns))
+	_, err = repo.GetRankingProfile(ctx, id)
+	assert.ErrorIs(t, err, domain.ErrRankingProfileNotFound)
+
+	mock.ExpectQuery("SELECT \\* FROM ranking_profiles WHERE section = \\$1 AND is_active").WithArgs("trending").WillReturnRows(sqlmock.NewRows(rankingProfileColumns))
+	active, err := repo.GetActiveRankingProfile(ctx, "trending")
+	require.NoError(t, err)
+	assert.Nil(t, active)
+
+	mock.ExpectQuery("SELECT \\* FROM ranking_profiles\\s+WHERE \\(\\$1 = '' OR section = \\$1\\)").WithArgs("").WillReturnRows(rankingProfileRow(id, "top_charts", true))
+	profiles, err := repo.ListRankingProfiles(ctx, "")
+	require.NoError(t, err)
+	require.Len(t, profiles, 1)
+	assert.True(t, profiles[0].IsActive)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPGSpecRepository_ActivateRankingProfile(t *testing.T) {
+	db, mock, cleanup := newMockDB(t)
+	defer cleanup()
+	repo := postgres.NewSpecRepository(db)
+	id, actorID := uuid.New(), uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT section FROM ranking_profiles WHERE id = \\$1 FOR UPDATE").WithArgs(id).WillReturnRows(sqlmock.NewRows([]string{"section"}).AddRow("trending"))
+	mock.ExpectExec("UPDATE ranking_profiles\\s+SET is_active = FALSE").WithArgs("trending", id).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("UPDATE ranking_profiles\\s+SET is_active = TRUE").WithArgs(id, actorID).WillReturnRows(rankingProfileRow(id, "trending", true))
+	mock.ExpectCommit()
+
+	profile, err := repo.ActivateRankingProfile(context.Background(), id, actorID)
+	require.NoError(t, err)
+	assert.True(t, profile.IsActive)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT section FROM ranking_profiles").WithArgs(id).WillReturnRows(sqlmock.NewRows([]string{"section"}))
+	mock.ExpectRollback()
+	_, err = repo.ActivateRankingProfile(context.Background(), id, actorID)
+	assert.ErrorIs(t, err, domain.ErrRankingProfileNotFound)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPGSpecRepository_RecalculateWithActiveProfile(t *testing.T) {
+	db, mock, cleanup := newMockDB(t)
+	defer cleanup()
+	repo := postgres.NewSpecRepository(db)
+	id := uuid.New()
+
+	mock.ExpectQuery("SELECT \\* FROM ranking_profiles WHERE section = \\$1 AND is_active").WithArgs("trending").WillReturnRows(rankingProfileRow(id, "trending", true))
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT pg_try_advisory_xact_lock").WithArgs("beat_rankings:trending:24h").WillReturnRows(sqlmock.NewRows([]string{"locked"}).AddRow(true))
+	mock.ExpectExec("PARTITION BY producer_id[\\s\\S]*INSERT INTO beat_rankings").
+		WithArgs("24 hours", 1.0, 1.5, 4.0, 6.0, 15.0, 0.15, 168.0, 2, id.String(), "Profile", "trending", "24h").
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectCommit()
+
+	require.NoError(t, repo.RecalculateBeatRankings(context.Background(), "trending", "24h"))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPGSpecRepository_PreviewRankingProfile(t *testing.T) {
+	db, mock, cleanup := newMockDB(t)
+	defer cleanup()
+	repo := postgres.NewSpecRepository(db)
+	profile := &domain.RankingProfile{ID: uuid.New(), Name: "Preview", Section: "top_charts", RankingWeights: domain.RankingWeights{Purchases: 10}}
+	kept, dropped, producerID := uuid.New(), uuid.New(), uuid.New()
+	metrics, _ := json.Marshal(map[string]any{"purchases": 2, "contributions": map[string]float64{"purchases": 20}, "decay": 1})
+
+	mock.ExpectQuery("FULL OUTER JOIN current_rankings").
+		WithArgs("7 days", 0.0, 0.0, 0.0, 0.0, 10.0, 0.0, 0.0, 0, profile.ID.String(), "Preview", "top_charts", "7d", 10).
+		WillReturnRows(sqlmock.NewRows([]string{"spec_id", "title", "producer_id", "proposed_rank", "proposed_score", "current_rank", "current_score", "metrics"}).
+			AddRow(kept, "Kept", producerID, 1, 20.0, 2, 12.5, metrics).
+			AddRow(dropped, "Dropped", producerID, nil, nil, 1, 30.0, nil))
+
+	rows, err := repo.PreviewRankingProfile(context.Background(), profile, "7d", 10)
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	assert.Equal(t, 1, *rows[0].ProposedRank)
+	assert.Equal(t, 2, *rows[0].CurrentRank)
+	assert.Equal(t, 20.0, *rows[0].ProposedScore)
+	assert.Nil(t, rows[1].ProposedRank)
+	assert.Equal(t, 30.0, *rows[1].CurrentScore)
+
+	_, err = repo.PreviewRankingProfile(context.Background(), profile, "year", 10)
+	assert.EqualError(t, err, "unsupported ranking period: year")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,375 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/saransh1220/blueprint-audio/internal/modules/catalog/domain"
+)
+
+// recommendationInteractions lists each user's distinct beats over the
+// signals recommendations learn from: plays in the last 90 days, favorites
+// and paid purchases.
+const recommendationInteractions = `
+	interactions AS (
+		SELECT DISTINCT user_id, spec_id
+		FROM (
+			SELECT user_id, spec_id
+			FROM analytics_events
+			WHERE user_id IS NOT NULL
+			  AND event_type = 'play'
+			  AND created_at >= NOW() - INTERVAL '90 days'
+			UNION ALL
+			SELECT user_id, spec_id FROM user_favorites
+			UNION ALL
+			SELECT o.user_id, oi.spec_id
+			FROM order_items oi
+			JOIN orders o ON o.id = oi.order_id
+			WHERE o.status = 'paid'
+		) i
+	)`
+
+// recommendationPairsQuery counts, for every pair of beats, the users who
+// interacted with both. A beat is paired with itself too.
+const recommendationPairsQuery = `
+	WITH` + recommendationInteractions + `
+	INSERT INTO recommendation_item_pairs (spec_id, other_spec_id, co_users)
+	SELECT a.spec_id, b.spec_id, COUNT(*)
+	FROM interactions a
+	JOIN interactions b ON b.user_id = a.user_id
+	GROUP BY a.spec_id, b.spec_id`
+
+// recommendationStatsQuery stores each beat's listener count and its plays
+// and favorites over the last 30 days.
+const recommendationStatsQuery = `
+	WITH` + recommendationInteractions + `,
+	item_users AS (
+		SELECT spec_id, COUNT(*) AS users
+		FROM interactions
+		GROUP BY spec_id
+	),
+	popularity AS (
+		SELECT
+			spec_id,
+			COUNT(*) FILTER (WHERE event_type = 'play') AS plays,
+			COUNT(*) FILTER (WHERE event_type = 'favorite') AS favorites
+		FROM analytics_events
+		WHERE created_at >= NOW() - INTERVAL '30 days'
+		GROUP BY spec_id
+	)
+	INSERT INTO recommendation_item_stats (spec_id, users, plays, favorites)
+	SELECT
+		COALESCE(iu.spec_id, pop.spec_id),
+		COALESCE(iu.users, 0),
+		COALESCE(pop.plays, 0),
+		COALESCE(pop.favorites, 0)
+	FROM item_users iu
+	FULL JOIN popularity pop ON pop.spec_id = iu.spec_id`
+
+// recommendationQuery rebuilds one user's "For you" beats from the signals
+// stored by RebuildRecommendationSignals. Parameters: $1 user id, $2 number of
+// beats to keep.
+//
+// The user's plays (last 90 days), favorites and paid purchases are the seeds,
+// weighted 1, 3 and 5 with diminishing returns for repeat plays. Candidates are
+// scored out of 100 points:
+//   - 60 for item-to-item co-occurrence: how strongly listeners who share
+//     the user's seeds also played, favorited or bought the candidate, with
+//     each beat's listener count dampening popular items (cosine similarity)
+//     and the user's own interactions left out of the pair counts,
+//   - 30 for content similarity to the seeds: genres 35%, moods 20%,
+//     instruments 15%, BPM 15% and key 15%,
+//   - 10 for popularity over the last 30 days, to break ties between
+//     otherwise unrelated beats.
+//
+// Purchased and favorited beats and the user's own beats are never
+// recommended, and no producer gets more than three places.
+const recommendationQuery = `
+	WITH user_signals AS (
+		SELECT spec_id, 1.0 AS weight
+		FROM analytics_events
+		WHERE user_id = $1
+		  AND event_type = 'play'
+		  AND created_at >= NOW() - INTERVAL '90 days'
+		UNION ALL
+		SELECT spec_id, 3.0 FROM user_favorites WHERE user_id = $1
+		UNION ALL
+		SELECT oi.spec_id, 5.0
+		FROM order_items oi
+		JOIN orders o ON o.id = oi.order_id
+		WHERE o.user_id = $1 AND o.status = 'paid'
+	),
+	seeds AS (
+		SELECT us.spec_id, LN(1 + SUM(us.weight)) AS weight
+		FROM user_signals us
+		JOIN specs s ON s.id = us.spec_id
+		GROUP BY us.spec_id
+	),
+	co_occurrence AS (
+		SELECT
+			p.other_spec_id AS spec_id,
+			SUM(
+				sd.weight
+				* GREATEST(p.co_users - CASE WHEN own.spec_id IS NULL THEN 0 ELSE 1 END, 0)
+				/ SQRT(seed_stats.users * other_stats.users)
+			) AS similarity
+		FROM seeds sd
+		JOIN recommendation_item_pairs p ON p.spec_id = sd.spec_id
+		JOIN recommendation_item_stats seed_stats ON seed_stats.spec_id = p.spec_id
+		JOIN recommendation_item_stats other_stats ON other_stats.spec_id = p.other_spec_id
+		LEFT JOIN seeds own ON own.spec_id = p.other_spec_id
+		GROUP BY p.other_spec_id
+	),
+	profile AS (
+		SELECT SUM(sd.weight) AS total_weight, SUM(s.bpm * sd.weight) / SUM(sd.weight) AS bpm
+		FROM seeds sd
+		JOIN specs s ON s.id = sd.spec_id
+	),
+	seed_genres AS (
+		SELECT sg.genre_id, SUM(sd.weight) AS weight
+		FROM seeds sd
+		JOIN spec_genres sg ON sg.spec_id = sd.spec_id
+		GROUP BY sg.genre_id
+	),
+	seed_moods AS (
+		SELECT LOWER(mood) AS mood, SUM(sd.weight) AS weight
+		FROM seeds sd
+		JOIN specs s ON s.id = sd.spec_id
+		CROSS JOIN LATERAL UNNEST(s.moods) AS mood
+		GROUP BY LOWER(mood)
+	),
+	seed_instruments AS (
+		SELECT LOWER(instrument) AS instrument, SUM(sd.weight) AS weight
+		FROM seeds sd
+		JOIN specs s ON s.id = sd.spec_id
+		CROSS JOIN LATERAL UNNEST(s.instruments) AS instrument
+		GROUP BY LOWER(instrument)
+	),
+	seed_keys AS (
+		SELECT s.key, SUM(sd.weight) AS weight
+		FROM seeds sd
+		JOIN specs s ON s.id = sd.spec_id
+		GROUP BY s.key
+	),
+	candidates AS (
+		SELECT
+			s.id AS spec_id,
+			s.producer_id,
+			s.created_at,
+			COALESCE(pop.plays, 0) AS plays,
+			COALESCE(pop.favorites, 0) AS favorites,
+			COALESCE(co.similarity, 0) AS similarity,
+			LEAST(1, COALESCE((
+				SELECT SUM(g.weight)
+				FROM spec_genres sg
+				JOIN seed_genres g ON g.genre_id = sg.genre_id
+				WHERE sg.spec_id = s.id
+			), 0) / p.total_weight) AS genre_match,
+			LEAST(1, COALESCE((
+				SELECT SUM(m.weight) FROM seed_moods m
+				WHERE m.mood IN (SELECT LOWER(x) FROM UNNEST(s.moods) AS x)
+			), 0) / p.total_weight) AS mood_match,
+			LEAST(1, COALESCE((
+				SELECT SUM(si.weight) FROM seed_instruments si
+				WHERE si.instrument IN (SELECT LOWER(x) FROM UNNEST(s.instruments) AS x)
+			), 0) / p.total_weight) AS instrument_match,
+			GREATEST(0, 1 - ABS(s.bpm - p.bpm) / 40.0) AS bpm_match,
+			COALESCE((SELECT k.weight FROM seed_keys k WHERE k.key = s.key), 0) / p.total_weight AS key_match,
+			LN(1 + COALESCE(pop.plays, 0) + 3 * COALESCE(pop.favorites, 0)) AS popularity
+		FROM specs s
+		CROSS JOIN profile p
+		LEFT JOIN co_occurrence co ON co.spec_id = s.id
+		LEFT JOIN recommendation_item_stats pop ON pop.spec_id = s.id
+		WHERE p.total_weight > 0
+		  AND s.category = 'beat'
+		  AND s.processing_status = 'completed'
+		  AND s.is_deleted = FALSE
+		  AND s.producer_id <> $1
+		  AND NOT EXISTS (SELECT 1 FROM user_favorites uf WHERE uf.user_id = $1 AND uf.spec_id = s.id)
+		  AND NOT EXISTS (
+			SELECT 1
+			FROM order_items oi
+			JOIN orders o ON o.id = oi.order_id
+			WHERE o.user_id = $1 AND o.status = 'paid' AND oi.spec_id = s.id
+		  )
+	),
+	weighted AS (
+		SELECT
+			candidates.*,
+			60 * COALESCE(similarity / NULLIF(MAX(similarity) OVER (), 0), 0) AS co_occurrence_points,
+			30 * 0.35 * genre_match AS genres_points,
+			30 * 0.20 * mood_match AS moods_points,
+			30 * 0.15 * instrument_match AS instruments_points,
+			30 * 0.15 * bpm_match AS bpm_points,
+			30 * 0.15 * key_match AS key_points,
+			10 * COALESCE(popularity / NULLIF(MAX(popularity) OVER (), 0), 0) AS popularity_points
+		FROM candidates
+	),
+	scored AS (
+		SELECT
+			weighted.*,
+			co_occurrence_points + genres_points + moods_points + instruments_points
+				+ bpm_points + key_points + popularity_points AS score
+		FROM weighted
+	),
+	capped AS (
+		SELECT
+			scored.*,
+			ROW_NUMBER() OVER (
+				PARTITION BY producer_id
+				ORDER BY score DESC, created_at DESC, spec_id
+			) AS producer_rank
+		FROM scored
+	),
+	ranked AS (
+		SELECT
+			capped.*,
+			ROW_NUMBER() OVER (ORDER BY score DESC, created_at DESC, spec_id) AS rank
+		FROM capped
+		WHERE producer_rank <= 3
+	)
+	INSERT INTO user_recommendations (user_id, spec_id, rank, score, metrics, calculated_at)
+	SELECT
+		$1,
+		spec_id,
+		rank,
+		score,
+		jsonb_build_object(
+			'plays', plays,
+			'favorites', favorites,
+			'contributions', jsonb_build_object(
+				'co_occurrence', co_occurrence_points,
+				'genres', genres_points,
+				'moods', moods_points,
+				'instruments', instruments_points,
+				'bpm', bpm_points,
+				'key', key_points,
+				'popularity', popularity_points
+			)
+		),
+		NOW()
+	FROM ranked
+	WHERE rank <= $2`
+
+func (r *PgSpecRepository) ListRecommendationUsers(ctx context.Context, activeSince time.Time) ([]uuid.UUID, error) {
+	users := []uuid.UUID{}
+	query := `
+		SELECT user_id FROM analytics_events
+		WHERE user_id IS NOT NULL AND event_type = 'play' AND created_at >= $1
+		UNION
+		SELECT user_id FROM user_favorites WHERE created_at >= $1
+		UNION
+		SELECT user_id FROM orders WHERE status = 'paid' AND created_at >= $1`
+	if err := r.db.SelectContext(ctx, &users, query, activeSince); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+func (r *PgSpecRepository) RebuildRecommendationSignals(ctx context.Context) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, query := range []string{
+		`DELETE FROM recommendation_item_pairs`,
+		`DELETE FROM recommendation_item_stats`,
+		recommendationPairsQuery,
+		recommendationStatsQuery,
+	} {
+		if _, err := tx.ExecContext(ctx, query); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *PgSpecRepository) RecalculateUserRecommendations(ctx context.Context, userID uuid.UUID, limit int) (int, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM user_recommendations WHERE user_id = $1`, userID); err != nil {
+		return 0, err
+	}
+	result, err := tx.ExecContext(ctx, recommendationQuery, userID, limit)
+	if err != nil {
+		return 0, err
+	}
+	stored, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return int(stored), nil
+}
+
+func (r *PgSpecRepository) GetRecommendedSpecs(ctx context.Context, userID uuid.UUID, limit int) ([]domain.RankingRow, error) {
+	if limit <= 0 {
+		limit = 8
+	}
+
+	var rows []struct {
+		domain.Spec
+		Rank         int             `db:"rank"`
+		Score        float64         `db:"score"`
+		MetricsJSON  json.RawMessage `db:"metrics"`
+		CalculatedAt time.Time       `db:"calculated_at"`
+	}
+
+	query := `
+		SELECT
+			s.*, u.display_name as producer_name, '' as producer_handle,
+			ur.rank, ur.score, ur.metrics, ur.calculated_at
+		FROM user_recommendations ur
+		JOIN specs s ON s.id = ur.spec_id
+		JOIN users u ON s.producer_id = u.id
+		WHERE ur.user_id = $1
+		  AND s.category = 'beat'
+		  AND s.processing_status = 'completed'
+		  AND s.is_deleted = FALSE
+		  AND NOT EXISTS (
+			SELECT 1
+			FROM order_items oi
+			JOIN orders o ON o.id = oi.order_id
+			WHERE o.user_id = ur.user_id AND o.status = 'paid' AND oi.spec_id = ur.spec_id
+		  )
+		ORDER BY ur.rank ASC
+		LIMIT $2`
+
+	if err := r.db.SelectContext(ctx, &rows, query, userID, limit); err != nil {
+		log.Printf("[CatalogRepo.Home] recommended specs query failed user=%s err=%v", userID, err)
+		return nil, err
+	}
+
+	specs := make([]domain.Spec, len(rows))
+	for i := range rows {
+		specs[i] = rows[i].Spec
+	}
+	if err := r.hydrateSpecRelations(ctx, specs); err != nil {
+		log.Printf("[CatalogRepo.Home] recommended specs relation hydration failed user=%s err=%v", userID, err)
+		return nil, err
+	}
+
+	result := make([]domain.RankingRow, len(rows))
+	for i := range rows {
+		result[i] = domain.RankingRow{
+			Spec:         specs[i],
+			Rank:         rows[i].Rank,
+			Score:        rows[i].Score,
+			MetricsJSON:  rows[i].MetricsJSON,
+			CalculatedAt: rows[i].CalculatedAt,
+		}
+	}
+	log.Printf("[CatalogRepo.Home] recommended specs loaded user=%s count=%d", userID, len(result))
+	return result, nil
+}
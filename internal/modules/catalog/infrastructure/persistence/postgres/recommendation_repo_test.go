@@ -0,0 +1,95 @@
+package postgres_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/saransh1220/blueprint-audio/internal/modules/catalog/infrastructure/persistence/postgres"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPGSpecRepository_ListRecommendationUsers(t *testing.T) {
+	db, mock, cleanup := newMockDB(t)
+	defer cleanup()
+	repo := postgres.NewSpecRepository(db)
+	since := time.Now().Add(-time.Hour)
+	userID := uuid.New()
+
+	mock.ExpectQuery("SELECT user_id FROM analytics_events[\\s\\S]*UNION[\\s\\S]*user_favorites[\\s\\S]*UNION[\\s\\S]*FROM orders WHERE status = 'paid'").
+		WithArgs(since).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(userID))
+	users, err := repo.ListRecommendationUsers(context.Background(), since)
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{userID}, users)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPGSpecRepository_RebuildRecommendationSignals(t *testing.T) {
+	db, mock, cleanup := newMockDB(t)
+	defer cleanup()
+	repo := postgres.NewSpecRepository(db)
+	ctx := context.Background()
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM recommendation_item_pairs").WillReturnResult(sqlmock.NewResult(0, 40))
+	mock.ExpectExec("DELETE FROM recommendation_item_stats").WillReturnResult(sqlmock.NewResult(0, 8))
+	mock.ExpectExec("WITH[\\s\\S]*interactions AS[\\s\\S]*INSERT INTO recommendation_item_pairs").WillReturnResult(sqlmock.NewResult(0, 42))
+	mock.ExpectExec("WITH[\\s\\S]*popularity AS[\\s\\S]*INSERT INTO recommendation_item_stats").WillReturnResult(sqlmock.NewResult(0, 9))
+	mock.ExpectCommit()
+	require.NoError(t, repo.RebuildRecommendationSignals(ctx))
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM recommendation_item_pairs").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("DELETE FROM recommendation_item_stats").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO recommendation_item_pairs").WillReturnError(errors.New("insert failed"))
+	mock.ExpectRollback()
+	assert.EqualError(t, repo.RebuildRecommendationSignals(ctx), "insert failed")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPGSpecRepository_RecalculateUserRecommendations(t *testing.T) {
+	db, mock, cleanup := newMockDB(t)
+	defer cleanup()
+	repo := postgres.NewSpecRepository(db)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM user_recommendations WHERE user_id = \\$1").WithArgs(userID).WillReturnResult(sqlmock.NewResult(0, 4))
+	mock.ExpectExec("WITH user_signals AS[\\s\\S]*co_occurrence AS[\\s\\S]*recommendation_item_pairs[\\s\\S]*INSERT INTO user_recommendations").WithArgs(userID, 50).WillReturnResult(sqlmock.NewResult(0, 12))
+	mock.ExpectCommit()
+	stored, err := repo.RecalculateUserRecommendations(ctx, userID, 50)
+	require.NoError(t, err)
+	assert.Equal(t, 12, stored)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM user_recommendations").WithArgs(userID).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO user_recommendations").WithArgs(userID, 50).WillReturnError(errors.New("insert failed"))
+	mock.ExpectRollback()
+	_, err = repo.RecalculateUserRecommendations(ctx, userID, 50)
+	assert.EqualError(t, err, "insert failed")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPGSpecRepository_GetRecommendedSpecs(t *testing.T) {
+	db, mock, cleanup := newMockDB(t)
+	defer cleanup()
+	repo := postgres.NewSpecRepository(db)
+	id, producerID, userID := uuid.New(), uuid.New(), uuid.New()
+
+	mock.ExpectQuery("SELECT[\\s\\S]*FROM user_recommendations ur[\\s\\S]*o.status = 'paid'").WithArgs(userID, 8).WillReturnRows(sqlmock.NewRows([]string{"id", "producer_id", "title", "category", "type", "bpm", "key", "base_price_minor", "image_url", "preview_url", "duration", "free_mp3_enabled", "producer_name", "producer_handle", "rank", "score", "metrics", "calculated_at"}).AddRow(id, producerID, "Track", "beat", "wav", 120, "C", 10.0, "image", "preview", 90, true, "Producer", "", 1, 71.5, []byte(`{"plays": 3, "contributions": {"co_occurrence": 60}}`), time.Now()))
+	mock.ExpectQuery("SELECT sg.spec_id, g.\\* FROM genres").WithArgs(id).WillReturnRows(sqlmock.NewRows([]string{"spec_id", "id", "name", "slug", "created_at"}))
+	mock.ExpectQuery("SELECT \\* FROM license_options").WithArgs(id).WillReturnRows(sqlmock.NewRows([]string{"id", "spec_id", "license_type", "name", "price_minor", "features", "file_types", "is_deleted"}))
+	rows, err := repo.GetRecommendedSpecs(context.Background(), userID, 0)
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.Equal(t, id, rows[0].Spec.ID)
+	assert.Equal(t, 71.5, rows[0].Score)
+	assert.Nil(t, rows[0].PreviousRank)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
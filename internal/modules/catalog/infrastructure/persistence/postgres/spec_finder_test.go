@@ -25,20 +25,20 @@ func TestSpecFinderMethods(t *testing.T) {
 	specID := uuid.New()
 	licenseID := uuid.New()
 
-	rows := sqlmock.NewRows([]string{"id", "producer_id", "producer_name", "title", "category", "type", "bpm", "key", "image_url", "preview_url", "wav_url", "stems_url", "base_price", "description", "duration", "free_mp3_enabled", "created_at", "updated_at", "is_deleted"}).
+	rows := sqlmock.NewRows([]string{"id", "producer_id", "producer_name", "title", "category", "type", "bpm", "key", "image_url", "preview_url", "wav_url", "stems_url", "base_price_minor", "description", "duration", "free_mp3_enabled", "created_at", "updated_at", "is_deleted"}).
 		AddRow(specID, uuid.New(), "p", "t", "beat", "wav", 120, "C", "img", "prev", nil, nil, 10.0, "d", 60, false, time.Now(), time.Now(), false)
 	mock.ExpectQuery(`SELECT s\.\*, u\.display_name as producer_name`).WithArgs(specID).WillReturnRows(rows)
-	mock.ExpectQuery(`SELECT \* FROM license_options WHERE spec_id = \$1 AND is_deleted = FALSE`).WithArgs(specID).WillReturnRows(sqlmock.NewRows([]string{"id", "spec_id", "license_type", "name", "price", "features", "file_types", "is_deleted"}))
+	mock.ExpectQuery(`SELECT \* FROM license_options WHERE spec_id = \$1 AND is_deleted = FALSE`).WithArgs(specID).WillReturnRows(sqlmock.NewRows([]string{"id", "spec_id", "license_type", "name", "price_minor", "features", "file_types", "is_deleted"}))
 	mock.ExpectQuery(`SELECT g\.\* FROM genres g JOIN spec_genres sg ON g\.id = sg\.genre_id WHERE sg\.spec_id = \$1`).WithArgs(specID).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "slug", "created_at"}))
 
 	spec, err := repo.FindByID(ctx, specID)
 	require.NoError(t, err)
 	require.NotNil(t, spec)
 
-	rows2 := sqlmock.NewRows([]string{"id", "producer_id", "producer_name", "title", "category", "type", "bpm", "key", "image_url", "preview_url", "wav_url", "stems_url", "base_price", "description", "duration", "free_mp3_enabled", "created_at", "updated_at", "is_deleted"}).
+	rows2 := sqlmock.NewRows([]string{"id", "producer_id", "producer_name", "title", "category", "type", "bpm", "key", "image_url", "preview_url", "wav_url", "stems_url", "base_price_minor", "description", "duration", "free_mp3_enabled", "created_at", "updated_at", "is_deleted"}).
 		AddRow(specID, uuid.New(), "p", "t", "beat", "wav", 120, "C", "img", "prev", nil, nil, 10.0, "d", 60, false, time.Now(), time.Now(), false)
 	mock.ExpectQuery(`SELECT s\.\*, u\.display_name as producer_name`).WithArgs(specID).WillReturnRows(rows2)
-	mock.ExpectQuery(`SELECT \* FROM license_options WHERE spec_id = \$1 AND is_deleted = FALSE`).WithArgs(specID).WillReturnRows(sqlmock.NewRows([]string{"id", "spec_id", "license_type", "name", "price", "features", "file_types", "is_deleted"}))
+	mock.ExpectQuery(`SELECT \* FROM license_options WHERE spec_id = \$1 AND is_deleted = FALSE`).WithArgs(specID).WillReturnRows(sqlmock.NewRows([]string{"id", "spec_id", "license_type", "name", "price_minor", "features", "file_types", "is_deleted"}))
 	mock.ExpectQuery(`SELECT g\.\* FROM genres g JOIN spec_genres sg ON g\.id = sg\.genre_id WHERE sg\.spec_id = \$1`).WithArgs(specID).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "slug", "created_at"}))
 	_, err = repo.FindWithLicenses(ctx, specID)
 	require.NoError(t, err)
@@ -48,7 +48,7 @@ func TestSpecFinderMethods(t *testing.T) {
 	require.NoError(t, err)
 	assert.True(t, exists)
 
-	licRows := sqlmock.NewRows([]string{"id", "spec_id", "license_type", "name", "price", "features", "file_types", "is_deleted"}).AddRow(licenseID, specID, "Basic", "Basic", 9.99, "{}", "{}", false)
+	licRows := sqlmock.NewRows([]string{"id", "spec_id", "license_type", "name", "price_minor", "features", "file_types", "is_deleted"}).AddRow(licenseID, specID, "Basic", "Basic", 999, "{}", "{}", false)
 	mock.ExpectQuery(`SELECT \* FROM license_options WHERE id = \$1 AND is_deleted = FALSE`).WithArgs(licenseID).WillReturnRows(licRows)
 	license, err := repo.GetLicenseByID(ctx, licenseID)
 	require.NoError(t, err)
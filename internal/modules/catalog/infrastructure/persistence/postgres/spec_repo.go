@@ -9,6 +9,7 @@ import (
 	"log"
 	"sort"
 	"time"
+	"unicode"
 
 	"strings"
 
@@ -65,12 +66,12 @@ func createSpecTx(ctx context.Context, tx *sqlx.Tx, spec *domain.Spec) error {
 	query := `
         INSERT INTO specs (
             id, producer_id, title, category, type, bpm, key, 
-            base_price, price_currency, image_url, preview_url, wav_url, stems_url,
+            base_price_minor, price_currency, image_url, preview_url, wav_url, stems_url,
             tags, description, duration, free_mp3_enabled,
             created_at, updated_at, processing_status,moods,instruments,slug,short_code
         ) VALUES (
             :id, :producer_id, :title, :category, :type, :bpm, :key, 
-            :base_price, :price_currency, :image_url, :preview_url, :wav_url, :stems_url,
+            :base_price_minor, :price_currency, :image_url, :preview_url, :wav_url, :stems_url,
             :tags, :description, :duration, :free_mp3_enabled,
             :created_at, :updated_at, :processing_status, :moods, :instruments, :slug, :short_code
         )`
@@ -139,9 +140,9 @@ func createSpecTx(ctx context.Context, tx *sqlx.Tx, spec *domain.Spec) error {
 
 		licenseQuery := `
             INSERT INTO license_options (
-                id, spec_id, license_type, name, price, price_currency, features, file_types
+                id, spec_id, license_type, name, price_minor, price_currency, features, file_types
             ) VALUES (
-                :id, :spec_id, :license_type, :name, :price, :price_currency, :features, :file_types
+                :id, :spec_id, :license_type, :name, :price_minor, :price_currency, :features, :file_types
             )`
 		_, err = tx.NamedExecContext(ctx, licenseQuery, license)
 		if err != nil {
@@ -249,13 +250,18 @@ func (r *PgSpecRepository) List(ctx context.Context, filter domain.SpecFilter) (
 	// Use a struct to hold the result including the window function count
 	var results []struct {
 		domain.Spec
-		TotalCount int `db:"total_count"`
+		TotalCount           int            `db:"total_count"`
+		SearchRank           float64        `db:"search_rank"`
+		TitleHighlight       sql.NullString `db:"title_highlight"`
+		DescriptionHighlight sql.NullString `db:"description_highlight"`
 	}
 
-	query := `
-		SELECT s.*, u.display_name as producer_name, '' as producer_handle, COUNT(*) OVER() as total_count 
+	selectClause := `
+		SELECT s.*, u.display_name as producer_name, '' as producer_handle, COUNT(*) OVER() as total_count`
+	fromClause := `
 		FROM specs s
-		JOIN users u ON s.producer_id = u.id
+		JOIN users u ON s.producer_id = u.id`
+	query := `
 		WHERE s.is_deleted = FALSE
 		  AND s.processing_status = 'completed'
 	`
@@ -284,17 +290,29 @@ func (r *PgSpecRepository) List(ctx context.Context, filter domain.SpecFilter) (
 		argId++
 	}
 
-	if filter.Search != "" {
-		searchTerm := "%" + filter.Search + "%"
-		query += fmt.Sprintf(` AND (
-			s.title ILIKE $%d OR
-			array_to_string(s.tags, ',') ILIKE $%d OR
-			array_to_string(s.moods, ',') ILIKE $%d OR
-			u.display_name ILIKE $%d OR
-			s.description ILIKE $%d
-		)`, argId, argId, argId, argId, argId)
-		args = append(args, searchTerm)
-		argId++
+	search := strings.TrimSpace(filter.Search)
+	if search != "" {
+		// The websearch query handles whole words, quoted phrases and -exclusions
+		// against the stemmed document. The word still being typed must also
+		// match, either whole or as a prefix, so "atmos" finds "atmospheric".
+		selectClause += `,
+			ts_rank_cd(d.document, sq.query) as search_rank,
+			spec_search_headline(s.title, sq.query, 'HighlightAll=TRUE') as title_highlight,
+			spec_search_headline(s.description, sq.query, 'MaxWords=35, MinWords=15, MaxFragments=2') as description_highlight`
+		fromClause += fmt.Sprintf(`
+		JOIN spec_search_documents d ON d.spec_id = s.id
+		CROSS JOIN (
+			SELECT websearch_to_tsquery('english', $%d)
+			    && (to_tsquery('english', $%d) || to_tsquery('simple', $%d)) AS query
+		) sq`, argId, argId+1, argId+2)
+		query += " AND d.document @@ sq.query"
+		rest, word := splitSearchPrefix(search)
+		prefix := ""
+		if word != "" {
+			prefix = word + ":*"
+		}
+		args = append(args, rest, word, prefix)
+		argId += 3
 	}
 
 	if filter.MinBPM > 0 {
@@ -310,13 +328,13 @@ func (r *PgSpecRepository) List(ctx context.Context, filter domain.SpecFilter) (
 	}
 
 	if filter.MinPrice >= 0 {
-		query += fmt.Sprintf(" AND s.base_price >= $%d", argId)
+		query += fmt.Sprintf(" AND s.base_price_minor >= $%d", argId)
 		args = append(args, filter.MinPrice)
 		argId++
 	}
 
 	if filter.MaxPrice > 0 {
-		query += fmt.Sprintf(" AND s.base_price <= $%d", argId)
+		query += fmt.Sprintf(" AND s.base_price_minor <= $%d", argId)
 		args = append(args, filter.MaxPrice)
 		argId++
 	}
@@ -359,15 +377,21 @@ func (r *PgSpecRepository) List(ctx context.Context, filter domain.SpecFilter) (
 	case "oldest":
 		orderBy = "s.created_at ASC"
 	case "price_asc":
-		orderBy = "s.base_price ASC"
+		orderBy = "s.base_price_minor ASC"
 	case "price_desc":
-		orderBy = "s.base_price DESC"
+		orderBy = "s.base_price_minor DESC"
 	case "bpm_asc":
 		orderBy = "s.bpm ASC"
 	case "bpm_desc":
 		orderBy = "s.bpm DESC"
 	}
+	// Relevance only means something when there is a search; searches
+	// without an explicit sort default to it.
+	if search != "" && (filter.Sort == "" || filter.Sort == "relevance") {
+		orderBy = "search_rank DESC, s.created_at DESC"
+	}
 
+	query = selectClause + fromClause + query
 	query += fmt.Sprintf(" ORDER BY %s LIMIT $%d OFFSET $%d", orderBy, argId, argId+1)
 	args = append(args, filter.Limit, filter.Offset)
 
@@ -391,6 +415,9 @@ func (r *PgSpecRepository) List(ctx context.Context, filter domain.SpecFilter) (
 		specs[i] = res.Spec
 		specs[i].Genres = []domain.Genre{}           // Initialize empty slice
 		specs[i].Licenses = []domain.LicenseOption{} // Initialize empty slice
+		if search != "" {
+			specs[i].Highlights = searchHighlights(res.TitleHighlight, res.DescriptionHighlight)
+		}
 		specMap[specs[i].ID] = &specs[i]
 		specIDs[i] = specs[i].ID
 	}
@@ -445,6 +472,45 @@ func (r *PgSpecRepository) List(ctx context.Context, filter domain.SpecFilter) (
 	return specs, total, nil
 }
 
+// splitSearchPrefix separates the word still being typed from the rest of a
+// search, e.g. "dark tra" gives "dark" and "tra". Only a trailing plain word
+// is split off: inside an open quote, after "or", or with any character other
+// than letters and digits, the whole search is left to websearch_to_tsquery,
+// which keeps phrases and -exclusions intact and user input out of to_tsquery.
+func splitSearchPrefix(search string) (rest, word string) {
+	search = strings.TrimSpace(search)
+	if strings.Count(search, `"`)%2 != 0 {
+		return search, ""
+	}
+	start := strings.LastIndexFunc(search, unicode.IsSpace) + 1
+	last := strings.ToLower(search[start:])
+	rest = strings.TrimSpace(search[:start])
+	if last == "" || last == "or" || strings.IndexFunc(last, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) >= 0 {
+		return search, ""
+	}
+	if fields := strings.Fields(rest); len(fields) > 0 && strings.EqualFold(fields[len(fields)-1], "or") {
+		return search, ""
+	}
+	return rest, last
+}
+
+// searchHighlights keeps only the snippets that actually contain a match.
+func searchHighlights(title, description sql.NullString) *domain.SearchHighlights {
+	highlights := &domain.SearchHighlights{}
+	if title.Valid && strings.Contains(title.String, "<mark>") {
+		highlights.Title = title.String
+	}
+	if description.Valid && strings.Contains(description.String, "<mark>") {
+		highlights.Description = description.String
+	}
+	if highlights.Title == "" && highlights.Description == "" {
+		return nil
+	}
+	return highlights
+}
+
 func (r *PgSpecRepository) GetHomepageStats(ctx context.Context) (*domain.HomepageStats, error) {
 	stats := &domain.HomepageStats{}
 	log.Printf("[CatalogRepo.Home] querying homepage stats")
@@ -529,7 +595,7 @@ func (r *PgSpecRepository) GetRankedSpecs(ctx context.Context, section, period s
 		JOIN users u ON s.producer_id = u.id
 		WHERE br.section = $1
 		  AND br.period = $2
-		  AND ($1 <> 'top_charts' OR br.metrics->>'algorithm_version' = '3')
+		  AND ($1 <> 'top_charts' OR br.metrics->>'algorithm_version' = '3' OR br.metrics->>'profile_id' IS NOT NULL)
 		  AND s.category = 'beat'
 		  AND s.processing_status = 'completed'
 		  AND s.is_deleted = FALSE
@@ -570,34 +636,25 @@ func (r *PgSpecRepository) GetRankedSpecs(ctx context.Context, section, period s
 	return result, nil
 }
 
-func (r *PgSpecRepository) GetRankingFreshness(ctx context.Context, section, period string) (*domain.RankingFreshness, error) {
-	var row struct {
-		Count        int          `db:"count"`
-		CalculatedAt sql.NullTime `db:"calculated_at"`
-	}
-	query := `
-		SELECT COUNT(*) AS count, MAX(calculated_at) AS calculated_at
-		FROM beat_rankings
-		WHERE section = $1
-		  AND period = $2
-		  AND ($1 <> 'top_charts' OR metrics->>'algorithm_version' = '3')`
-	if err := r.db.GetContext(ctx, &row, query, section, period); err != nil {
-		log.Printf("[CatalogRepo.Home] ranking freshness query failed section=%s period=%s err=%v", section, period, err)
-		return nil, err
+func (r *PgSpecRepository) RecalculateBeatRankings(ctx context.Context, section, period string) error {
+	interval, err := rankingInterval(period)
+	if err != nil {
+		return err
 	}
-	freshness := &domain.RankingFreshness{Count: row.Count}
-	if row.CalculatedAt.Valid {
-		freshness.CalculatedAt = row.CalculatedAt.Time
+	if section != domain.HomeSectionTrending && section != domain.HomeSectionTopCharts {
+		return fmt.Errorf("unsupported ranking section: %s", section)
 	}
-	log.Printf("[CatalogRepo.Home] ranking freshness section=%s period=%s count=%d calculated_at=%s", section, period, freshness.Count, freshness.CalculatedAt.Format(time.RFC3339))
-	return freshness, nil
-}
 
-func (r *PgSpecRepository) RecalculateBeatRankings(ctx context.Context, section, period string) error {
-	interval, err := rankingInterval(period)
+	profile, err := r.GetActiveRankingProfile(ctx, section)
 	if err != nil {
+		log.Printf("[CatalogRepo.Home] active ranking profile lookup failed section=%s err=%v", section, err)
 		return err
 	}
+	if profile != nil {
+		log.Printf("[CatalogRepo.Home] recalculating %s rankings with profile=%s period=%s interval=%s", section, profile.ID, period, interval)
+		return r.recalculateProfileRankings(ctx, profile, period, interval)
+	}
+
 	switch section {
 	case domain.HomeSectionTrending:
 		log.Printf("[CatalogRepo.Home] recalculating trending rankings period=%s interval=%s", period, interval)
@@ -611,20 +668,12 @@ func (r *PgSpecRepository) RecalculateBeatRankings(ctx context.Context, section,
 }
 
 func (r *PgSpecRepository) recalculateTrendingRankings(ctx context.Context, period, interval string) error {
-	lockKey := fmt.Sprintf("beat_rankings:%s:%s", domain.HomeSectionTrending, period)
 	tx, err := r.db.BeginTxx(ctx, nil)
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback()
-	var locked bool
-	if err = tx.GetContext(ctx, &locked, "SELECT pg_try_advisory_xact_lock(hashtext($1))", lockKey); err != nil || !locked {
-		if err != nil {
-			log.Printf("[CatalogRepo.Home] advisory lock failed key=%s err=%v", lockKey, err)
-		} else {
-			log.Printf("[CatalogRepo.Home] advisory lock busy key=%s", lockKey)
-			err = errRankingRefreshSkipped
-		}
+	if err = lockRankingRefresh(ctx, tx, domain.HomeSectionTrending, period); err != nil {
 		return err
 	}
 
@@ -665,19 +714,21 @@ func (r *PgSpecRepository) recalculateTrendingRankings(ctx context.Context, peri
 		current_orders AS (
 			SELECT
 				s.id AS spec_id,
-				COUNT(o.id) AS purchases,
-				COALESCE(SUM(o.amount), 0) / 100.0 AS revenue
+				COUNT(oi.id) AS purchases,
+				COALESCE(SUM(oi.amount), 0) / 100.0 AS revenue
 			FROM specs s
-			LEFT JOIN orders o
-				ON o.spec_id = s.id
-			   AND o.status = 'paid'
-			   AND o.created_at >= NOW() - $1::interval
+			LEFT JOIN (order_items oi
+				JOIN orders o
+					ON o.id = oi.order_id
+				   AND o.status = 'paid'
+				   AND o.created_at >= NOW() - $1::interval)
+				ON oi.spec_id = s.id
 			WHERE s.category = 'beat'
 			  AND s.processing_status = 'completed'
 			  AND s.is_deleted = FALSE
 			GROUP BY s.id
 		),
-		scored AS (
+		weighted AS (
 			SELECT
 				s.id AS spec_id,
 				cm.plays,
@@ -686,40 +737,54 @@ func (r *PgSpecRepository) recalculateTrendingRankings(ctx context.Context, peri
 				cm.downloads,
 				co.purchases,
 				co.revenue,
-				(
-					(cm.plays * 1.0)
-					+ (cm.unique_listeners * 1.5)
-					+ (cm.favorites * 4.0)
-					+ (cm.downloads * 6.0)
-					+ (co.purchases * 15.0)
-					+ (co.revenue * 0.15)
-					+ GREATEST(
-						(
-							(cm.plays * 1.0)
-							+ (cm.unique_listeners * 1.5)
-							+ (cm.favorites * 4.0)
-							+ (cm.downloads * 6.0)
-						) - (
-							(pm.plays * 1.0)
-							+ (pm.unique_listeners * 1.5)
-							+ (pm.favorites * 4.0)
-							+ (pm.downloads * 6.0)
-						),
-						0
-					) * 0.35
-					+ CASE
-						WHEN s.created_at >= NOW() - INTERVAL '7 days'
-						THEN 10 * GREATEST(0, 1 - (EXTRACT(EPOCH FROM (NOW() - s.created_at)) / 604800.0))
-						ELSE 0
-					  END
-					- (GREATEST((EXTRACT(EPOCH FROM (NOW() - s.created_at)) / 86400.0) - 30, 0) * 0.05)
-				) AS score,
+				(cm.plays * 1.0) AS plays_points,
+				(cm.unique_listeners * 1.5) AS unique_listeners_points,
+				(cm.favorites * 4.0) AS favorites_points,
+				(cm.downloads * 6.0) AS downloads_points,
+				(co.purchases * 15.0) AS purchases_points,
+				(co.revenue * 0.15) AS revenue_points,
+				GREATEST(
+					(
+						(cm.plays * 1.0)
+						+ (cm.unique_listeners * 1.5)
+						+ (cm.favorites * 4.0)
+						+ (cm.downloads * 6.0)
+					) - (
+						(pm.plays * 1.0)
+						+ (pm.unique_listeners * 1.5)
+						+ (pm.favorites * 4.0)
+						+ (pm.downloads * 6.0)
+					),
+					0
+				) * 0.35 AS momentum_points,
+				CASE
+					WHEN s.created_at >= NOW() - INTERVAL '7 days'
+					THEN 10 * GREATEST(0, 1 - (EXTRACT(EPOCH FROM (NOW() - s.created_at)) / 604800.0))
+					ELSE 0
+				END AS new_release_points,
+				-(GREATEST((EXTRACT(EPOCH FROM (NOW() - s.created_at)) / 86400.0) - 30, 0) * 0.05) AS age_penalty_points,
 				s.created_at
 			FROM specs s
 			JOIN current_metrics cm ON cm.spec_id = s.id
 			JOIN previous_metrics pm ON pm.spec_id = s.id
 			JOIN current_orders co ON co.spec_id = s.id
 		),
+		scored AS (
+			SELECT
+				weighted.*,
+				(
+					plays_points
+					+ unique_listeners_points
+					+ favorites_points
+					+ downloads_points
+					+ purchases_points
+					+ revenue_points
+					+ momentum_points
+					+ new_release_points
+					+ age_penalty_points
+				) AS score
+			FROM weighted
+		),
 		ranked AS (
 			SELECT
 				spec_id,
@@ -733,7 +798,18 @@ func (r *PgSpecRepository) recalculateTrendingRankings(ctx context.Context, peri
 					'favorites', favorites,
 					'downloads', downloads,
 					'purchases', purchases,
-					'revenue', revenue
+					'revenue', revenue,
+					'contributions', jsonb_build_object(
+						'plays', plays_points,
+						'unique_listeners', unique_listeners_points,
+						'favorites', favorites_points,
+						'downloads', downloads_points,
+						'purchases', purchases_points,
+						'revenue', revenue_points,
+						'momentum', momentum_points,
+						'new_release', new_release_points,
+						'age_penalty', age_penalty_points
+					)
 				) AS metrics
 			FROM scored
 			WHERE score > 0
@@ -762,20 +838,12 @@ func (r *PgSpecRepository) recalculateTrendingRankings(ctx context.Context, peri
 }
 
 func (r *PgSpecRepository) recalculateTopChartRankings(ctx context.Context, period, interval string) error {
-	lockKey := fmt.Sprintf("beat_rankings:%s:%s", domain.HomeSectionTopCharts, period)
 	tx, err := r.db.BeginTxx(ctx, nil)
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback()
-	var locked bool
-	if err = tx.GetContext(ctx, &locked, "SELECT pg_try_advisory_xact_lock(hashtext($1))", lockKey); err != nil || !locked {
-		if err != nil {
-			log.Printf("[CatalogRepo.Home] advisory lock failed key=%s err=%v", lockKey, err)
-		} else {
-			log.Printf("[CatalogRepo.Home] advisory lock busy key=%s", lockKey)
-			err = errRankingRefreshSkipped
-		}
+	if err = lockRankingRefresh(ctx, tx, domain.HomeSectionTopCharts, period); err != nil {
 		return err
 	}
 
@@ -798,13 +866,15 @@ func (r *PgSpecRepository) recalculateTopChartRankings(ctx context.Context, peri
 		order_metrics AS (
 			SELECT
 				s.id AS spec_id,
-				COUNT(o.id) AS purchases,
-				COALESCE(SUM(o.amount), 0) / 100.0 AS revenue
+				COUNT(oi.id) AS purchases,
+				COALESCE(SUM(oi.amount), 0) / 100.0 AS revenue
 			FROM specs s
-			LEFT JOIN orders o
-				ON o.spec_id = s.id
-			   AND o.status = 'paid'
-			   AND o.created_at >= NOW() - $1::interval
+			LEFT JOIN (order_items oi
+				JOIN orders o
+					ON o.id = oi.order_id
+				   AND o.status = 'paid'
+				   AND o.created_at >= NOW() - $1::interval)
+				ON oi.spec_id = s.id
 			WHERE s.category = 'beat'
 			  AND s.processing_status = 'completed'
 			  AND s.is_deleted = FALSE
@@ -823,7 +893,7 @@ func (r *PgSpecRepository) recalculateTopChartRankings(ctx context.Context, peri
 			  AND s.processing_status = 'completed'
 			  AND s.is_deleted = FALSE
 		),
-		scored AS (
+		weighted AS (
 			SELECT
 				s.id AS spec_id,
 				em.plays,
@@ -831,48 +901,11 @@ func (r *PgSpecRepository) recalculateTopChartRankings(ctx context.Context, peri
 				em.downloads,
 				om.purchases,
 				om.revenue,
-				(
-					(
-						(em.plays * 1.0)
-						+ (em.favorites * 3.0)
-						+ (em.downloads * 4.0)
-						+ (om.purchases * 20.0)
-						+ (om.revenue * 0.25)
-					)
-					+ (
-						(
-							(lm.lifetime_plays * 0.20)
-							+ (lm.lifetime_favorites * 1.00)
-							+ (lm.lifetime_downloads * 1.50)
-							+ (lm.lifetime_purchases * 6.00)
-						)
-						*
-						CASE
-							WHEN (
-								(em.plays * 1.0)
-								+ (em.favorites * 3.0)
-								+ (em.downloads * 4.0)
-								+ (om.purchases * 20.0)
-								+ (om.revenue * 0.25)
-							) >= 5 THEN 0.22
-							WHEN (
-								(em.plays * 1.0)
-								+ (em.favorites * 3.0)
-								+ (em.downloads * 4.0)
-								+ (om.purchases * 20.0)
-								+ (om.revenue * 0.25)
-							) > 0 THEN 0.12
-							ELSE 0.03
-						END
-					)
-				) AS score,
-				(
-					(em.plays * 1.0)
-					+ (em.favorites * 3.0)
-					+ (em.downloads * 4.0)
-					+ (om.purchases * 20.0)
-					+ (om.revenue * 0.25)
-				) AS recent_score,
+				(em.plays * 1.0) AS plays_points,
+				(em.favorites * 3.0) AS favorites_points,
+				(em.downloads * 4.0) AS downloads_points,
+				(om.purchases * 20.0) AS purchases_points,
+				(om.revenue * 0.25) AS revenue_points,
 				(
 					(lm.lifetime_plays * 0.20)
 					+ (lm.lifetime_favorites * 1.00)
@@ -889,6 +922,33 @@ func (r *PgSpecRepository) recalculateTopChartRankings(ctx context.Context, peri
 			JOIN order_metrics om ON om.spec_id = s.id
 			JOIN lifetime_metrics lm ON lm.spec_id = s.id
 		),
+		recent AS (
+			SELECT
+				weighted.*,
+				(
+					plays_points
+					+ favorites_points
+					+ downloads_points
+					+ purchases_points
+					+ revenue_points
+				) AS recent_score
+			FROM weighted
+		),
+		scored AS (
+			SELECT
+				recent.*,
+				authority.points AS lifetime_authority_points,
+				recent.recent_score + authority.points AS score
+			FROM recent
+			CROSS JOIN LATERAL (
+				SELECT recent.lifetime_authority_score *
+					CASE
+						WHEN recent.recent_score >= 5 THEN 0.22
+						WHEN recent.recent_score > 0 THEN 0.12
+						ELSE 0.03
+					END AS points
+			) authority
+		),
 		ranked AS (
 			SELECT
 				spec_id,
@@ -904,6 +964,14 @@ func (r *PgSpecRepository) recalculateTopChartRankings(ctx context.Context, peri
 					'revenue', revenue,
 					'recent_score', recent_score,
 					'lifetime_authority_score', lifetime_authority_score,
+					'contributions', jsonb_build_object(
+						'plays', plays_points,
+						'favorites', favorites_points,
+						'downloads', downloads_points,
+						'purchases', purchases_points,
+						'revenue', revenue_points,
+						'lifetime_authority', lifetime_authority_points
+					),
 					'algorithm_version', 3
 				) AS metrics
 			FROM scored
@@ -987,6 +1055,22 @@ func (r *PgSpecRepository) hydrateSpecRelations(ctx context.Context, specs []dom
 	return nil
 }
 
+// lockRankingRefresh serialises refreshes of one section and period across
+// instances; the lock is released when tx ends.
+func lockRankingRefresh(ctx context.Context, tx *sqlx.Tx, section, period string) error {
+	lockKey := fmt.Sprintf("beat_rankings:%s:%s", section, period)
+	var locked bool
+	if err := tx.GetContext(ctx, &locked, "SELECT pg_try_advisory_xact_lock(hashtext($1))", lockKey); err != nil {
+		log.Printf("[CatalogRepo.Home] advisory lock failed key=%s err=%v", lockKey, err)
+		return err
+	}
+	if !locked {
+		log.Printf("[CatalogRepo.Home] advisory lock busy key=%s", lockKey)
+		return errRankingRefreshSkipped
+	}
+	return nil
+}
+
 func rankingInterval(period string) (string, error) {
 	switch period {
 	case domain.HomePeriod24H:
@@ -1074,7 +1158,7 @@ func (r *PgSpecRepository) Update(ctx context.Context, spec *domain.Spec) error
 		    type = :type,
 		    bpm = :bpm,
 		    key = :key,
-		    base_price = :base_price,
+		    base_price_minor = :base_price_minor,
 		    image_url = :image_url,
 		    description = :description,
 		    tags = :tags,
@@ -1123,16 +1207,16 @@ func (r *PgSpecRepository) Update(ctx context.Context, spec *domain.Spec) error
 		// Define Queries
 		insertQuery := `
             INSERT INTO license_options (
-                id, spec_id, license_type, name, price, price_currency, features, file_types
+                id, spec_id, license_type, name, price_minor, price_currency, features, file_types
             ) VALUES (
-                :id, :spec_id, :license_type, :name, :price, :price_currency, :features, :file_types
+                :id, :spec_id, :license_type, :name, :price_minor, :price_currency, :features, :file_types
             )`
 
 		updateQuery := `
 			UPDATE license_options SET
 				license_type = :license_type,
 				name = :name,
-				price = :price,
+				price_minor = :price_minor,
 				price_currency = :price_currency,
 				features = :features,
 				file_types = :file_types,
@@ -84,3 +84,25 @@ func TestUpdateFilesAndStatusBranches(t *testing.T) {
 		require.ErrorIs(t, repo.UpdateFilesAndStatus(ctx, specID, nil, domain.ProcessingStatusFailed), domain.ErrSpecNotFound)
 	})
 }
+
+func TestSplitSearchPrefix(t *testing.T) {
+	tests := []struct {
+		search, rest, word string
+	}{
+		{"dark tra", "dark", "tra"},
+		{"  Atmos ", "", "atmos"},
+		{"trap -dark", "trap -dark", ""},
+		{`"dark trap"`, `"dark trap"`, ""},
+		{`"dark trap" moo`, `"dark trap"`, "moo"},
+		{`"dark tra`, `"dark tra`, ""},
+		{"trap or dri", "trap or dri", ""},
+		{"trap or", "trap or", ""},
+		{"tra-p!", "tra-p!", ""},
+		{"dark tra:*", "dark tra:*", ""},
+	}
+	for _, tt := range tests {
+		rest, word := splitSearchPrefix(tt.search)
+		require.Equal(t, tt.rest, rest, tt.search)
+		require.Equal(t, tt.word, word, tt.search)
+	}
+}
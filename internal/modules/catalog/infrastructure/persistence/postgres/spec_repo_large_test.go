@@ -30,7 +30,7 @@ func TestPGSpecRepository_CreateAndListEmpty(t *testing.T) {
 		Type:           "WAV",
 		BPM:            120,
 		Key:            "C",
-		BasePrice:      100,
+		BasePriceMinor: 10000,
 		ImageUrl:       "img",
 		PreviewUrl:     "preview",
 		Tags:           pq.StringArray{"trap"},
@@ -47,7 +47,7 @@ func TestPGSpecRepository_CreateAndListEmpty(t *testing.T) {
 	require.NoError(t, repo.Create(ctx, spec))
 
 	mock.ExpectQuery(`SELECT s\.\*, u\.display_name as producer_name, '' as producer_handle, COUNT\(\*\) OVER\(\) as total_count FROM specs s JOIN users u ON s\.producer_id = u\.id WHERE s\.is_deleted = FALSE`).
-		WillReturnRows(sqlmock.NewRows([]string{"id", "producer_id", "title", "category", "type", "bpm", "key", "base_price", "image_url", "preview_url", "duration", "free_mp3_enabled", "total_count"}))
+		WillReturnRows(sqlmock.NewRows([]string{"id", "producer_id", "title", "category", "type", "bpm", "key", "base_price_minor", "image_url", "preview_url", "duration", "free_mp3_enabled", "total_count"}))
 	out, total, err := repo.List(ctx, domain.SpecFilter{Limit: 20, Offset: 0, MinPrice: -1})
 	require.NoError(t, err)
 	assert.Len(t, out, 0)
@@ -94,7 +94,7 @@ func TestPGSpecRepository_UpdateAndGetErrors(t *testing.T) {
 		Type:           "WAV",
 		BPM:            120,
 		Key:            "C",
-		BasePrice:      100,
+		BasePriceMinor: 10000,
 		ImageUrl:       "img",
 		Description:    "desc",
 		Tags:           pq.StringArray{"a"},
@@ -128,13 +128,13 @@ func TestPGSpecRepository_GetByIDAndListByUserID(t *testing.T) {
 	userID := uuid.New()
 
 	rows := sqlmock.NewRows([]string{
-		"id", "producer_id", "title", "category", "type", "bpm", "key", "base_price",
+		"id", "producer_id", "title", "category", "type", "bpm", "key", "base_price_minor",
 		"image_url", "preview_url", "duration", "free_mp3_enabled", "is_deleted", "producer_name",
 	}).
 		AddRow(id, userID, "Track", "beat", "WAV", 120, "C", 100, "img", "prev", 120, true, false, "Producer Name")
 	mock.ExpectQuery(`SELECT s\.\*, u\.display_name as producer_name, '' as producer_handle FROM specs s JOIN users u ON s\.producer_id = u\.id WHERE s\.id = \$1 AND s\.is_deleted = FALSE`).WithArgs(id).WillReturnRows(rows)
 	mock.ExpectQuery("SELECT \\* FROM license_options WHERE spec_id = \\$1 AND is_deleted = FALSE").WithArgs(id).
-		WillReturnRows(sqlmock.NewRows([]string{"id", "spec_id", "license_type", "name", "price", "features", "file_types", "is_deleted"}))
+		WillReturnRows(sqlmock.NewRows([]string{"id", "spec_id", "license_type", "name", "price_minor", "features", "file_types", "is_deleted"}))
 	mock.ExpectQuery("SELECT g\\.\\* FROM genres g JOIN spec_genres sg ON g.id = sg.genre_id WHERE sg.spec_id = \\$1").WithArgs(id).
 		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "slug", "created_at"}))
 	_, err := repo.GetByID(ctx, id)
@@ -143,7 +143,7 @@ func TestPGSpecRepository_GetByIDAndListByUserID(t *testing.T) {
 	mock.ExpectQuery(`SELECT s\.\*, u\.display_name as producer_name, '' as producer_handle, COUNT\(\*\) OVER\(\) as total_count FROM specs s JOIN users u ON s\.producer_id = u\.id WHERE s\.producer_id = \$1 AND s\.is_deleted = FALSE AND s\.processing_status = 'completed' ORDER BY s\.created_at DESC LIMIT \$2 OFFSET \$3`).
 		WithArgs(userID, 20, 0).
 		WillReturnRows(sqlmock.NewRows([]string{
-			"id", "producer_id", "title", "category", "type", "bpm", "key", "base_price",
+			"id", "producer_id", "title", "category", "type", "bpm", "key", "base_price_minor",
 			"image_url", "preview_url", "duration", "free_mp3_enabled", "is_deleted", "total_count", "producer_name",
 		}))
 	specs, total, err := repo.ListByUserID(ctx, userID, 20, 0)
@@ -164,7 +164,7 @@ func TestPGSpecRepository_ListWithRelationsAndUpdateNotFound(t *testing.T) {
 	producerID := uuid.New()
 
 	mainRows := sqlmock.NewRows([]string{
-		"id", "producer_id", "title", "category", "type", "bpm", "key", "base_price", "image_url", "preview_url", "duration", "free_mp3_enabled", "total_count", "producer_name",
+		"id", "producer_id", "title", "category", "type", "bpm", "key", "base_price_minor", "image_url", "preview_url", "duration", "free_mp3_enabled", "total_count", "producer_name",
 	}).AddRow(specID, producerID, "Track", "beat", "WAV", 120, "C", 10.0, "img", "prev", 120, true, 1, "Producer Name")
 
 	mock.ExpectQuery(`SELECT s\.\*, u\.display_name as producer_name, '' as producer_handle, COUNT\(\*\) OVER\(\) as total_count FROM specs s JOIN users u ON s\.producer_id = u\.id WHERE s\.is_deleted = FALSE`).
@@ -172,7 +172,7 @@ func TestPGSpecRepository_ListWithRelationsAndUpdateNotFound(t *testing.T) {
 	mock.ExpectQuery("SELECT sg.spec_id, g.\\*").
 		WillReturnRows(sqlmock.NewRows([]string{"spec_id", "id", "name", "slug", "created_at"}).AddRow(specID, genreID, "Hip Hop", "hip-hop", time.Now()))
 	mock.ExpectQuery("SELECT \\* FROM license_options WHERE spec_id IN").
-		WillReturnRows(sqlmock.NewRows([]string{"id", "spec_id", "license_type", "name", "price", "features", "file_types", "is_deleted"}).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "spec_id", "license_type", "name", "price_minor", "features", "file_types", "is_deleted"}).
 			AddRow(licenseID, specID, "Basic", "Basic", 10.0, pq.StringArray{"f1"}, pq.StringArray{"mp3"}, false))
 
 	out, total, err := repo.List(ctx, domain.SpecFilter{Limit: 20, Offset: 0, MinPrice: -1, Sort: "price_asc"})
@@ -202,25 +202,25 @@ func TestPGSpecRepository_UpdateLicenseSyncDeletionBranches(t *testing.T) {
 	usedLicenseID := uuid.MustParse("aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa")
 	unusedLicenseID := uuid.MustParse("bbbbbbbb-bbbb-bbbb-bbbb-bbbbbbbbbbbb")
 	spec := &domain.Spec{
-		ID:         specID,
-		ProducerID: producerID,
-		Title:      "Track",
-		Category:   domain.CategoryBeat,
-		Type:       "WAV",
-		BPM:        120,
-		Key:        "C",
-		BasePrice:  10,
-		ImageUrl:   "img",
-		Tags:       pq.StringArray{"a"},
-		Duration:   120,
-		Licenses:   []domain.LicenseOption{},
+		ID:             specID,
+		ProducerID:     producerID,
+		Title:          "Track",
+		Category:       domain.CategoryBeat,
+		Type:           "WAV",
+		BPM:            120,
+		Key:            "C",
+		BasePriceMinor: 1000,
+		ImageUrl:       "img",
+		Tags:           pq.StringArray{"a"},
+		Duration:       120,
+		Licenses:       []domain.LicenseOption{},
 	}
 
 	mock.ExpectBegin()
 	mock.ExpectExec("UPDATE specs").
 		WillReturnResult(sqlmock.NewResult(0, 1))
 	existingRows := sqlmock.NewRows([]string{
-		"id", "spec_id", "license_type", "name", "price", "features", "file_types", "is_deleted",
+		"id", "spec_id", "license_type", "name", "price_minor", "features", "file_types", "is_deleted",
 	}).
 		AddRow(usedLicenseID, specID, "Basic", "Basic", 10, pq.StringArray{"f1"}, pq.StringArray{"mp3"}, false).
 		AddRow(unusedLicenseID, specID, "Premium", "Premium", 20, pq.StringArray{"f2"}, pq.StringArray{"wav"}, false)
@@ -250,21 +250,21 @@ func TestPGSpecRepository_CreateWithGenresAndLicenses(t *testing.T) {
 	specID := uuid.New()
 	producerID := uuid.New()
 	spec := &domain.Spec{
-		ID:         specID,
-		ProducerID: producerID,
-		Title:      "Track",
-		Category:   domain.CategoryBeat,
-		Type:       "WAV",
-		BPM:        120,
-		Key:        "C",
-		BasePrice:  10,
-		ImageUrl:   "img",
-		PreviewUrl: "prev",
+		ID:             specID,
+		ProducerID:     producerID,
+		Title:          "Track",
+		Category:       domain.CategoryBeat,
+		Type:           "WAV",
+		BPM:            120,
+		Key:            "C",
+		BasePriceMinor: 1000,
+		ImageUrl:       "img",
+		PreviewUrl:     "prev",
 		Genres: []domain.Genre{
 			{Name: "Hip Hop", Slug: "hip-hop"},
 		},
 		Licenses: []domain.LicenseOption{
-			{LicenseType: domain.LicenseBasic, Name: "Basic", Price: 10, Features: pq.StringArray{"f1"}, FileTypes: pq.StringArray{"mp3"}},
+			{LicenseType: domain.LicenseBasic, Name: "Basic", PriceMinor: 1000, Features: pq.StringArray{"f1"}, FileTypes: pq.StringArray{"mp3"}},
 		},
 	}
 
@@ -292,27 +292,27 @@ func TestPGSpecRepository_UpdateLicenseUpsertBranches(t *testing.T) {
 	producerID := uuid.New()
 	existingID := uuid.New()
 	spec := &domain.Spec{
-		ID:         specID,
-		ProducerID: producerID,
-		Title:      "Track",
-		Category:   domain.CategoryBeat,
-		Type:       "WAV",
-		BPM:        120,
-		Key:        "C",
-		BasePrice:  10,
-		ImageUrl:   "img",
-		Tags:       pq.StringArray{"a"},
-		Duration:   120,
+		ID:             specID,
+		ProducerID:     producerID,
+		Title:          "Track",
+		Category:       domain.CategoryBeat,
+		Type:           "WAV",
+		BPM:            120,
+		Key:            "C",
+		BasePriceMinor: 1000,
+		ImageUrl:       "img",
+		Tags:           pq.StringArray{"a"},
+		Duration:       120,
 		Licenses: []domain.LicenseOption{
-			{LicenseType: domain.LicenseBasic, Name: "Basic", Price: 12, Features: pq.StringArray{"f1"}, FileTypes: pq.StringArray{"mp3"}},
-			{LicenseType: domain.LicensePremium, Name: "Premium", Price: 20, Features: pq.StringArray{"f2"}, FileTypes: pq.StringArray{"wav"}},
+			{LicenseType: domain.LicenseBasic, Name: "Basic", PriceMinor: 1200, Features: pq.StringArray{"f1"}, FileTypes: pq.StringArray{"mp3"}},
+			{LicenseType: domain.LicensePremium, Name: "Premium", PriceMinor: 2000, Features: pq.StringArray{"f2"}, FileTypes: pq.StringArray{"wav"}},
 		},
 	}
 
 	mock.ExpectBegin()
 	mock.ExpectExec("UPDATE specs").WillReturnResult(sqlmock.NewResult(0, 1))
 	rows := sqlmock.NewRows([]string{
-		"id", "spec_id", "license_type", "name", "price", "features", "file_types", "is_deleted",
+		"id", "spec_id", "license_type", "name", "price_minor", "features", "file_types", "is_deleted",
 	}).AddRow(existingID, specID, "Basic", "Basic", 10, pq.StringArray{"f1"}, pq.StringArray{"mp3"}, false)
 	mock.ExpectQuery("SELECT \\* FROM license_options WHERE spec_id = \\$1 AND is_deleted = FALSE").
 		WithArgs(specID).WillReturnRows(rows)
@@ -332,17 +332,17 @@ func TestPGSpecRepository_List_WithAllFiltersAndProducerName(t *testing.T) {
 	specID := uuid.New()
 	producerID := uuid.New()
 	mainRows := sqlmock.NewRows([]string{
-		"id", "producer_id", "title", "category", "type", "bpm", "key", "base_price",
+		"id", "producer_id", "title", "category", "type", "bpm", "key", "base_price_minor",
 		"image_url", "preview_url", "duration", "free_mp3_enabled", "total_count", "producer_name",
 	}).AddRow(specID, producerID, "Track", "beat", "WAV", 120, "C", 10.0, "img", "prev", 120, true, 1, "Producer Alias")
 
 	mock.ExpectQuery("SELECT s\\.\\*, u\\.display_name as producer_name, '' as producer_handle, COUNT\\(\\*\\) OVER\\(\\) as total_count").
-		WithArgs("beat", sqlmock.AnyArg(), sqlmock.AnyArg(), "%track%", 100, 160, 5.0, 20.0, "C", 10, 0).
+		WithArgs("beat", sqlmock.AnyArg(), sqlmock.AnyArg(), "", "track", "track:*", 100, 160, int64(500), int64(2000), "C", 10, 0).
 		WillReturnRows(mainRows)
 	mock.ExpectQuery("SELECT sg\\.spec_id, g\\.\\*").
 		WillReturnRows(sqlmock.NewRows([]string{"spec_id", "id", "name", "slug", "created_at"}))
 	mock.ExpectQuery("SELECT \\* FROM license_options WHERE spec_id IN").
-		WillReturnRows(sqlmock.NewRows([]string{"id", "spec_id", "license_type", "name", "price", "features", "file_types", "is_deleted"}))
+		WillReturnRows(sqlmock.NewRows([]string{"id", "spec_id", "license_type", "name", "price_minor", "features", "file_types", "is_deleted"}))
 
 	out, total, err := repo.List(ctx, domain.SpecFilter{
 		Category: "beat",
@@ -351,8 +351,8 @@ func TestPGSpecRepository_List_WithAllFiltersAndProducerName(t *testing.T) {
 		Search:   "track",
 		MinBPM:   100,
 		MaxBPM:   160,
-		MinPrice: 5,
-		MaxPrice: 20,
+		MinPrice: 500,
+		MaxPrice: 2000,
 		Key:      "C",
 		Sort:     "bpm_desc",
 		Limit:    10,
@@ -364,6 +364,68 @@ func TestPGSpecRepository_List_WithAllFiltersAndProducerName(t *testing.T) {
 	assert.Equal(t, "Producer Alias", out[0].ProducerName)
 }
 
+func TestPGSpecRepository_List_FullTextSearchRanksAndHighlights(t *testing.T) {
+	db, mock, cleanup := newMockDB(t)
+	defer cleanup()
+	repo := postgres.NewSpecRepository(db)
+	ctx := context.Background()
+
+	matchedID := uuid.New()
+	descriptionOnlyID := uuid.New()
+	producerID := uuid.New()
+	mainRows := sqlmock.NewRows([]string{
+		"id", "producer_id", "title", "category", "type", "bpm", "key", "base_price_minor",
+		"image_url", "preview_url", "duration", "free_mp3_enabled", "total_count", "producer_name",
+		"search_rank", "title_highlight", "description_highlight",
+	}).
+		AddRow(matchedID, producerID, "Dark Trap", "beat", "WAV", 140, "C", 10.0, "img", "prev", 120, true, 2, "Producer",
+			0.8, "<mark>Dark</mark> <mark>Trap</mark>", "Moody &amp; <mark>dark</mark>").
+		AddRow(descriptionOnlyID, producerID, "Night Drive", "beat", "WAV", 90, "A", 10.0, "img", "prev", 120, true, 2, "Producer",
+			0.1, "Night Drive", nil)
+
+	mock.ExpectQuery(`SELECT s\.\*, u\.display_name as producer_name, '' as producer_handle, COUNT\(\*\) OVER\(\) as total_count, ts_rank_cd\(d\.document, sq\.query\) as search_rank`).
+		WithArgs("Dark", "tra", "tra:*", 10, 0).
+		WillReturnRows(mainRows)
+	mock.ExpectQuery("SELECT sg\\.spec_id, g\\.\\*").
+		WillReturnRows(sqlmock.NewRows([]string{"spec_id", "id", "name", "slug", "created_at"}))
+	mock.ExpectQuery("SELECT \\* FROM license_options WHERE spec_id IN").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "spec_id", "license_type", "name", "price_minor", "features", "file_types", "is_deleted"}))
+
+	out, total, err := repo.List(ctx, domain.SpecFilter{Search: "  Dark tra ", Limit: 10, Offset: 0, MinPrice: -1})
+	require.NoError(t, err)
+	require.Len(t, out, 2)
+	assert.Equal(t, 2, total)
+	require.NotNil(t, out[0].Highlights)
+	assert.Equal(t, "<mark>Dark</mark> <mark>Trap</mark>", out[0].Highlights.Title)
+	assert.Equal(t, "Moody &amp; <mark>dark</mark>", out[0].Highlights.Description)
+	assert.Nil(t, out[1].Highlights)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPGSpecRepository_List_RelevanceSortRequiresSearch(t *testing.T) {
+	db, mock, cleanup := newMockDB(t)
+	defer cleanup()
+	repo := postgres.NewSpecRepository(db)
+	ctx := context.Background()
+
+	emptyRows := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"id", "producer_id", "title", "total_count"})
+	}
+
+	mock.ExpectQuery(`ORDER BY search_rank DESC, s\.created_at DESC LIMIT`).WillReturnRows(emptyRows())
+	_, _, err := repo.List(ctx, domain.SpecFilter{Search: "trap", Sort: "relevance", Limit: 10, MinPrice: -1})
+	require.NoError(t, err)
+
+	mock.ExpectQuery(`AND d\.document @@ sq\.query ORDER BY s\.base_price_minor ASC LIMIT`).WillReturnRows(emptyRows())
+	_, _, err = repo.List(ctx, domain.SpecFilter{Search: "trap", Sort: "price_asc", Limit: 10, MinPrice: -1})
+	require.NoError(t, err)
+
+	mock.ExpectQuery(`FROM specs s JOIN users u ON s\.producer_id = u\.id WHERE s\.is_deleted = FALSE AND s\.processing_status = 'completed' ORDER BY s\.created_at DESC LIMIT`).WillReturnRows(emptyRows())
+	_, _, err = repo.List(ctx, domain.SpecFilter{Sort: "relevance", Limit: 10, MinPrice: -1})
+	require.NoError(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestPGSpecRepository_ListByUserID_WithRowsAndRelations(t *testing.T) {
 	db, mock, cleanup := newMockDB(t)
 	defer cleanup()
@@ -376,7 +438,7 @@ func TestPGSpecRepository_ListByUserID_WithRowsAndRelations(t *testing.T) {
 	genreID := uuid.New()
 
 	mainRows := sqlmock.NewRows([]string{
-		"id", "producer_id", "title", "category", "type", "bpm", "key", "base_price",
+		"id", "producer_id", "title", "category", "type", "bpm", "key", "base_price_minor",
 		"image_url", "preview_url", "duration", "free_mp3_enabled", "is_deleted", "total_count", "producer_name",
 	}).AddRow(specID, producerID, "Track", "beat", "WAV", 120, "C", 10.0, "img", "prev", 120, true, false, 1, "Producer Alias")
 
@@ -387,7 +449,7 @@ func TestPGSpecRepository_ListByUserID_WithRowsAndRelations(t *testing.T) {
 		WillReturnRows(sqlmock.NewRows([]string{"spec_id", "id", "name", "slug", "created_at"}).
 			AddRow(specID, genreID, "Hip Hop", "hip-hop", time.Now()))
 	mock.ExpectQuery("SELECT \\* FROM license_options WHERE spec_id IN").
-		WillReturnRows(sqlmock.NewRows([]string{"id", "spec_id", "license_type", "name", "price", "features", "file_types", "is_deleted"}).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "spec_id", "license_type", "name", "price_minor", "features", "file_types", "is_deleted"}).
 			AddRow(licenseID, specID, "Basic", "Basic", 10.0, pq.StringArray{"f1"}, pq.StringArray{"mp3"}, false))
 
 	out, total, err := repo.ListByUserID(ctx, producerID, 10, 0)
@@ -418,7 +480,7 @@ func TestPGSpecRepository_ListByUserID_ErrorBranches(t *testing.T) {
 
 	t.Run("genre fetch error", func(t *testing.T) {
 		mainRows := sqlmock.NewRows([]string{
-			"id", "producer_id", "title", "category", "type", "bpm", "key", "base_price",
+			"id", "producer_id", "title", "category", "type", "bpm", "key", "base_price_minor",
 			"image_url", "preview_url", "duration", "free_mp3_enabled", "is_deleted", "total_count", "producer_name",
 		}).AddRow(specID, producerID, "Track", "beat", "WAV", 120, "C", 10.0, "img", "prev", 120, true, false, 1, "Producer Alias")
 
@@ -434,7 +496,7 @@ func TestPGSpecRepository_ListByUserID_ErrorBranches(t *testing.T) {
 
 	t.Run("license fetch error", func(t *testing.T) {
 		mainRows := sqlmock.NewRows([]string{
-			"id", "producer_id", "title", "category", "type", "bpm", "key", "base_price",
+			"id", "producer_id", "title", "category", "type", "bpm", "key", "base_price_minor",
 			"image_url", "preview_url", "duration", "free_mp3_enabled", "is_deleted", "total_count", "producer_name",
 		}).AddRow(specID, producerID, "Track", "beat", "WAV", 120, "C", 10.0, "img", "prev", 120, true, false, 1, "Producer Alias")
 
@@ -461,7 +523,7 @@ func TestPGSpecRepository_GetByIDSystem_SuccessAndErrors(t *testing.T) {
 
 	t.Run("success", func(t *testing.T) {
 		rows := sqlmock.NewRows([]string{
-			"id", "producer_id", "title", "category", "type", "bpm", "key", "base_price",
+			"id", "producer_id", "title", "category", "type", "bpm", "key", "base_price_minor",
 			"image_url", "preview_url", "duration", "free_mp3_enabled", "is_deleted", "producer_name",
 		}).AddRow(id, userID, "Track", "beat", "WAV", 120, "C", 100, "img", "prev", 120, true, false, "Producer Name")
 
@@ -469,7 +531,7 @@ func TestPGSpecRepository_GetByIDSystem_SuccessAndErrors(t *testing.T) {
 			WithArgs(id).WillReturnRows(rows)
 		mock.ExpectQuery("SELECT \\* FROM license_options WHERE spec_id = \\$1 AND is_deleted = FALSE").
 			WithArgs(id).
-			WillReturnRows(sqlmock.NewRows([]string{"id", "spec_id", "license_type", "name", "price", "features", "file_types", "is_deleted"}))
+			WillReturnRows(sqlmock.NewRows([]string{"id", "spec_id", "license_type", "name", "price_minor", "features", "file_types", "is_deleted"}))
 		mock.ExpectQuery("SELECT g\\.\\* FROM genres g JOIN spec_genres sg ON g.id = sg.genre_id WHERE sg.spec_id = \\$1").
 			WithArgs(id).
 			WillReturnRows(sqlmock.NewRows([]string{"id", "name", "slug", "created_at"}))
@@ -481,7 +543,7 @@ func TestPGSpecRepository_GetByIDSystem_SuccessAndErrors(t *testing.T) {
 
 	t.Run("license query error", func(t *testing.T) {
 		rows := sqlmock.NewRows([]string{
-			"id", "producer_id", "title", "category", "type", "bpm", "key", "base_price",
+			"id", "producer_id", "title", "category", "type", "bpm", "key", "base_price_minor",
 			"image_url", "preview_url", "duration", "free_mp3_enabled", "is_deleted", "producer_name",
 		}).AddRow(id, userID, "Track", "beat", "WAV", 120, "C", 100, "img", "prev", 120, true, false, "Producer Name")
 
@@ -497,7 +559,7 @@ func TestPGSpecRepository_GetByIDSystem_SuccessAndErrors(t *testing.T) {
 
 	t.Run("genre query error", func(t *testing.T) {
 		rows := sqlmock.NewRows([]string{
-			"id", "producer_id", "title", "category", "type", "bpm", "key", "base_price",
+			"id", "producer_id", "title", "category", "type", "bpm", "key", "base_price_minor",
 			"image_url", "preview_url", "duration", "free_mp3_enabled", "is_deleted", "producer_name",
 		}).AddRow(id, userID, "Track", "beat", "WAV", 120, "C", 100, "img", "prev", 120, true, false, "Producer Name")
 
@@ -505,7 +567,7 @@ func TestPGSpecRepository_GetByIDSystem_SuccessAndErrors(t *testing.T) {
 			WithArgs(id).WillReturnRows(rows)
 		mock.ExpectQuery("SELECT \\* FROM license_options WHERE spec_id = \\$1 AND is_deleted = FALSE").
 			WithArgs(id).
-			WillReturnRows(sqlmock.NewRows([]string{"id", "spec_id", "license_type", "name", "price", "features", "file_types", "is_deleted"}))
+			WillReturnRows(sqlmock.NewRows([]string{"id", "spec_id", "license_type", "name", "price_minor", "features", "file_types", "is_deleted"}))
 		mock.ExpectQuery("SELECT g\\.\\* FROM genres g JOIN spec_genres sg ON g.id = sg.genre_id WHERE sg.spec_id = \\$1").
 			WithArgs(id).
 			WillReturnError(errors.New("genre query failed"))
@@ -564,7 +626,7 @@ func TestPGSpecRepository_GetByID_QueryFailures(t *testing.T) {
 	userID := uuid.New()
 
 	rows := sqlmock.NewRows([]string{
-		"id", "producer_id", "title", "category", "type", "bpm", "key", "base_price",
+		"id", "producer_id", "title", "category", "type", "bpm", "key", "base_price_minor",
 		"image_url", "preview_url", "duration", "free_mp3_enabled", "is_deleted", "producer_name",
 	}).AddRow(id, userID, "Track", "beat", "WAV", 120, "C", 100, "img", "prev", 120, true, false, "Producer Name")
 
@@ -577,14 +639,14 @@ func TestPGSpecRepository_GetByID_QueryFailures(t *testing.T) {
 	assert.EqualError(t, err, "license failed")
 
 	rows2 := sqlmock.NewRows([]string{
-		"id", "producer_id", "title", "category", "type", "bpm", "key", "base_price",
+		"id", "producer_id", "title", "category", "type", "bpm", "key", "base_price_minor",
 		"image_url", "preview_url", "duration", "free_mp3_enabled", "is_deleted", "producer_name",
 	}).AddRow(id, userID, "Track", "beat", "WAV", 120, "C", 100, "img", "prev", 120, true, false, "Producer Name")
 	mock.ExpectQuery(`SELECT s\.\*, u\.display_name as producer_name, '' as producer_handle FROM specs s JOIN users u ON s\.producer_id = u\.id WHERE s\.id = \$1 AND s\.is_deleted = FALSE`).
 		WithArgs(id).WillReturnRows(rows2)
 	mock.ExpectQuery("SELECT \\* FROM license_options WHERE spec_id = \\$1 AND is_deleted = FALSE").
 		WithArgs(id).
-		WillReturnRows(sqlmock.NewRows([]string{"id", "spec_id", "license_type", "name", "price", "features", "file_types", "is_deleted"}))
+		WillReturnRows(sqlmock.NewRows([]string{"id", "spec_id", "license_type", "name", "price_minor", "features", "file_types", "is_deleted"}))
 	mock.ExpectQuery("SELECT g\\.\\* FROM genres g JOIN spec_genres sg ON g.id = sg.genre_id WHERE sg.spec_id = \\$1").
 		WithArgs(id).
 		WillReturnError(errors.New("genres failed"))
@@ -606,7 +668,7 @@ func TestPGSpecRepository_List_QueryFailures(t *testing.T) {
 	assert.EqualError(t, err, "list failed")
 
 	mainRows := sqlmock.NewRows([]string{
-		"id", "producer_id", "title", "category", "type", "bpm", "key", "base_price",
+		"id", "producer_id", "title", "category", "type", "bpm", "key", "base_price_minor",
 		"image_url", "preview_url", "duration", "free_mp3_enabled", "total_count", "producer_name",
 	}).AddRow(specID, producerID, "Track", "beat", "WAV", 120, "C", 10.0, "img", "prev", 120, true, 1, "Producer Alias")
 	mock.ExpectQuery("SELECT s\\.\\*, u\\.display_name as producer_name, '' as producer_handle, COUNT\\(\\*\\) OVER\\(\\) as total_count").
@@ -617,7 +679,7 @@ func TestPGSpecRepository_List_QueryFailures(t *testing.T) {
 	assert.EqualError(t, err, "failed to fetch genres: genres failed")
 
 	mainRows = sqlmock.NewRows([]string{
-		"id", "producer_id", "title", "category", "type", "bpm", "key", "base_price",
+		"id", "producer_id", "title", "category", "type", "bpm", "key", "base_price_minor",
 		"image_url", "preview_url", "duration", "free_mp3_enabled", "total_count", "producer_name",
 	}).AddRow(specID, producerID, "Track", "beat", "WAV", 120, "C", 10.0, "img", "prev", 120, true, 1, "Producer Alias")
 	mock.ExpectQuery("SELECT s\\.\\*, u\\.display_name as producer_name, '' as producer_handle, COUNT\\(\\*\\) OVER\\(\\) as total_count").
@@ -639,7 +701,7 @@ func TestPGSpecRepository_List_SortModes(t *testing.T) {
 	sorts := []string{"newest", "oldest", "price_asc", "price_desc", "bpm_asc", "bpm_desc"}
 	for _, sortMode := range sorts {
 		mock.ExpectQuery("SELECT s\\.\\*, u\\.display_name as producer_name, '' as producer_handle, COUNT\\(\\*\\) OVER\\(\\) as total_count").
-			WillReturnRows(sqlmock.NewRows([]string{"id", "producer_id", "title", "category", "type", "bpm", "key", "base_price", "image_url", "preview_url", "duration", "free_mp3_enabled", "total_count", "producer_name"}))
+			WillReturnRows(sqlmock.NewRows([]string{"id", "producer_id", "title", "category", "type", "bpm", "key", "base_price_minor", "image_url", "preview_url", "duration", "free_mp3_enabled", "total_count", "producer_name"}))
 		_, _, err := repo.List(ctx, domain.SpecFilter{Sort: sortMode, Limit: 10, Offset: 0, MinPrice: -1})
 		require.NoError(t, err)
 	}
@@ -705,17 +767,17 @@ func TestPGSpecRepository_Update_ErrorBranches(t *testing.T) {
 	producerID := uuid.New()
 
 	baseSpec := &domain.Spec{
-		ID:         specID,
-		ProducerID: producerID,
-		Title:      "Track",
-		Category:   domain.CategoryBeat,
-		Type:       "WAV",
-		BPM:        120,
-		Key:        "C",
-		BasePrice:  10,
-		ImageUrl:   "img",
-		Tags:       pq.StringArray{"a"},
-		Duration:   120,
+		ID:             specID,
+		ProducerID:     producerID,
+		Title:          "Track",
+		Category:       domain.CategoryBeat,
+		Type:           "WAV",
+		BPM:            120,
+		Key:            "C",
+		BasePriceMinor: 1000,
+		ImageUrl:       "img",
+		Tags:           pq.StringArray{"a"},
+		Duration:       120,
 	}
 
 	t.Run("begin tx error", func(t *testing.T) {
@@ -734,7 +796,7 @@ func TestPGSpecRepository_Update_ErrorBranches(t *testing.T) {
 
 	t.Run("existing licenses query error", func(t *testing.T) {
 		spec := *baseSpec
-		spec.Licenses = []domain.LicenseOption{{LicenseType: domain.LicenseBasic, Name: "Basic", Price: 10}}
+		spec.Licenses = []domain.LicenseOption{{LicenseType: domain.LicenseBasic, Name: "Basic", PriceMinor: 1000}}
 		mock.ExpectBegin()
 		mock.ExpectExec("UPDATE specs").WillReturnResult(sqlmock.NewResult(0, 1))
 		mock.ExpectQuery("SELECT \\* FROM license_options WHERE spec_id = \\$1 AND is_deleted = FALSE").
@@ -752,7 +814,7 @@ func TestPGSpecRepository_Update_ErrorBranches(t *testing.T) {
 		mock.ExpectExec("UPDATE specs").WillReturnResult(sqlmock.NewResult(0, 1))
 		mock.ExpectQuery("SELECT \\* FROM license_options WHERE spec_id = \\$1 AND is_deleted = FALSE").
 			WithArgs(specID).
-			WillReturnRows(sqlmock.NewRows([]string{"id", "spec_id", "license_type", "name", "price", "features", "file_types", "is_deleted"}).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "spec_id", "license_type", "name", "price_minor", "features", "file_types", "is_deleted"}).
 				AddRow(existingID, specID, "Basic", "Basic", 10, pq.StringArray{"f1"}, pq.StringArray{"mp3"}, false))
 		mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM licenses WHERE license_option_id = \\$1").
 			WithArgs(existingID).WillReturnError(errors.New("usage failed"))
@@ -769,7 +831,7 @@ func TestPGSpecRepository_Update_ErrorBranches(t *testing.T) {
 				ID:          unknownID,
 				LicenseType: domain.LicensePremium,
 				Name:        "Premium",
-				Price:       20,
+				PriceMinor:  2000,
 				Features:    pq.StringArray{"f1"},
 				FileTypes:   pq.StringArray{"wav"},
 			},
@@ -778,7 +840,7 @@ func TestPGSpecRepository_Update_ErrorBranches(t *testing.T) {
 		mock.ExpectExec("UPDATE specs").WillReturnResult(sqlmock.NewResult(0, 1))
 		mock.ExpectQuery("SELECT \\* FROM license_options WHERE spec_id = \\$1 AND is_deleted = FALSE").
 			WithArgs(specID).
-			WillReturnRows(sqlmock.NewRows([]string{"id", "spec_id", "license_type", "name", "price", "features", "file_types", "is_deleted"}))
+			WillReturnRows(sqlmock.NewRows([]string{"id", "spec_id", "license_type", "name", "price_minor", "features", "file_types", "is_deleted"}))
 		mock.ExpectExec("UPDATE license_options SET").WillReturnResult(sqlmock.NewResult(0, 0))
 		mock.ExpectExec("INSERT INTO license_options").WillReturnResult(sqlmock.NewResult(0, 1))
 		mock.ExpectCommit()
@@ -793,7 +855,7 @@ func TestPGSpecRepository_Update_ErrorBranches(t *testing.T) {
 		mock.ExpectExec("UPDATE specs").WillReturnResult(sqlmock.NewResult(0, 1))
 		mock.ExpectQuery("SELECT \\* FROM license_options WHERE spec_id = \\$1 AND is_deleted = FALSE").
 			WithArgs(specID).
-			WillReturnRows(sqlmock.NewRows([]string{"id", "spec_id", "license_type", "name", "price", "features", "file_types", "is_deleted"}).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "spec_id", "license_type", "name", "price_minor", "features", "file_types", "is_deleted"}).
 				AddRow(existingID, specID, "Basic", "Basic", 10, pq.StringArray{"f1"}, pq.StringArray{"mp3"}, false))
 		mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM licenses WHERE license_option_id = \\$1").
 			WithArgs(existingID).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
@@ -808,13 +870,13 @@ func TestPGSpecRepository_Update_ErrorBranches(t *testing.T) {
 		existingID := uuid.New()
 		spec := *baseSpec
 		spec.Licenses = []domain.LicenseOption{
-			{ID: existingID, LicenseType: domain.LicenseBasic, Name: "Basic", Price: 10},
+			{ID: existingID, LicenseType: domain.LicenseBasic, Name: "Basic", PriceMinor: 1000},
 		}
 		mock.ExpectBegin()
 		mock.ExpectExec("UPDATE specs").WillReturnResult(sqlmock.NewResult(0, 1))
 		mock.ExpectQuery("SELECT \\* FROM license_options WHERE spec_id = \\$1 AND is_deleted = FALSE").
 			WithArgs(specID).
-			WillReturnRows(sqlmock.NewRows([]string{"id", "spec_id", "license_type", "name", "price", "features", "file_types", "is_deleted"}).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "spec_id", "license_type", "name", "price_minor", "features", "file_types", "is_deleted"}).
 				AddRow(existingID, specID, "Basic", "Basic", 10, pq.StringArray{"f1"}, pq.StringArray{"mp3"}, false))
 		mock.ExpectExec("UPDATE license_options SET").WillReturnError(errors.New("license update failed"))
 		mock.ExpectRollback()
@@ -825,13 +887,13 @@ func TestPGSpecRepository_Update_ErrorBranches(t *testing.T) {
 	t.Run("new license insert error", func(t *testing.T) {
 		spec := *baseSpec
 		spec.Licenses = []domain.LicenseOption{
-			{LicenseType: domain.LicensePremium, Name: "Premium", Price: 20},
+			{LicenseType: domain.LicensePremium, Name: "Premium", PriceMinor: 2000},
 		}
 		mock.ExpectBegin()
 		mock.ExpectExec("UPDATE specs").WillReturnResult(sqlmock.NewResult(0, 1))
 		mock.ExpectQuery("SELECT \\* FROM license_options WHERE spec_id = \\$1 AND is_deleted = FALSE").
 			WithArgs(specID).
-			WillReturnRows(sqlmock.NewRows([]string{"id", "spec_id", "license_type", "name", "price", "features", "file_types", "is_deleted"}))
+			WillReturnRows(sqlmock.NewRows([]string{"id", "spec_id", "license_type", "name", "price_minor", "features", "file_types", "is_deleted"}))
 		mock.ExpectExec("INSERT INTO license_options").WillReturnError(errors.New("insert failed"))
 		mock.ExpectRollback()
 		err := repo.Update(ctx, &spec)
@@ -849,22 +911,22 @@ func TestPGSpecRepository_Create_GenreUpsertReturnsConcurrentWinner(t *testing.T
 	genreID := uuid.New()
 
 	spec := &domain.Spec{
-		ID:         specID,
-		ProducerID: producerID,
-		Title:      "Track",
-		Category:   domain.CategoryBeat,
-		Type:       "WAV",
-		BPM:        120,
-		Key:        "C",
-		BasePrice:  10,
-		ImageUrl:   "img",
-		PreviewUrl: "prev",
-		Duration:   120,
+		ID:             specID,
+		ProducerID:     producerID,
+		Title:          "Track",
+		Category:       domain.CategoryBeat,
+		Type:           "WAV",
+		BPM:            120,
+		Key:            "C",
+		BasePriceMinor: 1000,
+		ImageUrl:       "img",
+		PreviewUrl:     "prev",
+		Duration:       120,
 		Genres: []domain.Genre{
 			{Name: "Hip Hop", Slug: "hip-hop"},
 		},
 		Licenses: []domain.LicenseOption{
-			{LicenseType: domain.LicenseBasic, Name: "Basic", Price: 10, Features: pq.StringArray{"f1"}, FileTypes: pq.StringArray{"mp3"}},
+			{LicenseType: domain.LicenseBasic, Name: "Basic", PriceMinor: 1000, Features: pq.StringArray{"f1"}, FileTypes: pq.StringArray{"mp3"}},
 		},
 	}
 
@@ -881,3 +943,38 @@ func TestPGSpecRepository_Create_GenreUpsertReturnsConcurrentWinner(t *testing.T
 
 	require.NoError(t, repo.Create(ctx, spec))
 }
+
+func TestPGSpecRepository_SuggestSearch(t *testing.T) {
+	db, mock, cleanup := newMockDB(t)
+	defer cleanup()
+	repo := postgres.NewSpecRepository(db)
+	ctx := context.Background()
+
+	specID := uuid.New()
+	mock.ExpectBegin()
+	mock.ExpectExec(`SELECT set_config\('pg_trgm\.similarity_threshold', \$1, true\), set_config\('pg_trgm\.word_similarity_threshold', \$2, true\)`).
+		WithArgs("0.2", "0.4").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`WITH q AS \( SELECT \$1::text AS term \)`).
+		WithArgs("trp", 5, 3).
+		WillReturnRows(sqlmock.NewRows([]string{"type", "value", "id", "slug", "score"}).
+			AddRow("genre", "Trap", nil, "trap", 0.29).
+			AddRow("spec", "Trippy Nights", specID, "trippy-nights", 0.25))
+	mock.ExpectRollback()
+
+	out, err := repo.SuggestSearch(ctx, "trp", 5)
+	require.NoError(t, err)
+	require.Len(t, out, 2)
+	assert.Equal(t, domain.SuggestionGenre, out[0].Type)
+	assert.Nil(t, out[0].ID)
+	require.NotNil(t, out[1].ID)
+	assert.Equal(t, specID, *out[1].ID)
+	require.NoError(t, mock.ExpectationsWereMet())
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`set_config`).WillReturnError(errors.New("unrecognized configuration parameter"))
+	mock.ExpectRollback()
+	_, err = repo.SuggestSearch(ctx, "trp", 5)
+	assert.ErrorContains(t, err, "failed to set similarity thresholds")
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -57,7 +57,7 @@ func TestPgSpecRepository_List_PartialSearch(t *testing.T) {
 		ProducerID:       producerID,
 		Title:            "Atmospheric Trap Beat",
 		Category:         domain.CategoryBeat,
-		BasePrice:        25.0,
+		BasePriceMinor:   2500,
 		Description:      "Dark moody vibes",
 		Duration:         120,
 		ImageUrl:         "http://example.com/image.jpg",
@@ -118,6 +118,26 @@ func TestPgSpecRepository_List_PartialSearch(t *testing.T) {
 			query:       "xyz123",
 			shouldFound: false,
 		},
+		{
+			name:        "Excluded Word",
+			query:       "trap -dark",
+			shouldFound: false,
+		},
+		{
+			name:        "Excluded Missing Word",
+			query:       "trap -hyperpop",
+			shouldFound: true,
+		},
+		{
+			name:        "Quoted Phrase",
+			query:       `"trap beat"`,
+			shouldFound: true,
+		},
+		{
+			name:        "Quoted Phrase Out Of Order",
+			query:       `"dark trap"`,
+			shouldFound: false,
+		},
 	}
 
 	for _, tc := range tests {
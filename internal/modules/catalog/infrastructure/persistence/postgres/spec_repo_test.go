@@ -63,20 +63,20 @@ func TestPgSpecRepository_List_Genres(t *testing.T) {
 	// 1. Create a Seed Spec with Genre
 	genreName := "TestGenre_" + uuid.New().String()
 	spec := &domain.Spec{
-		ID:           uuid.New(),
-		ProducerID:   uuid.New(), // We might need a real user if FK constraints exist? Yes, users table.
-		ProducerName: "Test Producer",
-		Title:        "Genre Test Spec",
-		Category:     domain.CategoryBeat,
-		BasePrice:    25.0,
-		Description:  "Test Description",
-		Duration:     120,
-		ImageUrl:     "http://example.com/image.jpg",
-		PreviewUrl:   "http://example.com/preview.mp3",
-		BPM:          120,
-		Key:          "C Minor",
-		Moods:        pq.StringArray{},
-		Instruments:  pq.StringArray{},
+		ID:             uuid.New(),
+		ProducerID:     uuid.New(), // We might need a real user if FK constraints exist? Yes, users table.
+		ProducerName:   "Test Producer",
+		Title:          "Genre Test Spec",
+		Category:       domain.CategoryBeat,
+		BasePriceMinor: 2500,
+		Description:    "Test Description",
+		Duration:       120,
+		ImageUrl:       "http://example.com/image.jpg",
+		PreviewUrl:     "http://example.com/preview.mp3",
+		BPM:            120,
+		Key:            "C Minor",
+		Moods:          pq.StringArray{},
+		Instruments:    pq.StringArray{},
 		Genres: []domain.Genre{
 			{ID: uuid.Nil, Name: genreName, Slug: genreName},
 		},
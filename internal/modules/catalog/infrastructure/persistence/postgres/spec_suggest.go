@@ -0,0 +1,119 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/saransh1220/blueprint-audio/internal/modules/catalog/domain"
+)
+
+// The pg_trgm defaults (0.3 and 0.6) are tuned for whole words and miss short
+// typos: "trp" against "trap" only scores 0.29. These are applied per
+// transaction so they do not leak into other queries on the pooled connection.
+const (
+	suggestSimilarityThreshold     = "0.2"
+	suggestWordSimilarityThreshold = "0.4"
+)
+
+// Every branch filters with pg_trgm operators on lower(...) so the trigram
+// indexes can be used; starts_with keeps one and two letter prefixes working,
+// which trigrams alone cannot match. Each type is capped at $3 before the
+// overall limit so a flood of similar titles cannot hide genres or producers.
+const suggestSearchQuery = `
+	WITH q AS (
+		SELECT $1::text AS term
+	),
+	candidates AS (
+		SELECT 'spec' AS type, s.title AS value, s.id AS id, s.slug::text AS slug, lower(s.title) AS match
+		FROM specs s, q
+		WHERE s.is_deleted = FALSE
+		  AND s.processing_status = 'completed'
+		  AND (lower(s.title) % q.term OR q.term <% lower(s.title) OR starts_with(lower(s.title), q.term))
+
+		UNION ALL
+
+		SELECT 'producer', u.display_name, u.id, NULL::text, lower(u.display_name)
+		FROM users u, q
+		WHERE (lower(u.display_name) % q.term OR q.term <% lower(u.display_name) OR starts_with(lower(u.display_name), q.term))
+		  AND EXISTS (
+			SELECT 1 FROM specs s
+			WHERE s.producer_id = u.id
+			  AND s.is_deleted = FALSE
+			  AND s.processing_status = 'completed'
+		  )
+
+		UNION ALL
+
+		SELECT 'genre', g.name, NULL::uuid, g.slug::text, lower(g.name)
+		FROM genres g, q
+		WHERE lower(g.name) % q.term OR q.term <% lower(g.name) OR starts_with(lower(g.name), q.term)
+
+		UNION ALL
+
+		SELECT 'tag', min(t.value), NULL::uuid, NULL::text, lower(t.value)
+		FROM specs s
+		CROSS JOIN LATERAL unnest(s.tags) AS t(value), q
+		WHERE s.is_deleted = FALSE
+		  AND s.processing_status = 'completed'
+		  AND (lower(t.value) % q.term OR q.term <% lower(t.value) OR starts_with(lower(t.value), q.term))
+		GROUP BY lower(t.value)
+
+		UNION ALL
+
+		SELECT 'mood', min(m.value), NULL::uuid, NULL::text, lower(m.value)
+		FROM specs s
+		CROSS JOIN LATERAL unnest(s.moods) AS m(value), q
+		WHERE s.is_deleted = FALSE
+		  AND s.processing_status = 'completed'
+		  AND (lower(m.value) % q.term OR q.term <% lower(m.value) OR starts_with(lower(m.value), q.term))
+		GROUP BY lower(m.value)
+	),
+	scored AS (
+		SELECT c.type, c.value, c.id, c.slug,
+			CASE
+				WHEN starts_with(c.match, q.term) THEN 1.0::real
+				ELSE GREATEST(similarity(c.match, q.term), word_similarity(q.term, c.match))
+			END AS score
+		FROM candidates c, q
+	),
+	ranked AS (
+		SELECT scored.*,
+			ROW_NUMBER() OVER (PARTITION BY type ORDER BY score DESC, length(value), value) AS type_rank
+		FROM scored
+	)
+	SELECT type, value, id, slug, score
+	FROM ranked
+	WHERE type_rank <= $3
+	ORDER BY score DESC, type_rank, value
+	LIMIT $2
+`
+
+// SuggestSearch returns autocomplete candidates across spec titles, producers,
+// genres, tags and moods, ranked by trigram similarity to the query. The query
+// is expected to be normalized (trimmed and lower-cased) by the caller.
+func (r *PgSpecRepository) SuggestSearch(ctx context.Context, query string, limit int) ([]domain.SearchSuggestion, error) {
+	perType := (limit + 1) / 2
+	if perType < 1 {
+		perType = 1
+	}
+
+	tx, err := r.db.BeginTxx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx,
+		"SELECT set_config('pg_trgm.similarity_threshold', $1, true), set_config('pg_trgm.word_similarity_threshold', $2, true)",
+		suggestSimilarityThreshold, suggestWordSimilarityThreshold,
+	); err != nil {
+		return nil, fmt.Errorf("failed to set similarity thresholds: %w", err)
+	}
+
+	suggestions := []domain.SearchSuggestion{}
+	if err := tx.SelectContext(ctx, &suggestions, suggestSearchQuery, query, limit, perType); err != nil {
+		return nil, err
+	}
+	return suggestions, nil
+}
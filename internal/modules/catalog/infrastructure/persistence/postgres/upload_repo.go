@@ -273,6 +273,12 @@ func (r *PgSpecUploadRepository) GetStatus(ctx context.Context, uploadID, produc
 		Status           domain.UploadStatus     `db:"status"`
 		ProcessingStatus domain.ProcessingStatus `db:"processing_status"`
 		ErrorMessage     *string                 `db:"error_message"`
+		DeclaredBPM      *int                    `db:"declared_bpm"`
+		DeclaredKey      *string                 `db:"declared_key"`
+		SuggestedBPM     *int                    `db:"suggested_bpm"`
+		BPMConfidence    *float64                `db:"suggested_bpm_confidence"`
+		SuggestedKey     *string                 `db:"suggested_key"`
+		KeyConfidence    *float64                `db:"suggested_key_confidence"`
 		CreatedAt        time.Time               `db:"created_at"`
 		UpdatedAt        time.Time               `db:"updated_at"`
 		ExpiresAt        time.Time               `db:"expires_at"`
@@ -281,6 +287,9 @@ func (r *PgSpecUploadRepository) GetStatus(ctx context.Context, uploadID, produc
 		SELECT us.id AS upload_id, us.spec_id, us.producer_id, us.status,
 		       COALESCE(s.processing_status, 'pending') AS processing_status,
 		       COALESCE(us.error_message, j.error_message) AS error_message,
+		       s.bpm AS declared_bpm, s.key AS declared_key,
+		       s.suggested_bpm, s.suggested_bpm_confidence,
+		       s.suggested_key, s.suggested_key_confidence,
 		       us.created_at, us.updated_at, us.expires_at
 		FROM spec_upload_sessions us
 		LEFT JOIN specs s ON s.id = us.spec_id
@@ -302,9 +311,17 @@ func (r *PgSpecUploadRepository) GetStatus(ctx context.Context, uploadID, produc
 		Status:           row.Status,
 		ProcessingStatus: row.ProcessingStatus,
 		ErrorMessage:     row.ErrorMessage,
-		CreatedAt:        row.CreatedAt,
-		UpdatedAt:        row.UpdatedAt,
-		ExpiresAt:        row.ExpiresAt,
+		DeclaredBPM:      row.DeclaredBPM,
+		DeclaredKey:      row.DeclaredKey,
+		Suggestions: domain.AudioSuggestions{
+			BPM:           row.SuggestedBPM,
+			BPMConfidence: row.BPMConfidence,
+			Key:           row.SuggestedKey,
+			KeyConfidence: row.KeyConfidence,
+		},
+		CreatedAt: row.CreatedAt,
+		UpdatedAt: row.UpdatedAt,
+		ExpiresAt: row.ExpiresAt,
 	}, nil
 }
 
@@ -438,6 +455,71 @@ func (r *PgSpecUploadRepository) ExpireUploadSessions(
 	return result.RowsAffected()
 }
 
+// ListUnpurgedSessions returns expired and failed sessions last updated
+// before finishedBefore whose objects have not been purged, with their assets.
+func (r *PgSpecUploadRepository) ListUnpurgedSessions(
+	ctx context.Context,
+	finishedBefore time.Time,
+	limit int,
+) ([]domain.SpecUploadSession, error) {
+	sessions := []domain.SpecUploadSession{}
+	if err := r.db.SelectContext(ctx, &sessions, `
+		SELECT id, spec_id, producer_id, metadata, status, error_message,
+		       expires_at, created_at, updated_at, completed_at
+		FROM spec_upload_sessions
+		WHERE status IN ('expired', 'failed')
+		  AND objects_purged_at IS NULL
+		  AND updated_at < $1
+		ORDER BY updated_at
+		LIMIT $2`, finishedBefore, limit); err != nil {
+		return nil, err
+	}
+	if len(sessions) == 0 {
+		return sessions, nil
+	}
+
+	ids := make([]uuid.UUID, len(sessions))
+	index := make(map[uuid.UUID]int, len(sessions))
+	for i, session := range sessions {
+		ids[i] = session.ID
+		index[session.ID] = i
+	}
+	var assets []domain.SpecUploadAsset
+	if err := r.db.SelectContext(ctx, &assets, `
+		SELECT id, session_id, kind, file_name, object_key, final_object_key,
+		       declared_content_type, actual_content_type, expected_size,
+		       actual_size, etag, created_at, updated_at
+		FROM spec_upload_assets
+		WHERE session_id = ANY($1)
+		ORDER BY session_id, kind`, pq.Array(ids)); err != nil {
+		return nil, err
+	}
+	for _, asset := range assets {
+		i := index[asset.SessionID]
+		sessions[i].Assets = append(sessions[i].Assets, asset)
+	}
+	return sessions, nil
+}
+
+func (r *PgSpecUploadRepository) MarkObjectsPurged(ctx context.Context, sessionID uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE spec_upload_sessions
+		SET objects_purged_at = NOW()
+		WHERE id = $1`, sessionID)
+	return err
+}
+
+func (r *PgSpecUploadRepository) SpecHasProcessedFiles(ctx context.Context, specID uuid.UUID) (bool, error) {
+	var processed bool
+	err := r.db.GetContext(ctx, &processed, `
+		SELECT EXISTS (
+			SELECT 1 FROM specs
+			WHERE id = $1
+			  AND (image_url <> '' OR preview_url <> '' OR wav_url IS NOT NULL OR stems_url IS NOT NULL)
+		)`, specID)
+	return processed, err
+}
+
 func (r *PgSpecUploadRepository) RequeueStaleJobs(ctx context.Context, staleBefore time.Time) (int64, error) {
 	result, err := r.db.ExecContext(ctx, `
 		WITH stale AS (
@@ -576,11 +658,17 @@ func (r *PgSpecUploadRepository) CompleteJob(
 		    stems_url = $5,
 		    duration = $6,
 		    waveform_peaks = $7,
+		    suggested_bpm = $8,
+		    suggested_bpm_confidence = $9,
+		    suggested_key = $10,
+		    suggested_key_confidence = $11,
 		    processing_status = 'completed',
 		    updated_at = NOW()
 		WHERE id = $1`,
 		ids.SpecID, result.ImageURL, result.PreviewURL, result.WAVURL,
-		result.StemsURL, result.Duration, pq.Array(result.WaveformPeaks))
+		result.StemsURL, result.Duration, pq.Array(result.WaveformPeaks),
+		result.Suggestions.BPM, result.Suggestions.BPMConfidence,
+		result.Suggestions.Key, result.Suggestions.KeyConfidence)
 	if err != nil {
 		return err
 	}
@@ -75,3 +75,89 @@ func TestSpecUploadRepositoryExpiresAbandonedSessions(t *testing.T) {
 	require.Equal(t, int64(3), count)
 	require.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestSpecUploadRepositoryListsUnpurgedSessionsWithAssets(t *testing.T) {
+	db, mock, cleanup := newMockDB(t)
+	defer cleanup()
+	repository := postgres.NewSpecUploadRepository(db)
+	cutoff := time.Now().UTC()
+	expiredID, failedID := uuid.New(), uuid.New()
+	sessionColumns := []string{
+		"id", "spec_id", "producer_id", "metadata", "status", "error_message",
+		"expires_at", "created_at", "updated_at", "completed_at",
+	}
+	assetColumns := []string{
+		"id", "session_id", "kind", "file_name", "object_key", "final_object_key",
+		"declared_content_type", "actual_content_type", "expected_size",
+		"actual_size", "etag", "created_at", "updated_at",
+	}
+
+	mock.ExpectQuery("FROM spec_upload_sessions[\\s\\S]*objects_purged_at IS NULL").
+		WithArgs(cutoff, 100).
+		WillReturnRows(sqlmock.NewRows(sessionColumns).
+			AddRow(expiredID, uuid.New(), uuid.New(), []byte(`{}`), "expired", nil, cutoff, cutoff, cutoff, cutoff).
+			AddRow(failedID, uuid.New(), uuid.New(), []byte(`{}`), "failed", nil, cutoff, cutoff, cutoff, nil))
+	mock.ExpectQuery("FROM spec_upload_assets[\\s\\S]*session_id = ANY").
+		WillReturnRows(sqlmock.NewRows(assetColumns).
+			AddRow(uuid.New(), failedID, "preview", "a.mp3", "staging/a", "final/a", "audio/mpeg", nil, 10, nil, nil, cutoff, cutoff).
+			AddRow(uuid.New(), failedID, "wav", "a.wav", "staging/b", "final/b", "audio/wav", nil, 10, nil, nil, cutoff, cutoff))
+	sessions, err := repository.ListUnpurgedSessions(context.Background(), cutoff, 100)
+	require.NoError(t, err)
+	require.Len(t, sessions, 2)
+	require.Empty(t, sessions[0].Assets)
+	require.Len(t, sessions[1].Assets, 2)
+
+	mock.ExpectExec("UPDATE spec_upload_sessions\\s+SET objects_purged_at = NOW\\(\\)").
+		WithArgs(failedID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	require.NoError(t, repository.MarkObjectsPurged(context.Background(), failedID))
+
+	specID := uuid.New()
+	mock.ExpectQuery("SELECT EXISTS[\\s\\S]*FROM specs").
+		WithArgs(specID).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	processed, err := repository.SpecHasProcessedFiles(context.Background(), specID)
+	require.NoError(t, err)
+	require.True(t, processed)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSpecUploadRepositoryCompleteStoresAudioSuggestions(t *testing.T) {
+	db, mock, cleanup := newMockDB(t)
+	defer cleanup()
+	repository := postgres.NewSpecUploadRepository(db)
+	jobID := uuid.New()
+	specID := uuid.New()
+	sessionID := uuid.New()
+	bpm := 128
+	bpmConfidence := 0.74
+	key := "A MINOR"
+	keyConfidence := 0.62
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT spec_id, session_id").
+		WithArgs(jobID, "worker-one").
+		WillReturnRows(sqlmock.NewRows([]string{"spec_id", "session_id"}).AddRow(specID, sessionID))
+	mock.ExpectExec("UPDATE specs[\\s\\S]*suggested_bpm = \\$8[\\s\\S]*suggested_key_confidence = \\$11").
+		WithArgs(
+			specID, "image", "preview", nil, nil, 30, sqlmock.AnyArg(),
+			&bpm, &bpmConfidence, &key, &keyConfidence,
+		).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE spec_processing_jobs").WithArgs(jobID).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE spec_upload_sessions").WithArgs(sessionID).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	require.NoError(t, repository.CompleteJob(context.Background(), jobID, "worker-one", domain.ProcessedSpecFiles{
+		ImageURL:   "image",
+		PreviewURL: "preview",
+		Duration:   30,
+		Suggestions: domain.AudioSuggestions{
+			BPM:           &bpm,
+			BPMConfidence: &bpmConfidence,
+			Key:           &key,
+			KeyConfidence: &keyConfidence,
+		},
+	}))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
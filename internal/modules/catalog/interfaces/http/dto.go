@@ -21,7 +21,8 @@ type SpecResponse struct {
 	Description       string            `json:"description"`
 	ImageURL          string            `json:"image_url"`
 	PreviewURL        string            `json:"preview_url"`
-	Price             float64           `json:"price"`
+	PriceMinor        int64             `json:"price_minor"`
+	Price             float64           `json:"price"` // deprecated: PriceMinor in major units
 	PriceMoney        money.Money       `json:"price_money"`
 	DisplayPriceMoney money.Money       `json:"display_price_money"`
 	Duration          int               `json:"duration"`
@@ -38,6 +39,8 @@ type SpecResponse struct {
 	Instruments       []string          `json:"instruments,omitempty"`
 	WaveformPeaks     []int64           `json:"waveform_peaks,omitempty"`
 	ProcessingStatus  string            `json:"processing_status"`
+
+	Highlights *domain.SearchHighlights `json:"highlights,omitempty"`
 }
 
 // SpecAnalytics contains publicly visible analytics
@@ -76,13 +79,19 @@ type HomepageItemResponse struct {
 	Spec     SpecResponse               `json:"spec"`
 }
 
+type SearchSuggestResponse struct {
+	Query       string                    `json:"query"`
+	Suggestions []domain.SearchSuggestion `json:"suggestions"`
+}
+
 // LicenseResponse for nested license data
 type LicenseResponse struct {
 	ID                uuid.UUID   `json:"id"`
 	SpecID            uuid.UUID   `json:"spec_id"`
 	Type              string      `json:"type"`
 	Name              string      `json:"name"`
-	Price             float64     `json:"price"`
+	PriceMinor        int64       `json:"price_minor"`
+	Price             float64     `json:"price"` // deprecated: PriceMinor in major units
 	PriceMoney        money.Money `json:"price_money"`
 	DisplayPriceMoney money.Money `json:"display_price_money"`
 	Features          []string    `json:"features"`
@@ -116,9 +125,10 @@ func ToSpecResponseForCurrency(spec *domain.Spec, displayCurrency string) *SpecR
 		Description:       spec.Description,
 		ImageURL:          spec.ImageUrl,
 		PreviewURL:        spec.PreviewUrl,
-		Price:             spec.BasePrice,
-		PriceMoney:        money.DisplayPrice(spec.BasePrice, spec.PriceCurrency, spec.PriceCurrency),
-		DisplayPriceMoney: money.DisplayPrice(spec.BasePrice, spec.PriceCurrency, displayCurrency),
+		PriceMinor:        spec.BasePriceMinor,
+		Price:             spec.BasePrice().Major(),
+		PriceMoney:        money.DisplayPrice(spec.BasePrice(), spec.PriceCurrency),
+		DisplayPriceMoney: money.DisplayPrice(spec.BasePrice(), displayCurrency),
 		Duration:          spec.Duration,
 		FreeMp3Enabled:    spec.FreeMp3Enabled,
 		CreatedAt:         spec.CreatedAt,
@@ -130,6 +140,7 @@ func ToSpecResponseForCurrency(spec *domain.Spec, displayCurrency string) *SpecR
 		Instruments:       spec.Instruments,
 		WaveformPeaks:     spec.WaveformPeaks,
 		ProcessingStatus:  string(spec.ProcessingStatus),
+		Highlights:        spec.Highlights,
 	}
 
 	// Convert licenses
@@ -141,9 +152,10 @@ func ToSpecResponseForCurrency(spec *domain.Spec, displayCurrency string) *SpecR
 				SpecID:            license.SpecID,
 				Type:              string(license.LicenseType),
 				Name:              license.Name,
-				Price:             license.Price,
-				PriceMoney:        money.DisplayPrice(license.Price, license.PriceCurrency, license.PriceCurrency),
-				DisplayPriceMoney: money.DisplayPrice(license.Price, license.PriceCurrency, displayCurrency),
+				PriceMinor:        license.PriceMinor,
+				Price:             license.Price().Major(),
+				PriceMoney:        money.DisplayPrice(license.Price(), license.PriceCurrency),
+				DisplayPriceMoney: money.DisplayPrice(license.Price(), displayCurrency),
 				Features:          license.Features,
 				FileTypes:         license.FileTypes,
 				CreatedAt:         license.CreatedAt,
@@ -14,10 +14,10 @@ func TestToSpecResponse_WithRelations(t *testing.T) {
 	now := time.Now()
 	spec := &domain.Spec{
 		ID: uuid.New(), ProducerID: uuid.New(), ProducerName: "p", Title: "t", Category: domain.CategoryBeat, Type: "wav", BPM: 120, Key: "C",
-		ImageUrl: "img", PreviewUrl: "prev", BasePrice: 10, Duration: 100, FreeMp3Enabled: true, CreatedAt: now, UpdatedAt: now,
+		ImageUrl: "img", PreviewUrl: "prev", BasePriceMinor: 1000, Duration: 100, FreeMp3Enabled: true, CreatedAt: now, UpdatedAt: now,
 		Tags:          pq.StringArray{"trap"},
 		WaveformPeaks: pq.Int64Array{12, 48, 100},
-		Licenses:      []domain.LicenseOption{{ID: uuid.New(), SpecID: uuid.New(), LicenseType: domain.LicenseBasic, Name: "Basic", Price: 1, Features: pq.StringArray{"a"}, FileTypes: pq.StringArray{"mp3"}, CreatedAt: now, UpdatedAt: now}},
+		Licenses:      []domain.LicenseOption{{ID: uuid.New(), SpecID: uuid.New(), LicenseType: domain.LicenseBasic, Name: "Basic", PriceMinor: 100, Features: pq.StringArray{"a"}, FileTypes: pq.StringArray{"mp3"}, CreatedAt: now, UpdatedAt: now}},
 		Genres:        []domain.Genre{{ID: uuid.New(), Name: "HipHop", Slug: "hiphop", CreatedAt: now}},
 	}
 	res := ToSpecResponse(spec)
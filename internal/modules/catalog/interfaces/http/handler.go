@@ -18,9 +18,12 @@ import (
 	"github.com/saransh1220/blueprint-audio/internal/gateway/middleware"
 	"github.com/saransh1220/blueprint-audio/internal/modules/catalog/application"
 	"github.com/saransh1220/blueprint-audio/internal/modules/catalog/domain"
+	notificationDomain "github.com/saransh1220/blueprint-audio/internal/modules/notification/domain"
 	"github.com/saransh1220/blueprint-audio/internal/shared/money"
 )
 
+const suggestCacheTTL = 5 * time.Minute
+
 type SpecHandler struct {
 	service             application.SpecService
 	fileService         FileService
@@ -69,8 +72,9 @@ func (h *SpecHandler) cacheDel(ctx context.Context, key string) {
 func (h *SpecHandler) cacheDelSpec(ctx context.Context, specID uuid.UUID) {
 	baseKey := "spec:" + specID.String()
 	h.cacheDel(ctx, baseKey)
-	h.cacheDel(ctx, baseKey+":"+money.CurrencyINR)
-	h.cacheDel(ctx, baseKey+":"+money.CurrencyUSD)
+	for _, currency := range money.SupportedCurrencies() {
+		h.cacheDel(ctx, baseKey+":"+currency.Code)
+	}
 }
 
 // CreateGone retires the server-proxied multipart upload. New uploads must use
@@ -196,6 +200,8 @@ func (h *SpecHandler) List(w http.ResponseWriter, r *http.Request) {
 	minDuration, _ := strconv.Atoi(q.Get("min_duration"))
 	maxDuration, _ := strconv.Atoi(q.Get("max_duration"))
 
+	// Price filters are in major units and are compared with minor unit
+	// prices as if every currency had cents.
 	minPrice, _ := strconv.ParseFloat(q.Get("min_price"), 64)
 	maxPrice, _ := strconv.ParseFloat(q.Get("max_price"), 64)
 
@@ -221,8 +227,8 @@ func (h *SpecHandler) List(w http.ResponseWriter, r *http.Request) {
 		Search:      search,
 		MinBPM:      minBPM,
 		MaxBPM:      maxBPM,
-		MinPrice:    minPrice,
-		MaxPrice:    maxPrice,
+		MinPrice:    money.AmountFromMajor(minPrice, money.CurrencyUSD).Minor,
+		MaxPrice:    money.AmountFromMajor(maxPrice, money.CurrencyUSD).Minor,
 		MinDuration: minDuration,
 		MaxDuration: maxDuration,
 		Key:         key,
@@ -284,6 +290,41 @@ func (h *SpecHandler) List(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// Suggest serves search-bar autocomplete. Results are cached per normalized
+// query so that typing does not hit Postgres on every keystroke.
+func (h *SpecHandler) Suggest(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	query := application.NormalizeSuggestQuery(q.Get("q"))
+	requestedLimit, _ := strconv.Atoi(q.Get("limit"))
+	limit := application.NormalizeSuggestLimit(requestedLimit)
+
+	cacheKey := fmt.Sprintf("search:suggest:%d:%s", limit, query)
+	if val, ok := h.cacheGet(r.Context(), cacheKey); ok {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Cache", "HIT")
+		w.Write([]byte(val))
+		return
+	}
+
+	suggestions, err := h.service.SuggestSearch(r.Context(), query, limit)
+	if err != nil {
+		log.Printf("[SpecHandler.Suggest] Error: %v", err)
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	jsonBytes, err := json.Marshal(SearchSuggestResponse{Query: query, Suggestions: suggestions})
+	if err != nil {
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+	h.cacheSet(r.Context(), cacheKey, jsonBytes, suggestCacheTTL)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Cache", "MISS")
+	w.Write(jsonBytes)
+}
+
 func (h *SpecHandler) Home(w http.ResponseWriter, r *http.Request) {
 	q := r.URL.Query()
 
@@ -296,10 +337,16 @@ func (h *SpecHandler) Home(w http.ResponseWriter, r *http.Request) {
 	}
 	log.Printf("[SpecHandler.Home] request limit=%d period=%q sections=%v raw_query=%q", limit, q.Get("period"), sections, r.URL.RawQuery)
 
+	var userIDPtr *uuid.UUID
+	if userID, ok := r.Context().Value(middleware.ContextKeyUserId).(uuid.UUID); ok {
+		userIDPtr = &userID
+	}
+
 	home, err := h.service.GetHome(r.Context(), domain.HomepageParams{
 		Limit:    limit,
 		Sections: sections,
 		Period:   q.Get("period"),
+		UserID:   userIDPtr,
 	})
 	if err != nil {
 		log.Printf("[SpecHandler.Home] Error: %v", err)
@@ -307,11 +354,6 @@ func (h *SpecHandler) Home(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var userIDPtr *uuid.UUID
-	if userID, ok := r.Context().Value(middleware.ContextKeyUserId).(uuid.UUID); ok {
-		userIDPtr = &userID
-	}
-
 	displayCurrency := money.ResolveCurrencyFromRequest(r)
 	response := HomepageResponse{
 		GeneratedAt:     home.GeneratedAt,
@@ -542,8 +584,9 @@ func (h *SpecHandler) Update(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Update fields
+	oldPrices := specPrices(existingSpec)
 	existingSpec.Title = updateData.Title
-	existingSpec.BasePrice = updateData.BasePrice
+	existingSpec.BasePriceMinor = updateData.BasePriceMinor
 	existingSpec.BPM = updateData.BPM
 	existingSpec.Key = updateData.Key
 	existingSpec.Tags = updateData.Tags
@@ -619,6 +662,10 @@ func (h *SpecHandler) Update(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	if priceDropped(oldPrices, specPrices(existingSpec)) {
+		go h.notifyPriceDrop(existingSpec.ID, existingSpec.ProducerID, existingSpec.Title)
+	}
+
 	// 6. Return Updated Spec
 	h.sanitizeSpec(existingSpec)
 	response := ToSpecResponseForCurrency(existingSpec, money.ResolveCurrencyFromRequest(r))
@@ -631,6 +678,48 @@ func (h *SpecHandler) Update(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(response)
 }
 
+// specPrices returns the spec's base price and its license prices by license
+// type, in minor units.
+func specPrices(spec *domain.Spec) map[string]int64 {
+	prices := map[string]int64{"": spec.BasePriceMinor}
+	for _, license := range spec.Licenses {
+		prices[string(license.LicenseType)] = license.PriceMinor
+	}
+	return prices
+}
+
+// priceDropped reports whether any price that existed before is now lower.
+func priceDropped(before, after map[string]int64) bool {
+	for name, price := range after {
+		if old, ok := before[name]; ok && price < old {
+			return true
+		}
+	}
+	return false
+}
+
+// notifyPriceDrop tells the users who favorited a spec that it got cheaper.
+func (h *SpecHandler) notifyPriceDrop(specID, producerID uuid.UUID, title string) {
+	if h.notificationService == nil {
+		return
+	}
+	ctx := context.Background()
+	userIDs, err := h.analyticsService.ListFavoritedBy(ctx, specID)
+	if err != nil {
+		log.Printf("Failed to list favorites for price drop on spec %s: %v", specID, err)
+		return
+	}
+	message := fmt.Sprintf("'%s', a beat you favorited, just dropped in price.", title)
+	for _, userID := range userIDs {
+		if userID == producerID {
+			continue
+		}
+		if err := h.notificationService.Create(ctx, userID, notificationDomain.CategoryPriceDrop, "Price drop", message, notificationDomain.NotificationTypeInfo); err != nil {
+			log.Printf("Failed to notify user %s of price drop on spec %s: %v", userID, specID, err)
+		}
+	}
+}
+
 func (h *SpecHandler) GetUserSpecs(w http.ResponseWriter, r *http.Request) {
 	userIDStr := r.PathValue("id")
 	userID, err := uuid.Parse(userIDStr)
@@ -159,8 +159,8 @@ func TestSpecHandler_List_Get_Update_Delete_GetUserSpecs_Branches(t *testing.T)
 				filter.Key == "" &&
 				filter.MinBPM == 90 &&
 				filter.MaxBPM == 140 &&
-				filter.MinPrice == 10 &&
-				filter.MaxPrice == 99 &&
+				filter.MinPrice == 1000 &&
+				filter.MaxPrice == 9900 &&
 				filter.Sort == "newest" &&
 				filter.Offset == 0 &&
 				filter.Limit == 20 &&
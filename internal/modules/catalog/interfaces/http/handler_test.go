@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/redis/go-redis/v9"
@@ -14,6 +15,7 @@ import (
 	analyticsDomain "github.com/saransh1220/blueprint-audio/internal/modules/analytics/domain"
 	catalogDomain "github.com/saransh1220/blueprint-audio/internal/modules/catalog/domain"
 	catalogHTTP "github.com/saransh1220/blueprint-audio/internal/modules/catalog/interfaces/http"
+	notificationDomain "github.com/saransh1220/blueprint-audio/internal/modules/notification/domain"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -162,6 +164,7 @@ func TestSpecHandler_Home_Success(t *testing.T) {
 		Limit:    6,
 		Sections: []string{"top_charts"},
 		Period:   "7d",
+		UserID:   &viewerID,
 	}).Return(home, nil).Once()
 	analyticsSvc.On("GetPublicAnalytics", mock.Anything, specID, &viewerID).
 		Return(&analyticsDomain.PublicAnalytics{PlayCount: 10, FavoriteCount: 2, IsFavorited: true}, nil).Once()
@@ -270,6 +273,42 @@ func TestSpecHandler_UpdateSuccessWithoutImage(t *testing.T) {
 	assert.Contains(t, w.Body.String(), "New")
 }
 
+func TestSpecHandler_UpdateNotifiesFavoritersOfPriceDrop(t *testing.T) {
+	h, specSvc, _, analyticsSvc, notificationSvc := newHandler()
+	defer analyticsSvc.AssertExpectations(t)
+	defer notificationSvc.AssertExpectations(t)
+	id, producerID, fanID := uuid.New(), uuid.New(), uuid.New()
+	existing := &catalogDomain.Spec{
+		ID: id, ProducerID: producerID, Title: "Night Drive", Category: catalogDomain.CategoryBeat, BasePriceMinor: 3000,
+		Licenses: []catalogDomain.LicenseOption{{LicenseType: catalogDomain.LicenseBasic, PriceMinor: 3000}},
+	}
+	specSvc.On("GetSpec", mock.Anything, id).Return(existing, nil).Once()
+	specSvc.On("UpdateSpec", mock.Anything, mock.AnythingOfType("*domain.Spec"), producerID).Return(nil).Once()
+	analyticsSvc.On("ListFavoritedBy", mock.Anything, id).Return([]uuid.UUID{producerID, fanID}, nil).Once()
+	notified := make(chan struct{})
+	notificationSvc.On("Create", mock.Anything, fanID, notificationDomain.CategoryPriceDrop, "Price drop",
+		"'Night Drive', a beat you favorited, just dropped in price.", notificationDomain.NotificationTypeInfo).
+		Return(nil).Once().Run(func(mock.Arguments) { close(notified) })
+
+	var body bytes.Buffer
+	body.WriteString("--x\r\nContent-Disposition: form-data; name=\"metadata\"\r\n\r\n")
+	body.WriteString(`{"title":"Night Drive","price":30,"licenses":[{"type":"Basic","price":20}]}`)
+	body.WriteString("\r\n--x--\r\n")
+	req := httptest.NewRequest(http.MethodPatch, "/specs/"+id.String(), &body)
+	req.Header.Set("Content-Type", "multipart/form-data; boundary=x")
+	req.SetPathValue("id", id.String())
+	req = req.WithContext(context.WithValue(req.Context(), middleware.ContextKeyUserId, producerID))
+	w := httptest.NewRecorder()
+	h.Update(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	select {
+	case <-notified:
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected price drop notification")
+	}
+}
+
 func TestSpecHandler_CreateGone(t *testing.T) {
 	h, _, _, _, _ := newHandler()
 	req := httptest.NewRequest(http.MethodPost, "/specs", nil)
@@ -278,3 +317,34 @@ func TestSpecHandler_CreateGone(t *testing.T) {
 	assert.Equal(t, http.StatusGone, w.Code)
 	assert.Contains(t, w.Body.String(), "/spec-uploads")
 }
+
+func TestSpecHandler_SuggestNormalizesQueryAndReturnsSuggestions(t *testing.T) {
+	h, specSvc, _, _, _ := newHandler()
+
+	genreSlug := "trap"
+	suggestions := []catalogDomain.SearchSuggestion{
+		{Type: catalogDomain.SuggestionGenre, Value: "Trap", Slug: &genreSlug, Score: 0.57},
+		{Type: catalogDomain.SuggestionTag, Value: "trap soul", Score: 0.4},
+	}
+	specSvc.On("SuggestSearch", mock.Anything, "trapp", 5).Return(suggestions, nil).Once()
+
+	req := httptest.NewRequest(http.MethodGet, "/search/suggest?q=%20TRAPP%20&limit=5", nil)
+	w := httptest.NewRecorder()
+	h.Suggest(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "MISS", w.Header().Get("X-Cache"))
+	var body catalogHTTP.SearchSuggestResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "trapp", body.Query)
+	require.Len(t, body.Suggestions, 2)
+	assert.Equal(t, catalogDomain.SuggestionGenre, body.Suggestions[0].Type)
+	require.NotNil(t, body.Suggestions[0].Slug)
+	assert.Equal(t, "trap", *body.Suggestions[0].Slug)
+
+	specSvc.On("SuggestSearch", mock.Anything, "trp", 8).Return(nil, assert.AnError).Once()
+	w = httptest.NewRecorder()
+	h.Suggest(w, httptest.NewRequest(http.MethodGet, "/search/suggest?q=trp", nil))
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	specSvc.AssertExpectations(t)
+}
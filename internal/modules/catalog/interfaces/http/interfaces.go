@@ -25,9 +25,10 @@ type FileService interface {
 type AnalyticsService interface {
 	GetPublicAnalytics(ctx context.Context, specID uuid.UUID, userID *uuid.UUID) (*analyticsDomain.PublicAnalytics, error)
 	TrackFreeDownload(ctx context.Context, specID uuid.UUID) error
+	ListFavoritedBy(ctx context.Context, specID uuid.UUID) ([]uuid.UUID, error)
 }
 
 // NotificationService defines the dependency on the notification module
 type NotificationService interface {
-	Create(ctx context.Context, userID uuid.UUID, title, message string, type_ notificationDomain.NotificationType) error
+	Create(ctx context.Context, userID uuid.UUID, category notificationDomain.Category, title, message string, type_ notificationDomain.NotificationType) error
 }
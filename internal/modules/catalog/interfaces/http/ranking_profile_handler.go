@@ -0,0 +1,162 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/saransh1220/blueprint-audio/internal/gateway/middleware"
+	"github.com/saransh1220/blueprint-audio/internal/modules/catalog/application"
+	"github.com/saransh1220/blueprint-audio/internal/modules/catalog/domain"
+)
+
+// RankingProfileHandler serves the admin API for the scoring profiles of the
+// algorithmic homepage sections.
+type RankingProfileHandler struct {
+	service application.RankingProfileService
+}
+
+func NewRankingProfileHandler(service application.RankingProfileService) *RankingProfileHandler {
+	return &RankingProfileHandler{service: service}
+}
+
+func (h *RankingProfileHandler) AdminListProfiles(w http.ResponseWriter, r *http.Request) {
+	profiles, err := h.service.ListProfiles(r.Context(), r.URL.Query().Get("section"))
+	if err != nil {
+		h.writeError(w, "AdminListProfiles", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{"data": profiles})
+}
+
+func (h *RankingProfileHandler) AdminGetProfile(w http.ResponseWriter, r *http.Request) {
+	id, ok := profileID(w, r)
+	if !ok {
+		return
+	}
+
+	profile, err := h.service.GetProfile(r.Context(), id)
+	if err != nil {
+		h.writeError(w, "AdminGetProfile", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(profile)
+}
+
+// AdminCreateProfile stores a new profile. It stays inactive until activated,
+// so it can be previewed first.
+func (h *RankingProfileHandler) AdminCreateProfile(w http.ResponseWriter, r *http.Request) {
+	actorID, ok := r.Context().Value(middleware.ContextKeyUserId).(uuid.UUID)
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var input application.CreateRankingProfileInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	input.ActorID = actorID
+
+	profile, err := h.service.CreateProfile(r.Context(), input)
+	if err != nil {
+		h.writeError(w, "AdminCreateProfile", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(profile)
+}
+
+// AdminPreviewProfile ranks with the profile and diffs the result against the
+// stored rankings without changing them.
+func (h *RankingProfileHandler) AdminPreviewProfile(w http.ResponseWriter, r *http.Request) {
+	id, ok := profileID(w, r)
+	if !ok {
+		return
+	}
+	limit := 0
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
+			limit = l
+		}
+	}
+
+	preview, err := h.service.PreviewProfile(r.Context(), id, r.URL.Query().Get("period"), limit)
+	if err != nil {
+		h.writeError(w, "AdminPreviewProfile", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(preview)
+}
+
+func (h *RankingProfileHandler) AdminActivateProfile(w http.ResponseWriter, r *http.Request) {
+	actorID, ok := r.Context().Value(middleware.ContextKeyUserId).(uuid.UUID)
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	id, ok := profileID(w, r)
+	if !ok {
+		return
+	}
+
+	profile, err := h.service.ActivateProfile(r.Context(), id, actorID)
+	if err != nil {
+		h.writeError(w, "AdminActivateProfile", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(profile)
+}
+
+// AdminDeactivateProfile puts the profile's section back on its built-in
+// formula.
+func (h *RankingProfileHandler) AdminDeactivateProfile(w http.ResponseWriter, r *http.Request) {
+	id, ok := profileID(w, r)
+	if !ok {
+		return
+	}
+
+	profile, err := h.service.DeactivateProfile(r.Context(), id)
+	if err != nil {
+		h.writeError(w, "AdminDeactivateProfile", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(profile)
+}
+
+func (h *RankingProfileHandler) writeError(w http.ResponseWriter, op string, err error) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidRankingProfile):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, domain.ErrRankingProfileNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	default:
+		log.Printf("RankingProfileHandler.%s failed: %v", op, err)
+		http.Error(w, "failed to process ranking profile", http.StatusInternalServerError)
+	}
+}
+
+func profileID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "invalid ranking profile id", http.StatusBadRequest)
+		return uuid.Nil, false
+	}
+	return id, true
+}
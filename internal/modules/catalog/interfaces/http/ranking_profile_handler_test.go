@@ -0,0 +1,119 @@
+package http_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/saransh1220/blueprint-audio/internal/gateway/middleware"
+	"github.com/saransh1220/blueprint-audio/internal/modules/catalog/application"
+	catalogDomain "github.com/saransh1220/blueprint-audio/internal/modules/catalog/domain"
+	catalogHTTP "github.com/saransh1220/blueprint-audio/internal/modules/catalog/interfaces/http"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockRankingProfileService struct {
+	createFn   func(context.Context, application.CreateRankingProfileInput) (*catalogDomain.RankingProfile, error)
+	previewFn  func(context.Context, uuid.UUID, string, int) (*application.RankingPreview, error)
+	activateFn func(context.Context, uuid.UUID, uuid.UUID) (*catalogDomain.RankingProfile, error)
+}
+
+func (m mockRankingProfileService) ListProfiles(context.Context, string) ([]catalogDomain.RankingProfile, error) {
+	return []catalogDomain.RankingProfile{}, nil
+}
+func (m mockRankingProfileService) GetProfile(context.Context, uuid.UUID) (*catalogDomain.RankingProfile, error) {
+	return nil, catalogDomain.ErrRankingProfileNotFound
+}
+func (m mockRankingProfileService) CreateProfile(ctx context.Context, input application.CreateRankingProfileInput) (*catalogDomain.RankingProfile, error) {
+	return m.createFn(ctx, input)
+}
+func (m mockRankingProfileService) PreviewProfile(ctx context.Context, id uuid.UUID, period string, limit int) (*application.RankingPreview, error) {
+	return m.previewFn(ctx, id, period, limit)
+}
+func (m mockRankingProfileService) ActivateProfile(ctx context.Context, id, actorID uuid.UUID) (*catalogDomain.RankingProfile, error) {
+	return m.activateFn(ctx, id, actorID)
+}
+func (m mockRankingProfileService) DeactivateProfile(context.Context, uuid.UUID) (*catalogDomain.RankingProfile, error) {
+	return nil, catalogDomain.ErrRankingProfileNotFound
+}
+func (m mockRankingProfileService) RefreshSection(context.Context, string) error {
+	return nil
+}
+
+func TestRankingProfileHandler_Create(t *testing.T) {
+	actorID := uuid.New()
+	var got application.CreateRankingProfileInput
+	h := catalogHTTP.NewRankingProfileHandler(mockRankingProfileService{
+		createFn: func(_ context.Context, input application.CreateRankingProfileInput) (*catalogDomain.RankingProfile, error) {
+			got = input
+			if input.Name == "" {
+				return nil, fmt.Errorf("%w: name is required", catalogDomain.ErrInvalidRankingProfile)
+			}
+			return &catalogDomain.RankingProfile{ID: uuid.New(), Name: input.Name, Section: input.Section, RankingWeights: input.Weights}, nil
+		},
+	})
+
+	body := `{"name":"Sales","section":"top_charts","weights":{"purchases":30,"revenue":0.5},"max_per_producer":2}`
+	req := httptest.NewRequest(http.MethodPost, "/admin/ranking-profiles", bytes.NewBufferString(body))
+	req = req.WithContext(context.WithValue(req.Context(), middleware.ContextKeyUserId, actorID))
+	w := httptest.NewRecorder()
+	h.AdminCreateProfile(w, req)
+	require.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, actorID, got.ActorID)
+	assert.Equal(t, 30.0, got.Weights.Purchases)
+	assert.Equal(t, 2, got.MaxPerProducer)
+	var resp map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, 30.0, resp["weights"].(map[string]any)["purchases"])
+
+	req = httptest.NewRequest(http.MethodPost, "/admin/ranking-profiles", bytes.NewBufferString(`{"section":"trending"}`))
+	req = req.WithContext(context.WithValue(req.Context(), middleware.ContextKeyUserId, actorID))
+	w = httptest.NewRecorder()
+	h.AdminCreateProfile(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	h.AdminCreateProfile(w, httptest.NewRequest(http.MethodPost, "/admin/ranking-profiles", bytes.NewBufferString(body)))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestRankingProfileHandler_PreviewAndActivate(t *testing.T) {
+	id := uuid.New()
+	h := catalogHTTP.NewRankingProfileHandler(mockRankingProfileService{
+		previewFn: func(_ context.Context, got uuid.UUID, period string, limit int) (*application.RankingPreview, error) {
+			assert.Equal(t, id, got)
+			assert.Equal(t, "7d", period)
+			assert.Equal(t, 5, limit)
+			return &application.RankingPreview{Period: period, Limit: limit, Items: []application.RankingPreviewItem{{Change: "new"}}, Summary: application.RankingPreviewSummary{Entered: 1}}, nil
+		},
+		activateFn: func(context.Context, uuid.UUID, uuid.UUID) (*catalogDomain.RankingProfile, error) {
+			return nil, catalogDomain.ErrRankingProfileNotFound
+		},
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/ranking-profiles/"+id.String()+"/preview?period=7d&limit=5", nil)
+	req.SetPathValue("id", id.String())
+	w := httptest.NewRecorder()
+	h.AdminPreviewProfile(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"entered":1`)
+
+	req = httptest.NewRequest(http.MethodGet, "/admin/ranking-profiles/bad/preview", nil)
+	req.SetPathValue("id", "bad")
+	w = httptest.NewRecorder()
+	h.AdminPreviewProfile(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	req = httptest.NewRequest(http.MethodPost, "/admin/ranking-profiles/"+id.String()+"/activate", nil)
+	req.SetPathValue("id", id.String())
+	req = req.WithContext(context.WithValue(req.Context(), middleware.ContextKeyUserId, uuid.New()))
+	w = httptest.NewRecorder()
+	h.AdminActivateProfile(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -83,6 +83,14 @@ func (m *mockSpecService) GetHome(ctx context.Context, params domain.HomepagePar
 	return args.Get(0).(*domain.HomepageData), args.Error(1)
 }
 
+func (m *mockSpecService) SuggestSearch(ctx context.Context, query string, limit int) ([]domain.SearchSuggestion, error) {
+	args := m.Called(ctx, query, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.SearchSuggestion), args.Error(1)
+}
+
 type mockAnalyticsService struct{ mock.Mock }
 
 func (m *mockAnalyticsService) GetPublicAnalytics(ctx context.Context, specID uuid.UUID, userID *uuid.UUID) (*analyticsDomain.PublicAnalytics, error) {
@@ -98,6 +106,14 @@ func (m *mockAnalyticsService) TrackFreeDownload(ctx context.Context, specID uui
 	return args.Error(0)
 }
 
+func (m *mockAnalyticsService) ListFavoritedBy(ctx context.Context, specID uuid.UUID) ([]uuid.UUID, error) {
+	args := m.Called(ctx, specID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]uuid.UUID), args.Error(1)
+}
+
 type mockFileService struct{ mock.Mock }
 
 func (m *mockFileService) Upload(ctx context.Context, file multipart.File, header *multipart.FileHeader, folder string) (string, string, error) {
@@ -132,7 +148,7 @@ func (m *mockFileService) Delete(ctx context.Context, key string) error {
 
 type mockNotificationService struct{ mock.Mock }
 
-func (m *mockNotificationService) Create(ctx context.Context, userID uuid.UUID, title, message string, notificationType notificationDomain.NotificationType) error {
-	args := m.Called(ctx, userID, title, message, notificationType)
+func (m *mockNotificationService) Create(ctx context.Context, userID uuid.UUID, category notificationDomain.Category, title, message string, notificationType notificationDomain.NotificationType) error {
+	args := m.Called(ctx, userID, category, title, message, notificationType)
 	return args.Error(0)
 }
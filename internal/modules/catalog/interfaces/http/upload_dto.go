@@ -15,7 +15,8 @@ type CreateSpecMetadataRequest struct {
 	Type           string                 `json:"type"`
 	BPM            int                    `json:"bpm"`
 	Key            string                 `json:"key"`
-	Price          float64                `json:"price"`
+	PriceMinor     *int64                 `json:"price_minor"`
+	Price          *float64               `json:"price"` // legacy, major units
 	PriceCurrency  string                 `json:"price_currency"`
 	Description    string                 `json:"description"`
 	FreeMP3Enabled bool                   `json:"free_mp3_enabled"`
@@ -34,7 +35,8 @@ type CreateGenreRequest struct {
 type CreateLicenseRequest struct {
 	Type          domain.LicenseType `json:"type"`
 	Name          string             `json:"name"`
-	Price         float64            `json:"price"`
+	PriceMinor    *int64             `json:"price_minor"`
+	Price         *float64           `json:"price"` // legacy, major units
 	PriceCurrency string             `json:"price_currency"`
 	Features      []string           `json:"features"`
 	FileTypes     []string           `json:"file_types"`
@@ -76,11 +78,31 @@ type SpecUploadStatusResponse struct {
 	Status           domain.UploadStatus     `json:"status"`
 	ProcessingStatus domain.ProcessingStatus `json:"processing_status"`
 	Error            *string                 `json:"error,omitempty"`
+	Analysis         *AudioAnalysisResponse  `json:"analysis,omitempty"`
 	CreatedAt        time.Time               `json:"created_at"`
 	UpdatedAt        time.Time               `json:"updated_at"`
 	ExpiresAt        time.Time               `json:"expires_at"`
 }
 
+// AudioAnalysisResponse pairs each value the producer declared with the value
+// detected from the preview, so the client can offer a one-click correction.
+type AudioAnalysisResponse struct {
+	BPM BPMAnalysisResponse `json:"bpm"`
+	Key KeyAnalysisResponse `json:"key"`
+}
+
+type BPMAnalysisResponse struct {
+	Declared   *int     `json:"declared"`
+	Suggested  *int     `json:"suggested"`
+	Confidence *float64 `json:"confidence"`
+}
+
+type KeyAnalysisResponse struct {
+	Declared   *string  `json:"declared"`
+	Suggested  *string  `json:"suggested"`
+	Confidence *float64 `json:"confidence"`
+}
+
 func (r CreateSpecMetadataRequest) toSpec() domain.Spec {
 	spec := domain.Spec{
 		Title:          r.Title,
@@ -88,7 +110,7 @@ func (r CreateSpecMetadataRequest) toSpec() domain.Spec {
 		Type:           r.Type,
 		BPM:            r.BPM,
 		Key:            r.Key,
-		BasePrice:      r.Price,
+		BasePriceMinor: domain.PriceMinor(r.PriceMinor, r.Price, r.PriceCurrency),
 		PriceCurrency:  r.PriceCurrency,
 		Description:    r.Description,
 		FreeMp3Enabled: r.FreeMP3Enabled,
@@ -105,7 +127,7 @@ func (r CreateSpecMetadataRequest) toSpec() domain.Spec {
 		spec.Licenses[i] = domain.LicenseOption{
 			LicenseType:   license.Type,
 			Name:          license.Name,
-			Price:         license.Price,
+			PriceMinor:    domain.PriceMinor(license.PriceMinor, license.Price, license.PriceCurrency),
 			PriceCurrency: license.PriceCurrency,
 			Features:      pq.StringArray(license.Features),
 			FileTypes:     pq.StringArray(license.FileTypes),
@@ -167,8 +189,30 @@ func toUploadStatusResponse(status *domain.SpecUploadStatus) SpecUploadStatusRes
 		Status:           status.Status,
 		ProcessingStatus: status.ProcessingStatus,
 		Error:            status.ErrorMessage,
+		Analysis:         toAudioAnalysisResponse(status),
 		CreatedAt:        status.CreatedAt,
 		UpdatedAt:        status.UpdatedAt,
 		ExpiresAt:        status.ExpiresAt,
 	}
 }
+
+// toAudioAnalysisResponse omits the block until processing has stored at least
+// one suggestion; before that there is nothing to compare against.
+func toAudioAnalysisResponse(status *domain.SpecUploadStatus) *AudioAnalysisResponse {
+	suggestions := status.Suggestions
+	if suggestions.BPM == nil && suggestions.Key == nil {
+		return nil
+	}
+	return &AudioAnalysisResponse{
+		BPM: BPMAnalysisResponse{
+			Declared:   status.DeclaredBPM,
+			Suggested:  suggestions.BPM,
+			Confidence: suggestions.BPMConfidence,
+		},
+		Key: KeyAnalysisResponse{
+			Declared:   status.DeclaredKey,
+			Suggested:  suggestions.Key,
+			Confidence: suggestions.KeyConfidence,
+		},
+	}
+}
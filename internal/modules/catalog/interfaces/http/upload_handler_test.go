@@ -301,3 +301,49 @@ func TestSpecUploadHandlerStatusResponse(t *testing.T) {
 	require.Contains(t, response.Body.String(), `"status":"processing"`)
 	require.Contains(t, response.Body.String(), specID.String())
 }
+
+func TestSpecUploadHandlerStatusIncludesAudioAnalysis(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+	uploadID := uuid.New()
+	producerID := uuid.New()
+	declaredBPM := 140
+	declaredKey := "C MINOR"
+	suggestedBPM := 142
+	bpmConfidence := 0.81
+	suggestedKey := "D# MAJOR"
+	keyConfidence := 0.47
+	handler := NewSpecUploadHandler(stubSpecUploadService{
+		status: func(context.Context, uuid.UUID, uuid.UUID) (*domain.SpecUploadStatus, error) {
+			return &domain.SpecUploadStatus{
+				UploadID:         uploadID,
+				SpecID:           uuid.New(),
+				ProducerID:       producerID,
+				Status:           domain.UploadStatusCompleted,
+				ProcessingStatus: domain.ProcessingStatusCompleted,
+				DeclaredBPM:      &declaredBPM,
+				DeclaredKey:      &declaredKey,
+				Suggestions: domain.AudioSuggestions{
+					BPM:           &suggestedBPM,
+					BPMConfidence: &bpmConfidence,
+					Key:           &suggestedKey,
+					KeyConfidence: &keyConfidence,
+				},
+				CreatedAt: now,
+				UpdatedAt: now,
+				ExpiresAt: now.Add(time.Hour),
+			}, nil
+		},
+	})
+	request := authenticatedUploadRequest(
+		http.MethodGet, "/spec-uploads/"+uploadID.String(), nil, producerID,
+	)
+	request.SetPathValue("id", uploadID.String())
+	response := httptest.NewRecorder()
+
+	handler.Status(response, request)
+
+	require.Equal(t, http.StatusOK, response.Code)
+	require.Contains(t, response.Body.String(),
+		`"analysis":{"bpm":{"declared":140,"suggested":142,"confidence":0.81},`+
+			`"key":{"declared":"C MINOR","suggested":"D# MAJOR","confidence":0.47}}`)
+}
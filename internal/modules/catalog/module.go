@@ -18,6 +18,8 @@ type Module struct {
 	uploadService    application.SpecUploadService
 	handler          *catalogHttp.SpecHandler
 	uploadHandler    *catalogHttp.SpecUploadHandler
+	rankingService   application.RankingProfileService
+	rankingHandler   *catalogHttp.RankingProfileHandler
 }
 
 type FileService interface {
@@ -39,6 +41,8 @@ func NewModule(
 	uploadService := application.NewSpecUploadService(uploadRepository, repository, fileService)
 	handler := catalogHttp.NewSpecHandler(service, fileService, analyticsService, notificationService, redisClient)
 	uploadHandler := catalogHttp.NewSpecUploadHandler(uploadService)
+	rankingService := application.NewRankingProfileService(repository)
+	rankingHandler := catalogHttp.NewRankingProfileHandler(rankingService)
 
 	return &Module{
 		repository:       repository,
@@ -47,6 +51,8 @@ func NewModule(
 		uploadService:    uploadService,
 		handler:          handler,
 		uploadHandler:    uploadHandler,
+		rankingService:   rankingService,
+		rankingHandler:   rankingHandler,
 	}
 }
 
@@ -81,3 +87,11 @@ func (m *Module) UploadService() application.SpecUploadService {
 func (m *Module) UploadHTTPHandler() *catalogHttp.SpecUploadHandler {
 	return m.uploadHandler
 }
+
+func (m *Module) RankingProfileService() application.RankingProfileService {
+	return m.rankingService
+}
+
+func (m *Module) RankingProfileHTTPHandler() *catalogHttp.RankingProfileHandler {
+	return m.rankingHandler
+}
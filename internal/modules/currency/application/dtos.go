@@ -0,0 +1,20 @@
+package application
+
+import (
+	"github.com/saransh1220/blueprint-audio/internal/modules/currency/domain"
+	"github.com/saransh1220/blueprint-audio/internal/shared/money"
+)
+
+// CurrenciesResponse lists the currencies prices can be shown in together
+// with the rates they are currently converted with.
+type CurrenciesResponse struct {
+	Currencies []money.Currency `json:"currencies"`
+	Rates      *money.Rates     `json:"rates"`
+}
+
+type SnapshotListResponse struct {
+	Snapshots []domain.RateSnapshot `json:"snapshots"`
+	Total     int                   `json:"total"`
+	Limit     int                   `json:"limit"`
+	Offset    int                   `json:"offset"`
+}
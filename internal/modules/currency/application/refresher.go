@@ -0,0 +1,30 @@
+package application
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// StartRateRefresher refreshes exchange rates once at startup and then every
+// interval until ctx is canceled.
+func StartRateRefresher(ctx context.Context, service CurrencyService, interval time.Duration) {
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	log.Printf("exchange rate refresher started interval=%s", interval)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := service.Refresh(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("refresh exchange rates: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Printf("exchange rate refresher stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
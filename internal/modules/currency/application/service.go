@@ -0,0 +1,103 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"maps"
+
+	"github.com/google/uuid"
+	"github.com/saransh1220/blueprint-audio/internal/modules/currency/domain"
+	"github.com/saransh1220/blueprint-audio/internal/shared/money"
+)
+
+type CurrencyService interface {
+	// Refresh fetches rates from the provider, stores them as a new snapshot
+	// if they changed and converts prices with the latest snapshot from then
+	// on. Without a provider it only loads the latest snapshot, so instances
+	// pick up rates stored by another.
+	Refresh(ctx context.Context) error
+	GetCurrencies() *CurrenciesResponse
+	ListSnapshots(ctx context.Context, page, limit int) (*SnapshotListResponse, error)
+	GetSnapshot(ctx context.Context, id uuid.UUID) (*domain.RateSnapshot, error)
+}
+
+type currencyService struct {
+	snapshots domain.RateSnapshotRepository
+	provider  money.RateProvider
+	source    string
+}
+
+// NewCurrencyService creates the service. provider may be nil; source names
+// it on the snapshots it produces.
+func NewCurrencyService(snapshots domain.RateSnapshotRepository, provider money.RateProvider, source string) CurrencyService {
+	return &currencyService{
+		snapshots: snapshots,
+		provider:  provider,
+		source:    source,
+	}
+}
+
+func (s *currencyService) Refresh(ctx context.Context) error {
+	var fetchErr error
+	if s.provider != nil {
+		fetchErr = s.fetch(ctx)
+	}
+
+	// A failed fetch keeps converting with the last stored rates.
+	latest, err := s.snapshots.Latest(ctx)
+	if err != nil {
+		return err
+	}
+	if latest != nil {
+		money.SetRates(latest.MoneyRates())
+	}
+	return fetchErr
+}
+
+func (s *currencyService) fetch(ctx context.Context) error {
+	rates, err := s.provider.FetchRates(ctx)
+	if err != nil {
+		return fmt.Errorf("fetch exchange rates: %w", err)
+	}
+	latest, err := s.snapshots.Latest(ctx)
+	if err != nil {
+		return err
+	}
+	if latest != nil && latest.BaseCurrency == rates.Base && maps.Equal(latest.Rates, rates.Rates) {
+		return nil
+	}
+	return s.snapshots.Create(ctx, &domain.RateSnapshot{
+		BaseCurrency: rates.Base,
+		Rates:        rates.Rates,
+		Source:       s.source,
+		AsOf:         rates.AsOf,
+	})
+}
+
+func (s *currencyService) GetCurrencies() *CurrenciesResponse {
+	return &CurrenciesResponse{
+		Currencies: money.SupportedCurrencies(),
+		Rates:      money.CurrentRates(),
+	}
+}
+
+func (s *currencyService) ListSnapshots(ctx context.Context, page, limit int) (*SnapshotListResponse, error) {
+	offset := (page - 1) * limit
+	if offset < 0 {
+		offset = 0
+	}
+	snapshots, total, err := s.snapshots.List(ctx, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return &SnapshotListResponse{
+		Snapshots: snapshots,
+		Total:     total,
+		Limit:     limit,
+		Offset:    offset,
+	}, nil
+}
+
+func (s *currencyService) GetSnapshot(ctx context.Context, id uuid.UUID) (*domain.RateSnapshot, error) {
+	return s.snapshots.GetByID(ctx, id)
+}
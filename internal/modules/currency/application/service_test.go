@@ -0,0 +1,123 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/saransh1220/blueprint-audio/internal/modules/currency/domain"
+	"github.com/saransh1220/blueprint-audio/internal/shared/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type snapshotRepoMock struct{ mock.Mock }
+
+func (m *snapshotRepoMock) Create(ctx context.Context, snapshot *domain.RateSnapshot) error {
+	return m.Called(ctx, snapshot).Error(0)
+}
+func (m *snapshotRepoMock) Latest(ctx context.Context) (*domain.RateSnapshot, error) {
+	args := m.Called(ctx)
+	snapshot, _ := args.Get(0).(*domain.RateSnapshot)
+	return snapshot, args.Error(1)
+}
+func (m *snapshotRepoMock) GetByID(ctx context.Context, id uuid.UUID) (*domain.RateSnapshot, error) {
+	args := m.Called(ctx, id)
+	snapshot, _ := args.Get(0).(*domain.RateSnapshot)
+	return snapshot, args.Error(1)
+}
+func (m *snapshotRepoMock) List(ctx context.Context, limit, offset int) ([]domain.RateSnapshot, int, error) {
+	args := m.Called(ctx, limit, offset)
+	snapshots, _ := args.Get(0).([]domain.RateSnapshot)
+	return snapshots, args.Int(1), args.Error(2)
+}
+
+type providerMock struct {
+	rates *money.Rates
+	err   error
+}
+
+func (p providerMock) FetchRates(context.Context) (*money.Rates, error) { return p.rates, p.err }
+
+func TestCurrencyService_Refresh(t *testing.T) {
+	ctx := context.Background()
+	defer money.SetRates(nil)
+	asOf := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	fetched := &money.Rates{Base: money.CurrencyUSD, Rates: map[string]float64{"INR": 83, "EUR": 0.9}, AsOf: asOf}
+
+	t.Run("stores changed rates and converts with them", func(t *testing.T) {
+		repo := new(snapshotRepoMock)
+		old := &domain.RateSnapshot{ID: uuid.New(), BaseCurrency: money.CurrencyUSD, Rates: map[string]float64{"INR": 80}}
+		created := &domain.RateSnapshot{}
+		repo.On("Latest", ctx).Return(old, nil).Once()
+		repo.On("Create", ctx, mock.AnythingOfType("*domain.RateSnapshot")).Run(func(args mock.Arguments) {
+			*created = *args.Get(1).(*domain.RateSnapshot)
+			created.ID = uuid.New()
+		}).Return(nil).Once()
+		repo.On("Latest", ctx).Return(created, nil).Once()
+
+		require.NoError(t, NewCurrencyService(repo, providerMock{rates: fetched}, "file").Refresh(ctx))
+		repo.AssertExpectations(t)
+		assert.Equal(t, "file", created.Source)
+		assert.Equal(t, asOf, created.AsOf)
+		assert.Equal(t, fetched.Rates, created.Rates)
+		assert.Equal(t, &created.ID, money.CurrentRates().SnapshotID)
+	})
+
+	t.Run("skips unchanged rates", func(t *testing.T) {
+		repo := new(snapshotRepoMock)
+		same := &domain.RateSnapshot{ID: uuid.New(), BaseCurrency: money.CurrencyUSD, Rates: map[string]float64{"INR": 83, "EUR": 0.9}}
+		repo.On("Latest", ctx).Return(same, nil).Twice()
+
+		require.NoError(t, NewCurrencyService(repo, providerMock{rates: fetched}, "file").Refresh(ctx))
+		repo.AssertExpectations(t)
+		assert.Equal(t, &same.ID, money.CurrentRates().SnapshotID)
+	})
+
+	t.Run("keeps the stored rates when the fetch fails", func(t *testing.T) {
+		repo := new(snapshotRepoMock)
+		stored := &domain.RateSnapshot{ID: uuid.New(), BaseCurrency: money.CurrencyUSD, Rates: map[string]float64{"INR": 82}}
+		repo.On("Latest", ctx).Return(stored, nil).Once()
+
+		err := NewCurrencyService(repo, providerMock{err: errors.New("missing file")}, "file").Refresh(ctx)
+		assert.ErrorContains(t, err, "missing file")
+		repo.AssertExpectations(t)
+		assert.Equal(t, &stored.ID, money.CurrentRates().SnapshotID)
+	})
+
+	t.Run("without a provider only loads stored rates", func(t *testing.T) {
+		money.SetRates(nil)
+		repo := new(snapshotRepoMock)
+		repo.On("Latest", ctx).Return(nil, nil).Once()
+
+		require.NoError(t, NewCurrencyService(repo, nil, "").Refresh(ctx))
+		repo.AssertExpectations(t)
+		assert.Nil(t, money.CurrentRates().SnapshotID)
+	})
+}
+
+func TestCurrencyService_Snapshots(t *testing.T) {
+	ctx := context.Background()
+	repo := new(snapshotRepoMock)
+	svc := NewCurrencyService(repo, nil, "")
+	id := uuid.New()
+
+	repo.On("List", ctx, 20, 20).Return([]domain.RateSnapshot{{ID: id}}, 21, nil).Once()
+	list, err := svc.ListSnapshots(ctx, 2, 20)
+	require.NoError(t, err)
+	assert.Equal(t, 21, list.Total)
+	assert.Equal(t, 20, list.Offset)
+	assert.Len(t, list.Snapshots, 1)
+
+	repo.On("GetByID", ctx, id).Return(nil, domain.ErrSnapshotNotFound).Once()
+	_, err = svc.GetSnapshot(ctx, id)
+	assert.ErrorIs(t, err, domain.ErrSnapshotNotFound)
+
+	currencies := svc.GetCurrencies()
+	assert.Len(t, currencies.Currencies, len(money.SupportedCurrencies()))
+	assert.NotNil(t, currencies.Rates)
+	repo.AssertExpectations(t)
+}
@@ -0,0 +1,7 @@
+package domain
+
+import "errors"
+
+var (
+	ErrSnapshotNotFound = errors.New("exchange rate snapshot not found")
+)
@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/saransh1220/blueprint-audio/internal/shared/money"
+)
+
+// RateSnapshot is one set of exchange rates as fetched from a provider. Rates
+// are units of each currency per unit of BaseCurrency.
+type RateSnapshot struct {
+	ID           uuid.UUID          `json:"id" db:"id"`
+	BaseCurrency string             `json:"base_currency" db:"base_currency"`
+	Rates        map[string]float64 `json:"rates" db:"-"`
+	Source       string             `json:"source" db:"source"`
+	AsOf         time.Time          `json:"as_of" db:"as_of"`
+	CreatedAt    time.Time          `json:"created_at" db:"created_at"`
+}
+
+// MoneyRates returns the snapshot as rates prices can be converted with.
+func (s *RateSnapshot) MoneyRates() *money.Rates {
+	id := s.ID
+	return &money.Rates{SnapshotID: &id, Base: s.BaseCurrency, Rates: s.Rates, AsOf: s.AsOf}
+}
+
+type RateSnapshotRepository interface {
+	Create(ctx context.Context, snapshot *RateSnapshot) error
+	// Latest returns the most recently stored snapshot, or nil if there is
+	// none yet.
+	Latest(ctx context.Context) (*RateSnapshot, error)
+	// GetByID returns the snapshot or ErrSnapshotNotFound.
+	GetByID(ctx context.Context, id uuid.UUID) (*RateSnapshot, error)
+	// List returns snapshots newest first with the total count.
+	List(ctx context.Context, limit, offset int) ([]RateSnapshot, int, error)
+}
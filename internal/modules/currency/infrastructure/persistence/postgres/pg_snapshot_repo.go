@@ -0,0 +1,104 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/saransh1220/blueprint-audio/internal/modules/currency/domain"
+)
+
+type PgRateSnapshotRepository struct {
+	db *sqlx.DB
+}
+
+func NewRateSnapshotRepository(db *sqlx.DB) domain.RateSnapshotRepository {
+	return &PgRateSnapshotRepository{db: db}
+}
+
+// snapshotRow carries the JSONB rates column, which the domain type leaves
+// out.
+type snapshotRow struct {
+	domain.RateSnapshot
+	RatesJSON []byte `db:"rates"`
+}
+
+func (row *snapshotRow) snapshot() (*domain.RateSnapshot, error) {
+	snapshot := row.RateSnapshot
+	if err := json.Unmarshal(row.RatesJSON, &snapshot.Rates); err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+func (r *PgRateSnapshotRepository) Create(ctx context.Context, snapshot *domain.RateSnapshot) error {
+	if snapshot.ID == uuid.Nil {
+		id, err := uuid.NewV7()
+		if err != nil {
+			return err
+		}
+		snapshot.ID = id
+	}
+	snapshot.CreatedAt = time.Now()
+	ratesJSON, err := json.Marshal(snapshot.Rates)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.ExecContext(ctx, `
+		INSERT INTO exchange_rate_snapshots (id, base_currency, rates, source, as_of, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)`,
+		snapshot.ID, snapshot.BaseCurrency, ratesJSON, snapshot.Source, snapshot.AsOf, snapshot.CreatedAt,
+	)
+	return err
+}
+
+func (r *PgRateSnapshotRepository) Latest(ctx context.Context) (*domain.RateSnapshot, error) {
+	var row snapshotRow
+	err := r.db.GetContext(ctx, &row, `SELECT * FROM exchange_rate_snapshots ORDER BY created_at DESC, id DESC LIMIT 1`)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return row.snapshot()
+}
+
+func (r *PgRateSnapshotRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.RateSnapshot, error) {
+	var row snapshotRow
+	err := r.db.GetContext(ctx, &row, `SELECT * FROM exchange_rate_snapshots WHERE id = $1`, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrSnapshotNotFound
+		}
+		return nil, err
+	}
+	return row.snapshot()
+}
+
+func (r *PgRateSnapshotRepository) List(ctx context.Context, limit, offset int) ([]domain.RateSnapshot, int, error) {
+	var total int
+	if err := r.db.GetContext(ctx, &total, `SELECT COUNT(*) FROM exchange_rate_snapshots`); err != nil {
+		return nil, 0, err
+	}
+
+	var rows []snapshotRow
+	if err := r.db.SelectContext(ctx, &rows,
+		`SELECT * FROM exchange_rate_snapshots ORDER BY created_at DESC, id DESC LIMIT $1 OFFSET $2`, limit, offset); err != nil {
+		return nil, 0, err
+	}
+	snapshots := make([]domain.RateSnapshot, 0, len(rows))
+	for i := range rows {
+		snapshot, err := rows[i].snapshot()
+		if err != nil {
+			return nil, 0, err
+		}
+		snapshots = append(snapshots, *snapshot)
+	}
+	return snapshots, total, nil
+}
@@ -0,0 +1,67 @@
+package postgres_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/saransh1220/blueprint-audio/internal/modules/currency/domain"
+	"github.com/saransh1220/blueprint-audio/internal/modules/currency/infrastructure/persistence/postgres"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newMockDB(t *testing.T) (*sqlx.DB, sqlmock.Sqlmock, func()) {
+	t.Helper()
+	sqlDB, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherRegexp))
+	require.NoError(t, err)
+	return sqlx.NewDb(sqlDB, "sqlmock"), mock, func() { _ = sqlDB.Close() }
+}
+
+func TestPgRateSnapshotRepository(t *testing.T) {
+	db, mock, cleanup := newMockDB(t)
+	defer cleanup()
+	repo := postgres.NewRateSnapshotRepository(db)
+	ctx := context.Background()
+	id := uuid.New()
+	now := time.Now()
+	columns := []string{"id", "base_currency", "rates", "source", "as_of", "created_at"}
+
+	snapshot := &domain.RateSnapshot{BaseCurrency: "USD", Rates: map[string]float64{"INR": 83.2}, Source: "file", AsOf: now}
+	mock.ExpectExec("INSERT INTO exchange_rate_snapshots").
+		WithArgs(sqlmock.AnyArg(), "USD", []byte(`{"INR":83.2}`), "file", now, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	require.NoError(t, repo.Create(ctx, snapshot))
+	assert.NotEqual(t, uuid.Nil, snapshot.ID)
+
+	mock.ExpectQuery("SELECT \\* FROM exchange_rate_snapshots ORDER BY created_at DESC").WillReturnError(sql.ErrNoRows)
+	latest, err := repo.Latest(ctx)
+	require.NoError(t, err)
+	assert.Nil(t, latest)
+
+	mock.ExpectQuery("SELECT \\* FROM exchange_rate_snapshots ORDER BY created_at DESC").
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(id, "USD", []byte(`{"INR":83.2,"EUR":0.92}`), "file", now, now))
+	latest, err = repo.Latest(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, id, latest.ID)
+	assert.Equal(t, map[string]float64{"INR": 83.2, "EUR": 0.92}, latest.Rates)
+
+	mock.ExpectQuery("SELECT \\* FROM exchange_rate_snapshots WHERE id").WithArgs(id).WillReturnError(sql.ErrNoRows)
+	_, err = repo.GetByID(ctx, id)
+	assert.ErrorIs(t, err, domain.ErrSnapshotNotFound)
+
+	mock.ExpectQuery("SELECT COUNT").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+	mock.ExpectQuery("SELECT \\* FROM exchange_rate_snapshots ORDER BY .* LIMIT").WithArgs(1, 2).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(id, "USD", []byte(`{"INR":83.2}`), "file", now, now))
+	snapshots, total, err := repo.List(ctx, 1, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 3, total)
+	require.Len(t, snapshots, 1)
+	assert.Equal(t, 83.2, snapshots[0].Rates["INR"])
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
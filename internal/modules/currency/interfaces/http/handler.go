@@ -0,0 +1,78 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/saransh1220/blueprint-audio/internal/modules/currency/application"
+	"github.com/saransh1220/blueprint-audio/internal/modules/currency/domain"
+)
+
+type CurrencyHandler struct {
+	service application.CurrencyService
+}
+
+func NewCurrencyHandler(service application.CurrencyService) *CurrencyHandler {
+	return &CurrencyHandler{service: service}
+}
+
+// ListCurrencies returns the supported currencies and the current rates, so
+// clients can offer a currency picker.
+func (h *CurrencyHandler) ListCurrencies(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.service.GetCurrencies())
+}
+
+func (h *CurrencyHandler) AdminListSnapshots(w http.ResponseWriter, r *http.Request) {
+	page := 1
+	if pageStr := r.URL.Query().Get("page"); pageStr != "" {
+		if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
+			page = p
+		}
+	}
+	limit := 20
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
+			limit = l
+		}
+	}
+	if limit > 100 {
+		limit = 100
+	}
+
+	response, err := h.service.ListSnapshots(r.Context(), page, limit)
+	if err != nil {
+		log.Printf("CurrencyHandler.AdminListSnapshots failed: %v", err)
+		http.Error(w, "failed to fetch exchange rates", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *CurrencyHandler) AdminGetSnapshot(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "invalid snapshot id", http.StatusBadRequest)
+		return
+	}
+
+	snapshot, err := h.service.GetSnapshot(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, domain.ErrSnapshotNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		log.Printf("CurrencyHandler.AdminGetSnapshot failed. id=%s err=%v", id, err)
+		http.Error(w, "failed to fetch exchange rates", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(snapshot)
+}
@@ -0,0 +1,103 @@
+package http_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/saransh1220/blueprint-audio/internal/modules/currency/application"
+	"github.com/saransh1220/blueprint-audio/internal/modules/currency/domain"
+	currencyhttp "github.com/saransh1220/blueprint-audio/internal/modules/currency/interfaces/http"
+	"github.com/saransh1220/blueprint-audio/internal/shared/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockCurrencyService struct {
+	listFn func(context.Context, int, int) (*application.SnapshotListResponse, error)
+	getFn  func(context.Context, uuid.UUID) (*domain.RateSnapshot, error)
+}
+
+func (m mockCurrencyService) Refresh(context.Context) error { return nil }
+func (m mockCurrencyService) GetCurrencies() *application.CurrenciesResponse {
+	return &application.CurrenciesResponse{
+		Currencies: money.SupportedCurrencies(),
+		Rates:      &money.Rates{Base: money.CurrencyUSD, Rates: map[string]float64{"INR": 83}},
+	}
+}
+func (m mockCurrencyService) ListSnapshots(ctx context.Context, page, limit int) (*application.SnapshotListResponse, error) {
+	return m.listFn(ctx, page, limit)
+}
+func (m mockCurrencyService) GetSnapshot(ctx context.Context, id uuid.UUID) (*domain.RateSnapshot, error) {
+	return m.getFn(ctx, id)
+}
+
+func TestCurrencyHandler_ListCurrencies(t *testing.T) {
+	h := currencyhttp.NewCurrencyHandler(mockCurrencyService{})
+
+	rec := httptest.NewRecorder()
+	h.ListCurrencies(rec, httptest.NewRequest(http.MethodGet, "/currencies", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var body struct {
+		Currencies []struct {
+			Code     string `json:"code"`
+			Exponent int    `json:"exponent"`
+		} `json:"currencies"`
+		Rates struct {
+			Base string `json:"base"`
+		} `json:"rates"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	require.NotEmpty(t, body.Currencies)
+	assert.Equal(t, "AUD", body.Currencies[0].Code)
+	assert.Equal(t, "USD", body.Rates.Base)
+}
+
+func TestCurrencyHandler_AdminSnapshots(t *testing.T) {
+	id := uuid.New()
+	var gotPage, gotLimit int
+	h := currencyhttp.NewCurrencyHandler(mockCurrencyService{
+		listFn: func(_ context.Context, page, limit int) (*application.SnapshotListResponse, error) {
+			gotPage, gotLimit = page, limit
+			return &application.SnapshotListResponse{Snapshots: []domain.RateSnapshot{}, Limit: limit}, nil
+		},
+		getFn: func(_ context.Context, got uuid.UUID) (*domain.RateSnapshot, error) {
+			if got != id {
+				return nil, domain.ErrSnapshotNotFound
+			}
+			return &domain.RateSnapshot{ID: id, BaseCurrency: "USD"}, nil
+		},
+	})
+
+	rec := httptest.NewRecorder()
+	h.AdminListSnapshots(rec, httptest.NewRequest(http.MethodGet, "/admin/exchange-rates?page=3&limit=500", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, 3, gotPage)
+	assert.Equal(t, 100, gotLimit)
+
+	get := func(rawID string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/admin/exchange-rates/"+rawID, nil)
+		req.SetPathValue("id", rawID)
+		rec := httptest.NewRecorder()
+		h.AdminGetSnapshot(rec, req)
+		return rec
+	}
+	assert.Equal(t, http.StatusOK, get(id.String()).Code)
+	assert.Equal(t, http.StatusNotFound, get(uuid.NewString()).Code)
+	assert.Equal(t, http.StatusBadRequest, get("nope").Code)
+}
+
+func TestCurrencyHandler_AdminListSnapshotsError(t *testing.T) {
+	h := currencyhttp.NewCurrencyHandler(mockCurrencyService{
+		listFn: func(context.Context, int, int) (*application.SnapshotListResponse, error) {
+			return nil, errors.New("db down")
+		},
+	})
+	rec := httptest.NewRecorder()
+	h.AdminListSnapshots(rec, httptest.NewRequest(http.MethodGet, "/admin/exchange-rates", nil))
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+}
@@ -0,0 +1,43 @@
+package currency
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/saransh1220/blueprint-audio/internal/modules/currency/application"
+	persistence "github.com/saransh1220/blueprint-audio/internal/modules/currency/infrastructure/persistence/postgres"
+	currencyHttp "github.com/saransh1220/blueprint-audio/internal/modules/currency/interfaces/http"
+	"github.com/saransh1220/blueprint-audio/internal/shared/money"
+)
+
+// Module represents the Currency module
+type Module struct {
+	service application.CurrencyService
+	handler *currencyHttp.CurrencyHandler
+}
+
+// NewModule creates and initializes the Currency module. Rates are read from
+// ratesFile; with no file the module only serves snapshots stored earlier.
+func NewModule(db *sqlx.DB, ratesFile string) *Module {
+	var provider money.RateProvider
+	if ratesFile != "" {
+		provider = money.NewFileRateProvider(ratesFile)
+	}
+
+	service := application.NewCurrencyService(persistence.NewRateSnapshotRepository(db), provider, "file")
+	handler := currencyHttp.NewCurrencyHandler(service)
+
+	return &Module{
+		service: service,
+		handler: handler,
+	}
+}
+
+// Service returns the currency service, whose Refresh keeps exchange rates
+// current.
+func (m *Module) Service() application.CurrencyService {
+	return m.service
+}
+
+// HTTPHandler returns the HTTP handler
+func (m *Module) HTTPHandler() *currencyHttp.CurrencyHandler {
+	return m.handler
+}
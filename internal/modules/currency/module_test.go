@@ -0,0 +1,17 @@
+package currency
+
+import (
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModuleAccessors(t *testing.T) {
+	for _, ratesFile := range []string{"", "rates.json"} {
+		m := NewModule(&sqlx.DB{}, ratesFile)
+		require.NotNil(t, m)
+		require.NotNil(t, m.Service())
+		require.NotNil(t, m.HTTPHandler())
+	}
+}
@@ -0,0 +1,63 @@
+package application
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/saransh1220/blueprint-audio/internal/modules/earnings/domain"
+)
+
+// Config sets how sales are split between the platform and producers.
+type Config struct {
+	// PlatformFeeBPS is the platform's commission in basis points of each
+	// item's price. Changing it only affects sales posted afterwards.
+	PlatformFeeBPS int
+	// ClearanceDays is how long a sale's earnings stay pending before they
+	// can be paid out.
+	ClearanceDays int
+}
+
+// Summary is a producer's earnings position, one balance per currency they
+// have sold in. Amounts are in minor units.
+type Summary struct {
+	Balances           []domain.Balance `json:"balances"`
+	LastPayout         *domain.Payout   `json:"last_payout"`
+	PlatformFeePercent float64          `json:"platform_fee_percent"`
+	ClearanceDays      int              `json:"clearance_days"`
+}
+
+type PayoutListResponse struct {
+	Payouts []domain.Payout `json:"payouts"`
+	Total   int             `json:"total"`
+	Limit   int             `json:"limit"`
+	Offset  int             `json:"offset"`
+}
+
+// CreatePayoutInput records money an admin has sent to a producer. Amount is
+// in minor units of Currency; PaidAt defaults to now. The request details are
+// recorded in the admin audit log.
+type CreatePayoutInput struct {
+	ProducerID uuid.UUID  `json:"producer_id"`
+	Amount     int        `json:"amount"`
+	Currency   string     `json:"currency"`
+	Method     string     `json:"method"`
+	Reference  string     `json:"reference"`
+	Note       string     `json:"note"`
+	PaidAt     *time.Time `json:"paid_at"`
+	ActorID    uuid.UUID  `json:"-"`
+	IPAddress  string     `json:"-"`
+	UserAgent  string     `json:"-"`
+}
+
+// Statement lists a producer's ledger activity for one calendar month (UTC).
+type Statement struct {
+	ProducerID    uuid.UUID                 `json:"producer_id"`
+	ProducerName  string                    `json:"producer_name"`
+	ProducerEmail string                    `json:"producer_email"`
+	Month         string                    `json:"month"`
+	From          time.Time                 `json:"from"`
+	To            time.Time                 `json:"to"`
+	Lines         []domain.StatementLine    `json:"lines"`
+	Balances      []domain.StatementBalance `json:"balances"`
+	GeneratedAt   time.Time                 `json:"generated_at"`
+}
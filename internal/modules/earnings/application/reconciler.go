@@ -0,0 +1,33 @@
+package application
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// StartLedgerReconciler posts orders that payment fulfilment failed to record,
+// once at startup and then every interval until ctx is canceled.
+func StartLedgerReconciler(ctx context.Context, service EarningsService, interval time.Duration) {
+	if interval <= 0 {
+		interval = 15 * time.Minute
+	}
+	log.Printf("earnings ledger reconciler started interval=%s", interval)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		posted, err := service.Reconcile(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("reconcile earnings ledger: %v", err)
+		} else if posted > 0 {
+			log.Printf("posted %d missed orders to the earnings ledger", posted)
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Printf("earnings ledger reconciler stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
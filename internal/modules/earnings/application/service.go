@@ -0,0 +1,471 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	authDomain "github.com/saransh1220/blueprint-audio/internal/modules/auth/domain"
+	"github.com/saransh1220/blueprint-audio/internal/modules/earnings/domain"
+	notificationDomain "github.com/saransh1220/blueprint-audio/internal/modules/notification/domain"
+	sharedmoney "github.com/saransh1220/blueprint-audio/internal/shared/money"
+)
+
+// reconcileBatchSize caps how many missed orders one reconcile pass posts.
+const reconcileBatchSize = 100
+
+type EarningsService interface {
+	// RecordSale posts a paid order to the ledger. Posting an order twice is
+	// a no-op, so callers may retry freely.
+	RecordSale(ctx context.Context, orderID uuid.UUID) error
+	// RecordRefund reverses the order's refunds that have not been posted
+	// yet, posting the sale first if it was missed. A partial refund reverses
+	// its share of the sale and the refund that completes the order reverses
+	// the rest. Like RecordSale it is idempotent.
+	RecordRefund(ctx context.Context, orderID uuid.UUID) error
+	// Reconcile posts paid and refunded orders whose postings are missing and
+	// returns how many it posted.
+	Reconcile(ctx context.Context) (int, error)
+	GetSummary(ctx context.Context, producerID uuid.UUID) (*Summary, error)
+	ListPayouts(ctx context.Context, producerID *uuid.UUID, page, limit int) (*PayoutListResponse, error)
+	CreatePayout(ctx context.Context, input CreatePayoutInput) (*domain.Payout, error)
+	GetStatement(ctx context.Context, producerID uuid.UUID, month string) (*Statement, error)
+}
+
+// Notifier defines the dependency on the notification module
+type Notifier interface {
+	Create(ctx context.Context, userID uuid.UUID, category notificationDomain.Category, title, message string, type_ notificationDomain.NotificationType) error
+}
+
+type earningsService struct {
+	ledger     domain.LedgerRepository
+	payouts    domain.PayoutRepository
+	userFinder authDomain.UserFinder
+	notifier   Notifier
+	config     Config
+}
+
+func NewEarningsService(
+	ledger domain.LedgerRepository,
+	payouts domain.PayoutRepository,
+	userFinder authDomain.UserFinder,
+	notifier Notifier,
+	config Config,
+) EarningsService {
+	return &earningsService{
+		ledger:     ledger,
+		payouts:    payouts,
+		userFinder: userFinder,
+		notifier:   notifier,
+		config:     config,
+	}
+}
+
+func (s *earningsService) RecordSale(ctx context.Context, orderID uuid.UUID) error {
+	sale, err := s.ledger.GetSale(ctx, orderID)
+	if err != nil {
+		return err
+	}
+	return s.postSale(ctx, sale)
+}
+
+func (s *earningsService) postSale(ctx context.Context, sale *domain.Sale) error {
+	transaction, err := saleTransaction(sale, s.config)
+	if err != nil {
+		return err
+	}
+	if err := s.ledger.Post(ctx, transaction); err != nil && !errors.Is(err, domain.ErrAlreadyPosted) {
+		return err
+	}
+	return nil
+}
+
+func (s *earningsService) RecordRefund(ctx context.Context, orderID uuid.UUID) error {
+	sale, err := s.ledger.GetSale(ctx, orderID)
+	if err != nil {
+		return err
+	}
+	if len(sale.Refunds) == 0 {
+		return fmt.Errorf("order %s has not been refunded", orderID)
+	}
+	if err := s.postSale(ctx, sale); err != nil {
+		return err
+	}
+
+	posted, err := s.ledger.GetOrderTransaction(ctx, orderID, domain.TransactionKindSale)
+	if err != nil {
+		return err
+	}
+	reversals, err := s.ledger.ListRefundTransactions(ctx, orderID)
+	if err != nil {
+		return err
+	}
+	remaining := remainingAmounts(posted, reversals)
+
+	// Refunds are posted in order, so everything up to the latest posted one
+	// is already in the ledger.
+	next := 0
+	for i, refund := range sale.Refunds {
+		for _, reversal := range reversals {
+			if reversal.RefundID != nil && *reversal.RefundID == refund.ID {
+				next = i + 1
+			}
+		}
+	}
+	refunded := 0
+	for i, refund := range sale.Refunds {
+		refunded += refund.Amount
+		if i < next {
+			continue
+		}
+		completes := refunded >= sale.Amount || (sale.Status == "refunded" && i == len(sale.Refunds)-1)
+		reversal := refundTransaction(posted, remaining, refund, sale.Amount, completes)
+		if len(reversal.Entries) == 0 {
+			continue
+		}
+		if err := s.ledger.Post(ctx, reversal); err != nil {
+			// Another caller is posting this order's refunds.
+			if errors.Is(err, domain.ErrAlreadyPosted) {
+				return nil
+			}
+			return err
+		}
+		for _, entry := range reversal.Entries {
+			remaining[keyOf(entry)] += entry.Amount
+		}
+	}
+	return nil
+}
+
+// saleTransaction splits each item of a sale between the provider, the
+// platform and the item's producer. The provider's fee is shared across items
+// in proportion to their price; the last item absorbs the rounding.
+//
+// For an item sold for A with provider fee F and platform fee C:
+//
+//	gateway           +A
+//	provider_fees     -F
+//	platform_revenue  -C
+//	producer_payable  -(A - F - C)
+func saleTransaction(sale *domain.Sale, config Config) (*domain.Transaction, error) {
+	if len(sale.Lines) == 0 {
+		return nil, fmt.Errorf("order %s has no items", sale.OrderID)
+	}
+	total := 0
+	for _, line := range sale.Lines {
+		total += line.Amount
+	}
+
+	orderID := sale.OrderID
+	transaction := &domain.Transaction{
+		Kind:       domain.TransactionKindSale,
+		OrderID:    &orderID,
+		Currency:   sale.Currency,
+		OccurredAt: sale.PaidAt,
+	}
+	availableAt := sale.PaidAt.AddDate(0, 0, config.ClearanceDays)
+	feeLeft := sale.ProviderFee
+	for i, line := range sale.Lines {
+		providerFee := feeLeft
+		if i < len(sale.Lines)-1 {
+			providerFee = sale.ProviderFee * line.Amount / total
+		}
+		feeLeft -= providerFee
+		platformFee := (line.Amount*config.PlatformFeeBPS + 5000) / 10000
+
+		itemID := line.OrderItemID
+		add := func(account domain.Account, amount int) {
+			if amount == 0 {
+				return
+			}
+			transaction.Entries = append(transaction.Entries, domain.Entry{
+				Account:     account,
+				ProducerID:  line.ProducerID,
+				OrderItemID: &itemID,
+				Amount:      amount,
+				Currency:    sale.Currency,
+				AvailableAt: availableAt,
+			})
+		}
+		add(domain.AccountGateway, line.Amount)
+		add(domain.AccountProviderFees, -providerFee)
+		add(domain.AccountPlatformRevenue, -platformFee)
+		add(domain.AccountProducerPayable, -(line.Amount - providerFee - platformFee))
+	}
+	return transaction, nil
+}
+
+// entryKey identifies what a sale entry moved: one account of one item.
+type entryKey struct {
+	account     domain.Account
+	producerID  uuid.UUID
+	orderItemID uuid.UUID
+}
+
+func keyOf(entry domain.Entry) entryKey {
+	key := entryKey{account: entry.Account, producerID: entry.ProducerID}
+	if entry.OrderItemID != nil {
+		key.orderItemID = *entry.OrderItemID
+	}
+	return key
+}
+
+// remainingAmounts is what each entry of a sale still holds after the
+// reversals already posted against it.
+func remainingAmounts(sale *domain.Transaction, reversals []domain.Transaction) map[entryKey]int {
+	remaining := make(map[entryKey]int, len(sale.Entries))
+	for _, entry := range sale.Entries {
+		remaining[keyOf(entry)] += entry.Amount
+	}
+	for _, reversal := range reversals {
+		for _, entry := range reversal.Entries {
+			remaining[keyOf(entry)] += entry.Amount
+		}
+	}
+	return remaining
+}
+
+// refundTransaction reverses a refund's share of a sale. A partial refund
+// takes back the same fraction of every entry, with the producer payable
+// absorbing each item's rounding so the item still balances. The refund that
+// completes the order reverses whatever the earlier ones left.
+//
+// Entries keep their available_at, so refunding a sale still in clearance
+// cancels its pending earnings while refunding a cleared one comes out of the
+// available balance.
+func refundTransaction(sale *domain.Transaction, remaining map[entryKey]int, refund domain.SaleRefund, saleAmount int, completes bool) *domain.Transaction {
+	refundID := refund.ID
+	reversal := &domain.Transaction{
+		Kind:       domain.TransactionKindRefund,
+		OrderID:    sale.OrderID,
+		RefundID:   &refundID,
+		Currency:   sale.Currency,
+		OccurredAt: refund.CreatedAt,
+	}
+	add := func(entry domain.Entry, amount int) {
+		if amount == 0 {
+			return
+		}
+		reversal.Entries = append(reversal.Entries, domain.Entry{
+			Account:     entry.Account,
+			ProducerID:  entry.ProducerID,
+			OrderItemID: entry.OrderItemID,
+			Amount:      amount,
+			Currency:    entry.Currency,
+			AvailableAt: entry.AvailableAt,
+		})
+	}
+
+	if completes || saleAmount <= 0 {
+		for _, entry := range sale.Entries {
+			add(entry, -remaining[keyOf(entry)])
+		}
+		return reversal
+	}
+
+	// Entries of an item sum to zero, so the payable is whatever balances
+	// the item's other reversed entries.
+	itemTotals := map[uuid.UUID]int{}
+	for _, entry := range sale.Entries {
+		if entry.Account == domain.AccountProducerPayable {
+			continue
+		}
+		amount := -prorate(entry.Amount, refund.Amount, saleAmount)
+		itemTotals[keyOf(entry).orderItemID] += amount
+		add(entry, amount)
+	}
+	for _, entry := range sale.Entries {
+		if entry.Account == domain.AccountProducerPayable {
+			add(entry, -itemTotals[keyOf(entry).orderItemID])
+		}
+	}
+	return reversal
+}
+
+// prorate returns amount * part / whole rounded half away from zero.
+func prorate(amount, part, whole int) int {
+	scaled := amount * part
+	if scaled < 0 {
+		return -((-scaled + whole/2) / whole)
+	}
+	return (scaled + whole/2) / whole
+}
+
+func (s *earningsService) Reconcile(ctx context.Context) (int, error) {
+	orders, err := s.ledger.ListUnposted(ctx, reconcileBatchSize)
+	if err != nil {
+		return 0, err
+	}
+	posted := 0
+	for _, order := range orders {
+		record := s.RecordSale
+		if order.Refunded {
+			record = s.RecordRefund
+		}
+		if err := record(ctx, order.OrderID); err != nil {
+			log.Printf("EarningsService.Reconcile failed to post order. order_id=%s err=%v", order.OrderID, err)
+			continue
+		}
+		posted++
+	}
+	return posted, nil
+}
+
+func (s *earningsService) GetSummary(ctx context.Context, producerID uuid.UUID) (*Summary, error) {
+	balances, err := s.ledger.GetBalances(ctx, producerID)
+	if err != nil {
+		return nil, err
+	}
+	summary := &Summary{
+		Balances:           balances,
+		PlatformFeePercent: float64(s.config.PlatformFeeBPS) / 100,
+		ClearanceDays:      s.config.ClearanceDays,
+	}
+
+	latest, _, err := s.payouts.List(ctx, &producerID, 1, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(latest) > 0 {
+		summary.LastPayout = &latest[0]
+	}
+	return summary, nil
+}
+
+func (s *earningsService) ListPayouts(ctx context.Context, producerID *uuid.UUID, page, limit int) (*PayoutListResponse, error) {
+	offset := (page - 1) * limit
+	if offset < 0 {
+		offset = 0
+	}
+	payouts, total, err := s.payouts.List(ctx, producerID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return &PayoutListResponse{
+		Payouts: payouts,
+		Total:   total,
+		Limit:   limit,
+		Offset:  offset,
+	}, nil
+}
+
+// CreatePayout records a payout and debits it from the producer's available
+// balance. The balance check happens under a lock in PayoutRepository.Create.
+func (s *earningsService) CreatePayout(ctx context.Context, input CreatePayoutInput) (*domain.Payout, error) {
+	currency := strings.ToUpper(strings.TrimSpace(input.Currency))
+	if currency != sharedmoney.CurrencyINR && currency != sharedmoney.CurrencyUSD {
+		return nil, fmt.Errorf("%w: currency must be INR or USD", domain.ErrInvalidPayout)
+	}
+	if input.Amount <= 0 {
+		return nil, fmt.Errorf("%w: amount must be positive", domain.ErrInvalidPayout)
+	}
+	now := time.Now()
+	paidAt := now
+	if input.PaidAt != nil {
+		if input.PaidAt.After(now) {
+			return nil, fmt.Errorf("%w: paid_at cannot be in the future", domain.ErrInvalidPayout)
+		}
+		paidAt = *input.PaidAt
+	}
+	producer, err := s.userFinder.FindByID(ctx, input.ProducerID)
+	if err != nil || producer == nil {
+		return nil, fmt.Errorf("%w: producer not found", domain.ErrInvalidPayout)
+	}
+
+	actorID := input.ActorID
+	payout := &domain.Payout{
+		ProducerID: input.ProducerID,
+		Amount:     input.Amount,
+		Currency:   currency,
+		Method:     strings.TrimSpace(input.Method),
+		Reference:  optionalString(input.Reference),
+		Note:       optionalString(input.Note),
+		CreatedBy:  &actorID,
+		PaidAt:     paidAt,
+	}
+	transaction := &domain.Transaction{
+		Kind:       domain.TransactionKindPayout,
+		Currency:   currency,
+		OccurredAt: paidAt,
+		Entries: []domain.Entry{
+			{Account: domain.AccountProducerPayable, ProducerID: payout.ProducerID, Amount: payout.Amount, Currency: currency, AvailableAt: paidAt},
+			{Account: domain.AccountGateway, ProducerID: payout.ProducerID, Amount: -payout.Amount, Currency: currency, AvailableAt: paidAt},
+		},
+	}
+	if err := s.payouts.Create(ctx, payout, transaction, domain.PayoutAudit{
+		ActorID:   actorID,
+		IPAddress: input.IPAddress,
+		UserAgent: input.UserAgent,
+	}); err != nil {
+		return nil, err
+	}
+
+	go func() {
+		notifyCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		s.notifyPayout(notifyCtx, payout)
+	}()
+
+	return payout, nil
+}
+
+func (s *earningsService) notifyPayout(ctx context.Context, payout *domain.Payout) {
+	if s.notifier == nil {
+		return
+	}
+	message := fmt.Sprintf("A payout of %s was sent to you.", formatMoney(payout.Amount, payout.Currency))
+	if payout.Reference != nil {
+		message = fmt.Sprintf("A payout of %s was sent to you (reference %s).", formatMoney(payout.Amount, payout.Currency), *payout.Reference)
+	}
+	if err := s.notifier.Create(ctx, payout.ProducerID, notificationDomain.CategoryAccount, "Payout sent", message, notificationDomain.NotificationTypeSuccess); err != nil {
+		log.Printf("EarningsService.notifyPayout failed. payout_id=%s err=%v", payout.ID, err)
+	}
+}
+
+// GetStatement builds the producer's statement for month, given as YYYY-MM.
+// The current month gives a statement to date.
+func (s *earningsService) GetStatement(ctx context.Context, producerID uuid.UUID, month string) (*Statement, error) {
+	from, err := time.Parse("2006-01", month)
+	if err != nil {
+		return nil, fmt.Errorf("%w: expected YYYY-MM", domain.ErrInvalidStatementMonth)
+	}
+	now := time.Now().UTC()
+	if from.After(now) {
+		return nil, fmt.Errorf("%w: %s has not started yet", domain.ErrInvalidStatementMonth, month)
+	}
+	to := from.AddDate(0, 1, 0)
+
+	lines, balances, err := s.ledger.GetStatement(ctx, producerID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	statement := &Statement{
+		ProducerID:  producerID,
+		Month:       month,
+		From:        from,
+		To:          to,
+		Lines:       lines,
+		Balances:    balances,
+		GeneratedAt: now,
+	}
+	if producer, err := s.userFinder.FindByID(ctx, producerID); err == nil && producer != nil {
+		statement.ProducerName = producer.Name
+		statement.ProducerEmail = producer.Email
+		if producer.DisplayName != nil && *producer.DisplayName != "" {
+			statement.ProducerName = *producer.DisplayName
+		}
+	}
+	return statement, nil
+}
+
+func optionalString(value string) *string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+	return &value
+}
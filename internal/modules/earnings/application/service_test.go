@@ -0,0 +1,463 @@
+package application
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	authDomain "github.com/saransh1220/blueprint-audio/internal/modules/auth/domain"
+	"github.com/saransh1220/blueprint-audio/internal/modules/earnings/domain"
+	notificationDomain "github.com/saransh1220/blueprint-audio/internal/modules/notification/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type ledgerRepoMock struct{ mock.Mock }
+
+func (m *ledgerRepoMock) GetSale(ctx context.Context, orderID uuid.UUID) (*domain.Sale, error) {
+	args := m.Called(ctx, orderID)
+	sale, _ := args.Get(0).(*domain.Sale)
+	return sale, args.Error(1)
+}
+func (m *ledgerRepoMock) GetOrderTransaction(ctx context.Context, orderID uuid.UUID, kind domain.TransactionKind) (*domain.Transaction, error) {
+	args := m.Called(ctx, orderID, kind)
+	transaction, _ := args.Get(0).(*domain.Transaction)
+	return transaction, args.Error(1)
+}
+func (m *ledgerRepoMock) ListRefundTransactions(ctx context.Context, orderID uuid.UUID) ([]domain.Transaction, error) {
+	args := m.Called(ctx, orderID)
+	transactions, _ := args.Get(0).([]domain.Transaction)
+	return transactions, args.Error(1)
+}
+func (m *ledgerRepoMock) Post(ctx context.Context, transaction *domain.Transaction) error {
+	return m.Called(ctx, transaction).Error(0)
+}
+func (m *ledgerRepoMock) ListUnposted(ctx context.Context, limit int) ([]domain.UnpostedOrder, error) {
+	args := m.Called(ctx, limit)
+	orders, _ := args.Get(0).([]domain.UnpostedOrder)
+	return orders, args.Error(1)
+}
+func (m *ledgerRepoMock) GetBalances(ctx context.Context, producerID uuid.UUID) ([]domain.Balance, error) {
+	args := m.Called(ctx, producerID)
+	balances, _ := args.Get(0).([]domain.Balance)
+	return balances, args.Error(1)
+}
+func (m *ledgerRepoMock) GetStatement(ctx context.Context, producerID uuid.UUID, from, to time.Time) ([]domain.StatementLine, []domain.StatementBalance, error) {
+	args := m.Called(ctx, producerID, from, to)
+	lines, _ := args.Get(0).([]domain.StatementLine)
+	balances, _ := args.Get(1).([]domain.StatementBalance)
+	return lines, balances, args.Error(2)
+}
+
+type payoutRepoMock struct{ mock.Mock }
+
+func (m *payoutRepoMock) Create(ctx context.Context, payout *domain.Payout, transaction *domain.Transaction, audit domain.PayoutAudit) error {
+	return m.Called(ctx, payout, transaction, audit).Error(0)
+}
+func (m *payoutRepoMock) List(ctx context.Context, producerID *uuid.UUID, limit, offset int) ([]domain.Payout, int, error) {
+	args := m.Called(ctx, producerID, limit, offset)
+	payouts, _ := args.Get(0).([]domain.Payout)
+	return payouts, args.Int(1), args.Error(2)
+}
+
+type userFinderMock struct{ mock.Mock }
+
+func (m *userFinderMock) FindByID(ctx context.Context, id uuid.UUID) (*authDomain.User, error) {
+	args := m.Called(ctx, id)
+	user, _ := args.Get(0).(*authDomain.User)
+	return user, args.Error(1)
+}
+func (m *userFinderMock) Exists(ctx context.Context, id uuid.UUID) (bool, error) {
+	args := m.Called(ctx, id)
+	return args.Bool(0), args.Error(1)
+}
+
+type notifierMock struct{ mock.Mock }
+
+func (m *notifierMock) Create(ctx context.Context, userID uuid.UUID, category notificationDomain.Category, title, message string, type_ notificationDomain.NotificationType) error {
+	return m.Called(ctx, userID, category, title, message, type_).Error(0)
+}
+
+func newEarningsSvc() (*earningsService, *ledgerRepoMock, *payoutRepoMock, *userFinderMock) {
+	ledger := new(ledgerRepoMock)
+	payouts := new(payoutRepoMock)
+	users := new(userFinderMock)
+	return &earningsService{
+		ledger:     ledger,
+		payouts:    payouts,
+		userFinder: users,
+		config:     Config{PlatformFeeBPS: 1000, ClearanceDays: 7},
+	}, ledger, payouts, users
+}
+
+// sumByAccount totals a transaction's entries per account and producer.
+func sumByAccount(transaction *domain.Transaction, producerID uuid.UUID) map[domain.Account]int {
+	totals := map[domain.Account]int{}
+	for _, entry := range transaction.Entries {
+		if entry.ProducerID == producerID {
+			totals[entry.Account] += entry.Amount
+		}
+	}
+	return totals
+}
+
+func TestSaleTransaction_SplitsFeesPerItem(t *testing.T) {
+	producerA, producerB := uuid.New(), uuid.New()
+	paidAt := time.Date(2026, 5, 10, 12, 0, 0, 0, time.UTC)
+	sale := &domain.Sale{
+		OrderID:     uuid.New(),
+		Currency:    "INR",
+		Amount:      3000,
+		ProviderFee: 70,
+		PaidAt:      paidAt,
+		Lines: []domain.SaleLine{
+			{OrderItemID: uuid.New(), ProducerID: producerA, Amount: 1000},
+			{OrderItemID: uuid.New(), ProducerID: producerB, Amount: 2000},
+		},
+	}
+
+	transaction, err := saleTransaction(sale, Config{PlatformFeeBPS: 1000, ClearanceDays: 7})
+	require.NoError(t, err)
+	require.NoError(t, transaction.Validate())
+	assert.Equal(t, domain.TransactionKindSale, transaction.Kind)
+	assert.Equal(t, paidAt, transaction.OccurredAt)
+
+	// The 70 fee splits 23/47 by price; the last item takes the rounding.
+	a := sumByAccount(transaction, producerA)
+	assert.Equal(t, 1000, a[domain.AccountGateway])
+	assert.Equal(t, -23, a[domain.AccountProviderFees])
+	assert.Equal(t, -100, a[domain.AccountPlatformRevenue])
+	assert.Equal(t, -877, a[domain.AccountProducerPayable])
+
+	b := sumByAccount(transaction, producerB)
+	assert.Equal(t, -47, b[domain.AccountProviderFees])
+	assert.Equal(t, -200, b[domain.AccountPlatformRevenue])
+	assert.Equal(t, -1753, b[domain.AccountProducerPayable])
+
+	for _, entry := range transaction.Entries {
+		assert.Equal(t, paidAt.AddDate(0, 0, 7), entry.AvailableAt)
+		require.NotNil(t, entry.OrderItemID)
+	}
+
+	_, err = saleTransaction(&domain.Sale{OrderID: uuid.New()}, Config{})
+	assert.Error(t, err)
+}
+
+func TestEarningsService_RecordSaleIsIdempotent(t *testing.T) {
+	s, ledger, _, _ := newEarningsSvc()
+	ctx := context.Background()
+	orderID := uuid.New()
+	sale := &domain.Sale{
+		OrderID:  orderID,
+		Currency: "INR",
+		PaidAt:   time.Now(),
+		Lines:    []domain.SaleLine{{OrderItemID: uuid.New(), ProducerID: uuid.New(), Amount: 1000}},
+	}
+
+	ledger.On("GetSale", ctx, orderID).Return(sale, nil).Twice()
+	ledger.On("Post", ctx, mock.AnythingOfType("*domain.Transaction")).Return(nil).Once()
+	ledger.On("Post", ctx, mock.AnythingOfType("*domain.Transaction")).Return(domain.ErrAlreadyPosted).Once()
+
+	require.NoError(t, s.RecordSale(ctx, orderID))
+	require.NoError(t, s.RecordSale(ctx, orderID))
+	ledger.AssertExpectations(t)
+}
+
+func TestEarningsService_RecordRefundReversesSale(t *testing.T) {
+	s, ledger, _, _ := newEarningsSvc()
+	ctx := context.Background()
+	orderID, itemID, producerID := uuid.New(), uuid.New(), uuid.New()
+	paidAt := time.Now().Add(-48 * time.Hour)
+	refund := domain.SaleRefund{ID: uuid.New(), Amount: 1000, CreatedAt: time.Now()}
+	sale := &domain.Sale{
+		OrderID:  orderID,
+		Status:   "refunded",
+		Currency: "USD",
+		Amount:   1000,
+		PaidAt:   paidAt,
+		Lines:    []domain.SaleLine{{OrderItemID: itemID, ProducerID: producerID, Amount: 1000}},
+		Refunds:  []domain.SaleRefund{refund},
+	}
+	posted, err := saleTransaction(sale, s.config)
+	require.NoError(t, err)
+
+	ledger.On("GetSale", ctx, orderID).Return(sale, nil).Once()
+	ledger.On("Post", ctx, mock.MatchedBy(func(t *domain.Transaction) bool {
+		return t.Kind == domain.TransactionKindSale
+	})).Return(domain.ErrAlreadyPosted).Once()
+	ledger.On("GetOrderTransaction", ctx, orderID, domain.TransactionKindSale).Return(posted, nil).Once()
+	ledger.On("ListRefundTransactions", ctx, orderID).Return(nil, nil).Once()
+	ledger.On("Post", ctx, mock.MatchedBy(func(reversal *domain.Transaction) bool {
+		if reversal.Kind != domain.TransactionKindRefund || !reversal.OccurredAt.Equal(refund.CreatedAt) ||
+			reversal.RefundID == nil || *reversal.RefundID != refund.ID || reversal.Validate() != nil {
+			return false
+		}
+		totals := sumByAccount(reversal, producerID)
+		return totals[domain.AccountProducerPayable] == 900 &&
+			totals[domain.AccountPlatformRevenue] == 100 &&
+			reversal.Entries[0].AvailableAt.Equal(posted.Entries[0].AvailableAt)
+	})).Return(nil).Once()
+
+	require.NoError(t, s.RecordRefund(ctx, orderID))
+	ledger.AssertExpectations(t)
+
+	unrefunded := *sale
+	unrefunded.Refunds = nil
+	ledger.On("GetSale", ctx, orderID).Return(&unrefunded, nil).Once()
+	assert.Error(t, s.RecordRefund(ctx, orderID))
+}
+
+func TestEarningsService_RecordRefundPostsPartialRefunds(t *testing.T) {
+	s, ledger, _, _ := newEarningsSvc()
+	ctx := context.Background()
+	orderID, itemID, producerID := uuid.New(), uuid.New(), uuid.New()
+	first := domain.SaleRefund{ID: uuid.New(), Amount: 333, CreatedAt: time.Now().Add(-time.Hour)}
+	second := domain.SaleRefund{ID: uuid.New(), Amount: 667, CreatedAt: time.Now()}
+	sale := &domain.Sale{
+		OrderID:     orderID,
+		Status:      "paid",
+		Currency:    "INR",
+		Amount:      1000,
+		ProviderFee: 30,
+		PaidAt:      time.Now().Add(-48 * time.Hour),
+		Lines:       []domain.SaleLine{{OrderItemID: itemID, ProducerID: producerID, Amount: 1000}},
+		Refunds:     []domain.SaleRefund{first},
+	}
+	posted, err := saleTransaction(sale, s.config)
+	require.NoError(t, err)
+	var reversals []domain.Transaction
+	capture := func(args mock.Arguments) {
+		reversals = append(reversals, *args.Get(1).(*domain.Transaction))
+	}
+	isRefund := mock.MatchedBy(func(t *domain.Transaction) bool { return t.Kind == domain.TransactionKindRefund })
+	isSale := mock.MatchedBy(func(t *domain.Transaction) bool { return t.Kind == domain.TransactionKindSale })
+
+	// The first refund takes back its share of every entry.
+	ledger.On("GetSale", ctx, orderID).Return(sale, nil).Once()
+	ledger.On("Post", ctx, isSale).Return(domain.ErrAlreadyPosted).Once()
+	ledger.On("GetOrderTransaction", ctx, orderID, domain.TransactionKindSale).Return(posted, nil).Once()
+	ledger.On("ListRefundTransactions", ctx, orderID).Return(nil, nil).Once()
+	ledger.On("Post", ctx, isRefund).Run(capture).Return(nil).Once()
+	require.NoError(t, s.RecordRefund(ctx, orderID))
+
+	require.Len(t, reversals, 1)
+	require.NoError(t, reversals[0].Validate())
+	assert.Equal(t, first.ID, *reversals[0].RefundID)
+	totals := sumByAccount(&reversals[0], producerID)
+	assert.Equal(t, -333, totals[domain.AccountGateway])
+	assert.Equal(t, 10, totals[domain.AccountProviderFees])
+	assert.Equal(t, 33, totals[domain.AccountPlatformRevenue])
+	assert.Equal(t, 290, totals[domain.AccountProducerPayable])
+
+	// The second completes the refund and reverses only what is left.
+	completed := *sale
+	completed.Status = "refunded"
+	completed.Refunds = []domain.SaleRefund{first, second}
+	ledger.On("GetSale", ctx, orderID).Return(&completed, nil).Once()
+	ledger.On("Post", ctx, isSale).Return(domain.ErrAlreadyPosted).Once()
+	ledger.On("GetOrderTransaction", ctx, orderID, domain.TransactionKindSale).Return(posted, nil).Once()
+	ledger.On("ListRefundTransactions", ctx, orderID).Return(reversals, nil).Once()
+	ledger.On("Post", ctx, isRefund).Run(capture).Return(nil).Once()
+	require.NoError(t, s.RecordRefund(ctx, orderID))
+	ledger.AssertExpectations(t)
+
+	require.Len(t, reversals, 2)
+	require.NoError(t, reversals[1].Validate())
+	assert.Equal(t, second.ID, *reversals[1].RefundID)
+	assert.True(t, reversals[1].OccurredAt.Equal(second.CreatedAt))
+	totals = sumByAccount(&reversals[1], producerID)
+	assert.Equal(t, -667, totals[domain.AccountGateway])
+	assert.Equal(t, 20, totals[domain.AccountProviderFees])
+	assert.Equal(t, 67, totals[domain.AccountPlatformRevenue])
+	assert.Equal(t, 580, totals[domain.AccountProducerPayable])
+
+	net := sumByAccount(posted, producerID)
+	for _, reversal := range reversals {
+		for account, amount := range sumByAccount(&reversal, producerID) {
+			net[account] += amount
+		}
+	}
+	for account, amount := range net {
+		assert.Zero(t, amount, account)
+	}
+}
+
+func TestEarningsService_Reconcile(t *testing.T) {
+	s, ledger, _, _ := newEarningsSvc()
+	ctx := context.Background()
+	paidID, brokenID := uuid.New(), uuid.New()
+
+	ledger.On("ListUnposted", ctx, reconcileBatchSize).Return([]domain.UnpostedOrder{
+		{OrderID: paidID, Status: "paid"},
+		{OrderID: brokenID, Status: "paid", Refunded: true},
+	}, nil).Once()
+	ledger.On("GetSale", ctx, paidID).Return(&domain.Sale{
+		OrderID: paidID, Currency: "INR", PaidAt: time.Now(),
+		Lines: []domain.SaleLine{{OrderItemID: uuid.New(), ProducerID: uuid.New(), Amount: 500}},
+	}, nil).Once()
+	ledger.On("GetSale", ctx, brokenID).Return(nil, domain.ErrSaleNotFound).Once()
+	ledger.On("Post", ctx, mock.AnythingOfType("*domain.Transaction")).Return(nil).Once()
+
+	posted, err := s.Reconcile(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, posted)
+	ledger.AssertExpectations(t)
+}
+
+func TestEarningsService_GetSummary(t *testing.T) {
+	s, ledger, payouts, _ := newEarningsSvc()
+	ctx := context.Background()
+	producerID := uuid.New()
+	last := domain.Payout{ID: uuid.New(), ProducerID: producerID, Amount: 500, Currency: "INR"}
+
+	ledger.On("GetBalances", ctx, producerID).Return([]domain.Balance{{Currency: "INR", Available: 900}}, nil).Once()
+	payouts.On("List", ctx, &producerID, 1, 0).Return([]domain.Payout{last}, 3, nil).Once()
+
+	summary, err := s.GetSummary(ctx, producerID)
+	require.NoError(t, err)
+	assert.Equal(t, 10.0, summary.PlatformFeePercent)
+	assert.Equal(t, 7, summary.ClearanceDays)
+	require.NotNil(t, summary.LastPayout)
+	assert.Equal(t, last.ID, summary.LastPayout.ID)
+	assert.Equal(t, 900, summary.Balances[0].Available)
+}
+
+func TestEarningsService_CreatePayout(t *testing.T) {
+	s, _, payouts, users := newEarningsSvc()
+	notifier := new(notifierMock)
+	s.notifier = notifier
+	ctx := context.Background()
+	producerID, actorID := uuid.New(), uuid.New()
+
+	users.On("FindByID", ctx, producerID).Return(&authDomain.User{ID: producerID}, nil)
+	payouts.On("Create", ctx, mock.MatchedBy(func(payout *domain.Payout) bool {
+		return payout.Amount == 500 && payout.Currency == "INR" && *payout.Reference == "UTR1" && payout.Note == nil
+	}), mock.MatchedBy(func(transaction *domain.Transaction) bool {
+		totals := sumByAccount(transaction, producerID)
+		return transaction.Kind == domain.TransactionKindPayout &&
+			totals[domain.AccountProducerPayable] == 500 && totals[domain.AccountGateway] == -500
+	}), domain.PayoutAudit{ActorID: actorID, IPAddress: "127.0.0.1"}).Return(nil).Once()
+	notifier.On("Create", mock.Anything, producerID, notificationDomain.CategoryAccount, "Payout sent", "A payout of INR 5.00 was sent to you (reference UTR1).", notificationDomain.NotificationTypeSuccess).Return(nil).Once()
+
+	payout, err := s.CreatePayout(ctx, CreatePayoutInput{
+		ProducerID: producerID, Amount: 500, Currency: "inr", Method: "bank_transfer", Reference: " UTR1 ",
+		ActorID: actorID, IPAddress: "127.0.0.1",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &actorID, payout.CreatedBy)
+
+	time.Sleep(50 * time.Millisecond)
+	payouts.AssertExpectations(t)
+	notifier.AssertExpectations(t)
+
+	future := time.Now().Add(time.Hour)
+	for _, input := range []CreatePayoutInput{
+		{ProducerID: producerID, Amount: 500, Currency: "EUR"},
+		{ProducerID: producerID, Amount: 0, Currency: "INR"},
+		{ProducerID: producerID, Amount: 500, Currency: "INR", PaidAt: &future},
+	} {
+		_, err := s.CreatePayout(ctx, input)
+		assert.ErrorIs(t, err, domain.ErrInvalidPayout)
+	}
+
+	missingID := uuid.New()
+	users.On("FindByID", ctx, missingID).Return(nil, nil).Once()
+	_, err = s.CreatePayout(ctx, CreatePayoutInput{ProducerID: missingID, Amount: 500, Currency: "INR"})
+	assert.ErrorIs(t, err, domain.ErrInvalidPayout)
+
+	payouts.On("Create", ctx, mock.Anything, mock.Anything, mock.Anything).Return(domain.ErrInsufficientBalance).Once()
+	_, err = s.CreatePayout(ctx, CreatePayoutInput{ProducerID: producerID, Amount: 10_000, Currency: "INR"})
+	assert.ErrorIs(t, err, domain.ErrInsufficientBalance)
+}
+
+func TestEarningsService_GetStatement(t *testing.T) {
+	s, ledger, _, users := newEarningsSvc()
+	ctx := context.Background()
+	producerID := uuid.New()
+	displayName := "Night Shift"
+	from := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	ledger.On("GetStatement", ctx, producerID, from, to).Return([]domain.StatementLine{}, []domain.StatementBalance{}, nil).Once()
+	users.On("FindByID", ctx, producerID).Return(&authDomain.User{Name: "Sam", Email: "sam@example.com", DisplayName: &displayName}, nil).Once()
+
+	statement, err := s.GetStatement(ctx, producerID, "2026-05")
+	require.NoError(t, err)
+	assert.Equal(t, "Night Shift", statement.ProducerName)
+	assert.Equal(t, "sam@example.com", statement.ProducerEmail)
+	assert.Equal(t, from, statement.From)
+	assert.Equal(t, to, statement.To)
+
+	_, err = s.GetStatement(ctx, producerID, "May 2026")
+	assert.ErrorIs(t, err, domain.ErrInvalidStatementMonth)
+	_, err = s.GetStatement(ctx, producerID, time.Now().UTC().AddDate(0, 2, 0).Format("2006-01"))
+	assert.ErrorIs(t, err, domain.ErrInvalidStatementMonth)
+}
+
+func testStatement() *Statement {
+	orderID := uuid.New()
+	return &Statement{
+		ProducerName:  "Night Shift",
+		ProducerEmail: "sam@example.com",
+		Month:         "2026-05",
+		From:          time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC),
+		To:            time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC),
+		GeneratedAt:   time.Date(2026, 6, 2, 9, 0, 0, 0, time.UTC),
+		Lines: []domain.StatementLine{
+			{Kind: domain.TransactionKindSale, OccurredAt: time.Date(2026, 5, 3, 10, 0, 0, 0, time.UTC), Currency: "INR", OrderID: &orderID,
+				SpecTitle: "Midnight Drive", LicenseType: "Basic", Gross: 100000, PlatformFee: 10000, ProviderFee: 2360, Net: 87640},
+			{Kind: domain.TransactionKindRefund, OccurredAt: time.Date(2026, 5, 9, 10, 0, 0, 0, time.UTC), Currency: "INR", OrderID: &orderID,
+				SpecTitle: "Midnight Drive", LicenseType: "Basic", Gross: -100000, PlatformFee: -10000, ProviderFee: -2360, Net: -87640},
+			{Kind: domain.TransactionKindPayout, OccurredAt: time.Date(2026, 5, 20, 10, 0, 0, 0, time.UTC), Currency: "INR",
+				Reference: "UTR1", Net: -50000},
+		},
+		Balances: []domain.StatementBalance{{Currency: "INR", Opening: 120000, Closing: 70000}},
+	}
+}
+
+func TestWriteStatementCSV(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteStatementCSV(&buf, testStatement()))
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, rows, 6)
+	assert.Equal(t, "date", rows[0][0])
+	assert.Equal(t, []string{"2026-05-01", "opening_balance", "", "", "", "", "INR", "", "", "", "1200.00"}, rows[1])
+	assert.Equal(t, "sale", rows[2][1])
+	assert.Equal(t, "Midnight Drive", rows[2][3])
+	assert.Equal(t, "876.40", rows[2][10])
+	assert.Equal(t, "-876.40", rows[3][10])
+	assert.Equal(t, "UTR1", rows[4][5])
+	assert.Equal(t, []string{"2026-05-31", "closing_balance", "", "", "", "", "INR", "", "", "", "700.00"}, rows[5])
+}
+
+func TestWriteStatementPDF(t *testing.T) {
+	statement := testStatement()
+	// Enough lines to need a second page.
+	for i := 0; i < 60; i++ {
+		statement.Lines = append(statement.Lines, statement.Lines[0])
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteStatementPDF(&buf, statement))
+	output := buf.String()
+	assert.True(t, bytes.HasPrefix(buf.Bytes(), []byte("%PDF-")))
+	assert.Contains(t, output, "/Count 2")
+	assert.Contains(t, output, "(Closing balance)")
+	assert.Contains(t, output, "(INR 700.00)")
+
+	buf.Reset()
+	require.NoError(t, WriteStatementPDF(&buf, &Statement{Month: "2026-05", From: statement.From, To: statement.To}))
+	assert.Contains(t, buf.String(), "No earnings activity")
+}
+
+func TestFormatMoney(t *testing.T) {
+	assert.Equal(t, "INR 12.50", formatMoney(1250, "inr"))
+	assert.Equal(t, "USD -0.05", formatMoney(-5, "USD"))
+	assert.Equal(t, "0.00", formatDecimal(0))
+}
@@ -0,0 +1,183 @@
+package application
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/saransh1220/blueprint-audio/internal/modules/earnings/domain"
+	"github.com/saransh1220/blueprint-audio/internal/shared/pdf"
+)
+
+// WriteStatementCSV writes one row per statement line, framed by the opening
+// and closing balance of each currency. Amounts are decimal major units.
+func WriteStatementCSV(w io.Writer, statement *Statement) error {
+	out := csv.NewWriter(w)
+	_ = out.Write([]string{"date", "type", "order_id", "spec_title", "license_type", "reference", "currency", "gross", "platform_fee", "provider_fee", "net"})
+
+	lastDay := statement.To.AddDate(0, 0, -1).Format("2006-01-02")
+	for _, balance := range statement.Balances {
+		_ = out.Write([]string{statement.From.Format("2006-01-02"), "opening_balance", "", "", "", "", balance.Currency, "", "", "", formatDecimal(balance.Opening)})
+		for _, line := range statement.Lines {
+			if line.Currency != balance.Currency {
+				continue
+			}
+			orderID := ""
+			if line.OrderID != nil {
+				orderID = line.OrderID.String()
+			}
+			_ = out.Write([]string{
+				line.OccurredAt.UTC().Format("2006-01-02"), string(line.Kind), orderID,
+				line.SpecTitle, line.LicenseType, line.Reference, line.Currency,
+				formatDecimal(line.Gross), formatDecimal(line.PlatformFee),
+				formatDecimal(line.ProviderFee), formatDecimal(line.Net),
+			})
+		}
+		_ = out.Write([]string{lastDay, "closing_balance", "", "", "", "", balance.Currency, "", "", "", formatDecimal(balance.Closing)})
+	}
+	out.Flush()
+	return out.Error()
+}
+
+// Column layout of the PDF statement, in points.
+const (
+	statementMargin      = 40.0
+	statementBottom      = 800.0
+	statementDescX       = 100.0
+	statementDescWidth   = 210.0
+	statementGrossX      = 375.0
+	statementPlatformX   = 440.0
+	statementProviderX   = 500.0
+	statementNetX        = pdf.PageWidth - statementMargin
+	statementRowHeight   = 15.0
+	statementFontSize    = 9.0
+	statementHeadingSize = 11.0
+)
+
+// WriteStatementPDF renders the statement as an A4 PDF with one table per
+// currency.
+func WriteStatementPDF(w io.Writer, statement *Statement) error {
+	doc := pdf.New("Earnings statement " + statement.Month)
+	page := doc.AddPage()
+	y := 60.0
+
+	page.Text(statementMargin, y, pdf.Bold, 18, "Earnings statement")
+	y += 24
+	producer := statement.ProducerName
+	if statement.ProducerEmail != "" {
+		producer += " (" + statement.ProducerEmail + ")"
+	}
+	page.Text(statementMargin, y, pdf.Regular, 10, "Producer: "+strings.TrimSpace(producer))
+	y += 14
+	page.Text(statementMargin, y, pdf.Regular, 10, fmt.Sprintf("Period: %s to %s (UTC)",
+		statement.From.Format("2 Jan 2006"), statement.To.AddDate(0, 0, -1).Format("2 Jan 2006")))
+	y += 14
+	page.Text(statementMargin, y, pdf.Regular, 10, "Generated: "+statement.GeneratedAt.UTC().Format("2 Jan 2006 15:04 MST"))
+	y += 28
+
+	if len(statement.Balances) == 0 {
+		page.Text(statementMargin, y, pdf.Regular, 10, "No earnings activity up to the end of this period.")
+	}
+
+	// ensureSpace starts a new page when the next rows would not fit.
+	ensureSpace := func(rows int) {
+		if y+float64(rows)*statementRowHeight > statementBottom {
+			page = doc.AddPage()
+			y = 60
+		}
+	}
+	tableHeader := func() {
+		ensureSpace(2)
+		page.Text(statementMargin, y, pdf.Bold, statementFontSize, "Date")
+		page.Text(statementDescX, y, pdf.Bold, statementFontSize, "Description")
+		page.TextRight(statementGrossX, y, pdf.Bold, statementFontSize, "Gross")
+		page.TextRight(statementPlatformX, y, pdf.Bold, statementFontSize, "Platform fee")
+		page.TextRight(statementProviderX, y, pdf.Bold, statementFontSize, "Provider fee")
+		page.TextRight(statementNetX, y, pdf.Bold, statementFontSize, "Net")
+		page.Line(statementMargin, y+4, statementNetX, y+4)
+		y += statementRowHeight
+	}
+
+	for _, balance := range statement.Balances {
+		ensureSpace(4)
+		page.Text(statementMargin, y, pdf.Bold, statementHeadingSize, balance.Currency)
+		y += statementRowHeight
+		page.Text(statementMargin, y, pdf.Regular, statementFontSize, "Opening balance")
+		page.TextRight(statementNetX, y, pdf.Regular, statementFontSize, formatMoney(balance.Opening, balance.Currency))
+		y += statementRowHeight + 4
+		tableHeader()
+
+		var gross, platformFee, providerFee, net int
+		for _, line := range statement.Lines {
+			if line.Currency != balance.Currency {
+				continue
+			}
+			if y+statementRowHeight > statementBottom {
+				page = doc.AddPage()
+				y = 60
+				tableHeader()
+			}
+			page.Text(statementMargin, y, pdf.Regular, statementFontSize, line.OccurredAt.UTC().Format("02 Jan"))
+			page.Text(statementDescX, y, pdf.Regular, statementFontSize,
+				pdf.Truncate(pdf.Regular, statementFontSize, statementDescWidth, describeLine(line)))
+			if line.Kind != domain.TransactionKindPayout {
+				page.TextRight(statementGrossX, y, pdf.Regular, statementFontSize, formatDecimal(line.Gross))
+				page.TextRight(statementPlatformX, y, pdf.Regular, statementFontSize, formatDecimal(line.PlatformFee))
+				page.TextRight(statementProviderX, y, pdf.Regular, statementFontSize, formatDecimal(line.ProviderFee))
+			}
+			page.TextRight(statementNetX, y, pdf.Regular, statementFontSize, formatDecimal(line.Net))
+			y += statementRowHeight
+
+			gross += line.Gross
+			platformFee += line.PlatformFee
+			providerFee += line.ProviderFee
+			net += line.Net
+		}
+
+		ensureSpace(3)
+		page.Line(statementMargin, y-statementRowHeight+4, statementNetX, y-statementRowHeight+4)
+		page.Text(statementMargin, y, pdf.Bold, statementFontSize, "Total")
+		page.TextRight(statementGrossX, y, pdf.Bold, statementFontSize, formatDecimal(gross))
+		page.TextRight(statementPlatformX, y, pdf.Bold, statementFontSize, formatDecimal(platformFee))
+		page.TextRight(statementProviderX, y, pdf.Bold, statementFontSize, formatDecimal(providerFee))
+		page.TextRight(statementNetX, y, pdf.Bold, statementFontSize, formatDecimal(net))
+		y += statementRowHeight
+		page.Text(statementMargin, y, pdf.Bold, statementFontSize, "Closing balance")
+		page.TextRight(statementNetX, y, pdf.Bold, statementFontSize, formatMoney(balance.Closing, balance.Currency))
+		y += statementRowHeight * 2
+	}
+
+	_, err := doc.WriteTo(w)
+	return err
+}
+
+func describeLine(line domain.StatementLine) string {
+	switch line.Kind {
+	case domain.TransactionKindPayout:
+		if line.Reference != "" {
+			return "Payout, ref " + line.Reference
+		}
+		return "Payout"
+	case domain.TransactionKindRefund:
+		return fmt.Sprintf("Refund: %s (%s)", line.SpecTitle, line.LicenseType)
+	default:
+		return fmt.Sprintf("Sale: %s (%s)", line.SpecTitle, line.LicenseType)
+	}
+}
+
+// formatDecimal formats minor units as a decimal amount, e.g. -1250 as
+// "-12.50". Both supported currencies have two decimal places.
+func formatDecimal(minor int) string {
+	sign := ""
+	if minor < 0 {
+		sign = "-"
+		minor = -minor
+	}
+	return sign + strconv.Itoa(minor/100) + "." + fmt.Sprintf("%02d", minor%100)
+}
+
+func formatMoney(minor int, currency string) string {
+	return strings.ToUpper(currency) + " " + formatDecimal(minor)
+}
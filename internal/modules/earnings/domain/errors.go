@@ -0,0 +1,13 @@
+package domain
+
+import "errors"
+
+var (
+	ErrSaleNotFound          = errors.New("sale not found")
+	ErrTransactionNotFound   = errors.New("ledger transaction not found")
+	ErrAlreadyPosted         = errors.New("order already posted to the ledger")
+	ErrUnbalancedTransaction = errors.New("ledger transaction does not balance")
+	ErrInsufficientBalance   = errors.New("payout exceeds the available balance")
+	ErrInvalidPayout         = errors.New("invalid payout")
+	ErrInvalidStatementMonth = errors.New("invalid statement month")
+)
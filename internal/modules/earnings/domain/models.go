@@ -0,0 +1,213 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Account is a ledger account. Debits are positive amounts and credits
+// negative, so the entries of a transaction always sum to zero.
+type Account string
+
+const (
+	// AccountGateway is what buyers paid through the payment providers, less
+	// what has been paid out to producers.
+	AccountGateway Account = "gateway"
+	// AccountProviderFees is what the payment providers deducted from sales
+	// before settling them. Its credit balance offsets the gateway's.
+	AccountProviderFees Account = "provider_fees"
+	// AccountPlatformRevenue is the platform's commission on sales.
+	AccountPlatformRevenue Account = "platform_revenue"
+	// AccountProducerPayable is what the platform owes producers. A credit
+	// balance is money the producer has earned and not been paid yet.
+	AccountProducerPayable Account = "producer_payable"
+)
+
+type TransactionKind string
+
+const (
+	TransactionKindSale   TransactionKind = "sale"
+	TransactionKindRefund TransactionKind = "refund"
+	TransactionKindPayout TransactionKind = "payout"
+)
+
+// Transaction is one balanced posting to the ledger: a paid order, the
+// reversal of one of its refunds, or a payout. OccurredAt is when the
+// underlying event happened, which statements are grouped by.
+type Transaction struct {
+	ID         uuid.UUID       `json:"id" db:"id"`
+	Kind       TransactionKind `json:"kind" db:"kind"`
+	OrderID    *uuid.UUID      `json:"order_id,omitempty" db:"order_id"`
+	PayoutID   *uuid.UUID      `json:"payout_id,omitempty" db:"payout_id"`
+	RefundID   *uuid.UUID      `json:"refund_id,omitempty" db:"refund_id"`
+	Currency   string          `json:"currency" db:"currency"`
+	OccurredAt time.Time       `json:"occurred_at" db:"occurred_at"`
+	CreatedAt  time.Time       `json:"created_at" db:"created_at"`
+	Entries    []Entry         `json:"entries" db:"-"`
+}
+
+// Entry moves Amount into (positive) or out of (negative) an account on behalf
+// of a producer. Producer payable entries only count towards the producer's
+// available balance from AvailableAt on.
+type Entry struct {
+	ID            uuid.UUID  `json:"id" db:"id"`
+	TransactionID uuid.UUID  `json:"transaction_id" db:"transaction_id"`
+	Account       Account    `json:"account" db:"account"`
+	ProducerID    uuid.UUID  `json:"producer_id" db:"producer_id"`
+	OrderItemID   *uuid.UUID `json:"order_item_id,omitempty" db:"order_item_id"`
+	Amount        int        `json:"amount" db:"amount"`
+	Currency      string     `json:"currency" db:"currency"`
+	AvailableAt   time.Time  `json:"available_at" db:"available_at"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+}
+
+// Validate checks the transaction is balanced and in a single currency.
+func (t *Transaction) Validate() error {
+	if len(t.Entries) == 0 {
+		return ErrUnbalancedTransaction
+	}
+	sum := 0
+	for _, entry := range t.Entries {
+		if entry.Amount == 0 || entry.Currency != t.Currency {
+			return ErrUnbalancedTransaction
+		}
+		sum += entry.Amount
+	}
+	if sum != 0 {
+		return ErrUnbalancedTransaction
+	}
+	return nil
+}
+
+// Sale is a paid order as the ledger sees it: what each producer's items sold
+// for, what the provider kept and the refunds and chargebacks recorded
+// against it, oldest first.
+type Sale struct {
+	OrderID     uuid.UUID    `db:"order_id"`
+	Status      string       `db:"status"`
+	Currency    string       `db:"currency"`
+	Amount      int          `db:"amount"`
+	ProviderFee int          `db:"provider_fee"`
+	PaidAt      time.Time    `db:"paid_at"`
+	Lines       []SaleLine   `db:"-"`
+	Refunds     []SaleRefund `db:"-"`
+}
+
+type SaleLine struct {
+	OrderItemID uuid.UUID `db:"order_item_id"`
+	ProducerID  uuid.UUID `db:"producer_id"`
+	Amount      int       `db:"amount"`
+}
+
+// SaleRefund is one refund or chargeback of a sale, in minor units of the
+// sale currency.
+type SaleRefund struct {
+	ID        uuid.UUID `db:"id"`
+	Amount    int       `db:"amount"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// UnpostedOrder is a paid or refunded order whose ledger postings are missing.
+// Refunded is set when it has refunds that have not been posted yet.
+type UnpostedOrder struct {
+	OrderID  uuid.UUID `db:"order_id"`
+	Status   string    `db:"status"`
+	Refunded bool      `db:"refunded"`
+}
+
+// Balance is a producer's position in one currency, in minor units.
+// Pending earnings are still inside the clearance period; Available can be
+// paid out, and goes negative if a sale is refunded after its payout.
+type Balance struct {
+	Currency     string `json:"currency" db:"currency"`
+	Pending      int    `json:"pending" db:"pending"`
+	Available    int    `json:"available" db:"available"`
+	PaidOut      int    `json:"paid_out" db:"paid_out"`
+	GrossSales   int    `json:"gross_sales" db:"gross_sales"`
+	PlatformFees int    `json:"platform_fees" db:"platform_fees"`
+	ProviderFees int    `json:"provider_fees" db:"provider_fees"`
+	Refunds      int    `json:"refunds" db:"refunds"`
+}
+
+// Payout is money sent to a producer outside the platform, recorded by an
+// admin. Amount is in minor units of Currency.
+type Payout struct {
+	ID         uuid.UUID  `json:"id" db:"id"`
+	ProducerID uuid.UUID  `json:"producer_id" db:"producer_id"`
+	Amount     int        `json:"amount" db:"amount"`
+	Currency   string     `json:"currency" db:"currency"`
+	Method     string     `json:"method" db:"method"`
+	Reference  *string    `json:"reference,omitempty" db:"reference"`
+	Note       *string    `json:"note,omitempty" db:"note"`
+	CreatedBy  *uuid.UUID `json:"created_by,omitempty" db:"created_by"`
+	PaidAt     time.Time  `json:"paid_at" db:"paid_at"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+}
+
+// PayoutAudit carries the request details written to admin_audit_logs with a
+// payout.
+type PayoutAudit struct {
+	ActorID   uuid.UUID
+	IPAddress string
+	UserAgent string
+}
+
+// StatementLine is one sale item, refund or payout on a producer's statement.
+// Gross is what the buyer paid for the item and Net what the producer keeps;
+// refunds and payouts have negative amounts.
+type StatementLine struct {
+	TransactionID uuid.UUID       `json:"transaction_id" db:"transaction_id"`
+	Kind          TransactionKind `json:"kind" db:"kind"`
+	OccurredAt    time.Time       `json:"occurred_at" db:"occurred_at"`
+	Currency      string          `json:"currency" db:"currency"`
+	OrderID       *uuid.UUID      `json:"order_id,omitempty" db:"order_id"`
+	SpecTitle     string          `json:"spec_title" db:"spec_title"`
+	LicenseType   string          `json:"license_type" db:"license_type"`
+	Reference     string          `json:"reference" db:"reference"`
+	Gross         int             `json:"gross" db:"gross"`
+	PlatformFee   int             `json:"platform_fee" db:"platform_fee"`
+	ProviderFee   int             `json:"provider_fee" db:"provider_fee"`
+	Net           int             `json:"net" db:"net"`
+}
+
+// StatementBalance is what the platform owed the producer in one currency at
+// the start and end of a statement period, cleared or not.
+type StatementBalance struct {
+	Currency string `json:"currency" db:"currency"`
+	Opening  int    `json:"opening" db:"opening"`
+	Closing  int    `json:"closing" db:"closing"`
+}
+
+// Repositories
+
+type LedgerRepository interface {
+	// GetSale loads a paid or refunded order with its items and producers.
+	// Other orders return ErrSaleNotFound.
+	GetSale(ctx context.Context, orderID uuid.UUID) (*Sale, error)
+	// GetOrderTransaction returns the order's posting of kind with its
+	// entries, or ErrTransactionNotFound. An order can have several refund
+	// postings, so those are read with ListRefundTransactions.
+	GetOrderTransaction(ctx context.Context, orderID uuid.UUID, kind TransactionKind) (*Transaction, error)
+	// ListRefundTransactions returns the order's refund postings with their
+	// entries, oldest first.
+	ListRefundTransactions(ctx context.Context, orderID uuid.UUID) ([]Transaction, error)
+	// Post stores a sale or refund transaction with its entries. An order is
+	// posted as a sale at most once and each of its refunds at most once; a
+	// second posting returns ErrAlreadyPosted.
+	Post(ctx context.Context, transaction *Transaction) error
+	ListUnposted(ctx context.Context, limit int) ([]UnpostedOrder, error)
+	GetBalances(ctx context.Context, producerID uuid.UUID) ([]Balance, error)
+	GetStatement(ctx context.Context, producerID uuid.UUID, from, to time.Time) ([]StatementLine, []StatementBalance, error)
+}
+
+type PayoutRepository interface {
+	// Create records the payout, posts transaction for it and writes the audit
+	// entry in one transaction, provided the producer's available balance in
+	// the payout currency covers it; otherwise it returns
+	// ErrInsufficientBalance.
+	Create(ctx context.Context, payout *Payout, transaction *Transaction, audit PayoutAudit) error
+	// List returns payouts newest first, all of them or one producer's.
+	List(ctx context.Context, producerID *uuid.UUID, limit, offset int) ([]Payout, int, error)
+}
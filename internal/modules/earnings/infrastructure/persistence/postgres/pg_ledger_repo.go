@@ -0,0 +1,262 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/saransh1220/blueprint-audio/internal/modules/earnings/domain"
+)
+
+const insertEntryQuery = `
+	INSERT INTO ledger_entries (
+		id, transaction_id, account, producer_id, order_item_id,
+		amount, currency, available_at, created_at
+	) VALUES (
+		:id, :transaction_id, :account, :producer_id, :order_item_id,
+		:amount, :currency, :available_at, :created_at
+	)`
+
+type PgLedgerRepository struct {
+	db *sqlx.DB
+}
+
+func NewLedgerRepository(db *sqlx.DB) domain.LedgerRepository {
+	return &PgLedgerRepository{db: db}
+}
+
+func (r *PgLedgerRepository) GetSale(ctx context.Context, orderID uuid.UUID) (*domain.Sale, error) {
+	sale := &domain.Sale{}
+	query := `
+		SELECT o.id AS order_id, o.status, o.currency, o.amount,
+		       COALESCE(p.provider_fee, 0) AS provider_fee,
+		       COALESCE(p.captured_at, p.created_at, o.updated_at) AS paid_at
+		FROM orders o
+		LEFT JOIN LATERAL (
+			SELECT provider_fee, captured_at, created_at
+			FROM payments
+			WHERE order_id = o.id
+			ORDER BY captured_at DESC NULLS LAST
+			LIMIT 1
+		) p ON TRUE
+		WHERE o.id = $1 AND o.status IN ('paid', 'refunded')`
+	if err := r.db.GetContext(ctx, sale, query, orderID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrSaleNotFound
+		}
+		return nil, err
+	}
+
+	linesQuery := `
+		SELECT oi.id AS order_item_id, s.producer_id, oi.amount
+		FROM order_items oi
+		JOIN specs s ON s.id = oi.spec_id
+		WHERE oi.order_id = $1
+		ORDER BY oi.created_at, oi.id`
+	if err := r.db.SelectContext(ctx, &sale.Lines, linesQuery, orderID); err != nil {
+		return nil, err
+	}
+
+	refundsQuery := `
+		SELECT id, amount, created_at
+		FROM order_refunds
+		WHERE order_id = $1
+		ORDER BY created_at, id`
+	if err := r.db.SelectContext(ctx, &sale.Refunds, refundsQuery, orderID); err != nil {
+		return nil, err
+	}
+	return sale, nil
+}
+
+func (r *PgLedgerRepository) GetOrderTransaction(ctx context.Context, orderID uuid.UUID, kind domain.TransactionKind) (*domain.Transaction, error) {
+	transaction := &domain.Transaction{}
+	err := r.db.GetContext(ctx, transaction,
+		`SELECT * FROM ledger_transactions WHERE order_id = $1 AND kind = $2`, orderID, kind)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrTransactionNotFound
+		}
+		return nil, err
+	}
+	if err := r.db.SelectContext(ctx, &transaction.Entries,
+		`SELECT * FROM ledger_entries WHERE transaction_id = $1 ORDER BY id`, transaction.ID); err != nil {
+		return nil, err
+	}
+	return transaction, nil
+}
+
+func (r *PgLedgerRepository) ListRefundTransactions(ctx context.Context, orderID uuid.UUID) ([]domain.Transaction, error) {
+	var transactions []domain.Transaction
+	if err := r.db.SelectContext(ctx, &transactions, `
+		SELECT * FROM ledger_transactions
+		WHERE order_id = $1 AND kind = $2
+		ORDER BY occurred_at, id`, orderID, domain.TransactionKindRefund); err != nil {
+		return nil, err
+	}
+	for i := range transactions {
+		if err := r.db.SelectContext(ctx, &transactions[i].Entries,
+			`SELECT * FROM ledger_entries WHERE transaction_id = $1 ORDER BY id`, transactions[i].ID); err != nil {
+			return nil, err
+		}
+	}
+	return transactions, nil
+}
+
+func (r *PgLedgerRepository) Post(ctx context.Context, transaction *domain.Transaction) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := insertTransaction(ctx, tx, transaction); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// insertTransaction writes a balanced transaction and its entries inside tx.
+// The unique indexes on sale orders and refund ids make concurrent postings of
+// the same sale or refund wait for each other; the later one gets
+// ErrAlreadyPosted.
+func insertTransaction(ctx context.Context, tx *sqlx.Tx, transaction *domain.Transaction) error {
+	if err := transaction.Validate(); err != nil {
+		return err
+	}
+	now := time.Now()
+	if transaction.ID == uuid.Nil {
+		id, err := uuid.NewV7()
+		if err != nil {
+			return err
+		}
+		transaction.ID = id
+	}
+	transaction.CreatedAt = now
+
+	result, err := tx.NamedExecContext(ctx, `
+		INSERT INTO ledger_transactions (id, kind, order_id, payout_id, refund_id, currency, occurred_at, created_at)
+		VALUES (:id, :kind, :order_id, :payout_id, :refund_id, :currency, :occurred_at, :created_at)
+		ON CONFLICT DO NOTHING`, transaction)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return domain.ErrAlreadyPosted
+	}
+
+	for i := range transaction.Entries {
+		entry := &transaction.Entries[i]
+		id, err := uuid.NewV7()
+		if err != nil {
+			return err
+		}
+		entry.ID, entry.TransactionID, entry.CreatedAt = id, transaction.ID, now
+		if _, err := tx.NamedExecContext(ctx, insertEntryQuery, entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListUnposted finds paid orders without a sale posting and orders with
+// refunds after their latest refund posting, oldest first. Refunds are posted
+// in order, so earlier ones count as posted. A zero amount refund only needs a
+// posting when it completed the order's refund.
+func (r *PgLedgerRepository) ListUnposted(ctx context.Context, limit int) ([]domain.UnpostedOrder, error) {
+	var orders []domain.UnpostedOrder
+	query := `
+		SELECT order_id, status, refunded
+		FROM (
+			SELECT o.id AS order_id, o.status, o.updated_at,
+			       NOT EXISTS (
+			           SELECT 1 FROM ledger_transactions t
+			           WHERE t.order_id = o.id AND t.kind = 'sale') AS unposted_sale,
+			       EXISTS (
+			           SELECT 1 FROM order_refunds r
+			           WHERE r.order_id = o.id
+			             AND (r.amount > 0 OR o.status = 'refunded')
+			             AND NOT EXISTS (
+			                 SELECT 1 FROM ledger_transactions t
+			                 JOIN order_refunds pr ON pr.id = t.refund_id
+			                 WHERE t.order_id = o.id AND (pr.created_at, pr.id) >= (r.created_at, r.id))) AS refunded
+			FROM orders o
+			WHERE o.status IN ('paid', 'refunded')
+			  AND EXISTS (SELECT 1 FROM order_items oi WHERE oi.order_id = o.id)
+		) unposted
+		WHERE unposted_sale OR refunded
+		ORDER BY updated_at, order_id
+		LIMIT $1`
+	if err := r.db.SelectContext(ctx, &orders, query, limit); err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+func (r *PgLedgerRepository) GetBalances(ctx context.Context, producerID uuid.UUID) ([]domain.Balance, error) {
+	balances := []domain.Balance{}
+	query := `
+		SELECT e.currency,
+		       COALESCE(-SUM(e.amount) FILTER (WHERE e.account = 'producer_payable' AND e.available_at > NOW()), 0) AS pending,
+		       COALESCE(-SUM(e.amount) FILTER (WHERE e.account = 'producer_payable' AND e.available_at <= NOW()), 0) AS available,
+		       COALESCE(SUM(e.amount) FILTER (WHERE e.account = 'producer_payable' AND t.kind = 'payout'), 0) AS paid_out,
+		       COALESCE(SUM(e.amount) FILTER (WHERE e.account = 'gateway' AND t.kind = 'sale'), 0) AS gross_sales,
+		       COALESCE(-SUM(e.amount) FILTER (WHERE e.account = 'platform_revenue' AND t.kind = 'sale'), 0) AS platform_fees,
+		       COALESCE(-SUM(e.amount) FILTER (WHERE e.account = 'provider_fees' AND t.kind = 'sale'), 0) AS provider_fees,
+		       COALESCE(SUM(e.amount) FILTER (WHERE e.account = 'producer_payable' AND t.kind = 'refund'), 0) AS refunds
+		FROM ledger_entries e
+		JOIN ledger_transactions t ON t.id = e.transaction_id
+		WHERE e.producer_id = $1
+		GROUP BY e.currency
+		ORDER BY e.currency`
+	if err := r.db.SelectContext(ctx, &balances, query, producerID); err != nil {
+		return nil, err
+	}
+	return balances, nil
+}
+
+// GetStatement returns one line per sale item, refunded item and payout that
+// occurred in [from, to), and the producer's balances at both ends.
+func (r *PgLedgerRepository) GetStatement(ctx context.Context, producerID uuid.UUID, from, to time.Time) ([]domain.StatementLine, []domain.StatementBalance, error) {
+	lines := []domain.StatementLine{}
+	linesQuery := `
+		SELECT t.id AS transaction_id, t.kind, t.occurred_at, t.currency, t.order_id,
+		       COALESCE(oi.spec_title, '') AS spec_title,
+		       COALESCE(oi.license_type, '') AS license_type,
+		       COALESCE(p.reference, '') AS reference,
+		       COALESCE(SUM(e.amount) FILTER (WHERE e.account = 'gateway' AND t.kind <> 'payout'), 0) AS gross,
+		       COALESCE(-SUM(e.amount) FILTER (WHERE e.account = 'platform_revenue'), 0) AS platform_fee,
+		       COALESCE(-SUM(e.amount) FILTER (WHERE e.account = 'provider_fees'), 0) AS provider_fee,
+		       COALESCE(-SUM(e.amount) FILTER (WHERE e.account = 'producer_payable'), 0) AS net
+		FROM ledger_entries e
+		JOIN ledger_transactions t ON t.id = e.transaction_id
+		LEFT JOIN order_items oi ON oi.id = e.order_item_id
+		LEFT JOIN payouts p ON p.id = t.payout_id
+		WHERE e.producer_id = $1 AND t.occurred_at >= $2 AND t.occurred_at < $3
+		GROUP BY t.id, e.order_item_id, oi.spec_title, oi.license_type, p.reference
+		ORDER BY t.occurred_at, t.id, oi.spec_title`
+	if err := r.db.SelectContext(ctx, &lines, linesQuery, producerID, from, to); err != nil {
+		return nil, nil, err
+	}
+
+	balances := []domain.StatementBalance{}
+	balancesQuery := `
+		SELECT e.currency,
+		       COALESCE(-SUM(e.amount) FILTER (WHERE t.occurred_at < $2), 0) AS opening,
+		       COALESCE(-SUM(e.amount), 0) AS closing
+		FROM ledger_entries e
+		JOIN ledger_transactions t ON t.id = e.transaction_id
+		WHERE e.producer_id = $1 AND e.account = 'producer_payable' AND t.occurred_at < $3
+		GROUP BY e.currency
+		ORDER BY e.currency`
+	if err := r.db.SelectContext(ctx, &balances, balancesQuery, producerID, from, to); err != nil {
+		return nil, nil, err
+	}
+	return lines, balances, nil
+}
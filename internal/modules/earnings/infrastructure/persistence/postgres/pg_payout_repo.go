@@ -0,0 +1,120 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/saransh1220/blueprint-audio/internal/modules/earnings/domain"
+)
+
+type PgPayoutRepository struct {
+	db *sqlx.DB
+}
+
+func NewPayoutRepository(db *sqlx.DB) domain.PayoutRepository {
+	return &PgPayoutRepository{db: db}
+}
+
+// Create serialises payouts per producer with an advisory lock, so two admins
+// recording payouts at once cannot both spend the same available balance.
+func (r *PgPayoutRepository) Create(ctx context.Context, payout *domain.Payout, transaction *domain.Transaction, audit domain.PayoutAudit) error {
+	if payout.ID == uuid.Nil {
+		id, err := uuid.NewV7()
+		if err != nil {
+			return err
+		}
+		payout.ID = id
+	}
+	payout.CreatedAt = time.Now()
+	transaction.PayoutID = &payout.ID
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext($1))`, "payouts:"+payout.ProducerID.String()); err != nil {
+		return err
+	}
+
+	var available int
+	if err := tx.GetContext(ctx, &available, `
+		SELECT COALESCE(-SUM(amount), 0)
+		FROM ledger_entries
+		WHERE producer_id = $1 AND currency = $2
+		  AND account = 'producer_payable' AND available_at <= NOW()`,
+		payout.ProducerID, payout.Currency,
+	); err != nil {
+		return err
+	}
+	if payout.Amount > available {
+		return domain.ErrInsufficientBalance
+	}
+
+	if _, err := tx.NamedExecContext(ctx, `
+		INSERT INTO payouts (
+			id, producer_id, amount, currency, method, reference, note,
+			created_by, paid_at, created_at
+		) VALUES (
+			:id, :producer_id, :amount, :currency, :method, :reference, :note,
+			:created_by, :paid_at, :created_at
+		)`, payout); err != nil {
+		return err
+	}
+
+	if err := insertTransaction(ctx, tx, transaction); err != nil {
+		return err
+	}
+
+	afterJSON, _ := json.Marshal(map[string]any{
+		"producer_id": payout.ProducerID,
+		"amount":      payout.Amount,
+		"currency":    payout.Currency,
+		"method":      payout.Method,
+		"reference":   payout.Reference,
+		"paid_at":     payout.PaidAt,
+	})
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO admin_audit_logs (actor_id, action, resource_type, resource_id, after_state, ip_address, user_agent)
+		VALUES ($1, 'payouts.create', 'payout', $2, $3, $4, $5)`,
+		audit.ActorID, payout.ID, afterJSON, nullIfEmpty(audit.IPAddress), nullIfEmpty(audit.UserAgent),
+	); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *PgPayoutRepository) List(ctx context.Context, producerID *uuid.UUID, limit, offset int) ([]domain.Payout, int, error) {
+	where := ""
+	args := []any{}
+	if producerID != nil {
+		where = ` WHERE producer_id = $1`
+		args = append(args, *producerID)
+	}
+
+	var total int
+	if err := r.db.GetContext(ctx, &total, `SELECT COUNT(*) FROM payouts`+where, args...); err != nil {
+		return nil, 0, err
+	}
+
+	payouts := []domain.Payout{}
+	query := `SELECT * FROM payouts` + where + ` ORDER BY paid_at DESC, id DESC` +
+		` LIMIT $` + strconv.Itoa(len(args)+1) + ` OFFSET $` + strconv.Itoa(len(args)+2)
+	if err := r.db.SelectContext(ctx, &payouts, query, append(args, limit, offset)...); err != nil {
+		return nil, 0, err
+	}
+	return payouts, total, nil
+}
+
+func nullIfEmpty(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
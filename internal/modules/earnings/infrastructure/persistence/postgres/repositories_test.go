@@ -0,0 +1,230 @@
+package postgres_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/saransh1220/blueprint-audio/internal/modules/earnings/domain"
+	"github.com/saransh1220/blueprint-audio/internal/modules/earnings/infrastructure/persistence/postgres"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newMockDB(t *testing.T) (*sqlx.DB, sqlmock.Sqlmock, func()) {
+	t.Helper()
+	sqlDB, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherRegexp))
+	require.NoError(t, err)
+	return sqlx.NewDb(sqlDB, "sqlmock"), mock, func() { _ = sqlDB.Close() }
+}
+
+func saleTransaction(orderID, producerID uuid.UUID) *domain.Transaction {
+	now := time.Now()
+	return &domain.Transaction{
+		Kind:       domain.TransactionKindSale,
+		OrderID:    &orderID,
+		Currency:   "INR",
+		OccurredAt: now,
+		Entries: []domain.Entry{
+			{Account: domain.AccountGateway, ProducerID: producerID, Amount: 1000, Currency: "INR", AvailableAt: now},
+			{Account: domain.AccountPlatformRevenue, ProducerID: producerID, Amount: -100, Currency: "INR", AvailableAt: now},
+			{Account: domain.AccountProducerPayable, ProducerID: producerID, Amount: -900, Currency: "INR", AvailableAt: now},
+		},
+	}
+}
+
+func TestPgLedgerRepository_Post(t *testing.T) {
+	db, mock, cleanup := newMockDB(t)
+	defer cleanup()
+	repo := postgres.NewLedgerRepository(db)
+	ctx := context.Background()
+	orderID, producerID := uuid.New(), uuid.New()
+
+	transaction := saleTransaction(orderID, producerID)
+	mock.ExpectBegin()
+	mock.ExpectExec(`INSERT INTO ledger_transactions .* refund_id.* ON CONFLICT DO NOTHING`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO ledger_entries`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO ledger_entries`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO ledger_entries`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	require.NoError(t, repo.Post(ctx, transaction))
+	assert.NotEqual(t, uuid.Nil, transaction.ID)
+	for _, entry := range transaction.Entries {
+		assert.Equal(t, transaction.ID, entry.TransactionID)
+		assert.NotEqual(t, uuid.Nil, entry.ID)
+	}
+
+	// The order was posted by someone else first.
+	mock.ExpectBegin()
+	mock.ExpectExec(`INSERT INTO ledger_transactions`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+	assert.ErrorIs(t, repo.Post(ctx, saleTransaction(orderID, producerID)), domain.ErrAlreadyPosted)
+
+	// Unbalanced transactions never reach the database.
+	unbalanced := saleTransaction(orderID, producerID)
+	unbalanced.Entries[0].Amount = 999
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+	assert.ErrorIs(t, repo.Post(ctx, unbalanced), domain.ErrUnbalancedTransaction)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPgLedgerRepository_GetSale(t *testing.T) {
+	db, mock, cleanup := newMockDB(t)
+	defer cleanup()
+	repo := postgres.NewLedgerRepository(db)
+	ctx := context.Background()
+	orderID, itemID, producerID, refundID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	paidAt := time.Now()
+
+	mock.ExpectQuery(`FROM orders o .* WHERE o\.id = \$1 AND o\.status IN \('paid', 'refunded'\)`).WithArgs(orderID).
+		WillReturnRows(sqlmock.NewRows([]string{"order_id", "status", "currency", "amount", "provider_fee", "paid_at"}).
+			AddRow(orderID, "paid", "INR", 2000, 40, paidAt))
+	mock.ExpectQuery(`FROM order_items oi JOIN specs s`).WithArgs(orderID).
+		WillReturnRows(sqlmock.NewRows([]string{"order_item_id", "producer_id", "amount"}).AddRow(itemID, producerID, 2000))
+	mock.ExpectQuery(`FROM order_refunds WHERE order_id = \$1 ORDER BY created_at, id`).WithArgs(orderID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "amount", "created_at"}).
+			AddRow(refundID, 500, paidAt.Add(time.Hour)).
+			AddRow(uuid.New(), 700, paidAt.Add(2*time.Hour)))
+
+	sale, err := repo.GetSale(ctx, orderID)
+	require.NoError(t, err)
+	assert.Equal(t, 40, sale.ProviderFee)
+	require.Len(t, sale.Refunds, 2)
+	assert.Equal(t, refundID, sale.Refunds[0].ID)
+	assert.Equal(t, 500, sale.Refunds[0].Amount)
+	require.Len(t, sale.Lines, 1)
+	assert.Equal(t, producerID, sale.Lines[0].ProducerID)
+
+	mock.ExpectQuery(`FROM orders o`).WithArgs(orderID).WillReturnError(sql.ErrNoRows)
+	_, err = repo.GetSale(ctx, orderID)
+	assert.ErrorIs(t, err, domain.ErrSaleNotFound)
+
+	mock.ExpectQuery(`SELECT \* FROM ledger_transactions WHERE order_id = \$1 AND kind = \$2`).WithArgs(orderID, domain.TransactionKindSale).
+		WillReturnError(sql.ErrNoRows)
+	_, err = repo.GetOrderTransaction(ctx, orderID, domain.TransactionKindSale)
+	assert.ErrorIs(t, err, domain.ErrTransactionNotFound)
+
+	transactionID := uuid.New()
+	mock.ExpectQuery(`SELECT \* FROM ledger_transactions WHERE order_id = \$1 AND kind = \$2 ORDER BY occurred_at, id`).
+		WithArgs(orderID, domain.TransactionKindRefund).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "kind", "order_id", "refund_id", "currency"}).
+			AddRow(transactionID, "refund", orderID, refundID, "INR"))
+	mock.ExpectQuery(`SELECT \* FROM ledger_entries WHERE transaction_id = \$1`).WithArgs(transactionID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "account", "producer_id", "amount"}).
+			AddRow(uuid.New(), "producer_payable", producerID, 450))
+	reversals, err := repo.ListRefundTransactions(ctx, orderID)
+	require.NoError(t, err)
+	require.Len(t, reversals, 1)
+	assert.Equal(t, refundID, *reversals[0].RefundID)
+	require.Len(t, reversals[0].Entries, 1)
+	assert.Equal(t, 450, reversals[0].Entries[0].Amount)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPgLedgerRepository_BalancesAndUnposted(t *testing.T) {
+	db, mock, cleanup := newMockDB(t)
+	defer cleanup()
+	repo := postgres.NewLedgerRepository(db)
+	ctx := context.Background()
+	producerID, orderID := uuid.New(), uuid.New()
+
+	mock.ExpectQuery(`FROM ledger_entries e JOIN ledger_transactions t .* WHERE e\.producer_id = \$1 GROUP BY e\.currency`).WithArgs(producerID).
+		WillReturnRows(sqlmock.NewRows([]string{"currency", "pending", "available", "paid_out", "gross_sales", "platform_fees", "provider_fees", "refunds"}).
+			AddRow("INR", 900, 1800, 500, 3000, 300, 0, 0))
+	balances, err := repo.GetBalances(ctx, producerID)
+	require.NoError(t, err)
+	require.Len(t, balances, 1)
+	assert.Equal(t, 1800, balances[0].Available)
+	assert.Equal(t, 500, balances[0].PaidOut)
+
+	mock.ExpectQuery(`SELECT order_id, status, refunded FROM \(.* FROM orders o .*\) unposted WHERE unposted_sale OR refunded .* LIMIT \$1`).WithArgs(100).
+		WillReturnRows(sqlmock.NewRows([]string{"order_id", "status", "refunded"}).AddRow(orderID, "paid", true))
+	unposted, err := repo.ListUnposted(ctx, 100)
+	require.NoError(t, err)
+	require.Len(t, unposted, 1)
+	assert.Equal(t, "paid", unposted[0].Status)
+	assert.True(t, unposted[0].Refunded)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPgPayoutRepository_Create(t *testing.T) {
+	db, mock, cleanup := newMockDB(t)
+	defer cleanup()
+	repo := postgres.NewPayoutRepository(db)
+	ctx := context.Background()
+	producerID, actorID := uuid.New(), uuid.New()
+	now := time.Now()
+
+	newPayout := func() (*domain.Payout, *domain.Transaction) {
+		payout := &domain.Payout{ProducerID: producerID, Amount: 500, Currency: "INR", Method: "bank_transfer", CreatedBy: &actorID, PaidAt: now}
+		transaction := &domain.Transaction{
+			Kind:       domain.TransactionKindPayout,
+			Currency:   "INR",
+			OccurredAt: now,
+			Entries: []domain.Entry{
+				{Account: domain.AccountProducerPayable, ProducerID: producerID, Amount: 500, Currency: "INR", AvailableAt: now},
+				{Account: domain.AccountGateway, ProducerID: producerID, Amount: -500, Currency: "INR", AvailableAt: now},
+			},
+		}
+		return payout, transaction
+	}
+
+	payout, transaction := newPayout()
+	mock.ExpectBegin()
+	mock.ExpectExec(`SELECT pg_advisory_xact_lock\(hashtext\(\$1\)\)`).WithArgs("payouts:" + producerID.String()).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT COALESCE\(-SUM\(amount\), 0\) FROM ledger_entries`).WithArgs(producerID, "INR").
+		WillReturnRows(sqlmock.NewRows([]string{"available"}).AddRow(800))
+	mock.ExpectExec(`INSERT INTO payouts`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO ledger_transactions`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO ledger_entries`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO ledger_entries`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO admin_audit_logs .* 'payouts\.create', 'payout'`).
+		WithArgs(actorID, sqlmock.AnyArg(), sqlmock.AnyArg(), "127.0.0.1", nil).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	require.NoError(t, repo.Create(ctx, payout, transaction, domain.PayoutAudit{ActorID: actorID, IPAddress: "127.0.0.1"}))
+	assert.NotEqual(t, uuid.Nil, payout.ID)
+	require.NotNil(t, transaction.PayoutID)
+	assert.Equal(t, payout.ID, *transaction.PayoutID)
+
+	payout, transaction = newPayout()
+	mock.ExpectBegin()
+	mock.ExpectExec(`SELECT pg_advisory_xact_lock`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`FROM ledger_entries`).WithArgs(producerID, "INR").
+		WillReturnRows(sqlmock.NewRows([]string{"available"}).AddRow(300))
+	mock.ExpectRollback()
+	assert.ErrorIs(t, repo.Create(ctx, payout, transaction, domain.PayoutAudit{ActorID: actorID}), domain.ErrInsufficientBalance)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPgPayoutRepository_List(t *testing.T) {
+	db, mock, cleanup := newMockDB(t)
+	defer cleanup()
+	repo := postgres.NewPayoutRepository(db)
+	ctx := context.Background()
+	producerID := uuid.New()
+	columns := []string{"id", "producer_id", "amount", "currency", "method", "reference", "note", "created_by", "paid_at", "created_at"}
+
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM payouts WHERE producer_id = \$1`).WithArgs(producerID).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery(`SELECT \* FROM payouts WHERE producer_id = \$1 ORDER BY paid_at DESC, id DESC LIMIT \$2 OFFSET \$3`).WithArgs(producerID, 20, 0).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(uuid.New(), producerID, 500, "INR", "bank_transfer", "UTR1", nil, nil, time.Now(), time.Now()))
+	payouts, total, err := repo.List(ctx, &producerID, 20, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 1, total)
+	require.Len(t, payouts, 1)
+	assert.Equal(t, "UTR1", *payouts[0].Reference)
+
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM payouts$`).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectQuery(`SELECT \* FROM payouts ORDER BY paid_at DESC, id DESC LIMIT \$1 OFFSET \$2`).WithArgs(10, 10).
+		WillReturnRows(sqlmock.NewRows(columns))
+	payouts, total, err = repo.List(ctx, nil, 10, 10)
+	require.NoError(t, err)
+	assert.Zero(t, total)
+	assert.Empty(t, payouts)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
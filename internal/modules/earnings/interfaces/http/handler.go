@@ -0,0 +1,179 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/saransh1220/blueprint-audio/internal/gateway/middleware"
+	"github.com/saransh1220/blueprint-audio/internal/modules/earnings/application"
+	"github.com/saransh1220/blueprint-audio/internal/modules/earnings/domain"
+	"github.com/saransh1220/blueprint-audio/internal/shared/clientinfo"
+)
+
+type EarningsHandler struct {
+	service application.EarningsService
+}
+
+func NewEarningsHandler(service application.EarningsService) *EarningsHandler {
+	return &EarningsHandler{service: service}
+}
+
+func (h *EarningsHandler) GetSummary(w http.ResponseWriter, r *http.Request) {
+	producerID, ok := r.Context().Value(middleware.ContextKeyUserId).(uuid.UUID)
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	summary, err := h.service.GetSummary(r.Context(), producerID)
+	if err != nil {
+		log.Printf("EarningsHandler.GetSummary failed. producer_id=%s err=%v", producerID, err)
+		http.Error(w, "failed to fetch earnings", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(summary)
+}
+
+func (h *EarningsHandler) ListPayouts(w http.ResponseWriter, r *http.Request) {
+	producerID, ok := r.Context().Value(middleware.ContextKeyUserId).(uuid.UUID)
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	h.listPayouts(w, r, &producerID)
+}
+
+// GetStatement downloads a monthly statement as CSV (the default) or PDF.
+func (h *EarningsHandler) GetStatement(w http.ResponseWriter, r *http.Request) {
+	producerID, ok := r.Context().Value(middleware.ContextKeyUserId).(uuid.UUID)
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	month := r.PathValue("month")
+	format := strings.ToLower(r.URL.Query().Get("format"))
+	if format == "" {
+		format = "csv"
+	}
+	if format != "csv" && format != "pdf" {
+		http.Error(w, "format must be csv or pdf", http.StatusBadRequest)
+		return
+	}
+
+	statement, err := h.service.GetStatement(r.Context(), producerID, month)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidStatementMonth) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("EarningsHandler.GetStatement failed. producer_id=%s month=%s err=%v", producerID, month, err)
+		http.Error(w, "failed to build statement", http.StatusInternalServerError)
+		return
+	}
+
+	// Render into a buffer so a failure can still become a 500.
+	var body bytes.Buffer
+	contentType := "text/csv; charset=utf-8"
+	if format == "pdf" {
+		contentType = "application/pdf"
+		err = application.WriteStatementPDF(&body, statement)
+	} else {
+		err = application.WriteStatementCSV(&body, statement)
+	}
+	if err != nil {
+		log.Printf("EarningsHandler.GetStatement render failed. producer_id=%s month=%s err=%v", producerID, month, err)
+		http.Error(w, "failed to build statement", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", `attachment; filename="earnings-`+statement.Month+`.`+format+`"`)
+	w.Header().Set("Content-Length", strconv.Itoa(body.Len()))
+	w.Write(body.Bytes())
+}
+
+// AdminCreatePayout records a payout made outside the platform, e.g. a bank
+// transfer, against the producer's available balance.
+func (h *EarningsHandler) AdminCreatePayout(w http.ResponseWriter, r *http.Request) {
+	actorID, ok := r.Context().Value(middleware.ContextKeyUserId).(uuid.UUID)
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var input application.CreatePayoutInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	input.ActorID = actorID
+	input.IPAddress = clientinfo.IP(r)
+	input.UserAgent = r.UserAgent()
+
+	payout, err := h.service.CreatePayout(r.Context(), input)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidPayout):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, domain.ErrInsufficientBalance):
+			http.Error(w, err.Error(), http.StatusConflict)
+		default:
+			log.Printf("EarningsHandler.AdminCreatePayout failed. producer_id=%s err=%v", input.ProducerID, err)
+			http.Error(w, "failed to record payout", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(payout)
+}
+
+func (h *EarningsHandler) AdminListPayouts(w http.ResponseWriter, r *http.Request) {
+	var producerID *uuid.UUID
+	if raw := r.URL.Query().Get("producer_id"); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			http.Error(w, "invalid producer_id", http.StatusBadRequest)
+			return
+		}
+		producerID = &id
+	}
+	h.listPayouts(w, r, producerID)
+}
+
+func (h *EarningsHandler) listPayouts(w http.ResponseWriter, r *http.Request, producerID *uuid.UUID) {
+	page := 1
+	if pageStr := r.URL.Query().Get("page"); pageStr != "" {
+		if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
+			page = p
+		}
+	}
+	limit := 20
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
+			limit = l
+		}
+	}
+	if limit > 100 {
+		limit = 100
+	}
+
+	response, err := h.service.ListPayouts(r.Context(), producerID, page, limit)
+	if err != nil {
+		log.Printf("EarningsHandler.ListPayouts failed: %v", err)
+		http.Error(w, "failed to fetch payouts", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,187 @@
+package http_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/saransh1220/blueprint-audio/internal/gateway/middleware"
+	"github.com/saransh1220/blueprint-audio/internal/modules/earnings/application"
+	"github.com/saransh1220/blueprint-audio/internal/modules/earnings/domain"
+	earningshttp "github.com/saransh1220/blueprint-audio/internal/modules/earnings/interfaces/http"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockEarningsService struct {
+	summaryFn      func(context.Context, uuid.UUID) (*application.Summary, error)
+	listPayoutsFn  func(context.Context, *uuid.UUID, int, int) (*application.PayoutListResponse, error)
+	createPayoutFn func(context.Context, application.CreatePayoutInput) (*domain.Payout, error)
+	statementFn    func(context.Context, uuid.UUID, string) (*application.Statement, error)
+}
+
+func (m mockEarningsService) RecordSale(context.Context, uuid.UUID) error   { return nil }
+func (m mockEarningsService) RecordRefund(context.Context, uuid.UUID) error { return nil }
+func (m mockEarningsService) Reconcile(context.Context) (int, error)        { return 0, nil }
+func (m mockEarningsService) GetSummary(ctx context.Context, producerID uuid.UUID) (*application.Summary, error) {
+	return m.summaryFn(ctx, producerID)
+}
+func (m mockEarningsService) ListPayouts(ctx context.Context, producerID *uuid.UUID, page, limit int) (*application.PayoutListResponse, error) {
+	return m.listPayoutsFn(ctx, producerID, page, limit)
+}
+func (m mockEarningsService) CreatePayout(ctx context.Context, input application.CreatePayoutInput) (*domain.Payout, error) {
+	return m.createPayoutFn(ctx, input)
+}
+func (m mockEarningsService) GetStatement(ctx context.Context, producerID uuid.UUID, month string) (*application.Statement, error) {
+	return m.statementFn(ctx, producerID, month)
+}
+
+func withUser(req *http.Request, userID uuid.UUID) *http.Request {
+	return req.WithContext(context.WithValue(req.Context(), middleware.ContextKeyUserId, userID))
+}
+
+func TestEarningsHandler_GetSummary(t *testing.T) {
+	producerID := uuid.New()
+	h := earningshttp.NewEarningsHandler(mockEarningsService{
+		summaryFn: func(_ context.Context, id uuid.UUID) (*application.Summary, error) {
+			require.Equal(t, producerID, id)
+			return &application.Summary{Balances: []domain.Balance{{Currency: "INR", Available: 900}}, PlatformFeePercent: 10}, nil
+		},
+	})
+
+	rec := httptest.NewRecorder()
+	h.GetSummary(rec, httptest.NewRequest(http.MethodGet, "/earnings", nil))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	rec = httptest.NewRecorder()
+	h.GetSummary(rec, withUser(httptest.NewRequest(http.MethodGet, "/earnings", nil), producerID))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var body map[string]any
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, float64(10), body["platform_fee_percent"])
+	assert.Nil(t, body["last_payout"])
+}
+
+func TestEarningsHandler_ListPayouts(t *testing.T) {
+	producerID := uuid.New()
+	var gotProducer *uuid.UUID
+	var gotPage, gotLimit int
+	h := earningshttp.NewEarningsHandler(mockEarningsService{
+		listPayoutsFn: func(_ context.Context, id *uuid.UUID, page, limit int) (*application.PayoutListResponse, error) {
+			gotProducer, gotPage, gotLimit = id, page, limit
+			return &application.PayoutListResponse{Payouts: []domain.Payout{}, Limit: limit}, nil
+		},
+	})
+
+	rec := httptest.NewRecorder()
+	h.ListPayouts(rec, withUser(httptest.NewRequest(http.MethodGet, "/earnings/payouts?page=2&limit=500", nil), producerID))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.NotNil(t, gotProducer)
+	assert.Equal(t, producerID, *gotProducer)
+	assert.Equal(t, 2, gotPage)
+	assert.Equal(t, 100, gotLimit)
+
+	// Admins may list everyone's payouts or filter by producer.
+	rec = httptest.NewRecorder()
+	h.AdminListPayouts(rec, httptest.NewRequest(http.MethodGet, "/admin/payouts", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Nil(t, gotProducer)
+	assert.Equal(t, 20, gotLimit)
+
+	rec = httptest.NewRecorder()
+	h.AdminListPayouts(rec, httptest.NewRequest(http.MethodGet, "/admin/payouts?producer_id=nope", nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestEarningsHandler_GetStatement(t *testing.T) {
+	producerID := uuid.New()
+	h := earningshttp.NewEarningsHandler(mockEarningsService{
+		statementFn: func(_ context.Context, _ uuid.UUID, month string) (*application.Statement, error) {
+			if month != "2026-05" {
+				return nil, domain.ErrInvalidStatementMonth
+			}
+			return &application.Statement{
+				Month: month,
+				From:  time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC),
+				To:    time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC),
+			}, nil
+		},
+	})
+	statementRequest := func(target, month string) *http.Request {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req.SetPathValue("month", month)
+		return withUser(req, producerID)
+	}
+
+	rec := httptest.NewRecorder()
+	h.GetStatement(rec, statementRequest("/earnings/statements/2026-05", "2026-05"))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="earnings-2026-05.csv"`, rec.Header().Get("Content-Disposition"))
+	assert.True(t, strings.HasPrefix(rec.Body.String(), "date,type,"))
+
+	rec = httptest.NewRecorder()
+	h.GetStatement(rec, statementRequest("/earnings/statements/2026-05?format=pdf", "2026-05"))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/pdf", rec.Header().Get("Content-Type"))
+	assert.True(t, strings.HasPrefix(rec.Body.String(), "%PDF-"))
+
+	rec = httptest.NewRecorder()
+	h.GetStatement(rec, statementRequest("/earnings/statements/2026-05?format=xlsx", "2026-05"))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = httptest.NewRecorder()
+	h.GetStatement(rec, statementRequest("/earnings/statements/may", "may"))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestEarningsHandler_AdminCreatePayout(t *testing.T) {
+	actorID, producerID := uuid.New(), uuid.New()
+	var got application.CreatePayoutInput
+	createErr := error(nil)
+	h := earningshttp.NewEarningsHandler(mockEarningsService{
+		createPayoutFn: func(_ context.Context, input application.CreatePayoutInput) (*domain.Payout, error) {
+			got = input
+			if createErr != nil {
+				return nil, createErr
+			}
+			return &domain.Payout{ID: uuid.New(), ProducerID: input.ProducerID, Amount: input.Amount, Currency: input.Currency}, nil
+		},
+	})
+	payoutRequest := func(body string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/admin/payouts", strings.NewReader(body))
+		req.Header.Set("X-Forwarded-For", "203.0.113.7, 10.0.0.1")
+		req.Header.Set("User-Agent", "admin-console")
+		return withUser(req, actorID)
+	}
+	body := `{"producer_id":"` + producerID.String() + `","amount":500,"currency":"INR","method":"bank_transfer"}`
+
+	rec := httptest.NewRecorder()
+	h.AdminCreatePayout(rec, payoutRequest(body))
+	require.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, actorID, got.ActorID)
+	assert.Equal(t, "203.0.113.7", got.IPAddress)
+	assert.Equal(t, "admin-console", got.UserAgent)
+	assert.Equal(t, 500, got.Amount)
+
+	rec = httptest.NewRecorder()
+	h.AdminCreatePayout(rec, payoutRequest("{"))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	for err, status := range map[error]int{
+		domain.ErrInvalidPayout:       http.StatusBadRequest,
+		domain.ErrInsufficientBalance: http.StatusConflict,
+		errors.New("db down"):         http.StatusInternalServerError,
+	} {
+		createErr = err
+		rec = httptest.NewRecorder()
+		h.AdminCreatePayout(rec, payoutRequest(body))
+		assert.Equal(t, status, rec.Code, err.Error())
+	}
+}
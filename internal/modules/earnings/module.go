@@ -0,0 +1,45 @@
+package earnings
+
+import (
+	"github.com/jmoiron/sqlx"
+	authDomain "github.com/saransh1220/blueprint-audio/internal/modules/auth/domain"
+	"github.com/saransh1220/blueprint-audio/internal/modules/earnings/application"
+	persistence "github.com/saransh1220/blueprint-audio/internal/modules/earnings/infrastructure/persistence/postgres"
+	earningsHttp "github.com/saransh1220/blueprint-audio/internal/modules/earnings/interfaces/http"
+)
+
+// Module represents the Earnings module
+type Module struct {
+	service application.EarningsService
+	handler *earningsHttp.EarningsHandler
+}
+
+// NewModule creates and initializes the Earnings module
+func NewModule(
+	db *sqlx.DB,
+	userFinder authDomain.UserFinder,
+	notifier application.Notifier,
+	config application.Config,
+) *Module {
+	ledgerRepo := persistence.NewLedgerRepository(db)
+	payoutRepo := persistence.NewPayoutRepository(db)
+
+	service := application.NewEarningsService(ledgerRepo, payoutRepo, userFinder, notifier, config)
+	handler := earningsHttp.NewEarningsHandler(service)
+
+	return &Module{
+		service: service,
+		handler: handler,
+	}
+}
+
+// Service returns the earnings service, which payment uses to post sales and
+// refunds to the ledger.
+func (m *Module) Service() application.EarningsService {
+	return m.service
+}
+
+// HTTPHandler returns the HTTP handler
+func (m *Module) HTTPHandler() *earningsHttp.EarningsHandler {
+	return m.handler
+}
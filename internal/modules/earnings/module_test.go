@@ -0,0 +1,26 @@
+package earnings
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	authDomain "github.com/saransh1220/blueprint-audio/internal/modules/auth/domain"
+	"github.com/saransh1220/blueprint-audio/internal/modules/earnings/application"
+	"github.com/stretchr/testify/require"
+)
+
+type nilUserFinder struct{}
+
+func (nilUserFinder) FindByID(_ context.Context, id uuid.UUID) (*authDomain.User, error) {
+	return &authDomain.User{ID: id}, nil
+}
+func (nilUserFinder) Exists(_ context.Context, _ uuid.UUID) (bool, error) { return true, nil }
+
+func TestModuleAccessors(t *testing.T) {
+	m := NewModule(&sqlx.DB{}, nilUserFinder{}, nil, application.Config{PlatformFeeBPS: 1000, ClearanceDays: 7})
+	require.NotNil(t, m)
+	require.NotNil(t, m.Service())
+	require.NotNil(t, m.HTTPHandler())
+}
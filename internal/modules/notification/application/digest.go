@@ -0,0 +1,111 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/saransh1220/blueprint-audio/internal/modules/notification/domain"
+	sharedemail "github.com/saransh1220/blueprint-audio/internal/shared/infrastructure/email"
+)
+
+// DigestWindow is how long notifications wait before they are sent in a
+// digest, so each user gets at most one digest a day.
+const DigestWindow = 24 * time.Hour
+
+const digestBatchSize = 100
+
+// SendDigests emails each user whose oldest pending digest notification is
+// older than DigestWindow a summary of their unread ones, and returns how
+// many digests were sent. Notifications read in the meantime, or whose
+// category is no longer emailed as a digest, are left out.
+func (s *NotificationService) SendDigests(ctx context.Context, now time.Time) (int, error) {
+	sent := 0
+	for {
+		recipients, err := s.repo.ListDigestRecipients(ctx, now.Add(-DigestWindow), digestBatchSize)
+		if err != nil {
+			return sent, fmt.Errorf("list digest recipients: %w", err)
+		}
+		failed := 0
+		for _, userID := range recipients {
+			ok, err := s.sendDigest(ctx, userID)
+			if err != nil {
+				log.Printf("NotificationService.SendDigests failed. user_id=%s err=%v", userID, err)
+				failed++
+				continue
+			}
+			if ok {
+				sent++
+			}
+		}
+		// Failed digests are pending again and would be listed again, so
+		// stop rather than retry them in the same run.
+		if len(recipients) < digestBatchSize || failed > 0 {
+			return sent, nil
+		}
+	}
+}
+
+// sendDigest claims the user's pending notifications and emails the ones
+// still worth sending, reporting whether an email was sent.
+func (s *NotificationService) sendDigest(ctx context.Context, userID uuid.UUID) (bool, error) {
+	claimed, err := s.repo.ClaimDigest(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	recipient, err := s.emailRecipient(ctx, userID)
+	if err != nil {
+		return false, s.releaseDigest(ctx, claimed, fmt.Errorf("look up recipient: %w", err))
+	}
+	if recipient == nil {
+		return false, nil
+	}
+	preferences, err := s.Preferences(ctx, userID)
+	if err != nil {
+		return false, s.releaseDigest(ctx, claimed, err)
+	}
+	digest := make(map[domain.Category]bool, len(preferences))
+	for _, preference := range preferences {
+		digest[preference.Category] = preference.Email == domain.EmailDailyDigest
+	}
+
+	items := make([]sharedemail.NotificationDigestItem, 0, len(claimed))
+	for _, notification := range claimed {
+		if notification.IsRead || !digest[notification.Category] {
+			continue
+		}
+		items = append(items, sharedemail.NotificationDigestItem{
+			Title:   notification.Title,
+			Message: notification.Message,
+			When:    notification.CreatedAt.UTC().Format("Jan 2, 15:04 UTC"),
+		})
+	}
+	if len(items) == 0 {
+		return false, nil
+	}
+
+	message := sharedemail.BuildNotificationDigestEmail(sharedemail.NotificationDigestData{
+		RecipientName:  recipientName(recipient),
+		RecipientEmail: recipient.Email,
+		Items:          items,
+	}, s.email.AppBaseURL)
+	if err := s.email.Sender.Send(ctx, message); err != nil {
+		return false, s.releaseDigest(ctx, claimed, err)
+	}
+	return true, nil
+}
+
+// releaseDigest makes claimed notifications pending again so the next run
+// retries them, and returns the error that stopped the digest.
+func (s *NotificationService) releaseDigest(ctx context.Context, claimed []domain.Notification, cause error) error {
+	ids := make([]uuid.UUID, len(claimed))
+	for i, notification := range claimed {
+		ids[i] = notification.ID
+	}
+	if err := s.repo.ReleaseDigest(ctx, ids); err != nil {
+		return fmt.Errorf("%w (release digest: %v)", cause, err)
+	}
+	return cause
+}
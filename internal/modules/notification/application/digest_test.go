@@ -0,0 +1,147 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	authDomain "github.com/saransh1220/blueprint-audio/internal/modules/auth/domain"
+	"github.com/saransh1220/blueprint-audio/internal/modules/notification/domain"
+	ws "github.com/saransh1220/blueprint-audio/internal/modules/notification/infrastructure/websocket"
+	sharedemail "github.com/saransh1220/blueprint-audio/internal/shared/infrastructure/email"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type preferenceRepoStub map[uuid.UUID][]domain.Preference
+
+func (s preferenceRepoStub) ListByUserID(_ context.Context, userID uuid.UUID) ([]domain.Preference, error) {
+	return s[userID], nil
+}
+
+func (s preferenceRepoStub) Upsert(_ context.Context, userID uuid.UUID, preferences []domain.Preference) error {
+	s[userID] = append(s[userID], preferences...)
+	return nil
+}
+
+type userFinderStub struct{}
+
+func (userFinderStub) FindByID(_ context.Context, id uuid.UUID) (*authDomain.User, error) {
+	return &authDomain.User{ID: id, Email: "fan@example.com", Name: "Fan"}, nil
+}
+
+func (userFinderStub) Exists(context.Context, uuid.UUID) (bool, error) { return true, nil }
+
+type failingUserFinder struct{}
+
+func (failingUserFinder) FindByID(context.Context, uuid.UUID) (*authDomain.User, error) {
+	return nil, errors.New("database unavailable")
+}
+
+func (failingUserFinder) Exists(context.Context, uuid.UUID) (bool, error) { return true, nil }
+
+// emailSenderStub hands sent emails to the test, which may be running on
+// another goroutine.
+type emailSenderStub struct {
+	sent chan sharedemail.Message
+	err  error
+}
+
+func (s *emailSenderStub) Send(_ context.Context, message sharedemail.Message) error {
+	s.sent <- message
+	return s.err
+}
+
+func TestNotificationService_CreateFollowsPreferences(t *testing.T) {
+	userID := uuid.New()
+	var stored []domain.Notification
+	repo := notificationRepoMock{
+		createFn: func(_ context.Context, n *domain.Notification) error {
+			stored = append(stored, *n)
+			return nil
+		},
+		unreadCountFn: func(context.Context, uuid.UUID) (int, error) { return 1, nil },
+	}
+	preferences := preferenceRepoStub{userID: {{Category: domain.CategoryUploadProcessed, InApp: false, Email: domain.EmailInstant}}}
+	sender := &emailSenderStub{sent: make(chan sharedemail.Message, 1)}
+	svc := NewNotificationService(repo, nil, preferences, ws.NewPublisher(nil), userFinderStub{}, EmailConfig{Sender: sender, AppBaseURL: "https://app.example.com"})
+	ctx := context.Background()
+
+	// Instant email only: nothing is stored.
+	require.NoError(t, svc.Create(ctx, userID, domain.CategoryUploadProcessed, "Upload Complete", "'Loop' is now live!", domain.NotificationTypeSuccess))
+	select {
+	case message := <-sender.sent:
+		assert.Equal(t, []string{"fan@example.com"}, message.To)
+		assert.Equal(t, "Upload Complete", message.Subject)
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected an instant email")
+	}
+	assert.Empty(t, stored)
+
+	// Defaults: price drops are stored for the digest and not emailed now.
+	require.NoError(t, svc.Create(ctx, userID, domain.CategoryPriceDrop, "Price drop", "m", domain.NotificationTypeInfo))
+	require.Len(t, stored, 1)
+	assert.Equal(t, domain.CategoryPriceDrop, stored[0].Category)
+	assert.True(t, stored[0].DigestPending)
+	assert.Empty(t, sender.sent)
+
+	require.ErrorIs(t, svc.Create(ctx, userID, "gossip", "t", "m", domain.NotificationTypeInfo), domain.ErrUnknownCategory)
+}
+
+func TestNotificationService_SendDigests(t *testing.T) {
+	userID := uuid.New()
+	now := time.Date(2026, 3, 4, 9, 0, 0, 0, time.UTC)
+	claimed := []domain.Notification{
+		{ID: uuid.New(), UserID: userID, Category: domain.CategoryPriceDrop, Title: "Price drop", Message: "'Loop' is now 20% off", CreatedAt: now.Add(-25 * time.Hour)},
+		{ID: uuid.New(), UserID: userID, Category: domain.CategoryPriceDrop, Title: "Read already", IsRead: true, CreatedAt: now.Add(-2 * time.Hour)},
+		{ID: uuid.New(), UserID: userID, Category: domain.CategorySaleMade, Title: "No longer digested", CreatedAt: now.Add(-time.Hour)},
+	}
+	var released []uuid.UUID
+	repo := notificationRepoMock{
+		listDigestRecipientsFn: func(_ context.Context, pendingSince time.Time, limit int) ([]uuid.UUID, error) {
+			assert.Equal(t, now.Add(-DigestWindow), pendingSince)
+			return []uuid.UUID{userID}, nil
+		},
+		claimDigestFn: func(_ context.Context, gotUserID uuid.UUID) ([]domain.Notification, error) {
+			assert.Equal(t, userID, gotUserID)
+			return claimed, nil
+		},
+		releaseDigestFn: func(_ context.Context, ids []uuid.UUID) error {
+			released = ids
+			return nil
+		},
+	}
+	sender := &emailSenderStub{sent: make(chan sharedemail.Message, 1)}
+	svc := NewNotificationService(repo, nil, preferenceRepoStub{}, ws.NewPublisher(nil), userFinderStub{}, EmailConfig{Sender: sender, AppBaseURL: "https://app.example.com"})
+
+	sent, err := svc.SendDigests(context.Background(), now)
+	require.NoError(t, err)
+	assert.Equal(t, 1, sent)
+	message := <-sender.sent
+	assert.Equal(t, "You have 1 unread notification on Blueprint", message.Subject)
+	assert.Contains(t, message.Text, "'Loop' is now 20% off")
+	assert.Contains(t, message.Text, "Mar 3, 08:00 UTC")
+	assert.NotContains(t, message.Text, "Read already")
+	assert.NotContains(t, message.Text, "No longer digested")
+	assert.Empty(t, released)
+
+	// A digest that cannot be sent is released for the next run.
+	sender.err = errors.New("smtp down")
+	sent, err = svc.SendDigests(context.Background(), now)
+	require.NoError(t, err)
+	assert.Zero(t, sent)
+	<-sender.sent
+	assert.Len(t, released, len(claimed))
+
+	// So is a digest whose recipient could not be looked up.
+	released = nil
+	sender.err = nil
+	svc = NewNotificationService(repo, nil, preferenceRepoStub{}, ws.NewPublisher(nil), failingUserFinder{}, EmailConfig{Sender: sender, AppBaseURL: "https://app.example.com"})
+	sent, err = svc.SendDigests(context.Background(), now)
+	require.NoError(t, err)
+	assert.Zero(t, sent)
+	assert.Empty(t, sender.sent)
+	assert.Len(t, released, len(claimed))
+}
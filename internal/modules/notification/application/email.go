@@ -0,0 +1,70 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	authDomain "github.com/saransh1220/blueprint-audio/internal/modules/auth/domain"
+	"github.com/saransh1220/blueprint-audio/internal/modules/notification/domain"
+	sharedemail "github.com/saransh1220/blueprint-audio/internal/shared/infrastructure/email"
+)
+
+// deliveryTimeout bounds emailing or pushing one notification, which runs
+// after the request that triggered it has returned.
+const deliveryTimeout = 30 * time.Second
+
+// emailNotification emails a notification to its recipient. Failures are
+// logged: the notification is still available in-app.
+func (s *NotificationService) emailNotification(notification domain.Notification) {
+	ctx, cancel := context.WithTimeout(context.Background(), deliveryTimeout)
+	defer cancel()
+
+	recipient, err := s.emailRecipient(ctx, notification.UserID)
+	if err != nil {
+		log.Printf("NotificationService.emailNotification recipient lookup failed. notification_id=%s err=%v", notification.ID, err)
+		return
+	}
+	if recipient == nil {
+		return
+	}
+	message := sharedemail.BuildNotificationEmail(sharedemail.NotificationEmailData{
+		RecipientName:  recipientName(recipient),
+		RecipientEmail: recipient.Email,
+		Title:          notification.Title,
+		Message:        notification.Message,
+	}, s.email.AppBaseURL)
+	if err := s.email.Sender.Send(ctx, message); err != nil {
+		log.Printf("NotificationService.emailNotification failed. notification_id=%s err=%v", notification.ID, err)
+	}
+}
+
+// emailRecipient looks up the user to email. It returns no user and no error
+// when emails cannot be sent to them, such as when they have no address or
+// no longer exist.
+func (s *NotificationService) emailRecipient(ctx context.Context, userID uuid.UUID) (*authDomain.User, error) {
+	if s.email.Sender == nil || s.users == nil {
+		return nil, nil
+	}
+	user, err := s.users.FindByID(ctx, userID)
+	if errors.Is(err, authDomain.ErrUserNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(user.Email) == "" {
+		return nil, nil
+	}
+	return user, nil
+}
+
+func recipientName(user *authDomain.User) string {
+	if user.DisplayName != nil && strings.TrimSpace(*user.DisplayName) != "" {
+		return strings.TrimSpace(*user.DisplayName)
+	}
+	return user.Name
+}
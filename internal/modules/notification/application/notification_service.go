@@ -2,68 +2,165 @@ package application
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"log"
+	"slices"
+	"strings"
 	"time"
-
-	"encoding/json"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
+	authDomain "github.com/saransh1220/blueprint-audio/internal/modules/auth/domain"
 	"github.com/saransh1220/blueprint-audio/internal/modules/notification/domain"
 	"github.com/saransh1220/blueprint-audio/internal/modules/notification/infrastructure/websocket"
+	sharedemail "github.com/saransh1220/blueprint-audio/internal/shared/infrastructure/email"
 )
 
+// EmailConfig is what the service needs to email notifications. Without a
+// sender, nothing is emailed.
+type EmailConfig struct {
+	Sender     sharedemail.Sender
+	AppBaseURL string
+}
+
 type NotificationService struct {
 	repo domain.NotificationRepository
-	hub  *websocket.Hub
+	// events numbers and stores realtime events for replay. When nil,
+	// events are sent without a seq and cannot be replayed.
+	events domain.EventRepository
+	// preferences holds the delivery choices users made. When nil, every
+	// user gets the defaults.
+	preferences domain.PreferenceRepository
+	// sender is the module's hub in the API, or a publisher in processes
+	// without WebSocket clients such as the worker.
+	sender websocket.Sender
+	users  authDomain.UserFinder
+	email  EmailConfig
+	// pushSubscriptions and pushSender are set by SetPush.
+	pushSubscriptions domain.PushSubscriptionRepository
+	pushSender        domain.PushSender
 }
 
-func NewNotificationService(repo domain.NotificationRepository, hub *websocket.Hub) *NotificationService {
-	return &NotificationService{repo: repo, hub: hub}
+func NewNotificationService(
+	repo domain.NotificationRepository,
+	events domain.EventRepository,
+	preferences domain.PreferenceRepository,
+	sender websocket.Sender,
+	users authDomain.UserFinder,
+	email EmailConfig,
+) *NotificationService {
+	return &NotificationService{
+		repo:        repo,
+		events:      events,
+		preferences: preferences,
+		sender:      sender,
+		users:       users,
+		email:       email,
+	}
 }
 
-func (s *NotificationService) Create(ctx context.Context, userID uuid.UUID, title, message string, type_ domain.NotificationType) error {
+// Create delivers a notification on the channels the user chose for its
+// category: stored in-app and pushed to their open connections and
+// subscribed browsers, and emailed right away or in the daily digest.
+func (s *NotificationService) Create(ctx context.Context, userID uuid.UUID, category domain.Category, title, message string, type_ domain.NotificationType) error {
+	if !slices.Contains(domain.Categories, category) {
+		return domain.ErrUnknownCategory
+	}
+	preference := s.preference(ctx, userID, category)
+
 	notificationID, err := uuid.NewV7()
 	if err != nil {
 		return fmt.Errorf("failed to generate uuid: %w", err)
 	}
 
 	notification := &domain.Notification{
-		ID:        notificationID,
-		UserID:    userID,
-		Title:     title,
-		Message:   message,
-		Type:      type_,
-		IsRead:    false,
-		CreatedAt: time.Now(),
+		ID:            notificationID,
+		UserID:        userID,
+		Category:      category,
+		Title:         title,
+		Message:       message,
+		Type:          type_,
+		IsRead:        false,
+		CreatedAt:     time.Now(),
+		DigestPending: preference.Email == domain.EmailDailyDigest,
+	}
+	if preference.InApp {
+		saveErr := s.repo.Create(ctx, notification)
+		if saveErr != nil {
+			return saveErr
+		}
+
+		if err := s.Publish(ctx, userID, domain.EventNotificationCreated, notification); err != nil {
+			log.Printf("NotificationService.Create publish failed. notification_id=%s err=%v", notification.ID, err)
+		}
+		s.publishUnreadCount(ctx, userID)
+		if s.pushSender != nil {
+			go s.pushNotification(*notification)
+		}
 	}
-	saveErr := s.repo.Create(ctx, notification)
-	if saveErr != nil {
-		return saveErr
+	if preference.Email == domain.EmailInstant {
+		go s.emailNotification(*notification)
 	}
 
-	// Broadcast to WebSocket
-	// We might want to optimize this to only send to specific user, but for now hub broadcasts to all (filtered by client side? No, hub has all clients)
-	// Actually, my Hub implementation broadcasts to ALL clients.
-	// We need targeted notifications.
-	// My Client struct has UserID.
-	// I should use that.
-
-	// For now, let's just broadcast and let the client implementation handle filtering or update Hub to support direct messaging.
-	// The current Hub implementation broadcasts to everyone.
-	// I'll update the Hub later or now to support filtering, but let's stick to the plan.
-	// Sending the notification as JSON.
-
-	msgBytes, err := json.Marshal(notification)
-	if err == nil {
-		// s.hub.BroadcastMessage(msgBytes) // OLD: Insecure broadcast
-		s.hub.SendToUser(userID, msgBytes) // NEW: Secure unicast
+	return nil
+}
+
+// SendAdminMessage notifies a user of a message from the platform's admins.
+func (s *NotificationService) SendAdminMessage(ctx context.Context, userID uuid.UUID, title, message string) error {
+	title, message = strings.TrimSpace(title), strings.TrimSpace(message)
+	if title == "" || message == "" || utf8.RuneCountInString(title) > 255 {
+		return domain.ErrInvalidAdminMessage
+	}
+	if s.users != nil {
+		exists, err := s.users.Exists(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return domain.ErrRecipientNotFound
+		}
 	}
+	return s.Create(ctx, userID, domain.CategoryAdminMessage, title, message, domain.NotificationTypeInfo)
+}
 
+// Publish stores a realtime event under the user's next seq and pushes it to
+// their open connections. Clients receive it only if they subscribed to the
+// event's topic, and clients that were offline can replay it.
+func (s *NotificationService) Publish(ctx context.Context, userID uuid.UUID, eventType domain.EventType, payload any) error {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	event := domain.Event{UserID: userID, Type: eventType, Payload: raw}
+	if s.events != nil {
+		if err := s.events.Append(ctx, &event); err != nil {
+			return fmt.Errorf("store event: %w", err)
+		}
+	}
+	message, err := json.Marshal(websocket.NewEvent(event))
+	if err != nil {
+		return err
+	}
+	s.sender.SendToUser(userID, message)
 	return nil
 }
 
+// publishUnreadCount tells the user's connections their new unread count.
+func (s *NotificationService) publishUnreadCount(ctx context.Context, userID uuid.UUID) {
+	count, err := s.repo.UnreadCount(ctx, userID)
+	if err != nil {
+		log.Printf("NotificationService.publishUnreadCount failed. user_id=%s err=%v", userID, err)
+		return
+	}
+	_ = s.Publish(ctx, userID, domain.EventUnreadCountChanged, domain.UnreadCountPayload{UnreadCount: count})
+}
+
+// GetHub returns the hub the service sends through, or nil when it only
+// publishes.
 func (s *NotificationService) GetHub() *websocket.Hub {
-	return s.hub
+	hub, _ := s.sender.(*websocket.Hub)
+	return hub
 }
 
 func (s *NotificationService) GetUserNotifications(ctx context.Context, userID uuid.UUID, limit, offset int) ([]domain.Notification, error) {
@@ -71,11 +168,19 @@ func (s *NotificationService) GetUserNotifications(ctx context.Context, userID u
 }
 
 func (s *NotificationService) MarkAsRead(ctx context.Context, notificationID, userID uuid.UUID) error {
-	return s.repo.MarkAsRead(ctx, notificationID, userID)
+	if err := s.repo.MarkAsRead(ctx, notificationID, userID); err != nil {
+		return err
+	}
+	s.publishUnreadCount(ctx, userID)
+	return nil
 }
 
 func (s *NotificationService) MarkAllAsRead(ctx context.Context, userID uuid.UUID) error {
-	return s.repo.MarkAllAsRead(ctx, userID)
+	if err := s.repo.MarkAllAsRead(ctx, userID); err != nil {
+		return err
+	}
+	s.publishUnreadCount(ctx, userID)
+	return nil
 }
 
 func (s *NotificationService) UnreadCount(ctx context.Context, userID uuid.UUID) (int, error) {
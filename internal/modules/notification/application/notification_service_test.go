@@ -2,8 +2,10 @@ package application
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/saransh1220/blueprint-audio/internal/modules/notification/domain"
@@ -13,11 +15,14 @@ import (
 )
 
 type notificationRepoMock struct {
-	createFn        func(context.Context, *domain.Notification) error
-	getByUserIDFn   func(context.Context, uuid.UUID, int, int) ([]domain.Notification, error)
-	markAsReadFn    func(context.Context, uuid.UUID, uuid.UUID) error
-	markAllAsReadFn func(context.Context, uuid.UUID) error
-	unreadCountFn   func(context.Context, uuid.UUID) (int, error)
+	createFn               func(context.Context, *domain.Notification) error
+	getByUserIDFn          func(context.Context, uuid.UUID, int, int) ([]domain.Notification, error)
+	markAsReadFn           func(context.Context, uuid.UUID, uuid.UUID) error
+	markAllAsReadFn        func(context.Context, uuid.UUID) error
+	unreadCountFn          func(context.Context, uuid.UUID) (int, error)
+	listDigestRecipientsFn func(context.Context, time.Time, int) ([]uuid.UUID, error)
+	claimDigestFn          func(context.Context, uuid.UUID) ([]domain.Notification, error)
+	releaseDigestFn        func(context.Context, []uuid.UUID) error
 }
 
 func (m notificationRepoMock) Create(ctx context.Context, n *domain.Notification) error {
@@ -40,6 +45,18 @@ func (m notificationRepoMock) UnreadCount(ctx context.Context, userID uuid.UUID)
 	return m.unreadCountFn(ctx, userID)
 }
 
+func (m notificationRepoMock) ListDigestRecipients(ctx context.Context, pendingSince time.Time, limit int) ([]uuid.UUID, error) {
+	return m.listDigestRecipientsFn(ctx, pendingSince, limit)
+}
+
+func (m notificationRepoMock) ClaimDigest(ctx context.Context, userID uuid.UUID) ([]domain.Notification, error) {
+	return m.claimDigestFn(ctx, userID)
+}
+
+func (m notificationRepoMock) ReleaseDigest(ctx context.Context, notificationIDs []uuid.UUID) error {
+	return m.releaseDigestFn(ctx, notificationIDs)
+}
+
 func TestNotificationService_Create(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		hub := ws.NewHub()
@@ -58,9 +75,9 @@ func TestNotificationService_Create(t *testing.T) {
 			markAllAsReadFn: func(context.Context, uuid.UUID) error { return nil },
 			unreadCountFn:   func(context.Context, uuid.UUID) (int, error) { return 0, nil },
 		}
-		svc := NewNotificationService(repo, hub)
+		svc := NewNotificationService(repo, nil, nil, hub, nil, EmailConfig{})
 
-		err := svc.Create(context.Background(), userID, "Title", "Message", domain.NotificationTypeInfo)
+		err := svc.Create(context.Background(), userID, domain.CategoryAccount, "Title", "Message", domain.NotificationTypeInfo)
 		require.NoError(t, err)
 		require.NotNil(t, captured)
 		assert.Equal(t, userID, captured.UserID)
@@ -74,6 +91,17 @@ func TestNotificationService_Create(t *testing.T) {
 		assert.Equal(t, hub, svc.GetHub())
 	})
 
+	t.Run("publish only", func(t *testing.T) {
+		repo := notificationRepoMock{
+			createFn:      func(context.Context, *domain.Notification) error { return nil },
+			unreadCountFn: func(context.Context, uuid.UUID) (int, error) { return 1, nil },
+		}
+		svc := NewNotificationService(repo, nil, nil, ws.NewPublisher(nil), nil, EmailConfig{})
+
+		require.NoError(t, svc.Create(context.Background(), uuid.New(), domain.CategoryAccount, "t", "m", domain.NotificationTypeInfo))
+		assert.Nil(t, svc.GetHub())
+	})
+
 	t.Run("repo error", func(t *testing.T) {
 		hub := ws.NewHub()
 		go hub.Run()
@@ -86,9 +114,9 @@ func TestNotificationService_Create(t *testing.T) {
 			markAllAsReadFn: func(context.Context, uuid.UUID) error { return nil },
 			unreadCountFn:   func(context.Context, uuid.UUID) (int, error) { return 0, nil },
 		}
-		svc := NewNotificationService(repo, hub)
+		svc := NewNotificationService(repo, nil, nil, hub, nil, EmailConfig{})
 
-		err := svc.Create(context.Background(), uuid.New(), "t", "m", domain.NotificationTypeError)
+		err := svc.Create(context.Background(), uuid.New(), domain.CategoryAccount, "t", "m", domain.NotificationTypeError)
 		require.EqualError(t, err, "db error")
 	})
 }
@@ -99,6 +127,8 @@ func TestNotificationService_Delegates(t *testing.T) {
 	expected := []domain.Notification{{ID: uuid.New(), UserID: userID, Title: "n"}}
 
 	hub := ws.NewHub()
+	go hub.Run()
+	defer hub.Stop()
 	repo := notificationRepoMock{
 		createFn: func(context.Context, *domain.Notification) error { return nil },
 		getByUserIDFn: func(_ context.Context, gotUserID uuid.UUID, limit, offset int) ([]domain.Notification, error) {
@@ -121,7 +151,7 @@ func TestNotificationService_Delegates(t *testing.T) {
 			return 7, nil
 		},
 	}
-	svc := NewNotificationService(repo, hub)
+	svc := NewNotificationService(repo, nil, nil, hub, nil, EmailConfig{})
 	ctx := context.Background()
 
 	items, err := svc.GetUserNotifications(ctx, userID, 10, 5)
@@ -135,3 +165,96 @@ func TestNotificationService_Delegates(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, 7, count)
 }
+
+type recordingSender struct {
+	userIDs  []uuid.UUID
+	messages []ws.Event
+}
+
+func (r *recordingSender) SendToUser(userID uuid.UUID, message []byte) {
+	var event ws.Event
+	_ = json.Unmarshal(message, &event)
+	r.userIDs = append(r.userIDs, userID)
+	r.messages = append(r.messages, event)
+}
+
+func (r *recordingSender) BroadcastMessage([]byte) {}
+
+func TestNotificationService_PublishesEvents(t *testing.T) {
+	userID := uuid.New()
+	unread := 3
+	repo := notificationRepoMock{
+		createFn:        func(context.Context, *domain.Notification) error { return nil },
+		markAsReadFn:    func(context.Context, uuid.UUID, uuid.UUID) error { return nil },
+		markAllAsReadFn: func(context.Context, uuid.UUID) error { return errors.New("db") },
+		unreadCountFn:   func(context.Context, uuid.UUID) (int, error) { return unread, nil },
+	}
+	sender := &recordingSender{}
+	svc := NewNotificationService(repo, nil, nil, sender, nil, EmailConfig{})
+	ctx := context.Background()
+
+	require.NoError(t, svc.Create(ctx, userID, domain.CategorySaleMade, "Sale", "You sold a beat", domain.NotificationTypeSuccess))
+	require.Len(t, sender.messages, 2)
+	assert.Equal(t, domain.EventNotificationCreated, sender.messages[0].Type)
+	assert.Equal(t, ws.ProtocolVersion, sender.messages[0].Version)
+	assert.Contains(t, string(sender.messages[0].Payload), `"title":"Sale"`)
+	assert.Equal(t, domain.EventUnreadCountChanged, sender.messages[1].Type)
+	assert.JSONEq(t, `{"unread_count":3}`, string(sender.messages[1].Payload))
+
+	unread = 2
+	require.NoError(t, svc.MarkAsRead(ctx, uuid.New(), userID))
+	require.Len(t, sender.messages, 3)
+	assert.JSONEq(t, `{"unread_count":2}`, string(sender.messages[2].Payload))
+
+	// Nothing changed, so nothing is published.
+	require.Error(t, svc.MarkAllAsRead(ctx, userID))
+	assert.Len(t, sender.messages, 3)
+
+	require.NoError(t, svc.Publish(ctx, userID, domain.EventOrderPaid, map[string]string{"status": "paid"}))
+	assert.Equal(t, domain.EventOrderPaid, sender.messages[3].Type)
+	for _, id := range sender.userIDs {
+		assert.Equal(t, userID, id)
+	}
+}
+
+type eventRepoStub struct {
+	appended []domain.Event
+	err      error
+}
+
+func (r *eventRepoStub) Append(_ context.Context, event *domain.Event) error {
+	if r.err != nil {
+		return r.err
+	}
+	event.Seq = int64(len(r.appended) + 1)
+	r.appended = append(r.appended, *event)
+	return nil
+}
+
+func (r *eventRepoStub) ListAfter(context.Context, uuid.UUID, int64, int) ([]domain.Event, error) {
+	return nil, nil
+}
+
+func (r *eventRepoStub) LastSeq(context.Context, uuid.UUID) (int64, error) { return 0, nil }
+
+func TestNotificationService_PublishStoresEventsInSequence(t *testing.T) {
+	userID := uuid.New()
+	events := &eventRepoStub{}
+	sender := &recordingSender{}
+	svc := NewNotificationService(notificationRepoMock{}, events, nil, sender, nil, EmailConfig{})
+	ctx := context.Background()
+
+	require.NoError(t, svc.Publish(ctx, userID, domain.EventOrderPaid, map[string]string{"status": "paid"}))
+	require.NoError(t, svc.Publish(ctx, userID, domain.EventLicenseIssued, map[string]string{"license_key": "LIC-1"}))
+	require.Len(t, events.appended, 2)
+	assert.Equal(t, userID, events.appended[0].UserID)
+	assert.JSONEq(t, `{"status":"paid"}`, string(events.appended[0].Payload))
+	assert.Equal(t, int64(1), sender.messages[0].Seq)
+	assert.Equal(t, int64(2), sender.messages[1].Seq)
+	assert.Equal(t, domain.EventLicenseIssued, sender.messages[1].Type)
+
+	// An event that could not be stored is not sent either.
+	events.err = errors.New("db down")
+	require.Error(t, svc.Publish(ctx, userID, domain.EventOrderPaid, nil))
+	assert.Len(t, sender.messages, 2)
+}
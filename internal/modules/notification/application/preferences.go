@@ -0,0 +1,69 @@
+package application
+
+import (
+	"context"
+	"log"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/saransh1220/blueprint-audio/internal/modules/notification/domain"
+)
+
+// Preferences returns the user's preference for every category, using the
+// defaults for categories they have not changed.
+func (s *NotificationService) Preferences(ctx context.Context, userID uuid.UUID) ([]domain.Preference, error) {
+	var stored []domain.Preference
+	if s.preferences != nil {
+		var err error
+		if stored, err = s.preferences.ListByUserID(ctx, userID); err != nil {
+			return nil, err
+		}
+	}
+	preferences := make([]domain.Preference, len(domain.Categories))
+	for i, category := range domain.Categories {
+		preferences[i] = domain.DefaultPreference(category)
+		for _, preference := range stored {
+			if preference.Category == category {
+				preferences[i] = preference
+			}
+		}
+	}
+	return preferences, nil
+}
+
+// UpdatePreferences saves the given categories' preferences, leaving the
+// others as they were, and returns the preferences for every category.
+func (s *NotificationService) UpdatePreferences(ctx context.Context, userID uuid.UUID, preferences []domain.Preference) ([]domain.Preference, error) {
+	seen := make([]domain.Category, 0, len(preferences))
+	for _, preference := range preferences {
+		if err := preference.Validate(); err != nil {
+			return nil, err
+		}
+		if slices.Contains(seen, preference.Category) {
+			return nil, domain.ErrDuplicateCategory
+		}
+		seen = append(seen, preference.Category)
+	}
+	if s.preferences != nil && len(preferences) > 0 {
+		if err := s.preferences.Upsert(ctx, userID, preferences); err != nil {
+			return nil, err
+		}
+	}
+	return s.Preferences(ctx, userID)
+}
+
+// preference returns how the user receives the category. The defaults apply
+// when the preferences cannot be read, so notifications are not lost.
+func (s *NotificationService) preference(ctx context.Context, userID uuid.UUID, category domain.Category) domain.Preference {
+	preferences, err := s.Preferences(ctx, userID)
+	if err != nil {
+		log.Printf("NotificationService.preference failed, using defaults. user_id=%s err=%v", userID, err)
+		return domain.DefaultPreference(category)
+	}
+	for _, preference := range preferences {
+		if preference.Category == category {
+			return preference
+		}
+	}
+	return domain.DefaultPreference(category)
+}
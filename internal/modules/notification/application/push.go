@@ -0,0 +1,112 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/saransh1220/blueprint-audio/internal/modules/notification/domain"
+)
+
+// pushPayload is what the frontend's service worker receives and shows.
+type pushPayload struct {
+	NotificationID uuid.UUID               `json:"notification_id"`
+	Category       domain.Category         `json:"category"`
+	Type           domain.NotificationType `json:"type"`
+	Title          string                  `json:"title"`
+	Body           string                  `json:"body"`
+	URL            string                  `json:"url"`
+}
+
+// pushMessageLimit keeps payloads well within one Web Push record.
+const pushMessageLimit = 1000
+
+// SetPush enables Web Push. Without it, nothing is pushed and subscribing
+// fails with domain.ErrPushDisabled.
+func (s *NotificationService) SetPush(subscriptions domain.PushSubscriptionRepository, sender domain.PushSender) {
+	s.pushSubscriptions = subscriptions
+	s.pushSender = sender
+}
+
+// PushPublicKey returns the VAPID public key browsers subscribe with.
+func (s *NotificationService) PushPublicKey() (string, error) {
+	if s.pushSender == nil {
+		return "", domain.ErrPushDisabled
+	}
+	return s.pushSender.PublicKey(), nil
+}
+
+// SubscribePush stores a browser's push subscription for the user. Their
+// in-app notifications are then pushed to that browser too.
+func (s *NotificationService) SubscribePush(ctx context.Context, subscription *domain.PushSubscription) error {
+	if s.pushSender == nil || s.pushSubscriptions == nil {
+		return domain.ErrPushDisabled
+	}
+	if err := subscription.Validate(); err != nil {
+		return err
+	}
+	id, err := uuid.NewV7()
+	if err != nil {
+		return fmt.Errorf("failed to generate uuid: %w", err)
+	}
+	subscription.ID = id
+	return s.pushSubscriptions.Upsert(ctx, subscription)
+}
+
+// UnsubscribePush removes one of the user's push subscriptions.
+func (s *NotificationService) UnsubscribePush(ctx context.Context, userID uuid.UUID, endpoint string) error {
+	if s.pushSubscriptions == nil {
+		return domain.ErrPushDisabled
+	}
+	return s.pushSubscriptions.Delete(ctx, userID, endpoint)
+}
+
+// pushNotification pushes a notification to every browser the user
+// subscribed, dropping subscriptions the push service reports gone.
+func (s *NotificationService) pushNotification(notification domain.Notification) {
+	ctx, cancel := context.WithTimeout(context.Background(), deliveryTimeout)
+	defer cancel()
+
+	subscriptions, err := s.pushSubscriptions.ListByUserID(ctx, notification.UserID)
+	if err != nil {
+		log.Printf("NotificationService.pushNotification list subscriptions failed. user_id=%s err=%v", notification.UserID, err)
+		return
+	}
+	if len(subscriptions) == 0 {
+		return
+	}
+
+	body := notification.Message
+	if len(body) > pushMessageLimit {
+		body = strings.ToValidUTF8(body[:pushMessageLimit], "") + "…"
+	}
+	payload, err := json.Marshal(pushPayload{
+		NotificationID: notification.ID,
+		Category:       notification.Category,
+		Type:           notification.Type,
+		Title:          notification.Title,
+		Body:           body,
+		URL:            "/notifications",
+	})
+	if err != nil {
+		log.Printf("NotificationService.pushNotification encode failed. notification_id=%s err=%v", notification.ID, err)
+		return
+	}
+
+	for _, subscription := range subscriptions {
+		err := s.pushSender.Send(ctx, subscription, payload)
+		if errors.Is(err, domain.ErrPushSubscriptionGone) {
+			if err := s.pushSubscriptions.DeleteByEndpoint(ctx, subscription.Endpoint); err != nil {
+				log.Printf("NotificationService.pushNotification remove subscription failed. subscription_id=%s err=%v", subscription.ID, err)
+			}
+			continue
+		}
+		if err != nil {
+			log.Printf("NotificationService.pushNotification failed. notification_id=%s subscription_id=%s err=%v", notification.ID, subscription.ID, err)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package application
+
+import (
+	"context"
+	"crypto/ecdh"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/saransh1220/blueprint-audio/internal/modules/notification/domain"
+	ws "github.com/saransh1220/blueprint-audio/internal/modules/notification/infrastructure/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type pushSubscriptionRepoStub struct {
+	mu      sync.Mutex
+	stored  []domain.PushSubscription
+	deleted chan string
+}
+
+func (s *pushSubscriptionRepoStub) Upsert(_ context.Context, subscription *domain.PushSubscription) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	subscription.CreatedAt = time.Now()
+	s.stored = append(s.stored, *subscription)
+	return nil
+}
+
+func (s *pushSubscriptionRepoStub) ListByUserID(_ context.Context, userID uuid.UUID) ([]domain.PushSubscription, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var subscriptions []domain.PushSubscription
+	for _, subscription := range s.stored {
+		if subscription.UserID == userID {
+			subscriptions = append(subscriptions, subscription)
+		}
+	}
+	return subscriptions, nil
+}
+
+func (s *pushSubscriptionRepoStub) Delete(_ context.Context, userID uuid.UUID, endpoint string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, subscription := range s.stored {
+		if subscription.UserID == userID && subscription.Endpoint == endpoint {
+			s.stored = append(s.stored[:i], s.stored[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+func (s *pushSubscriptionRepoStub) DeleteByEndpoint(_ context.Context, endpoint string) error {
+	s.deleted <- endpoint
+	return nil
+}
+
+// pushSenderStub hands pushed payloads to the test and reports the
+// endpoints in gone as expired.
+type pushSenderStub struct {
+	gone map[string]bool
+	sent chan pushPayload
+}
+
+func (s *pushSenderStub) Send(_ context.Context, subscription domain.PushSubscription, payload []byte) error {
+	if s.gone[subscription.Endpoint] {
+		return domain.ErrPushSubscriptionGone
+	}
+	var decoded pushPayload
+	if err := json.Unmarshal(payload, &decoded); err != nil {
+		return err
+	}
+	s.sent <- decoded
+	return nil
+}
+
+func (s *pushSenderStub) PublicKey() string { return "BPublicKey" }
+
+func newPushSubscription(t *testing.T, userID uuid.UUID, endpoint string) *domain.PushSubscription {
+	t.Helper()
+	key, err := ecdh.P256().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	auth := make([]byte, 16)
+	_, err = rand.Read(auth)
+	require.NoError(t, err)
+	return &domain.PushSubscription{
+		UserID:   userID,
+		Endpoint: endpoint,
+		P256dh:   base64.RawURLEncoding.EncodeToString(key.PublicKey().Bytes()),
+		Auth:     base64.RawURLEncoding.EncodeToString(auth),
+	}
+}
+
+func TestNotificationService_Push(t *testing.T) {
+	userID := uuid.New()
+	repo := notificationRepoMock{
+		createFn:      func(context.Context, *domain.Notification) error { return nil },
+		unreadCountFn: func(context.Context, uuid.UUID) (int, error) { return 1, nil },
+	}
+	svc := NewNotificationService(repo, nil, nil, ws.NewPublisher(nil), nil, EmailConfig{})
+	ctx := context.Background()
+
+	_, err := svc.PushPublicKey()
+	require.ErrorIs(t, err, domain.ErrPushDisabled)
+	require.ErrorIs(t, svc.SubscribePush(ctx, newPushSubscription(t, userID, "https://fcm.googleapis.com/fcm/send/a")), domain.ErrPushDisabled)
+
+	subscriptions := &pushSubscriptionRepoStub{deleted: make(chan string, 1)}
+	sender := &pushSenderStub{gone: map[string]bool{"https://fcm.googleapis.com/fcm/send/gone": true}, sent: make(chan pushPayload, 2)}
+	svc.SetPush(subscriptions, sender)
+
+	publicKey, err := svc.PushPublicKey()
+	require.NoError(t, err)
+	assert.Equal(t, "BPublicKey", publicKey)
+
+	invalid := newPushSubscription(t, userID, "http://push.example.com/a")
+	require.ErrorIs(t, svc.SubscribePush(ctx, invalid), domain.ErrInvalidPushSubscription)
+
+	active := newPushSubscription(t, userID, "https://fcm.googleapis.com/fcm/send/active")
+	require.NoError(t, svc.SubscribePush(ctx, active))
+	assert.Equal(t, uuid.Version(7), active.ID.Version())
+	require.NoError(t, svc.SubscribePush(ctx, newPushSubscription(t, userID, "https://fcm.googleapis.com/fcm/send/gone")))
+
+	require.NoError(t, svc.Create(ctx, userID, domain.CategorySaleMade, "New sale", "You sold Night Drive (INR 10.00).", domain.NotificationTypeSuccess))
+	select {
+	case payload := <-sender.sent:
+		assert.Equal(t, "New sale", payload.Title)
+		assert.Equal(t, "You sold Night Drive (INR 10.00).", payload.Body)
+		assert.Equal(t, domain.CategorySaleMade, payload.Category)
+		assert.Equal(t, "/notifications", payload.URL)
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected a push message")
+	}
+	select {
+	case endpoint := <-subscriptions.deleted:
+		assert.Equal(t, "https://fcm.googleapis.com/fcm/send/gone", endpoint)
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected the gone subscription to be removed")
+	}
+
+	require.NoError(t, svc.UnsubscribePush(ctx, userID, active.Endpoint))
+	remaining, err := subscriptions.ListByUserID(ctx, userID)
+	require.NoError(t, err)
+	require.Len(t, remaining, 1)
+	assert.Equal(t, "https://fcm.googleapis.com/fcm/send/gone", remaining[0].Endpoint)
+}
@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EventType names a realtime event sent over /ws. The part before the dot is
+// the topic clients subscribe to.
+type EventType string
+
+const (
+	EventNotificationCreated EventType = "notification.created"
+	EventUnreadCountChanged  EventType = "notification.unread_count"
+	EventUploadProgress      EventType = "upload.progress"
+	EventOrderPaid           EventType = "order.paid"
+	EventLicenseIssued       EventType = "license.issued"
+)
+
+// Topics clients can subscribe to. New connections are subscribed to
+// TopicNotification only.
+const (
+	TopicNotification = "notification"
+	TopicUpload       = "upload"
+	TopicOrder        = "order"
+	TopicLicense      = "license"
+)
+
+var Topics = []string{TopicNotification, TopicUpload, TopicOrder, TopicLicense}
+
+func (t EventType) Topic() string {
+	topic, _, _ := strings.Cut(string(t), ".")
+	return topic
+}
+
+// UnreadCountPayload is the payload of EventUnreadCountChanged.
+type UnreadCountPayload struct {
+	UnreadCount int `json:"unread_count"`
+}
+
+// EventRetention is how many of a user's latest events are kept for replay.
+const EventRetention = 1000
+
+// Event is a realtime event stored so a reconnecting client can replay what
+// it missed. Seq numbers each user's events from 1, without gaps.
+type Event struct {
+	UserID    uuid.UUID       `db:"user_id"`
+	Seq       int64           `db:"seq"`
+	Type      EventType       `db:"type"`
+	Payload   json.RawMessage `db:"payload"`
+	CreatedAt time.Time       `db:"created_at"`
+}
@@ -19,13 +19,19 @@ const (
 type Notification struct {
 	ID        uuid.UUID        `json:"id" db:"id"`
 	UserID    uuid.UUID        `json:"user_id" db:"user_id"`
+	Category  Category         `json:"category" db:"category"`
 	Title     string           `json:"title" db:"title"`
 	Message   string           `json:"message" db:"message"`
 	Type      NotificationType `json:"type" db:"type"`
 	IsRead    bool             `json:"is_read" db:"is_read"`
 	CreatedAt time.Time        `json:"created_at" db:"created_at"`
+	// DigestPending is set while the notification waits for the user's
+	// daily email digest.
+	DigestPending bool `json:"-" db:"digest_pending"`
 }
 
 var (
 	ErrNotificationNotFound = errors.New("notification not found")
+	ErrRecipientNotFound    = errors.New("recipient not found")
+	ErrInvalidAdminMessage  = errors.New("title (at most 255 characters) and message are required")
 )
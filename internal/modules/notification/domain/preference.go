@@ -0,0 +1,80 @@
+package domain
+
+import (
+	"errors"
+	"slices"
+)
+
+// Category is what a notification is about. Users choose per category how
+// notifications reach them.
+type Category string
+
+const (
+	CategorySaleMade        Category = "sale_made"
+	CategoryUploadProcessed Category = "upload_processed"
+	CategoryUploadFailed    Category = "upload_failed"
+	CategoryPriceDrop       Category = "price_drop"
+	CategoryAdminMessage    Category = "admin_message"
+	// CategoryAccount covers orders, refunds and payouts.
+	CategoryAccount Category = "account"
+)
+
+var Categories = []Category{
+	CategorySaleMade,
+	CategoryUploadProcessed,
+	CategoryUploadFailed,
+	CategoryPriceDrop,
+	CategoryAdminMessage,
+	CategoryAccount,
+}
+
+// EmailMode is how notifications of a category are emailed.
+type EmailMode string
+
+const (
+	EmailOff     EmailMode = "off"
+	EmailInstant EmailMode = "instant"
+	// EmailDailyDigest batches the category's unread notifications into one
+	// email a day.
+	EmailDailyDigest EmailMode = "daily_digest"
+)
+
+var (
+	ErrUnknownCategory   = errors.New("unknown notification category")
+	ErrInvalidEmailMode  = errors.New("email must be off, instant or daily_digest")
+	ErrDigestNeedsInApp  = errors.New("daily_digest summarises in-app notifications, so in_app must be on")
+	ErrDuplicateCategory = errors.New("each category can only be given once")
+)
+
+// Preference is how a user receives one category of notifications. In-app
+// notifications are stored and pushed to the user's open connections.
+type Preference struct {
+	Category Category  `json:"category" db:"category"`
+	InApp    bool      `json:"in_app" db:"in_app"`
+	Email    EmailMode `json:"email" db:"email"`
+}
+
+// DefaultPreference is the preference of a user who has not chosen one.
+func DefaultPreference(category Category) Preference {
+	email := EmailOff
+	switch category {
+	case CategorySaleMade, CategoryUploadFailed, CategoryAdminMessage, CategoryAccount:
+		email = EmailInstant
+	case CategoryPriceDrop:
+		email = EmailDailyDigest
+	}
+	return Preference{Category: category, InApp: true, Email: email}
+}
+
+func (p Preference) Validate() error {
+	if !slices.Contains(Categories, p.Category) {
+		return ErrUnknownCategory
+	}
+	if !slices.Contains([]EmailMode{EmailOff, EmailInstant, EmailDailyDigest}, p.Email) {
+		return ErrInvalidEmailMode
+	}
+	if p.Email == EmailDailyDigest && !p.InApp {
+		return ErrDigestNeedsInApp
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package domain
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidPushSubscription = errors.New("push subscription needs an https endpoint of a known push service, a P-256 p256dh key and a 16-byte auth secret")
+	// ErrPushSubscriptionTaken is returned when another user registered the
+	// endpoint with different keys.
+	ErrPushSubscriptionTaken = errors.New("push subscription belongs to another user")
+	// ErrPushSubscriptionGone is returned by push services for subscriptions
+	// the browser dropped. They are deleted.
+	ErrPushSubscriptionGone = errors.New("push subscription is gone")
+	ErrPushDisabled         = errors.New("web push is not configured")
+)
+
+// pushServiceHosts are the push services browsers subscribe with. The server
+// POSTs to the endpoint, so any other host is refused.
+var pushServiceHosts = []string{
+	"fcm.googleapis.com",
+	"android.googleapis.com",
+	"push.services.mozilla.com",
+	"push.apple.com",
+	"notify.windows.com",
+}
+
+// PushSubscription is a browser's Web Push subscription, as returned by
+// PushManager.subscribe().
+type PushSubscription struct {
+	ID        uuid.UUID `json:"id" db:"id"`
+	UserID    uuid.UUID `json:"-" db:"user_id"`
+	Endpoint  string    `json:"endpoint" db:"endpoint"`
+	P256dh    string    `json:"-" db:"p256dh"`
+	Auth      string    `json:"-" db:"auth"`
+	UserAgent string    `json:"user_agent" db:"user_agent"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// Validate checks the subscription can be pushed to.
+func (s PushSubscription) Validate() error {
+	endpoint, err := url.Parse(s.Endpoint)
+	if err != nil || endpoint.Scheme != "https" || endpoint.User != nil || endpoint.Port() != "" ||
+		!isPushServiceHost(endpoint.Hostname()) {
+		return ErrInvalidPushSubscription
+	}
+	key, err := DecodePushKey(s.P256dh)
+	if err != nil || len(key) != 65 || key[0] != 0x04 {
+		return ErrInvalidPushSubscription
+	}
+	auth, err := DecodePushKey(s.Auth)
+	if err != nil || len(auth) != 16 {
+		return ErrInvalidPushSubscription
+	}
+	return nil
+}
+
+// isPushServiceHost reports whether host is a known push service or one of
+// its subdomains.
+func isPushServiceHost(host string) bool {
+	host = strings.ToLower(host)
+	for _, service := range pushServiceHosts {
+		if host == service || strings.HasSuffix(host, "."+service) {
+			return true
+		}
+	}
+	return false
+}
+
+// DecodePushKey decodes a base64url key, with or without padding.
+func DecodePushKey(key string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(key, "="))
+}
+
+type PushSubscriptionRepository interface {
+	// Upsert stores the subscription. An endpoint registered by someone else
+	// is only moved to the user when the keys match, which only the browser
+	// holding the subscription knows; otherwise ErrPushSubscriptionTaken.
+	Upsert(ctx context.Context, subscription *PushSubscription) error
+	ListByUserID(ctx context.Context, userID uuid.UUID) ([]PushSubscription, error)
+	// Delete removes the user's subscription with the endpoint.
+	Delete(ctx context.Context, userID uuid.UUID, endpoint string) error
+	// DeleteByEndpoint removes a subscription the push service reported gone.
+	DeleteByEndpoint(ctx context.Context, endpoint string) error
+}
+
+// PushSender delivers an encrypted Web Push message to a subscription.
+type PushSender interface {
+	Send(ctx context.Context, subscription PushSubscription, payload []byte) error
+	// PublicKey is the VAPID public key browsers subscribe with.
+	PublicKey() string
+}
@@ -2,6 +2,7 @@ package domain
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -12,4 +13,32 @@ type NotificationRepository interface {
 	MarkAsRead(ctx context.Context, notificationID, userID uuid.UUID) error
 	MarkAllAsRead(ctx context.Context, userID uuid.UUID) error
 	UnreadCount(ctx context.Context, userID uuid.UUID) (int, error)
+	// ListDigestRecipients returns up to limit users with notifications
+	// pending a digest since before the given time.
+	ListDigestRecipients(ctx context.Context, pendingSince time.Time, limit int) ([]uuid.UUID, error)
+	// ClaimDigest clears the user's pending digest notifications and returns
+	// them, oldest first. Concurrent callers never claim the same ones.
+	ClaimDigest(ctx context.Context, userID uuid.UUID) ([]Notification, error)
+	// ReleaseDigest marks claimed notifications pending again, after the
+	// digest could not be sent.
+	ReleaseDigest(ctx context.Context, notificationIDs []uuid.UUID) error
+}
+
+// PreferenceRepository stores the preferences users changed from the
+// defaults.
+type PreferenceRepository interface {
+	ListByUserID(ctx context.Context, userID uuid.UUID) ([]Preference, error)
+	Upsert(ctx context.Context, userID uuid.UUID, preferences []Preference) error
+}
+
+// EventRepository stores each user's realtime events in sequence.
+type EventRepository interface {
+	// Append assigns the event the user's next seq and stores it, pruning
+	// events beyond EventRetention.
+	Append(ctx context.Context, event *Event) error
+	// ListAfter returns up to limit of the user's events after seq, oldest
+	// first.
+	ListAfter(ctx context.Context, userID uuid.UUID, afterSeq int64, limit int) ([]Event, error)
+	// LastSeq returns the seq of the user's latest event, or 0.
+	LastSeq(ctx context.Context, userID uuid.UUID) (int64, error)
 }
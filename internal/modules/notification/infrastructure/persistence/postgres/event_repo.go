@@ -0,0 +1,60 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/saransh1220/blueprint-audio/internal/modules/notification/domain"
+)
+
+type PgEventRepository struct {
+	db *sqlx.DB
+}
+
+func NewPgEventRepository(db *sqlx.DB) *PgEventRepository {
+	return &PgEventRepository{db: db}
+}
+
+func (r *PgEventRepository) Append(ctx context.Context, event *domain.Event) error {
+	query := `
+		WITH next AS (
+			INSERT INTO user_event_sequences (user_id, last_seq)
+			VALUES ($1, 1)
+			ON CONFLICT (user_id) DO UPDATE SET last_seq = user_event_sequences.last_seq + 1
+			RETURNING last_seq
+		), pruned AS (
+			DELETE FROM user_events
+			WHERE user_id = $1 AND seq <= (SELECT last_seq FROM next) - $4
+		)
+		INSERT INTO user_events (user_id, seq, type, payload)
+		SELECT $1, last_seq, $2, $3 FROM next
+		RETURNING seq, created_at
+	`
+	row := r.db.QueryRowxContext(ctx, query, event.UserID, event.Type, []byte(event.Payload), domain.EventRetention)
+	return row.Scan(&event.Seq, &event.CreatedAt)
+}
+
+func (r *PgEventRepository) ListAfter(ctx context.Context, userID uuid.UUID, afterSeq int64, limit int) ([]domain.Event, error) {
+	query := `
+		SELECT user_id, seq, type, payload, created_at FROM user_events
+		WHERE user_id = $1 AND seq > $2
+		ORDER BY seq
+		LIMIT $3
+	`
+	var events []domain.Event
+	if err := r.db.SelectContext(ctx, &events, query, userID, afterSeq, limit); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+func (r *PgEventRepository) LastSeq(ctx context.Context, userID uuid.UUID) (int64, error) {
+	query := `
+		SELECT COALESCE(MAX(last_seq), 0) FROM user_event_sequences
+		WHERE user_id = $1
+	`
+	var seq int64
+	err := r.db.GetContext(ctx, &seq, query, userID)
+	return seq, err
+}
@@ -0,0 +1,54 @@
+package postgres_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/saransh1220/blueprint-audio/internal/modules/notification/domain"
+	"github.com/saransh1220/blueprint-audio/internal/modules/notification/infrastructure/persistence/postgres"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPgEventRepository(t *testing.T) {
+	db, mock, cleanup := newMockDB(t)
+	defer cleanup()
+
+	repo := postgres.NewPgEventRepository(db)
+	ctx := context.Background()
+	userID := uuid.New()
+	now := time.Now()
+
+	event := &domain.Event{UserID: userID, Type: domain.EventOrderPaid, Payload: json.RawMessage(`{"status":"paid"}`)}
+	mock.ExpectQuery(`INSERT INTO user_event_sequences(.|\n)*DELETE FROM user_events(.|\n)*INSERT INTO user_events`).
+		WithArgs(userID, domain.EventOrderPaid, []byte(`{"status":"paid"}`), domain.EventRetention).
+		WillReturnRows(sqlmock.NewRows([]string{"seq", "created_at"}).AddRow(int64(7), now))
+	require.NoError(t, repo.Append(ctx, event))
+	assert.Equal(t, int64(7), event.Seq)
+	assert.Equal(t, now, event.CreatedAt)
+
+	mock.ExpectQuery(`SELECT (.+) FROM user_events`).
+		WithArgs(userID, int64(5), 10).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "seq", "type", "payload", "created_at"}).
+			AddRow(userID, int64(6), "notification.unread_count", []byte(`{"unread_count":1}`), now).
+			AddRow(userID, int64(7), "order.paid", []byte(`{"status":"paid"}`), now))
+	events, err := repo.ListAfter(ctx, userID, 5, 10)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.Equal(t, domain.EventUnreadCountChanged, events[0].Type)
+	assert.Equal(t, int64(7), events[1].Seq)
+	assert.JSONEq(t, `{"status":"paid"}`, string(events[1].Payload))
+
+	mock.ExpectQuery(`SELECT COALESCE\(MAX\(last_seq\), 0\) FROM user_event_sequences`).
+		WithArgs(userID).
+		WillReturnRows(sqlmock.NewRows([]string{"coalesce"}).AddRow(int64(7)))
+	seq, err := repo.LastSeq(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), seq)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -2,10 +2,12 @@ package postgres
 
 import (
 	"context"
+	"slices"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/saransh1220/blueprint-audio/internal/modules/notification/domain"
 )
 
@@ -22,8 +24,8 @@ func (r *PgNotificationRepository) Create(ctx context.Context, n *domain.Notific
 		n.CreatedAt = time.Now()
 	}
 	query := `
-		INSERT INTO notifications (id, user_id, title, message, type, is_read, created_at)
-		VALUES (:id, :user_id, :title, :message, :type, :is_read, :created_at)
+		INSERT INTO notifications (id, user_id, category, title, message, type, is_read, created_at, digest_pending)
+		VALUES (:id, :user_id, :category, :title, :message, :type, :is_read, :created_at, :digest_pending)
 	`
 	_, err := r.db.NamedExecContext(ctx, query, n)
 	return err
@@ -84,3 +86,50 @@ func (r *PgNotificationRepository) UnreadCount(ctx context.Context, userID uuid.
 	err := r.db.GetContext(ctx, &count, query, userID)
 	return count, err
 }
+
+func (r *PgNotificationRepository) ListDigestRecipients(ctx context.Context, pendingSince time.Time, limit int) ([]uuid.UUID, error) {
+	query := `
+		SELECT user_id FROM notifications
+		WHERE digest_pending
+		GROUP BY user_id
+		HAVING MIN(created_at) <= $1
+		ORDER BY MIN(created_at)
+		LIMIT $2
+	`
+	var userIDs []uuid.UUID
+	if err := r.db.SelectContext(ctx, &userIDs, query, pendingSince, limit); err != nil {
+		return nil, err
+	}
+	return userIDs, nil
+}
+
+func (r *PgNotificationRepository) ClaimDigest(ctx context.Context, userID uuid.UUID) ([]domain.Notification, error) {
+	query := `
+		UPDATE notifications
+		SET digest_pending = FALSE
+		WHERE user_id = $1 AND digest_pending
+		RETURNING *
+	`
+	var notifications []domain.Notification
+	if err := r.db.SelectContext(ctx, &notifications, query, userID); err != nil {
+		return nil, err
+	}
+	slices.SortFunc(notifications, func(a, b domain.Notification) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+	return notifications, nil
+}
+
+func (r *PgNotificationRepository) ReleaseDigest(ctx context.Context, notificationIDs []uuid.UUID) error {
+	ids := make([]string, len(notificationIDs))
+	for i, id := range notificationIDs {
+		ids[i] = id.String()
+	}
+	query := `
+		UPDATE notifications
+		SET digest_pending = TRUE
+		WHERE id = ANY($1::uuid[])
+	`
+	_, err := r.db.ExecContext(ctx, query, pq.Array(ids))
+	return err
+}
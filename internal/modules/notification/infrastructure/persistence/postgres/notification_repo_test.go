@@ -179,3 +179,40 @@ func TestPgNotificationRepository_MarkAllAsRead_Error(t *testing.T) {
 	require.EqualError(t, err, "exec fail")
 	require.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestPgNotificationRepository_DigestClaims(t *testing.T) {
+	db, mock, cleanup := newMockDB(t)
+	defer cleanup()
+
+	repo := postgres.NewPgNotificationRepository(db)
+	ctx := context.Background()
+	userID := uuid.New()
+	pendingSince := time.Now().Add(-24 * time.Hour)
+
+	mock.ExpectQuery(`SELECT user_id FROM notifications\s+WHERE digest_pending`).
+		WithArgs(pendingSince, 100).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(userID))
+	recipients, err := repo.ListDigestRecipients(ctx, pendingSince, 100)
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{userID}, recipients)
+
+	older, newer := uuid.New(), uuid.New()
+	now := time.Now()
+	mock.ExpectQuery(`UPDATE notifications\s+SET digest_pending = FALSE`).
+		WithArgs(userID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "category", "title", "message", "type", "is_read", "created_at", "digest_pending"}).
+			AddRow(newer, userID, "account", "B", "b", "info", false, now, false).
+			AddRow(older, userID, "price_drop", "A", "a", "info", false, now.Add(-time.Hour), false))
+	claimed, err := repo.ClaimDigest(ctx, userID)
+	require.NoError(t, err)
+	require.Len(t, claimed, 2)
+	assert.Equal(t, older, claimed[0].ID)
+	assert.Equal(t, domain.CategoryPriceDrop, claimed[0].Category)
+
+	mock.ExpectExec(`UPDATE notifications\s+SET digest_pending = TRUE`).
+		WithArgs(sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	require.NoError(t, repo.ReleaseDigest(ctx, []uuid.UUID{older, newer}))
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
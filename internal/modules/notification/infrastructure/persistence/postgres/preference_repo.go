@@ -0,0 +1,50 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/saransh1220/blueprint-audio/internal/modules/notification/domain"
+)
+
+type PgPreferenceRepository struct {
+	db *sqlx.DB
+}
+
+func NewPgPreferenceRepository(db *sqlx.DB) *PgPreferenceRepository {
+	return &PgPreferenceRepository{db: db}
+}
+
+func (r *PgPreferenceRepository) ListByUserID(ctx context.Context, userID uuid.UUID) ([]domain.Preference, error) {
+	query := `
+		SELECT category, in_app, email FROM notification_preferences
+		WHERE user_id = $1
+	`
+	var preferences []domain.Preference
+	if err := r.db.SelectContext(ctx, &preferences, query, userID); err != nil {
+		return nil, err
+	}
+	return preferences, nil
+}
+
+func (r *PgPreferenceRepository) Upsert(ctx context.Context, userID uuid.UUID, preferences []domain.Preference) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO notification_preferences (user_id, category, in_app, email, updated_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		ON CONFLICT (user_id, category) DO UPDATE
+		SET in_app = EXCLUDED.in_app, email = EXCLUDED.email, updated_at = NOW()
+	`
+	for _, preference := range preferences {
+		if _, err := tx.ExecContext(ctx, query, userID, preference.Category, preference.InApp, preference.Email); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
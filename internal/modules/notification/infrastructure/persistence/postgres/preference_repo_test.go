@@ -0,0 +1,51 @@
+package postgres_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/saransh1220/blueprint-audio/internal/modules/notification/domain"
+	"github.com/saransh1220/blueprint-audio/internal/modules/notification/infrastructure/persistence/postgres"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPgPreferenceRepository_ListAndUpsert(t *testing.T) {
+	db, mock, cleanup := newMockDB(t)
+	defer cleanup()
+
+	repo := postgres.NewPgPreferenceRepository(db)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	mock.ExpectQuery(`SELECT category, in_app, email FROM notification_preferences`).
+		WithArgs(userID).
+		WillReturnRows(sqlmock.NewRows([]string{"category", "in_app", "email"}).
+			AddRow("price_drop", true, "off"))
+	preferences, err := repo.ListByUserID(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.Preference{{Category: domain.CategoryPriceDrop, InApp: true, Email: domain.EmailOff}}, preferences)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`INSERT INTO notification_preferences`).
+		WithArgs(userID, domain.CategorySaleMade, true, domain.EmailDailyDigest).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO notification_preferences`).
+		WithArgs(userID, domain.CategoryAdminMessage, false, domain.EmailInstant).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	require.NoError(t, repo.Upsert(ctx, userID, []domain.Preference{
+		{Category: domain.CategorySaleMade, InApp: true, Email: domain.EmailDailyDigest},
+		{Category: domain.CategoryAdminMessage, InApp: false, Email: domain.EmailInstant},
+	}))
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`INSERT INTO notification_preferences`).WillReturnError(errors.New("db"))
+	mock.ExpectRollback()
+	require.Error(t, repo.Upsert(ctx, userID, []domain.Preference{{Category: domain.CategorySaleMade, InApp: true, Email: domain.EmailOff}}))
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,67 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/saransh1220/blueprint-audio/internal/modules/notification/domain"
+)
+
+type PgPushSubscriptionRepository struct {
+	db *sqlx.DB
+}
+
+func NewPgPushSubscriptionRepository(db *sqlx.DB) *PgPushSubscriptionRepository {
+	return &PgPushSubscriptionRepository{db: db}
+}
+
+func (r *PgPushSubscriptionRepository) Upsert(ctx context.Context, subscription *domain.PushSubscription) error {
+	query := `
+		INSERT INTO push_subscriptions (id, user_id, endpoint, p256dh, auth, user_agent)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (endpoint) DO UPDATE
+		SET user_id = EXCLUDED.user_id, p256dh = EXCLUDED.p256dh, auth = EXCLUDED.auth,
+			user_agent = EXCLUDED.user_agent, updated_at = NOW()
+		WHERE push_subscriptions.user_id = EXCLUDED.user_id
+			OR (push_subscriptions.p256dh = EXCLUDED.p256dh AND push_subscriptions.auth = EXCLUDED.auth)
+		RETURNING id, created_at
+	`
+	row := r.db.QueryRowxContext(ctx, query,
+		subscription.ID, subscription.UserID, subscription.Endpoint,
+		subscription.P256dh, subscription.Auth, subscription.UserAgent,
+	)
+	err := row.Scan(&subscription.ID, &subscription.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.ErrPushSubscriptionTaken
+	}
+	return err
+}
+
+func (r *PgPushSubscriptionRepository) ListByUserID(ctx context.Context, userID uuid.UUID) ([]domain.PushSubscription, error) {
+	query := `
+		SELECT id, user_id, endpoint, p256dh, auth, user_agent, created_at
+		FROM push_subscriptions
+		WHERE user_id = $1
+		ORDER BY created_at
+	`
+	var subscriptions []domain.PushSubscription
+	if err := r.db.SelectContext(ctx, &subscriptions, query, userID); err != nil {
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+func (r *PgPushSubscriptionRepository) Delete(ctx context.Context, userID uuid.UUID, endpoint string) error {
+	query := `DELETE FROM push_subscriptions WHERE user_id = $1 AND endpoint = $2`
+	_, err := r.db.ExecContext(ctx, query, userID, endpoint)
+	return err
+}
+
+func (r *PgPushSubscriptionRepository) DeleteByEndpoint(ctx context.Context, endpoint string) error {
+	query := `DELETE FROM push_subscriptions WHERE endpoint = $1`
+	_, err := r.db.ExecContext(ctx, query, endpoint)
+	return err
+}
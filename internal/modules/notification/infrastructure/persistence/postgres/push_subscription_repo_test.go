@@ -0,0 +1,68 @@
+package postgres_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/saransh1220/blueprint-audio/internal/modules/notification/domain"
+	"github.com/saransh1220/blueprint-audio/internal/modules/notification/infrastructure/persistence/postgres"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPgPushSubscriptionRepository(t *testing.T) {
+	db, mock, cleanup := newMockDB(t)
+	defer cleanup()
+
+	repo := postgres.NewPgPushSubscriptionRepository(db)
+	ctx := context.Background()
+	userID := uuid.New()
+	existingID := uuid.New()
+	createdAt := time.Now().Add(-time.Hour)
+
+	// Subscribing an endpoint again keeps its original id.
+	subscription := &domain.PushSubscription{
+		ID: uuid.New(), UserID: userID, Endpoint: "https://fcm.googleapis.com/fcm/send/1",
+		P256dh: "key", Auth: "auth", UserAgent: "Firefox",
+	}
+	mock.ExpectQuery(`INSERT INTO push_subscriptions .* ON CONFLICT \(endpoint\) DO UPDATE`).
+		WithArgs(subscription.ID, userID, subscription.Endpoint, "key", "auth", "Firefox").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(existingID, createdAt))
+	require.NoError(t, repo.Upsert(ctx, subscription))
+	assert.Equal(t, existingID, subscription.ID)
+	assert.Equal(t, createdAt, subscription.CreatedAt)
+
+	// Another user's endpoint is not moved over without its keys.
+	taken := &domain.PushSubscription{
+		ID: uuid.New(), UserID: uuid.New(), Endpoint: subscription.Endpoint,
+		P256dh: "other-key", Auth: "other-auth", UserAgent: "Chrome",
+	}
+	mock.ExpectQuery(`ON CONFLICT \(endpoint\) DO UPDATE .* WHERE push_subscriptions.user_id = EXCLUDED.user_id`).
+		WithArgs(taken.ID, taken.UserID, taken.Endpoint, "other-key", "other-auth", "Chrome").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}))
+	assert.ErrorIs(t, repo.Upsert(ctx, taken), domain.ErrPushSubscriptionTaken)
+
+	mock.ExpectQuery(`SELECT id, user_id, endpoint, p256dh, auth, user_agent, created_at FROM push_subscriptions`).
+		WithArgs(userID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "endpoint", "p256dh", "auth", "user_agent", "created_at"}).
+			AddRow(existingID, userID, subscription.Endpoint, "key", "auth", "Firefox", createdAt))
+	subscriptions, err := repo.ListByUserID(ctx, userID)
+	require.NoError(t, err)
+	require.Len(t, subscriptions, 1)
+	assert.Equal(t, *subscription, subscriptions[0])
+
+	mock.ExpectExec(`DELETE FROM push_subscriptions WHERE user_id = \$1 AND endpoint = \$2`).
+		WithArgs(userID, subscription.Endpoint).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	require.NoError(t, repo.Delete(ctx, userID, subscription.Endpoint))
+
+	mock.ExpectExec(`DELETE FROM push_subscriptions WHERE endpoint = \$1`).
+		WithArgs(subscription.Endpoint).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	require.NoError(t, repo.DeleteByEndpoint(ctx, subscription.Endpoint))
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,234 @@
+// Package webpush sends Web Push messages to browsers, encrypted as RFC 8291
+// requires and authenticated with VAPID (RFC 8292).
+package webpush
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/saransh1220/blueprint-audio/internal/modules/notification/domain"
+)
+
+const (
+	// recordSize is the aes128gcm record size. Messages are sent as a single
+	// record, which push services accept up to 4096 bytes.
+	recordSize = 4096
+	// headerSize is the aes128gcm header: salt, record size, key id length
+	// and the 65-byte server public key.
+	headerSize = 16 + 4 + 1 + 65
+	// MaxPayload is the largest payload that fits one record.
+	MaxPayload = recordSize - headerSize - 16 - 1
+
+	// ttl is how long push services keep a message for an offline browser.
+	ttl = 24 * time.Hour
+	// tokenLifetime is how long VAPID tokens are valid; RFC 8292 allows up
+	// to 24 hours.
+	tokenLifetime = 12 * time.Hour
+)
+
+var ErrPayloadTooLarge = fmt.Errorf("push payload exceeds %d bytes", MaxPayload)
+
+// Config holds the VAPID key pair, base64url encoded as generated by
+// `npx web-push generate-vapid-keys`, and the contact URL push services can
+// use to reach the sender (mailto: or https:).
+type Config struct {
+	PublicKey  string
+	PrivateKey string
+	Subject    string
+}
+
+// Enabled reports whether a key pair is configured.
+func (c Config) Enabled() bool {
+	return strings.TrimSpace(c.PrivateKey) != ""
+}
+
+type Sender struct {
+	client     *http.Client
+	privateKey *ecdsa.PrivateKey
+	publicKey  string
+	subject    string
+	now        func() time.Time
+}
+
+// NewSender parses the VAPID keys. A nil client uses one with a 10s timeout.
+func NewSender(cfg Config, client *http.Client) (*Sender, error) {
+	raw, err := domain.DecodePushKey(strings.TrimSpace(cfg.PrivateKey))
+	if err != nil {
+		return nil, fmt.Errorf("decode VAPID private key: %w", err)
+	}
+	privateKey, err := ecdsa.ParseRawPrivateKey(elliptic.P256(), raw)
+	if err != nil {
+		return nil, fmt.Errorf("parse VAPID private key: %w", err)
+	}
+	publicKey, err := privateKey.PublicKey.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	encodedPublicKey := base64.RawURLEncoding.EncodeToString(publicKey)
+	if configured := strings.TrimRight(strings.TrimSpace(cfg.PublicKey), "="); configured != "" && configured != encodedPublicKey {
+		return nil, errors.New("VAPID public key does not match the private key")
+	}
+	subject := strings.TrimSpace(cfg.Subject)
+	if !strings.HasPrefix(subject, "mailto:") && !strings.HasPrefix(subject, "https://") {
+		return nil, errors.New("VAPID subject must be a mailto: or https: URL")
+	}
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &Sender{
+		client:     client,
+		privateKey: privateKey,
+		publicKey:  encodedPublicKey,
+		subject:    subject,
+		now:        time.Now,
+	}, nil
+}
+
+func (s *Sender) PublicKey() string {
+	return s.publicKey
+}
+
+// Send encrypts the payload for the subscription and posts it to the
+// subscription's push service. Subscriptions the service no longer knows
+// return domain.ErrPushSubscriptionGone.
+func (s *Sender) Send(ctx context.Context, subscription domain.PushSubscription, payload []byte) error {
+	if len(payload) > MaxPayload {
+		return ErrPayloadTooLarge
+	}
+	userPublicKey, err := domain.DecodePushKey(subscription.P256dh)
+	if err != nil {
+		return fmt.Errorf("decode p256dh: %w", err)
+	}
+	authSecret, err := domain.DecodePushKey(subscription.Auth)
+	if err != nil {
+		return fmt.Errorf("decode auth: %w", err)
+	}
+	body, err := encrypt(rand.Reader, payload, userPublicKey, authSecret)
+	if err != nil {
+		return err
+	}
+	authorization, err := s.authorization(subscription.Endpoint)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", authorization)
+	req.Header.Set("Content-Encoding", "aes128gcm")
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("TTL", strconv.Itoa(int(ttl.Seconds())))
+	req.Header.Set("Urgency", "normal")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("push request: %w", err)
+	}
+	defer resp.Body.Close()
+	detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return domain.ErrPushSubscriptionGone
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		return fmt.Errorf("push service returned %d: %s", resp.StatusCode, strings.TrimSpace(string(detail)))
+	}
+	return nil
+}
+
+// authorization builds the VAPID header for the endpoint's push service.
+func (s *Sender) authorization(endpoint string) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return "", fmt.Errorf("invalid push endpoint %q", endpoint)
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"aud": u.Scheme + "://" + u.Host,
+		"exp": s.now().Add(tokenLifetime).Unix(),
+		"sub": s.subject,
+	})
+	signed, err := token.SignedString(s.privateKey)
+	if err != nil {
+		return "", fmt.Errorf("sign VAPID token: %w", err)
+	}
+	return fmt.Sprintf("vapid t=%s, k=%s", signed, s.publicKey), nil
+}
+
+// encrypt encodes the payload as a single aes128gcm record (RFC 8188) keyed
+// for the browser as RFC 8291 describes, with a fresh server key and salt.
+func encrypt(random io.Reader, payload, userPublicKey, authSecret []byte) ([]byte, error) {
+	serverKey, err := ecdh.P256().GenerateKey(random)
+	if err != nil {
+		return nil, err
+	}
+	salt := make([]byte, 16)
+	if _, err := io.ReadFull(random, salt); err != nil {
+		return nil, err
+	}
+	return encryptWith(payload, userPublicKey, authSecret, serverKey, salt)
+}
+
+func encryptWith(payload, userPublicKey, authSecret []byte, serverKey *ecdh.PrivateKey, salt []byte) ([]byte, error) {
+	userKey, err := ecdh.P256().NewPublicKey(userPublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("parse p256dh: %w", err)
+	}
+	sharedSecret, err := serverKey.ECDH(userKey)
+	if err != nil {
+		return nil, err
+	}
+	serverPublicKey := serverKey.PublicKey().Bytes()
+
+	keyInfo := "WebPush: info\x00" + string(userPublicKey) + string(serverPublicKey)
+	ikm, err := hkdf.Key(sha256.New, sharedSecret, authSecret, keyInfo, 32)
+	if err != nil {
+		return nil, err
+	}
+	contentKey, err := hkdf.Key(sha256.New, ikm, salt, "Content-Encoding: aes128gcm\x00", 16)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := hkdf.Key(sha256.New, ikm, salt, "Content-Encoding: nonce\x00", 12)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(contentKey)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	body := make([]byte, headerSize, headerSize+len(payload)+1+gcm.Overhead())
+	copy(body, salt)
+	binary.BigEndian.PutUint32(body[16:20], recordSize)
+	body[20] = byte(len(serverPublicKey))
+	copy(body[21:], serverPublicKey)
+	// 0x02 marks the last (and only) record.
+	plaintext := append(append([]byte{}, payload...), 0x02)
+	return gcm.Seal(body, nonce, plaintext, nil), nil
+}
@@ -0,0 +1,203 @@
+package webpush
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/saransh1220/blueprint-audio/internal/modules/notification/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func b64(t *testing.T, s string) []byte {
+	t.Helper()
+	out, err := domain.DecodePushKey(s)
+	require.NoError(t, err)
+	return out
+}
+
+// The example in RFC 8291 section 5.
+func TestEncryptMatchesRFC8291Example(t *testing.T) {
+	serverKey, err := ecdh.P256().NewPrivateKey(b64(t, "yfWPiYE-n46HLnH0KqZOF1fJJU3MYrct3AELtAQ-oRw"))
+	require.NoError(t, err)
+
+	body, err := encryptWith(
+		[]byte("When I grow up, I want to be a watermelon"),
+		b64(t, "BCVxsr7N_eNgVRqvHtD0zTZsEc6-VV-JvLexhqUzORcxaOzi6-AYWXvTBHm4bjyPjs7Vd8pZGH6SRpkNtoIAiw4"),
+		b64(t, "BTBZMqHH6r4Tts7J_aSIgg"),
+		serverKey,
+		b64(t, "DGv6ra1nlYgDCS1FRnbzlw"),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, "DGv6ra1nlYgDCS1FRnbzlwAAEABBBP4z9KsN6nGRTbVYI_c7VJSPQTBtkgcy27mlmlMoZIIgDll6e3vCYLocInmYWAmS6TlzAC8wEqKK6PBru3jl7A_yl95bQpu6cVPTpK4Mqgkf1CXztLVBSt2Ks3oZwbuwXPXLWyouBWLVWGNWQexSgSxsj_Qulcy4a-fN",
+		base64.RawURLEncoding.EncodeToString(body))
+}
+
+// browser holds a subscription's keys and decrypts messages sent to it, as
+// the browser would.
+type browser struct {
+	key  *ecdh.PrivateKey
+	auth []byte
+}
+
+func newBrowser(t *testing.T) *browser {
+	t.Helper()
+	key, err := ecdh.P256().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	auth := make([]byte, 16)
+	_, err = rand.Read(auth)
+	require.NoError(t, err)
+	return &browser{key: key, auth: auth}
+}
+
+func (b *browser) subscription(endpoint string) domain.PushSubscription {
+	return domain.PushSubscription{
+		Endpoint: endpoint,
+		P256dh:   base64.RawURLEncoding.EncodeToString(b.key.PublicKey().Bytes()),
+		Auth:     base64.RawURLEncoding.EncodeToString(b.auth),
+	}
+}
+
+func (b *browser) decrypt(t *testing.T, body []byte) []byte {
+	t.Helper()
+	require.Greater(t, len(body), headerSize)
+	salt := body[:16]
+	assert.Equal(t, uint32(recordSize), binary.BigEndian.Uint32(body[16:20]))
+	require.Equal(t, byte(65), body[20])
+	serverKey, err := ecdh.P256().NewPublicKey(body[21:headerSize])
+	require.NoError(t, err)
+	secret, err := b.key.ECDH(serverKey)
+	require.NoError(t, err)
+
+	info := "WebPush: info\x00" + string(b.key.PublicKey().Bytes()) + string(serverKey.Bytes())
+	ikm, err := hkdf.Key(sha256.New, secret, b.auth, info, 32)
+	require.NoError(t, err)
+	cek, err := hkdf.Key(sha256.New, ikm, salt, "Content-Encoding: aes128gcm\x00", 16)
+	require.NoError(t, err)
+	nonce, err := hkdf.Key(sha256.New, ikm, salt, "Content-Encoding: nonce\x00", 12)
+	require.NoError(t, err)
+	block, err := aes.NewCipher(cek)
+	require.NoError(t, err)
+	gcm, err := cipher.NewGCM(block)
+	require.NoError(t, err)
+	plaintext, err := gcm.Open(nil, nonce, body[headerSize:], nil)
+	require.NoError(t, err)
+	require.Equal(t, byte(0x02), plaintext[len(plaintext)-1])
+	return plaintext[:len(plaintext)-1]
+}
+
+func newTestSender(t *testing.T, client *http.Client) *Sender {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	raw, err := key.Bytes()
+	require.NoError(t, err)
+	sender, err := NewSender(Config{
+		PrivateKey: base64.RawURLEncoding.EncodeToString(raw),
+		Subject:    "mailto:ops@example.com",
+	}, client)
+	require.NoError(t, err)
+	return sender
+}
+
+func TestSender_SendsEncryptedMessageWithVAPID(t *testing.T) {
+	b := newBrowser(t)
+	var (
+		received []byte
+		header   http.Header
+	)
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received, _ = io.ReadAll(r.Body)
+		header = r.Header.Clone()
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+	sender := newTestSender(t, server.Client())
+
+	require.NoError(t, sender.Send(context.Background(), b.subscription(server.URL+"/push/abc"), []byte(`{"title":"New sale"}`)))
+	assert.Equal(t, `{"title":"New sale"}`, string(b.decrypt(t, received)))
+	assert.Equal(t, "aes128gcm", header.Get("Content-Encoding"))
+	assert.Equal(t, "86400", header.Get("TTL"))
+
+	// The VAPID token is signed by the key the header advertises, for the
+	// push service's origin.
+	authorization := header.Get("Authorization")
+	require.True(t, strings.HasPrefix(authorization, "vapid t="))
+	fields := strings.Split(strings.TrimPrefix(authorization, "vapid "), ", ")
+	require.Len(t, fields, 2)
+	token := strings.TrimPrefix(fields[0], "t=")
+	publicKey := strings.TrimPrefix(fields[1], "k=")
+	assert.Equal(t, sender.PublicKey(), publicKey)
+	verifyKey, err := ecdsa.ParseUncompressedPublicKey(elliptic.P256(), b64(t, publicKey))
+	require.NoError(t, err)
+	claims := jwt.MapClaims{}
+	_, err = jwt.ParseWithClaims(token, claims, func(*jwt.Token) (any, error) { return verifyKey, nil },
+		jwt.WithValidMethods([]string{"ES256"}), jwt.WithAudience(server.URL))
+	require.NoError(t, err)
+	assert.Equal(t, "mailto:ops@example.com", claims["sub"])
+	exp, err := claims.GetExpirationTime()
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(tokenLifetime), exp.Time, time.Minute)
+}
+
+func TestSender_ReportsGoneSubscriptions(t *testing.T) {
+	for _, status := range []int{http.StatusNotFound, http.StatusGone} {
+		server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(status)
+		}))
+		sender := newTestSender(t, server.Client())
+		err := sender.Send(context.Background(), newBrowser(t).subscription(server.URL), []byte("x"))
+		assert.ErrorIs(t, err, domain.ErrPushSubscriptionGone, status)
+		server.Close()
+	}
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "slow down", http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+	sender := newTestSender(t, server.Client())
+	err := sender.Send(context.Background(), newBrowser(t).subscription(server.URL), []byte("x"))
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, domain.ErrPushSubscriptionGone)
+	assert.Contains(t, err.Error(), "429")
+
+	assert.ErrorIs(t, sender.Send(context.Background(), newBrowser(t).subscription(server.URL), make([]byte, MaxPayload+1)), ErrPayloadTooLarge)
+}
+
+func TestNewSender_ValidatesConfig(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	raw, err := key.Bytes()
+	require.NoError(t, err)
+	private := base64.RawURLEncoding.EncodeToString(raw)
+	public, err := key.PublicKey.Bytes()
+	require.NoError(t, err)
+
+	sender, err := NewSender(Config{PublicKey: base64.URLEncoding.EncodeToString(public), PrivateKey: private, Subject: "https://example.com"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, base64.RawURLEncoding.EncodeToString(public), sender.PublicKey())
+
+	_, err = NewSender(Config{PublicKey: "BAAA", PrivateKey: private, Subject: "https://example.com"}, nil)
+	assert.Error(t, err)
+	_, err = NewSender(Config{PrivateKey: private, Subject: "ops@example.com"}, nil)
+	assert.Error(t, err)
+	_, err = NewSender(Config{PrivateKey: "not-a-key", Subject: "https://example.com"}, nil)
+	assert.Error(t, err)
+	assert.False(t, Config{}.Enabled())
+}
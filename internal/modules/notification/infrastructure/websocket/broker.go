@@ -0,0 +1,124 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisChannel is the pub/sub channel hub messages travel on.
+const RedisChannel = "notifications:realtime"
+
+// publishTimeout bounds how long a send waits on the broker before falling
+// back to local delivery.
+const publishTimeout = 5 * time.Second
+
+// Broker relays hub messages between processes, so a message sent from one
+// API replica or the worker reaches clients connected to any replica.
+type Broker interface {
+	Publish(ctx context.Context, payload []byte) error
+	// Subscribe returns the payloads published by every process, including
+	// this one, until ctx is done.
+	Subscribe(ctx context.Context) (<-chan []byte, error)
+}
+
+// Sender pushes messages to connected users. *Hub serves the clients of this
+// process; *Publisher only publishes, for processes without clients.
+type Sender interface {
+	SendToUser(userID uuid.UUID, message []byte)
+	BroadcastMessage(message []byte)
+}
+
+// envelope is a hub message on the broker. A nil UserID is a broadcast.
+type envelope struct {
+	UserID  *uuid.UUID `json:"user_id,omitempty"`
+	Message []byte     `json:"message"`
+}
+
+func publish(broker Broker, env envelope) error {
+	payload, err := json.Marshal(env)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), publishTimeout)
+	defer cancel()
+	return broker.Publish(ctx, payload)
+}
+
+// RedisBroker relays hub messages over Redis pub/sub.
+type RedisBroker struct {
+	client *redis.Client
+}
+
+func NewRedisBroker(client *redis.Client) *RedisBroker {
+	return &RedisBroker{client: client}
+}
+
+func (b *RedisBroker) Publish(ctx context.Context, payload []byte) error {
+	return b.client.Publish(ctx, RedisChannel, payload).Err()
+}
+
+func (b *RedisBroker) Subscribe(ctx context.Context) (<-chan []byte, error) {
+	pubsub := b.client.Subscribe(ctx, RedisChannel)
+	// Wait for the confirmation so a failed connection is reported here.
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return nil, err
+	}
+
+	out := make(chan []byte)
+	go func() {
+		defer close(out)
+		defer pubsub.Close()
+		messages := pubsub.Channel()
+		for {
+			select {
+			case msg, ok := <-messages:
+				if !ok {
+					return
+				}
+				select {
+				case out <- []byte(msg.Payload):
+				case <-ctx.Done():
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out, nil
+}
+
+// Publisher sends hub messages through a broker without serving clients. The
+// worker uses it to reach users connected to the API. Without a broker,
+// messages are dropped; notifications are still stored and show up on the
+// next fetch.
+type Publisher struct {
+	broker Broker
+}
+
+func NewPublisher(broker Broker) *Publisher {
+	return &Publisher{broker: broker}
+}
+
+func (p *Publisher) SendToUser(userID uuid.UUID, message []byte) {
+	p.publish(envelope{UserID: &userID, Message: message})
+}
+
+func (p *Publisher) BroadcastMessage(message []byte) {
+	p.publish(envelope{Message: message})
+}
+
+func (p *Publisher) publish(env envelope) {
+	if p.broker == nil {
+		return
+	}
+	if err := publish(p.broker, env); err != nil {
+		log.Printf("[WebSocket Publisher] Publish failed: %v", err)
+	}
+}
@@ -0,0 +1,148 @@
+package websocket
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryBroker fans published payloads out to every subscriber, standing in
+// for Redis between hubs in one test process.
+type memoryBroker struct {
+	mu          sync.Mutex
+	subscribers []chan []byte
+	publishErr  error
+}
+
+func (b *memoryBroker) Publish(_ context.Context, payload []byte) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.publishErr != nil {
+		return b.publishErr
+	}
+	for _, sub := range b.subscribers {
+		sub <- payload
+	}
+	return nil
+}
+
+func (b *memoryBroker) Subscribe(ctx context.Context) (<-chan []byte, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	sub := make(chan []byte, 16)
+	b.subscribers = append(b.subscribers, sub)
+	out := make(chan []byte)
+	go func() {
+		defer close(out)
+		for {
+			select {
+			case payload := <-sub:
+				select {
+				case out <- payload:
+				case <-ctx.Done():
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out, nil
+}
+
+func (b *memoryBroker) waitForSubscribers(t *testing.T, n int) {
+	t.Helper()
+	require.Eventually(t, func() bool {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		return len(b.subscribers) >= n
+	}, 2*time.Second, 5*time.Millisecond)
+}
+
+func receive(t *testing.T, client *Client) string {
+	t.Helper()
+	select {
+	case msg := <-client.send:
+		return string(msg)
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected message")
+		return ""
+	}
+}
+
+func TestBrokeredHub_FansOutAcrossHubs(t *testing.T) {
+	broker := &memoryBroker{}
+	first, second := NewBrokeredHub(broker), NewBrokeredHub(broker)
+	go first.Run()
+	go second.Run()
+	defer first.Stop()
+	defer second.Stop()
+	broker.waitForSubscribers(t, 2)
+
+	userID := uuid.New()
+	local := &Client{send: make(chan []byte, 2), userID: userID, hub: first}
+	remote := &Client{send: make(chan []byte, 2), userID: userID, hub: second}
+	other := &Client{send: make(chan []byte, 2), userID: uuid.New(), hub: second}
+	first.register <- local
+	second.register <- remote
+	second.register <- other
+
+	// A message sent on one hub reaches the user's clients on both.
+	first.SendToUser(userID, []byte("private"))
+	assert.Equal(t, "private", receive(t, local))
+	assert.Equal(t, "private", receive(t, remote))
+
+	// So does one sent by a process without clients, such as the worker.
+	NewPublisher(broker).BroadcastMessage([]byte("everyone"))
+	assert.Equal(t, "everyone", receive(t, local))
+	assert.Equal(t, "everyone", receive(t, remote))
+	assert.Equal(t, "everyone", receive(t, other))
+
+	select {
+	case <-other.send:
+		t.Fatal("unicast reached another user")
+	default:
+	}
+}
+
+func TestBrokeredHub_DeliversLocallyWhenPublishFails(t *testing.T) {
+	broker := &memoryBroker{publishErr: errors.New("connection refused")}
+	h := NewBrokeredHub(broker)
+	go h.Run()
+	defer h.Stop()
+
+	userID := uuid.New()
+	client := &Client{send: make(chan []byte, 2), userID: userID, hub: h}
+	h.register <- client
+
+	h.SendToUser(userID, []byte("private"))
+	assert.Equal(t, "private", receive(t, client))
+	h.BroadcastMessage([]byte("everyone"))
+	assert.Equal(t, "everyone", receive(t, client))
+}
+
+func TestPublisher_WithoutBrokerDropsMessages(t *testing.T) {
+	// Must not block or panic.
+	p := NewPublisher(nil)
+	p.SendToUser(uuid.New(), []byte("x"))
+	p.BroadcastMessage([]byte("x"))
+}
+
+func TestRedisBroker_ReportsConnectionErrors(t *testing.T) {
+	client := redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", MaxRetries: -1})
+	defer client.Close()
+	broker := NewRedisBroker(client)
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	assert.Error(t, broker.Publish(ctx, []byte("x")))
+	_, err := broker.Subscribe(ctx)
+	assert.Error(t, err)
+}
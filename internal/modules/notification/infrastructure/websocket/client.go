@@ -1,14 +1,28 @@
 package websocket
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
+	"github.com/saransh1220/blueprint-audio/internal/modules/notification/domain"
 )
 
+// errClosed reports that the hub closed the client's send channel.
+var errClosed = errors.New("send channel closed")
+
 const (
 	// Time allowed to write a message to the peer.
 	writeWait = 10 * time.Second
@@ -23,6 +37,10 @@ const (
 	maxMessageSize = 512
 )
 
+// sessionCheckPeriod is how often a connection checks that the session it
+// was opened with has not been revoked. A variable so tests can shorten it.
+var sessionCheckPeriod = 5 * time.Second
+
 var upgrader = websocket.Upgrader{
 	ReadBufferSize:  1024,
 	WriteBufferSize: 1024,
@@ -57,6 +75,37 @@ type Client struct {
 
 	// UserID associated with this connection (optional, for targeted notifications)
 	userID uuid.UUID
+
+	// Replies to the client's own messages. The hub closes send, so the read
+	// pump never writes to it.
+	control chan []byte
+
+	// Topics the client subscribed to. Events of other topics are not
+	// written; messages that are not events always are.
+	mu     sync.Mutex
+	topics map[string]bool
+
+	// resumeFrom is the last seq the client saw before reconnecting; the
+	// write pump replays the events after it first. It is only used when
+	// resume is set.
+	resume     bool
+	resumeFrom int64
+
+	// lastSeq is the highest seq written to the connection, and events up
+	// to replayedThrough were replayed, so live copies of them are skipped.
+	// Only the write pump uses them.
+	lastSeq         int64
+	replayedThrough int64
+
+	// lagging is set by the hub when send is full. Until the write pump has
+	// caught up and sent a resync event, signalled on resync, the hub queues
+	// nothing more for the client.
+	lagging atomic.Bool
+	resync  chan struct{}
+
+	// sessionActive reports whether the session the connection was opened
+	// with is still active; the connection is closed once it is not.
+	sessionActive func(context.Context) bool
 }
 
 // readPump pumps messages from the websocket connection to the hub.
@@ -75,15 +124,179 @@ func (c *Client) readPump() {
 	c.conn.SetReadDeadline(time.Now().Add(pongWait))
 	c.conn.SetPongHandler(func(string) error { c.conn.SetReadDeadline(time.Now().Add(pongWait)); return nil })
 	for {
-		_, _, err := c.conn.ReadMessage()
+		_, message, err := c.conn.ReadMessage()
 		if err != nil {
 			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
 				log.Printf("error: %v", err)
 			}
 			break
 		}
-		// We ignore incoming messages for now, this is a push-only system for notifications
+		c.handleMessage(message)
+	}
+}
+
+// handleMessage applies a subscribe or unsubscribe message and replies with
+// the resulting topics, or with a protocol error.
+func (c *Client) handleMessage(message []byte) {
+	var msg clientMessage
+	if err := json.Unmarshal(message, &msg); err != nil {
+		c.reply(EventProtocolError, ProtocolErrorPayload{Message: "message must be a JSON object"})
+		return
+	}
+	if msg.Action != "subscribe" && msg.Action != "unsubscribe" {
+		c.reply(EventProtocolError, ProtocolErrorPayload{Message: fmt.Sprintf("unknown action %q", msg.Action)})
+		return
+	}
+	for _, topic := range msg.Topics {
+		if !slices.Contains(domain.Topics, topic) {
+			c.reply(EventProtocolError, ProtocolErrorPayload{Message: fmt.Sprintf("unknown topic %q", topic)})
+			return
+		}
+	}
+
+	c.mu.Lock()
+	for _, topic := range msg.Topics {
+		if msg.Action == "subscribe" {
+			c.topics[topic] = true
+		} else {
+			delete(c.topics, topic)
+		}
+	}
+	topics := make([]string, 0, len(c.topics))
+	for topic := range c.topics {
+		topics = append(topics, topic)
+	}
+	c.mu.Unlock()
+	sort.Strings(topics)
+	c.reply(EventSubscriptionUpdated, SubscriptionPayload{Topics: topics})
+}
+
+// reply queues a reply for the write pump, dropping it if the client is not
+// reading its replies.
+func (c *Client) reply(eventType domain.EventType, payload any) {
+	message, err := EncodeEvent(eventType, payload)
+	if err != nil {
+		return
+	}
+	select {
+	case c.control <- message:
+	default:
+	}
+}
+
+func (c *Client) subscribed(topic string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.topics[topic]
+}
+
+// frame prepares a message for writing: events of topics the client did not
+// subscribe to and events already replayed are skipped. Replies skip the
+// checks.
+func (c *Client) frame(message []byte, reply bool) ([]byte, bool) {
+	var event Event
+	if err := json.Unmarshal(message, &event); err != nil || event.Version != ProtocolVersion {
+		return message, true
+	}
+	if !reply {
+		if !c.subscribed(event.Type.Topic()) {
+			return nil, false
+		}
+		if event.Seq != 0 && event.Seq <= c.replayedThrough {
+			return nil, false
+		}
 	}
+	c.lastSeq = max(c.lastSeq, event.Seq)
+	return message, true
+}
+
+// replay writes the events the client missed since resumeFrom. When they
+// cannot all be replayed, it sends a resync event with the latest seq.
+func (c *Client) replay() error {
+	history := c.hub.history
+	if history == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), writeWait)
+	defer cancel()
+
+	c.lastSeq = c.resumeFrom
+	c.replayedThrough = c.resumeFrom
+	events, err := history.ListAfter(ctx, c.userID, c.resumeFrom, ReplayLimit+1)
+	if err != nil {
+		log.Printf("[WebSocket Client] Replay failed. user_id=%s err=%v", c.userID, err)
+		return c.sendResync(c.resumeFrom)
+	}
+	// Seqs have no gaps, so a first event later than the next seq means the
+	// ones before it were pruned.
+	if len(events) > ReplayLimit || (len(events) > 0 && events[0].Seq != c.resumeFrom+1) {
+		latest, err := history.LastSeq(ctx, c.userID)
+		if err != nil {
+			log.Printf("[WebSocket Client] Replay failed. user_id=%s err=%v", c.userID, err)
+			return c.sendResync(c.resumeFrom)
+		}
+		c.lastSeq = latest
+		c.replayedThrough = latest
+		return c.sendResync(latest)
+	}
+
+	for _, event := range events {
+		c.replayedThrough = event.Seq
+		if !c.subscribed(event.Type.Topic()) {
+			continue
+		}
+		message, err := json.Marshal(NewEvent(event))
+		if err != nil {
+			continue
+		}
+		c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+		if err := c.write(message); err != nil {
+			return err
+		}
+		c.lastSeq = event.Seq
+	}
+	return nil
+}
+
+// catchUp writes what was queued before the hub found send full, then tells
+// the client to resync from the last event written and lets the hub queue
+// messages again.
+func (c *Client) catchUp() error {
+	n := len(c.send)
+	for i := 0; i < n; i++ {
+		message, ok := <-c.send
+		if !ok {
+			return errClosed
+		}
+		if frame, ok := c.frame(message, false); ok {
+			if err := c.write(frame); err != nil {
+				return err
+			}
+		}
+	}
+	if err := c.sendResync(c.lastSeq); err != nil {
+		return err
+	}
+	c.lagging.Store(false)
+	return nil
+}
+
+func (c *Client) sendResync(lastSeq int64) error {
+	message, err := EncodeEvent(EventResyncRequired, ResyncPayload{LastSeq: lastSeq})
+	if err != nil {
+		return err
+	}
+	c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+	return c.write(message)
+}
+
+func (c *Client) write(message []byte) error {
+	w, err := c.conn.NextWriter(websocket.TextMessage)
+	if err != nil {
+		return err
+	}
+	w.Write(message)
+	return w.Close()
 }
 
 // writePump pumps messages from the hub to the websocket connection.
@@ -92,10 +305,21 @@ func (c *Client) readPump() {
 // executing all writes from this goroutine.
 func (c *Client) writePump() {
 	ticker := time.NewTicker(pingPeriod)
+	var sessionCheck <-chan time.Time
+	if c.sessionActive != nil {
+		sessionTicker := time.NewTicker(sessionCheckPeriod)
+		defer sessionTicker.Stop()
+		sessionCheck = sessionTicker.C
+	}
 	defer func() {
 		ticker.Stop()
 		c.conn.Close()
 	}()
+	if c.resume {
+		if err := c.replay(); err != nil {
+			return
+		}
+	}
 	for {
 		select {
 		case message, ok := <-c.send:
@@ -106,45 +330,96 @@ func (c *Client) writePump() {
 				return
 			}
 
-			w, err := c.conn.NextWriter(websocket.TextMessage)
-			if err != nil {
-				return
-			}
-			w.Write(message)
-
-			if err := w.Close(); err != nil {
-				return
+			if frame, ok := c.frame(message, false); ok {
+				if err := c.write(frame); err != nil {
+					return
+				}
 			}
 
-			// Add queued chat messages to the current websocket message.
+			// Write the messages queued meanwhile.
 			n := len(c.send)
 			for i := 0; i < n; i++ {
-				w, err := c.conn.NextWriter(websocket.TextMessage)
-				if err != nil {
+				frame, ok := c.frame(<-c.send, false)
+				if !ok {
+					continue
+				}
+				if err := c.write(frame); err != nil {
 					return
 				}
-				w.Write(<-c.send)
-				if err := w.Close(); err != nil {
+			}
+		case message := <-c.control:
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if frame, ok := c.frame(message, true); ok {
+				if err := c.write(frame); err != nil {
 					return
 				}
 			}
+		case <-c.resync:
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.catchUp(); err != nil {
+				if errors.Is(err, errClosed) {
+					c.conn.WriteMessage(websocket.CloseMessage, []byte{})
+				}
+				return
+			}
 		case <-ticker.C:
 			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
 			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
 				return
 			}
+		case <-sessionCheck:
+			ctx, cancel := context.WithTimeout(context.Background(), writeWait)
+			active := c.sessionActive(ctx)
+			cancel()
+			if !active {
+				c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+				c.conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "session revoked"))
+				return
+			}
 		}
 	}
 }
 
-// ServeWs handles websocket requests from the peer.
-func ServeWs(hub *Hub, w http.ResponseWriter, r *http.Request, userID uuid.UUID) {
+// ServeWs handles websocket requests from the peer. A reconnecting client
+// passes the last seq it saw as last_seq to replay the events it missed, and
+// may pass the topics to replay and subscribe to as a comma-separated topics.
+// If sessionActive is set, the connection is closed once it reports false.
+func ServeWs(hub *Hub, w http.ResponseWriter, r *http.Request, userID uuid.UUID, sessionActive func(context.Context) bool) {
+	client := &Client{
+		hub:           hub,
+		send:          make(chan []byte, 256),
+		userID:        userID,
+		control:       make(chan []byte, 16),
+		topics:        map[string]bool{domain.TopicNotification: true},
+		resync:        make(chan struct{}, 1),
+		sessionActive: sessionActive,
+	}
+	query := r.URL.Query()
+	if lastSeq := query.Get("last_seq"); lastSeq != "" {
+		seq, err := strconv.ParseInt(lastSeq, 10, 64)
+		if err != nil || seq < 0 {
+			http.Error(w, "invalid last_seq", http.StatusBadRequest)
+			return
+		}
+		client.resume, client.resumeFrom = true, seq
+	}
+	if topics := query.Get("topics"); topics != "" {
+		client.topics = map[string]bool{}
+		for _, topic := range strings.Split(topics, ",") {
+			if !slices.Contains(domain.Topics, topic) {
+				http.Error(w, fmt.Sprintf("unknown topic %q", topic), http.StatusBadRequest)
+				return
+			}
+			client.topics[topic] = true
+		}
+	}
+
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		log.Println(err)
 		return
 	}
-	client := &Client{hub: hub, conn: conn, send: make(chan []byte, 256), userID: userID}
+	client.conn = conn
 	select {
 	case client.hub.register <- client:
 	case <-client.hub.stop:
@@ -1,14 +1,20 @@
 package websocket
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
+	"github.com/saransh1220/blueprint-audio/internal/modules/notification/domain"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -45,7 +51,7 @@ func TestServeWs_EndToEndUnicast(t *testing.T) {
 
 	userID := uuid.New()
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		ServeWs(hub, w, r, userID)
+		ServeWs(hub, w, r, userID, nil)
 	}))
 	defer srv.Close()
 
@@ -69,7 +75,7 @@ func TestServeWs_UpgradeFailure(t *testing.T) {
 	req := httptest.NewRequest(http.MethodGet, "/ws", nil)
 	w := httptest.NewRecorder()
 
-	ServeWs(hub, w, req, uuid.New())
+	ServeWs(hub, w, req, uuid.New(), nil)
 
 	// Upgrade fails for normal HTTP request and upgrader writes bad request.
 	assert.Equal(t, http.StatusBadRequest, w.Code)
@@ -81,7 +87,7 @@ func TestClient_WritePump_ClosedChannelSendsCloseFrame(t *testing.T) {
 		unregister: make(chan *Client, 1),
 	}
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		ServeWs(hub, w, r, uuid.New())
+		ServeWs(hub, w, r, uuid.New(), nil)
 	}))
 	defer srv.Close()
 
@@ -105,7 +111,7 @@ func TestClient_WritePump_DrainsQueuedMessages(t *testing.T) {
 		stop:       make(chan struct{}),
 	}
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		ServeWs(hub, w, r, uuid.New())
+		ServeWs(hub, w, r, uuid.New(), nil)
 	}))
 	defer srv.Close()
 	defer close(hub.stop)
@@ -137,7 +143,7 @@ func TestServeWs_WhenHubStopped_DoesNotBlockRegistration(t *testing.T) {
 	close(hub.stop)
 
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		ServeWs(hub, w, r, uuid.New())
+		ServeWs(hub, w, r, uuid.New(), nil)
 	}))
 	defer srv.Close()
 
@@ -154,7 +160,7 @@ func TestClient_WritePump_StopsWhenConnectionClosed(t *testing.T) {
 		stop:       make(chan struct{}),
 	}
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		ServeWs(hub, w, r, uuid.New())
+		ServeWs(hub, w, r, uuid.New(), nil)
 	}))
 	defer srv.Close()
 	defer close(hub.stop)
@@ -170,3 +176,254 @@ func TestClient_WritePump_StopsWhenConnectionClosed(t *testing.T) {
 	registered.send <- []byte("after-close")
 	time.Sleep(50 * time.Millisecond)
 }
+
+func TestServeWs_EventsAndTopicSubscriptions(t *testing.T) {
+	hub := NewHub()
+	go hub.Run()
+	defer hub.Stop()
+
+	userID := uuid.New()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ServeWs(hub, w, r, userID, nil)
+	}))
+	defer srv.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	require.NoError(t, err)
+	defer conn.Close()
+	time.Sleep(100 * time.Millisecond)
+
+	readEvent := func() Event {
+		t.Helper()
+		_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		var event Event
+		require.NoError(t, conn.ReadJSON(&event))
+		assert.Equal(t, ProtocolVersion, event.Version)
+		return event
+	}
+	var seq int64
+	send := func(eventType domain.EventType, payload any) {
+		t.Helper()
+		raw, err := json.Marshal(payload)
+		require.NoError(t, err)
+		seq++
+		message, err := json.Marshal(NewEvent(domain.Event{Type: eventType, Seq: seq, Payload: raw}))
+		require.NoError(t, err)
+		hub.SendToUser(userID, message)
+	}
+
+	// New connections only get notification events.
+	send(domain.EventUploadProgress, map[string]int{"progress": 10})
+	send(domain.EventUnreadCountChanged, domain.UnreadCountPayload{UnreadCount: 2})
+	event := readEvent()
+	assert.Equal(t, domain.EventUnreadCountChanged, event.Type)
+	assert.Equal(t, int64(2), event.Seq)
+	assert.JSONEq(t, `{"unread_count":2}`, string(event.Payload))
+
+	require.NoError(t, conn.WriteJSON(map[string]any{"action": "subscribe", "topics": []string{"upload", "order"}}))
+	event = readEvent()
+	assert.Equal(t, EventSubscriptionUpdated, event.Type)
+	assert.Zero(t, event.Seq)
+	assert.JSONEq(t, `{"topics":["notification","order","upload"]}`, string(event.Payload))
+
+	send(domain.EventUploadProgress, map[string]int{"progress": 50})
+	event = readEvent()
+	assert.Equal(t, domain.EventUploadProgress, event.Type)
+	assert.Equal(t, int64(3), event.Seq)
+
+	require.NoError(t, conn.WriteJSON(map[string]any{"action": "unsubscribe", "topics": []string{"notification"}}))
+	assert.JSONEq(t, `{"topics":["order","upload"]}`, string(readEvent().Payload))
+	send(domain.EventNotificationCreated, map[string]string{"title": "hidden"})
+	send(domain.EventOrderPaid, map[string]string{"status": "paid"})
+	assert.Equal(t, domain.EventOrderPaid, readEvent().Type)
+
+	for _, message := range []string{`not json`, `{"action":"shout"}`, `{"action":"subscribe","topics":["secrets"]}`} {
+		require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(message)))
+		assert.Equal(t, EventProtocolError, readEvent().Type)
+	}
+}
+
+func TestEventType_Topic(t *testing.T) {
+	assert.Equal(t, domain.TopicUpload, domain.EventUploadProgress.Topic())
+	assert.Equal(t, domain.TopicNotification, domain.EventUnreadCountChanged.Topic())
+	assert.Equal(t, domain.TopicLicense, domain.EventLicenseIssued.Topic())
+}
+
+// memoryHistory stores one user's events from seq first on.
+type memoryHistory struct {
+	events []domain.Event
+	err    error
+}
+
+func (h *memoryHistory) add(first, last int64, eventType domain.EventType) {
+	for seq := first; seq <= last; seq++ {
+		h.events = append(h.events, domain.Event{Seq: seq, Type: eventType, Payload: json.RawMessage(fmt.Sprintf(`{"n":%d}`, seq))})
+	}
+}
+
+func (h *memoryHistory) ListAfter(_ context.Context, _ uuid.UUID, afterSeq int64, limit int) ([]domain.Event, error) {
+	var events []domain.Event
+	for _, event := range h.events {
+		if event.Seq > afterSeq && len(events) < limit {
+			events = append(events, event)
+		}
+	}
+	return events, h.err
+}
+
+func (h *memoryHistory) LastSeq(context.Context, uuid.UUID) (int64, error) {
+	if len(h.events) == 0 {
+		return 0, h.err
+	}
+	return h.events[len(h.events)-1].Seq, h.err
+}
+
+func dialWs(t *testing.T, hub *Hub, userID uuid.UUID, query string) *websocket.Conn {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ServeWs(hub, w, r, userID, nil)
+	}))
+	t.Cleanup(srv.Close)
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/ws"+query, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func readEvent(t *testing.T, conn *websocket.Conn) Event {
+	t.Helper()
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var event Event
+	require.NoError(t, conn.ReadJSON(&event))
+	return event
+}
+
+func TestServeWs_ReplaysMissedEvents(t *testing.T) {
+	history := &memoryHistory{}
+	history.add(1, 3, domain.EventUnreadCountChanged)
+	history.add(4, 4, domain.EventOrderPaid)
+	history.add(5, 5, domain.EventNotificationCreated)
+	hub := NewHub()
+	hub.SetHistory(history)
+	go hub.Run()
+	defer hub.Stop()
+
+	userID := uuid.New()
+	conn := dialWs(t, hub, userID, "?last_seq=2&topics=notification,order")
+	for _, want := range []int64{3, 4, 5} {
+		event := readEvent(t, conn)
+		assert.Equal(t, want, event.Seq)
+		assert.JSONEq(t, fmt.Sprintf(`{"n":%d}`, want), string(event.Payload))
+	}
+
+	// Live copies of replayed events are skipped; later ones go through.
+	time.Sleep(100 * time.Millisecond)
+	for _, seq := range []int64{5, 6} {
+		message, err := json.Marshal(NewEvent(domain.Event{Seq: seq, Type: domain.EventOrderPaid, Payload: json.RawMessage(`{}`)}))
+		require.NoError(t, err)
+		hub.SendToUser(userID, message)
+	}
+	assert.Equal(t, int64(6), readEvent(t, conn).Seq)
+}
+
+func TestServeWs_ResyncWhenEventsCannotBeReplayed(t *testing.T) {
+	userID := uuid.New()
+
+	t.Run("pruned", func(t *testing.T) {
+		history := &memoryHistory{}
+		history.add(10, 12, domain.EventNotificationCreated)
+		hub := NewHub()
+		hub.SetHistory(history)
+		go hub.Run()
+		defer hub.Stop()
+
+		event := readEvent(t, dialWs(t, hub, userID, "?last_seq=3"))
+		assert.Equal(t, EventResyncRequired, event.Type)
+		assert.JSONEq(t, `{"last_seq":12}`, string(event.Payload))
+	})
+
+	t.Run("too far behind", func(t *testing.T) {
+		history := &memoryHistory{}
+		history.add(1, ReplayLimit+5, domain.EventNotificationCreated)
+		hub := NewHub()
+		hub.SetHistory(history)
+		go hub.Run()
+		defer hub.Stop()
+
+		event := readEvent(t, dialWs(t, hub, userID, "?last_seq=0"))
+		assert.Equal(t, EventResyncRequired, event.Type)
+		assert.JSONEq(t, fmt.Sprintf(`{"last_seq":%d}`, ReplayLimit+5), string(event.Payload))
+	})
+
+	t.Run("history unavailable", func(t *testing.T) {
+		hub := NewHub()
+		hub.SetHistory(&memoryHistory{err: errors.New("db down")})
+		go hub.Run()
+		defer hub.Stop()
+
+		event := readEvent(t, dialWs(t, hub, userID, "?last_seq=7"))
+		assert.Equal(t, EventResyncRequired, event.Type)
+		assert.JSONEq(t, `{"last_seq":7}`, string(event.Payload))
+	})
+}
+
+func TestServeWs_RejectsInvalidResumeParameters(t *testing.T) {
+	for _, query := range []string{"?last_seq=abc", "?last_seq=-1", "?topics=notification,secrets"} {
+		w := httptest.NewRecorder()
+		ServeWs(NewHub(), w, httptest.NewRequest(http.MethodGet, "/ws"+query, nil), uuid.New(), nil)
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+}
+
+func TestClient_SlowConsumerGetsResync(t *testing.T) {
+	hub := &Hub{
+		register:   make(chan *Client, 1),
+		unregister: make(chan *Client, 1),
+		stop:       make(chan struct{}),
+	}
+	defer close(hub.stop)
+	conn := dialWs(t, hub, uuid.New(), "")
+	client := <-hub.register
+
+	// The hub found send full after queueing seq 4.
+	message, err := json.Marshal(NewEvent(domain.Event{Seq: 4, Type: domain.EventNotificationCreated, Payload: json.RawMessage(`{}`)}))
+	require.NoError(t, err)
+	client.lagging.Store(true)
+	client.send <- message
+	client.resync <- struct{}{}
+
+	assert.Equal(t, int64(4), readEvent(t, conn).Seq)
+	event := readEvent(t, conn)
+	assert.Equal(t, EventResyncRequired, event.Type)
+	assert.JSONEq(t, `{"last_seq":4}`, string(event.Payload))
+	require.Eventually(t, func() bool { return !client.lagging.Load() }, time.Second, 5*time.Millisecond)
+}
+
+func TestServeWs_ClosesRevokedSession(t *testing.T) {
+	previous := sessionCheckPeriod
+	sessionCheckPeriod = 10 * time.Millisecond
+	defer func() { sessionCheckPeriod = previous }()
+
+	hub := NewHub()
+	go hub.Run()
+	defer hub.Stop()
+
+	var revoked atomic.Bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ServeWs(hub, w, r, uuid.New(), func(context.Context) bool { return !revoked.Load() })
+	}))
+	defer srv.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	time.Sleep(50 * time.Millisecond)
+	revoked.Store(true)
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_, _, err = conn.ReadMessage()
+	var closeErr *websocket.CloseError
+	require.ErrorAs(t, err, &closeErr)
+	assert.Equal(t, websocket.ClosePolicyViolation, closeErr.Code)
+	assert.Equal(t, "session revoked", closeErr.Text)
+}
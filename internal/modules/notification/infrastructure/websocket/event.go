@@ -0,0 +1,82 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/google/uuid"
+	"github.com/saransh1220/blueprint-audio/internal/modules/notification/domain"
+)
+
+// ProtocolVersion is the version of the event envelope. Clients should ignore
+// events with a version they do not know.
+const ProtocolVersion = 1
+
+// ReplayLimit is the most missed events replayed to a reconnecting client.
+// A client further behind is told to resync instead.
+const ReplayLimit = 500
+
+// Messages about the connection itself. They are not tied to a topic, are
+// always sent and carry no seq.
+const (
+	EventSubscriptionUpdated domain.EventType = "subscription.updated"
+	EventProtocolError       domain.EventType = "protocol.error"
+	// EventResyncRequired tells the client it missed events that cannot be
+	// replayed on this connection.
+	EventResyncRequired domain.EventType = "resync.required"
+)
+
+// Event is the envelope of every message sent over /ws. Seq is the user's
+// event sequence number, shared by all their connections; it is 0 for
+// messages about the connection itself.
+type Event struct {
+	Version int              `json:"v"`
+	Type    domain.EventType `json:"type"`
+	Seq     int64            `json:"seq"`
+	Payload json.RawMessage  `json:"payload"`
+}
+
+// NewEvent returns the envelope of a stored event.
+func NewEvent(event domain.Event) Event {
+	return Event{Version: ProtocolVersion, Type: event.Type, Seq: event.Seq, Payload: event.Payload}
+}
+
+// EncodeEvent returns the envelope of an event without a seq.
+func EncodeEvent(eventType domain.EventType, payload any) ([]byte, error) {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(Event{Version: ProtocolVersion, Type: eventType, Payload: raw})
+}
+
+// History is the store of past events the hub replays from.
+type History interface {
+	ListAfter(ctx context.Context, userID uuid.UUID, afterSeq int64, limit int) ([]domain.Event, error)
+	LastSeq(ctx context.Context, userID uuid.UUID) (int64, error)
+}
+
+// clientMessage is a message from the client. Action is "subscribe" or
+// "unsubscribe".
+type clientMessage struct {
+	Action string   `json:"action"`
+	Topics []string `json:"topics"`
+}
+
+// SubscriptionPayload is the payload of EventSubscriptionUpdated.
+type SubscriptionPayload struct {
+	Topics []string `json:"topics"`
+}
+
+// ProtocolErrorPayload is the payload of EventProtocolError.
+type ProtocolErrorPayload struct {
+	Message string `json:"message"`
+}
+
+// ResyncPayload is the payload of EventResyncRequired. The client reconnects
+// with last_seq=LastSeq to replay what it missed. When LastSeq is past the
+// last event it saw, the events in between are gone and it should reload its
+// state through the REST API first.
+type ResyncPayload struct {
+	LastSeq int64 `json:"last_seq"`
+}
@@ -1,12 +1,19 @@
 package websocket
 
 import (
+	"context"
+	"encoding/json"
 	"log"
 	"sync"
+	"time"
 
 	"github.com/google/uuid"
 )
 
+// resubscribeDelay is how long the hub waits before subscribing to the broker
+// again after losing its subscription.
+const resubscribeDelay = 2 * time.Second
+
 type UnicastMessage struct {
 	UserID  uuid.UUID
 	Message []byte
@@ -30,11 +37,21 @@ type Hub struct {
 	// Unregister requests from clients.
 	unregister chan *Client
 
+	// broker relays messages between processes. When nil, messages are only
+	// delivered to this process's clients.
+	broker Broker
+
+	// history replays missed events to reconnecting clients. When nil,
+	// nothing is replayed.
+	history History
+
 	// Channel to signal termination
 	stop     chan struct{}
 	stopOnce sync.Once
 }
 
+// NewHub returns a hub that delivers messages to the clients of this process
+// only.
 func NewHub() *Hub {
 	return &Hub{
 		broadcast:  make(chan []byte),
@@ -47,7 +64,28 @@ func NewHub() *Hub {
 	}
 }
 
+// NewBrokeredHub returns a hub that sends messages through the broker and
+// delivers what any process publishes to its own clients. Messages are
+// delivered locally when publishing fails.
+func NewBrokeredHub(broker Broker) *Hub {
+	h := NewHub()
+	h.broker = broker
+	return h
+}
+
+// SetHistory sets the store missed events are replayed from. It must be
+// called before Run.
+func (h *Hub) SetHistory(history History) {
+	h.history = history
+}
+
 func (h *Hub) Run() {
+	if h.broker != nil {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go h.relay(ctx)
+	}
+
 	for {
 		select {
 		case client := <-h.register:
@@ -70,23 +108,13 @@ func (h *Hub) Run() {
 		case message := <-h.broadcast:
 			log.Printf("[WebSocket Hub] Broadcasting message to %d clients", len(h.clients))
 			for client := range h.clients {
-				select {
-				case client.send <- message:
-				default:
-					close(client.send)
-					delete(h.clients, client)
-				}
+				h.queue(client, message)
 			}
 		case msg := <-h.unicast:
 			log.Printf("[WebSocket Hub] Sending unicast to user: %s", msg.UserID)
 			for client := range h.clients {
 				if client.userID == msg.UserID {
-					select {
-					case client.send <- msg.Message:
-					default:
-						close(client.send)
-						delete(h.clients, client)
-					}
+					h.queue(client, msg.Message)
 				}
 			}
 		case <-h.stop:
@@ -100,7 +128,28 @@ func (h *Hub) Run() {
 	}
 }
 
+// queue hands a message to the client's write pump. A client too slow to keep
+// up is not disconnected: it misses messages until it has been told to
+// resync.
+func (h *Hub) queue(client *Client, message []byte) {
+	if client.lagging.Load() {
+		return
+	}
+	select {
+	case client.send <- message:
+	default:
+		client.lagging.Store(true)
+		select {
+		case client.resync <- struct{}{}:
+		default:
+		}
+	}
+}
+
 func (h *Hub) BroadcastMessage(message []byte) {
+	if h.published(envelope{Message: message}) {
+		return
+	}
 	select {
 	case h.broadcast <- message:
 	case <-h.stop:
@@ -108,12 +157,67 @@ func (h *Hub) BroadcastMessage(message []byte) {
 }
 
 func (h *Hub) SendToUser(userID uuid.UUID, message []byte) {
+	if h.published(envelope{UserID: &userID, Message: message}) {
+		return
+	}
 	select {
 	case h.unicast <- UnicastMessage{UserID: userID, Message: message}:
 	case <-h.stop:
 	}
 }
 
+// published reports whether the message went out through the broker, which
+// hands it back to this hub's relay for local delivery.
+func (h *Hub) published(env envelope) bool {
+	if h.broker == nil {
+		return false
+	}
+	if err := publish(h.broker, env); err != nil {
+		log.Printf("[WebSocket Hub] Publish failed, delivering locally: %v", err)
+		return false
+	}
+	return true
+}
+
+// relay delivers the messages published by every process to this hub's
+// clients, subscribing again whenever the subscription is lost.
+func (h *Hub) relay(ctx context.Context) {
+	for {
+		messages, err := h.broker.Subscribe(ctx)
+		if err != nil {
+			log.Printf("[WebSocket Hub] Subscribe failed: %v", err)
+		} else {
+			for payload := range messages {
+				var env envelope
+				if err := json.Unmarshal(payload, &env); err != nil {
+					log.Printf("[WebSocket Hub] Dropping malformed message: %v", err)
+					continue
+				}
+				h.deliver(env)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(resubscribeDelay):
+		}
+	}
+}
+
+func (h *Hub) deliver(env envelope) {
+	if env.UserID == nil {
+		select {
+		case h.broadcast <- env.Message:
+		case <-h.stop:
+		}
+		return
+	}
+	select {
+	case h.unicast <- UnicastMessage{UserID: *env.UserID, Message: env.Message}:
+	case <-h.stop:
+	}
+}
+
 func (h *Hub) Stop() {
 	h.stopOnce.Do(func() {
 		close(h.stop)
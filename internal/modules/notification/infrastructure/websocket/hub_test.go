@@ -107,40 +107,46 @@ func TestHub_UnregisterRemovesClientAndClosesChannel(t *testing.T) {
 	assert.False(t, ok, "client send channel should be closed")
 }
 
-func TestHub_DropsBlockedClientOnBroadcastAndUnicast(t *testing.T) {
-	t.Run("broadcast blocked send removes client", func(t *testing.T) {
-		h := NewHub()
-		go h.Run()
-		defer h.Stop()
-
-		// Unbuffered channel without receiver forces default branch.
-		client := &Client{send: make(chan []byte), userID: uuid.New(), hub: h}
-		h.register <- client
-		time.Sleep(10 * time.Millisecond)
-
-		h.BroadcastMessage([]byte("x"))
-		time.Sleep(10 * time.Millisecond)
-
-		// Verify channel is closed (client removed)
-		_, ok := <-client.send
-		assert.False(t, ok, "blocked client should be removed and channel closed")
-	})
-
-	t.Run("unicast blocked send removes client", func(t *testing.T) {
-		h := NewHub()
-		go h.Run()
-		defer h.Stop()
-
-		uid := uuid.New()
-		client := &Client{send: make(chan []byte), userID: uid, hub: h}
-		h.register <- client
-		time.Sleep(10 * time.Millisecond)
-
-		h.SendToUser(uid, []byte("x"))
-		time.Sleep(10 * time.Millisecond)
-
-		// Verify channel is closed (client removed)
-		_, ok := <-client.send
-		assert.False(t, ok, "blocked client should be removed and channel closed")
-	})
+func TestHub_FlagsBlockedClientForResync(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		send func(h *Hub, userID uuid.UUID)
+	}{
+		{name: "broadcast", send: func(h *Hub, _ uuid.UUID) { h.BroadcastMessage([]byte("x")) }},
+		{name: "unicast", send: func(h *Hub, userID uuid.UUID) { h.SendToUser(userID, []byte("x")) }},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			h := NewHub()
+			go h.Run()
+			defer h.Stop()
+
+			uid := uuid.New()
+			client := &Client{send: make(chan []byte, 1), resync: make(chan struct{}, 1), userID: uid, hub: h}
+			h.register <- client
+
+			tc.send(h, uid)
+			tc.send(h, uid)
+			select {
+			case <-client.resync:
+			case <-time.After(2 * time.Second):
+				t.Fatal("expected resync signal")
+			}
+			assert.True(t, client.lagging.Load())
+
+			// The client stays registered but gets nothing until it caught up.
+			assert.Equal(t, "x", string(<-client.send))
+			tc.send(h, uid)
+			time.Sleep(10 * time.Millisecond)
+			assert.Empty(t, client.send)
+
+			client.lagging.Store(false)
+			tc.send(h, uid)
+			select {
+			case msg := <-client.send:
+				assert.Equal(t, "x", string(msg))
+			case <-time.After(2 * time.Second):
+				t.Fatal("expected message after catching up")
+			}
+		})
+	}
 }
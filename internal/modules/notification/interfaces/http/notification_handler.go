@@ -1,11 +1,13 @@
 package http
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"log"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/google/uuid"
 	"github.com/saransh1220/blueprint-audio/internal/gateway/middleware"
@@ -33,7 +35,9 @@ func (h *NotificationHandler) Subscribe(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	websocket.ServeWs(h.hub, w, r, userID)
+	// The connection outlives the request, so it rechecks the session.
+	sessionActive, _ := r.Context().Value(middleware.ContextKeySessionActive).(func(context.Context) bool)
+	websocket.ServeWs(h.hub, w, r, userID, sessionActive)
 }
 
 func (h *NotificationHandler) ListNotifications(w http.ResponseWriter, r *http.Request) {
@@ -133,3 +137,175 @@ func (h *NotificationHandler) UnreadCount(w http.ResponseWriter, r *http.Request
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]int{"count": count})
 }
+
+type preferencesBody struct {
+	Preferences []domain.Preference `json:"preferences"`
+}
+
+func (h *NotificationHandler) GetPreferences(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.ContextKeyUserId).(uuid.UUID)
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	preferences, err := h.service.Preferences(r.Context(), userID)
+	if err != nil {
+		http.Error(w, "failed to get notification preferences", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(preferencesBody{Preferences: preferences})
+}
+
+func (h *NotificationHandler) UpdatePreferences(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.ContextKeyUserId).(uuid.UUID)
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var body preferencesBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	preferences, err := h.service.UpdatePreferences(r.Context(), userID, body.Preferences)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrUnknownCategory),
+			errors.Is(err, domain.ErrInvalidEmailMode),
+			errors.Is(err, domain.ErrDigestNeedsInApp),
+			errors.Is(err, domain.ErrDuplicateCategory):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		default:
+			http.Error(w, "failed to update notification preferences", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(preferencesBody{Preferences: preferences})
+}
+
+// SendAdminMessage lets admins notify a user directly.
+func (h *NotificationHandler) SendAdminMessage(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "invalid user id", http.StatusBadRequest)
+		return
+	}
+
+	var body struct {
+		Title   string `json:"title"`
+		Message string `json:"message"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.SendAdminMessage(r.Context(), userID, body.Title, body.Message); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidAdminMessage):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, domain.ErrRecipientNotFound):
+			http.Error(w, "user not found", http.StatusNotFound)
+		default:
+			http.Error(w, "failed to send message", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// pushSubscriptionBody is a browser PushSubscription serialised with toJSON().
+type pushSubscriptionBody struct {
+	Endpoint string `json:"endpoint"`
+	Keys     struct {
+		P256dh string `json:"p256dh"`
+		Auth   string `json:"auth"`
+	} `json:"keys"`
+}
+
+func (h *NotificationHandler) PushPublicKey(w http.ResponseWriter, r *http.Request) {
+	publicKey, err := h.service.PushPublicKey()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"public_key": publicKey})
+}
+
+func (h *NotificationHandler) SubscribePush(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.ContextKeyUserId).(uuid.UUID)
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var body pushSubscriptionBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	userAgent := r.UserAgent()
+	if len(userAgent) > 255 {
+		userAgent = userAgent[:255]
+	}
+	subscription := &domain.PushSubscription{
+		UserID:    userID,
+		Endpoint:  body.Endpoint,
+		P256dh:    body.Keys.P256dh,
+		Auth:      body.Keys.Auth,
+		UserAgent: strings.ToValidUTF8(userAgent, ""),
+	}
+	if err := h.service.SubscribePush(r.Context(), subscription); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidPushSubscription):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, domain.ErrPushSubscriptionTaken):
+			http.Error(w, err.Error(), http.StatusConflict)
+		case errors.Is(err, domain.ErrPushDisabled):
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		default:
+			http.Error(w, "failed to save push subscription", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(subscription)
+}
+
+func (h *NotificationHandler) UnsubscribePush(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.ContextKeyUserId).(uuid.UUID)
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var body pushSubscriptionBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Endpoint == "" {
+		http.Error(w, "endpoint is required", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.UnsubscribePush(r.Context(), userID, body.Endpoint); err != nil {
+		if errors.Is(err, domain.ErrPushDisabled) {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		http.Error(w, "failed to remove push subscription", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -4,9 +4,12 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"io"
 	stdhttp "net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/saransh1220/blueprint-audio/internal/gateway/middleware"
@@ -38,6 +41,25 @@ func (s notificationRepoStub) MarkAllAsRead(ctx context.Context, userID uuid.UUI
 func (s notificationRepoStub) UnreadCount(ctx context.Context, userID uuid.UUID) (int, error) {
 	return s.unreadCountFn(ctx, userID)
 }
+func (s notificationRepoStub) ListDigestRecipients(context.Context, time.Time, int) ([]uuid.UUID, error) {
+	return nil, nil
+}
+func (s notificationRepoStub) ClaimDigest(context.Context, uuid.UUID) ([]domain.Notification, error) {
+	return nil, nil
+}
+func (s notificationRepoStub) ReleaseDigest(context.Context, []uuid.UUID) error { return nil }
+
+type preferenceRepoStub struct {
+	stored map[uuid.UUID][]domain.Preference
+}
+
+func (s *preferenceRepoStub) ListByUserID(_ context.Context, userID uuid.UUID) ([]domain.Preference, error) {
+	return s.stored[userID], nil
+}
+func (s *preferenceRepoStub) Upsert(_ context.Context, userID uuid.UUID, preferences []domain.Preference) error {
+	s.stored[userID] = append(s.stored[userID], preferences...)
+	return nil
+}
 
 func authedRequest(method, path string, userID uuid.UUID) *stdhttp.Request {
 	req := httptest.NewRequest(method, path, nil)
@@ -46,7 +68,7 @@ func authedRequest(method, path string, userID uuid.UUID) *stdhttp.Request {
 }
 
 func newHandler(repo notificationRepoStub, hub *ws.Hub) *notificationhttp.NotificationHandler {
-	svc := application.NewNotificationService(repo, hub)
+	svc := application.NewNotificationService(repo, nil, nil, hub, nil, application.EmailConfig{})
 	return notificationhttp.NewNotificationHandler(svc, hub)
 }
 
@@ -164,3 +186,166 @@ func TestNotificationHandler_SuccessBranches(t *testing.T) {
 	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &payload))
 	assert.Equal(t, 3, payload["count"])
 }
+
+func TestNotificationHandler_Preferences(t *testing.T) {
+	userID := uuid.New()
+	prefs := &preferenceRepoStub{stored: map[uuid.UUID][]domain.Preference{}}
+	svc := application.NewNotificationService(notificationRepoStub{}, nil, prefs, ws.NewPublisher(nil), nil, application.EmailConfig{})
+	h := notificationhttp.NewNotificationHandler(svc, nil)
+
+	w := httptest.NewRecorder()
+	h.GetPreferences(w, authedRequest(stdhttp.MethodGet, "/notifications/preferences", userID))
+	require.Equal(t, stdhttp.StatusOK, w.Code)
+	var body struct {
+		Preferences []domain.Preference `json:"preferences"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	require.Len(t, body.Preferences, len(domain.Categories))
+	assert.Equal(t, domain.Preference{Category: domain.CategorySaleMade, InApp: true, Email: domain.EmailInstant}, body.Preferences[0])
+
+	put := func(payload string) *httptest.ResponseRecorder {
+		req := authedRequest(stdhttp.MethodPut, "/notifications/preferences", userID)
+		req.Body = io.NopCloser(strings.NewReader(payload))
+		w := httptest.NewRecorder()
+		h.UpdatePreferences(w, req)
+		return w
+	}
+
+	w = put(`{"preferences":[{"category":"sale_made","in_app":true,"email":"daily_digest"}]}`)
+	require.Equal(t, stdhttp.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, domain.EmailDailyDigest, body.Preferences[0].Email)
+	assert.Len(t, body.Preferences, len(domain.Categories))
+
+	for _, payload := range []string{
+		`{"preferences":[{"category":"gossip","in_app":true,"email":"off"}]}`,
+		`{"preferences":[{"category":"sale_made","in_app":true,"email":"weekly"}]}`,
+		`{"preferences":[{"category":"sale_made","in_app":false,"email":"daily_digest"}]}`,
+		`{"preferences":[{"category":"sale_made","in_app":true,"email":"off"},{"category":"sale_made","in_app":false,"email":"off"}]}`,
+		`not json`,
+	} {
+		assert.Equal(t, stdhttp.StatusBadRequest, put(payload).Code, payload)
+	}
+
+	w = httptest.NewRecorder()
+	h.GetPreferences(w, httptest.NewRequest(stdhttp.MethodGet, "/notifications/preferences", nil))
+	assert.Equal(t, stdhttp.StatusUnauthorized, w.Code)
+}
+
+func TestNotificationHandler_SendAdminMessage(t *testing.T) {
+	var created []domain.Notification
+	repo := notificationRepoStub{unreadCountFn: func(context.Context, uuid.UUID) (int, error) { return 1, nil }}
+	svc := application.NewNotificationService(recordingRepo{notificationRepoStub: repo, created: &created}, nil, nil, ws.NewPublisher(nil), nil, application.EmailConfig{})
+	h := notificationhttp.NewNotificationHandler(svc, nil)
+
+	send := func(id, payload string) int {
+		req := httptest.NewRequest(stdhttp.MethodPost, "/admin/users/"+id+"/notifications", strings.NewReader(payload))
+		req.SetPathValue("id", id)
+		w := httptest.NewRecorder()
+		h.SendAdminMessage(w, req)
+		return w.Code
+	}
+
+	userID := uuid.New()
+	assert.Equal(t, stdhttp.StatusNoContent, send(userID.String(), `{"title":"Maintenance","message":"Checkout is down tonight."}`))
+	require.Len(t, created, 1)
+	assert.Equal(t, domain.CategoryAdminMessage, created[0].Category)
+	assert.Equal(t, userID, created[0].UserID)
+
+	assert.Equal(t, stdhttp.StatusBadRequest, send("bad", `{}`))
+	assert.Equal(t, stdhttp.StatusBadRequest, send(userID.String(), `{"title":" ","message":"x"}`))
+	assert.Equal(t, stdhttp.StatusBadRequest, send(userID.String(), `{"title":"`+strings.Repeat("x", 256)+`","message":"x"}`))
+}
+
+type recordingRepo struct {
+	notificationRepoStub
+	created *[]domain.Notification
+}
+
+func (r recordingRepo) Create(_ context.Context, n *domain.Notification) error {
+	*r.created = append(*r.created, *n)
+	return nil
+}
+
+type pushSubscriptionRepoStub struct {
+	stored []domain.PushSubscription
+}
+
+func (s *pushSubscriptionRepoStub) Upsert(_ context.Context, subscription *domain.PushSubscription) error {
+	s.stored = append(s.stored, *subscription)
+	return nil
+}
+func (s *pushSubscriptionRepoStub) ListByUserID(context.Context, uuid.UUID) ([]domain.PushSubscription, error) {
+	return s.stored, nil
+}
+func (s *pushSubscriptionRepoStub) Delete(_ context.Context, userID uuid.UUID, endpoint string) error {
+	for i, subscription := range s.stored {
+		if subscription.UserID == userID && subscription.Endpoint == endpoint {
+			s.stored = append(s.stored[:i], s.stored[i+1:]...)
+		}
+	}
+	return nil
+}
+func (s *pushSubscriptionRepoStub) DeleteByEndpoint(context.Context, string) error { return nil }
+
+type pushSenderStub struct{}
+
+func (pushSenderStub) Send(context.Context, domain.PushSubscription, []byte) error { return nil }
+func (pushSenderStub) PublicKey() string                                           { return "BPublicKey" }
+
+func TestNotificationHandler_PushSubscriptions(t *testing.T) {
+	userID := uuid.New()
+	svc := application.NewNotificationService(notificationRepoStub{}, nil, nil, ws.NewPublisher(nil), nil, application.EmailConfig{})
+	h := notificationhttp.NewNotificationHandler(svc, nil)
+
+	call := func(handler stdhttp.HandlerFunc, method, payload string) *httptest.ResponseRecorder {
+		req := authedRequest(method, "/notifications/push-subscriptions", userID)
+		req.Body = io.NopCloser(strings.NewReader(payload))
+		req.Header.Set("User-Agent", "Firefox")
+		w := httptest.NewRecorder()
+		handler(w, req)
+		return w
+	}
+	subscription := `{"endpoint":"https://fcm.googleapis.com/fcm/send/1","expirationTime":null,"keys":{` +
+		`"p256dh":"BCVxsr7N_eNgVRqvHtD0zTZsEc6-VV-JvLexhqUzORcxaOzi6-AYWXvTBHm4bjyPjs7Vd8pZGH6SRpkNtoIAiw4",` +
+		`"auth":"BTBZMqHH6r4Tts7J_aSIgg"}}`
+
+	assert.Equal(t, stdhttp.StatusServiceUnavailable, call(h.PushPublicKey, stdhttp.MethodGet, "").Code)
+	assert.Equal(t, stdhttp.StatusServiceUnavailable, call(h.SubscribePush, stdhttp.MethodPost, subscription).Code)
+
+	subscriptions := &pushSubscriptionRepoStub{}
+	svc.SetPush(subscriptions, pushSenderStub{})
+
+	w := call(h.PushPublicKey, stdhttp.MethodGet, "")
+	require.Equal(t, stdhttp.StatusOK, w.Code)
+	assert.JSONEq(t, `{"public_key":"BPublicKey"}`, w.Body.String())
+
+	w = call(h.SubscribePush, stdhttp.MethodPost, subscription)
+	require.Equal(t, stdhttp.StatusCreated, w.Code)
+	var created map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.Equal(t, "https://fcm.googleapis.com/fcm/send/1", created["endpoint"])
+	assert.Equal(t, "Firefox", created["user_agent"])
+	assert.NotContains(t, created, "p256dh")
+	require.Len(t, subscriptions.stored, 1)
+	assert.Equal(t, userID, subscriptions.stored[0].UserID)
+
+	for _, payload := range []string{
+		`{"endpoint":"http://push.example.com/1","keys":{"p256dh":"x","auth":"y"}}`,
+		`{"endpoint":"https://fcm.googleapis.com/fcm/send/1","keys":{"p256dh":"short","auth":"BTBZMqHH6r4Tts7J_aSIgg"}}`,
+		strings.Replace(subscription, "https://fcm.googleapis.com", "https://169.254.169.254", 1),
+		strings.Replace(subscription, "https://fcm.googleapis.com", "https://fcm.googleapis.com.evil.example", 1),
+		strings.Replace(subscription, "https://fcm.googleapis.com", "https://fcm.googleapis.com:8443", 1),
+		`not json`,
+	} {
+		assert.Equal(t, stdhttp.StatusBadRequest, call(h.SubscribePush, stdhttp.MethodPost, payload).Code, payload)
+	}
+
+	assert.Equal(t, stdhttp.StatusBadRequest, call(h.UnsubscribePush, stdhttp.MethodDelete, `{}`).Code)
+	assert.Equal(t, stdhttp.StatusNoContent, call(h.UnsubscribePush, stdhttp.MethodDelete, `{"endpoint":"https://fcm.googleapis.com/fcm/send/1"}`).Code)
+	assert.Empty(t, subscriptions.stored)
+
+	w = httptest.NewRecorder()
+	h.SubscribePush(w, httptest.NewRequest(stdhttp.MethodPost, "/notifications/push-subscriptions", strings.NewReader(subscription)))
+	assert.Equal(t, stdhttp.StatusUnauthorized, w.Code)
+}
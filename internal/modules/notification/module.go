@@ -1,11 +1,17 @@
 package notification
 
 import (
+	"log"
+
 	"github.com/jmoiron/sqlx"
+	"github.com/redis/go-redis/v9"
+	authDomain "github.com/saransh1220/blueprint-audio/internal/modules/auth/domain"
 	"github.com/saransh1220/blueprint-audio/internal/modules/notification/application"
 	"github.com/saransh1220/blueprint-audio/internal/modules/notification/infrastructure/persistence/postgres"
+	"github.com/saransh1220/blueprint-audio/internal/modules/notification/infrastructure/webpush"
 	"github.com/saransh1220/blueprint-audio/internal/modules/notification/infrastructure/websocket"
 	notification_http "github.com/saransh1220/blueprint-audio/internal/modules/notification/interfaces/http"
+	sharedemail "github.com/saransh1220/blueprint-audio/internal/shared/infrastructure/email"
 )
 
 type Module struct {
@@ -14,12 +20,30 @@ type Module struct {
 	hub     *websocket.Hub
 }
 
-func NewModule(db *sqlx.DB) *Module {
+// NewModule starts the WebSocket hub. With a Redis client, messages fan out
+// through Redis pub/sub to every API replica; without one they stay in this
+// process. Events are stored in Postgres so reconnecting clients can replay
+// the ones they missed.
+//
+// Notifications are emailed through emailSender to the addresses userFinder
+// returns, with links to appBaseURL. With a VAPID key pair in push, they are
+// also sent as Web Push messages to the browsers users subscribed.
+func NewModule(db *sqlx.DB, redisClient *redis.Client, userFinder authDomain.UserFinder, emailSender sharedemail.Sender, appBaseURL string, push webpush.Config) *Module {
 	repo := postgres.NewPgNotificationRepository(db)
+	events := postgres.NewPgEventRepository(db)
+	preferences := postgres.NewPgPreferenceRepository(db)
 	hub := websocket.NewHub()
+	if redisClient != nil {
+		hub = websocket.NewBrokeredHub(websocket.NewRedisBroker(redisClient))
+	}
+	hub.SetHistory(events)
 	go hub.Run()
 
-	service := application.NewNotificationService(repo, hub)
+	service := application.NewNotificationService(repo, events, preferences, hub, userFinder, application.EmailConfig{
+		Sender:     emailSender,
+		AppBaseURL: appBaseURL,
+	})
+	EnablePush(service, db, push)
 	handler := notification_http.NewNotificationHandler(service, hub)
 
 	return &Module{
@@ -29,6 +53,20 @@ func NewModule(db *sqlx.DB) *Module {
 	}
 }
 
+// EnablePush turns on Web Push for service when push has a key pair. An
+// invalid key pair is logged and leaves push disabled.
+func EnablePush(service *application.NotificationService, db *sqlx.DB, push webpush.Config) {
+	if !push.Enabled() {
+		return
+	}
+	sender, err := webpush.NewSender(push, nil)
+	if err != nil {
+		log.Printf("Warning: Web Push disabled: %v", err)
+		return
+	}
+	service.SetPush(postgres.NewPgPushSubscriptionRepository(db), sender)
+}
+
 func (m *Module) HTTPHandler() *notification_http.NotificationHandler {
 	return m.handler
 }
@@ -6,6 +6,7 @@ import (
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/jmoiron/sqlx"
 	"github.com/saransh1220/blueprint-audio/internal/modules/notification"
+	"github.com/saransh1220/blueprint-audio/internal/modules/notification/infrastructure/webpush"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -16,7 +17,7 @@ func TestNewModule(t *testing.T) {
 	defer sqlDB.Close()
 
 	db := sqlx.NewDb(sqlDB, "sqlmock")
-	m := notification.NewModule(db)
+	m := notification.NewModule(db, nil, nil, nil, "http://localhost:4200", webpush.Config{})
 	defer m.Shutdown()
 	require.NotNil(t, m)
 	assert.NotNil(t, m.HTTPHandler())
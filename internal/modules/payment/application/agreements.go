@@ -0,0 +1,503 @@
+package application
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	authDomain "github.com/saransh1220/blueprint-audio/internal/modules/auth/domain"
+	catalogDomain "github.com/saransh1220/blueprint-audio/internal/modules/catalog/domain"
+	"github.com/saransh1220/blueprint-audio/internal/modules/payment/domain"
+	sharedemail "github.com/saransh1220/blueprint-audio/internal/shared/infrastructure/email"
+	"github.com/saransh1220/blueprint-audio/internal/shared/pdf"
+	"github.com/saransh1220/blueprint-audio/internal/shared/qr"
+)
+
+const maxAgreementTermsLength = 20000
+
+// defaultAgreementTerms is printed on agreements for license options the
+// producer has not customised.
+const defaultAgreementTerms = `This non-exclusive license is granted by {{producer_name}} (the Licensor) to {{buyer_name}} (the Licensee) for the composition "{{spec_title}}", purchased on {{date}} for {{price}} under license key {{license_key}}.
+
+The Licensee may use the composition to record one new song and release it commercially within the limits above. The Licensor keeps ownership of the composition and may continue to license it to others.
+
+The Licensee may not resell, sublicense or give away the composition on its own, register it with a content identification system, or claim it as their own work.
+
+Going beyond the limits above requires a higher license. This license ends if the purchase is refunded or charged back.`
+
+// agreementPlaceholders are the fields agreement terms may reference as
+// {{name}}.
+var agreementPlaceholders = []string{"buyer_name", "producer_name", "spec_title", "license_type", "license_key", "price", "date"}
+
+var agreementPlaceholderPattern = regexp.MustCompile(`\{\{([^{}]*)\}\}`)
+
+// defaultAgreementLimits are the stream and distribution caps of each license
+// type. A nil cap is unlimited.
+var defaultAgreementLimits = map[catalogDomain.LicenseType][2]*int{
+	catalogDomain.LicenseBasic:     {agreementCap(10000), agreementCap(2000)},
+	catalogDomain.LicensePremium:   {agreementCap(100000), agreementCap(10000)},
+	catalogDomain.LicenseTrackout:  {agreementCap(500000), agreementCap(50000)},
+	catalogDomain.LicenseUnlimited: {nil, nil},
+}
+
+func agreementCap(v int) *int { return &v }
+
+// defaultAgreementTemplate returns the template used for options the producer
+// has not customised. Unknown license types get the Basic caps.
+func defaultAgreementTemplate(licenseOptionID uuid.UUID, licenseType string) *domain.AgreementTemplate {
+	limits, ok := defaultAgreementLimits[catalogDomain.LicenseType(licenseType)]
+	if !ok {
+		limits = defaultAgreementLimits[catalogDomain.LicenseBasic]
+	}
+	return &domain.AgreementTemplate{
+		LicenseOptionID:   licenseOptionID,
+		StreamLimit:       limits[0],
+		DistributionLimit: limits[1],
+		CreditRequired:    true,
+		Terms:             defaultAgreementTerms,
+	}
+}
+
+// AgreementData fills the placeholders of an agreement template.
+type AgreementData struct {
+	BuyerName    string
+	ProducerName string
+	SpecTitle    string
+	LicenseType  string
+	LicenseKey   string
+	Price        string
+	IssuedAt     time.Time
+	// VerifyURL is printed with a QR code so third parties can check the
+	// license. The section is left out when it is empty.
+	VerifyURL string
+}
+
+func renderAgreementTerms(terms string, data AgreementData) string {
+	return strings.NewReplacer(
+		"{{buyer_name}}", data.BuyerName,
+		"{{producer_name}}", data.ProducerName,
+		"{{spec_title}}", data.SpecTitle,
+		"{{license_type}}", data.LicenseType,
+		"{{license_key}}", data.LicenseKey,
+		"{{price}}", data.Price,
+		"{{date}}", data.IssuedAt.UTC().Format("2 January 2006"),
+	).Replace(terms)
+}
+
+// Layout of the agreement PDF, in points.
+const (
+	agreementMargin     = 50.0
+	agreementBottom     = 790.0
+	agreementValueX     = 160.0
+	agreementWidth      = pdf.PageWidth - 2*agreementMargin
+	agreementFontSize   = 10.0
+	agreementLineHeight = 14.0
+	agreementQRSize     = 84.0
+)
+
+// WriteAgreementPDF renders the license agreement as an A4 PDF: the purchase
+// details, the rights the template grants and its terms.
+func WriteAgreementPDF(w io.Writer, template *domain.AgreementTemplate, data AgreementData) error {
+	title := data.LicenseType + " License Agreement"
+	doc := pdf.New(title)
+	page := doc.AddPage()
+	y := 70.0
+
+	// ensureSpace starts a new page when the next lines would not fit.
+	ensureSpace := func(lines int) {
+		if y+float64(lines)*agreementLineHeight > agreementBottom {
+			page = doc.AddPage()
+			y = 70
+		}
+	}
+	heading := func(text string) {
+		ensureSpace(3)
+		y += 10
+		page.Text(agreementMargin, y, pdf.Bold, 12, text)
+		y += 6
+		page.Line(agreementMargin, y, pdf.PageWidth-agreementMargin, y)
+		y += agreementLineHeight + 2
+	}
+	paragraph := func(text string, indent float64) {
+		for _, line := range pdf.Wrap(pdf.Regular, agreementFontSize, agreementWidth-indent, text) {
+			ensureSpace(1)
+			page.Text(agreementMargin+indent, y, pdf.Regular, agreementFontSize, line)
+			y += agreementLineHeight
+		}
+	}
+
+	page.Text(agreementMargin, y, pdf.Bold, 20, title)
+	y += 22
+	page.Text(agreementMargin, y, pdf.Regular, agreementFontSize, "Non-exclusive license issued through Blueprint")
+	y += 14
+
+	heading("Details")
+	for _, row := range [][2]string{
+		{"License key", data.LicenseKey},
+		{"Title", data.SpecTitle},
+		{"Licensor", data.ProducerName},
+		{"Licensee", data.BuyerName},
+		{"License", data.LicenseType},
+		{"Price", data.Price},
+		{"Date", data.IssuedAt.UTC().Format("2 January 2006")},
+	} {
+		page.Text(agreementMargin, y, pdf.Bold, agreementFontSize, row[0])
+		page.Text(agreementValueX, y, pdf.Regular, agreementFontSize,
+			pdf.Truncate(pdf.Regular, agreementFontSize, pdf.PageWidth-agreementMargin-agreementValueX, row[1]))
+		y += agreementLineHeight
+	}
+
+	heading("Rights granted")
+	credit := "Credit: not required."
+	if template.CreditRequired {
+		credit = fmt.Sprintf(`Credit: the Licensee must credit the Licensor as "Prod. by %s" wherever the song is released.`, data.ProducerName)
+	}
+	for _, right := range []string{
+		"Audio streams: " + describeAgreementLimit(template.StreamLimit, "streams"),
+		"Distribution: " + describeAgreementLimit(template.DistributionLimit, "sold or distributed copies"),
+		credit,
+	} {
+		ensureSpace(1)
+		page.Text(agreementMargin, y, pdf.Regular, agreementFontSize, "-")
+		paragraph(right, 12)
+	}
+
+	heading("Terms")
+	for _, block := range strings.Split(strings.ReplaceAll(renderAgreementTerms(template.Terms, data), "\r\n", "\n"), "\n\n") {
+		if strings.TrimSpace(block) == "" {
+			continue
+		}
+		paragraph(block, 0)
+		y += agreementLineHeight / 2
+	}
+
+	if data.VerifyURL != "" {
+		if code, err := qr.Encode(data.VerifyURL); err == nil {
+			heading("Verification")
+			if y+agreementQRSize > agreementBottom {
+				page = doc.AddPage()
+				y = 70
+			}
+			drawQRCode(page, agreementMargin, y-agreementFontSize, agreementQRSize, code)
+			textX := agreementMargin + agreementQRSize + 16
+			lineY := y
+			for _, line := range pdf.Wrap(pdf.Regular, agreementFontSize, pdf.PageWidth-agreementMargin-textX,
+				"Scan the code or open the address below to check that this license is genuine and still active.") {
+				page.Text(textX, lineY, pdf.Regular, agreementFontSize, line)
+				lineY += agreementLineHeight
+			}
+			page.Text(textX, lineY+4, pdf.Regular, 8,
+				pdf.Truncate(pdf.Regular, 8, pdf.PageWidth-agreementMargin-textX, data.VerifyURL))
+			y += agreementQRSize
+		} else {
+			log.Printf("WriteAgreementPDF: skipping QR code. license_key=%s err=%v", data.LicenseKey, err)
+		}
+	}
+
+	ensureSpace(3)
+	y += agreementLineHeight
+	page.Text(agreementMargin, y, pdf.Regular, 8,
+		fmt.Sprintf("Issued by Blueprint on behalf of %s. License key %s.", data.ProducerName, data.LicenseKey))
+
+	_, err := doc.WriteTo(w)
+	return err
+}
+
+// drawQRCode draws code as a size by size square with its top-left corner at
+// x, y, including the four module quiet zone. Runs of dark modules in a row
+// are drawn as one rectangle.
+func drawQRCode(page *pdf.Page, x, y, size float64, code *qr.Code) {
+	module := size / float64(code.Size+8)
+	x += 4 * module
+	y += 4 * module
+	for row := 0; row < code.Size; row++ {
+		for col := 0; col < code.Size; {
+			if !code.Dark(col, row) {
+				col++
+				continue
+			}
+			start := col
+			for col < code.Size && code.Dark(col, row) {
+				col++
+			}
+			page.Rect(x+float64(start)*module, y+float64(row)*module, float64(col-start)*module, module)
+		}
+	}
+}
+
+func describeAgreementLimit(limit *int, unit string) string {
+	if limit == nil {
+		return "unlimited " + unit + "."
+	}
+	return fmt.Sprintf("up to %s %s.", formatCount(*limit), unit)
+}
+
+// formatCount writes n with thousands separators.
+func formatCount(n int) string {
+	digits := fmt.Sprintf("%d", n)
+	var b strings.Builder
+	for i, digit := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(digit)
+	}
+	return b.String()
+}
+
+func agreementKey(licenseID uuid.UUID) string {
+	return "agreements/" + licenseID.String() + ".pdf"
+}
+
+func agreementFilename(license *domain.License) string {
+	return "license-agreement-" + license.LicenseKey + ".pdf"
+}
+
+func userDisplayName(user *authDomain.User) string {
+	if user.DisplayName != nil && strings.TrimSpace(*user.DisplayName) != "" {
+		return strings.TrimSpace(*user.DisplayName)
+	}
+	return user.Name
+}
+
+// agreementTemplate returns the producer's template for the option or the
+// defaults for its license type.
+func (s *paymentService) agreementTemplate(ctx context.Context, licenseOptionID uuid.UUID, licenseType string) (*domain.AgreementTemplate, error) {
+	template, err := s.agreementTemplates.GetByLicenseOptionID(ctx, licenseOptionID)
+	if errors.Is(err, domain.ErrAgreementTemplateNotFound) {
+		return defaultAgreementTemplate(licenseOptionID, licenseType), nil
+	}
+	return template, err
+}
+
+// snapshotAgreements copies the current template of each license's option
+// onto the license, so its agreement is always rendered with the terms it was
+// sold under.
+func (s *paymentService) snapshotAgreements(ctx context.Context, licenses []domain.License) error {
+	if s.agreementTemplates == nil {
+		return nil
+	}
+	for i := range licenses {
+		template, err := s.agreementTemplate(ctx, licenses[i].LicenseOptionID, licenses[i].LicenseType)
+		if err != nil {
+			return err
+		}
+		terms := template.Terms
+		licenses[i].AgreementStreamLimit = copyAgreementLimit(template.StreamLimit)
+		licenses[i].AgreementDistributionLimit = copyAgreementLimit(template.DistributionLimit)
+		licenses[i].AgreementCreditRequired = template.CreditRequired
+		licenses[i].AgreementTerms = &terms
+	}
+	return nil
+}
+
+func copyAgreementLimit(limit *int) *int {
+	if limit == nil {
+		return nil
+	}
+	return agreementCap(*limit)
+}
+
+// licenseAgreementTemplate returns the template snapshotted on the license,
+// or nil for licenses issued before snapshots were kept.
+func licenseAgreementTemplate(license *domain.License) *domain.AgreementTemplate {
+	if license.AgreementTerms == nil {
+		return nil
+	}
+	return &domain.AgreementTemplate{
+		LicenseOptionID:   license.LicenseOptionID,
+		StreamLimit:       license.AgreementStreamLimit,
+		DistributionLimit: license.AgreementDistributionLimit,
+		CreditRequired:    license.AgreementCreditRequired,
+		Terms:             *license.AgreementTerms,
+	}
+}
+
+// renderAgreement renders the license's agreement from its snapshotted
+// template, never from the option's current one.
+func (s *paymentService) renderAgreement(ctx context.Context, license *domain.License) ([]byte, error) {
+	template := licenseAgreementTemplate(license)
+	if template == nil {
+		return nil, domain.ErrAgreementUnavailable
+	}
+	spec, err := s.specFinder.FindByIDIncludingDeleted(ctx, license.SpecID)
+	if err != nil {
+		return nil, fmt.Errorf("find spec: %w", err)
+	}
+	buyer, err := s.userFinder.FindByID(ctx, license.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("find buyer: %w", err)
+	}
+	producer, err := s.userFinder.FindByID(ctx, spec.ProducerID)
+	if err != nil {
+		return nil, fmt.Errorf("find producer: %w", err)
+	}
+
+	var buf bytes.Buffer
+	err = WriteAgreementPDF(&buf, template, AgreementData{
+		BuyerName:    userDisplayName(buyer),
+		ProducerName: userDisplayName(producer),
+		SpecTitle:    spec.Title,
+		LicenseType:  license.LicenseType,
+		LicenseKey:   license.LicenseKey,
+		Price:        formatMoney(license.PurchasePrice, license.Currency),
+		IssuedAt:     license.IssuedAt,
+		VerifyURL:    s.licenseVerificationURL(license.LicenseKey),
+	})
+	return buf.Bytes(), err
+}
+
+// issueAgreement renders the license's agreement, stores it and records its
+// key on the license.
+func (s *paymentService) issueAgreement(ctx context.Context, license *domain.License) ([]byte, error) {
+	content, err := s.renderAgreement(ctx, license)
+	if err != nil {
+		return nil, err
+	}
+	key := agreementKey(license.ID)
+	if _, err := s.fileService.UploadWithKey(ctx, bytes.NewReader(content), key, "application/pdf"); err != nil {
+		return nil, fmt.Errorf("upload agreement: %w", err)
+	}
+	if err := s.licenseRepo.SetAgreementKey(ctx, license.ID, key); err != nil {
+		return nil, fmt.Errorf("record agreement key: %w", err)
+	}
+	license.AgreementKey = &key
+	return content, nil
+}
+
+// issueAgreements issues the agreement of each license and returns them as
+// receipt attachments. A failure only drops that attachment: the agreement
+// is issued again when the buyer downloads it.
+func (s *paymentService) issueAgreements(ctx context.Context, licenses []domain.License) []sharedemail.Attachment {
+	if s.agreementTemplates == nil || s.fileService == nil || s.userFinder == nil {
+		return nil
+	}
+	var attachments []sharedemail.Attachment
+	for i := range licenses {
+		content, err := s.issueAgreement(ctx, &licenses[i])
+		if err != nil {
+			log.Printf("PaymentService.issueAgreements failed. license_id=%s err=%v", licenses[i].ID, err)
+			continue
+		}
+		attachments = append(attachments, sharedemail.Attachment{
+			Filename: agreementFilename(&licenses[i]),
+			Content:  content,
+		})
+	}
+	return attachments
+}
+
+// GetLicenseAgreement returns the agreement PDF of a license the user owns.
+// Agreements that were not stored at issue time are issued now from the
+// license's snapshotted template.
+func (s *paymentService) GetLicenseAgreement(ctx context.Context, licenseID, userID uuid.UUID) (*LicenseAgreement, error) {
+	license, err := s.licenseRepo.GetByID(ctx, licenseID)
+	if err != nil {
+		return nil, err
+	}
+	if license.UserID != userID {
+		return nil, domain.ErrLicenseNotFound
+	}
+	if license.IsRevoked {
+		return nil, domain.ErrLicenseRevoked
+	}
+
+	if license.AgreementKey != nil {
+		content, err := s.readAgreement(ctx, *license.AgreementKey)
+		if err == nil {
+			return &LicenseAgreement{Filename: agreementFilename(license), Content: content}, nil
+		}
+		if license.AgreementTerms == nil {
+			return nil, fmt.Errorf("read agreement: %w", err)
+		}
+		log.Printf("PaymentService.GetLicenseAgreement read failed, reissuing. license_id=%s err=%v", license.ID, err)
+	}
+	content, err := s.issueAgreement(ctx, license)
+	if err != nil {
+		return nil, err
+	}
+	return &LicenseAgreement{Filename: agreementFilename(license), Content: content}, nil
+}
+
+func (s *paymentService) readAgreement(ctx context.Context, key string) ([]byte, error) {
+	object, err := s.fileService.OpenObject(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer object.Close()
+	return io.ReadAll(object)
+}
+
+// ownedLicenseOption returns the license option if it belongs to one of the
+// producer's specs. Other producers' options are reported as not found.
+func (s *paymentService) ownedLicenseOption(ctx context.Context, producerID, licenseOptionID uuid.UUID) (*catalogDomain.LicenseOption, error) {
+	option, err := s.specFinder.GetLicenseByID(ctx, licenseOptionID)
+	if err != nil || option == nil {
+		return nil, domain.ErrLicenseOptionNotFound
+	}
+	spec, err := s.specFinder.FindByID(ctx, option.SpecID)
+	if err != nil || spec.ProducerID != producerID {
+		return nil, domain.ErrLicenseOptionNotFound
+	}
+	return option, nil
+}
+
+func (s *paymentService) GetAgreementTemplate(ctx context.Context, producerID, licenseOptionID uuid.UUID) (*domain.AgreementTemplate, error) {
+	option, err := s.ownedLicenseOption(ctx, producerID, licenseOptionID)
+	if err != nil {
+		return nil, err
+	}
+	return s.agreementTemplate(ctx, option.ID, string(option.LicenseType))
+}
+
+// UpdateAgreementTemplate replaces the option's agreement template. Licenses
+// already issued keep the agreement they were issued with.
+func (s *paymentService) UpdateAgreementTemplate(ctx context.Context, producerID, licenseOptionID uuid.UUID, input AgreementTemplateInput) (*domain.AgreementTemplate, error) {
+	option, err := s.ownedLicenseOption(ctx, producerID, licenseOptionID)
+	if err != nil {
+		return nil, err
+	}
+	template := &domain.AgreementTemplate{
+		LicenseOptionID:   option.ID,
+		StreamLimit:       input.StreamLimit,
+		DistributionLimit: input.DistributionLimit,
+		CreditRequired:    input.CreditRequired,
+		Terms:             strings.TrimSpace(input.Terms),
+	}
+	if err := validateAgreementTemplate(template); err != nil {
+		return nil, err
+	}
+	if err := s.agreementTemplates.Upsert(ctx, template); err != nil {
+		return nil, err
+	}
+	return template, nil
+}
+
+func validateAgreementTemplate(template *domain.AgreementTemplate) error {
+	if (template.StreamLimit != nil && *template.StreamLimit < 0) ||
+		(template.DistributionLimit != nil && *template.DistributionLimit < 0) {
+		return fmt.Errorf("%w: limits cannot be negative", domain.ErrInvalidAgreementTemplate)
+	}
+	if template.Terms == "" {
+		return fmt.Errorf("%w: terms are required", domain.ErrInvalidAgreementTemplate)
+	}
+	if utf8.RuneCountInString(template.Terms) > maxAgreementTermsLength {
+		return fmt.Errorf("%w: terms cannot exceed %d characters", domain.ErrInvalidAgreementTemplate, maxAgreementTermsLength)
+	}
+	for _, match := range agreementPlaceholderPattern.FindAllStringSubmatch(template.Terms, -1) {
+		if !slices.Contains(agreementPlaceholders, match[1]) {
+			return fmt.Errorf("%w: unknown placeholder %s, expected one of %s",
+				domain.ErrInvalidAgreementTemplate, match[0], strings.Join(agreementPlaceholders, ", "))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,236 @@
+package application
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	authDomain "github.com/saransh1220/blueprint-audio/internal/modules/auth/domain"
+	catalogDomain "github.com/saransh1220/blueprint-audio/internal/modules/catalog/domain"
+	"github.com/saransh1220/blueprint-audio/internal/modules/payment/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type agreementTemplateRepoMock struct{ mock.Mock }
+
+func (m *agreementTemplateRepoMock) GetByLicenseOptionID(ctx context.Context, licenseOptionID uuid.UUID) (*domain.AgreementTemplate, error) {
+	args := m.Called(ctx, licenseOptionID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.AgreementTemplate), args.Error(1)
+}
+func (m *agreementTemplateRepoMock) Upsert(ctx context.Context, template *domain.AgreementTemplate) error {
+	return m.Called(ctx, template).Error(0)
+}
+
+func newAgreementSvc() (*paymentService, *licenseRepoMock, *agreementTemplateRepoMock, *specFinderMock, *fileSvcMock, *userFinderMock) {
+	s, _, _, lr, sf, fs, uf, _ := newPaymentSvc()
+	ar := new(agreementTemplateRepoMock)
+	s.agreementTemplates = ar
+	return s, lr, ar, sf, fs, uf
+}
+
+func TestWriteAgreementPDF(t *testing.T) {
+	data := AgreementData{
+		BuyerName:    "Asha",
+		ProducerName: "Night Owl",
+		SpecTitle:    "Midnight Drive",
+		LicenseType:  "Premium",
+		LicenseKey:   "LIC-123",
+		Price:        "INR 1999.00",
+		IssuedAt:     time.Date(2026, 5, 4, 10, 0, 0, 0, time.UTC),
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteAgreementPDF(&buf, defaultAgreementTemplate(uuid.New(), "Premium"), data))
+	out := buf.String()
+	assert.True(t, strings.HasPrefix(out, "%PDF-"))
+	assert.Contains(t, out, "(Premium License Agreement) Tj")
+	assert.Contains(t, out, "(LIC-123) Tj")
+	assert.Contains(t, out, "up to 100,000 streams.")
+	assert.Contains(t, out, "up to 10,000 sold or distributed copies.")
+	assert.Contains(t, out, `Prod. by Night Owl`)
+	assert.Contains(t, out, "Asha \\(the Licensee\\)")
+	assert.Contains(t, out, "4 May 2026")
+	assert.NotContains(t, out, "{{")
+	assert.Contains(t, out, "/Count 1 >>")
+	assert.NotContains(t, out, " re f")
+
+	// With a verification URL the agreement carries its QR code.
+	buf.Reset()
+	data.VerifyURL = "https://api.example.com/verify/licenses/LIC-123"
+	require.NoError(t, WriteAgreementPDF(&buf, defaultAgreementTemplate(uuid.New(), "Premium"), data))
+	assert.Contains(t, buf.String(), "(https://api.example.com/verify/licenses/LIC-123) Tj")
+	assert.Contains(t, buf.String(), " re f\n")
+
+	// Long terms flow onto further pages.
+	buf.Reset()
+	long := &domain.AgreementTemplate{Terms: strings.Repeat("The Licensee agrees to these terms. ", 400)}
+	require.NoError(t, WriteAgreementPDF(&buf, long, data))
+	assert.Contains(t, buf.String(), "unlimited streams.")
+	assert.Contains(t, buf.String(), "Credit: not required.")
+	assert.Regexp(t, `/Count [2-9] >>`, buf.String())
+}
+
+func TestDefaultAgreementTemplate(t *testing.T) {
+	unlimited := defaultAgreementTemplate(uuid.New(), "Unlimited")
+	assert.Nil(t, unlimited.StreamLimit)
+	assert.Nil(t, unlimited.DistributionLimit)
+	assert.False(t, unlimited.IsCustom)
+
+	unknown := defaultAgreementTemplate(uuid.New(), "Exclusive")
+	require.NotNil(t, unknown.StreamLimit)
+	assert.Equal(t, 10000, *unknown.StreamLimit)
+}
+
+func TestPaymentService_IssueAgreements(t *testing.T) {
+	s, lr, ar, sf, fs, uf := newAgreementSvc()
+	ctx := context.Background()
+	producerID, buyerID, optionID := uuid.New(), uuid.New(), uuid.New()
+	licenses := []domain.License{
+		{ID: uuid.New(), UserID: buyerID, SpecID: uuid.New(), LicenseOptionID: optionID, LicenseType: "Basic", LicenseKey: "LIC-1", PurchasePrice: 99900, Currency: "INR"},
+		{ID: uuid.New(), UserID: buyerID, SpecID: uuid.New(), LicenseOptionID: uuid.New(), LicenseType: "Basic", LicenseKey: "LIC-2"},
+	}
+	custom := &domain.AgreementTemplate{LicenseOptionID: optionID, StreamLimit: agreementCap(5000), CreditRequired: true, Terms: "Issued to {{buyer_name}} for {{price}}.", IsCustom: true}
+	ar.On("GetByLicenseOptionID", ctx, optionID).Return(custom, nil).Once()
+	ar.On("GetByLicenseOptionID", ctx, licenses[1].LicenseOptionID).Return(nil, domain.ErrAgreementTemplateNotFound).Once()
+	require.NoError(t, s.snapshotAgreements(ctx, licenses))
+	assert.Equal(t, custom.Terms, *licenses[0].AgreementTerms)
+	assert.Equal(t, defaultAgreementTerms, *licenses[1].AgreementTerms)
+
+	// Editing the template afterwards does not change the issued agreement.
+	custom.Terms = "Edited {{buyer_name}}."
+	custom.StreamLimit = agreementCap(1)
+
+	sf.On("FindByIDIncludingDeleted", ctx, licenses[0].SpecID).Return(&catalogDomain.Spec{ID: licenses[0].SpecID, ProducerID: producerID, Title: "Midnight Drive"}, nil).Once()
+	uf.On("FindByID", ctx, buyerID).Return(&authDomain.User{ID: buyerID, Name: "Asha"}, nil).Once()
+	displayName := "Night Owl"
+	uf.On("FindByID", ctx, producerID).Return(&authDomain.User{ID: producerID, Name: "Sam", DisplayName: &displayName}, nil).Once()
+	var uploaded []byte
+	fs.On("UploadWithKey", ctx, mock.Anything, "agreements/"+licenses[0].ID.String()+".pdf", "application/pdf").
+		Run(func(args mock.Arguments) { uploaded, _ = io.ReadAll(args.Get(1).(io.Reader)) }).
+		Return("https://cdn.example/agreement.pdf", nil).Once()
+	lr.On("SetAgreementKey", ctx, licenses[0].ID, "agreements/"+licenses[0].ID.String()+".pdf").Return(nil).Once()
+
+	// The second license's spec is gone; its agreement is skipped.
+	sf.On("FindByIDIncludingDeleted", ctx, licenses[1].SpecID).Return(nil, errors.New("not found")).Once()
+
+	attachments := s.issueAgreements(ctx, licenses)
+	require.Len(t, attachments, 1)
+	assert.Equal(t, "license-agreement-LIC-1.pdf", attachments[0].Filename)
+	assert.Equal(t, uploaded, attachments[0].Content)
+	assert.Contains(t, string(uploaded), "Issued to Asha for INR 999.00.")
+	assert.Contains(t, string(uploaded), "Prod. by Night Owl")
+	assert.Contains(t, string(uploaded), "up to 5,000 streams.")
+	assert.Contains(t, string(uploaded), "(https://api.example.com/verify/licenses/LIC-1) Tj")
+	require.NotNil(t, licenses[0].AgreementKey)
+	assert.Nil(t, licenses[1].AgreementKey)
+	mock.AssertExpectationsForObjects(t, lr, ar, sf, fs, uf)
+
+	// Without a template repository, as in most tests, nothing is issued.
+	s.agreementTemplates = nil
+	assert.Nil(t, s.issueAgreements(ctx, licenses))
+}
+
+func TestPaymentService_GetLicenseAgreement(t *testing.T) {
+	s, lr, ar, sf, fs, uf := newAgreementSvc()
+	ctx := context.Background()
+	userID, licenseID := uuid.New(), uuid.New()
+	key := "agreements/" + licenseID.String() + ".pdf"
+
+	lr.On("GetByID", ctx, licenseID).Return(&domain.License{ID: licenseID, UserID: uuid.New()}, nil).Once()
+	_, err := s.GetLicenseAgreement(ctx, licenseID, userID)
+	assert.ErrorIs(t, err, domain.ErrLicenseNotFound)
+
+	lr.On("GetByID", ctx, licenseID).Return(&domain.License{ID: licenseID, UserID: userID, IsRevoked: true}, nil).Once()
+	_, err = s.GetLicenseAgreement(ctx, licenseID, userID)
+	assert.ErrorIs(t, err, domain.ErrLicenseRevoked)
+
+	// A stored agreement is returned as issued.
+	lr.On("GetByID", ctx, licenseID).Return(&domain.License{ID: licenseID, UserID: userID, LicenseKey: "LIC-9", AgreementKey: &key}, nil).Once()
+	fs.On("OpenObject", ctx, key).Return(io.NopCloser(strings.NewReader("%PDF-stored")), nil).Once()
+	agreement, err := s.GetLicenseAgreement(ctx, licenseID, userID)
+	require.NoError(t, err)
+	assert.Equal(t, "license-agreement-LIC-9.pdf", agreement.Filename)
+	assert.Equal(t, "%PDF-stored", string(agreement.Content))
+
+	// Database errors are not reported as a missing license.
+	lr.On("GetByID", ctx, licenseID).Return(nil, errors.New("db down")).Once()
+	_, err = s.GetLicenseAgreement(ctx, licenseID, userID)
+	assert.EqualError(t, err, "db down")
+
+	// Without a stored agreement or a snapshot the agreement cannot be
+	// reproduced; it is never rendered from the option's current template.
+	specID, optionID := uuid.New(), uuid.New()
+	lr.On("GetByID", ctx, licenseID).Return(&domain.License{ID: licenseID, UserID: userID, SpecID: specID, LicenseOptionID: optionID, LicenseType: "Trackout", LicenseKey: "LIC-9"}, nil).Once()
+	_, err = s.GetLicenseAgreement(ctx, licenseID, userID)
+	assert.ErrorIs(t, err, domain.ErrAgreementUnavailable)
+
+	lr.On("GetByID", ctx, licenseID).Return(&domain.License{ID: licenseID, UserID: userID, LicenseKey: "LIC-9", AgreementKey: &key}, nil).Once()
+	fs.On("OpenObject", ctx, key).Return(nil, errors.New("storage down")).Once()
+	_, err = s.GetLicenseAgreement(ctx, licenseID, userID)
+	assert.EqualError(t, err, "read agreement: storage down")
+
+	// One that was never stored is issued now from its snapshot.
+	trackout := defaultAgreementTemplate(optionID, "Trackout")
+	lr.On("GetByID", ctx, licenseID).Return(&domain.License{
+		ID: licenseID, UserID: userID, SpecID: specID, LicenseOptionID: optionID, LicenseType: "Trackout", LicenseKey: "LIC-9",
+		AgreementStreamLimit: trackout.StreamLimit, AgreementDistributionLimit: trackout.DistributionLimit,
+		AgreementCreditRequired: true, AgreementTerms: &trackout.Terms,
+	}, nil).Once()
+	sf.On("FindByIDIncludingDeleted", ctx, specID).Return(&catalogDomain.Spec{ID: specID, ProducerID: userID, Title: "Track"}, nil).Once()
+	uf.On("FindByID", ctx, userID).Return(&authDomain.User{ID: userID, Name: "Asha"}, nil).Twice()
+	fs.On("UploadWithKey", ctx, mock.Anything, key, "application/pdf").Return("", nil).Once()
+	lr.On("SetAgreementKey", ctx, licenseID, key).Return(nil).Once()
+	agreement, err = s.GetLicenseAgreement(ctx, licenseID, userID)
+	require.NoError(t, err)
+	assert.Contains(t, string(agreement.Content), "up to 500,000 streams.")
+	mock.AssertExpectationsForObjects(t, lr, ar, sf, fs, uf)
+}
+
+func TestPaymentService_AgreementTemplates(t *testing.T) {
+	s, _, ar, sf, _, _ := newAgreementSvc()
+	ctx := context.Background()
+	producerID, specID, optionID := uuid.New(), uuid.New(), uuid.New()
+	option := &catalogDomain.LicenseOption{ID: optionID, SpecID: specID, LicenseType: catalogDomain.LicensePremium}
+	sf.On("GetLicenseByID", ctx, optionID).Return(option, nil)
+	sf.On("FindByID", ctx, specID).Return(&catalogDomain.Spec{ID: specID, ProducerID: producerID}, nil)
+
+	_, err := s.GetAgreementTemplate(ctx, uuid.New(), optionID)
+	assert.ErrorIs(t, err, domain.ErrLicenseOptionNotFound)
+
+	ar.On("GetByLicenseOptionID", ctx, optionID).Return(nil, domain.ErrAgreementTemplateNotFound).Once()
+	template, err := s.GetAgreementTemplate(ctx, producerID, optionID)
+	require.NoError(t, err)
+	assert.False(t, template.IsCustom)
+	assert.Equal(t, 100000, *template.StreamLimit)
+
+	for _, input := range []AgreementTemplateInput{
+		{Terms: "  "},
+		{Terms: "Terms", StreamLimit: agreementCap(-1)},
+		{Terms: "Hello {{buyer}}"},
+		{Terms: strings.Repeat("a", maxAgreementTermsLength+1)},
+	} {
+		_, err := s.UpdateAgreementTemplate(ctx, producerID, optionID, input)
+		assert.ErrorIs(t, err, domain.ErrInvalidAgreementTemplate)
+	}
+
+	ar.On("Upsert", ctx, mock.MatchedBy(func(template *domain.AgreementTemplate) bool {
+		return template.LicenseOptionID == optionID && template.StreamLimit == nil && *template.DistributionLimit == 500 &&
+			template.Terms == "For {{buyer_name}} only."
+	})).Return(nil).Once()
+	_, err = s.UpdateAgreementTemplate(ctx, producerID, optionID, AgreementTemplateInput{
+		DistributionLimit: agreementCap(500),
+		Terms:             " For {{buyer_name}} only. ",
+	})
+	require.NoError(t, err)
+	ar.AssertExpectations(t)
+}
@@ -0,0 +1,112 @@
+package application
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/saransh1220/blueprint-audio/internal/modules/payment/domain"
+	sharedmoney "github.com/saransh1220/blueprint-audio/internal/shared/money"
+)
+
+// maxCartItems caps a cart so a single checkout stays within a sane number of
+// licenses.
+const maxCartItems = 50
+
+func (s *paymentService) GetCart(ctx context.Context, userID uuid.UUID, currency string) (*CartResponse, error) {
+	items, err := s.cartRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	cartCurrency := resolveOrderCurrency(currency)
+	rates := sharedmoney.CurrentRates()
+	cart := &CartResponse{Items: make([]CartItemDto, 0, len(items)), Currency: cartCurrency}
+	for _, item := range items {
+		dto := CartItemDto{
+			SpecID:          item.SpecID,
+			LicenseOptionID: item.LicenseOptionID,
+			AddedAt:         item.CreatedAt,
+		}
+		if quoted, err := s.quoteItem(ctx, rates, item.SpecID, item.LicenseOptionID, cartCurrency); err == nil {
+			dto.SpecTitle = quoted.SpecTitle
+			dto.LicenseType = quoted.LicenseType
+			dto.LicenseName = quoted.LicenseName
+			dto.Amount = quoted.Amount
+			dto.Available = true
+			cart.Total += quoted.Amount
+		}
+		cart.Items = append(cart.Items, dto)
+	}
+	return cart, nil
+}
+
+// AddToCart puts a spec in the cart, replacing the license option if the spec
+// is already there.
+func (s *paymentService) AddToCart(ctx context.Context, userID, specID, licenseOptionID uuid.UUID, currency string) (*CartResponse, error) {
+	if _, err := s.quoteItem(ctx, sharedmoney.CurrentRates(), specID, licenseOptionID, resolveOrderCurrency(currency)); err != nil {
+		return nil, err
+	}
+
+	items, err := s.cartRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) >= maxCartItems && !cartContains(items, specID) {
+		return nil, domain.ErrCartFull
+	}
+
+	if err := s.cartRepo.Upsert(ctx, &domain.CartItem{
+		UserID:          userID,
+		SpecID:          specID,
+		LicenseOptionID: licenseOptionID,
+	}); err != nil {
+		return nil, err
+	}
+	return s.GetCart(ctx, userID, currency)
+}
+
+func (s *paymentService) RemoveFromCart(ctx context.Context, userID, specID uuid.UUID, currency string) (*CartResponse, error) {
+	if err := s.cartRepo.Remove(ctx, userID, specID); err != nil {
+		return nil, err
+	}
+	return s.GetCart(ctx, userID, currency)
+}
+
+func (s *paymentService) ClearCart(ctx context.Context, userID uuid.UUID) error {
+	return s.cartRepo.Clear(ctx, userID)
+}
+
+// Checkout re-prices every cart item against the catalog and opens one order
+// for the total. The cart is left alone until the order is paid, so an
+// abandoned checkout does not lose it.
+func (s *paymentService) Checkout(ctx context.Context, userID uuid.UUID, currency, couponCode string) (*domain.Order, error) {
+	cartItems, err := s.cartRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(cartItems) == 0 {
+		return nil, domain.ErrCartEmpty
+	}
+
+	orderCurrency := resolveOrderCurrency(currency)
+	rates := sharedmoney.CurrentRates()
+	items := make([]quotedItem, 0, len(cartItems))
+	for _, cartItem := range cartItems {
+		item, err := s.quoteItem(ctx, rates, cartItem.SpecID, cartItem.LicenseOptionID, orderCurrency)
+		if err != nil {
+			return nil, fmt.Errorf("%w: spec %s: %v", domain.ErrCartItemUnavailable, cartItem.SpecID, err)
+		}
+		items = append(items, *item)
+	}
+	return s.placeOrder(ctx, userID, rates, items, orderCurrency, couponCode)
+}
+
+func cartContains(items []domain.CartItem, specID uuid.UUID) bool {
+	for _, item := range items {
+		if item.SpecID == specID {
+			return true
+		}
+	}
+	return false
+}
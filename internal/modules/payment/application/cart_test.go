@@ -0,0 +1,247 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/razorpay/razorpay-go"
+	catalogDomain "github.com/saransh1220/blueprint-audio/internal/modules/catalog/domain"
+	notificationDomain "github.com/saransh1220/blueprint-audio/internal/modules/notification/domain"
+	"github.com/saransh1220/blueprint-audio/internal/modules/payment/domain"
+	sharedmoney "github.com/saransh1220/blueprint-audio/internal/shared/money"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type cartRepoMock struct{ mock.Mock }
+
+func (m *cartRepoMock) ListByUser(ctx context.Context, userID uuid.UUID) ([]domain.CartItem, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.CartItem), args.Error(1)
+}
+func (m *cartRepoMock) Upsert(ctx context.Context, item *domain.CartItem) error {
+	args := m.Called(ctx, item)
+	return args.Error(0)
+}
+func (m *cartRepoMock) Remove(ctx context.Context, userID, specID uuid.UUID) error {
+	args := m.Called(ctx, userID, specID)
+	return args.Error(0)
+}
+func (m *cartRepoMock) Clear(ctx context.Context, userID uuid.UUID) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func newCartSvc() (*paymentService, *orderRepoMock, *cartRepoMock, *specFinderMock) {
+	s, or, _, _, sf, _, _, _ := newPaymentSvc()
+	cr := new(cartRepoMock)
+	s.cartRepo = cr
+	return s, or, cr, sf
+}
+
+func catalogSpec(title string, priceMinor int64) (*catalogDomain.Spec, uuid.UUID) {
+	loID := uuid.New()
+	return &catalogDomain.Spec{
+		ID:    uuid.New(),
+		Title: title,
+		Licenses: []catalogDomain.LicenseOption{
+			{ID: loID, LicenseType: catalogDomain.LicenseBasic, Name: "Basic", PriceMinor: priceMinor, PriceCurrency: "INR"},
+		},
+	}, loID
+}
+
+func TestPaymentService_Checkout_OneOrderForWholeCart(t *testing.T) {
+	s, or, cr, sf := newCartSvc()
+	ctx := context.Background()
+	userID := uuid.New()
+	first, firstLO := catalogSpec("Night Drive", 49900)
+	second, secondLO := catalogSpec("Low Tide", 99900)
+
+	var razorpayAmounts []float64
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		razorpayAmounts = append(razorpayAmounts, body["amount"].(float64))
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": "order_cart_1"})
+	}))
+	defer ts.Close()
+	s.razorpayClient = razorpay.NewClient("key", "secret")
+	s.razorpayClient.Request.BaseURL = ts.URL
+
+	cr.On("ListByUser", ctx, userID).Return([]domain.CartItem{
+		{UserID: userID, SpecID: first.ID, LicenseOptionID: firstLO},
+		{UserID: userID, SpecID: second.ID, LicenseOptionID: secondLO},
+	}, nil).Once()
+	sf.On("FindWithLicenses", ctx, first.ID).Return(first, nil).Once()
+	sf.On("FindWithLicenses", ctx, second.ID).Return(second, nil).Once()
+	or.On("Create", ctx, mock.MatchedBy(func(order *domain.Order) bool {
+		return len(order.Items) == 2 && order.SpecID == uuid.Nil && order.LicenseType == ""
+	})).Return(nil).Once()
+
+	order, err := s.Checkout(ctx, userID, "INR", "")
+	require.NoError(t, err)
+	assert.Equal(t, 149800, order.Amount)
+	assert.Equal(t, []float64{149800}, razorpayAmounts)
+	assert.Equal(t, "Night Drive + 1 more", order.Notes["spec_title"])
+	assert.Equal(t, 49900, order.Items[0].Amount)
+	assert.Equal(t, 99900, order.Items[1].Amount)
+	or.AssertExpectations(t)
+	cr.AssertNotCalled(t, "Clear", mock.Anything, mock.Anything)
+}
+
+func TestPaymentService_Checkout_ChargesEurozoneBuyersInUSD(t *testing.T) {
+	s, or, cr, sf := newCartSvc()
+	ctx := context.Background()
+	userID := uuid.New()
+	spec, loID := catalogSpec("Night Drive", 50000)
+
+	var dodoBody map[string]any
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&dodoBody))
+		_ = json.NewEncoder(w).Encode(map[string]any{"session_id": "cks_de", "checkout_url": "https://checkout.test/cks_de"})
+	}))
+	defer ts.Close()
+	s.dodoConfig = DodoConfig{APIKey: "key", ProductID: "prod", APIURL: ts.URL}
+	sharedmoney.SetRates(&sharedmoney.Rates{Base: sharedmoney.CurrencyUSD, Rates: map[string]float64{"INR": 80, "EUR": 0.9}})
+	defer sharedmoney.SetRates(nil)
+
+	cr.On("ListByUser", ctx, userID).Return([]domain.CartItem{{UserID: userID, SpecID: spec.ID, LicenseOptionID: loID}}, nil).Once()
+	sf.On("FindWithLicenses", ctx, spec.ID).Return(spec, nil).Once()
+	or.On("Create", ctx, mock.AnythingOfType("*domain.Order")).Return(nil).Once()
+
+	req := httptest.NewRequest(http.MethodPost, "/cart/checkout", nil)
+	req.Header.Set("CF-IPCountry", "DE")
+	order, err := s.Checkout(ctx, userID, sharedmoney.ResolveCurrencyFromRequest(req), "")
+	require.NoError(t, err)
+	assert.Equal(t, sharedmoney.CurrencyUSD, order.Currency)
+	assert.Equal(t, "dodo", order.Provider)
+	assert.Equal(t, 699, order.Amount)
+	cart := dodoBody["product_cart"].([]any)
+	assert.Equal(t, float64(699), cart[0].(map[string]any)["amount"])
+}
+
+func TestPaymentService_Checkout_RejectsUnavailableItems(t *testing.T) {
+	s, or, cr, sf := newCartSvc()
+	ctx := context.Background()
+	userID := uuid.New()
+	ready, readyLO := catalogSpec("Night Drive", 49900)
+	processing, processingLO := catalogSpec("Low Tide", 99900)
+	processing.ProcessingStatus = catalogDomain.ProcessingStatusProcessing
+	deletedID := uuid.New()
+
+	cr.On("ListByUser", ctx, userID).Return([]domain.CartItem{
+		{SpecID: ready.ID, LicenseOptionID: readyLO},
+		{SpecID: processing.ID, LicenseOptionID: processingLO},
+	}, nil).Once()
+	sf.On("FindWithLicenses", ctx, ready.ID).Return(ready, nil).Once()
+	sf.On("FindWithLicenses", ctx, processing.ID).Return(processing, nil).Once()
+	_, err := s.Checkout(ctx, userID, "INR", "")
+	assert.ErrorIs(t, err, domain.ErrCartItemUnavailable)
+	assert.Contains(t, err.Error(), processing.ID.String())
+
+	cr.On("ListByUser", ctx, userID).Return([]domain.CartItem{{SpecID: deletedID, LicenseOptionID: uuid.New()}}, nil).Once()
+	sf.On("FindWithLicenses", ctx, deletedID).Return(nil, errors.New("not found")).Once()
+	_, err = s.Checkout(ctx, userID, "INR", "")
+	assert.ErrorIs(t, err, domain.ErrCartItemUnavailable)
+
+	cr.On("ListByUser", ctx, userID).Return([]domain.CartItem{}, nil).Once()
+	_, err = s.Checkout(ctx, userID, "INR", "")
+	assert.ErrorIs(t, err, domain.ErrCartEmpty)
+
+	or.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestPaymentService_GetCart_FlagsUnavailableItems(t *testing.T) {
+	s, _, cr, sf := newCartSvc()
+	ctx := context.Background()
+	userID := uuid.New()
+	spec, loID := catalogSpec("Night Drive", 49900)
+	deletedID := uuid.New()
+
+	cr.On("ListByUser", ctx, userID).Return([]domain.CartItem{
+		{SpecID: spec.ID, LicenseOptionID: loID},
+		{SpecID: deletedID, LicenseOptionID: uuid.New()},
+	}, nil).Once()
+	sf.On("FindWithLicenses", ctx, spec.ID).Return(spec, nil).Once()
+	sf.On("FindWithLicenses", ctx, deletedID).Return(nil, errors.New("not found")).Once()
+
+	cart, err := s.GetCart(ctx, userID, "INR")
+	require.NoError(t, err)
+	require.Len(t, cart.Items, 2)
+	assert.True(t, cart.Items[0].Available)
+	assert.Equal(t, "Night Drive", cart.Items[0].SpecTitle)
+	assert.False(t, cart.Items[1].Available)
+	assert.Equal(t, 49900, cart.Total)
+	assert.Equal(t, "INR", cart.Currency)
+}
+
+func TestPaymentService_AddToCart(t *testing.T) {
+	s, _, cr, sf := newCartSvc()
+	ctx := context.Background()
+	userID := uuid.New()
+	spec, loID := catalogSpec("Night Drive", 49900)
+
+	sf.On("FindWithLicenses", ctx, spec.ID).Return(spec, nil)
+	_, err := s.AddToCart(ctx, userID, spec.ID, uuid.New(), "INR")
+	assert.EqualError(t, err, "license option not found")
+
+	full := make([]domain.CartItem, maxCartItems)
+	for i := range full {
+		full[i] = domain.CartItem{SpecID: uuid.New()}
+	}
+	cr.On("ListByUser", ctx, userID).Return(full, nil).Once()
+	_, err = s.AddToCart(ctx, userID, spec.ID, loID, "INR")
+	assert.ErrorIs(t, err, domain.ErrCartFull)
+
+	cr.On("ListByUser", ctx, userID).Return([]domain.CartItem{}, nil).Once()
+	cr.On("Upsert", ctx, mock.MatchedBy(func(item *domain.CartItem) bool {
+		return item.UserID == userID && item.SpecID == spec.ID && item.LicenseOptionID == loID
+	})).Return(nil).Once()
+	cr.On("ListByUser", ctx, userID).Return([]domain.CartItem{{SpecID: spec.ID, LicenseOptionID: loID}}, nil).Once()
+
+	cart, err := s.AddToCart(ctx, userID, spec.ID, loID, "INR")
+	require.NoError(t, err)
+	assert.Len(t, cart.Items, 1)
+	assert.Equal(t, 49900, cart.Total)
+	cr.AssertExpectations(t)
+}
+
+func TestPaymentService_NotifyRefund_NotifiesEachProducerOfCartOrder(t *testing.T) {
+	s, _, _, _, sf, n := newRefundSvc()
+	ctx := context.Background()
+	firstProducer, secondProducer := uuid.New(), uuid.New()
+	order := paidOrder("razorpay")
+	order.SpecID = uuid.Nil
+	order.LicenseType = ""
+	order.Items = []domain.OrderItem{
+		{SpecID: uuid.New(), LicenseType: "Basic", SpecTitle: "Night Drive", Amount: 1000},
+		{SpecID: uuid.New(), LicenseType: "Premium", SpecTitle: "Low Tide", Amount: 1000},
+		{SpecID: uuid.New(), LicenseType: "Basic", SpecTitle: "Undertow", Amount: 500},
+	}
+
+	sf.On("FindByIDIncludingDeleted", ctx, order.Items[0].SpecID).Return(&catalogDomain.Spec{ProducerID: firstProducer}, nil).Once()
+	sf.On("FindByIDIncludingDeleted", ctx, order.Items[1].SpecID).Return(&catalogDomain.Spec{ProducerID: secondProducer}, nil).Once()
+	sf.On("FindByIDIncludingDeleted", ctx, order.Items[2].SpecID).Return(&catalogDomain.Spec{ProducerID: firstProducer}, nil).Once()
+	n.On("Create", ctx, order.UserID, notificationDomain.CategoryAccount, "Order refunded", mock.MatchedBy(func(message string) bool {
+		return strings.Contains(message, `3 licenses ("Night Drive + 2 more")`)
+	}), notificationDomain.NotificationTypeInfo).Return(nil).Once()
+	n.On("Create", ctx, firstProducer, notificationDomain.CategoryAccount, "Sale refunded", mock.MatchedBy(func(message string) bool {
+		return strings.Contains(message, `2 licenses ("Night Drive + 1 more")`)
+	}), notificationDomain.NotificationTypeWarning).Return(nil).Once()
+	n.On("Create", ctx, secondProducer, notificationDomain.CategoryAccount, "Sale refunded", mock.MatchedBy(func(message string) bool {
+		return strings.Contains(message, `a Premium license for "Low Tide"`)
+	}), notificationDomain.NotificationTypeWarning).Return(nil).Once()
+
+	s.notifyRefund(ctx, order, &domain.Refund{Kind: domain.RefundKindRefund, Amount: 2500, Currency: "INR"})
+	n.AssertExpectations(t)
+}
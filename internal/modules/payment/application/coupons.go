@@ -0,0 +1,317 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	catalogDomain "github.com/saransh1220/blueprint-audio/internal/modules/catalog/domain"
+	"github.com/saransh1220/blueprint-audio/internal/modules/payment/domain"
+	sharedmoney "github.com/saransh1220/blueprint-audio/internal/shared/money"
+)
+
+// minItemAmount is the least a discounted item may cost, in minor units.
+// Providers reject charges below one unit of the currency.
+const minItemAmount = 100
+
+var couponCodePattern = regexp.MustCompile(`^[A-Z0-9_-]{3,32}$`)
+
+func normalizeCouponCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// applyCoupon returns the items with the coupon identified by code taken off
+// the ones it covers. An empty code leaves every item at its quoted price.
+func (s *paymentService) applyCoupon(ctx context.Context, userID uuid.UUID, quoted []quotedItem, currency, code string) ([]domain.OrderItem, *domain.Coupon, error) {
+	items := make([]domain.OrderItem, len(quoted))
+	for i := range quoted {
+		items[i] = quoted[i].OrderItem
+	}
+	code = normalizeCouponCode(code)
+	if code == "" {
+		return items, nil, nil
+	}
+
+	coupon, err := s.couponRepo.GetByCode(ctx, code)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := s.checkCouponRedeemable(ctx, coupon, userID); err != nil {
+		return nil, nil, err
+	}
+
+	var covered []int
+	for i, item := range quoted {
+		if couponCovers(coupon, item) {
+			covered = append(covered, i)
+		}
+	}
+	if len(covered) == 0 {
+		return nil, nil, domain.ErrCouponNotApplicable
+	}
+
+	switch coupon.DiscountType {
+	case domain.DiscountTypePercentage:
+		for _, i := range covered {
+			discountItem(&items[i], items[i].Amount**coupon.PercentOff/100)
+		}
+	case domain.DiscountTypeFixed:
+		if coupon.Currency == nil || *coupon.Currency != currency {
+			return nil, nil, fmt.Errorf("%w: coupon is only valid for %s orders", domain.ErrCouponNotApplicable, stringValue(coupon.Currency))
+		}
+		// A fixed discount applies once per order, spread over the covered
+		// items in order until it is used up.
+		remaining := *coupon.AmountOff
+		for _, i := range covered {
+			remaining -= discountItem(&items[i], remaining)
+		}
+	}
+	return items, coupon, nil
+}
+
+// checkCouponRedeemable rejects coupons that are switched off, expired or used
+// up. OrderRepository.Create repeats the limit checks under a lock; this check
+// fails fast before a provider checkout is opened.
+func (s *paymentService) checkCouponRedeemable(ctx context.Context, coupon *domain.Coupon, userID uuid.UUID) error {
+	if !coupon.IsActive {
+		return domain.ErrCouponInactive
+	}
+	if coupon.ExpiresAt != nil && time.Now().After(*coupon.ExpiresAt) {
+		return domain.ErrCouponExpired
+	}
+	if coupon.MaxRedemptions == nil && coupon.PerUserLimit == nil {
+		return nil
+	}
+
+	counts, err := s.couponRepo.CountRedemptions(ctx, coupon.ID, userID)
+	if err != nil {
+		return err
+	}
+	if coupon.MaxRedemptions != nil && counts.Total >= *coupon.MaxRedemptions {
+		return domain.ErrCouponExhausted
+	}
+	if coupon.PerUserLimit != nil && counts.ByUser >= *coupon.PerUserLimit {
+		return domain.ErrCouponLimitReached
+	}
+	return nil
+}
+
+func couponCovers(coupon *domain.Coupon, item quotedItem) bool {
+	if item.ProducerID != coupon.ProducerID {
+		return false
+	}
+	if len(coupon.SpecIDs) > 0 && !slices.Contains(coupon.SpecIDs, item.SpecID) {
+		return false
+	}
+	if len(coupon.LicenseTypes) > 0 && !slices.Contains(coupon.LicenseTypes, item.LicenseType) {
+		return false
+	}
+	return true
+}
+
+// discountItem takes up to amount off the item, never below minItemAmount,
+// and returns how much it took.
+func discountItem(item *domain.OrderItem, amount int) int {
+	if limit := item.Amount - minItemAmount; amount > limit {
+		amount = limit
+	}
+	if amount <= 0 {
+		return 0
+	}
+	item.Amount -= amount
+	item.DiscountAmount += amount
+	return amount
+}
+
+func (s *paymentService) CreateCoupon(ctx context.Context, producerID uuid.UUID, input CouponInput) (*domain.Coupon, error) {
+	coupon := &domain.Coupon{
+		ProducerID:     producerID,
+		Code:           normalizeCouponCode(input.Code),
+		DiscountType:   input.DiscountType,
+		PercentOff:     input.PercentOff,
+		AmountOff:      input.AmountOff,
+		MaxRedemptions: input.MaxRedemptions,
+		PerUserLimit:   input.PerUserLimit,
+		ExpiresAt:      input.ExpiresAt,
+		IsActive:       true,
+	}
+	if input.Currency != nil {
+		currency := strings.ToUpper(strings.TrimSpace(*input.Currency))
+		coupon.Currency = &currency
+	}
+
+	if !couponCodePattern.MatchString(coupon.Code) {
+		return nil, fmt.Errorf("%w: code must be 3-32 letters, digits, '-' or '_'", domain.ErrInvalidCoupon)
+	}
+	switch coupon.DiscountType {
+	case domain.DiscountTypePercentage:
+		if coupon.PercentOff == nil || *coupon.PercentOff < 1 || *coupon.PercentOff > 100 {
+			return nil, fmt.Errorf("%w: percent_off must be between 1 and 100", domain.ErrInvalidCoupon)
+		}
+		if coupon.AmountOff != nil || coupon.Currency != nil {
+			return nil, fmt.Errorf("%w: amount_off and currency apply to fixed coupons only", domain.ErrInvalidCoupon)
+		}
+	case domain.DiscountTypeFixed:
+		if coupon.AmountOff == nil || *coupon.AmountOff <= 0 {
+			return nil, fmt.Errorf("%w: amount_off must be positive", domain.ErrInvalidCoupon)
+		}
+		if coupon.Currency == nil || (*coupon.Currency != sharedmoney.CurrencyINR && *coupon.Currency != sharedmoney.CurrencyUSD) {
+			return nil, fmt.Errorf("%w: currency must be INR or USD", domain.ErrInvalidCoupon)
+		}
+		if coupon.PercentOff != nil {
+			return nil, fmt.Errorf("%w: percent_off applies to percentage coupons only", domain.ErrInvalidCoupon)
+		}
+	default:
+		return nil, fmt.Errorf("%w: discount_type must be percentage or fixed", domain.ErrInvalidCoupon)
+	}
+	if err := s.setCouponSpecs(ctx, coupon, input.SpecIDs); err != nil {
+		return nil, err
+	}
+	if err := setCouponLicenseTypes(coupon, input.LicenseTypes); err != nil {
+		return nil, err
+	}
+	if err := validateCouponLimits(coupon); err != nil {
+		return nil, err
+	}
+	if err := validateCouponExpiry(coupon.ExpiresAt); err != nil {
+		return nil, err
+	}
+
+	if err := s.couponRepo.Create(ctx, coupon); err != nil {
+		return nil, err
+	}
+	return coupon, nil
+}
+
+func (s *paymentService) ListCoupons(ctx context.Context, producerID uuid.UUID) ([]domain.Coupon, error) {
+	return s.couponRepo.ListByProducer(ctx, producerID)
+}
+
+func (s *paymentService) UpdateCoupon(ctx context.Context, producerID, couponID uuid.UUID, input UpdateCouponInput) (*domain.Coupon, error) {
+	coupon, err := s.producerCoupon(ctx, producerID, couponID)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.SpecIDs != nil {
+		if err := s.setCouponSpecs(ctx, coupon, *input.SpecIDs); err != nil {
+			return nil, err
+		}
+	}
+	if input.LicenseTypes != nil {
+		if err := setCouponLicenseTypes(coupon, *input.LicenseTypes); err != nil {
+			return nil, err
+		}
+	}
+	if input.MaxRedemptions != nil {
+		coupon.MaxRedemptions = clearableLimit(*input.MaxRedemptions)
+	}
+	if input.PerUserLimit != nil {
+		coupon.PerUserLimit = clearableLimit(*input.PerUserLimit)
+	}
+	if input.ExpiresAt != nil {
+		if err := validateCouponExpiry(input.ExpiresAt); err != nil {
+			return nil, err
+		}
+		coupon.ExpiresAt = input.ExpiresAt
+	}
+	if input.IsActive != nil {
+		coupon.IsActive = *input.IsActive
+	}
+	if err := validateCouponLimits(coupon); err != nil {
+		return nil, err
+	}
+
+	if err := s.couponRepo.Update(ctx, coupon); err != nil {
+		return nil, err
+	}
+	return coupon, nil
+}
+
+func (s *paymentService) DeleteCoupon(ctx context.Context, producerID, couponID uuid.UUID) error {
+	if _, err := s.producerCoupon(ctx, producerID, couponID); err != nil {
+		return err
+	}
+	return s.couponRepo.Delete(ctx, couponID)
+}
+
+// producerCoupon loads a coupon owned by the producer. Other producers'
+// coupons are reported as not found.
+func (s *paymentService) producerCoupon(ctx context.Context, producerID, couponID uuid.UUID) (*domain.Coupon, error) {
+	coupon, err := s.couponRepo.GetByID(ctx, couponID)
+	if err != nil {
+		return nil, err
+	}
+	if coupon.ProducerID != producerID {
+		return nil, domain.ErrCouponNotFound
+	}
+	return coupon, nil
+}
+
+// setCouponSpecs limits the coupon to the given specs, which must belong to
+// the coupon's producer.
+func (s *paymentService) setCouponSpecs(ctx context.Context, coupon *domain.Coupon, specIDs []uuid.UUID) error {
+	coupon.SpecIDs = make([]uuid.UUID, 0, len(specIDs))
+	for _, specID := range specIDs {
+		if slices.Contains(coupon.SpecIDs, specID) {
+			continue
+		}
+		spec, err := s.specFinder.FindByID(ctx, specID)
+		if err != nil || spec.ProducerID != coupon.ProducerID {
+			return fmt.Errorf("%w: spec %s not found", domain.ErrInvalidCoupon, specID)
+		}
+		coupon.SpecIDs = append(coupon.SpecIDs, specID)
+	}
+	return nil
+}
+
+func setCouponLicenseTypes(coupon *domain.Coupon, licenseTypes []string) error {
+	coupon.LicenseTypes = make([]string, 0, len(licenseTypes))
+	for _, licenseType := range licenseTypes {
+		switch catalogDomain.LicenseType(licenseType) {
+		case catalogDomain.LicenseBasic, catalogDomain.LicensePremium, catalogDomain.LicenseTrackout, catalogDomain.LicenseUnlimited:
+		default:
+			return fmt.Errorf("%w: unknown license type %q", domain.ErrInvalidCoupon, licenseType)
+		}
+		if !slices.Contains(coupon.LicenseTypes, licenseType) {
+			coupon.LicenseTypes = append(coupon.LicenseTypes, licenseType)
+		}
+	}
+	return nil
+}
+
+func validateCouponLimits(coupon *domain.Coupon) error {
+	if coupon.MaxRedemptions != nil && *coupon.MaxRedemptions <= 0 {
+		return fmt.Errorf("%w: max_redemptions must be positive", domain.ErrInvalidCoupon)
+	}
+	if coupon.PerUserLimit != nil && *coupon.PerUserLimit <= 0 {
+		return fmt.Errorf("%w: per_user_limit must be positive", domain.ErrInvalidCoupon)
+	}
+	return nil
+}
+
+func validateCouponExpiry(expiresAt *time.Time) error {
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return fmt.Errorf("%w: expires_at must be in the future", domain.ErrInvalidCoupon)
+	}
+	return nil
+}
+
+// clearableLimit maps an update of 0 to "no limit".
+func clearableLimit(limit int) *int {
+	if limit == 0 {
+		return nil
+	}
+	return &limit
+}
+
+func stringValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
@@ -0,0 +1,251 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/razorpay/razorpay-go"
+	catalogDomain "github.com/saransh1220/blueprint-audio/internal/modules/catalog/domain"
+	"github.com/saransh1220/blueprint-audio/internal/modules/payment/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type couponRepoMock struct{ mock.Mock }
+
+func (m *couponRepoMock) Create(ctx context.Context, coupon *domain.Coupon) error {
+	args := m.Called(ctx, coupon)
+	return args.Error(0)
+}
+func (m *couponRepoMock) GetByID(ctx context.Context, id uuid.UUID) (*domain.Coupon, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Coupon), args.Error(1)
+}
+func (m *couponRepoMock) GetByCode(ctx context.Context, code string) (*domain.Coupon, error) {
+	args := m.Called(ctx, code)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Coupon), args.Error(1)
+}
+func (m *couponRepoMock) ListByProducer(ctx context.Context, producerID uuid.UUID) ([]domain.Coupon, error) {
+	args := m.Called(ctx, producerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.Coupon), args.Error(1)
+}
+func (m *couponRepoMock) Update(ctx context.Context, coupon *domain.Coupon) error {
+	args := m.Called(ctx, coupon)
+	return args.Error(0)
+}
+func (m *couponRepoMock) Delete(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+func (m *couponRepoMock) CountRedemptions(ctx context.Context, couponID, userID uuid.UUID) (domain.CouponRedemptions, error) {
+	args := m.Called(ctx, couponID, userID)
+	return args.Get(0).(domain.CouponRedemptions), args.Error(1)
+}
+
+func newCouponSvc(t *testing.T) (*paymentService, *orderRepoMock, *cartRepoMock, *couponRepoMock, *specFinderMock) {
+	s, or, cr, sf := newCartSvc()
+	cp := new(couponRepoMock)
+	s.couponRepo = cp
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": "order_coupon_1"})
+	}))
+	t.Cleanup(ts.Close)
+	s.razorpayClient = razorpay.NewClient("key", "secret")
+	s.razorpayClient.Request.BaseURL = ts.URL
+	return s, or, cr, cp, sf
+}
+
+func intPtr(v int) *int { return &v }
+
+func TestPaymentService_CreateOrder_AppliesPercentageCoupon(t *testing.T) {
+	s, or, _, cp, sf := newCouponSvc(t)
+	ctx := context.Background()
+	userID := uuid.New()
+	spec, loID := catalogSpec("Night Drive", 49900)
+	spec.ProducerID = uuid.New()
+	coupon := &domain.Coupon{ID: uuid.New(), ProducerID: spec.ProducerID, Code: "WEEKEND20", DiscountType: domain.DiscountTypePercentage, PercentOff: intPtr(20), LicenseTypes: []string{"Basic"}, PerUserLimit: intPtr(1), IsActive: true}
+
+	sf.On("FindWithLicenses", ctx, spec.ID).Return(spec, nil).Once()
+	cp.On("GetByCode", ctx, "WEEKEND20").Return(coupon, nil).Once()
+	cp.On("CountRedemptions", ctx, coupon.ID, userID).Return(domain.CouponRedemptions{Total: 5}, nil).Once()
+	or.On("Create", ctx, mock.AnythingOfType("*domain.Order")).Return(nil).Once()
+
+	order, err := s.CreateOrder(ctx, userID, spec.ID, loID, "INR", " weekend20 ")
+	require.NoError(t, err)
+	assert.Equal(t, 39920, order.Amount)
+	assert.Equal(t, 9980, order.DiscountAmount)
+	assert.Equal(t, &coupon.ID, order.CouponID)
+	assert.Equal(t, "WEEKEND20", order.Notes["coupon_code"])
+	assert.Equal(t, 9980, order.Notes["discount_amount"])
+	require.Len(t, order.Items, 1)
+	assert.Equal(t, 9980, order.Items[0].DiscountAmount)
+
+	licenses, err := newLicenses(order)
+	require.NoError(t, err)
+	assert.Equal(t, 39920, licenses[0].PurchasePrice)
+}
+
+func TestPaymentService_Checkout_FixedCouponOnlyCoversProducerItems(t *testing.T) {
+	s, or, cr, cp, sf := newCouponSvc(t)
+	ctx := context.Background()
+	userID := uuid.New()
+	producerID := uuid.New()
+	cheap, cheapLO := catalogSpec("Night Drive", 49900)
+	pricey, priceyLO := catalogSpec("Low Tide", 99900)
+	other, otherLO := catalogSpec("Elsewhere", 79900)
+	cheap.ProducerID, pricey.ProducerID, other.ProducerID = producerID, producerID, uuid.New()
+	inr := "INR"
+	coupon := &domain.Coupon{ID: uuid.New(), ProducerID: producerID, Code: "FLAT600", DiscountType: domain.DiscountTypeFixed, AmountOff: intPtr(60000), Currency: &inr, IsActive: true}
+
+	cr.On("ListByUser", ctx, userID).Return([]domain.CartItem{
+		{SpecID: cheap.ID, LicenseOptionID: cheapLO},
+		{SpecID: other.ID, LicenseOptionID: otherLO},
+		{SpecID: pricey.ID, LicenseOptionID: priceyLO},
+	}, nil).Once()
+	sf.On("FindWithLicenses", ctx, cheap.ID).Return(cheap, nil).Once()
+	sf.On("FindWithLicenses", ctx, other.ID).Return(other, nil).Once()
+	sf.On("FindWithLicenses", ctx, pricey.ID).Return(pricey, nil).Once()
+	cp.On("GetByCode", ctx, "FLAT600").Return(coupon, nil).Once()
+	or.On("Create", ctx, mock.AnythingOfType("*domain.Order")).Return(nil).Once()
+
+	order, err := s.Checkout(ctx, userID, "INR", "FLAT600")
+	require.NoError(t, err)
+	// The cheapest item keeps the minimum charge; the rest comes off the next
+	// covered item and the other producer's item is untouched.
+	assert.Equal(t, []int{100, 79900, 89700}, []int{order.Items[0].Amount, order.Items[1].Amount, order.Items[2].Amount})
+	assert.Equal(t, []int{49800, 0, 10200}, []int{order.Items[0].DiscountAmount, order.Items[1].DiscountAmount, order.Items[2].DiscountAmount})
+	assert.Equal(t, 60000, order.DiscountAmount)
+	assert.Equal(t, 169700, order.Amount)
+	cp.AssertNotCalled(t, "CountRedemptions", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestPaymentService_CreateOrder_RejectsUnusableCoupons(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	spec, loID := catalogSpec("Night Drive", 49900)
+	spec.ProducerID = uuid.New()
+	past := time.Now().Add(-time.Hour)
+	usd := "USD"
+
+	cases := []struct {
+		name   string
+		coupon *domain.Coupon
+		counts domain.CouponRedemptions
+		want   error
+	}{
+		{"inactive", &domain.Coupon{ProducerID: spec.ProducerID, DiscountType: domain.DiscountTypePercentage, PercentOff: intPtr(10)}, domain.CouponRedemptions{}, domain.ErrCouponInactive},
+		{"expired", &domain.Coupon{ProducerID: spec.ProducerID, DiscountType: domain.DiscountTypePercentage, PercentOff: intPtr(10), ExpiresAt: &past, IsActive: true}, domain.CouponRedemptions{}, domain.ErrCouponExpired},
+		{"exhausted", &domain.Coupon{ProducerID: spec.ProducerID, DiscountType: domain.DiscountTypePercentage, PercentOff: intPtr(10), MaxRedemptions: intPtr(3), IsActive: true}, domain.CouponRedemptions{Total: 3}, domain.ErrCouponExhausted},
+		{"per user", &domain.Coupon{ProducerID: spec.ProducerID, DiscountType: domain.DiscountTypePercentage, PercentOff: intPtr(10), PerUserLimit: intPtr(1), IsActive: true}, domain.CouponRedemptions{Total: 1, ByUser: 1}, domain.ErrCouponLimitReached},
+		{"other producer", &domain.Coupon{ProducerID: uuid.New(), DiscountType: domain.DiscountTypePercentage, PercentOff: intPtr(10), IsActive: true}, domain.CouponRedemptions{}, domain.ErrCouponNotApplicable},
+		{"other spec", &domain.Coupon{ProducerID: spec.ProducerID, DiscountType: domain.DiscountTypePercentage, PercentOff: intPtr(10), SpecIDs: []uuid.UUID{uuid.New()}, IsActive: true}, domain.CouponRedemptions{}, domain.ErrCouponNotApplicable},
+		{"other currency", &domain.Coupon{ProducerID: spec.ProducerID, DiscountType: domain.DiscountTypeFixed, AmountOff: intPtr(500), Currency: &usd, IsActive: true}, domain.CouponRedemptions{}, domain.ErrCouponNotApplicable},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, or, _, cp, sf := newCouponSvc(t)
+			tc.coupon.ID = uuid.New()
+			sf.On("FindWithLicenses", ctx, spec.ID).Return(spec, nil).Once()
+			cp.On("GetByCode", ctx, "CODE").Return(tc.coupon, nil).Once()
+			cp.On("CountRedemptions", ctx, tc.coupon.ID, userID).Return(tc.counts, nil).Maybe()
+
+			_, err := s.CreateOrder(ctx, userID, spec.ID, loID, "INR", "code")
+			assert.ErrorIs(t, err, tc.want)
+			or.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		})
+	}
+
+	s, _, _, cp, sf := newCouponSvc(t)
+	sf.On("FindWithLicenses", ctx, spec.ID).Return(spec, nil).Once()
+	cp.On("GetByCode", ctx, "NOPE").Return(nil, domain.ErrCouponNotFound).Once()
+	_, err := s.CreateOrder(ctx, userID, spec.ID, loID, "INR", "nope")
+	assert.ErrorIs(t, err, domain.ErrCouponNotFound)
+}
+
+func TestPaymentService_CreateCoupon(t *testing.T) {
+	s, _, _, cp, sf := newCouponSvc(t)
+	ctx := context.Background()
+	producerID := uuid.New()
+	ownSpec := &catalogDomain.Spec{ID: uuid.New(), ProducerID: producerID}
+	foreignSpec := &catalogDomain.Spec{ID: uuid.New(), ProducerID: uuid.New()}
+	sf.On("FindByID", ctx, ownSpec.ID).Return(ownSpec, nil)
+	sf.On("FindByID", ctx, foreignSpec.ID).Return(foreignSpec, nil)
+
+	cp.On("Create", ctx, mock.AnythingOfType("*domain.Coupon")).Return(nil).Once()
+	usd := "usd"
+	coupon, err := s.CreateCoupon(ctx, producerID, CouponInput{
+		Code:         "launch-5",
+		DiscountType: domain.DiscountTypeFixed,
+		AmountOff:    intPtr(500),
+		Currency:     &usd,
+		SpecIDs:      []uuid.UUID{ownSpec.ID, ownSpec.ID},
+		LicenseTypes: []string{"Premium"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "LAUNCH-5", coupon.Code)
+	assert.Equal(t, "USD", *coupon.Currency)
+	assert.Equal(t, []uuid.UUID{ownSpec.ID}, coupon.SpecIDs)
+	assert.True(t, coupon.IsActive)
+
+	past := time.Now().Add(-time.Minute)
+	invalid := []CouponInput{
+		{Code: "X", DiscountType: domain.DiscountTypePercentage, PercentOff: intPtr(10)},
+		{Code: "TOOMUCH", DiscountType: domain.DiscountTypePercentage, PercentOff: intPtr(101)},
+		{Code: "MIXED", DiscountType: domain.DiscountTypePercentage, PercentOff: intPtr(10), AmountOff: intPtr(100)},
+		{Code: "NOCURRENCY", DiscountType: domain.DiscountTypeFixed, AmountOff: intPtr(100)},
+		{Code: "BOGO", DiscountType: "bogo"},
+		{Code: "FOREIGN", DiscountType: domain.DiscountTypePercentage, PercentOff: intPtr(10), SpecIDs: []uuid.UUID{foreignSpec.ID}},
+		{Code: "LICENSE", DiscountType: domain.DiscountTypePercentage, PercentOff: intPtr(10), LicenseTypes: []string{"Exclusive"}},
+		{Code: "LIMIT", DiscountType: domain.DiscountTypePercentage, PercentOff: intPtr(10), MaxRedemptions: intPtr(0)},
+		{Code: "EXPIRED", DiscountType: domain.DiscountTypePercentage, PercentOff: intPtr(10), ExpiresAt: &past},
+	}
+	for _, input := range invalid {
+		_, err := s.CreateCoupon(ctx, producerID, input)
+		assert.ErrorIs(t, err, domain.ErrInvalidCoupon, input.Code)
+	}
+	cp.AssertNumberOfCalls(t, "Create", 1)
+}
+
+func TestPaymentService_UpdateAndDeleteCoupon(t *testing.T) {
+	s, _, _, cp, _ := newCouponSvc(t)
+	ctx := context.Background()
+	producerID := uuid.New()
+	past := time.Now().Add(-time.Hour)
+	coupon := &domain.Coupon{ID: uuid.New(), ProducerID: producerID, Code: "OLD", MaxRedemptions: intPtr(5), ExpiresAt: &past, IsActive: true}
+
+	cp.On("GetByID", ctx, coupon.ID).Return(coupon, nil)
+	cp.On("Update", ctx, coupon).Return(nil).Once()
+	active := false
+	updated, err := s.UpdateCoupon(ctx, producerID, coupon.ID, UpdateCouponInput{MaxRedemptions: intPtr(0), PerUserLimit: intPtr(2), IsActive: &active})
+	require.NoError(t, err)
+	assert.Nil(t, updated.MaxRedemptions)
+	assert.Equal(t, 2, *updated.PerUserLimit)
+	assert.False(t, updated.IsActive)
+
+	_, err = s.UpdateCoupon(ctx, producerID, coupon.ID, UpdateCouponInput{ExpiresAt: &past})
+	assert.ErrorIs(t, err, domain.ErrInvalidCoupon)
+
+	_, err = s.UpdateCoupon(ctx, uuid.New(), coupon.ID, UpdateCouponInput{IsActive: &active})
+	assert.ErrorIs(t, err, domain.ErrCouponNotFound)
+	assert.ErrorIs(t, s.DeleteCoupon(ctx, uuid.New(), coupon.ID), domain.ErrCouponNotFound)
+
+	cp.On("Delete", ctx, coupon.ID).Return(nil).Once()
+	require.NoError(t, s.DeleteCoupon(ctx, producerID, coupon.ID))
+	cp.AssertNumberOfCalls(t, "Update", 1)
+}
@@ -36,3 +36,112 @@ type ProducerOrderResponse struct {
 	Limit  int                `json:"limit"`
 	Offset int                `json:"offset"`
 }
+
+// RefundOrderInput describes an admin-initiated refund. The request details are
+// recorded in the admin audit log.
+type RefundOrderInput struct {
+	ActorID   uuid.UUID
+	Reason    string
+	IPAddress string
+	UserAgent string
+}
+
+// CartItemDto is a cart line priced at the current catalog price. Items whose
+// spec was deleted or whose license option was removed stay in the cart as
+// unavailable and are left out of the total.
+type CartItemDto struct {
+	SpecID          uuid.UUID `json:"spec_id"`
+	SpecTitle       string    `json:"spec_title"`
+	LicenseOptionID uuid.UUID `json:"license_option_id"`
+	LicenseType     string    `json:"license_type"`
+	LicenseName     string    `json:"license_name"`
+	Amount          int       `json:"amount"`
+	Available       bool      `json:"available"`
+	AddedAt         time.Time `json:"added_at"`
+}
+
+type CartResponse struct {
+	Items    []CartItemDto `json:"items"`
+	Total    int           `json:"total"`
+	Currency string        `json:"currency"`
+}
+
+// CouponInput creates a coupon. Percentage coupons set PercentOff; fixed
+// coupons set AmountOff in minor units together with its Currency.
+type CouponInput struct {
+	Code           string              `json:"code"`
+	DiscountType   domain.DiscountType `json:"discount_type"`
+	PercentOff     *int                `json:"percent_off"`
+	AmountOff      *int                `json:"amount_off"`
+	Currency       *string             `json:"currency"`
+	SpecIDs        []uuid.UUID         `json:"spec_ids"`
+	LicenseTypes   []string            `json:"license_types"`
+	MaxRedemptions *int                `json:"max_redemptions"`
+	PerUserLimit   *int                `json:"per_user_limit"`
+	ExpiresAt      *time.Time          `json:"expires_at"`
+}
+
+// UpdateCouponInput changes the fields that are set. A limit of 0 removes it;
+// empty lists lift the spec or license type restriction.
+type UpdateCouponInput struct {
+	SpecIDs        *[]uuid.UUID `json:"spec_ids"`
+	LicenseTypes   *[]string    `json:"license_types"`
+	MaxRedemptions *int         `json:"max_redemptions"`
+	PerUserLimit   *int         `json:"per_user_limit"`
+	ExpiresAt      *time.Time   `json:"expires_at"`
+	IsActive       *bool        `json:"is_active"`
+}
+
+// AgreementTemplateInput replaces a license option's agreement template. A
+// nil limit means unlimited. Terms may use the placeholders {{buyer_name}},
+// {{producer_name}}, {{spec_title}}, {{license_type}}, {{license_key}},
+// {{price}} and {{date}}.
+type AgreementTemplateInput struct {
+	StreamLimit       *int   `json:"stream_limit"`
+	DistributionLimit *int   `json:"distribution_limit"`
+	CreditRequired    bool   `json:"credit_required"`
+	Terms             string `json:"terms"`
+}
+
+// LicenseAgreement is a rendered agreement PDF ready for download.
+type LicenseAgreement struct {
+	Filename string
+	Content  []byte
+}
+
+// License verification states.
+const (
+	LicenseStatusActive   = "active"
+	LicenseStatusInactive = "inactive"
+	LicenseStatusRevoked  = "revoked"
+)
+
+// LicenseVerification is the public view of a license, for anyone holding
+// its key. It leaves out the buyer and the price.
+type LicenseVerification struct {
+	LicenseKey   string     `json:"license_key"`
+	SpecTitle    string     `json:"spec_title"`
+	ProducerName string     `json:"producer_name"`
+	LicenseType  string     `json:"license_type"`
+	IssuedAt     time.Time  `json:"issued_at"`
+	Status       string     `json:"status"`
+	RevokedAt    *time.Time `json:"revoked_at,omitempty"`
+}
+
+// OrderPaidEvent is the payload of the realtime order.paid event.
+type OrderPaidEvent struct {
+	OrderID  uuid.UUID          `json:"order_id"`
+	Status   domain.OrderStatus `json:"status"`
+	Amount   int                `json:"amount"`
+	Currency string             `json:"currency"`
+}
+
+// LicenseIssuedEvent is the payload of the realtime license.issued event,
+// sent for each license of a paid order.
+type LicenseIssuedEvent struct {
+	LicenseID   uuid.UUID `json:"license_id"`
+	OrderID     uuid.UUID `json:"order_id"`
+	SpecID      uuid.UUID `json:"spec_id"`
+	LicenseType string    `json:"license_type"`
+	LicenseKey  string    `json:"license_key"`
+}
@@ -0,0 +1,25 @@
+package application
+
+import (
+	"context"
+	"time"
+
+	"github.com/saransh1220/blueprint-audio/internal/modules/payment/domain"
+)
+
+// OrderExpirer fails orders whose checkout window closed without a payment,
+// so order histories stop showing them as awaiting payment. A payment that is
+// captured late for a failed order is still honoured by MarkPaid.
+type OrderExpirer struct {
+	orders domain.OrderRepository
+	now    func() time.Time
+}
+
+func NewOrderExpirer(orders domain.OrderRepository) *OrderExpirer {
+	return &OrderExpirer{orders: orders, now: time.Now}
+}
+
+// ExpireOrders fails the expired orders and returns how many there were.
+func (e *OrderExpirer) ExpireOrders(ctx context.Context) (int64, error) {
+	return e.orders.FailExpired(ctx, e.now())
+}
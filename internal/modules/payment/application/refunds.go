@@ -0,0 +1,352 @@
+package application
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	notificationDomain "github.com/saransh1220/blueprint-audio/internal/modules/notification/domain"
+	"github.com/saransh1220/blueprint-audio/internal/modules/payment/domain"
+)
+
+// providerRefundEvent is the provider-neutral shape of a refund or lost
+// dispute reported by a webhook.
+type providerRefundEvent struct {
+	Provider          string
+	Kind              domain.RefundKind
+	ProviderPaymentID string
+	ProviderRefundID  string
+	OrderID           uuid.UUID
+	Amount            int
+	Currency          string
+	Reason            string
+}
+
+func dodoRefundEvent(data map[string]any, kind domain.RefundKind) providerRefundEvent {
+	orderID, paymentID := extractDodoOrderMetadata(data)
+	event := providerRefundEvent{
+		Provider:          "dodo",
+		Kind:              kind,
+		ProviderPaymentID: stringFromAny(data["payment_id"]),
+		OrderID:           orderID,
+		Amount:            intFromAny(data["amount"]),
+		Currency:          stringFromAny(data["currency"]),
+		Reason:            stringFromAny(data["reason"]),
+	}
+	if event.ProviderPaymentID == "" {
+		event.ProviderPaymentID = paymentID
+	}
+	if kind == domain.RefundKindChargeback {
+		event.ProviderRefundID = stringFromAny(data["dispute_id"])
+		if event.Reason == "" {
+			event.Reason = stringFromAny(data["remarks"])
+		}
+	} else {
+		event.ProviderRefundID = stringFromAny(data["refund_id"])
+	}
+	return event
+}
+
+// handleProviderRefund applies a webhook-reported refund. Partial refunds are
+// recorded and the licenses revoked once they add up to the order amount.
+// Events the order cannot act on, including payments no order is known for,
+// are logged and acknowledged so the provider stops retrying.
+func (s *paymentService) handleProviderRefund(ctx context.Context, event providerRefundEvent) error {
+	order, err := s.findOrderForProviderPayment(ctx, event.ProviderPaymentID, event.OrderID)
+	if errors.Is(err, domain.ErrOrderNotFound) {
+		log.Printf("PaymentService.handleProviderRefund ignoring %s for unknown payment. provider=%s payment_id=%s", event.Kind, event.Provider, event.ProviderPaymentID)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	refund := &domain.Refund{
+		OrderID:  order.ID,
+		Provider: event.Provider,
+		Kind:     event.Kind,
+		Amount:   event.Amount,
+		Currency: strings.ToUpper(event.Currency),
+	}
+	if refund.Amount <= 0 {
+		refund.Amount = order.Amount
+	}
+	if refund.Currency == "" {
+		refund.Currency = order.Currency
+	}
+	if event.ProviderRefundID != "" {
+		refund.ProviderRefundID = &event.ProviderRefundID
+	}
+	if reason := strings.TrimSpace(event.Reason); reason != "" {
+		refund.Reason = &reason
+	}
+
+	if _, err := s.applyRefund(ctx, order, refund, domain.RefundAudit{}); err != nil {
+		if errors.Is(err, domain.ErrOrderNotRefundable) {
+			log.Printf("PaymentService.handleProviderRefund ignoring %s for %s order. order_id=%s", event.Kind, order.Status, order.ID)
+			return nil
+		}
+		if errors.Is(err, domain.ErrRefundAlreadyRecorded) {
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+// findOrderForProviderPayment returns ErrOrderNotFound when neither the
+// payment nor the fallback order ID leads to an order.
+func (s *paymentService) findOrderForProviderPayment(ctx context.Context, providerPaymentID string, fallbackOrderID uuid.UUID) (*domain.Order, error) {
+	orderID := fallbackOrderID
+	if providerPaymentID != "" {
+		if payment, err := s.paymentRepo.GetByRazorpayID(ctx, providerPaymentID); err == nil {
+			orderID = payment.OrderID
+		}
+	}
+	if orderID == uuid.Nil {
+		return nil, domain.ErrOrderNotFound
+	}
+	return s.orderRepo.GetByID(ctx, orderID)
+}
+
+// RefundOrder refunds what is left of a paid order through its provider and
+// applies the refund locally. Refunding an already refunded order returns the
+// latest refund.
+func (s *paymentService) RefundOrder(ctx context.Context, orderID uuid.UUID, input RefundOrderInput) (*domain.Refund, error) {
+	order, err := s.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, domain.ErrOrderNotFound
+	}
+	switch order.Status {
+	case domain.OrderStatusPaid:
+	case domain.OrderStatusRefunded:
+		refund, err := s.refundRepo.GetByOrderID(ctx, orderID)
+		if errors.Is(err, domain.ErrRefundNotFound) {
+			return nil, domain.ErrOrderAlreadyRefunded
+		}
+		return refund, err
+	default:
+		return nil, domain.ErrOrderNotRefundable
+	}
+
+	payment, err := s.paymentRepo.GetByOrderID(ctx, orderID)
+	if err != nil {
+		return nil, domain.ErrPaymentNotFound
+	}
+
+	refunded, err := s.refundRepo.RefundedAmount(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	remaining := payment.Amount - refunded
+	if remaining <= 0 {
+		return nil, domain.ErrOrderNotRefundable
+	}
+	// Dodo refunds the whole payment when a refund names no line items, so
+	// what is left of a partially refunded order is refunded from the Dodo
+	// dashboard and recorded by its webhook.
+	if order.Provider == "dodo" && refunded > 0 {
+		return nil, domain.ErrPartialProviderRefund
+	}
+
+	reason := strings.TrimSpace(input.Reason)
+	providerRefundID, err := s.createProviderRefund(ctx, order, payment, remaining, reason)
+	if err != nil {
+		return nil, err
+	}
+
+	refund := &domain.Refund{
+		OrderID:          order.ID,
+		Provider:         order.Provider,
+		ProviderRefundID: &providerRefundID,
+		Kind:             domain.RefundKindRefund,
+		Amount:           remaining,
+		Currency:         order.Currency,
+	}
+	if reason != "" {
+		refund.Reason = &reason
+	}
+
+	// If this fails after the provider accepted the refund, the provider's
+	// refund webhook applies it on delivery.
+	actorID := input.ActorID
+	return s.applyRefund(ctx, order, refund, domain.RefundAudit{
+		ActorID:   &actorID,
+		IPAddress: input.IPAddress,
+		UserAgent: input.UserAgent,
+	})
+}
+
+func (s *paymentService) createProviderRefund(ctx context.Context, order *domain.Order, payment *domain.Payment, amount int, reason string) (string, error) {
+	if order.Provider == "dodo" {
+		return s.createDodoRefund(ctx, payment.RazorpayPaymentID, reason)
+	}
+
+	data := map[string]interface{}{
+		"notes": map[string]interface{}{
+			"order_id": order.ID.String(),
+			"reason":   reason,
+		},
+	}
+	razorpayRefund, err := s.razorpayClient.Payment.Refund(payment.RazorpayPaymentID, amount, data, nil)
+	if err != nil {
+		return "", fmt.Errorf("razorpay refund failed: %w", err)
+	}
+	refundID, _ := razorpayRefund["id"].(string)
+	if refundID == "" {
+		return "", errors.New("invalid razorpay refund response")
+	}
+	return refundID, nil
+}
+
+func (s *paymentService) createDodoRefund(ctx context.Context, paymentID, reason string) (string, error) {
+	apiKey := strings.TrimSpace(s.dodoConfig.APIKey)
+	if apiKey == "" {
+		return "", errors.New("dodo payments is not configured")
+	}
+
+	body := map[string]any{"payment_id": paymentID}
+	if reason != "" {
+		body["reason"] = reason
+	}
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.dodoBaseURL()+"/refunds", bytes.NewReader(payload))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+apiKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("dodo refund failed: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(res.Body, 4096))
+		return "", fmt.Errorf("dodo refund failed with status %d: %s", res.StatusCode, strings.TrimSpace(string(body)))
+	}
+	var decoded struct {
+		RefundID string `json:"refund_id"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&decoded); err != nil {
+		return "", err
+	}
+	if decoded.RefundID == "" {
+		return "", errors.New("invalid dodo refund response")
+	}
+	return decoded.RefundID, nil
+}
+
+// applyRefund persists the refund and reverses its share of the order's
+// earnings. Once the order is refunded in full it also notifies both parties.
+// A refund that lost a race with another delivery resolves to the refund
+// already on record.
+func (s *paymentService) applyRefund(ctx context.Context, order *domain.Order, refund *domain.Refund, audit domain.RefundAudit) (*domain.Refund, error) {
+	completed, err := s.refundRepo.Apply(ctx, refund, audit)
+	if err != nil {
+		if errors.Is(err, domain.ErrOrderAlreadyRefunded) {
+			s.recordRefund(ctx, order.ID)
+			return s.refundRepo.GetByOrderID(ctx, order.ID)
+		}
+		return nil, err
+	}
+	s.recordRefund(ctx, order.ID)
+	if !completed {
+		log.Printf("PaymentService.applyRefund recorded partial %s. order_id=%s amount=%d order_amount=%d", refund.Kind, order.ID, refund.Amount, order.Amount)
+		return refund, nil
+	}
+	order.Status = domain.OrderStatusRefunded
+
+	go func() {
+		notifyCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		s.notifyRefund(notifyCtx, order, refund)
+	}()
+
+	return refund, nil
+}
+
+// recordRefund reverses the order's refunds in its earnings. Like recordSale
+// it only logs failures and leaves them to the earnings reconciler.
+func (s *paymentService) recordRefund(ctx context.Context, orderID uuid.UUID) {
+	if s.earnings == nil {
+		return
+	}
+	if err := s.earnings.RecordRefund(ctx, orderID); err != nil {
+		log.Printf("PaymentService.recordRefund failed. order_id=%s err=%v", orderID, err)
+	}
+}
+
+func (s *paymentService) notifyRefund(ctx context.Context, order *domain.Order, refund *domain.Refund) {
+	if s.notifier == nil {
+		return
+	}
+	items := lineItems(order)
+	amount := formatMoney(refund.Amount, refund.Currency)
+
+	buyerTitle := "Order refunded"
+	buyerMessage := fmt.Sprintf("Your purchase of %s was refunded (%s) and is no longer available for download.", describePurchase(items), amount)
+	buyerType := notificationDomain.NotificationTypeInfo
+	if refund.Kind == domain.RefundKindChargeback {
+		buyerTitle = "License revoked"
+		buyerMessage = fmt.Sprintf("Your purchase of %s was revoked after a payment dispute.", describePurchase(items))
+		buyerType = notificationDomain.NotificationTypeWarning
+	}
+	if err := s.notifier.Create(ctx, order.UserID, notificationDomain.CategoryAccount, buyerTitle, buyerMessage, buyerType); err != nil {
+		log.Printf("PaymentService.notifyRefund buyer notification failed. order_id=%s err=%v", order.ID, err)
+	}
+
+	producers, itemsByProducer := s.itemsByProducer(ctx, order, items)
+	for _, producerID := range producers {
+		producerItems := itemsByProducer[producerID]
+		producerAmount := 0
+		for _, item := range producerItems {
+			producerAmount += item.Amount
+		}
+		sale := describePurchase(producerItems)
+		producerTitle := "Sale refunded"
+		producerMessage := fmt.Sprintf("A sale of %s (%s) was refunded.", sale, formatMoney(producerAmount, order.Currency))
+		if refund.Kind == domain.RefundKindChargeback {
+			producerTitle = "Sale charged back"
+			producerMessage = fmt.Sprintf("A sale of %s (%s) was reversed by a payment dispute.", sale, formatMoney(producerAmount, order.Currency))
+		}
+		if err := s.notifier.Create(ctx, producerID, notificationDomain.CategoryAccount, producerTitle, producerMessage, notificationDomain.NotificationTypeWarning); err != nil {
+			log.Printf("PaymentService.notifyRefund producer notification failed. order_id=%s err=%v", order.ID, err)
+		}
+	}
+}
+
+func razorpayNote(entity map[string]any, key string) string {
+	notes, ok := entity["notes"].(map[string]any)
+	if !ok {
+		return ""
+	}
+	return stringFromAny(notes[key])
+}
+
+func intFromAny(value any) int {
+	switch v := value.(type) {
+	case float64:
+		return int(v)
+	case int:
+		return v
+	case string:
+		n, _ := strconv.Atoi(strings.TrimSpace(v))
+		return n
+	default:
+		return 0
+	}
+}
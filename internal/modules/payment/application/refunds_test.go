@@ -0,0 +1,305 @@
+package application
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	catalogDomain "github.com/saransh1220/blueprint-audio/internal/modules/catalog/domain"
+	notificationDomain "github.com/saransh1220/blueprint-audio/internal/modules/notification/domain"
+	"github.com/saransh1220/blueprint-audio/internal/modules/payment/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newRefundSvc() (*paymentService, *orderRepoMock, *paymentRepoMock, *refundRepoMock, *specFinderMock, *notifierMock) {
+	s, or, pr, _, sf, _, _, _ := newPaymentSvc()
+	rr := new(refundRepoMock)
+	n := new(notifierMock)
+	s.refundRepo = rr
+	s.notifier = n
+	return s, or, pr, rr, sf, n
+}
+
+func paidOrder(provider string) *domain.Order {
+	return &domain.Order{
+		ID:          uuid.New(),
+		UserID:      uuid.New(),
+		SpecID:      uuid.New(),
+		LicenseType: "Trackout",
+		Amount:      2500,
+		Currency:    "INR",
+		Provider:    provider,
+		Status:      domain.OrderStatusPaid,
+		Notes:       map[string]any{"spec_title": "Night Drive"},
+	}
+}
+
+func signRazorpayWebhook(t *testing.T, secret string, body any) ([]byte, map[string]string) {
+	t.Helper()
+	payload, err := json.Marshal(body)
+	require.NoError(t, err)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return payload, map[string]string{"x-razorpay-signature": hex.EncodeToString(mac.Sum(nil))}
+}
+
+func TestPaymentService_HandleRazorpayWebhook_RefundProcessedRevokesAndNotifies(t *testing.T) {
+	s, or, pr, rr, sf, n := newRefundSvc()
+	s.razorpayWebhookSecret = "rzp-webhook-secret"
+	ctx := context.Background()
+	order := paidOrder("razorpay")
+	producerID := uuid.New()
+
+	payload, headers := signRazorpayWebhook(t, s.razorpayWebhookSecret, map[string]any{
+		"event": "refund.processed",
+		"payload": map[string]any{
+			"refund": map[string]any{"entity": map[string]any{
+				"id": "rfnd_1", "payment_id": "pay_1", "amount": 2500, "currency": "INR",
+				"notes": map[string]any{"reason": "duplicate purchase"},
+			}},
+		},
+	})
+
+	pr.On("GetByRazorpayID", ctx, "pay_1").Return(&domain.Payment{OrderID: order.ID}, nil).Once()
+	or.On("GetByID", ctx, order.ID).Return(order, nil).Once()
+	rr.On("Apply", ctx, mock.MatchedBy(func(refund *domain.Refund) bool {
+		return refund.OrderID == order.ID &&
+			refund.Kind == domain.RefundKindRefund &&
+			refund.Provider == "razorpay" &&
+			*refund.ProviderRefundID == "rfnd_1" &&
+			*refund.Reason == "duplicate purchase" &&
+			refund.Amount == 2500
+	}), domain.RefundAudit{}).Return(true, nil).Once()
+	sf.On("FindByIDIncludingDeleted", mock.Anything, order.SpecID).Return(&catalogDomain.Spec{ID: order.SpecID, ProducerID: producerID}, nil).Once()
+	n.On("Create", mock.Anything, order.UserID, notificationDomain.CategoryAccount, "Order refunded", mock.AnythingOfType("string"), notificationDomain.NotificationTypeInfo).Return(nil).Once()
+	n.On("Create", mock.Anything, producerID, notificationDomain.CategoryAccount, "Sale refunded", mock.AnythingOfType("string"), notificationDomain.NotificationTypeWarning).Return(nil).Once()
+	earnings := new(earningsRecorderMock)
+	s.earnings = earnings
+	earnings.On("RecordRefund", ctx, order.ID).Return(nil).Once()
+
+	require.NoError(t, s.HandleRazorpayWebhook(ctx, payload, headers))
+	assert.Equal(t, domain.OrderStatusRefunded, order.Status)
+
+	time.Sleep(50 * time.Millisecond)
+	rr.AssertExpectations(t)
+	n.AssertExpectations(t)
+	earnings.AssertExpectations(t)
+}
+
+func TestPaymentService_HandleRazorpayWebhook_SignatureAndIgnoredEvents(t *testing.T) {
+	s, or, pr, rr, _, _ := newRefundSvc()
+	ctx := context.Background()
+	body := map[string]any{"event": "refund.processed"}
+
+	payload, headers := signRazorpayWebhook(t, "secret", body)
+	assert.EqualError(t, s.HandleRazorpayWebhook(ctx, payload, headers), "razorpay webhook secret is not configured")
+
+	s.razorpayWebhookSecret = "secret"
+	assert.EqualError(t, s.HandleRazorpayWebhook(ctx, payload, map[string]string{"x-razorpay-signature": "bad"}), "invalid razorpay webhook signature")
+
+	payload, headers = signRazorpayWebhook(t, "secret", map[string]any{"event": "payment.authorized"})
+	assert.NoError(t, s.HandleRazorpayWebhook(ctx, payload, headers))
+
+	// A refund for a payment no order is known for is acknowledged.
+	payload, headers = signRazorpayWebhook(t, "secret", map[string]any{
+		"event": "refund.processed",
+		"payload": map[string]any{
+			"refund": map[string]any{"entity": map[string]any{"id": "rfnd_3", "payment_id": "pay_unknown", "amount": 500}},
+		},
+	})
+	pr.On("GetByRazorpayID", ctx, "pay_unknown").Return(nil, domain.ErrPaymentNotFound).Once()
+	assert.NoError(t, s.HandleRazorpayWebhook(ctx, payload, headers))
+	rr.AssertNotCalled(t, "Apply", mock.Anything, mock.Anything, mock.Anything)
+
+	// Other lookup failures are returned so the provider retries.
+	order := paidOrder("razorpay")
+	payload, headers = signRazorpayWebhook(t, "secret", map[string]any{
+		"event": "refund.processed",
+		"payload": map[string]any{
+			"refund": map[string]any{"entity": map[string]any{"id": "rfnd_4", "payment_id": "pay_4", "amount": 500}},
+		},
+	})
+	pr.On("GetByRazorpayID", ctx, "pay_4").Return(&domain.Payment{OrderID: order.ID}, nil).Once()
+	or.On("GetByID", ctx, order.ID).Return(nil, errors.New("db down")).Once()
+	assert.EqualError(t, s.HandleRazorpayWebhook(ctx, payload, headers), "db down")
+}
+
+func TestPaymentService_HandleRazorpayWebhook_PartialRefundsAddUp(t *testing.T) {
+	s, or, pr, rr, sf, n := newRefundSvc()
+	s.razorpayWebhookSecret = "secret"
+	ctx := context.Background()
+	order := paidOrder("razorpay")
+	earnings := new(earningsRecorderMock)
+	s.earnings = earnings
+	refundEvent := func(id string, amount int) ([]byte, map[string]string) {
+		return signRazorpayWebhook(t, "secret", map[string]any{
+			"event": "refund.processed",
+			"payload": map[string]any{
+				"refund": map[string]any{"entity": map[string]any{"id": id, "payment_id": "pay_2", "amount": amount, "currency": "INR"}},
+			},
+		})
+	}
+	pr.On("GetByRazorpayID", ctx, "pay_2").Return(&domain.Payment{OrderID: order.ID}, nil)
+	or.On("GetByID", ctx, order.ID).Return(order, nil)
+
+	// A partial refund reverses its share of the earnings but leaves the
+	// license in place.
+	payload, headers := refundEvent("rfnd_2a", 500)
+	rr.On("Apply", ctx, mock.MatchedBy(func(refund *domain.Refund) bool {
+		return *refund.ProviderRefundID == "rfnd_2a" && refund.Amount == 500
+	}), domain.RefundAudit{}).Return(false, nil).Once()
+	earnings.On("RecordRefund", ctx, order.ID).Return(nil).Once()
+	require.NoError(t, s.HandleRazorpayWebhook(ctx, payload, headers))
+	assert.Equal(t, domain.OrderStatusPaid, order.Status)
+
+	// A redelivered refund is acknowledged.
+	rr.On("Apply", ctx, mock.MatchedBy(func(refund *domain.Refund) bool {
+		return *refund.ProviderRefundID == "rfnd_2a"
+	}), domain.RefundAudit{}).Return(false, domain.ErrRefundAlreadyRecorded).Once()
+	require.NoError(t, s.HandleRazorpayWebhook(ctx, payload, headers))
+
+	// The refund that completes the order amount revokes and notifies.
+	payload, headers = refundEvent("rfnd_2b", 2000)
+	rr.On("Apply", ctx, mock.MatchedBy(func(refund *domain.Refund) bool {
+		return *refund.ProviderRefundID == "rfnd_2b" && refund.Amount == 2000
+	}), domain.RefundAudit{}).Return(true, nil).Once()
+	earnings.On("RecordRefund", ctx, order.ID).Return(nil).Once()
+	sf.On("FindByIDIncludingDeleted", mock.Anything, order.SpecID).Return(&catalogDomain.Spec{ProducerID: uuid.New()}, nil).Maybe()
+	n.On("Create", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+	require.NoError(t, s.HandleRazorpayWebhook(ctx, payload, headers))
+	assert.Equal(t, domain.OrderStatusRefunded, order.Status)
+
+	time.Sleep(50 * time.Millisecond)
+	rr.AssertExpectations(t)
+	earnings.AssertExpectations(t)
+}
+
+func TestPaymentService_HandleDodoWebhook_DisputeLostIsIdempotent(t *testing.T) {
+	s, or, pr, rr, _, n := newRefundSvc()
+	ctx := context.Background()
+	secretBytes := []byte("dodo-test-webhook-secret")
+	s.dodoConfig = DodoConfig{WebhookKey: "whsec_" + base64.StdEncoding.EncodeToString(secretBytes)}
+	order := paidOrder("dodo")
+
+	payload, err := json.Marshal(map[string]any{
+		"type": "dispute.lost",
+		"data": map[string]any{"dispute_id": "dsp_1", "payment_id": "pay_dodo_1", "amount": "2500", "currency": "USD", "remarks": "fraudulent"},
+	})
+	require.NoError(t, err)
+	webhookID, timestamp := "evt_dodo_2", "1779091529"
+	mac := hmac.New(sha256.New, secretBytes)
+	mac.Write([]byte(webhookID + "." + timestamp + "." + string(payload)))
+	headers := map[string]string{
+		"webhook-id":        webhookID,
+		"webhook-timestamp": timestamp,
+		"webhook-signature": "v1," + base64.StdEncoding.EncodeToString(mac.Sum(nil)),
+	}
+
+	existing := &domain.Refund{OrderID: order.ID, Kind: domain.RefundKindChargeback}
+	pr.On("GetByRazorpayID", ctx, "pay_dodo_1").Return(&domain.Payment{OrderID: order.ID}, nil).Once()
+	or.On("GetByID", ctx, order.ID).Return(order, nil).Once()
+	rr.On("Apply", ctx, mock.MatchedBy(func(refund *domain.Refund) bool {
+		return refund.Kind == domain.RefundKindChargeback &&
+			*refund.ProviderRefundID == "dsp_1" &&
+			*refund.Reason == "fraudulent" &&
+			refund.Currency == "USD"
+	}), domain.RefundAudit{}).Return(false, domain.ErrOrderAlreadyRefunded).Once()
+	rr.On("GetByOrderID", ctx, order.ID).Return(existing, nil).Once()
+	// The redelivery still posts the reversal in case the first one failed;
+	// a ledger error is logged rather than failing the webhook.
+	earnings := new(earningsRecorderMock)
+	s.earnings = earnings
+	earnings.On("RecordRefund", ctx, order.ID).Return(errors.New("ledger unavailable")).Once()
+
+	require.NoError(t, s.HandleDodoWebhook(ctx, payload, headers))
+	rr.AssertExpectations(t)
+	earnings.AssertExpectations(t)
+	n.AssertNotCalled(t, "Create", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestPaymentService_RefundOrder_DodoRefundsThroughProvider(t *testing.T) {
+	s, or, pr, rr, sf, n := newRefundSvc()
+	ctx := context.Background()
+	order := paidOrder("dodo")
+	actorID := uuid.New()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/refunds" || r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		var body map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		// Without line items Dodo refunds the whole payment.
+		assert.Equal(t, map[string]any{"payment_id": "pay_dodo_9", "reason": "requested by buyer"}, body)
+		_ = json.NewEncoder(w).Encode(map[string]any{"refund_id": "ref_dodo_9"})
+	}))
+	defer ts.Close()
+	s.dodoConfig = DodoConfig{APIKey: "key", APIURL: ts.URL}
+
+	or.On("GetByID", ctx, order.ID).Return(order, nil).Once()
+	pr.On("GetByOrderID", ctx, order.ID).Return(&domain.Payment{OrderID: order.ID, RazorpayPaymentID: "pay_dodo_9", Amount: 2500}, nil).Once()
+	rr.On("RefundedAmount", ctx, order.ID).Return(0, nil).Once()
+	rr.On("Apply", ctx, mock.MatchedBy(func(refund *domain.Refund) bool {
+		return *refund.ProviderRefundID == "ref_dodo_9" && refund.Provider == "dodo" && refund.Amount == 2500
+	}), domain.RefundAudit{ActorID: &actorID, IPAddress: "10.0.0.1", UserAgent: "admin-ui"}).Return(true, nil).Once()
+	sf.On("FindByIDIncludingDeleted", mock.Anything, order.SpecID).Return(&catalogDomain.Spec{ProducerID: uuid.New()}, nil).Maybe()
+	n.On("Create", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+
+	refund, err := s.RefundOrder(ctx, order.ID, RefundOrderInput{ActorID: actorID, Reason: " requested by buyer ", IPAddress: "10.0.0.1", UserAgent: "admin-ui"})
+	require.NoError(t, err)
+	assert.Equal(t, domain.RefundKindRefund, refund.Kind)
+	assert.Equal(t, "requested by buyer", *refund.Reason)
+	rr.AssertExpectations(t)
+}
+
+func TestPaymentService_RefundOrder_DodoRefusesPartiallyRefundedOrders(t *testing.T) {
+	s, or, pr, rr, _, _ := newRefundSvc()
+	ctx := context.Background()
+	order := paidOrder("dodo")
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected dodo request %s %s", r.Method, r.URL.Path)
+	}))
+	defer ts.Close()
+	s.dodoConfig = DodoConfig{APIKey: "key", APIURL: ts.URL}
+
+	or.On("GetByID", ctx, order.ID).Return(order, nil).Once()
+	pr.On("GetByOrderID", ctx, order.ID).Return(&domain.Payment{OrderID: order.ID, RazorpayPaymentID: "pay_dodo_9", Amount: 2500}, nil).Once()
+	rr.On("RefundedAmount", ctx, order.ID).Return(500, nil).Once()
+
+	_, err := s.RefundOrder(ctx, order.ID, RefundOrderInput{ActorID: uuid.New()})
+	assert.ErrorIs(t, err, domain.ErrPartialProviderRefund)
+	rr.AssertNotCalled(t, "Apply", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestPaymentService_RefundOrder_StatusChecks(t *testing.T) {
+	s, or, _, rr, _, _ := newRefundSvc()
+	ctx := context.Background()
+
+	pending := paidOrder("razorpay")
+	pending.Status = domain.OrderStatusPending
+	or.On("GetByID", ctx, pending.ID).Return(pending, nil).Once()
+	_, err := s.RefundOrder(ctx, pending.ID, RefundOrderInput{})
+	assert.ErrorIs(t, err, domain.ErrOrderNotRefundable)
+
+	refunded := paidOrder("razorpay")
+	refunded.Status = domain.OrderStatusRefunded
+	existing := &domain.Refund{OrderID: refunded.ID}
+	or.On("GetByID", ctx, refunded.ID).Return(refunded, nil).Once()
+	rr.On("GetByOrderID", ctx, refunded.ID).Return(existing, nil).Once()
+	got, err := s.RefundOrder(ctx, refunded.ID, RefundOrderInput{})
+	require.NoError(t, err)
+	assert.Same(t, existing, got)
+}
@@ -14,6 +14,8 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
@@ -21,6 +23,7 @@ import (
 	"github.com/razorpay/razorpay-go"
 	authDomain "github.com/saransh1220/blueprint-audio/internal/modules/auth/domain"
 	catalogDomain "github.com/saransh1220/blueprint-audio/internal/modules/catalog/domain"
+	notificationDomain "github.com/saransh1220/blueprint-audio/internal/modules/notification/domain"
 	"github.com/saransh1220/blueprint-audio/internal/modules/payment/domain"
 	sharedemail "github.com/saransh1220/blueprint-audio/internal/shared/infrastructure/email"
 	sharedmoney "github.com/saransh1220/blueprint-audio/internal/shared/money"
@@ -33,31 +36,75 @@ func formatRazorpayReceipt(id uuid.UUID) string {
 type FileService interface {
 	GetKeyFromUrl(url string) (string, error)
 	GetPresignedURL(ctx context.Context, key string, expiresIn time.Duration) (string, error)
+	UploadWithKey(ctx context.Context, file io.Reader, key string, contentType string) (string, error)
+	OpenObject(ctx context.Context, key string) (io.ReadCloser, error)
 }
 
 type PaymentService interface {
-	CreateOrder(ctx context.Context, userID, specID, licenseOptionID uuid.UUID, currency string) (*domain.Order, error)
+	CreateOrder(ctx context.Context, userID, specID, licenseOptionID uuid.UUID, currency, couponCode string) (*domain.Order, error)
 	GetOrder(ctx context.Context, orderID uuid.UUID) (*domain.Order, error)
-	VerifyPayment(ctx context.Context, orderID uuid.UUID, razorpayPaymentID, razorpaySignature string) (*domain.License, error)
+	VerifyPayment(ctx context.Context, orderID uuid.UUID, razorpayPaymentID, razorpaySignature string) ([]domain.License, error)
 	HandleDodoWebhook(ctx context.Context, payload []byte, headers map[string]string) error
 	GetUserOrders(ctx context.Context, userID uuid.UUID, page int) ([]domain.Order, error)
 	GetUserLicenses(ctx context.Context, userID uuid.UUID, page int, search, licenseType string) ([]domain.License, int, error)
 	GetLicenseDownloads(ctx context.Context, licenseID, userID uuid.UUID) (*LicenseDownloadsResponse, error)
+	GetLicenseAgreement(ctx context.Context, licenseID, userID uuid.UUID) (*LicenseAgreement, error)
+	VerifyLicense(ctx context.Context, licenseKey string) (*LicenseVerification, error)
 	GetProducerOrders(ctx context.Context, producerID uuid.UUID, page, limit int) (*ProducerOrderResponse, error)
+	HandleRazorpayWebhook(ctx context.Context, payload []byte, headers map[string]string) error
+	RefundOrder(ctx context.Context, orderID uuid.UUID, input RefundOrderInput) (*domain.Refund, error)
+	GetCart(ctx context.Context, userID uuid.UUID, currency string) (*CartResponse, error)
+	AddToCart(ctx context.Context, userID, specID, licenseOptionID uuid.UUID, currency string) (*CartResponse, error)
+	RemoveFromCart(ctx context.Context, userID, specID uuid.UUID, currency string) (*CartResponse, error)
+	ClearCart(ctx context.Context, userID uuid.UUID) error
+	Checkout(ctx context.Context, userID uuid.UUID, currency, couponCode string) (*domain.Order, error)
+	CreateCoupon(ctx context.Context, producerID uuid.UUID, input CouponInput) (*domain.Coupon, error)
+	ListCoupons(ctx context.Context, producerID uuid.UUID) ([]domain.Coupon, error)
+	UpdateCoupon(ctx context.Context, producerID, couponID uuid.UUID, input UpdateCouponInput) (*domain.Coupon, error)
+	DeleteCoupon(ctx context.Context, producerID, couponID uuid.UUID) error
+	GetAgreementTemplate(ctx context.Context, producerID, licenseOptionID uuid.UUID) (*domain.AgreementTemplate, error)
+	UpdateAgreementTemplate(ctx context.Context, producerID, licenseOptionID uuid.UUID, input AgreementTemplateInput) (*domain.AgreementTemplate, error)
+}
+
+// Notifier defines the dependency on the notification module
+type Notifier interface {
+	Create(ctx context.Context, userID uuid.UUID, category notificationDomain.Category, title, message string, type_ notificationDomain.NotificationType) error
+	Publish(ctx context.Context, userID uuid.UUID, eventType notificationDomain.EventType, payload any) error
+}
+
+// EarningsRecorder defines the dependency on the earnings module, which posts
+// paid and refunded orders to the producer ledger. Both calls are idempotent.
+type EarningsRecorder interface {
+	RecordSale(ctx context.Context, orderID uuid.UUID) error
+	RecordRefund(ctx context.Context, orderID uuid.UUID) error
 }
 
 type paymentService struct {
-	orderRepo      domain.OrderRepository
-	paymentRepo    domain.PaymentRepository
-	licenseRepo    domain.LicenseRepository
-	specFinder     catalogDomain.SpecFinder
-	userFinder     authDomain.UserFinder
-	fileService    FileService
-	razorpayClient *razorpay.Client
-	razorpaySecret string
-	emailSender    sharedemail.Sender
-	appBaseURL     string
-	dodoConfig     DodoConfig
+	orderRepo     domain.OrderRepository
+	paymentRepo   domain.PaymentRepository
+	licenseRepo   domain.LicenseRepository
+	refundRepo    domain.RefundRepository
+	webhookEvents domain.WebhookEventRepository
+	cartRepo      domain.CartRepository
+	couponRepo    domain.CouponRepository
+	// agreementTemplates holds producer-customised license agreements.
+	agreementTemplates domain.AgreementTemplateRepository
+	specFinder         catalogDomain.SpecFinder
+	userFinder         authDomain.UserFinder
+	fileService        FileService
+	notifier           Notifier
+	earnings           EarningsRecorder
+	razorpayClient     *razorpay.Client
+	razorpaySecret     string
+	// razorpayWebhookSecret signs webhook deliveries and is configured
+	// separately from the API key secret in the Razorpay dashboard.
+	razorpayWebhookSecret string
+	emailSender           sharedemail.Sender
+	appBaseURL            string
+	// apiBaseURL is the public URL of this API; agreements link to its
+	// license verification endpoint.
+	apiBaseURL string
+	dodoConfig DodoConfig
 }
 
 type DodoConfig struct {
@@ -71,11 +118,19 @@ func NewPaymentService(
 	orderRepo domain.OrderRepository,
 	paymentRepo domain.PaymentRepository,
 	licenseRepo domain.LicenseRepository,
+	refundRepo domain.RefundRepository,
+	webhookEvents domain.WebhookEventRepository,
+	cartRepo domain.CartRepository,
+	couponRepo domain.CouponRepository,
+	agreementTemplates domain.AgreementTemplateRepository,
 	specFinder catalogDomain.SpecFinder,
 	userFinder authDomain.UserFinder,
 	fileService FileService,
+	notifier Notifier,
+	earnings EarningsRecorder,
 	emailSender sharedemail.Sender,
 	appBaseURL string,
+	apiBaseURL string,
 	dodoConfig DodoConfig,
 ) PaymentService {
 	client := razorpay.NewClient(
@@ -83,21 +138,61 @@ func NewPaymentService(
 		os.Getenv("RAZORPAY_KEY_SECRET"),
 	)
 	return &paymentService{
-		orderRepo:      orderRepo,
-		paymentRepo:    paymentRepo,
-		licenseRepo:    licenseRepo,
-		specFinder:     specFinder,
-		userFinder:     userFinder,
-		fileService:    fileService,
-		razorpayClient: client,
-		razorpaySecret: os.Getenv("RAZORPAY_KEY_SECRET"),
-		emailSender:    emailSender,
-		appBaseURL:     appBaseURL,
-		dodoConfig:     dodoConfig,
+		orderRepo:             orderRepo,
+		paymentRepo:           paymentRepo,
+		licenseRepo:           licenseRepo,
+		refundRepo:            refundRepo,
+		webhookEvents:         webhookEvents,
+		cartRepo:              cartRepo,
+		couponRepo:            couponRepo,
+		agreementTemplates:    agreementTemplates,
+		specFinder:            specFinder,
+		userFinder:            userFinder,
+		fileService:           fileService,
+		notifier:              notifier,
+		earnings:              earnings,
+		razorpayClient:        client,
+		razorpaySecret:        os.Getenv("RAZORPAY_KEY_SECRET"),
+		razorpayWebhookSecret: os.Getenv("RAZORPAY_WEBHOOK_SECRET"),
+		emailSender:           emailSender,
+		appBaseURL:            appBaseURL,
+		apiBaseURL:            apiBaseURL,
+		dodoConfig:            dodoConfig,
+	}
+}
+
+func (s *paymentService) CreateOrder(ctx context.Context, userID, specID, licenseOptionID uuid.UUID, currency, couponCode string) (*domain.Order, error) {
+	orderCurrency := resolveOrderCurrency(currency)
+	rates := sharedmoney.CurrentRates()
+	item, err := s.quoteItem(ctx, rates, specID, licenseOptionID, orderCurrency)
+	if err != nil {
+		return nil, err
 	}
+	return s.placeOrder(ctx, userID, rates, []quotedItem{*item}, orderCurrency, couponCode)
 }
 
-func (s *paymentService) CreateOrder(ctx context.Context, userID, specID, licenseOptionID uuid.UUID, currency string) (*domain.Order, error) {
+// resolveOrderCurrency returns the currency buyers shown prices in currency
+// are charged in. Orders are only charged in currencies the providers settle,
+// so every display currency other than INR is charged in USD. Requests without
+// a currency are charged in INR, as before display currencies existed.
+func resolveOrderCurrency(currency string) string {
+	requestedCurrency := strings.ToUpper(strings.TrimSpace(currency))
+	if requestedCurrency == "" || requestedCurrency == sharedmoney.CurrencyINR {
+		return sharedmoney.CurrencyINR
+	}
+	return sharedmoney.CurrencyUSD
+}
+
+// quotedItem is an order item priced at the catalog price, together with the
+// producer whose coupons may discount it.
+type quotedItem struct {
+	domain.OrderItem
+	ProducerID uuid.UUID
+}
+
+// quoteItem re-validates a spec and license option against the catalog and
+// prices the pair in the order currency with rates.
+func (s *paymentService) quoteItem(ctx context.Context, rates *sharedmoney.Rates, specID, licenseOptionID uuid.UUID, currency string) (*quotedItem, error) {
 	spec, err := s.specFinder.FindWithLicenses(ctx, specID)
 	if err != nil {
 		return nil, errors.New("Beat/Sample not found")
@@ -122,44 +217,84 @@ func (s *paymentService) CreateOrder(ctx context.Context, userID, specID, licens
 		return nil, errors.New("license option not found")
 	}
 
-	requestedCurrency := strings.ToUpper(strings.TrimSpace(currency))
-	if requestedCurrency != sharedmoney.CurrencyUSD {
-		requestedCurrency = sharedmoney.CurrencyINR
-	}
-
 	// Resolve the stored currency for this license — fall back to INR for legacy records
-	storedCurrency := strings.ToUpper(strings.TrimSpace(licenseOption.PriceCurrency))
-	if storedCurrency != sharedmoney.CurrencyINR && storedCurrency != sharedmoney.CurrencyUSD {
-		storedCurrency = sharedmoney.CurrencyINR
+	stored := licenseOption.Price()
+	if !sharedmoney.IsSupported(stored.Currency) {
+		stored.Currency = sharedmoney.CurrencyINR
 	}
 
-	displayMoney := sharedmoney.DisplayPrice(licenseOption.Price, storedCurrency, requestedCurrency)
+	// Unlike catalog display, a charge must never fall back to the stored
+	// currency when there is no rate.
+	price, err := rates.Price(stored, currency)
+	if err != nil {
+		return nil, fmt.Errorf("price not available in %s: %w", currency, err)
+	}
+
+	return &quotedItem{
+		OrderItem: domain.OrderItem{
+			SpecID:          specID,
+			LicenseOptionID: licenseOptionID,
+			LicenseType:     string(licenseOption.LicenseType),
+			LicenseName:     licenseOption.Name,
+			SpecTitle:       spec.Title,
+			Amount:          price.AmountMinor,
+			Currency:        currency,
+		},
+		ProducerID: spec.ProducerID,
+	}, nil
+}
+
+// placeOrder applies the coupon, if any, opens a single provider checkout for
+// the total of the items and stores the pending order.
+func (s *paymentService) placeOrder(ctx context.Context, userID uuid.UUID, rates *sharedmoney.Rates, quoted []quotedItem, currency, couponCode string) (*domain.Order, error) {
+	items, coupon, err := s.applyCoupon(ctx, userID, quoted, currency, couponCode)
+	if err != nil {
+		return nil, err
+	}
 
 	receiptID, err := uuid.NewV7()
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate uuid: %w", err)
 	}
 
+	total, discount := 0, 0
+	for _, item := range items {
+		total += item.Amount
+		discount += item.DiscountAmount
+	}
+
 	order := &domain.Order{
-		ID:          receiptID,
-		UserID:      userID,
-		SpecID:      specID,
-		LicenseType: string(licenseOption.LicenseType),
-		Amount:      displayMoney.AmountMinor,
-		Currency:    requestedCurrency,
-		Status:      domain.OrderStatusPending,
+		ID:       receiptID,
+		UserID:   userID,
+		Amount:   total,
+		Currency: currency,
+		Status:   domain.OrderStatusPending,
+		Items:    items,
 		Notes: map[string]any{
-			"license_option_id": licenseOptionID.String(),
-			"spec_title":        spec.Title,
-			"license_name":      licenseOption.Name,
-			"display_currency":  requestedCurrency,
+			"spec_title":       orderTitle(items),
+			"display_currency": currency,
 		},
-		ExpiresAt: time.Now().Add(30 * time.Minute),
+		ExpiresAt:              time.Now().Add(30 * time.Minute),
+		ExchangeRateSnapshotID: rates.SnapshotID,
+	}
+	if len(items) == 1 {
+		order.SpecID = items[0].SpecID
+		order.LicenseType = items[0].LicenseType
+		order.Notes["license_option_id"] = items[0].LicenseOptionID.String()
+		order.Notes["license_name"] = items[0].LicenseName
+	} else {
+		order.Notes["item_count"] = len(items)
+	}
+	if coupon != nil {
+		order.CouponID = &coupon.ID
+		order.DiscountAmount = discount
+		order.Notes["coupon_code"] = coupon.Code
+		order.Notes["discount_amount"] = discount
 	}
 
-	if requestedCurrency == sharedmoney.CurrencyUSD {
+	if currency == sharedmoney.CurrencyUSD {
 		order.Provider = "dodo"
-		checkoutURL, sessionID, err := s.createDodoCheckout(ctx, order, spec.Title, licenseOption.Name)
+		checkoutURL, sessionID, err := s.createDodoCheckout(ctx, order)
 		if err != nil {
 			return nil, err
 		}
@@ -169,7 +304,7 @@ func (s *paymentService) CreateOrder(ctx context.Context, userID, specID, licens
 	} else {
 		order.Provider = "razorpay"
 		razorpayOrderData := map[string]interface{}{
-			"amount":   displayMoney.AmountMinor,
+			"amount":   total,
 			"currency": sharedmoney.CurrencyINR,
 			"receipt":  formatRazorpayReceipt(receiptID),
 		}
@@ -191,16 +326,13 @@ func (s *paymentService) CreateOrder(ctx context.Context, userID, specID, licens
 	return order, nil
 }
 
-func (s *paymentService) createDodoCheckout(ctx context.Context, order *domain.Order, specTitle, licenseName string) (string, string, error) {
+func (s *paymentService) createDodoCheckout(ctx context.Context, order *domain.Order) (string, string, error) {
 	apiKey := strings.TrimSpace(s.dodoConfig.APIKey)
 	productID := strings.TrimSpace(s.dodoConfig.ProductID)
 	if apiKey == "" || productID == "" {
 		return "", "", errors.New("dodo payments is not configured")
 	}
-	baseURL := strings.TrimRight(s.dodoConfig.APIURL, "/")
-	if baseURL == "" {
-		baseURL = "https://test.dodopayments.com"
-	}
+	baseURL := s.dodoBaseURL()
 
 	body := map[string]any{
 		"product_cart": []map[string]any{{
@@ -209,15 +341,22 @@ func (s *paymentService) createDodoCheckout(ctx context.Context, order *domain.O
 			"amount":     order.Amount,
 		}},
 		"return_url": s.appBaseURL + "/dashboard",
-		"metadata": map[string]any{
-			"order_id":     order.ID.String(),
-			"spec_id":      order.SpecID.String(),
-			"license_type": order.LicenseType,
-			"spec_title":   specTitle,
-			"license_name": licenseName,
-		},
 	}
-	body["custom_data"] = body["metadata"]
+	metadata := map[string]any{
+		"order_id":   order.ID.String(),
+		"spec_title": stringFromAny(order.Notes["spec_title"]),
+		"item_count": strconv.Itoa(len(order.Items)),
+	}
+	if len(order.Items) == 1 {
+		metadata["spec_id"] = order.SpecID.String()
+		metadata["license_type"] = order.LicenseType
+		metadata["license_name"] = order.Items[0].LicenseName
+	}
+	if couponCode := stringFromAny(order.Notes["coupon_code"]); couponCode != "" {
+		metadata["coupon_code"] = couponCode
+	}
+	body["metadata"] = metadata
+	body["custom_data"] = metadata
 
 	payload, err := json.Marshal(body)
 	if err != nil {
@@ -252,12 +391,25 @@ func (s *paymentService) createDodoCheckout(ctx context.Context, order *domain.O
 	return *decoded.CheckoutURL, decoded.SessionID, nil
 }
 
-func (s *paymentService) VerifyPayment(ctx context.Context, orderID uuid.UUID, razorpayPaymentID, razorpaySignature string) (*domain.License, error) {
+func (s *paymentService) dodoBaseURL() string {
+	baseURL := strings.TrimRight(s.dodoConfig.APIURL, "/")
+	if baseURL == "" {
+		baseURL = "https://test.dodopayments.com"
+	}
+	return baseURL
+}
+
+func (s *paymentService) VerifyPayment(ctx context.Context, orderID uuid.UUID, razorpayPaymentID, razorpaySignature string) ([]domain.License, error) {
 	order, err := s.orderRepo.GetByID(ctx, orderID)
 	if err != nil {
 		return nil, errors.New("order not found")
 	}
 
+	if order.Status == domain.OrderStatusPaid && order.RazorpayOrderID != nil &&
+		hmac.Equal([]byte(s.generateSignature(*order.RazorpayOrderID, razorpayPaymentID)), []byte(razorpaySignature)) {
+		// The webhook won the race; hand the browser the licenses it issued.
+		return s.licenseRepo.ListByOrderID(ctx, orderID)
+	}
 	if order.Status != domain.OrderStatusPending {
 		return nil, errors.New("order already processed")
 	}
@@ -302,35 +454,128 @@ func (s *paymentService) VerifyPayment(ctx context.Context, orderID uuid.UUID, r
 		CapturedAt:        &now,
 	}
 
-	if method, ok := razorpayPayment["method"].(string); ok {
-		payment.Method = &method
-	}
-	if email, ok := razorpayPayment["email"].(string); ok {
-		payment.Email = &email
-	}
+	applyRazorpayPaymentDetails(payment, razorpayPayment)
 
-	if err := s.paymentRepo.Create(ctx, payment); err != nil {
-		return nil, err
+	licenses, err := s.fulfillOrder(ctx, order, payment)
+	if err != nil {
+		return nil, fmt.Errorf("payment ok but license failed: %w", err)
 	}
+	return licenses, nil
+}
 
-	if err := s.orderRepo.UpdateStatus(ctx, orderID, domain.OrderStatusPaid); err != nil {
+// fulfillOrder is the single path from a captured payment to issued licenses,
+// shared by VerifyPayment and the provider webhooks. Whichever caller gets
+// there first issues the licenses and sends the receipt; later callers get the
+// same licenses back.
+func (s *paymentService) fulfillOrder(ctx context.Context, order *domain.Order, payment *domain.Payment) ([]domain.License, error) {
+	licenses, err := newLicenses(order)
+	if err != nil {
 		return nil, err
 	}
+	if err := s.snapshotAgreements(ctx, licenses); err != nil {
+		return nil, fmt.Errorf("snapshot agreements: %w", err)
+	}
 
-	license, err := s.issueLicense(ctx, order)
+	issued, err := s.orderRepo.MarkPaid(ctx, payment, licenses)
+	if errors.Is(err, domain.ErrOrderAlreadyPaid) {
+		// Also covers a first delivery that died before posting the sale.
+		s.recordSale(ctx, order.ID)
+		return issued, nil
+	}
 	if err != nil {
-		return nil, fmt.Errorf("payment ok but license failed: %w", err)
+		return nil, err
 	}
+	order.Status = domain.OrderStatusPaid
+	s.recordSale(ctx, order.ID)
+	s.publishFulfilment(ctx, order, issued)
+	s.notifySale(ctx, order)
 
 	go func() {
-		emailCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		// Agreements are rendered and stored before the receipt so they can
+		// be attached to it.
+		emailCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 		defer cancel()
-		if err := s.sendReceiptEmail(emailCtx, order, payment, license); err != nil {
-			log.Printf("PaymentService.VerifyPayment receipt email failed. order_id=%s err=%v", order.ID, err)
+		agreements := s.issueAgreements(emailCtx, slices.Clone(issued))
+		if err := s.sendReceiptEmail(emailCtx, order, payment, issued, agreements); err != nil {
+			log.Printf("PaymentService.fulfillOrder receipt email failed. order_id=%s err=%v", order.ID, err)
 		}
 	}()
 
-	return license, nil
+	return issued, nil
+}
+
+// publishFulfilment tells the buyer's open sessions that the order was paid
+// and its licenses issued, so they can update without polling.
+func (s *paymentService) publishFulfilment(ctx context.Context, order *domain.Order, licenses []domain.License) {
+	if s.notifier == nil {
+		return
+	}
+	paid := OrderPaidEvent{OrderID: order.ID, Status: order.Status, Amount: order.Amount, Currency: order.Currency}
+	if err := s.notifier.Publish(ctx, order.UserID, notificationDomain.EventOrderPaid, paid); err != nil {
+		log.Printf("PaymentService.publishFulfilment order event failed. order_id=%s err=%v", order.ID, err)
+	}
+	for _, license := range licenses {
+		issued := LicenseIssuedEvent{
+			LicenseID:   license.ID,
+			OrderID:     order.ID,
+			SpecID:      license.SpecID,
+			LicenseType: license.LicenseType,
+			LicenseKey:  license.LicenseKey,
+		}
+		if err := s.notifier.Publish(ctx, order.UserID, notificationDomain.EventLicenseIssued, issued); err != nil {
+			log.Printf("PaymentService.publishFulfilment license event failed. order_id=%s license_id=%s err=%v", order.ID, license.ID, err)
+		}
+	}
+}
+
+// notifySale tells each producer in a paid order what they sold.
+func (s *paymentService) notifySale(ctx context.Context, order *domain.Order) {
+	if s.notifier == nil {
+		return
+	}
+	producers, itemsByProducer := s.itemsByProducer(ctx, order, lineItems(order))
+	for _, producerID := range producers {
+		producerItems := itemsByProducer[producerID]
+		producerAmount := 0
+		for _, item := range producerItems {
+			producerAmount += item.Amount
+		}
+		message := fmt.Sprintf("You sold %s (%s).", describePurchase(producerItems), formatMoney(producerAmount, order.Currency))
+		if err := s.notifier.Create(ctx, producerID, notificationDomain.CategorySaleMade, "New sale", message, notificationDomain.NotificationTypeSuccess); err != nil {
+			log.Printf("PaymentService.notifySale failed. order_id=%s producer_id=%s err=%v", order.ID, producerID, err)
+		}
+	}
+}
+
+// itemsByProducer groups an order's items by the producer who sells them, as
+// a cart order can span several producers. Producers are returned in the
+// order their first item appears.
+func (s *paymentService) itemsByProducer(ctx context.Context, order *domain.Order, items []domain.OrderItem) ([]uuid.UUID, map[uuid.UUID][]domain.OrderItem) {
+	var producers []uuid.UUID
+	itemsByProducer := make(map[uuid.UUID][]domain.OrderItem)
+	for _, item := range items {
+		spec, err := s.specFinder.FindByIDIncludingDeleted(ctx, item.SpecID)
+		if err != nil {
+			log.Printf("PaymentService.itemsByProducer spec lookup failed. order_id=%s spec_id=%s err=%v", order.ID, item.SpecID, err)
+			continue
+		}
+		if _, seen := itemsByProducer[spec.ProducerID]; !seen {
+			producers = append(producers, spec.ProducerID)
+		}
+		itemsByProducer[spec.ProducerID] = append(itemsByProducer[spec.ProducerID], item)
+	}
+	return producers, itemsByProducer
+}
+
+// recordSale posts a paid order to the earnings ledger. A failure does not
+// fail fulfilment: the earnings reconciler posts the order later.
+func (s *paymentService) recordSale(ctx context.Context, orderID uuid.UUID) {
+	if s.earnings == nil {
+		return
+	}
+	if err := s.earnings.RecordSale(ctx, orderID); err != nil {
+		log.Printf("PaymentService.recordSale failed. order_id=%s err=%v", orderID, err)
+	}
 }
 
 func (s *paymentService) HandleDodoWebhook(ctx context.Context, payload []byte, headers map[string]string) error {
@@ -345,11 +590,23 @@ func (s *paymentService) HandleDodoWebhook(ctx context.Context, payload []byte,
 	if err := json.Unmarshal(payload, &event); err != nil {
 		return err
 	}
-	if event.Type != "payment.succeeded" {
+	return s.processWebhookEvent(ctx, "dodo", headers["webhook-id"], event.Type, func() error {
+		return s.handleDodoEvent(ctx, event.Type, event.Data, headers)
+	})
+}
+
+func (s *paymentService) handleDodoEvent(ctx context.Context, eventType string, data map[string]any, headers map[string]string) error {
+	switch eventType {
+	case "payment.succeeded":
+	case "refund.succeeded":
+		return s.handleProviderRefund(ctx, dodoRefundEvent(data, domain.RefundKindRefund))
+	case "dispute.lost", "dispute.accepted":
+		return s.handleProviderRefund(ctx, dodoRefundEvent(data, domain.RefundKindChargeback))
+	default:
 		return nil
 	}
 
-	orderID, paymentID := extractDodoOrderMetadata(event.Data)
+	orderID, paymentID := extractDodoOrderMetadata(data)
 	if orderID == uuid.Nil {
 		return errors.New("dodo webhook missing order_id")
 	}
@@ -364,28 +621,23 @@ func (s *paymentService) HandleDodoWebhook(ctx context.Context, payload []byte,
 	if order.Status == domain.OrderStatusPaid {
 		return nil
 	}
-	if order.Status != domain.OrderStatusPending {
-		return errors.New("order is not payable")
-	}
 
 	payment := &domain.Payment{
 		OrderID:           order.ID,
 		RazorpayPaymentID: paymentID,
-		RazorpaySignature: headers["webhook-signature"],
 		Amount:            order.Amount,
 		Currency:          order.Currency,
 		Status:            domain.PaymentStatusCaptured,
 	}
 	now := time.Now()
 	payment.CapturedAt = &now
-	if err := s.paymentRepo.Create(ctx, payment); err != nil {
-		return err
-	}
-	if err := s.orderRepo.UpdateStatus(ctx, order.ID, domain.OrderStatusPaid); err != nil {
+	if _, err := s.fulfillOrder(ctx, order, payment); err != nil {
+		if errors.Is(err, domain.ErrInvalidOrderStatus) {
+			return errors.New("order is not payable")
+		}
 		return err
 	}
-	_, err = s.issueLicense(ctx, order)
-	return err
+	return nil
 }
 
 func (s *paymentService) verifyDodoSignature(payload []byte, headers map[string]string) error {
@@ -526,37 +778,86 @@ func (s *paymentService) generateSignature(orderID, paymentID string) string {
 	return hex.EncodeToString(h.Sum(nil))
 }
 
-func (s *paymentService) issueLicense(ctx context.Context, order *domain.Order) (*domain.License, error) {
-	licenseOptionIDStr, ok := order.Notes["license_option_id"].(string)
-	if !ok {
-		return nil, errors.New("license_option_id missing")
-	}
-	licenseOptionID, err := uuid.Parse(licenseOptionIDStr)
-	if err != nil {
-		return nil, errors.New("invalid license_option_id")
+// newLicenses builds one license per order item.
+func newLicenses(order *domain.Order) ([]domain.License, error) {
+	if len(order.Items) == 0 {
+		licenseOptionIDStr, ok := order.Notes["license_option_id"].(string)
+		if !ok {
+			return nil, errors.New("license_option_id missing")
+		}
+		if _, err := uuid.Parse(licenseOptionIDStr); err != nil {
+			return nil, errors.New("invalid license_option_id")
+		}
 	}
 
-	licenseKeyID, err := uuid.NewV7()
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate uuid: %w", err)
-	}
+	items := lineItems(order)
+	licenses := make([]domain.License, 0, len(items))
+	for _, item := range items {
+		licenseKeyID, err := uuid.NewV7()
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate uuid: %w", err)
+		}
+		licenses = append(licenses, domain.License{
+			OrderID:         order.ID,
+			UserID:          order.UserID,
+			SpecID:          item.SpecID,
+			LicenseOptionID: item.LicenseOptionID,
+			LicenseType:     item.LicenseType,
+			PurchasePrice:   item.Amount,
+			Currency:        order.Currency,
+			LicenseKey:      fmt.Sprintf("LIC-%s", licenseKeyID.String()),
+			IsActive:        true,
+			IsRevoked:       false,
+			DownloadsCount:  0,
+			IssuedAt:        time.Now(),
+		})
+	}
+	return licenses, nil
+}
 
-	license := &domain.License{
+// lineItems returns the order's items. Orders placed before line items were
+// recorded describe their only item on the order itself.
+func lineItems(order *domain.Order) []domain.OrderItem {
+	if len(order.Items) > 0 {
+		return order.Items
+	}
+	specTitle, _ := order.Notes["spec_title"].(string)
+	licenseName, _ := order.Notes["license_name"].(string)
+	licenseOptionID, _ := uuid.Parse(stringFromAny(order.Notes["license_option_id"]))
+	return []domain.OrderItem{{
 		OrderID:         order.ID,
-		UserID:          order.UserID,
 		SpecID:          order.SpecID,
 		LicenseOptionID: licenseOptionID,
 		LicenseType:     order.LicenseType,
-		PurchasePrice:   order.Amount,
+		LicenseName:     licenseName,
+		SpecTitle:       specTitle,
+		Amount:          order.Amount,
 		Currency:        order.Currency,
-		LicenseKey:      fmt.Sprintf("LIC-%s", licenseKeyID.String()),
-		IsActive:        true,
-		IsRevoked:       false,
-		DownloadsCount:  0,
-		IssuedAt:        time.Now(),
+	}}
+}
+
+// orderTitle names an order after its first item, e.g. "Night Drive + 2 more".
+func orderTitle(items []domain.OrderItem) string {
+	if len(items) == 0 {
+		return ""
 	}
+	if len(items) == 1 {
+		return items[0].SpecTitle
+	}
+	return fmt.Sprintf("%s + %d more", items[0].SpecTitle, len(items)-1)
+}
 
-	return license, s.licenseRepo.Create(ctx, license)
+// describePurchase phrases items for notifications, e.g. `a Basic license for
+// "Night Drive"` or `3 licenses ("Night Drive + 2 more")`.
+func describePurchase(items []domain.OrderItem) string {
+	if len(items) == 1 {
+		title := items[0].SpecTitle
+		if title == "" {
+			title = "your purchase"
+		}
+		return fmt.Sprintf("a %s license for %q", items[0].LicenseType, title)
+	}
+	return fmt.Sprintf("%d licenses (%q)", len(items), orderTitle(items))
 }
 
 func (s *paymentService) GetLicenseDownloads(ctx context.Context, licenseID, userID uuid.UUID) (*LicenseDownloadsResponse, error) {
@@ -665,7 +966,7 @@ func (s *paymentService) GetProducerOrders(ctx context.Context, producerID uuid.
 	}, nil
 }
 
-func (s *paymentService) sendReceiptEmail(ctx context.Context, order *domain.Order, payment *domain.Payment, license *domain.License) error {
+func (s *paymentService) sendReceiptEmail(ctx context.Context, order *domain.Order, payment *domain.Payment, licenses []domain.License, agreements []sharedemail.Attachment) error {
 	if s.emailSender == nil || s.userFinder == nil {
 		return nil
 	}
@@ -682,16 +983,25 @@ func (s *paymentService) sendReceiptEmail(ctx context.Context, order *domain.Ord
 	if specTitle == "" {
 		specTitle = "Blueprint purchase"
 	}
+	licenseType := order.LicenseType
+	if len(licenses) > 1 {
+		licenseType = fmt.Sprintf("%d licenses", len(licenses))
+	}
+	licenseIDs := make([]string, len(licenses))
+	for i, license := range licenses {
+		licenseIDs[i] = license.ID.String()
+	}
 
 	return s.emailSender.Send(ctx, sharedemail.BuildPaymentReceiptEmail(sharedemail.ReceiptData{
 		BuyerName:     user.Name,
 		BuyerEmail:    buyerEmail,
 		SpecTitle:     specTitle,
-		LicenseType:   order.LicenseType,
+		LicenseType:   licenseType,
 		AmountDisplay: formatMoney(order.Amount, order.Currency),
 		OrderID:       order.ID.String(),
 		PaymentID:     payment.RazorpayPaymentID,
-		LicenseID:     license.ID.String(),
+		LicenseID:     strings.Join(licenseIDs, ", "),
+		Attachments:   agreements,
 	}, s.appBaseURL))
 }
 
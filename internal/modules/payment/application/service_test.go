@@ -7,8 +7,10 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"sync"
 	"testing"
 	"time"
 
@@ -16,8 +18,10 @@ import (
 	"github.com/razorpay/razorpay-go"
 	authDomain "github.com/saransh1220/blueprint-audio/internal/modules/auth/domain"
 	catalogDomain "github.com/saransh1220/blueprint-audio/internal/modules/catalog/domain"
+	notificationDomain "github.com/saransh1220/blueprint-audio/internal/modules/notification/domain"
 	"github.com/saransh1220/blueprint-audio/internal/modules/payment/domain"
 	sharedemail "github.com/saransh1220/blueprint-audio/internal/shared/infrastructure/email"
+	sharedmoney "github.com/saransh1220/blueprint-audio/internal/shared/money"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -47,6 +51,21 @@ func (m *orderRepoMock) UpdateStatus(ctx context.Context, id uuid.UUID, status d
 	args := m.Called(ctx, id, status)
 	return args.Error(0)
 }
+func (m *orderRepoMock) MarkPaid(ctx context.Context, payment *domain.Payment, licenses []domain.License) ([]domain.License, error) {
+	args := m.Called(ctx, payment, licenses)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.License), args.Error(1)
+}
+func (m *orderRepoMock) MarkFailed(ctx context.Context, id uuid.UUID) (bool, error) {
+	args := m.Called(ctx, id)
+	return args.Bool(0), args.Error(1)
+}
+func (m *orderRepoMock) FailExpired(ctx context.Context, expiredBefore time.Time) (int64, error) {
+	args := m.Called(ctx, expiredBefore)
+	return args.Get(0).(int64), args.Error(1)
+}
 func (m *orderRepoMock) ListByUser(ctx context.Context, userID uuid.UUID, limit, offset int) ([]domain.Order, error) {
 	args := m.Called(ctx, userID, limit, offset)
 	if args.Get(0) == nil {
@@ -103,13 +122,20 @@ func (m *licenseRepoMock) GetByID(ctx context.Context, id uuid.UUID) (*domain.Li
 	}
 	return args.Get(0).(*domain.License), args.Error(1)
 }
-func (m *licenseRepoMock) GetByOrderID(ctx context.Context, orderID uuid.UUID) (*domain.License, error) {
-	args := m.Called(ctx, orderID)
+func (m *licenseRepoMock) GetByKey(ctx context.Context, licenseKey string) (*domain.License, error) {
+	args := m.Called(ctx, licenseKey)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*domain.License), args.Error(1)
 }
+func (m *licenseRepoMock) ListByOrderID(ctx context.Context, orderID uuid.UUID) ([]domain.License, error) {
+	args := m.Called(ctx, orderID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.License), args.Error(1)
+}
 func (m *licenseRepoMock) ListByUser(ctx context.Context, userID uuid.UUID, limit, offset int, search, licenseType string) ([]domain.License, int, error) {
 	args := m.Called(ctx, userID, limit, offset, search, licenseType)
 	if args.Get(0) == nil {
@@ -125,6 +151,49 @@ func (m *licenseRepoMock) Revoke(ctx context.Context, id uuid.UUID, reason strin
 	args := m.Called(ctx, id, reason)
 	return args.Error(0)
 }
+func (m *licenseRepoMock) SetAgreementKey(ctx context.Context, id uuid.UUID, key string) error {
+	return m.Called(ctx, id, key).Error(0)
+}
+
+type refundRepoMock struct{ mock.Mock }
+
+func (m *refundRepoMock) Apply(ctx context.Context, refund *domain.Refund, audit domain.RefundAudit) (bool, error) {
+	args := m.Called(ctx, refund, audit)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *refundRepoMock) RefundedAmount(ctx context.Context, orderID uuid.UUID) (int, error) {
+	args := m.Called(ctx, orderID)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *refundRepoMock) GetByOrderID(ctx context.Context, orderID uuid.UUID) (*domain.Refund, error) {
+	args := m.Called(ctx, orderID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Refund), args.Error(1)
+}
+
+type notifierMock struct{ mock.Mock }
+
+func (m *notifierMock) Create(ctx context.Context, userID uuid.UUID, category notificationDomain.Category, title, message string, type_ notificationDomain.NotificationType) error {
+	args := m.Called(ctx, userID, category, title, message, type_)
+	return args.Error(0)
+}
+
+func (m *notifierMock) Publish(ctx context.Context, userID uuid.UUID, eventType notificationDomain.EventType, payload any) error {
+	return m.Called(ctx, userID, eventType, payload).Error(0)
+}
+
+type earningsRecorderMock struct{ mock.Mock }
+
+func (m *earningsRecorderMock) RecordSale(ctx context.Context, orderID uuid.UUID) error {
+	return m.Called(ctx, orderID).Error(0)
+}
+func (m *earningsRecorderMock) RecordRefund(ctx context.Context, orderID uuid.UUID) error {
+	return m.Called(ctx, orderID).Error(0)
+}
 
 type specFinderMock struct{ mock.Mock }
 
@@ -171,6 +240,17 @@ func (m *fileSvcMock) GetPresignedURL(ctx context.Context, key string, expiratio
 	args := m.Called(ctx, key, expiration)
 	return args.String(0), args.Error(1)
 }
+func (m *fileSvcMock) UploadWithKey(ctx context.Context, file io.Reader, key string, contentType string) (string, error) {
+	args := m.Called(ctx, file, key, contentType)
+	return args.String(0), args.Error(1)
+}
+func (m *fileSvcMock) OpenObject(ctx context.Context, key string) (io.ReadCloser, error) {
+	args := m.Called(ctx, key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(io.ReadCloser), args.Error(1)
+}
 
 type userFinderMock struct{ mock.Mock }
 
@@ -193,6 +273,28 @@ func (m *emailSenderMock) Send(ctx context.Context, msg sharedemail.Message) err
 	return args.Error(0)
 }
 
+// webhookEventStore is an in-memory WebhookEventRepository.
+type webhookEventStore struct {
+	mu     sync.Mutex
+	events map[string]string
+}
+
+func (s *webhookEventStore) IsProcessed(ctx context.Context, provider, eventID string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.events[provider+":"+eventID]
+	return ok, nil
+}
+func (s *webhookEventStore) MarkProcessed(ctx context.Context, provider, eventID, eventType string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.events == nil {
+		s.events = map[string]string{}
+	}
+	s.events[provider+":"+eventID] = eventType
+	return nil
+}
+
 func newPaymentSvc() (*paymentService, *orderRepoMock, *paymentRepoMock, *licenseRepoMock, *specFinderMock, *fileSvcMock, *userFinderMock, *emailSenderMock) {
 	or := new(orderRepoMock)
 	pr := new(paymentRepoMock)
@@ -205,12 +307,14 @@ func newPaymentSvc() (*paymentService, *orderRepoMock, *paymentRepoMock, *licens
 		orderRepo:      or,
 		paymentRepo:    pr,
 		licenseRepo:    lr,
+		webhookEvents:  &webhookEventStore{},
 		specFinder:     sf,
 		userFinder:     uf,
 		fileService:    fs,
 		razorpaySecret: "key-secret",
 		emailSender:    es,
 		appBaseURL:     "http://localhost:4200",
+		apiBaseURL:     "https://api.example.com",
 	}, or, pr, lr, sf, fs, uf, es
 }
 
@@ -239,17 +343,17 @@ func TestPaymentService_CreateOrder_Errors(t *testing.T) {
 	licenseID := uuid.New()
 
 	sf.On("FindWithLicenses", ctx, specID).Return(nil, errors.New("not found")).Once()
-	_, err := s.CreateOrder(ctx, userID, specID, licenseID, "INR")
+	_, err := s.CreateOrder(ctx, userID, specID, licenseID, "INR", "")
 	assert.EqualError(t, err, "Beat/Sample not found")
 
 	spec := &catalogDomain.Spec{ID: specID, Title: "Track", Licenses: []catalogDomain.LicenseOption{}}
 	sf.On("FindWithLicenses", ctx, specID).Return(spec, nil).Once()
-	_, err = s.CreateOrder(ctx, userID, specID, licenseID, "INR")
+	_, err = s.CreateOrder(ctx, userID, specID, licenseID, "INR", "")
 	assert.EqualError(t, err, "license option not found")
 
 	spec.ProcessingStatus = catalogDomain.ProcessingStatusProcessing
 	sf.On("FindWithLicenses", ctx, specID).Return(spec, nil).Once()
-	_, err = s.CreateOrder(ctx, userID, specID, licenseID, "INR")
+	_, err = s.CreateOrder(ctx, userID, specID, licenseID, "INR", "")
 	assert.EqualError(t, err, "Beat/Sample is not ready for purchase")
 }
 
@@ -376,49 +480,64 @@ func TestPaymentService_GetLicenseDownloads_Errors(t *testing.T) {
 	assert.EqualError(t, err, "spec not found")
 }
 
-func TestPaymentService_IssueLicense(t *testing.T) {
-	s, _, _, lr, sf, _, _, _ := newPaymentSvc()
-	ctx := context.Background()
-	specID := uuid.New()
+func TestNewLicense(t *testing.T) {
 	loID := uuid.New()
-
 	order := &domain.Order{
 		ID:          uuid.New(),
 		UserID:      uuid.New(),
-		SpecID:      specID,
+		SpecID:      uuid.New(),
 		LicenseType: "Premium",
 		Amount:      1200,
+		Currency:    "INR",
 		Notes:       map[string]any{"license_option_id": loID.String()},
 	}
 
-	spec := &catalogDomain.Spec{
-		ID: specID,
-		Licenses: []catalogDomain.LicenseOption{
-			{ID: loID, LicenseType: catalogDomain.LicenseType("Premium"), Name: "Premium"},
-		},
-	}
-	sf.On("FindWithLicenses", ctx, specID).Return(spec, nil).Once()
-	lr.On("Create", ctx, mock.AnythingOfType("*domain.License")).Return(nil).Once()
-	lic, err := s.issueLicense(ctx, order)
+	licenses, err := newLicenses(order)
 	assert.NoError(t, err)
+	require.Len(t, licenses, 1)
+	lic := licenses[0]
 	assert.Equal(t, loID, lic.LicenseOptionID)
+	assert.Equal(t, order.ID, lic.OrderID)
+	assert.Equal(t, 1200, lic.PurchasePrice)
+	assert.True(t, lic.IsActive)
 }
 
-func TestPaymentService_IssueLicense_InvalidLicenseOptionID(t *testing.T) {
-	s, _, _, _, _, _, _, _ := newPaymentSvc()
-	ctx := context.Background()
+func TestNewLicense_InvalidLicenseOptionID(t *testing.T) {
 	order := &domain.Order{
 		ID:          uuid.New(),
-		UserID:      uuid.New(),
-		SpecID:      uuid.New(),
 		LicenseType: "Premium",
-		Amount:      1200,
 		Notes:       map[string]any{"license_option_id": "not-a-uuid"},
 	}
-	_, err := s.issueLicense(ctx, order)
+	_, err := newLicenses(order)
 	assert.EqualError(t, err, "invalid license_option_id")
 }
 
+func TestNewLicenses_OneLicensePerItem(t *testing.T) {
+	order := &domain.Order{
+		ID:       uuid.New(),
+		UserID:   uuid.New(),
+		Amount:   3000,
+		Currency: "INR",
+		Items: []domain.OrderItem{
+			{SpecID: uuid.New(), LicenseOptionID: uuid.New(), LicenseType: "Basic", Amount: 1000},
+			{SpecID: uuid.New(), LicenseOptionID: uuid.New(), LicenseType: "Premium", Amount: 2000},
+		},
+	}
+
+	licenses, err := newLicenses(order)
+	require.NoError(t, err)
+	require.Len(t, licenses, 2)
+	for i, item := range order.Items {
+		assert.Equal(t, order.ID, licenses[i].OrderID)
+		assert.Equal(t, item.SpecID, licenses[i].SpecID)
+		assert.Equal(t, item.LicenseOptionID, licenses[i].LicenseOptionID)
+		assert.Equal(t, item.LicenseType, licenses[i].LicenseType)
+		assert.Equal(t, item.Amount, licenses[i].PurchasePrice)
+		assert.Equal(t, "INR", licenses[i].Currency)
+	}
+	assert.NotEqual(t, licenses[0].LicenseKey, licenses[1].LicenseKey)
+}
+
 func ptr(s string) *string { return &s }
 
 func TestPaymentService_VerifyPayment_StatusUpdateFailureBranches(t *testing.T) {
@@ -468,9 +587,8 @@ func TestPaymentService_GetUserLicensesAndProducerOrders_ErrorsAndFallbacks(t *t
 	assert.EqualError(t, err, "repo")
 }
 
-func TestPaymentService_IssueLicense_MissingOptionID(t *testing.T) {
-	s, _, _, _, _, _, _, _ := newPaymentSvc()
-	_, err := s.issueLicense(context.Background(), &domain.Order{Notes: map[string]any{}})
+func TestNewLicense_MissingOptionID(t *testing.T) {
+	_, err := newLicenses(&domain.Order{Notes: map[string]any{}})
 	assert.EqualError(t, err, "license_option_id missing")
 }
 
@@ -503,13 +621,13 @@ func TestPaymentService_CreateOrder_SuccessWithLocalRazorpay(t *testing.T) {
 		ID:    specID,
 		Title: "Track",
 		Licenses: []catalogDomain.LicenseOption{
-			{ID: loID, LicenseType: catalogDomain.LicenseBasic, Name: "Basic", Price: 99, PriceCurrency: "EUR"},
+			{ID: loID, LicenseType: catalogDomain.LicenseBasic, Name: "Basic", PriceMinor: 9900, PriceCurrency: "BRL"},
 		},
 	}
 	sf.On("FindWithLicenses", ctx, specID).Return(spec, nil).Once()
 	or.On("Create", ctx, mock.AnythingOfType("*domain.Order")).Return(nil).Once()
 
-	order, err := s.CreateOrder(ctx, userID, specID, loID, "INR")
+	order, err := s.CreateOrder(ctx, userID, specID, loID, "INR", "")
 	assert.NoError(t, err)
 	assert.NotNil(t, order)
 	assert.Equal(t, "Basic", order.LicenseType)
@@ -519,8 +637,44 @@ func TestPaymentService_CreateOrder_SuccessWithLocalRazorpay(t *testing.T) {
 	assert.Regexp(t, "^order_[0-9a-f]{32}$", capturedReceipt)
 }
 
+func TestPaymentService_CreateOrder_RecordsExchangeRateSnapshot(t *testing.T) {
+	s, or, _, _, sf, _, _, _ := newPaymentSvc()
+	ctx := context.Background()
+	snapshotID := uuid.New()
+	sharedmoney.SetRates(&sharedmoney.Rates{SnapshotID: &snapshotID, Base: sharedmoney.CurrencyUSD, Rates: map[string]float64{"INR": 80, "EUR": 0.8}})
+	defer sharedmoney.SetRates(nil)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": "order_local_fx"})
+	}))
+	defer ts.Close()
+	s.razorpayClient = razorpay.NewClient("key", "secret")
+	s.razorpayClient.Request.BaseURL = ts.URL
+
+	specID, eurID, gbpID := uuid.New(), uuid.New(), uuid.New()
+	spec := &catalogDomain.Spec{
+		ID:    specID,
+		Title: "Track",
+		Licenses: []catalogDomain.LicenseOption{
+			{ID: eurID, LicenseType: catalogDomain.LicenseBasic, Name: "Basic", PriceMinor: 1000, PriceCurrency: "EUR"},
+			{ID: gbpID, LicenseType: catalogDomain.LicensePremium, Name: "Premium", PriceMinor: 2000, PriceCurrency: "GBP"},
+		},
+	}
+	sf.On("FindWithLicenses", ctx, specID).Return(spec, nil).Twice()
+	or.On("Create", ctx, mock.AnythingOfType("*domain.Order")).Return(nil).Once()
+
+	order, err := s.CreateOrder(ctx, uuid.New(), specID, eurID, "INR", "")
+	require.NoError(t, err)
+	assert.Equal(t, 100000, order.Amount)
+	assert.Equal(t, &snapshotID, order.ExchangeRateSnapshotID)
+
+	// A charge is never made in the stored currency when there is no rate.
+	_, err = s.CreateOrder(ctx, uuid.New(), specID, gbpID, "INR", "")
+	assert.ErrorIs(t, err, sharedmoney.ErrNoRate)
+}
+
 func TestPaymentService_VerifyPayment_SuccessAndNotCaptured(t *testing.T) {
-	s, or, pr, lr, _, _, uf, es := newPaymentSvc()
+	s, or, _, _, _, _, uf, es := newPaymentSvc()
 	ctx := context.Background()
 	orderID := uuid.New()
 	loID := uuid.New()
@@ -565,26 +719,28 @@ func TestPaymentService_VerifyPayment_SuccessAndNotCaptured(t *testing.T) {
 	}
 
 	signature := s.generateSignature(rzpOrderID, paymentID)
-	or.On("GetByID", ctx, orderID).Return(order, nil).Twice()
-	pr.On("Create", ctx, mock.AnythingOfType("*domain.Payment")).Return(nil).Once()
-	or.On("UpdateStatus", ctx, orderID, domain.OrderStatusPaid).Return(nil).Once()
-	lr.On("Create", ctx, mock.AnythingOfType("*domain.License")).Return(nil).Once()
+	retry := *order
+	or.On("GetByID", ctx, orderID).Return(order, nil).Once()
+	or.On("MarkPaid", ctx, mock.MatchedBy(func(payment *domain.Payment) bool {
+		return payment.RazorpayPaymentID == paymentID && *payment.Method == "card"
+	}), mock.AnythingOfType("[]domain.License")).Return([]domain.License{{OrderID: orderID, LicenseKey: "LIC-1"}}, nil).Once()
 	uf.On("FindByID", mock.Anything, order.UserID).Return(&authDomain.User{ID: order.UserID, Email: "buyer@example.com", Name: "Buyer"}, nil).Once()
 	es.On("Send", mock.Anything, mock.AnythingOfType("email.Message")).Return(nil).Once()
 
-	license, err := s.VerifyPayment(ctx, orderID, paymentID, signature)
+	licenses, err := s.VerifyPayment(ctx, orderID, paymentID, signature)
 	assert.NoError(t, err)
-	assert.NotNil(t, license)
+	assert.Len(t, licenses, 1)
 
 	time.Sleep(50 * time.Millisecond)
 
+	or.On("GetByID", ctx, orderID).Return(&retry, nil).Once()
 	or.On("UpdateStatus", ctx, orderID, domain.OrderStatusFailed).Return(nil).Once()
 	_, err = s.VerifyPayment(ctx, orderID, "pay_not_captured", s.generateSignature(rzpOrderID, "pay_not_captured"))
 	assert.EqualError(t, err, "payment not captured")
 }
 
 func TestPaymentService_HandleDodoWebhook_SucceedsWithStandardWebhookSignature(t *testing.T) {
-	s, or, pr, lr, _, _, _, _ := newPaymentSvc()
+	s, or, _, _, _, _, uf, es := newPaymentSvc()
 	ctx := context.Background()
 	orderID := uuid.New()
 	loID := uuid.New()
@@ -623,17 +779,18 @@ func TestPaymentService_HandleDodoWebhook_SucceedsWithStandardWebhookSignature(t
 		Notes:       map[string]any{"license_option_id": loID.String()},
 	}
 	or.On("GetByID", ctx, orderID).Return(order, nil).Once()
-	pr.On("Create", ctx, mock.MatchedBy(func(payment *domain.Payment) bool {
+	or.On("MarkPaid", ctx, mock.MatchedBy(func(payment *domain.Payment) bool {
 		return payment.OrderID == orderID &&
 			payment.RazorpayPaymentID == "pay_dodo_1" &&
 			payment.Status == domain.PaymentStatusCaptured
-	})).Return(nil).Once()
-	or.On("UpdateStatus", ctx, orderID, domain.OrderStatusPaid).Return(nil).Once()
-	lr.On("Create", ctx, mock.MatchedBy(func(license *domain.License) bool {
-		return license.OrderID == orderID &&
-			license.LicenseOptionID == loID &&
-			license.Currency == "USD"
-	})).Return(nil).Once()
+	}), mock.MatchedBy(func(licenses []domain.License) bool {
+		return len(licenses) == 1 &&
+			licenses[0].OrderID == orderID &&
+			licenses[0].LicenseOptionID == loID &&
+			licenses[0].Currency == "USD"
+	})).Return([]domain.License{{OrderID: orderID}}, nil).Once()
+	uf.On("FindByID", mock.Anything, order.UserID).Return(nil, errors.New("not found")).Maybe()
+	es.On("Send", mock.Anything, mock.Anything).Return(nil).Maybe()
 
 	err = s.HandleDodoWebhook(ctx, payload, map[string]string{
 		"webhook-id":        webhookID,
@@ -0,0 +1,57 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/saransh1220/blueprint-audio/internal/modules/payment/domain"
+)
+
+// licenseVerificationURL is the public address that verifies the license,
+// printed on its agreement. It is empty when no API URL is configured.
+func (s *paymentService) licenseVerificationURL(licenseKey string) string {
+	if s.apiBaseURL == "" {
+		return ""
+	}
+	return strings.TrimRight(s.apiBaseURL, "/") + "/verify/licenses/" + url.PathEscape(licenseKey)
+}
+
+// VerifyLicense returns the public view of the license with the key, so that
+// distributors and labels can check an agreement is genuine and still valid.
+func (s *paymentService) VerifyLicense(ctx context.Context, licenseKey string) (*LicenseVerification, error) {
+	licenseKey = strings.TrimSpace(licenseKey)
+	if licenseKey == "" {
+		return nil, domain.ErrLicenseNotFound
+	}
+	license, err := s.licenseRepo.GetByKey(ctx, licenseKey)
+	if err != nil {
+		return nil, err
+	}
+	spec, err := s.specFinder.FindByIDIncludingDeleted(ctx, license.SpecID)
+	if err != nil {
+		return nil, fmt.Errorf("find spec: %w", err)
+	}
+	producer, err := s.userFinder.FindByID(ctx, spec.ProducerID)
+	if err != nil {
+		return nil, fmt.Errorf("find producer: %w", err)
+	}
+
+	status := LicenseStatusActive
+	switch {
+	case license.IsRevoked:
+		status = LicenseStatusRevoked
+	case !license.IsActive:
+		status = LicenseStatusInactive
+	}
+	return &LicenseVerification{
+		LicenseKey:   license.LicenseKey,
+		SpecTitle:    spec.Title,
+		ProducerName: userDisplayName(producer),
+		LicenseType:  license.LicenseType,
+		IssuedAt:     license.IssuedAt,
+		Status:       status,
+		RevokedAt:    license.RevokedAt,
+	}, nil
+}
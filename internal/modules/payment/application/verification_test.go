@@ -0,0 +1,68 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	authDomain "github.com/saransh1220/blueprint-audio/internal/modules/auth/domain"
+	catalogDomain "github.com/saransh1220/blueprint-audio/internal/modules/catalog/domain"
+	"github.com/saransh1220/blueprint-audio/internal/modules/payment/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPaymentService_VerifyLicense(t *testing.T) {
+	s, _, _, lr, sf, _, uf, _ := newPaymentSvc()
+	ctx := context.Background()
+	specID, producerID := uuid.New(), uuid.New()
+	issuedAt := time.Date(2026, 5, 4, 10, 0, 0, 0, time.UTC)
+	license := &domain.License{ID: uuid.New(), UserID: uuid.New(), SpecID: specID, LicenseType: "Premium", LicenseKey: "LIC-1",
+		PurchasePrice: 199900, Currency: "INR", IsActive: true, IssuedAt: issuedAt}
+
+	lr.On("GetByKey", ctx, "LIC-1").Return(license, nil).Once()
+	sf.On("FindByIDIncludingDeleted", ctx, specID).Return(&catalogDomain.Spec{ID: specID, ProducerID: producerID, Title: "Midnight Drive"}, nil)
+	uf.On("FindByID", ctx, producerID).Return(&authDomain.User{ID: producerID, Name: "Sam"}, nil)
+	verification, err := s.VerifyLicense(ctx, " LIC-1 ")
+	require.NoError(t, err)
+	assert.Equal(t, &LicenseVerification{
+		LicenseKey:   "LIC-1",
+		SpecTitle:    "Midnight Drive",
+		ProducerName: "Sam",
+		LicenseType:  "Premium",
+		IssuedAt:     issuedAt,
+		Status:       LicenseStatusActive,
+	}, verification)
+
+	revokedAt := issuedAt.Add(48 * time.Hour)
+	revoked := *license
+	revoked.IsRevoked, revoked.RevokedAt = true, &revokedAt
+	lr.On("GetByKey", ctx, "LIC-1").Return(&revoked, nil).Once()
+	verification, err = s.VerifyLicense(ctx, "LIC-1")
+	require.NoError(t, err)
+	assert.Equal(t, LicenseStatusRevoked, verification.Status)
+	assert.Equal(t, &revokedAt, verification.RevokedAt)
+
+	lr.On("GetByKey", ctx, "LIC-2").Return(nil, domain.ErrLicenseNotFound).Once()
+	_, err = s.VerifyLicense(ctx, "LIC-2")
+	assert.ErrorIs(t, err, domain.ErrLicenseNotFound)
+	_, err = s.VerifyLicense(ctx, "  ")
+	assert.ErrorIs(t, err, domain.ErrLicenseNotFound)
+
+	lr.On("GetByKey", ctx, "LIC-3").Return(nil, errors.New("db down")).Once()
+	_, err = s.VerifyLicense(ctx, "LIC-3")
+	assert.Error(t, err)
+	mock.AssertExpectationsForObjects(t, lr, sf, uf)
+}
+
+func TestPaymentService_LicenseVerificationURL(t *testing.T) {
+	s, _, _, _, _, _, _, _ := newPaymentSvc()
+	assert.Equal(t, "https://api.example.com/verify/licenses/LIC-1%2F2", s.licenseVerificationURL("LIC-1/2"))
+	s.apiBaseURL = "https://api.example.com/"
+	assert.Equal(t, "https://api.example.com/verify/licenses/LIC-1", s.licenseVerificationURL("LIC-1"))
+	s.apiBaseURL = ""
+	assert.Empty(t, s.licenseVerificationURL("LIC-1"))
+}
@@ -0,0 +1,228 @@
+package application
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/saransh1220/blueprint-audio/internal/modules/payment/domain"
+)
+
+// processWebhookEvent runs handle at most once per provider event ID. The event
+// is recorded only after handle succeeds, so a failed delivery is retried in
+// full; the handlers are idempotent for concurrent deliveries in between.
+func (s *paymentService) processWebhookEvent(ctx context.Context, provider, eventID, eventType string, handle func() error) error {
+	if eventID == "" {
+		return handle()
+	}
+	processed, err := s.webhookEvents.IsProcessed(ctx, provider, eventID)
+	if err != nil {
+		return err
+	}
+	if processed {
+		return nil
+	}
+	if err := handle(); err != nil {
+		return err
+	}
+	return s.webhookEvents.MarkProcessed(ctx, provider, eventID, eventType)
+}
+
+func (s *paymentService) HandleRazorpayWebhook(ctx context.Context, payload []byte, headers map[string]string) error {
+	signature := headers["x-razorpay-signature"]
+	if err := s.verifyRazorpayWebhookSignature(payload, signature); err != nil {
+		return err
+	}
+
+	var event struct {
+		Event   string `json:"event"`
+		Payload map[string]struct {
+			Entity map[string]any `json:"entity"`
+		} `json:"payload"`
+	}
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return err
+	}
+
+	return s.processWebhookEvent(ctx, "razorpay", headers["x-razorpay-event-id"], event.Event, func() error {
+		switch event.Event {
+		case "payment.captured", "order.paid":
+			return s.handleRazorpayCapture(ctx, event.Payload["payment"].Entity)
+		case "payment.failed":
+			return s.handleRazorpayFailure(ctx, event.Payload["payment"].Entity)
+		case "refund.processed":
+			refund := event.Payload["refund"].Entity
+			return s.handleProviderRefund(ctx, providerRefundEvent{
+				Provider:          "razorpay",
+				Kind:              domain.RefundKindRefund,
+				ProviderPaymentID: stringFromAny(refund["payment_id"]),
+				ProviderRefundID:  stringFromAny(refund["id"]),
+				Amount:            intFromAny(refund["amount"]),
+				Currency:          stringFromAny(refund["currency"]),
+				Reason:            razorpayNote(refund, "reason"),
+			})
+		case "payment.dispute.lost":
+			dispute := event.Payload["dispute"].Entity
+			return s.handleProviderRefund(ctx, providerRefundEvent{
+				Provider:          "razorpay",
+				Kind:              domain.RefundKindChargeback,
+				ProviderPaymentID: stringFromAny(dispute["payment_id"]),
+				ProviderRefundID:  stringFromAny(dispute["id"]),
+				Amount:            intFromAny(dispute["amount"]),
+				Currency:          stringFromAny(dispute["currency"]),
+				Reason:            stringFromAny(dispute["reason_description"]),
+			})
+		default:
+			return nil
+		}
+	})
+}
+
+func (s *paymentService) verifyRazorpayWebhookSignature(payload []byte, signature string) error {
+	secret := strings.TrimSpace(s.razorpayWebhookSecret)
+	if secret == "" {
+		return errors.New("razorpay webhook secret is not configured")
+	}
+	if signature == "" {
+		return errors.New("missing razorpay webhook signature")
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	expected := hex.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(strings.TrimSpace(signature)), []byte(expected)) {
+		return errors.New("invalid razorpay webhook signature")
+	}
+	return nil
+}
+
+// handleRazorpayCapture issues the license for a captured payment, covering
+// buyers who close the tab before POST /payments/verify runs. The webhook
+// signature only authenticates the delivery, so it is not stored: the
+// payment's signature column holds the checkout signature, which webhook
+// captures do not have.
+func (s *paymentService) handleRazorpayCapture(ctx context.Context, entity map[string]any) error {
+	paymentID := stringFromAny(entity["id"])
+	razorpayOrderID := stringFromAny(entity["order_id"])
+	if paymentID == "" || razorpayOrderID == "" {
+		return errors.New("razorpay webhook missing payment or order id")
+	}
+
+	order, err := s.orderRepo.GetByRazorpayID(ctx, razorpayOrderID)
+	if err != nil {
+		log.Printf("PaymentService.handleRazorpayCapture ignoring unknown order. razorpay_order_id=%s", razorpayOrderID)
+		return nil
+	}
+	if order.Status == domain.OrderStatusPaid {
+		return nil
+	}
+	if amount := intFromAny(entity["amount"]); amount != order.Amount {
+		return s.refundMismatchedCapture(ctx, order, paymentID, amount, entity)
+	}
+
+	now := time.Now()
+	payment := &domain.Payment{
+		OrderID:           order.ID,
+		RazorpayPaymentID: paymentID,
+		Amount:            order.Amount,
+		Currency:          order.Currency,
+		Status:            domain.PaymentStatusCaptured,
+		CapturedAt:        &now,
+	}
+	applyRazorpayPaymentDetails(payment, entity)
+
+	if _, err := s.fulfillOrder(ctx, order, payment); err != nil {
+		if errors.Is(err, domain.ErrInvalidOrderStatus) {
+			log.Printf("PaymentService.handleRazorpayCapture ignoring capture for %s order. order_id=%s", order.Status, order.ID)
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+// refundMismatchedCapture returns a capture whose amount differs from the
+// order to the buyer and records it as a refunded payment, so the order stays
+// unpaid and later deliveries of the capture are acknowledged. A failed refund
+// is returned so the provider retries the webhook.
+func (s *paymentService) refundMismatchedCapture(ctx context.Context, order *domain.Order, paymentID string, amount int, entity map[string]any) error {
+	if _, err := s.paymentRepo.GetByRazorpayID(ctx, paymentID); err == nil {
+		return nil
+	}
+	log.Printf("PaymentService.refundMismatchedCapture refunding capture. order_id=%s payment_id=%s amount=%d order_amount=%d", order.ID, paymentID, amount, order.Amount)
+	if amount <= 0 {
+		return fmt.Errorf("%w: order_id=%s amount=%d", domain.ErrPaymentAmountMismatch, order.ID, amount)
+	}
+
+	data := map[string]interface{}{
+		"notes": map[string]interface{}{
+			"order_id": order.ID.String(),
+			"reason":   "captured amount does not match the order",
+		},
+	}
+	if _, err := s.razorpayClient.Payment.Refund(paymentID, amount, data, nil); err != nil {
+		return fmt.Errorf("%w: order_id=%s refund failed: %w", domain.ErrPaymentAmountMismatch, order.ID, err)
+	}
+
+	now := time.Now()
+	payment := &domain.Payment{
+		OrderID:           order.ID,
+		RazorpayPaymentID: paymentID,
+		Amount:            amount,
+		Currency:          strings.ToUpper(stringFromAny(entity["currency"])),
+		Status:            domain.PaymentStatusRefunded,
+		CapturedAt:        &now,
+	}
+	if payment.Currency == "" {
+		payment.Currency = order.Currency
+	}
+	applyRazorpayPaymentDetails(payment, entity)
+	if err := s.paymentRepo.Create(ctx, payment); err != nil {
+		log.Printf("PaymentService.refundMismatchedCapture failed to record refunded payment. order_id=%s payment_id=%s err=%v", order.ID, paymentID, err)
+	}
+	return nil
+}
+
+// handleRazorpayFailure fails an order still awaiting payment. Razorpay lets
+// the buyer retry on the same order, so a later capture can still fulfil it.
+func (s *paymentService) handleRazorpayFailure(ctx context.Context, entity map[string]any) error {
+	razorpayOrderID := stringFromAny(entity["order_id"])
+	if razorpayOrderID == "" {
+		return nil
+	}
+	order, err := s.orderRepo.GetByRazorpayID(ctx, razorpayOrderID)
+	if err != nil {
+		log.Printf("PaymentService.handleRazorpayFailure ignoring unknown order. razorpay_order_id=%s", razorpayOrderID)
+		return nil
+	}
+	_, err = s.orderRepo.MarkFailed(ctx, order.ID)
+	return err
+}
+
+func applyRazorpayPaymentDetails(payment *domain.Payment, entity map[string]any) {
+	payment.Method = optionalString(entity["method"])
+	payment.Email = optionalString(entity["email"])
+	payment.Contact = optionalString(entity["contact"])
+	payment.Bank = optionalString(entity["bank"])
+	payment.Wallet = optionalString(entity["wallet"])
+	payment.VPA = optionalString(entity["vpa"])
+	payment.ProviderFee = intFromAny(entity["fee"])
+	if card, ok := entity["card"].(map[string]any); ok {
+		payment.CardNetwork = optionalString(card["network"])
+		payment.CardLast4 = optionalString(card["last4"])
+	}
+}
+
+func optionalString(value any) *string {
+	str, ok := value.(string)
+	if !ok || str == "" {
+		return nil
+	}
+	return &str
+}
@@ -0,0 +1,208 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/razorpay/razorpay-go"
+	authDomain "github.com/saransh1220/blueprint-audio/internal/modules/auth/domain"
+	catalogDomain "github.com/saransh1220/blueprint-audio/internal/modules/catalog/domain"
+	notificationDomain "github.com/saransh1220/blueprint-audio/internal/modules/notification/domain"
+	"github.com/saransh1220/blueprint-audio/internal/modules/payment/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func pendingRazorpayOrder(razorpayOrderID string) *domain.Order {
+	return &domain.Order{
+		ID:              uuid.New(),
+		UserID:          uuid.New(),
+		SpecID:          uuid.New(),
+		Status:          domain.OrderStatusPending,
+		ExpiresAt:       time.Now().Add(time.Hour),
+		RazorpayOrderID: &razorpayOrderID,
+		LicenseType:     "Basic",
+		Amount:          1000,
+		Currency:        "INR",
+		Provider:        "razorpay",
+		Notes:           map[string]any{"license_option_id": uuid.NewString(), "spec_title": "Track"},
+	}
+}
+
+func TestPaymentService_HandleRazorpayWebhook_PaymentCapturedFulfilsOnce(t *testing.T) {
+	s, or, _, _, sf, _, uf, es := newPaymentSvc()
+	s.razorpayWebhookSecret = "secret"
+	earnings := new(earningsRecorderMock)
+	s.earnings = earnings
+	notifier := new(notifierMock)
+	s.notifier = notifier
+	ctx := context.Background()
+	order := pendingRazorpayOrder("order_rzp_1")
+	licenseID := uuid.New()
+
+	payload, headers := signRazorpayWebhook(t, "secret", map[string]any{
+		"event": "payment.captured",
+		"payload": map[string]any{
+			"payment": map[string]any{"entity": map[string]any{
+				"id": "pay_rzp_1", "order_id": "order_rzp_1", "amount": 1000, "currency": "INR",
+				"status": "captured", "method": "upi", "vpa": "buyer@upi", "email": "buyer@example.com",
+				"fee": 24,
+			}},
+		},
+	})
+	headers["x-razorpay-event-id"] = "evt_rzp_1"
+
+	or.On("GetByRazorpayID", ctx, "order_rzp_1").Return(order, nil).Once()
+	or.On("MarkPaid", ctx, mock.MatchedBy(func(payment *domain.Payment) bool {
+		return payment.OrderID == order.ID &&
+			payment.RazorpayPaymentID == "pay_rzp_1" &&
+			payment.RazorpaySignature == "" &&
+			payment.Status == domain.PaymentStatusCaptured &&
+			*payment.Method == "upi" &&
+			*payment.VPA == "buyer@upi" &&
+			payment.ProviderFee == 24
+	}), mock.MatchedBy(func(licenses []domain.License) bool {
+		return len(licenses) == 1 && licenses[0].OrderID == order.ID && licenses[0].PurchasePrice == 1000
+	})).Return([]domain.License{{ID: licenseID, OrderID: order.ID, SpecID: order.SpecID, LicenseType: "Basic", LicenseKey: "LIC-1"}}, nil).Once()
+	uf.On("FindByID", mock.Anything, order.UserID).Return(&authDomain.User{ID: order.UserID, Email: "buyer@example.com", Name: "Buyer"}, nil).Once()
+	es.On("Send", mock.Anything, mock.AnythingOfType("email.Message")).Return(nil).Once()
+	earnings.On("RecordSale", ctx, order.ID).Return(nil).Once()
+	notifier.On("Publish", ctx, order.UserID, notificationDomain.EventOrderPaid, OrderPaidEvent{
+		OrderID: order.ID, Status: domain.OrderStatusPaid, Amount: 1000, Currency: "INR",
+	}).Return(nil).Once()
+	notifier.On("Publish", ctx, order.UserID, notificationDomain.EventLicenseIssued, LicenseIssuedEvent{
+		LicenseID: licenseID, OrderID: order.ID, SpecID: order.SpecID, LicenseType: "Basic", LicenseKey: "LIC-1",
+	}).Return(nil).Once()
+	producerID := uuid.New()
+	sf.On("FindByIDIncludingDeleted", mock.Anything, order.SpecID).Return(&catalogDomain.Spec{ID: order.SpecID, ProducerID: producerID, Title: "Track"}, nil)
+	notifier.On("Create", ctx, producerID, notificationDomain.CategorySaleMade, "New sale",
+		`You sold a Basic license for "Track" (INR 10.00).`, notificationDomain.NotificationTypeSuccess).Return(nil).Once()
+
+	require.NoError(t, s.HandleRazorpayWebhook(ctx, payload, headers))
+	assert.Equal(t, domain.OrderStatusPaid, order.Status)
+
+	// Razorpay redelivers with the same event ID; nothing runs a second time.
+	require.NoError(t, s.HandleRazorpayWebhook(ctx, payload, headers))
+
+	time.Sleep(50 * time.Millisecond)
+	or.AssertExpectations(t)
+	es.AssertExpectations(t)
+	earnings.AssertExpectations(t)
+	notifier.AssertExpectations(t)
+}
+
+func TestPaymentService_HandleRazorpayWebhook_OrderPaidAfterVerifyIsNoop(t *testing.T) {
+	s, or, _, _, _, _, _, _ := newPaymentSvc()
+	s.razorpayWebhookSecret = "secret"
+	ctx := context.Background()
+	order := pendingRazorpayOrder("order_rzp_2")
+	order.Status = domain.OrderStatusPaid
+
+	payload, headers := signRazorpayWebhook(t, "secret", map[string]any{
+		"event": "order.paid",
+		"payload": map[string]any{
+			"payment": map[string]any{"entity": map[string]any{"id": "pay_rzp_2", "order_id": "order_rzp_2", "amount": 1000}},
+		},
+	})
+	or.On("GetByRazorpayID", ctx, "order_rzp_2").Return(order, nil).Once()
+
+	require.NoError(t, s.HandleRazorpayWebhook(ctx, payload, headers))
+	or.AssertNotCalled(t, "MarkPaid", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestPaymentService_HandleRazorpayWebhook_AmountMismatchIsRefunded(t *testing.T) {
+	s, or, pr, _, _, _, _, _ := newPaymentSvc()
+	s.razorpayWebhookSecret = "secret"
+	ctx := context.Background()
+	order := pendingRazorpayOrder("order_rzp_3")
+
+	refunds := 0
+	failRefund := true
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/payments/pay_rzp_3/refund" || r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if failRefund {
+			w.WriteHeader(http.StatusBadGateway)
+			_, _ = w.Write([]byte(`{"error":{"code":"SERVER_ERROR","description":"unavailable"}}`))
+			return
+		}
+		refunds++
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": "rfnd_rzp_3", "payment_id": "pay_rzp_3", "amount": 1})
+	}))
+	defer ts.Close()
+	s.razorpayClient = razorpay.NewClient("key", "secret")
+	s.razorpayClient.Request.BaseURL = ts.URL
+
+	payload, headers := signRazorpayWebhook(t, "secret", map[string]any{
+		"event": "payment.captured",
+		"payload": map[string]any{
+			"payment": map[string]any{"entity": map[string]any{"id": "pay_rzp_3", "order_id": "order_rzp_3", "amount": 1, "currency": "INR"}},
+		},
+	})
+	or.On("GetByRazorpayID", ctx, "order_rzp_3").Return(order, nil)
+
+	// A refund that fails is returned so Razorpay retries the webhook.
+	pr.On("GetByRazorpayID", ctx, "pay_rzp_3").Return(nil, domain.ErrPaymentNotFound).Twice()
+	assert.ErrorIs(t, s.HandleRazorpayWebhook(ctx, payload, headers), domain.ErrPaymentAmountMismatch)
+
+	failRefund = false
+	pr.On("Create", ctx, mock.MatchedBy(func(payment *domain.Payment) bool {
+		return payment.OrderID == order.ID && payment.RazorpayPaymentID == "pay_rzp_3" &&
+			payment.Amount == 1 && payment.Status == domain.PaymentStatusRefunded && payment.RazorpaySignature == ""
+	})).Return(nil).Once()
+	require.NoError(t, s.HandleRazorpayWebhook(ctx, payload, headers))
+	assert.Equal(t, 1, refunds)
+
+	// Later deliveries of the capture find the refunded payment.
+	pr.On("GetByRazorpayID", ctx, "pay_rzp_3").Return(&domain.Payment{Status: domain.PaymentStatusRefunded}, nil).Once()
+	require.NoError(t, s.HandleRazorpayWebhook(ctx, payload, headers))
+	assert.Equal(t, 1, refunds)
+	assert.Equal(t, domain.OrderStatusPending, order.Status)
+	or.AssertNotCalled(t, "MarkPaid", mock.Anything, mock.Anything, mock.Anything)
+	pr.AssertExpectations(t)
+}
+
+func TestPaymentService_HandleRazorpayWebhook_PaymentFailedMarksOrderFailed(t *testing.T) {
+	s, or, _, _, _, _, _, _ := newPaymentSvc()
+	s.razorpayWebhookSecret = "secret"
+	ctx := context.Background()
+	order := pendingRazorpayOrder("order_rzp_4")
+
+	payload, headers := signRazorpayWebhook(t, "secret", map[string]any{
+		"event": "payment.failed",
+		"payload": map[string]any{
+			"payment": map[string]any{"entity": map[string]any{"id": "pay_rzp_4", "order_id": "order_rzp_4", "status": "failed"}},
+		},
+	})
+	or.On("GetByRazorpayID", ctx, "order_rzp_4").Return(order, nil).Once()
+	or.On("MarkFailed", ctx, order.ID).Return(true, nil).Once()
+
+	require.NoError(t, s.HandleRazorpayWebhook(ctx, payload, headers))
+	or.AssertExpectations(t)
+}
+
+func TestPaymentService_VerifyPayment_ReturnsLicenseIssuedByWebhook(t *testing.T) {
+	s, or, _, lr, _, _, _, _ := newPaymentSvc()
+	ctx := context.Background()
+	order := pendingRazorpayOrder("order_rzp_5")
+	order.Status = domain.OrderStatusPaid
+	issued := []domain.License{{OrderID: order.ID, LicenseKey: "LIC-1"}}
+
+	or.On("GetByID", ctx, order.ID).Return(order, nil).Twice()
+	lr.On("ListByOrderID", ctx, order.ID).Return(issued, nil).Once()
+
+	licenses, err := s.VerifyPayment(ctx, order.ID, "pay_rzp_5", s.generateSignature("order_rzp_5", "pay_rzp_5"))
+	require.NoError(t, err)
+	assert.Equal(t, issued, licenses)
+
+	_, err = s.VerifyPayment(ctx, order.ID, "pay_rzp_5", "forged")
+	assert.EqualError(t, err, "order already processed")
+}
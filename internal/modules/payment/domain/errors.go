@@ -3,11 +3,40 @@ package domain
 import "errors"
 
 var (
-	ErrOrderNotFound        = errors.New("order not found")
-	ErrPaymentNotFound      = errors.New("payment not found")
-	ErrLicenseNotFound      = errors.New("license not found")
-	ErrInvalidOrderStatus   = errors.New("invalid order status")
-	ErrInvalidPaymentStatus = errors.New("invalid payment status")
-	ErrLicenseRevoked       = errors.New("license has been revoked")
-	ErrLicenseInactive      = errors.New("license is inactive")
+	ErrOrderNotFound             = errors.New("order not found")
+	ErrPaymentNotFound           = errors.New("payment not found")
+	ErrLicenseNotFound           = errors.New("license not found")
+	ErrInvalidOrderStatus        = errors.New("invalid order status")
+	ErrInvalidPaymentStatus      = errors.New("invalid payment status")
+	ErrLicenseRevoked            = errors.New("license has been revoked")
+	ErrLicenseInactive           = errors.New("license is inactive")
+	ErrRefundNotFound            = errors.New("refund not found")
+	ErrOrderNotRefundable        = errors.New("order is not refundable")
+	ErrOrderAlreadyRefunded      = errors.New("order already refunded")
+	ErrRefundAlreadyRecorded     = errors.New("refund already recorded")
+	ErrOrderAlreadyPaid          = errors.New("order already paid")
+	ErrCartEmpty                 = errors.New("cart is empty")
+	ErrCartFull                  = errors.New("cart is full")
+	ErrCartItemNotFound          = errors.New("cart item not found")
+	ErrCartItemUnavailable       = errors.New("cart item is no longer available")
+	ErrCouponNotFound            = errors.New("coupon not found")
+	ErrCouponInactive            = errors.New("coupon is not active")
+	ErrCouponExpired             = errors.New("coupon has expired")
+	ErrCouponExhausted           = errors.New("coupon has reached its redemption limit")
+	ErrCouponLimitReached        = errors.New("coupon already used the maximum number of times")
+	ErrCouponNotApplicable       = errors.New("coupon does not apply to this order")
+	ErrCouponCodeTaken           = errors.New("coupon code already in use")
+	ErrInvalidCoupon             = errors.New("invalid coupon")
+	ErrLicenseOptionNotFound     = errors.New("license option not found")
+	ErrAgreementTemplateNotFound = errors.New("agreement template not found")
+	ErrInvalidAgreementTemplate  = errors.New("invalid agreement template")
+	// ErrAgreementUnavailable is returned for licenses whose agreement was
+	// neither stored nor snapshotted at issue, so it cannot be reproduced.
+	ErrAgreementUnavailable = errors.New("license agreement is not available")
+	// ErrPartialProviderRefund is returned when the rest of a partially
+	// refunded order cannot be refunded through its provider's API.
+	ErrPartialProviderRefund = errors.New("order was partially refunded; refund the rest from the provider dashboard")
+	// ErrPaymentAmountMismatch is returned when a provider captured a
+	// different amount than the order charges.
+	ErrPaymentAmountMismatch = errors.New("captured amount does not match the order")
 )
@@ -27,6 +27,16 @@ const (
 	PaymentStatusRefunded PaymentStatus = "refunded"
 )
 
+type RefundKind string
+
+const (
+	RefundKindRefund     RefundKind = "refund"
+	RefundKindChargeback RefundKind = "chargeback"
+)
+
+// Order is a checkout of one or more items paid for in a single provider
+// transaction. SpecID and LicenseType mirror the only item of a single-item
+// order and are zero for multi-item orders; Items is authoritative.
 type Order struct {
 	ID                 uuid.UUID      `json:"id" db:"id"`
 	UserID             uuid.UUID      `json:"user_id" db:"user_id"`
@@ -44,6 +54,78 @@ type Order struct {
 	CreatedAt          time.Time      `json:"created_at" db:"created_at"`
 	UpdatedAt          time.Time      `json:"updated_at" db:"updated_at"`
 	ExpiresAt          time.Time      `json:"expires_at" db:"expires_at"`
+	CouponID           *uuid.UUID     `json:"coupon_id,omitempty" db:"coupon_id"`
+	DiscountAmount     int            `json:"discount_amount" db:"discount_amount"`
+	// ExchangeRateSnapshotID is the snapshot of the exchange rates the items
+	// were priced with, nil when the rates never came from a snapshot.
+	ExchangeRateSnapshotID *uuid.UUID  `json:"exchange_rate_snapshot_id,omitempty" db:"exchange_rate_snapshot_id"`
+	Items                  []OrderItem `json:"items" db:"-"`
+}
+
+// OrderItem is one spec and license option bought in an order, priced when
+// the order was placed. Amounts are in the order's currency; Amount is what
+// the buyer paid after DiscountAmount was taken off.
+type OrderItem struct {
+	ID              uuid.UUID `json:"id" db:"id"`
+	OrderID         uuid.UUID `json:"order_id" db:"order_id"`
+	SpecID          uuid.UUID `json:"spec_id" db:"spec_id"`
+	LicenseOptionID uuid.UUID `json:"license_option_id" db:"license_option_id"`
+	LicenseType     string    `json:"license_type" db:"license_type"`
+	LicenseName     string    `json:"license_name" db:"license_name"`
+	SpecTitle       string    `json:"spec_title" db:"spec_title"`
+	Amount          int       `json:"amount" db:"amount"`
+	DiscountAmount  int       `json:"discount_amount" db:"discount_amount"`
+	Currency        string    `json:"currency" db:"currency"`
+	CreatedAt       time.Time `json:"created_at" db:"created_at"`
+}
+
+// CartItem is a license option a user intends to buy. Prices are not stored;
+// they are re-read from the catalog when the cart is shown or checked out.
+type CartItem struct {
+	UserID          uuid.UUID `json:"user_id" db:"user_id"`
+	SpecID          uuid.UUID `json:"spec_id" db:"spec_id"`
+	LicenseOptionID uuid.UUID `json:"license_option_id" db:"license_option_id"`
+	CreatedAt       time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at" db:"updated_at"`
+}
+
+type DiscountType string
+
+const (
+	DiscountTypePercentage DiscountType = "percentage"
+	DiscountTypeFixed      DiscountType = "fixed"
+)
+
+// Coupon is a producer's discount code. It only ever discounts the producer's
+// own specs, optionally narrowed to SpecIDs and LicenseTypes. A fixed discount
+// applies once per order and only to orders in its Currency.
+type Coupon struct {
+	ID             uuid.UUID    `json:"id" db:"id"`
+	ProducerID     uuid.UUID    `json:"producer_id" db:"producer_id"`
+	Code           string       `json:"code" db:"code"`
+	DiscountType   DiscountType `json:"discount_type" db:"discount_type"`
+	PercentOff     *int         `json:"percent_off,omitempty" db:"percent_off"`
+	AmountOff      *int         `json:"amount_off,omitempty" db:"amount_off"`
+	Currency       *string      `json:"currency,omitempty" db:"currency"`
+	SpecIDs        []uuid.UUID  `json:"spec_ids" db:"-"`
+	LicenseTypes   []string     `json:"license_types" db:"-"`
+	MaxRedemptions *int         `json:"max_redemptions,omitempty" db:"max_redemptions"`
+	PerUserLimit   *int         `json:"per_user_limit,omitempty" db:"per_user_limit"`
+	ExpiresAt      *time.Time   `json:"expires_at,omitempty" db:"expires_at"`
+	IsActive       bool         `json:"is_active" db:"is_active"`
+	CreatedAt      time.Time    `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time    `json:"updated_at" db:"updated_at"`
+
+	// Computed fields, filled by CouponRepository.ListByProducer
+	Redemptions   int `json:"redemptions" db:"redemptions"`
+	DiscountGiven int `json:"discount_given" db:"discount_given"`
+}
+
+// CouponRedemptions counts the orders holding a coupon: Total includes checkouts
+// still awaiting payment, ByUser counts only the buyer's paid orders.
+type CouponRedemptions struct {
+	Total  int `db:"total"`
+	ByUser int `db:"by_user"`
 }
 
 type OrderWithBuyer struct {
@@ -72,6 +154,7 @@ type Payment struct {
 	ErrorCode         *string       `json:"error_code,omitempty" db:"error_code"`
 	ErrorDescription  *string       `json:"error_description,omitempty" db:"error_description"`
 	CapturedAt        *time.Time    `json:"captured_at,omitempty" db:"captured_at"`
+	ProviderFee       int           `json:"provider_fee" db:"provider_fee"`
 	CreatedAt         time.Time     `json:"created_at" db:"created_at"`
 	UpdatedAt         time.Time     `json:"updated_at" db:"updated_at"`
 }
@@ -95,23 +178,102 @@ type License struct {
 	IssuedAt         time.Time  `json:"issued_at" db:"issued_at"`
 	CreatedAt        time.Time  `json:"created_at" db:"created_at"`
 	UpdatedAt        time.Time  `json:"updated_at" db:"updated_at"`
+	// AgreementKey is the storage key of the agreement PDF issued with the
+	// license. It is nil until the agreement has been stored.
+	AgreementKey *string `json:"-" db:"agreement_key"`
+	// The agreement template the license was sold under, copied at issue.
+	// AgreementTerms is nil for licenses issued before the copy was kept.
+	AgreementStreamLimit       *int    `json:"-" db:"agreement_stream_limit"`
+	AgreementDistributionLimit *int    `json:"-" db:"agreement_distribution_limit"`
+	AgreementCreditRequired    bool    `json:"-" db:"agreement_credit_required"`
+	AgreementTerms             *string `json:"-" db:"agreement_terms"`
 
 	// Joined fields
 	SpecTitle string  `json:"spec_title" db:"spec_title"`
 	SpecImage *string `json:"spec_image" db:"spec_image"`
 }
 
+// AgreementTemplate sets what a license option permits and the terms printed
+// on the agreement issued with each license. A nil limit means unlimited.
+// Terms may contain placeholders such as {{buyer_name}} that are filled in
+// when the agreement is rendered.
+type AgreementTemplate struct {
+	LicenseOptionID   uuid.UUID `json:"license_option_id" db:"license_option_id"`
+	StreamLimit       *int      `json:"stream_limit" db:"stream_limit"`
+	DistributionLimit *int      `json:"distribution_limit" db:"distribution_limit"`
+	CreditRequired    bool      `json:"credit_required" db:"credit_required"`
+	Terms             string    `json:"terms" db:"terms"`
+	// IsCustom is false when the option uses the defaults for its license
+	// type, which have no timestamps.
+	IsCustom  bool       `json:"is_custom" db:"-"`
+	CreatedAt *time.Time `json:"created_at,omitempty" db:"created_at"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty" db:"updated_at"`
+}
+
+// Refund records money returned to the buyer, either through a provider
+// refund or a lost dispute. An order may be refunded in parts; it is refunded
+// once its refunds add up to the order amount, or on a chargeback.
+type Refund struct {
+	ID               uuid.UUID  `json:"id" db:"id"`
+	OrderID          uuid.UUID  `json:"order_id" db:"order_id"`
+	PaymentID        *uuid.UUID `json:"payment_id,omitempty" db:"payment_id"`
+	Provider         string     `json:"provider" db:"provider"`
+	ProviderRefundID *string    `json:"provider_refund_id,omitempty" db:"provider_refund_id"`
+	Kind             RefundKind `json:"kind" db:"kind"`
+	Amount           int        `json:"amount" db:"amount"`
+	Currency         string     `json:"currency" db:"currency"`
+	Reason           *string    `json:"reason,omitempty" db:"reason"`
+	InitiatedBy      *uuid.UUID `json:"initiated_by,omitempty" db:"initiated_by"`
+	CreatedAt        time.Time  `json:"created_at" db:"created_at"`
+}
+
+// RefundAudit carries the request details written to admin_audit_logs with a
+// refund. Webhook-driven refunds leave it empty.
+type RefundAudit struct {
+	ActorID   *uuid.UUID
+	IPAddress string
+	UserAgent string
+}
+
 // Repositories
 
 type OrderRepository interface {
+	// Create stores the order together with its items. An order with a coupon
+	// is only stored while the coupon is still within its redemption limits;
+	// otherwise Create returns ErrCouponExhausted or ErrCouponLimitReached.
 	Create(ctx context.Context, order *Order) error
+	// GetByID returns ErrOrderNotFound when no order has the id.
 	GetByID(ctx context.Context, id uuid.UUID) (*Order, error)
 	GetByRazorpayID(ctx context.Context, razorpayOrderID string) (*Order, error)
 	UpdateStatus(ctx context.Context, id uuid.UUID, status OrderStatus) error
+	// MarkPaid locks the order and, while it is still awaiting payment, records
+	// the captured payment, marks the order paid, creates one license per item
+	// and removes the bought items from the buyer's cart in one transaction.
+	// For an order that is already paid it returns the licenses issued earlier
+	// together with ErrOrderAlreadyPaid.
+	MarkPaid(ctx context.Context, payment *Payment, licenses []License) ([]License, error)
+	// MarkFailed fails the order only if it is still awaiting payment and
+	// reports whether it did.
+	MarkFailed(ctx context.Context, id uuid.UUID) (bool, error)
+	// FailExpired fails every order still awaiting payment whose checkout
+	// window closed before expiredBefore and returns how many it failed.
+	FailExpired(ctx context.Context, expiredBefore time.Time) (int64, error)
 	ListByUser(ctx context.Context, userID uuid.UUID, limit, offset int) ([]Order, error)
 	ListByProducer(ctx context.Context, producerID uuid.UUID, limit, offset int) ([]OrderWithBuyer, int, error)
 }
 
+type CouponRepository interface {
+	// Create stores a new coupon, returning ErrCouponCodeTaken if the code is
+	// already in use.
+	Create(ctx context.Context, coupon *Coupon) error
+	GetByID(ctx context.Context, id uuid.UUID) (*Coupon, error)
+	GetByCode(ctx context.Context, code string) (*Coupon, error)
+	ListByProducer(ctx context.Context, producerID uuid.UUID) ([]Coupon, error)
+	Update(ctx context.Context, coupon *Coupon) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	CountRedemptions(ctx context.Context, couponID, userID uuid.UUID) (CouponRedemptions, error)
+}
+
 type PaymentRepository interface {
 	Create(ctx context.Context, payment *Payment) error
 	GetByID(ctx context.Context, id uuid.UUID) (*Payment, error)
@@ -121,9 +283,52 @@ type PaymentRepository interface {
 
 type LicenseRepository interface {
 	Create(ctx context.Context, license *License) error
+	// GetByID returns ErrLicenseNotFound when no license has the id.
 	GetByID(ctx context.Context, id uuid.UUID) (*License, error)
-	GetByOrderID(ctx context.Context, orderID uuid.UUID) (*License, error)
+	// GetByKey returns ErrLicenseNotFound when no license has the key.
+	GetByKey(ctx context.Context, licenseKey string) (*License, error)
+	ListByOrderID(ctx context.Context, orderID uuid.UUID) ([]License, error)
 	ListByUser(ctx context.Context, userID uuid.UUID, limit, offset int, search, licenseType string) ([]License, int, error)
 	IncrementDownloads(ctx context.Context, id uuid.UUID) error
 	Revoke(ctx context.Context, id uuid.UUID, reason string) error
+	SetAgreementKey(ctx context.Context, id uuid.UUID, key string) error
+}
+
+// AgreementTemplateRepository stores producer-customised agreement templates.
+type AgreementTemplateRepository interface {
+	// GetByLicenseOptionID returns ErrAgreementTemplateNotFound when the option
+	// has no customised template.
+	GetByLicenseOptionID(ctx context.Context, licenseOptionID uuid.UUID) (*AgreementTemplate, error)
+	Upsert(ctx context.Context, template *AgreementTemplate) error
+}
+
+type RefundRepository interface {
+	// Apply records the refund and writes the audit entry. When the refund is
+	// a chargeback or brings the order's refunded total up to its amount, it
+	// also, in the same transaction, marks the order and payment refunded,
+	// revokes the order's licenses and recounts purchases of its specs, and
+	// reports true. A provider refund already on record returns
+	// ErrRefundAlreadyRecorded.
+	Apply(ctx context.Context, refund *Refund, audit RefundAudit) (bool, error)
+	// GetByOrderID returns the order's latest refund.
+	GetByOrderID(ctx context.Context, orderID uuid.UUID) (*Refund, error)
+	// RefundedAmount returns the total refunded on the order so far.
+	RefundedAmount(ctx context.Context, orderID uuid.UUID) (int, error)
+}
+
+// CartRepository stores one cart per user, keyed by spec.
+type CartRepository interface {
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]CartItem, error)
+	// Upsert adds the item or replaces the license option already chosen for
+	// the same spec.
+	Upsert(ctx context.Context, item *CartItem) error
+	Remove(ctx context.Context, userID, specID uuid.UUID) error
+	Clear(ctx context.Context, userID uuid.UUID) error
+}
+
+// WebhookEventRepository remembers provider webhook deliveries that were
+// handled successfully, so redeliveries are acknowledged without side effects.
+type WebhookEventRepository interface {
+	IsProcessed(ctx context.Context, provider, eventID string) (bool, error)
+	MarkProcessed(ctx context.Context, provider, eventID, eventType string) error
 }
@@ -0,0 +1,63 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/saransh1220/blueprint-audio/internal/modules/payment/domain"
+)
+
+type PgAgreementTemplateRepository struct {
+	db *sqlx.DB
+}
+
+func NewAgreementTemplateRepository(db *sqlx.DB) domain.AgreementTemplateRepository {
+	return &PgAgreementTemplateRepository{db: db}
+}
+
+func (r *PgAgreementTemplateRepository) GetByLicenseOptionID(ctx context.Context, licenseOptionID uuid.UUID) (*domain.AgreementTemplate, error) {
+	template := &domain.AgreementTemplate{}
+	query := `SELECT * FROM license_agreement_templates WHERE license_option_id = $1`
+	if err := r.db.GetContext(ctx, template, query, licenseOptionID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrAgreementTemplateNotFound
+		}
+		return nil, err
+	}
+	template.IsCustom = true
+	return template, nil
+}
+
+func (r *PgAgreementTemplateRepository) Upsert(ctx context.Context, template *domain.AgreementTemplate) error {
+	now := time.Now()
+	template.UpdatedAt = &now
+	query := `
+		INSERT INTO license_agreement_templates (
+			license_option_id, stream_limit, distribution_limit, credit_required, terms, created_at, updated_at
+		) VALUES (
+			:license_option_id, :stream_limit, :distribution_limit, :credit_required, :terms, :updated_at, :updated_at
+		)
+		ON CONFLICT (license_option_id) DO UPDATE SET
+			stream_limit = EXCLUDED.stream_limit,
+			distribution_limit = EXCLUDED.distribution_limit,
+			credit_required = EXCLUDED.credit_required,
+			terms = EXCLUDED.terms,
+			updated_at = EXCLUDED.updated_at
+		RETURNING created_at`
+	rows, err := r.db.NamedQueryContext(ctx, query, template)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	if rows.Next() {
+		if err := rows.Scan(&template.CreatedAt); err != nil {
+			return err
+		}
+	}
+	template.IsCustom = true
+	return rows.Err()
+}
@@ -0,0 +1,62 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/saransh1220/blueprint-audio/internal/modules/payment/domain"
+)
+
+type PgCartRepository struct {
+	db *sqlx.DB
+}
+
+func NewCartRepository(db *sqlx.DB) domain.CartRepository {
+	return &PgCartRepository{db: db}
+}
+
+func (r *PgCartRepository) ListByUser(ctx context.Context, userID uuid.UUID) ([]domain.CartItem, error) {
+	items := []domain.CartItem{}
+	query := `SELECT * FROM cart_items WHERE user_id = $1 ORDER BY created_at, spec_id`
+	err := r.db.SelectContext(ctx, &items, query, userID)
+	return items, err
+}
+
+func (r *PgCartRepository) Upsert(ctx context.Context, item *domain.CartItem) error {
+	now := time.Now()
+	if item.CreatedAt.IsZero() {
+		item.CreatedAt = now
+	}
+	item.UpdatedAt = now
+
+	query := `
+		INSERT INTO cart_items (user_id, spec_id, license_option_id, created_at, updated_at)
+		VALUES (:user_id, :spec_id, :license_option_id, :created_at, :updated_at)
+		ON CONFLICT (user_id, spec_id) DO UPDATE
+		SET license_option_id = EXCLUDED.license_option_id,
+		    updated_at = EXCLUDED.updated_at`
+	_, err := r.db.NamedExecContext(ctx, query, item)
+	return err
+}
+
+func (r *PgCartRepository) Remove(ctx context.Context, userID, specID uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM cart_items WHERE user_id = $1 AND spec_id = $2`, userID, specID)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return domain.ErrCartItemNotFound
+	}
+	return nil
+}
+
+func (r *PgCartRepository) Clear(ctx context.Context, userID uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM cart_items WHERE user_id = $1`, userID)
+	return err
+}
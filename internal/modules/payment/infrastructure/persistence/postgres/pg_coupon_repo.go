@@ -0,0 +1,235 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/saransh1220/blueprint-audio/internal/modules/payment/domain"
+)
+
+// couponRedemptionsQuery counts the orders holding coupon $1, overall and for
+// buyer $2. Checkouts still awaiting payment hold their redemption until they
+// expire, so concurrent checkouts cannot overshoot a limit.
+const couponRedemptionsQuery = `
+	SELECT
+	    COUNT(*) AS total,
+	    COUNT(*) FILTER (WHERE user_id = $2) AS by_user
+	FROM orders
+	WHERE coupon_id = $1
+	  AND (status = 'paid' OR (status IN ('pending', 'processing') AND expires_at > NOW()))`
+
+type PgCouponRepository struct {
+	db *sqlx.DB
+}
+
+func NewCouponRepository(db *sqlx.DB) domain.CouponRepository {
+	return &PgCouponRepository{db: db}
+}
+
+// couponRow scans a coupons row. The outer fields shadow Coupon.SpecIDs and
+// Coupon.LicenseTypes so the array columns can be decoded after scanning.
+type couponRow struct {
+	domain.Coupon
+	SpecIDs      pq.StringArray `db:"spec_ids"`
+	LicenseTypes pq.StringArray `db:"license_types"`
+}
+
+func (row *couponRow) toCoupon() (*domain.Coupon, error) {
+	coupon := row.Coupon
+	coupon.SpecIDs = make([]uuid.UUID, 0, len(row.SpecIDs))
+	for _, raw := range row.SpecIDs {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			return nil, err
+		}
+		coupon.SpecIDs = append(coupon.SpecIDs, id)
+	}
+	coupon.LicenseTypes = append([]string{}, row.LicenseTypes...)
+	return &coupon, nil
+}
+
+func specIDArray(ids []uuid.UUID) pq.StringArray {
+	array := make(pq.StringArray, len(ids))
+	for i, id := range ids {
+		array[i] = id.String()
+	}
+	return array
+}
+
+func licenseTypeArray(types []string) pq.StringArray {
+	return append(pq.StringArray{}, types...)
+}
+
+func (r *PgCouponRepository) Create(ctx context.Context, coupon *domain.Coupon) error {
+	if coupon.ID == uuid.Nil {
+		id, err := uuid.NewV7()
+		if err != nil {
+			return err
+		}
+		coupon.ID = id
+	}
+	now := time.Now()
+	coupon.CreatedAt, coupon.UpdatedAt = now, now
+
+	query := `
+		INSERT INTO coupons (
+			id, producer_id, code, discount_type, percent_off, amount_off, currency,
+			spec_ids, license_types, max_redemptions, per_user_limit, expires_at,
+			is_active, created_at, updated_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8::uuid[], $9, $10, $11, $12, $13, $14, $15
+		)`
+	_, err := r.db.ExecContext(ctx, query,
+		coupon.ID, coupon.ProducerID, coupon.Code, coupon.DiscountType,
+		coupon.PercentOff, coupon.AmountOff, coupon.Currency,
+		specIDArray(coupon.SpecIDs), licenseTypeArray(coupon.LicenseTypes),
+		coupon.MaxRedemptions, coupon.PerUserLimit, coupon.ExpiresAt,
+		coupon.IsActive, coupon.CreatedAt, coupon.UpdatedAt,
+	)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" { // Unique violation
+			return domain.ErrCouponCodeTaken
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *PgCouponRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Coupon, error) {
+	return r.get(ctx, `SELECT * FROM coupons WHERE id = $1`, id)
+}
+
+func (r *PgCouponRepository) GetByCode(ctx context.Context, code string) (*domain.Coupon, error) {
+	return r.get(ctx, `SELECT * FROM coupons WHERE code = $1`, code)
+}
+
+func (r *PgCouponRepository) get(ctx context.Context, query string, arg any) (*domain.Coupon, error) {
+	var row couponRow
+	if err := r.db.GetContext(ctx, &row, query, arg); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrCouponNotFound
+		}
+		return nil, err
+	}
+	return row.toCoupon()
+}
+
+// ListByProducer returns the producer's coupons, newest first, with the number
+// of paid orders that used each one and the discount they were given.
+func (r *PgCouponRepository) ListByProducer(ctx context.Context, producerID uuid.UUID) ([]domain.Coupon, error) {
+	var rows []couponRow
+	query := `
+		SELECT c.*,
+		       COUNT(o.id) AS redemptions,
+		       COALESCE(SUM(o.discount_amount), 0) AS discount_given
+		FROM coupons c
+		LEFT JOIN orders o ON o.coupon_id = c.id AND o.status = 'paid'
+		WHERE c.producer_id = $1
+		GROUP BY c.id
+		ORDER BY c.created_at DESC, c.id`
+	if err := r.db.SelectContext(ctx, &rows, query, producerID); err != nil {
+		return nil, err
+	}
+
+	coupons := make([]domain.Coupon, 0, len(rows))
+	for i := range rows {
+		coupon, err := rows[i].toCoupon()
+		if err != nil {
+			return nil, err
+		}
+		coupons = append(coupons, *coupon)
+	}
+	return coupons, nil
+}
+
+// Update saves the coupon's limits and restrictions. The code and the discount
+// itself are fixed once created so past orders keep describing what they got.
+func (r *PgCouponRepository) Update(ctx context.Context, coupon *domain.Coupon) error {
+	coupon.UpdatedAt = time.Now()
+	query := `
+		UPDATE coupons
+		SET spec_ids = $1::uuid[],
+		    license_types = $2,
+		    max_redemptions = $3,
+		    per_user_limit = $4,
+		    expires_at = $5,
+		    is_active = $6,
+		    updated_at = $7
+		WHERE id = $8`
+	result, err := r.db.ExecContext(ctx, query,
+		specIDArray(coupon.SpecIDs), licenseTypeArray(coupon.LicenseTypes),
+		coupon.MaxRedemptions, coupon.PerUserLimit, coupon.ExpiresAt,
+		coupon.IsActive, coupon.UpdatedAt, coupon.ID,
+	)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return domain.ErrCouponNotFound
+	}
+	return nil
+}
+
+// Delete removes the coupon. Orders that used it keep their discount and the
+// code in their notes; only the link to the coupon is cleared.
+func (r *PgCouponRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM coupons WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return domain.ErrCouponNotFound
+	}
+	return nil
+}
+
+func (r *PgCouponRepository) CountRedemptions(ctx context.Context, couponID, userID uuid.UUID) (domain.CouponRedemptions, error) {
+	var counts domain.CouponRedemptions
+	err := r.db.GetContext(ctx, &counts, couponRedemptionsQuery, couponID, userID)
+	return counts, err
+}
+
+// reserveCoupon locks the coupon row for the rest of tx and checks the order
+// still fits within the coupon's limits. Concurrent checkouts with the same
+// coupon queue on the lock, so each one counts the orders stored before it.
+func reserveCoupon(ctx context.Context, tx *sqlx.Tx, couponID, userID uuid.UUID) error {
+	var limits struct {
+		MaxRedemptions *int `db:"max_redemptions"`
+		PerUserLimit   *int `db:"per_user_limit"`
+	}
+	if err := tx.GetContext(ctx, &limits, `SELECT max_redemptions, per_user_limit FROM coupons WHERE id = $1 FOR UPDATE`, couponID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.ErrCouponNotFound
+		}
+		return err
+	}
+	if limits.MaxRedemptions == nil && limits.PerUserLimit == nil {
+		return nil
+	}
+
+	var counts domain.CouponRedemptions
+	if err := tx.GetContext(ctx, &counts, couponRedemptionsQuery, couponID, userID); err != nil {
+		return err
+	}
+	if limits.MaxRedemptions != nil && counts.Total >= *limits.MaxRedemptions {
+		return domain.ErrCouponExhausted
+	}
+	if limits.PerUserLimit != nil && counts.ByUser >= *limits.PerUserLimit {
+		return domain.ErrCouponLimitReached
+	}
+	return nil
+}
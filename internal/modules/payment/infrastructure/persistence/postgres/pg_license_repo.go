@@ -2,6 +2,8 @@ package postgres
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"strconv"
 	"time"
 
@@ -10,6 +12,23 @@ import (
 	"github.com/saransh1220/blueprint-audio/internal/modules/payment/domain"
 )
 
+const insertLicenseQuery = `
+	INSERT INTO licenses (
+		id, order_id, user_id, spec_id, license_option_id,
+		license_type, purchase_price, currency, license_key,
+		is_active, is_revoked, downloads_count,
+		issued_at, created_at, updated_at,
+		agreement_stream_limit, agreement_distribution_limit,
+		agreement_credit_required, agreement_terms
+	) VALUES (
+		:id, :order_id, :user_id, :spec_id, :license_option_id,
+		:license_type, :purchase_price, :currency, :license_key,
+		:is_active, :is_revoked, :downloads_count,
+		:issued_at, :created_at, :updated_at,
+		:agreement_stream_limit, :agreement_distribution_limit,
+		:agreement_credit_required, :agreement_terms
+	)`
+
 type PgLicenseRepository struct {
 	db *sqlx.DB
 }
@@ -32,35 +51,39 @@ func (r *PgLicenseRepository) Create(ctx context.Context, license *domain.Licens
 	license.UpdatedAt = time.Now()
 	license.IssuedAt = time.Now()
 
-	query := `
-		INSERT INTO licenses (
-			id, order_id, user_id, spec_id, license_option_id,
-			license_type, purchase_price, currency, license_key,
-			is_active, is_revoked, downloads_count,
-			issued_at, created_at, updated_at
-		) VALUES (
-			:id, :order_id, :user_id, :spec_id, :license_option_id,
-			:license_type, :purchase_price, :currency, :license_key,
-			:is_active, :is_revoked, :downloads_count,
-			:issued_at, :created_at, :updated_at
-		)`
-
-	_, err := r.db.NamedExecContext(ctx, query, license)
+	_, err := r.db.NamedExecContext(ctx, insertLicenseQuery, license)
 	return err
 }
 
 func (r *PgLicenseRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.License, error) {
 	license := &domain.License{}
 	query := `SELECT * FROM licenses WHERE id = $1`
-	err := r.db.GetContext(ctx, license, query, id)
-	return license, err
+	if err := r.db.GetContext(ctx, license, query, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrLicenseNotFound
+		}
+		return nil, err
+	}
+	return license, nil
 }
 
-func (r *PgLicenseRepository) GetByOrderID(ctx context.Context, orderID uuid.UUID) (*domain.License, error) {
+func (r *PgLicenseRepository) GetByKey(ctx context.Context, licenseKey string) (*domain.License, error) {
 	license := &domain.License{}
-	query := `SELECT * FROM licenses WHERE order_id = $1`
-	err := r.db.GetContext(ctx, license, query, orderID)
-	return license, err
+	query := `SELECT * FROM licenses WHERE license_key = $1`
+	if err := r.db.GetContext(ctx, license, query, licenseKey); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrLicenseNotFound
+		}
+		return nil, err
+	}
+	return license, nil
+}
+
+func (r *PgLicenseRepository) ListByOrderID(ctx context.Context, orderID uuid.UUID) ([]domain.License, error) {
+	var licenses []domain.License
+	query := `SELECT * FROM licenses WHERE order_id = $1 ORDER BY issued_at, id`
+	err := r.db.SelectContext(ctx, &licenses, query, orderID)
+	return licenses, err
 }
 
 func (r *PgLicenseRepository) ListByUser(ctx context.Context, userID uuid.UUID, limit, offset int, search, licenseType string) ([]domain.License, int, error) {
@@ -132,3 +155,9 @@ func (r *PgLicenseRepository) Revoke(ctx context.Context, id uuid.UUID, reason s
 	_, err := r.db.ExecContext(ctx, query, reason, id)
 	return err
 }
+
+func (r *PgLicenseRepository) SetAgreementKey(ctx context.Context, id uuid.UUID, key string) error {
+	query := `UPDATE licenses SET agreement_key = $1, updated_at = NOW() WHERE id = $2`
+	_, err := r.db.ExecContext(ctx, query, key, id)
+	return err
+}
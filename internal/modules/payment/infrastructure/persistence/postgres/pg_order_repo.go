@@ -4,10 +4,12 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/saransh1220/blueprint-audio/internal/modules/payment/domain"
 )
 
@@ -19,6 +21,42 @@ func NewOrderRepository(db *sqlx.DB) domain.OrderRepository {
 	return &PgOrderRepository{db: db}
 }
 
+// recountOrderPurchasesQuery refreshes total_purchase_count for every spec in
+// order $1. Counting paid line items keeps the stored total honest even if an
+// earlier update was missed.
+const recountOrderPurchasesQuery = `
+	INSERT INTO spec_analytics (spec_id, total_purchase_count)
+	SELECT oi.spec_id, (
+	        SELECT COUNT(*)
+	        FROM order_items paid
+	        JOIN orders o ON o.id = paid.order_id
+	        WHERE paid.spec_id = oi.spec_id AND o.status = 'paid'
+	    )
+	FROM order_items oi
+	WHERE oi.order_id = $1
+	ON CONFLICT (spec_id) DO UPDATE
+	SET total_purchase_count = EXCLUDED.total_purchase_count,
+	    updated_at = NOW()`
+
+// orderRow scans an orders row. The outer fields shadow Order.LicenseType and
+// Order.Notes so the nullable and JSONB columns can be decoded after scanning.
+type orderRow struct {
+	domain.Order
+	LicenseType sql.NullString `db:"license_type"`
+	NotesJSON   []byte         `db:"notes"`
+}
+
+func (row *orderRow) toOrder() (*domain.Order, error) {
+	order := row.Order
+	order.LicenseType = row.LicenseType.String
+	if len(row.NotesJSON) > 0 {
+		if err := json.Unmarshal(row.NotesJSON, &order.Notes); err != nil {
+			return nil, err
+		}
+	}
+	return &order, nil
+}
+
 func (r *PgOrderRepository) Create(ctx context.Context, order *domain.Order) error {
 
 	if order.ID == uuid.Nil {
@@ -39,20 +77,33 @@ func (r *PgOrderRepository) Create(ctx context.Context, order *domain.Order) err
 		return err
 	}
 
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if order.CouponID != nil {
+		if err := reserveCoupon(ctx, tx, *order.CouponID, order.UserID); err != nil {
+			return err
+		}
+	}
+
 	query := `
 		INSERT INTO orders (
 			id, user_id, spec_id, license_type, amount, currency,
 			razorpay_order_id, provider, provider_checkout_id, provider_payment_id,
-			status, notes, created_at, updated_at, expires_at
+			status, notes, created_at, updated_at, expires_at,
+			coupon_id, discount_amount, exchange_rate_snapshot_id
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18
 		)`
 
-	_, err = r.db.ExecContext(ctx, query,
+	_, err = tx.ExecContext(ctx, query,
 		order.ID,
 		order.UserID,
-		order.SpecID,
-		order.LicenseType,
+		nullUUID(order.SpecID),
+		nullIfEmpty(order.LicenseType),
 		order.Amount,
 		order.Currency,
 		order.RazorpayOrderID,
@@ -64,18 +115,50 @@ func (r *PgOrderRepository) Create(ctx context.Context, order *domain.Order) err
 		order.CreatedAt,
 		order.UpdatedAt,
 		order.ExpiresAt,
+		order.CouponID,
+		order.DiscountAmount,
+		order.ExchangeRateSnapshotID,
 	)
-	return err
+	if err != nil {
+		return err
+	}
+
+	for i := range order.Items {
+		item := &order.Items[i]
+		if item.ID == uuid.Nil {
+			id, err := uuid.NewV7()
+			if err != nil {
+				return err
+			}
+			item.ID = id
+		}
+		item.OrderID = order.ID
+		item.CreatedAt = order.CreatedAt
+		if _, err := tx.NamedExecContext(ctx, `
+			INSERT INTO order_items (
+				id, order_id, spec_id, license_option_id, license_type,
+				license_name, spec_title, amount, discount_amount, currency, created_at
+			) VALUES (
+				:id, :order_id, :spec_id, :license_option_id, :license_type,
+				:license_name, :spec_title, :amount, :discount_amount, :currency, :created_at
+			)`, item); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
 }
 
 func (r *PgOrderRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Order, error) {
 	order := &domain.Order{}
+	var licenseType sql.NullString
 	var notesJSON []byte
 
 	query := `
 		SELECT id, user_id, spec_id, license_type, amount, currency,
 		       razorpay_order_id, provider, provider_checkout_id, provider_payment_id,
-		       status, notes, created_at, updated_at, expires_at
+		       status, notes, created_at, updated_at, expires_at,
+		       coupon_id, discount_amount, exchange_rate_snapshot_id
 		FROM orders 
 		WHERE id = $1
 	`
@@ -84,7 +167,7 @@ func (r *PgOrderRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.
 		&order.ID,
 		&order.UserID,
 		&order.SpecID,
-		&order.LicenseType,
+		&licenseType,
 		&order.Amount,
 		&order.Currency,
 		&order.RazorpayOrderID,
@@ -96,14 +179,18 @@ func (r *PgOrderRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.
 		&order.CreatedAt,
 		&order.UpdatedAt,
 		&order.ExpiresAt,
+		&order.CouponID,
+		&order.DiscountAmount,
+		&order.ExchangeRateSnapshotID,
 	)
 
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, err
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrOrderNotFound
 		}
 		return nil, err
 	}
+	order.LicenseType = licenseType.String
 
 	// Unmarshal JSONB notes
 	if len(notesJSON) > 0 {
@@ -112,14 +199,52 @@ func (r *PgOrderRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.
 		}
 	}
 
+	if err := r.loadItems(ctx, order); err != nil {
+		return nil, err
+	}
 	return order, nil
 }
 
 func (r *PgOrderRepository) GetByRazorpayID(ctx context.Context, razorpayOrderID string) (*domain.Order, error) {
-	order := &domain.Order{}
+	var row orderRow
 	query := `SELECT * FROM orders WHERE razorpay_order_id = $1`
-	err := r.db.GetContext(ctx, order, query, razorpayOrderID)
-	return order, err
+	if err := r.db.GetContext(ctx, &row, query, razorpayOrderID); err != nil {
+		return nil, err
+	}
+	order, err := row.toOrder()
+	if err != nil {
+		return nil, err
+	}
+	if err := r.loadItems(ctx, order); err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// loadItems attaches the line items of each order with a single query.
+func (r *PgOrderRepository) loadItems(ctx context.Context, orders ...*domain.Order) error {
+	if len(orders) == 0 {
+		return nil
+	}
+	ids := make([]string, len(orders))
+	byID := make(map[uuid.UUID]*domain.Order, len(orders))
+	for i, order := range orders {
+		ids[i] = order.ID.String()
+		byID[order.ID] = order
+		order.Items = []domain.OrderItem{}
+	}
+
+	var items []domain.OrderItem
+	query := `SELECT * FROM order_items WHERE order_id = ANY($1::uuid[]) ORDER BY created_at, id`
+	if err := r.db.SelectContext(ctx, &items, query, pq.Array(ids)); err != nil {
+		return err
+	}
+	for _, item := range items {
+		if order := byID[item.OrderID]; order != nil {
+			order.Items = append(order.Items, item)
+		}
+	}
+	return nil
 }
 
 func (r *PgOrderRepository) UpdateStatus(ctx context.Context, id uuid.UUID, status domain.OrderStatus) error {
@@ -128,13 +253,149 @@ func (r *PgOrderRepository) UpdateStatus(ctx context.Context, id uuid.UUID, stat
 	return err
 }
 
+// MarkPaid serialises the browser verification and provider webhooks on the
+// order row lock, so whichever arrives second sees a paid order and gets the
+// existing licenses back instead of issuing more.
+func (r *PgOrderRepository) MarkPaid(ctx context.Context, payment *domain.Payment, licenses []domain.License) ([]domain.License, error) {
+	now := time.Now()
+	if payment.ID == uuid.Nil {
+		id, err := uuid.NewV7()
+		if err != nil {
+			return nil, err
+		}
+		payment.ID = id
+	}
+	for i := range licenses {
+		if licenses[i].ID == uuid.Nil {
+			id, err := uuid.NewV7()
+			if err != nil {
+				return nil, err
+			}
+			licenses[i].ID = id
+		}
+		licenses[i].IssuedAt, licenses[i].CreatedAt, licenses[i].UpdatedAt = now, now, now
+	}
+	payment.CreatedAt, payment.UpdatedAt = now, now
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var order struct {
+		Status domain.OrderStatus `db:"status"`
+		UserID uuid.UUID          `db:"user_id"`
+	}
+	if err := tx.GetContext(ctx, &order, `SELECT status, user_id FROM orders WHERE id = $1 FOR UPDATE`, payment.OrderID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrOrderNotFound
+		}
+		return nil, err
+	}
+	switch order.Status {
+	case domain.OrderStatusPending, domain.OrderStatusProcessing, domain.OrderStatusFailed:
+	case domain.OrderStatusPaid:
+		var existing []domain.License
+		if err := tx.SelectContext(ctx, &existing, `SELECT * FROM licenses WHERE order_id = $1 ORDER BY issued_at, id`, payment.OrderID); err != nil {
+			return nil, err
+		}
+		return existing, domain.ErrOrderAlreadyPaid
+	default:
+		return nil, domain.ErrInvalidOrderStatus
+	}
+
+	if _, err := tx.NamedExecContext(ctx, insertPaymentQuery+`
+		ON CONFLICT (razorpay_payment_id) DO UPDATE
+		SET status = EXCLUDED.status,
+		    captured_at = EXCLUDED.captured_at,
+		    provider_fee = EXCLUDED.provider_fee,
+		    updated_at = EXCLUDED.updated_at`, payment); err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE orders SET status = $1, provider_payment_id = $2, updated_at = NOW() WHERE id = $3`,
+		domain.OrderStatusPaid, payment.RazorpayPaymentID, payment.OrderID,
+	); err != nil {
+		return nil, err
+	}
+
+	for i := range licenses {
+		if _, err := tx.NamedExecContext(ctx, insertLicenseQuery, &licenses[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, recountOrderPurchasesQuery, payment.OrderID); err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		DELETE FROM cart_items ci
+		USING order_items oi
+		WHERE oi.order_id = $1 AND ci.user_id = $2 AND ci.spec_id = oi.spec_id`,
+		payment.OrderID, order.UserID,
+	); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return licenses, nil
+}
+
+func (r *PgOrderRepository) MarkFailed(ctx context.Context, id uuid.UUID) (bool, error) {
+	query := `
+		UPDATE orders
+		SET status = $1, updated_at = NOW()
+		WHERE id = $2 AND status IN ('pending', 'processing')`
+	result, err := r.db.ExecContext(ctx, query, domain.OrderStatusFailed, id)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+func (r *PgOrderRepository) FailExpired(ctx context.Context, expiredBefore time.Time) (int64, error) {
+	query := `
+		UPDATE orders
+		SET status = $1, updated_at = NOW()
+		WHERE status IN ('pending', 'processing') AND expires_at < $2`
+	result, err := r.db.ExecContext(ctx, query, domain.OrderStatusFailed, expiredBefore)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 func (r *PgOrderRepository) ListByUser(ctx context.Context, userID uuid.UUID, limit, offset int) ([]domain.Order, error) {
-	var orders []domain.Order
+	var rows []orderRow
 	query := `SELECT * FROM orders WHERE user_id = $1 ORDER BY created_at DESC LIMIT $2 OFFSET $3`
-	err := r.db.SelectContext(ctx, &orders, query, userID, limit, offset)
-	return orders, err
+	if err := r.db.SelectContext(ctx, &rows, query, userID, limit, offset); err != nil {
+		return nil, err
+	}
+
+	orders := make([]domain.Order, len(rows))
+	refs := make([]*domain.Order, len(rows))
+	for i := range rows {
+		order, err := rows[i].toOrder()
+		if err != nil {
+			return nil, err
+		}
+		orders[i] = *order
+		refs[i] = &orders[i]
+	}
+	if err := r.loadItems(ctx, refs...); err != nil {
+		return nil, err
+	}
+	return orders, nil
 }
 
+// ListByProducer returns one row per line item of the producer's specs, so a
+// cart order shows only the producer's share of it.
 func (r *PgOrderRepository) ListByProducer(ctx context.Context, producerID uuid.UUID, limit, offset int) ([]domain.OrderWithBuyer, int, error) {
 	var orders []domain.OrderWithBuyer
 
@@ -142,8 +403,8 @@ func (r *PgOrderRepository) ListByProducer(ctx context.Context, producerID uuid.
 	var total int
 	countQuery := `
 		SELECT COUNT(*)
-		FROM orders o
-		JOIN specs s ON o.spec_id = s.id
+		FROM order_items oi
+		JOIN specs s ON oi.spec_id = s.id
 		WHERE s.producer_id = $1
 	`
 	err := r.db.GetContext(ctx, &total, countQuery, producerID)
@@ -156,10 +417,10 @@ func (r *PgOrderRepository) ListByProducer(ctx context.Context, producerID uuid.
 		SELECT 
 			o.id,
 			o.user_id,
-			o.spec_id,
-			o.license_type,
-			o.amount,
-			o.currency,
+			oi.spec_id,
+			oi.license_type,
+			oi.amount,
+			oi.currency,
 			o.razorpay_order_id,
 			o.provider,
 			o.provider_checkout_id,
@@ -172,11 +433,12 @@ func (r *PgOrderRepository) ListByProducer(ctx context.Context, producerID uuid.
 			u.name as buyer_name,
 			u.email as buyer_email,
 			s.title as spec_title
-		FROM orders o
-		JOIN specs s ON o.spec_id = s.id
+		FROM order_items oi
+		JOIN orders o ON oi.order_id = o.id
+		JOIN specs s ON oi.spec_id = s.id
 		JOIN users u ON o.user_id = u.id
 		WHERE s.producer_id = $1
-		ORDER BY o.created_at DESC
+		ORDER BY o.created_at DESC, oi.id
 		LIMIT $2 OFFSET $3
 	`
 
@@ -210,3 +472,10 @@ func (r *PgOrderRepository) ListByProducer(ctx context.Context, producerID uuid.
 
 	return orders, total, nil
 }
+
+func nullUUID(id uuid.UUID) *uuid.UUID {
+	if id == uuid.Nil {
+		return nil
+	}
+	return &id
+}
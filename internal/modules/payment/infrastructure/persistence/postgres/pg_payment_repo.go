@@ -9,6 +9,21 @@ import (
 	"github.com/saransh1220/blueprint-audio/internal/modules/payment/domain"
 )
 
+const insertPaymentQuery = `
+	INSERT INTO payments (
+		id, order_id, razorpay_payment_id, razorpay_signature,
+		amount, currency, status, method, bank, wallet, vpa,
+		card_network, card_last4, email, contact,
+		error_code, error_description, captured_at, provider_fee,
+		created_at, updated_at
+	) VALUES (
+		:id, :order_id, :razorpay_payment_id, :razorpay_signature,
+		:amount, :currency, :status, :method, :bank, :wallet, :vpa,
+		:card_network, :card_last4, :email, :contact,
+		:error_code, :error_description, :captured_at, :provider_fee,
+		:created_at, :updated_at
+	)`
+
 type PgPaymentRepository struct {
 	db *sqlx.DB
 }
@@ -29,21 +44,7 @@ func (r *PgPaymentRepository) Create(ctx context.Context, payment *domain.Paymen
 		payment.CreatedAt = time.Now()
 	}
 	payment.UpdatedAt = time.Now()
-	query := `
-		INSERT INTO payments (
-			id, order_id, razorpay_payment_id, razorpay_signature,
-			amount, currency, status, method, bank, wallet, vpa,
-			card_network, card_last4, email, contact,
-			error_code, error_description, captured_at,
-			created_at, updated_at
-		) VALUES (
-			:id, :order_id, :razorpay_payment_id, :razorpay_signature,
-			:amount, :currency, :status, :method, :bank, :wallet, :vpa,
-			:card_network, :card_last4, :email, :contact,
-			:error_code, :error_description, :captured_at,
-			:created_at, :updated_at
-		)`
-	_, err := r.db.NamedExecContext(ctx, query, payment)
+	_, err := r.db.NamedExecContext(ctx, insertPaymentQuery, payment)
 	return err
 }
 
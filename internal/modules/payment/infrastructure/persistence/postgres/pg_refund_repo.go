@@ -0,0 +1,200 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/saransh1220/blueprint-audio/internal/modules/payment/domain"
+)
+
+type PgRefundRepository struct {
+	db *sqlx.DB
+}
+
+func NewRefundRepository(db *sqlx.DB) domain.RefundRepository {
+	return &PgRefundRepository{db: db}
+}
+
+// Apply locks the order row so a webhook and an admin refund racing each other
+// cannot both pass the status check or both complete the refund; the loser
+// gets ErrOrderAlreadyRefunded. A provider refund recorded before returns
+// ErrRefundAlreadyRecorded so redelivered webhooks are not counted twice.
+func (r *PgRefundRepository) Apply(ctx context.Context, refund *domain.Refund, audit domain.RefundAudit) (bool, error) {
+	if refund.ID == uuid.Nil {
+		id, err := uuid.NewV7()
+		if err != nil {
+			return false, err
+		}
+		refund.ID = id
+	}
+	if refund.CreatedAt.IsZero() {
+		refund.CreatedAt = time.Now()
+	}
+	refund.InitiatedBy = audit.ActorID
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var order struct {
+		Status domain.OrderStatus `db:"status"`
+		Amount int                `db:"amount"`
+	}
+	if err := tx.GetContext(ctx, &order, `SELECT status, amount FROM orders WHERE id = $1 FOR UPDATE`, refund.OrderID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, domain.ErrOrderNotFound
+		}
+		return false, err
+	}
+	switch order.Status {
+	case domain.OrderStatusPaid:
+	case domain.OrderStatusRefunded:
+		return false, domain.ErrOrderAlreadyRefunded
+	default:
+		return false, domain.ErrOrderNotRefundable
+	}
+
+	var paymentID uuid.UUID
+	err = tx.GetContext(ctx, &paymentID, `
+		SELECT id FROM payments
+		WHERE order_id = $1 AND status = $2
+		ORDER BY captured_at DESC NULLS LAST
+		LIMIT 1`,
+		refund.OrderID, domain.PaymentStatusCaptured,
+	)
+	switch {
+	case err == nil:
+		refund.PaymentID = &paymentID
+	case !errors.Is(err, sql.ErrNoRows):
+		return false, err
+	}
+
+	result, err := tx.NamedExecContext(ctx, `
+		INSERT INTO order_refunds (
+			id, order_id, payment_id, provider, provider_refund_id,
+			kind, amount, currency, reason, initiated_by, created_at
+		) VALUES (
+			:id, :order_id, :payment_id, :provider, :provider_refund_id,
+			:kind, :amount, :currency, :reason, :initiated_by, :created_at
+		)
+		ON CONFLICT (provider, provider_refund_id) WHERE provider_refund_id IS NOT NULL DO NOTHING`, refund)
+	if err != nil {
+		return false, err
+	}
+	if inserted, err := result.RowsAffected(); err != nil {
+		return false, err
+	} else if inserted == 0 {
+		return false, domain.ErrRefundAlreadyRecorded
+	}
+
+	var refunded int
+	if err := tx.GetContext(ctx, &refunded,
+		`SELECT COALESCE(SUM(amount), 0) FROM order_refunds WHERE order_id = $1`, refund.OrderID); err != nil {
+		return false, err
+	}
+
+	status := order.Status
+	completed := refund.Kind == domain.RefundKindChargeback || refunded >= order.Amount
+	if completed {
+		status = domain.OrderStatusRefunded
+		if err := completeRefund(ctx, tx, refund); err != nil {
+			return false, err
+		}
+	}
+
+	beforeJSON, _ := json.Marshal(map[string]any{"status": order.Status})
+	afterJSON, _ := json.Marshal(map[string]any{
+		"status":             status,
+		"refund_id":          refund.ID,
+		"kind":               refund.Kind,
+		"provider":           refund.Provider,
+		"provider_refund_id": refund.ProviderRefundID,
+		"amount":             refund.Amount,
+		"refunded_amount":    refunded,
+		"currency":           refund.Currency,
+		"reason":             refund.Reason,
+	})
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO admin_audit_logs (actor_id, action, resource_type, resource_id, before_state, after_state, ip_address, user_agent)
+		VALUES ($1, $2, 'order', $3, $4, $5, $6, $7)`,
+		audit.ActorID, "orders."+string(refund.Kind), refund.OrderID, beforeJSON, afterJSON,
+		nullIfEmpty(audit.IPAddress), nullIfEmpty(audit.UserAgent),
+	); err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, err
+	}
+	return completed, nil
+}
+
+// completeRefund marks the order and its captured payment refunded, revokes
+// the order's licenses and recounts purchases of its specs.
+func completeRefund(ctx context.Context, tx *sqlx.Tx, refund *domain.Refund) error {
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE orders SET status = $1, updated_at = NOW() WHERE id = $2`,
+		domain.OrderStatusRefunded, refund.OrderID,
+	); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE payments SET status = $1, updated_at = NOW() WHERE order_id = $2 AND status = $3`,
+		domain.PaymentStatusRefunded, refund.OrderID, domain.PaymentStatusCaptured,
+	); err != nil {
+		return err
+	}
+
+	revokedReason := string(refund.Kind)
+	if refund.Reason != nil && *refund.Reason != "" {
+		revokedReason += ": " + *refund.Reason
+	}
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE licenses
+		SET is_revoked = true,
+		    revoked_reason = $1,
+		    revoked_at = NOW(),
+		    updated_at = NOW()
+		WHERE order_id = $2 AND is_revoked = false`,
+		revokedReason, refund.OrderID,
+	); err != nil {
+		return err
+	}
+
+	_, err := tx.ExecContext(ctx, recountOrderPurchasesQuery, refund.OrderID)
+	return err
+}
+
+func (r *PgRefundRepository) GetByOrderID(ctx context.Context, orderID uuid.UUID) (*domain.Refund, error) {
+	refund := &domain.Refund{}
+	err := r.db.GetContext(ctx, refund, `
+		SELECT * FROM order_refunds
+		WHERE order_id = $1
+		ORDER BY created_at DESC, id DESC
+		LIMIT 1`, orderID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrRefundNotFound
+	}
+	return refund, err
+}
+
+func (r *PgRefundRepository) RefundedAmount(ctx context.Context, orderID uuid.UUID) (int, error) {
+	var refunded int
+	err := r.db.GetContext(ctx, &refunded,
+		`SELECT COALESCE(SUM(amount), 0) FROM order_refunds WHERE order_id = $1`, orderID)
+	return refunded, err
+}
+
+func nullIfEmpty(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
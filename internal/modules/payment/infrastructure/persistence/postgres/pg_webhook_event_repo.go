@@ -0,0 +1,32 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/saransh1220/blueprint-audio/internal/modules/payment/domain"
+)
+
+type PgWebhookEventRepository struct {
+	db *sqlx.DB
+}
+
+func NewWebhookEventRepository(db *sqlx.DB) domain.WebhookEventRepository {
+	return &PgWebhookEventRepository{db: db}
+}
+
+func (r *PgWebhookEventRepository) IsProcessed(ctx context.Context, provider, eventID string) (bool, error) {
+	var exists bool
+	query := `SELECT EXISTS (SELECT 1 FROM payment_webhook_events WHERE provider = $1 AND id = $2)`
+	err := r.db.GetContext(ctx, &exists, query, provider, eventID)
+	return exists, err
+}
+
+func (r *PgWebhookEventRepository) MarkProcessed(ctx context.Context, provider, eventID, eventType string) error {
+	query := `
+		INSERT INTO payment_webhook_events (id, provider, event_type)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (id) DO NOTHING`
+	_, err := r.db.ExecContext(ctx, query, eventID, provider, eventType)
+	return err
+}
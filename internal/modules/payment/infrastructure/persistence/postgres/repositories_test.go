@@ -10,6 +10,7 @@ import (
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/saransh1220/blueprint-audio/internal/modules/payment/domain"
 	"github.com/saransh1220/blueprint-audio/internal/modules/payment/infrastructure/persistence/postgres"
 	"github.com/stretchr/testify/assert"
@@ -33,17 +34,50 @@ func TestPgOrderRepository_CreateAndList(t *testing.T) {
 	userID := uuid.New()
 	specID := uuid.New()
 	razor := "order_1"
-	order := &domain.Order{ID: id, UserID: userID, SpecID: specID, LicenseType: "Basic", Amount: 1000, Currency: "INR", RazorpayOrderID: &razor, Provider: "razorpay", Status: domain.OrderStatusPending, Notes: map[string]any{"k": "v"}, ExpiresAt: time.Now().Add(time.Hour)}
+	snapshotID := uuid.New()
+	order := &domain.Order{ID: id, UserID: userID, SpecID: specID, LicenseType: "Basic", Amount: 1000, Currency: "INR", RazorpayOrderID: &razor, Provider: "razorpay", Status: domain.OrderStatusPending, Notes: map[string]any{"k": "v"}, ExpiresAt: time.Now().Add(time.Hour), ExchangeRateSnapshotID: &snapshotID}
 
-	mock.ExpectExec("INSERT INTO orders").WillReturnResult(sqlmock.NewResult(1, 1))
+	order.Items = []domain.OrderItem{{SpecID: specID, LicenseOptionID: uuid.New(), LicenseType: "Basic", LicenseName: "Basic", SpecTitle: "Track", Amount: 1000, Currency: "INR"}}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO orders").WithArgs(id, userID, specID, "Basic", 1000, "INR", &razor, "razorpay", nil, nil, domain.OrderStatusPending, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 0, &snapshotID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO order_items").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
 	require.NoError(t, repo.Create(ctx, order))
+	assert.Equal(t, id, order.Items[0].OrderID)
+	assert.NotEqual(t, uuid.Nil, order.Items[0].ID)
 
 	notes, _ := json.Marshal(order.Notes)
-	rows := sqlmock.NewRows([]string{"id", "user_id", "spec_id", "license_type", "amount", "currency", "razorpay_order_id", "provider", "provider_checkout_id", "provider_payment_id", "status", "notes", "created_at", "updated_at", "expires_at"}).AddRow(id, userID, specID, "Basic", 1000, "INR", razor, "razorpay", nil, nil, "pending", notes, time.Now(), time.Now(), time.Now())
+	rows := sqlmock.NewRows([]string{"id", "user_id", "spec_id", "license_type", "amount", "currency", "razorpay_order_id", "provider", "provider_checkout_id", "provider_payment_id", "status", "notes", "created_at", "updated_at", "expires_at", "coupon_id", "discount_amount", "exchange_rate_snapshot_id"}).AddRow(id, userID, specID, "Basic", 1000, "INR", razor, "razorpay", nil, nil, "pending", notes, time.Now(), time.Now(), time.Now(), nil, 0, snapshotID)
 	mock.ExpectQuery("SELECT id, user_id, spec_id, license_type").WithArgs(id).WillReturnRows(rows)
+	itemRows := sqlmock.NewRows([]string{"id", "order_id", "spec_id", "license_option_id", "license_type", "license_name", "spec_title", "amount", "currency", "created_at"}).
+		AddRow(order.Items[0].ID, id, specID, order.Items[0].LicenseOptionID, "Basic", "Basic", "Track", 1000, "INR", time.Now())
+	mock.ExpectQuery(`SELECT \* FROM order_items WHERE order_id = ANY\(\$1::uuid\[\]\)`).WillReturnRows(itemRows)
 	got, err := repo.GetByID(ctx, id)
 	require.NoError(t, err)
 	assert.Equal(t, id, got.ID)
+	assert.Equal(t, &snapshotID, got.ExchangeRateSnapshotID)
+	require.Len(t, got.Items, 1)
+	assert.Equal(t, specID, got.Items[0].SpecID)
+	mock.ExpectQuery("SELECT id, user_id, spec_id, license_type").WithArgs(specID).WillReturnError(sql.ErrNoRows)
+	_, err = repo.GetByID(ctx, specID)
+	assert.ErrorIs(t, err, domain.ErrOrderNotFound)
+
+	// Cart orders leave spec_id and license_type NULL on the order row.
+	cartID := uuid.New()
+	cartRows := sqlmock.NewRows([]string{"id", "user_id", "spec_id", "license_type", "amount", "currency", "razorpay_order_id", "provider", "provider_checkout_id", "provider_payment_id", "status", "notes", "created_at", "updated_at", "expires_at"}).
+		AddRow(cartID, userID, nil, nil, 3000, "INR", "order_2", "razorpay", nil, nil, "pending", notes, time.Now(), time.Now(), time.Now())
+	mock.ExpectQuery(`SELECT \* FROM orders WHERE razorpay_order_id = \$1`).WithArgs("order_2").WillReturnRows(cartRows)
+	mock.ExpectQuery(`SELECT \* FROM order_items`).WillReturnRows(sqlmock.NewRows([]string{"id", "order_id", "spec_id", "license_option_id", "license_type", "license_name", "spec_title", "amount", "currency", "created_at"}).
+		AddRow(uuid.New(), cartID, uuid.New(), uuid.New(), "Basic", "Basic", "A", 1000, "INR", time.Now()).
+		AddRow(uuid.New(), cartID, uuid.New(), uuid.New(), "Premium", "Premium", "B", 2000, "INR", time.Now()))
+	cartOrder, err := repo.GetByRazorpayID(ctx, "order_2")
+	require.NoError(t, err)
+	assert.Equal(t, uuid.Nil, cartOrder.SpecID)
+	assert.Empty(t, cartOrder.LicenseType)
+	assert.Equal(t, "v", cartOrder.Notes["k"])
+	assert.Len(t, cartOrder.Items, 2)
 
 	mock.ExpectExec(`UPDATE orders SET status = \$1, updated_at = NOW\(\) WHERE id = \$2`).WithArgs(domain.OrderStatusPaid, id).WillReturnResult(sqlmock.NewResult(0, 1))
 	require.NoError(t, repo.UpdateStatus(ctx, id, domain.OrderStatusPaid))
@@ -68,11 +102,11 @@ func TestPgOrderRepository_ListByProducerAndErrors(t *testing.T) {
 	_, _, err := repo.ListByProducer(ctx, producerID, 50, 0)
 	require.Error(t, err)
 
-	mock.ExpectQuery(`SELECT COUNT\(\*\)`).WithArgs(producerID).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM order_items oi JOIN specs s`).WithArgs(producerID).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
 	notes, _ := json.Marshal(map[string]any{"x": 1})
 	rows := sqlmock.NewRows([]string{"id", "user_id", "spec_id", "license_type", "amount", "currency", "razorpay_order_id", "provider", "provider_checkout_id", "provider_payment_id", "status", "notes", "created_at", "updated_at", "expires_at", "buyer_name", "buyer_email", "spec_title"}).
 		AddRow(uuid.New(), uuid.New(), uuid.New(), "Basic", 1000, "INR", nil, "razorpay", nil, nil, "paid", notes, time.Now(), time.Now(), time.Now(), "Buyer", "buyer@example.com", "Spec")
-	mock.ExpectQuery(`SELECT\s+o\.id`).WithArgs(producerID, 50, 0).WillReturnRows(rows)
+	mock.ExpectQuery(`SELECT\s+o\.id, o\.user_id, oi\.spec_id, oi\.license_type, oi\.amount`).WithArgs(producerID, 50, 0).WillReturnRows(rows)
 	orders, total, err := repo.ListByProducer(ctx, producerID, 50, 0)
 	require.NoError(t, err)
 	assert.Equal(t, 1, total)
@@ -128,11 +162,23 @@ func TestPgLicenseRepository_Basic(t *testing.T) {
 	mock.ExpectQuery(`SELECT \* FROM licenses WHERE id = \$1`).WithArgs(id).WillReturnRows(rows)
 	_, err := repo.GetByID(ctx, id)
 	require.NoError(t, err)
+	mock.ExpectQuery(`SELECT \* FROM licenses WHERE id = \$1`).WithArgs(orderID).WillReturnError(sql.ErrNoRows)
+	_, err = repo.GetByID(ctx, orderID)
+	assert.ErrorIs(t, err, domain.ErrLicenseNotFound)
+	byKeyRows := sqlmock.NewRows([]string{"id", "license_key", "is_active", "is_revoked"}).AddRow(id, "LIC-1", true, false)
+	mock.ExpectQuery(`SELECT \* FROM licenses WHERE license_key = \$1`).WithArgs("LIC-1").WillReturnRows(byKeyRows)
+	byKey, err := repo.GetByKey(ctx, "LIC-1")
+	require.NoError(t, err)
+	assert.Equal(t, id, byKey.ID)
+	mock.ExpectQuery(`SELECT \* FROM licenses WHERE license_key = \$1`).WithArgs("LIC-X").WillReturnError(sql.ErrNoRows)
+	_, err = repo.GetByKey(ctx, "LIC-X")
+	assert.ErrorIs(t, err, domain.ErrLicenseNotFound)
 	rowsByOrder := sqlmock.NewRows([]string{"id", "order_id", "user_id", "spec_id", "license_option_id", "license_type", "purchase_price", "license_key", "is_active", "is_revoked", "downloads_count", "issued_at", "created_at", "updated_at"}).
 		AddRow(id, orderID, userID, specID, optID, "Basic", 1000, "LIC-1", true, false, 0, time.Now(), time.Now(), time.Now())
-	mock.ExpectQuery(`SELECT \* FROM licenses WHERE order_id = \$1`).WithArgs(orderID).WillReturnRows(rowsByOrder)
-	_, err = repo.GetByOrderID(ctx, orderID)
+	mock.ExpectQuery(`SELECT \* FROM licenses WHERE order_id = \$1 ORDER BY issued_at, id`).WithArgs(orderID).WillReturnRows(rowsByOrder)
+	byOrder, err := repo.ListByOrderID(ctx, orderID)
 	require.NoError(t, err)
+	require.Len(t, byOrder, 1)
 
 	listRows := sqlmock.NewRows([]string{"id", "order_id", "user_id", "spec_id", "license_option_id", "license_type", "purchase_price", "license_key", "is_active", "is_revoked", "downloads_count", "issued_at", "created_at", "updated_at", "spec_title", "spec_image", "total_count"}).
 		AddRow(id, orderID, userID, specID, optID, "Basic", 1000, "LIC-1", true, false, 0, time.Now(), time.Now(), time.Now(), "Track", nil, 1)
@@ -146,4 +192,343 @@ func TestPgLicenseRepository_Basic(t *testing.T) {
 	require.NoError(t, repo.IncrementDownloads(ctx, id))
 	mock.ExpectExec("UPDATE licenses").WithArgs("reason", id).WillReturnResult(sqlmock.NewResult(0, 1))
 	require.NoError(t, repo.Revoke(ctx, id, "reason"))
+	mock.ExpectExec(`UPDATE licenses SET agreement_key = \$1`).WithArgs("agreements/LIC-1.pdf", id).WillReturnResult(sqlmock.NewResult(0, 1))
+	require.NoError(t, repo.SetAgreementKey(ctx, id, "agreements/LIC-1.pdf"))
+}
+
+func TestPgAgreementTemplateRepository(t *testing.T) {
+	db, mock, cleanup := newMockDB(t)
+	defer cleanup()
+	repo := postgres.NewAgreementTemplateRepository(db)
+	ctx := context.Background()
+	optionID := uuid.New()
+
+	mock.ExpectQuery(`SELECT \* FROM license_agreement_templates WHERE license_option_id = \$1`).WithArgs(optionID).WillReturnError(sql.ErrNoRows)
+	_, err := repo.GetByLicenseOptionID(ctx, optionID)
+	assert.ErrorIs(t, err, domain.ErrAgreementTemplateNotFound)
+
+	rows := sqlmock.NewRows([]string{"license_option_id", "stream_limit", "distribution_limit", "credit_required", "terms", "created_at", "updated_at"}).
+		AddRow(optionID, 5000, nil, true, "Terms for {{buyer_name}}", time.Now(), time.Now())
+	mock.ExpectQuery(`SELECT \* FROM license_agreement_templates`).WithArgs(optionID).WillReturnRows(rows)
+	template, err := repo.GetByLicenseOptionID(ctx, optionID)
+	require.NoError(t, err)
+	assert.True(t, template.IsCustom)
+	require.NotNil(t, template.StreamLimit)
+	assert.Equal(t, 5000, *template.StreamLimit)
+	assert.Nil(t, template.DistributionLimit)
+
+	createdAt := time.Now().Add(-time.Hour)
+	mock.ExpectQuery(`INSERT INTO license_agreement_templates .* ON CONFLICT \(license_option_id\) DO UPDATE`).
+		WillReturnRows(sqlmock.NewRows([]string{"created_at"}).AddRow(createdAt))
+	upserted := &domain.AgreementTemplate{LicenseOptionID: optionID, CreditRequired: true, Terms: "Terms"}
+	require.NoError(t, repo.Upsert(ctx, upserted))
+	assert.True(t, upserted.IsCustom)
+	require.NotNil(t, upserted.CreatedAt)
+	assert.WithinDuration(t, createdAt, *upserted.CreatedAt, time.Second)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPgRefundRepository_Apply(t *testing.T) {
+	db, mock, cleanup := newMockDB(t)
+	defer cleanup()
+	repo := postgres.NewRefundRepository(db)
+	ctx := context.Background()
+
+	orderID := uuid.New()
+	paymentID := uuid.New()
+	actorID := uuid.New()
+	providerRefundID := "rfnd_1"
+	refund := &domain.Refund{OrderID: orderID, Provider: "razorpay", ProviderRefundID: &providerRefundID, Kind: domain.RefundKindRefund, Amount: 1000, Currency: "INR"}
+
+	lockOrder := func(status string) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT status, amount FROM orders WHERE id = \$1 FOR UPDATE`).WithArgs(orderID).
+			WillReturnRows(sqlmock.NewRows([]string{"status", "amount"}).AddRow(status, 2500))
+	}
+	expectRefundInsert := func(inserted int64) {
+		mock.ExpectQuery(`SELECT id FROM payments WHERE order_id = \$1 AND status = \$2`).WithArgs(orderID, domain.PaymentStatusCaptured).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(paymentID))
+		mock.ExpectExec(`INSERT INTO order_refunds .* ON CONFLICT \(provider, provider_refund_id\)`).WillReturnResult(sqlmock.NewResult(0, inserted))
+	}
+
+	// A partial refund is recorded without completing the order.
+	lockOrder("paid")
+	expectRefundInsert(1)
+	mock.ExpectQuery(`SELECT COALESCE\(SUM\(amount\), 0\) FROM order_refunds WHERE order_id = \$1`).WithArgs(orderID).
+		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(1000))
+	mock.ExpectExec("INSERT INTO admin_audit_logs").WithArgs(&actorID, "orders.refund", orderID, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), nil).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	completed, err := repo.Apply(ctx, refund, domain.RefundAudit{ActorID: &actorID, IPAddress: "127.0.0.1"})
+	require.NoError(t, err)
+	assert.False(t, completed)
+	require.NotNil(t, refund.PaymentID)
+	assert.Equal(t, paymentID, *refund.PaymentID)
+	assert.Equal(t, &actorID, refund.InitiatedBy)
+	assert.NotEqual(t, uuid.Nil, refund.ID)
+
+	// The refund that brings the total to the order amount completes it.
+	secondRefundID := "rfnd_2"
+	second := &domain.Refund{OrderID: orderID, Provider: "razorpay", ProviderRefundID: &secondRefundID, Kind: domain.RefundKindRefund, Amount: 1500, Currency: "INR"}
+	lockOrder("paid")
+	expectRefundInsert(1)
+	mock.ExpectQuery(`SELECT COALESCE\(SUM\(amount\), 0\) FROM order_refunds`).WithArgs(orderID).
+		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(2500))
+	mock.ExpectExec(`UPDATE orders SET status = \$1`).WithArgs(domain.OrderStatusRefunded, orderID).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE payments SET status = \$1, updated_at = NOW\(\) WHERE order_id = \$2 AND status = \$3`).
+		WithArgs(domain.PaymentStatusRefunded, orderID, domain.PaymentStatusCaptured).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE licenses SET is_revoked = true`).WithArgs("refund", orderID).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO spec_analytics .* FROM order_items oi WHERE oi\.order_id = \$1`).WithArgs(orderID).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO admin_audit_logs").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	completed, err = repo.Apply(ctx, second, domain.RefundAudit{})
+	require.NoError(t, err)
+	assert.True(t, completed)
+
+	// A provider refund that was already recorded is not counted twice.
+	lockOrder("paid")
+	expectRefundInsert(0)
+	mock.ExpectRollback()
+	_, err = repo.Apply(ctx, second, domain.RefundAudit{})
+	assert.ErrorIs(t, err, domain.ErrRefundAlreadyRecorded)
+
+	lockOrder("refunded")
+	mock.ExpectRollback()
+	_, err = repo.Apply(ctx, &domain.Refund{OrderID: orderID}, domain.RefundAudit{})
+	assert.ErrorIs(t, err, domain.ErrOrderAlreadyRefunded)
+
+	lockOrder("pending")
+	mock.ExpectRollback()
+	_, err = repo.Apply(ctx, &domain.Refund{OrderID: orderID}, domain.RefundAudit{})
+	assert.ErrorIs(t, err, domain.ErrOrderNotRefundable)
+
+	mock.ExpectQuery(`SELECT COALESCE\(SUM\(amount\), 0\) FROM order_refunds`).WithArgs(orderID).
+		WillReturnRows(sqlmock.NewRows([]string{"sum"}).AddRow(1000))
+	refunded, err := repo.RefundedAmount(ctx, orderID)
+	require.NoError(t, err)
+	assert.Equal(t, 1000, refunded)
+
+	mock.ExpectQuery(`SELECT \* FROM order_refunds WHERE order_id = \$1`).WithArgs(orderID).WillReturnError(sql.ErrNoRows)
+	_, err = repo.GetByOrderID(ctx, orderID)
+	assert.ErrorIs(t, err, domain.ErrRefundNotFound)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPgOrderRepository_MarkPaid(t *testing.T) {
+	db, mock, cleanup := newMockDB(t)
+	defer cleanup()
+	repo := postgres.NewOrderRepository(db)
+	ctx := context.Background()
+
+	orderID := uuid.New()
+	specID := uuid.New()
+	payment := &domain.Payment{OrderID: orderID, RazorpayPaymentID: "pay_1", RazorpaySignature: "sig", Amount: 1000, Currency: "INR", Status: domain.PaymentStatusCaptured}
+	userID := uuid.New()
+	licenses := []domain.License{
+		{OrderID: orderID, UserID: userID, SpecID: specID, LicenseOptionID: uuid.New(), LicenseType: "Basic", PurchasePrice: 400, Currency: "INR", LicenseKey: "LIC-1", IsActive: true},
+		{OrderID: orderID, UserID: userID, SpecID: uuid.New(), LicenseOptionID: uuid.New(), LicenseType: "Premium", PurchasePrice: 600, Currency: "INR", LicenseKey: "LIC-2", IsActive: true},
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT status, user_id FROM orders WHERE id = \$1 FOR UPDATE`).WithArgs(orderID).
+		WillReturnRows(sqlmock.NewRows([]string{"status", "user_id"}).AddRow("pending", userID))
+	mock.ExpectExec(`INSERT INTO payments .* ON CONFLICT \(razorpay_payment_id\) DO UPDATE`).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(`UPDATE orders SET status = \$1, provider_payment_id = \$2`).WithArgs(domain.OrderStatusPaid, "pay_1", orderID).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO licenses").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO licenses").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(`INSERT INTO spec_analytics .* ON CONFLICT \(spec_id\) DO UPDATE`).WithArgs(orderID).WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(`DELETE FROM cart_items ci USING order_items oi`).WithArgs(orderID, userID).WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	issued, err := repo.MarkPaid(ctx, payment, licenses)
+	require.NoError(t, err)
+	require.Len(t, issued, 2)
+	assert.NotEqual(t, uuid.Nil, payment.ID)
+	assert.NotEqual(t, uuid.Nil, issued[0].ID)
+	assert.NotEqual(t, issued[0].ID, issued[1].ID)
+
+	existingID := uuid.New()
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT status, user_id FROM orders WHERE id = \$1 FOR UPDATE`).WithArgs(orderID).
+		WillReturnRows(sqlmock.NewRows([]string{"status", "user_id"}).AddRow("paid", userID))
+	mock.ExpectQuery(`SELECT \* FROM licenses WHERE order_id = \$1`).WithArgs(orderID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "order_id", "license_key"}).AddRow(existingID, orderID, "LIC-0"))
+	mock.ExpectRollback()
+	issued, err = repo.MarkPaid(ctx, &domain.Payment{OrderID: orderID}, []domain.License{{OrderID: orderID}})
+	assert.ErrorIs(t, err, domain.ErrOrderAlreadyPaid)
+	require.Len(t, issued, 1)
+	assert.Equal(t, existingID, issued[0].ID)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT status, user_id FROM orders WHERE id = \$1 FOR UPDATE`).WithArgs(orderID).
+		WillReturnRows(sqlmock.NewRows([]string{"status", "user_id"}).AddRow("refunded", userID))
+	mock.ExpectRollback()
+	_, err = repo.MarkPaid(ctx, &domain.Payment{OrderID: orderID}, []domain.License{{OrderID: orderID}})
+	assert.ErrorIs(t, err, domain.ErrInvalidOrderStatus)
+
+	mock.ExpectExec(`UPDATE orders SET status = \$1, updated_at = NOW\(\) WHERE id = \$2 AND status IN \('pending', 'processing'\)`).
+		WithArgs(domain.OrderStatusFailed, orderID).WillReturnResult(sqlmock.NewResult(0, 0))
+	failed, err := repo.MarkFailed(ctx, orderID)
+	require.NoError(t, err)
+	assert.False(t, failed)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPgOrderRepository_FailExpired(t *testing.T) {
+	db, mock, cleanup := newMockDB(t)
+	defer cleanup()
+	repo := postgres.NewOrderRepository(db)
+	now := time.Now()
+
+	mock.ExpectExec(`UPDATE orders\s+SET status = \$1, updated_at = NOW\(\)\s+WHERE status IN \('pending', 'processing'\) AND expires_at < \$2`).
+		WithArgs(domain.OrderStatusFailed, now).WillReturnResult(sqlmock.NewResult(0, 3))
+	failed, err := repo.FailExpired(context.Background(), now)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), failed)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPgWebhookEventRepository(t *testing.T) {
+	db, mock, cleanup := newMockDB(t)
+	defer cleanup()
+	repo := postgres.NewWebhookEventRepository(db)
+	ctx := context.Background()
+
+	mock.ExpectQuery(`SELECT EXISTS \(SELECT 1 FROM payment_webhook_events WHERE provider = \$1 AND id = \$2\)`).WithArgs("razorpay", "evt_1").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	processed, err := repo.IsProcessed(ctx, "razorpay", "evt_1")
+	require.NoError(t, err)
+	assert.False(t, processed)
+
+	mock.ExpectExec(`INSERT INTO payment_webhook_events .* ON CONFLICT \(id\) DO NOTHING`).WithArgs("evt_1", "razorpay", "payment.captured").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	require.NoError(t, repo.MarkProcessed(ctx, "razorpay", "evt_1", "payment.captured"))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPgCartRepository(t *testing.T) {
+	db, mock, cleanup := newMockDB(t)
+	defer cleanup()
+	repo := postgres.NewCartRepository(db)
+	ctx := context.Background()
+	userID := uuid.New()
+	specID := uuid.New()
+	optID := uuid.New()
+
+	mock.ExpectQuery(`SELECT \* FROM cart_items WHERE user_id = \$1 ORDER BY created_at, spec_id`).WithArgs(userID).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "spec_id", "license_option_id", "created_at", "updated_at"}))
+	items, err := repo.ListByUser(ctx, userID)
+	require.NoError(t, err)
+	assert.NotNil(t, items)
+	assert.Empty(t, items)
+
+	item := &domain.CartItem{UserID: userID, SpecID: specID, LicenseOptionID: optID}
+	mock.ExpectExec(`INSERT INTO cart_items .* ON CONFLICT \(user_id, spec_id\) DO UPDATE`).
+		WithArgs(userID, specID, optID, sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+	require.NoError(t, repo.Upsert(ctx, item))
+	assert.False(t, item.CreatedAt.IsZero())
+
+	mock.ExpectExec(`DELETE FROM cart_items WHERE user_id = \$1 AND spec_id = \$2`).WithArgs(userID, specID).WillReturnResult(sqlmock.NewResult(0, 1))
+	require.NoError(t, repo.Remove(ctx, userID, specID))
+	mock.ExpectExec(`DELETE FROM cart_items WHERE user_id = \$1 AND spec_id = \$2`).WithArgs(userID, specID).WillReturnResult(sqlmock.NewResult(0, 0))
+	assert.ErrorIs(t, repo.Remove(ctx, userID, specID), domain.ErrCartItemNotFound)
+
+	mock.ExpectExec(`DELETE FROM cart_items WHERE user_id = \$1`).WithArgs(userID).WillReturnResult(sqlmock.NewResult(0, 2))
+	require.NoError(t, repo.Clear(ctx, userID))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPgCouponRepository(t *testing.T) {
+	db, mock, cleanup := newMockDB(t)
+	defer cleanup()
+	repo := postgres.NewCouponRepository(db)
+	ctx := context.Background()
+	producerID := uuid.New()
+	specID := uuid.New()
+	percent := 20
+
+	coupon := &domain.Coupon{ProducerID: producerID, Code: "WEEKEND20", DiscountType: domain.DiscountTypePercentage, PercentOff: &percent, SpecIDs: []uuid.UUID{specID}, LicenseTypes: []string{"Basic"}, IsActive: true}
+	mock.ExpectExec(`INSERT INTO coupons`).WillReturnResult(sqlmock.NewResult(1, 1))
+	require.NoError(t, repo.Create(ctx, coupon))
+	assert.NotEqual(t, uuid.Nil, coupon.ID)
+
+	mock.ExpectExec(`INSERT INTO coupons`).WillReturnError(&pq.Error{Code: "23505"})
+	assert.ErrorIs(t, repo.Create(ctx, &domain.Coupon{ProducerID: producerID, Code: "WEEKEND20"}), domain.ErrCouponCodeTaken)
+
+	columns := []string{"id", "producer_id", "code", "discount_type", "percent_off", "amount_off", "currency", "spec_ids", "license_types", "max_redemptions", "per_user_limit", "expires_at", "is_active", "created_at", "updated_at"}
+	mock.ExpectQuery(`SELECT \* FROM coupons WHERE code = \$1`).WithArgs("WEEKEND20").
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(coupon.ID, producerID, "WEEKEND20", "percentage", 20, nil, nil, "{"+specID.String()+"}", "{Basic}", nil, 1, nil, true, time.Now(), time.Now()))
+	got, err := repo.GetByCode(ctx, "WEEKEND20")
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{specID}, got.SpecIDs)
+	assert.Equal(t, []string{"Basic"}, got.LicenseTypes)
+	assert.Equal(t, 1, *got.PerUserLimit)
+	assert.Nil(t, got.MaxRedemptions)
+
+	mock.ExpectQuery(`SELECT \* FROM coupons WHERE id = \$1`).WillReturnError(sql.ErrNoRows)
+	_, err = repo.GetByID(ctx, uuid.New())
+	assert.ErrorIs(t, err, domain.ErrCouponNotFound)
+
+	mock.ExpectQuery(`SELECT c\.\*, COUNT\(o\.id\) AS redemptions, COALESCE\(SUM\(o\.discount_amount\), 0\) AS discount_given FROM coupons c LEFT JOIN orders o ON o\.coupon_id = c\.id AND o\.status = 'paid'`).WithArgs(producerID).
+		WillReturnRows(sqlmock.NewRows(append(columns, "redemptions", "discount_given")).AddRow(coupon.ID, producerID, "WEEKEND20", "percentage", 20, nil, nil, "{}", "{}", nil, nil, nil, true, time.Now(), time.Now(), 3, 29970))
+	coupons, err := repo.ListByProducer(ctx, producerID)
+	require.NoError(t, err)
+	require.Len(t, coupons, 1)
+	assert.Equal(t, 3, coupons[0].Redemptions)
+	assert.Equal(t, 29970, coupons[0].DiscountGiven)
+	assert.Empty(t, coupons[0].SpecIDs)
+
+	mock.ExpectExec(`UPDATE coupons SET spec_ids = \$1::uuid\[\]`).WillReturnResult(sqlmock.NewResult(0, 0))
+	assert.ErrorIs(t, repo.Update(ctx, coupon), domain.ErrCouponNotFound)
+	mock.ExpectExec(`DELETE FROM coupons WHERE id = \$1`).WithArgs(coupon.ID).WillReturnResult(sqlmock.NewResult(0, 1))
+	require.NoError(t, repo.Delete(ctx, coupon.ID))
+
+	userID := uuid.New()
+	mock.ExpectQuery(`FROM orders WHERE coupon_id = \$1 AND \(status = 'paid' OR \(status IN \('pending', 'processing'\) AND expires_at > NOW\(\)\)\)`).WithArgs(coupon.ID, userID).
+		WillReturnRows(sqlmock.NewRows([]string{"total", "by_user"}).AddRow(4, 1))
+	counts, err := repo.CountRedemptions(ctx, coupon.ID, userID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.CouponRedemptions{Total: 4, ByUser: 1}, counts)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPgOrderRepository_CreateReservesCoupon(t *testing.T) {
+	db, mock, cleanup := newMockDB(t)
+	defer cleanup()
+	repo := postgres.NewOrderRepository(db)
+	ctx := context.Background()
+	couponID := uuid.New()
+	userID := uuid.New()
+	newOrder := func() *domain.Order {
+		return &domain.Order{UserID: userID, Amount: 800, Currency: "INR", Provider: "razorpay", Status: domain.OrderStatusPending, CouponID: &couponID, DiscountAmount: 200,
+			Items: []domain.OrderItem{{SpecID: uuid.New(), LicenseOptionID: uuid.New(), LicenseType: "Basic", Amount: 800, DiscountAmount: 200, Currency: "INR"}}}
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT max_redemptions, per_user_limit FROM coupons WHERE id = \$1 FOR UPDATE`).WithArgs(couponID).
+		WillReturnRows(sqlmock.NewRows([]string{"max_redemptions", "per_user_limit"}).AddRow(10, 1))
+	mock.ExpectQuery(`FROM orders WHERE coupon_id = \$1`).WithArgs(couponID, userID).
+		WillReturnRows(sqlmock.NewRows([]string{"total", "by_user"}).AddRow(9, 0))
+	mock.ExpectExec("INSERT INTO orders").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO order_items").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+	require.NoError(t, repo.Create(ctx, newOrder()))
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT max_redemptions, per_user_limit FROM coupons WHERE id = \$1 FOR UPDATE`).WithArgs(couponID).
+		WillReturnRows(sqlmock.NewRows([]string{"max_redemptions", "per_user_limit"}).AddRow(10, 1))
+	mock.ExpectQuery(`FROM orders WHERE coupon_id = \$1`).WithArgs(couponID, userID).
+		WillReturnRows(sqlmock.NewRows([]string{"total", "by_user"}).AddRow(10, 0))
+	mock.ExpectRollback()
+	assert.ErrorIs(t, repo.Create(ctx, newOrder()), domain.ErrCouponExhausted)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT max_redemptions, per_user_limit FROM coupons WHERE id = \$1 FOR UPDATE`).WithArgs(couponID).
+		WillReturnRows(sqlmock.NewRows([]string{"max_redemptions", "per_user_limit"}).AddRow(nil, 1))
+	mock.ExpectQuery(`FROM orders WHERE coupon_id = \$1`).WithArgs(couponID, userID).
+		WillReturnRows(sqlmock.NewRows([]string{"total", "by_user"}).AddRow(3, 1))
+	mock.ExpectRollback()
+	assert.ErrorIs(t, repo.Create(ctx, newOrder()), domain.ErrCouponLimitReached)
+	require.NoError(t, mock.ExpectationsWereMet())
 }
@@ -0,0 +1,122 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/saransh1220/blueprint-audio/internal/gateway/middleware"
+	"github.com/saransh1220/blueprint-audio/internal/modules/payment/application"
+	"github.com/saransh1220/blueprint-audio/internal/modules/payment/domain"
+)
+
+// GetLicenseAgreement downloads the agreement PDF issued with a license.
+func (h *PaymentHandler) GetLicenseAgreement(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.ContextKeyUserId).(uuid.UUID)
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	licenseID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "invalid license ID", http.StatusBadRequest)
+		return
+	}
+
+	agreement, err := h.service.GetLicenseAgreement(r.Context(), licenseID, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrLicenseNotFound), errors.Is(err, domain.ErrAgreementUnavailable):
+			http.Error(w, err.Error(), http.StatusNotFound)
+		case errors.Is(err, domain.ErrLicenseRevoked):
+			http.Error(w, err.Error(), http.StatusForbidden)
+		default:
+			log.Printf("PaymentHandler.GetLicenseAgreement failed. license_id=%s err=%v", licenseID, err)
+			http.Error(w, "failed to fetch license agreement", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+agreement.Filename+`"`)
+	w.Header().Set("Content-Length", strconv.Itoa(len(agreement.Content)))
+	w.Write(agreement.Content)
+}
+
+func agreementTemplateErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrInvalidAgreementTemplate):
+		return http.StatusBadRequest
+	case errors.Is(err, domain.ErrLicenseOptionNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// GetAgreementTemplate returns the agreement template of one of the
+// producer's license options, or the defaults if it was never customised.
+func (h *PaymentHandler) GetAgreementTemplate(w http.ResponseWriter, r *http.Request) {
+	producerID, ok := r.Context().Value(middleware.ContextKeyUserId).(uuid.UUID)
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	licenseOptionID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "invalid license option ID", http.StatusBadRequest)
+		return
+	}
+
+	template, err := h.service.GetAgreementTemplate(r.Context(), producerID, licenseOptionID)
+	if err != nil {
+		statusCode := agreementTemplateErrorStatus(err)
+		if statusCode == http.StatusInternalServerError {
+			log.Printf("PaymentHandler.GetAgreementTemplate failed. license_option_id=%s err=%v", licenseOptionID, err)
+			http.Error(w, "failed to fetch agreement template", statusCode)
+			return
+		}
+		http.Error(w, err.Error(), statusCode)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(template)
+}
+
+func (h *PaymentHandler) UpdateAgreementTemplate(w http.ResponseWriter, r *http.Request) {
+	producerID, ok := r.Context().Value(middleware.ContextKeyUserId).(uuid.UUID)
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	licenseOptionID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "invalid license option ID", http.StatusBadRequest)
+		return
+	}
+
+	var input application.AgreementTemplateInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	template, err := h.service.UpdateAgreementTemplate(r.Context(), producerID, licenseOptionID, input)
+	if err != nil {
+		statusCode := agreementTemplateErrorStatus(err)
+		if statusCode == http.StatusInternalServerError {
+			log.Printf("PaymentHandler.UpdateAgreementTemplate failed. license_option_id=%s err=%v", licenseOptionID, err)
+			http.Error(w, "failed to update agreement template", statusCode)
+			return
+		}
+		http.Error(w, err.Error(), statusCode)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(template)
+}
@@ -0,0 +1,149 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/saransh1220/blueprint-audio/internal/gateway/middleware"
+	"github.com/saransh1220/blueprint-audio/internal/modules/payment/application"
+	"github.com/saransh1220/blueprint-audio/internal/modules/payment/domain"
+	"github.com/saransh1220/blueprint-audio/internal/shared/money"
+)
+
+func (h *PaymentHandler) GetCart(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.ContextKeyUserId).(uuid.UUID)
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	cart, err := h.service.GetCart(r.Context(), userID, money.ResolveCurrencyFromRequest(r))
+	if err != nil {
+		log.Printf("PaymentHandler.GetCart failed: %v", err)
+		http.Error(w, "failed to fetch cart", http.StatusInternalServerError)
+		return
+	}
+	writeCart(w, cart)
+}
+
+func (h *PaymentHandler) AddCartItem(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.ContextKeyUserId).(uuid.UUID)
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req struct {
+		SpecID          string `json:"spec_id"`
+		LicenseOptionID string `json:"license_option_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	specID, err := uuid.Parse(req.SpecID)
+	if err != nil {
+		http.Error(w, "invalid spec_id", http.StatusBadRequest)
+		return
+	}
+	licenseOptionID, err := uuid.Parse(req.LicenseOptionID)
+	if err != nil {
+		http.Error(w, "invalid license_option_id", http.StatusBadRequest)
+		return
+	}
+
+	cart, err := h.service.AddToCart(r.Context(), userID, specID, licenseOptionID, money.ResolveCurrencyFromRequest(r))
+	if err != nil {
+		statusCode := http.StatusBadRequest
+		if errors.Is(err, domain.ErrCartFull) {
+			statusCode = http.StatusConflict
+		}
+		http.Error(w, err.Error(), statusCode)
+		return
+	}
+	writeCart(w, cart)
+}
+
+func (h *PaymentHandler) RemoveCartItem(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.ContextKeyUserId).(uuid.UUID)
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	specID, err := uuid.Parse(r.PathValue("spec_id"))
+	if err != nil {
+		http.Error(w, "invalid spec_id", http.StatusBadRequest)
+		return
+	}
+
+	cart, err := h.service.RemoveFromCart(r.Context(), userID, specID, money.ResolveCurrencyFromRequest(r))
+	if err != nil {
+		if errors.Is(err, domain.ErrCartItemNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		log.Printf("PaymentHandler.RemoveCartItem failed: %v", err)
+		http.Error(w, "failed to update cart", http.StatusInternalServerError)
+		return
+	}
+	writeCart(w, cart)
+}
+
+func (h *PaymentHandler) ClearCart(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.ContextKeyUserId).(uuid.UUID)
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	if err := h.service.ClearCart(r.Context(), userID); err != nil {
+		log.Printf("PaymentHandler.ClearCart failed: %v", err)
+		http.Error(w, "failed to clear cart", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Checkout opens one order for everything in the cart. The response has the
+// same shape as POST /orders.
+func (h *PaymentHandler) Checkout(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.ContextKeyUserId).(uuid.UUID)
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req struct {
+		CouponCode string `json:"coupon_code"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+
+	order, err := h.service.Checkout(r.Context(), userID, money.ResolveCurrencyFromRequest(r), req.CouponCode)
+	if err != nil {
+		statusCode := orderErrorStatus(err)
+		switch {
+		case errors.Is(err, domain.ErrCartEmpty):
+			statusCode = http.StatusBadRequest
+		case errors.Is(err, domain.ErrCartItemUnavailable):
+			statusCode = http.StatusConflict
+		}
+		http.Error(w, "failed to create order: "+err.Error(), statusCode)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(order)
+}
+
+func writeCart(w http.ResponseWriter, cart *application.CartResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(cart)
+}
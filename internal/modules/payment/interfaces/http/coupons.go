@@ -0,0 +1,149 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/saransh1220/blueprint-audio/internal/gateway/middleware"
+	"github.com/saransh1220/blueprint-audio/internal/modules/payment/application"
+	"github.com/saransh1220/blueprint-audio/internal/modules/payment/domain"
+)
+
+// orderErrorStatus maps a coupon the buyer cannot use to a client error;
+// anything else failing an order is a server error.
+func orderErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrCouponNotFound),
+		errors.Is(err, domain.ErrCouponInactive),
+		errors.Is(err, domain.ErrCouponExpired),
+		errors.Is(err, domain.ErrCouponNotApplicable):
+		return http.StatusBadRequest
+	case errors.Is(err, domain.ErrCouponExhausted), errors.Is(err, domain.ErrCouponLimitReached):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func couponErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrInvalidCoupon):
+		return http.StatusBadRequest
+	case errors.Is(err, domain.ErrCouponNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrCouponCodeTaken):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func (h *PaymentHandler) CreateCoupon(w http.ResponseWriter, r *http.Request) {
+	producerID, ok := r.Context().Value(middleware.ContextKeyUserId).(uuid.UUID)
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var input application.CouponInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	coupon, err := h.service.CreateCoupon(r.Context(), producerID, input)
+	if err != nil {
+		statusCode := couponErrorStatus(err)
+		if statusCode == http.StatusInternalServerError {
+			log.Printf("PaymentHandler.CreateCoupon failed: %v", err)
+			http.Error(w, "failed to create coupon", statusCode)
+			return
+		}
+		http.Error(w, err.Error(), statusCode)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(coupon)
+}
+
+func (h *PaymentHandler) ListCoupons(w http.ResponseWriter, r *http.Request) {
+	producerID, ok := r.Context().Value(middleware.ContextKeyUserId).(uuid.UUID)
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	coupons, err := h.service.ListCoupons(r.Context(), producerID)
+	if err != nil {
+		log.Printf("PaymentHandler.ListCoupons failed: %v", err)
+		http.Error(w, "failed to fetch coupons", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{"coupons": coupons})
+}
+
+func (h *PaymentHandler) UpdateCoupon(w http.ResponseWriter, r *http.Request) {
+	producerID, ok := r.Context().Value(middleware.ContextKeyUserId).(uuid.UUID)
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	couponID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "invalid coupon id", http.StatusBadRequest)
+		return
+	}
+
+	var input application.UpdateCouponInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	coupon, err := h.service.UpdateCoupon(r.Context(), producerID, couponID, input)
+	if err != nil {
+		statusCode := couponErrorStatus(err)
+		if statusCode == http.StatusInternalServerError {
+			log.Printf("PaymentHandler.UpdateCoupon failed. coupon_id=%s err=%v", couponID, err)
+			http.Error(w, "failed to update coupon", statusCode)
+			return
+		}
+		http.Error(w, err.Error(), statusCode)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(coupon)
+}
+
+func (h *PaymentHandler) DeleteCoupon(w http.ResponseWriter, r *http.Request) {
+	producerID, ok := r.Context().Value(middleware.ContextKeyUserId).(uuid.UUID)
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	couponID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "invalid coupon id", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.DeleteCoupon(r.Context(), producerID, couponID); err != nil {
+		statusCode := couponErrorStatus(err)
+		if statusCode == http.StatusInternalServerError {
+			log.Printf("PaymentHandler.DeleteCoupon failed. coupon_id=%s err=%v", couponID, err)
+			http.Error(w, "failed to delete coupon", statusCode)
+			return
+		}
+		http.Error(w, err.Error(), statusCode)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
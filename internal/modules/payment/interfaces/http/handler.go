@@ -2,6 +2,7 @@ package http
 
 import (
 	"encoding/json"
+	"errors"
 	"io"
 	"log"
 	"net/http"
@@ -10,6 +11,8 @@ import (
 	"github.com/google/uuid"
 	"github.com/saransh1220/blueprint-audio/internal/gateway/middleware"
 	"github.com/saransh1220/blueprint-audio/internal/modules/payment/application"
+	"github.com/saransh1220/blueprint-audio/internal/modules/payment/domain"
+	"github.com/saransh1220/blueprint-audio/internal/shared/clientinfo"
 	"github.com/saransh1220/blueprint-audio/internal/shared/money"
 )
 
@@ -38,6 +41,73 @@ func (h *PaymentHandler) DodoWebhook(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(map[string]bool{"received": true})
 }
 
+func (h *PaymentHandler) RazorpayWebhook(w http.ResponseWriter, r *http.Request) {
+	payload, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "invalid webhook body", http.StatusBadRequest)
+		return
+	}
+
+	headers := map[string]string{
+		"x-razorpay-signature": r.Header.Get("X-Razorpay-Signature"),
+		"x-razorpay-event-id":  r.Header.Get("X-Razorpay-Event-Id"),
+	}
+	if err := h.service.HandleRazorpayWebhook(r.Context(), payload, headers); err != nil {
+		log.Printf("PaymentHandler.RazorpayWebhook failed: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]bool{"received": true})
+}
+
+// RefundOrder lets a super admin refund a paid order through its provider.
+func (h *PaymentHandler) RefundOrder(w http.ResponseWriter, r *http.Request) {
+	actorID, ok := r.Context().Value(middleware.ContextKeyUserId).(uuid.UUID)
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	orderID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "invalid order_id", http.StatusBadRequest)
+		return
+	}
+
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+
+	refund, err := h.service.RefundOrder(r.Context(), orderID, application.RefundOrderInput{
+		ActorID:   actorID,
+		Reason:    req.Reason,
+		IPAddress: clientinfo.IP(r),
+		UserAgent: r.UserAgent(),
+	})
+	if err != nil {
+		statusCode := http.StatusBadGateway
+		switch {
+		case errors.Is(err, domain.ErrOrderNotFound), errors.Is(err, domain.ErrPaymentNotFound):
+			statusCode = http.StatusNotFound
+		case errors.Is(err, domain.ErrOrderNotRefundable), errors.Is(err, domain.ErrOrderAlreadyRefunded),
+			errors.Is(err, domain.ErrPartialProviderRefund):
+			statusCode = http.StatusConflict
+		}
+		log.Printf("PaymentHandler.RefundOrder failed. order_id=%s err=%v", orderID, err)
+		http.Error(w, err.Error(), statusCode)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(refund)
+}
+
 func NewPaymentHandler(service application.PaymentService) *PaymentHandler {
 	return &PaymentHandler{service: service}
 }
@@ -52,6 +122,7 @@ func (h *PaymentHandler) CreateOrder(w http.ResponseWriter, r *http.Request) {
 	var req struct {
 		SpecID          string `json:"spec_id"`
 		LicenseOptionID string `json:"license_option_id"`
+		CouponCode      string `json:"coupon_code"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, "invalid request body", http.StatusBadRequest)
@@ -69,9 +140,9 @@ func (h *PaymentHandler) CreateOrder(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	// Create order via service
-	order, err := h.service.CreateOrder(r.Context(), userID, specID, licenseOptionID, money.ResolveCurrencyFromRequest(r))
+	order, err := h.service.CreateOrder(r.Context(), userID, specID, licenseOptionID, money.ResolveCurrencyFromRequest(r), req.CouponCode)
 	if err != nil {
-		http.Error(w, "failed to create order: "+err.Error(), http.StatusInternalServerError)
+		http.Error(w, "failed to create order: "+err.Error(), orderErrorStatus(err))
 		return
 	}
 	// Return order
@@ -105,8 +176,8 @@ func (h *PaymentHandler) VerifyPayment(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Verify payment and issue license
-	license, err := h.service.VerifyPayment(
+	// Verify payment and issue licenses
+	licenses, err := h.service.VerifyPayment(
 		r.Context(),
 		orderID,
 		req.RazorpayPaymentID,
@@ -117,12 +188,13 @@ func (h *PaymentHandler) VerifyPayment(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Return success with license
+	// Return success with licenses; "license" stays for single-item clients
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
-		"success": true,
-		"license": license,
-		"message": "Payment successful! License issued.",
+		"success":  true,
+		"license":  licenses[0],
+		"licenses": licenses,
+		"message":  "Payment successful! License issued.",
 	})
 }
 
@@ -18,20 +18,35 @@ import (
 )
 
 type mockPaymentService struct {
-	createOrderFn       func(context.Context, uuid.UUID, uuid.UUID, uuid.UUID) (*domain.Order, error)
+	createOrderFn       func(context.Context, uuid.UUID, uuid.UUID, uuid.UUID, string) (*domain.Order, error)
 	dodoWebhookFn       func(context.Context, []byte, map[string]string) error
-	verifyFn            func(context.Context, uuid.UUID, string, string) (*domain.License, error)
+	verifyFn            func(context.Context, uuid.UUID, string, string) ([]domain.License, error)
 	getOrderFn          func(context.Context, uuid.UUID) (*domain.Order, error)
 	getUserOrdersFn     func(context.Context, uuid.UUID, int) ([]domain.Order, error)
 	getUserLicensesFn   func(context.Context, uuid.UUID, int, string, string) ([]domain.License, int, error)
 	getDownloadsFn      func(context.Context, uuid.UUID, uuid.UUID) (*application.LicenseDownloadsResponse, error)
 	getProducerOrdersFn func(context.Context, uuid.UUID, int, int) (*application.ProducerOrderResponse, error)
+	razorpayWebhookFn   func(context.Context, []byte, map[string]string) error
+	refundOrderFn       func(context.Context, uuid.UUID, application.RefundOrderInput) (*domain.Refund, error)
+	getCartFn           func(context.Context, uuid.UUID, string) (*application.CartResponse, error)
+	addToCartFn         func(context.Context, uuid.UUID, uuid.UUID, uuid.UUID) (*application.CartResponse, error)
+	removeFromCartFn    func(context.Context, uuid.UUID, uuid.UUID) (*application.CartResponse, error)
+	clearCartFn         func(context.Context, uuid.UUID) error
+	checkoutFn          func(context.Context, uuid.UUID, string, string) (*domain.Order, error)
+	createCouponFn      func(context.Context, uuid.UUID, application.CouponInput) (*domain.Coupon, error)
+	listCouponsFn       func(context.Context, uuid.UUID) ([]domain.Coupon, error)
+	updateCouponFn      func(context.Context, uuid.UUID, uuid.UUID, application.UpdateCouponInput) (*domain.Coupon, error)
+	deleteCouponFn      func(context.Context, uuid.UUID, uuid.UUID) error
+	getAgreementFn      func(context.Context, uuid.UUID, uuid.UUID) (*application.LicenseAgreement, error)
+	getTemplateFn       func(context.Context, uuid.UUID, uuid.UUID) (*domain.AgreementTemplate, error)
+	updateTemplateFn    func(context.Context, uuid.UUID, uuid.UUID, application.AgreementTemplateInput) (*domain.AgreementTemplate, error)
+	verifyLicenseFn     func(context.Context, string) (*application.LicenseVerification, error)
 }
 
-func (m mockPaymentService) CreateOrder(ctx context.Context, u, s, l uuid.UUID, c string) (*domain.Order, error) {
-	return m.createOrderFn(ctx, u, s, l)
+func (m mockPaymentService) CreateOrder(ctx context.Context, u, s, l uuid.UUID, c, coupon string) (*domain.Order, error) {
+	return m.createOrderFn(ctx, u, s, l, coupon)
 }
-func (m mockPaymentService) VerifyPayment(ctx context.Context, o uuid.UUID, p, sig string) (*domain.License, error) {
+func (m mockPaymentService) VerifyPayment(ctx context.Context, o uuid.UUID, p, sig string) ([]domain.License, error) {
 	return m.verifyFn(ctx, o, p, sig)
 }
 func (m mockPaymentService) HandleDodoWebhook(ctx context.Context, payload []byte, headers map[string]string) error {
@@ -56,6 +71,55 @@ func (m mockPaymentService) GetProducerOrders(ctx context.Context, u uuid.UUID,
 	return m.getProducerOrdersFn(ctx, u, p, l)
 }
 
+func (m mockPaymentService) HandleRazorpayWebhook(ctx context.Context, payload []byte, headers map[string]string) error {
+	if m.razorpayWebhookFn != nil {
+		return m.razorpayWebhookFn(ctx, payload, headers)
+	}
+	return nil
+}
+func (m mockPaymentService) RefundOrder(ctx context.Context, o uuid.UUID, in application.RefundOrderInput) (*domain.Refund, error) {
+	return m.refundOrderFn(ctx, o, in)
+}
+func (m mockPaymentService) GetCart(ctx context.Context, u uuid.UUID, c string) (*application.CartResponse, error) {
+	return m.getCartFn(ctx, u, c)
+}
+func (m mockPaymentService) AddToCart(ctx context.Context, u, s, l uuid.UUID, c string) (*application.CartResponse, error) {
+	return m.addToCartFn(ctx, u, s, l)
+}
+func (m mockPaymentService) RemoveFromCart(ctx context.Context, u, s uuid.UUID, c string) (*application.CartResponse, error) {
+	return m.removeFromCartFn(ctx, u, s)
+}
+func (m mockPaymentService) ClearCart(ctx context.Context, u uuid.UUID) error {
+	return m.clearCartFn(ctx, u)
+}
+func (m mockPaymentService) Checkout(ctx context.Context, u uuid.UUID, c, coupon string) (*domain.Order, error) {
+	return m.checkoutFn(ctx, u, c, coupon)
+}
+func (m mockPaymentService) CreateCoupon(ctx context.Context, p uuid.UUID, in application.CouponInput) (*domain.Coupon, error) {
+	return m.createCouponFn(ctx, p, in)
+}
+func (m mockPaymentService) ListCoupons(ctx context.Context, p uuid.UUID) ([]domain.Coupon, error) {
+	return m.listCouponsFn(ctx, p)
+}
+func (m mockPaymentService) UpdateCoupon(ctx context.Context, p, c uuid.UUID, in application.UpdateCouponInput) (*domain.Coupon, error) {
+	return m.updateCouponFn(ctx, p, c, in)
+}
+func (m mockPaymentService) DeleteCoupon(ctx context.Context, p, c uuid.UUID) error {
+	return m.deleteCouponFn(ctx, p, c)
+}
+func (m mockPaymentService) GetLicenseAgreement(ctx context.Context, l, u uuid.UUID) (*application.LicenseAgreement, error) {
+	return m.getAgreementFn(ctx, l, u)
+}
+func (m mockPaymentService) VerifyLicense(ctx context.Context, key string) (*application.LicenseVerification, error) {
+	return m.verifyLicenseFn(ctx, key)
+}
+func (m mockPaymentService) GetAgreementTemplate(ctx context.Context, p, o uuid.UUID) (*domain.AgreementTemplate, error) {
+	return m.getTemplateFn(ctx, p, o)
+}
+func (m mockPaymentService) UpdateAgreementTemplate(ctx context.Context, p, o uuid.UUID, input application.AgreementTemplateInput) (*domain.AgreementTemplate, error) {
+	return m.updateTemplateFn(ctx, p, o, input)
+}
+
 func authedReq(method, path, body string) *http.Request {
 	r := httptest.NewRequest(method, path, strings.NewReader(body))
 	ctx := context.WithValue(r.Context(), middleware.ContextKeyUserId, uuid.New())
@@ -64,11 +128,11 @@ func authedReq(method, path, body string) *http.Request {
 
 func TestPaymentHandler_BasicFlows(t *testing.T) {
 	h := paymenthttp.NewPaymentHandler(mockPaymentService{
-		createOrderFn: func(context.Context, uuid.UUID, uuid.UUID, uuid.UUID) (*domain.Order, error) {
+		createOrderFn: func(context.Context, uuid.UUID, uuid.UUID, uuid.UUID, string) (*domain.Order, error) {
 			return &domain.Order{ID: uuid.New()}, nil
 		},
-		verifyFn: func(context.Context, uuid.UUID, string, string) (*domain.License, error) {
-			return &domain.License{ID: uuid.New()}, nil
+		verifyFn: func(context.Context, uuid.UUID, string, string) ([]domain.License, error) {
+			return []domain.License{{ID: uuid.New()}, {ID: uuid.New()}}, nil
 		},
 		getOrderFn: func(context.Context, uuid.UUID) (*domain.Order, error) {
 			return &domain.Order{ID: uuid.New()}, nil
@@ -98,6 +162,13 @@ func TestPaymentHandler_BasicFlows(t *testing.T) {
 	w = httptest.NewRecorder()
 	h.VerifyPayment(w, authedReq(http.MethodPost, "/verify", `{"order_id":"`+orderID+`","razorpay_payment_id":"p","razorpay_signature":"s"}`))
 	require.Equal(t, http.StatusOK, w.Code)
+	var verified struct {
+		License  domain.License   `json:"license"`
+		Licenses []domain.License `json:"licenses"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &verified))
+	require.Len(t, verified.Licenses, 2)
+	require.Equal(t, verified.Licenses[0].ID, verified.License.ID)
 
 	w = httptest.NewRecorder()
 	r := authedReq(http.MethodGet, "/orders/"+orderID, "")
@@ -128,10 +199,10 @@ func TestPaymentHandler_BasicFlows(t *testing.T) {
 
 func TestPaymentHandler_ErrorBranches(t *testing.T) {
 	h := paymenthttp.NewPaymentHandler(mockPaymentService{
-		createOrderFn: func(context.Context, uuid.UUID, uuid.UUID, uuid.UUID) (*domain.Order, error) {
+		createOrderFn: func(context.Context, uuid.UUID, uuid.UUID, uuid.UUID, string) (*domain.Order, error) {
 			return nil, errors.New("x")
 		},
-		verifyFn: func(context.Context, uuid.UUID, string, string) (*domain.License, error) {
+		verifyFn: func(context.Context, uuid.UUID, string, string) ([]domain.License, error) {
 			return nil, errors.New("bad")
 		},
 		getOrderFn:      func(context.Context, uuid.UUID) (*domain.Order, error) { return nil, errors.New("nf") },
@@ -189,3 +260,341 @@ func TestPaymentHandler_ErrorBranches(t *testing.T) {
 	h.GetProducerOrders(w, authedReq(http.MethodGet, "/producer/orders", ""))
 	require.Equal(t, http.StatusInternalServerError, w.Code)
 }
+
+func TestPaymentHandler_RazorpayWebhookPassesSignatureHeaders(t *testing.T) {
+	var gotHeaders map[string]string
+	h := paymenthttp.NewPaymentHandler(mockPaymentService{
+		razorpayWebhookFn: func(_ context.Context, payload []byte, headers map[string]string) error {
+			gotHeaders = headers
+			if string(payload) != `{"event":"refund.processed"}` {
+				return errors.New("invalid razorpay webhook signature")
+			}
+			return nil
+		},
+	})
+
+	r := httptest.NewRequest(http.MethodPost, "/webhooks/razorpay", strings.NewReader(`{"event":"refund.processed"}`))
+	r.Header.Set("X-Razorpay-Signature", "sig")
+	r.Header.Set("X-Razorpay-Event-Id", "evt_1")
+	w := httptest.NewRecorder()
+	h.RazorpayWebhook(w, r)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "sig", gotHeaders["x-razorpay-signature"])
+	require.Equal(t, "evt_1", gotHeaders["x-razorpay-event-id"])
+
+	w = httptest.NewRecorder()
+	h.RazorpayWebhook(w, httptest.NewRequest(http.MethodPost, "/webhooks/razorpay", strings.NewReader(`{}`)))
+	require.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestPaymentHandler_RefundOrder(t *testing.T) {
+	orderID := uuid.New()
+	h := paymenthttp.NewPaymentHandler(mockPaymentService{
+		refundOrderFn: func(_ context.Context, id uuid.UUID, in application.RefundOrderInput) (*domain.Refund, error) {
+			switch in.Reason {
+			case "paid":
+				return &domain.Refund{OrderID: id, Kind: domain.RefundKindRefund}, nil
+			case "pending":
+				return nil, domain.ErrOrderNotRefundable
+			case "missing":
+				return nil, domain.ErrOrderNotFound
+			default:
+				return nil, errors.New("razorpay refund failed")
+			}
+		},
+	})
+	refund := func(body string) *httptest.ResponseRecorder {
+		r := authedReq(http.MethodPost, "/admin/orders/"+orderID.String()+"/refund", body)
+		r.SetPathValue("id", orderID.String())
+		w := httptest.NewRecorder()
+		h.RefundOrder(w, r)
+		return w
+	}
+
+	w := refund(`{"reason":"paid"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	var got domain.Refund
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+	require.Equal(t, orderID, got.OrderID)
+
+	require.Equal(t, http.StatusConflict, refund(`{"reason":"pending"}`).Code)
+	require.Equal(t, http.StatusNotFound, refund(`{"reason":"missing"}`).Code)
+	require.Equal(t, http.StatusBadGateway, refund(`{"reason":"provider down"}`).Code)
+	require.Equal(t, http.StatusBadRequest, refund(`bad`).Code)
+
+	r := httptest.NewRequest(http.MethodPost, "/admin/orders/"+orderID.String()+"/refund", nil)
+	w = httptest.NewRecorder()
+	h.RefundOrder(w, r)
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestPaymentHandler_Cart(t *testing.T) {
+	specID := uuid.New()
+	h := paymenthttp.NewPaymentHandler(mockPaymentService{
+		getCartFn: func(_ context.Context, _ uuid.UUID, currency string) (*application.CartResponse, error) {
+			return &application.CartResponse{Items: []application.CartItemDto{}, Currency: currency}, nil
+		},
+		addToCartFn: func(_ context.Context, _ uuid.UUID, s uuid.UUID, _ uuid.UUID) (*application.CartResponse, error) {
+			if s != specID {
+				return nil, domain.ErrCartFull
+			}
+			return &application.CartResponse{Items: []application.CartItemDto{{SpecID: s, Available: true}}}, nil
+		},
+		removeFromCartFn: func(_ context.Context, _ uuid.UUID, s uuid.UUID) (*application.CartResponse, error) {
+			if s != specID {
+				return nil, domain.ErrCartItemNotFound
+			}
+			return &application.CartResponse{Items: []application.CartItemDto{}}, nil
+		},
+		clearCartFn: func(context.Context, uuid.UUID) error { return nil },
+		checkoutFn: func(_ context.Context, _ uuid.UUID, currency, _ string) (*domain.Order, error) {
+			switch currency {
+			case "USD":
+				return nil, domain.ErrCartEmpty
+			default:
+				return nil, errors.Join(domain.ErrCartItemUnavailable, errors.New("spec deleted"))
+			}
+		},
+	})
+
+	w := httptest.NewRecorder()
+	h.GetCart(w, authedReq(http.MethodGet, "/cart", ""))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	h.AddCartItem(w, authedReq(http.MethodPost, "/cart/items", `{"spec_id":"`+specID.String()+`","license_option_id":"`+uuid.NewString()+`"}`))
+	require.Equal(t, http.StatusOK, w.Code)
+	var cart application.CartResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &cart))
+	require.Len(t, cart.Items, 1)
+
+	w = httptest.NewRecorder()
+	h.AddCartItem(w, authedReq(http.MethodPost, "/cart/items", `{"spec_id":"`+uuid.NewString()+`","license_option_id":"`+uuid.NewString()+`"}`))
+	require.Equal(t, http.StatusConflict, w.Code)
+
+	w = httptest.NewRecorder()
+	h.AddCartItem(w, authedReq(http.MethodPost, "/cart/items", `{"spec_id":"bad"}`))
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	remove := func(id string) *httptest.ResponseRecorder {
+		r := authedReq(http.MethodDelete, "/cart/items/"+id, "")
+		r.SetPathValue("spec_id", id)
+		w := httptest.NewRecorder()
+		h.RemoveCartItem(w, r)
+		return w
+	}
+	require.Equal(t, http.StatusOK, remove(specID.String()).Code)
+	require.Equal(t, http.StatusNotFound, remove(uuid.NewString()).Code)
+	require.Equal(t, http.StatusBadRequest, remove("bad").Code)
+
+	w = httptest.NewRecorder()
+	h.ClearCart(w, authedReq(http.MethodDelete, "/cart", ""))
+	require.Equal(t, http.StatusNoContent, w.Code)
+
+	w = httptest.NewRecorder()
+	h.Checkout(w, authedReq(http.MethodPost, "/cart/checkout", ""))
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	r := authedReq(http.MethodPost, "/cart/checkout", "")
+	r.Header.Set("CF-IPCountry", "IN")
+	h.Checkout(w, r)
+	require.Equal(t, http.StatusConflict, w.Code)
+
+	w = httptest.NewRecorder()
+	h.GetCart(w, httptest.NewRequest(http.MethodGet, "/cart", nil))
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestPaymentHandler_Coupons(t *testing.T) {
+	couponID := uuid.New()
+	h := paymenthttp.NewPaymentHandler(mockPaymentService{
+		createOrderFn: func(_ context.Context, _, _, _ uuid.UUID, couponCode string) (*domain.Order, error) {
+			switch couponCode {
+			case "":
+				return &domain.Order{ID: uuid.New()}, nil
+			case "GONE":
+				return nil, domain.ErrCouponExhausted
+			default:
+				return nil, domain.ErrCouponNotApplicable
+			}
+		},
+		createCouponFn: func(_ context.Context, _ uuid.UUID, in application.CouponInput) (*domain.Coupon, error) {
+			switch in.Code {
+			case "TAKEN":
+				return nil, domain.ErrCouponCodeTaken
+			case "":
+				return nil, errors.Join(domain.ErrInvalidCoupon, errors.New("code required"))
+			}
+			return &domain.Coupon{ID: couponID, Code: in.Code, DiscountType: in.DiscountType, PercentOff: in.PercentOff}, nil
+		},
+		listCouponsFn: func(context.Context, uuid.UUID) ([]domain.Coupon, error) {
+			return []domain.Coupon{{ID: couponID, Code: "WEEKEND20", Redemptions: 3}}, nil
+		},
+		updateCouponFn: func(_ context.Context, _, id uuid.UUID, in application.UpdateCouponInput) (*domain.Coupon, error) {
+			if id != couponID {
+				return nil, domain.ErrCouponNotFound
+			}
+			return &domain.Coupon{ID: id, IsActive: *in.IsActive}, nil
+		},
+		deleteCouponFn: func(_ context.Context, _, id uuid.UUID) error {
+			if id != couponID {
+				return domain.ErrCouponNotFound
+			}
+			return nil
+		},
+	})
+
+	orderBody := func(couponCode string) string {
+		return `{"spec_id":"` + uuid.NewString() + `","license_option_id":"` + uuid.NewString() + `","coupon_code":"` + couponCode + `"}`
+	}
+	w := httptest.NewRecorder()
+	h.CreateOrder(w, authedReq(http.MethodPost, "/orders", orderBody("GONE")))
+	require.Equal(t, http.StatusConflict, w.Code)
+	w = httptest.NewRecorder()
+	h.CreateOrder(w, authedReq(http.MethodPost, "/orders", orderBody("OTHER")))
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	h.CreateCoupon(w, authedReq(http.MethodPost, "/coupons", `{"code":"WEEKEND20","discount_type":"percentage","percent_off":20}`))
+	require.Equal(t, http.StatusCreated, w.Code)
+	var coupon domain.Coupon
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &coupon))
+	require.Equal(t, "WEEKEND20", coupon.Code)
+	require.Equal(t, 20, *coupon.PercentOff)
+
+	w = httptest.NewRecorder()
+	h.CreateCoupon(w, authedReq(http.MethodPost, "/coupons", `{"code":"TAKEN"}`))
+	require.Equal(t, http.StatusConflict, w.Code)
+	w = httptest.NewRecorder()
+	h.CreateCoupon(w, authedReq(http.MethodPost, "/coupons", `{"code":""}`))
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	h.ListCoupons(w, authedReq(http.MethodGet, "/coupons", ""))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Contains(t, w.Body.String(), `"redemptions":3`)
+
+	update := func(id string) *httptest.ResponseRecorder {
+		r := authedReq(http.MethodPatch, "/coupons/"+id, `{"is_active":false}`)
+		r.SetPathValue("id", id)
+		w := httptest.NewRecorder()
+		h.UpdateCoupon(w, r)
+		return w
+	}
+	require.Equal(t, http.StatusOK, update(couponID.String()).Code)
+	require.Equal(t, http.StatusNotFound, update(uuid.NewString()).Code)
+	require.Equal(t, http.StatusBadRequest, update("bad").Code)
+
+	remove := func(id string) *httptest.ResponseRecorder {
+		r := authedReq(http.MethodDelete, "/coupons/"+id, "")
+		r.SetPathValue("id", id)
+		w := httptest.NewRecorder()
+		h.DeleteCoupon(w, r)
+		return w
+	}
+	require.Equal(t, http.StatusNoContent, remove(couponID.String()).Code)
+	require.Equal(t, http.StatusNotFound, remove(uuid.NewString()).Code)
+
+	w = httptest.NewRecorder()
+	h.ListCoupons(w, httptest.NewRequest(http.MethodGet, "/coupons", nil))
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestPaymentHandler_Agreements(t *testing.T) {
+	licenseID, optionID := uuid.New(), uuid.New()
+	h := paymenthttp.NewPaymentHandler(mockPaymentService{
+		getAgreementFn: func(_ context.Context, id, _ uuid.UUID) (*application.LicenseAgreement, error) {
+			switch id {
+			case licenseID:
+				return &application.LicenseAgreement{Filename: "license-agreement-LIC-1.pdf", Content: []byte("%PDF-1.4")}, nil
+			case optionID:
+				return nil, domain.ErrLicenseRevoked
+			}
+			return nil, domain.ErrLicenseNotFound
+		},
+		getTemplateFn: func(_ context.Context, _, id uuid.UUID) (*domain.AgreementTemplate, error) {
+			if id != optionID {
+				return nil, domain.ErrLicenseOptionNotFound
+			}
+			return &domain.AgreementTemplate{LicenseOptionID: id, CreditRequired: true, Terms: "Terms"}, nil
+		},
+		updateTemplateFn: func(_ context.Context, _, id uuid.UUID, in application.AgreementTemplateInput) (*domain.AgreementTemplate, error) {
+			if in.Terms == "" {
+				return nil, errors.Join(domain.ErrInvalidAgreementTemplate, errors.New("terms are required"))
+			}
+			return &domain.AgreementTemplate{LicenseOptionID: id, StreamLimit: in.StreamLimit, Terms: in.Terms, IsCustom: true}, nil
+		},
+	})
+
+	download := func(id string) *httptest.ResponseRecorder {
+		r := authedReq(http.MethodGet, "/licenses/"+id+"/agreement", "")
+		r.SetPathValue("id", id)
+		w := httptest.NewRecorder()
+		h.GetLicenseAgreement(w, r)
+		return w
+	}
+	w := download(licenseID.String())
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "application/pdf", w.Header().Get("Content-Type"))
+	require.Equal(t, `attachment; filename="license-agreement-LIC-1.pdf"`, w.Header().Get("Content-Disposition"))
+	require.Equal(t, "%PDF-1.4", w.Body.String())
+	require.Equal(t, http.StatusForbidden, download(optionID.String()).Code)
+	require.Equal(t, http.StatusNotFound, download(uuid.NewString()).Code)
+	require.Equal(t, http.StatusBadRequest, download("bad").Code)
+
+	get := func(id string) *httptest.ResponseRecorder {
+		r := authedReq(http.MethodGet, "/license-options/"+id+"/agreement", "")
+		r.SetPathValue("id", id)
+		w := httptest.NewRecorder()
+		h.GetAgreementTemplate(w, r)
+		return w
+	}
+	w = get(optionID.String())
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Contains(t, w.Body.String(), `"stream_limit":null`)
+	require.Equal(t, http.StatusNotFound, get(uuid.NewString()).Code)
+
+	put := func(body string) *httptest.ResponseRecorder {
+		r := authedReq(http.MethodPut, "/license-options/"+optionID.String()+"/agreement", body)
+		r.SetPathValue("id", optionID.String())
+		w := httptest.NewRecorder()
+		h.UpdateAgreementTemplate(w, r)
+		return w
+	}
+	w = put(`{"stream_limit":5000,"credit_required":true,"terms":"For {{buyer_name}}"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Contains(t, w.Body.String(), `"stream_limit":5000`)
+	require.Equal(t, http.StatusBadRequest, put(`{"terms":""}`).Code)
+	require.Equal(t, http.StatusBadRequest, put(`{`).Code)
+}
+
+func TestPaymentHandler_VerifyLicense(t *testing.T) {
+	h := paymenthttp.NewPaymentHandler(mockPaymentService{
+		verifyLicenseFn: func(_ context.Context, key string) (*application.LicenseVerification, error) {
+			switch key {
+			case "LIC-1":
+				return &application.LicenseVerification{LicenseKey: key, SpecTitle: "Midnight Drive", ProducerName: "Night Owl", LicenseType: "Basic", Status: application.LicenseStatusActive}, nil
+			case "LIC-ERR":
+				return nil, errors.New("db down")
+			}
+			return nil, domain.ErrLicenseNotFound
+		},
+	})
+
+	verify := func(key string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/verify/licenses/"+key, nil)
+		r.SetPathValue("licenseKey", key)
+		w := httptest.NewRecorder()
+		h.VerifyLicense(w, r)
+		return w
+	}
+	w := verify("LIC-1")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+	require.Contains(t, w.Body.String(), `"status":"active"`)
+	require.Contains(t, w.Body.String(), `"producer_name":"Night Owl"`)
+	require.NotContains(t, w.Body.String(), "revoked_at")
+	require.Equal(t, http.StatusNotFound, verify("LIC-2").Code)
+	require.Equal(t, http.StatusInternalServerError, verify("LIC-ERR").Code)
+}
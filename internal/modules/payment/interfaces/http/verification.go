@@ -0,0 +1,31 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/saransh1220/blueprint-audio/internal/modules/payment/domain"
+)
+
+// VerifyLicense is public: anyone holding a license key, such as a
+// distributor reading an agreement, can check the license's status.
+func (h *PaymentHandler) VerifyLicense(w http.ResponseWriter, r *http.Request) {
+	licenseKey := r.PathValue("licenseKey")
+
+	verification, err := h.service.VerifyLicense(r.Context(), licenseKey)
+	if err != nil {
+		if errors.Is(err, domain.ErrLicenseNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		log.Printf("PaymentHandler.VerifyLicense failed. license_key=%s err=%v", licenseKey, err)
+		http.Error(w, "failed to verify license", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(verification)
+}
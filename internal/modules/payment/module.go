@@ -22,15 +22,23 @@ func NewModule(
 	specFinder catalogDomain.SpecFinder,
 	userFinder authDomain.UserFinder,
 	fileService application.FileService,
+	notifier application.Notifier,
+	earnings application.EarningsRecorder,
 	emailSender sharedemail.Sender,
 	appBaseURL string,
+	apiBaseURL string,
 	dodoConfig application.DodoConfig,
 ) *Module {
 	orderRepo := persistence.NewOrderRepository(db)
 	paymentRepo := persistence.NewPaymentRepository(db)
 	licenseRepo := persistence.NewLicenseRepository(db)
+	refundRepo := persistence.NewRefundRepository(db)
+	webhookEventRepo := persistence.NewWebhookEventRepository(db)
+	cartRepo := persistence.NewCartRepository(db)
+	couponRepo := persistence.NewCouponRepository(db)
+	agreementTemplateRepo := persistence.NewAgreementTemplateRepository(db)
 
-	service := application.NewPaymentService(orderRepo, paymentRepo, licenseRepo, specFinder, userFinder, fileService, emailSender, appBaseURL, dodoConfig)
+	service := application.NewPaymentService(orderRepo, paymentRepo, licenseRepo, refundRepo, webhookEventRepo, cartRepo, couponRepo, agreementTemplateRepo, specFinder, userFinder, fileService, notifier, earnings, emailSender, appBaseURL, apiBaseURL, dodoConfig)
 	handler := paymentHttp.NewPaymentHandler(service)
 
 	return &Module{
@@ -2,6 +2,7 @@ package payment
 
 import (
 	"context"
+	"io"
 	"testing"
 	"time"
 
@@ -36,6 +37,12 @@ func (nilFileService) GetKeyFromUrl(_ string) (string, error) { return "", nil }
 func (nilFileService) GetPresignedURL(_ context.Context, _ string, _ time.Duration) (string, error) {
 	return "", nil
 }
+func (nilFileService) UploadWithKey(_ context.Context, _ io.Reader, _ string, _ string) (string, error) {
+	return "", nil
+}
+func (nilFileService) OpenObject(_ context.Context, _ string) (io.ReadCloser, error) {
+	return nil, nil
+}
 
 type nilUserFinder struct{}
 
@@ -45,7 +52,7 @@ func (nilUserFinder) FindByID(_ context.Context, id uuid.UUID) (*authDomain.User
 func (nilUserFinder) Exists(_ context.Context, _ uuid.UUID) (bool, error) { return true, nil }
 
 func TestModuleAccessors(t *testing.T) {
-	m := NewModule(&sqlx.DB{}, nilSpecFinder{}, nilUserFinder{}, nilFileService{}, sharedemail.NewSender(sharedemail.Config{}), "http://localhost:4200", application.DodoConfig{})
+	m := NewModule(&sqlx.DB{}, nilSpecFinder{}, nilUserFinder{}, nilFileService{}, nil, nil, sharedemail.NewSender(sharedemail.Config{}), "http://localhost:4200", "http://localhost:8080", application.DodoConfig{})
 	require.NotNil(t, m)
 	require.NotNil(t, m.HTTPHandler())
 }
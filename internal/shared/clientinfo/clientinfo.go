@@ -0,0 +1,66 @@
+// Package clientinfo reads where a request came from, as reported by the
+// proxies in front of the API, in a form that fits the columns it is stored in.
+package clientinfo
+
+import (
+	"net"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+)
+
+// Lengths of the sessions columns the values are stored in.
+const (
+	MaxUserAgentLength = 512
+	MaxLocationLength  = 255
+)
+
+// IP returns the client address from the first X-Forwarded-For entry, falling
+// back to the connection's remote address. Values that are not an IP address
+// are ignored, so it returns "" when neither holds one.
+func IP(r *http.Request) string {
+	if forwarded := strings.TrimSpace(r.Header.Get("X-Forwarded-For")); forwarded != "" {
+		if ip := net.ParseIP(strings.TrimSpace(strings.Split(forwarded, ",")[0])); ip != nil {
+			return ip.String()
+		}
+	}
+	host := r.RemoteAddr
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		return ip.String()
+	}
+	return ""
+}
+
+// UserAgent returns the request's user agent cut to MaxUserAgentLength
+// characters; user agents are client controlled.
+func UserAgent(r *http.Request) string {
+	return truncate(r.UserAgent(), MaxUserAgentLength)
+}
+
+// Location uses the geolocation headers Cloudflare adds in front of the API,
+// such as "Mumbai, Maharashtra, IN", cut to MaxLocationLength characters.
+func Location(r *http.Request) string {
+	var parts []string
+	for _, header := range []string{"CF-IPCity", "CF-Region", "CF-IPCountry"} {
+		value := strings.TrimSpace(r.Header.Get(header))
+		// Cloudflare reports XX for unknown and T1 for Tor.
+		if value == "" || value == "XX" || value == "T1" {
+			continue
+		}
+		parts = append(parts, value)
+	}
+	return truncate(strings.Join(parts, ", "), MaxLocationLength)
+}
+
+// truncate cuts s to at most n characters without splitting one, dropping
+// invalid UTF-8 that the database would reject.
+func truncate(s string, n int) string {
+	s = strings.ToValidUTF8(s, "")
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n])
+}
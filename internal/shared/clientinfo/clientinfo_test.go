@@ -0,0 +1,62 @@
+package clientinfo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIP(t *testing.T) {
+	tests := []struct {
+		name       string
+		forwarded  string
+		remoteAddr string
+		want       string
+	}{
+		{"first forwarded entry", "198.51.100.4, 10.0.0.1", "10.0.0.1:443", "198.51.100.4"},
+		{"forwarded ipv6", " 2001:db8::1 ", "10.0.0.1:443", "2001:db8::1"},
+		{"remote address with port", "", "203.0.113.7:5123", "203.0.113.7"},
+		{"remote address without port", "", "203.0.113.7", "203.0.113.7"},
+		{"invalid forwarded falls back", strings.Repeat("a", 200), "203.0.113.7:5123", "203.0.113.7"},
+		{"nothing valid", "not-an-ip", "pipe", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = tt.remoteAddr
+			if tt.forwarded != "" {
+				r.Header.Set("X-Forwarded-For", tt.forwarded)
+			}
+			assert.Equal(t, tt.want, IP(r))
+		})
+	}
+}
+
+func TestUserAgent(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("User-Agent", "curl/8.0")
+	assert.Equal(t, "curl/8.0", UserAgent(r))
+
+	r.Header.Set("User-Agent", strings.Repeat("ü", 600))
+	assert.Equal(t, strings.Repeat("ü", MaxUserAgentLength), UserAgent(r))
+}
+
+func TestLocation(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("CF-IPCity", "Mumbai")
+	r.Header.Set("CF-Region", "Maharashtra")
+	r.Header.Set("CF-IPCountry", "IN")
+	assert.Equal(t, "Mumbai, Maharashtra, IN", Location(r))
+
+	r.Header.Set("CF-IPCity", "")
+	r.Header.Set("CF-IPCountry", "XX")
+	assert.Equal(t, "Maharashtra", Location(r))
+
+	r.Header.Set("CF-IPCity", strings.Repeat("é", 300))
+	location := Location(r)
+	assert.Equal(t, MaxLocationLength, len([]rune(location)))
+	assert.Equal(t, strings.Repeat("é", MaxLocationLength), location)
+}
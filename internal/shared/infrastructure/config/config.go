@@ -3,7 +3,9 @@ package config
 import (
 	"bufio"
 	"log"
+	"math"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
@@ -12,20 +14,42 @@ import (
 
 // Config holds all configuration for the application
 type Config struct {
-	Server      ServerConfig
-	Database    database.PostgresConfig
-	Migration   MigrationConfig
-	Bootstrap   BootstrapConfig
-	Redis       database.RedisConfig
-	JWT         JWTConfig
-	Razorpay    RazorpayConfig
-	Dodo        DodoPaymentsConfig
-	Currency    CurrencyConfig
-	FileStorage FileStorageConfig
-	Google      GoogleConfig
-	Email       EmailConfig
-	Worker      WorkerConfig
-	AppBaseURL  string
+	Server       ServerConfig
+	Database     database.PostgresConfig
+	Migration    MigrationConfig
+	Bootstrap    BootstrapConfig
+	Redis        database.RedisConfig
+	JWT          JWTConfig
+	Razorpay     RazorpayConfig
+	Dodo         DodoPaymentsConfig
+	Currency     CurrencyConfig
+	FileStorage  FileStorageConfig
+	Google       GoogleConfig
+	Email        EmailConfig
+	Worker       WorkerConfig
+	Earnings     EarningsConfig
+	Notification NotificationConfig
+	AppBaseURL   string
+	APIBaseURL   string
+}
+
+// EarningsConfig holds the producer earnings ledger configuration
+type EarningsConfig struct {
+	// PlatformFeeBPS is the platform commission in basis points (1000 = 10%).
+	PlatformFeeBPS    int
+	ClearanceDays     int
+	ReconcileInterval time.Duration
+}
+
+// NotificationConfig holds notification delivery configuration
+type NotificationConfig struct {
+	// DigestInterval is how often due daily digests are looked for.
+	DigestInterval time.Duration
+	// VAPID keys sign Web Push messages; push is disabled without a
+	// private key. The subject is a mailto: or https: contact URL.
+	VAPIDPublicKey  string
+	VAPIDPrivateKey string
+	VAPIDSubject    string
 }
 
 // WorkerConfig holds media processor worker configuration
@@ -34,6 +58,12 @@ type WorkerConfig struct {
 	ID            string
 	PollInterval  time.Duration
 	LeaseDuration time.Duration
+	// SchedulerEnabled runs the scheduled background jobs alongside the
+	// upload worker. Each job runs on one replica at a time.
+	SchedulerEnabled bool
+	// MetricsAddr is where the standalone worker serves /metrics; empty
+	// disables it.
+	MetricsAddr string
 }
 
 // GoogleConfig holds Google OAuth configuration
@@ -62,6 +92,10 @@ type JWTConfig struct {
 	Secret        string
 	Expiry        time.Duration
 	RefreshExpiry time.Duration
+	// SessionCacheTTL is how long a session is known to be active before
+	// it is checked again, bounding how long a revocation takes to reach
+	// replicas without Redis.
+	SessionCacheTTL time.Duration
 }
 
 // RazorpayConfig holds Razorpay payment gateway configuration
@@ -78,7 +112,13 @@ type DodoPaymentsConfig struct {
 }
 
 type CurrencyConfig struct {
+	// INRUSDRate converts INR prices to USD until exchange rates are loaded.
 	INRUSDRate string
+	// RatesFile is a JSON file of exchange rates; without one only rates
+	// stored earlier are used.
+	RatesFile string
+	// RefreshInterval is how often the rates file is re-read.
+	RefreshInterval time.Duration
 }
 
 // FileStorageConfig holds file storage configuration
@@ -140,9 +180,10 @@ func Load() Config {
 			DB:       0,
 		},
 		JWT: JWTConfig{
-			Secret:        getEnv("JWT_SECRET", "default-dev-secret"),
-			Expiry:        parseDuration(getEnv("JWT_EXPIRATION", "24h"), 24*time.Hour),
-			RefreshExpiry: parseDuration(getEnv("JWT_REFRESH_EXPIRATION", "720h"), 30*24*time.Hour),
+			Secret:          getEnv("JWT_SECRET", "default-dev-secret"),
+			Expiry:          parseDuration(getEnv("JWT_EXPIRATION", "24h"), 24*time.Hour),
+			RefreshExpiry:   parseDuration(getEnv("JWT_REFRESH_EXPIRATION", "720h"), 30*24*time.Hour),
+			SessionCacheTTL: parseDuration(getEnv("AUTH_SESSION_CACHE_TTL", "5s"), 5*time.Second),
 		},
 		Razorpay: RazorpayConfig{
 			KeyID:     getEnv("RAZORPAY_KEY_ID", ""),
@@ -155,7 +196,9 @@ func Load() Config {
 			APIURL:     getEnv("DODO_PAYMENTS_API_URL", ""),
 		},
 		Currency: CurrencyConfig{
-			INRUSDRate: getEnv("INR_USD_RATE", "0.012"),
+			INRUSDRate:      getEnv("INR_USD_RATE", "0.012"),
+			RatesFile:       getEnv("EXCHANGE_RATES_FILE", ""),
+			RefreshInterval: parseDuration(getEnv("EXCHANGE_RATES_REFRESH_INTERVAL", "1h"), time.Hour),
 		},
 		FileStorage: FileStorageConfig{
 			UseS3:             getEnv("USE_S3", "true") == "true",
@@ -179,12 +222,26 @@ func Load() Config {
 			Enabled:      getEnv("EMAIL_ENABLED", "true") == "true",
 		},
 		Worker: WorkerConfig{
-			Enabled:       getEnv("WORKER_ENABLED", "true") == "true",
-			ID:            getEnv("WORKER_ID", ""),
-			PollInterval:  parseDuration(getEnv("WORKER_POLL_INTERVAL", "2s"), 2*time.Second),
-			LeaseDuration: parseDuration(getEnv("WORKER_LEASE_DURATION", "30m"), 30*time.Minute),
+			Enabled:          getEnv("WORKER_ENABLED", "true") == "true",
+			ID:               getEnv("WORKER_ID", ""),
+			PollInterval:     parseDuration(getEnv("WORKER_POLL_INTERVAL", "2s"), 2*time.Second),
+			LeaseDuration:    parseDuration(getEnv("WORKER_LEASE_DURATION", "30m"), 30*time.Minute),
+			SchedulerEnabled: getEnv("WORKER_SCHEDULER_ENABLED", "true") == "true",
+			MetricsAddr:      getEnv("WORKER_METRICS_ADDR", ""),
+		},
+		Earnings: EarningsConfig{
+			PlatformFeeBPS:    parsePercentBPS(getEnv("PLATFORM_FEE_PERCENT", "10"), 1000),
+			ClearanceDays:     parseInt(getEnv("EARNINGS_CLEARANCE_DAYS", "7"), 7),
+			ReconcileInterval: parseDuration(getEnv("EARNINGS_RECONCILE_INTERVAL", "15m"), 15*time.Minute),
+		},
+		Notification: NotificationConfig{
+			DigestInterval:  parseDuration(getEnv("NOTIFICATION_DIGEST_INTERVAL", "1h"), time.Hour),
+			VAPIDPublicKey:  getEnv("VAPID_PUBLIC_KEY", ""),
+			VAPIDPrivateKey: getEnv("VAPID_PRIVATE_KEY", ""),
+			VAPIDSubject:    getEnv("VAPID_SUBJECT", ""),
 		},
 		AppBaseURL: getEnv("APP_BASE_URL", "http://localhost:4200"),
+		APIBaseURL: getEnv("API_BASE_URL", "http://localhost:8080"),
 	}
 }
 
@@ -233,3 +290,21 @@ func parseDuration(value string, defaultValue time.Duration) time.Duration {
 	}
 	return defaultValue
 }
+
+// parseInt parses a non-negative integer or returns a default value
+func parseInt(value string, defaultValue int) int {
+	if parsed, err := strconv.Atoi(strings.TrimSpace(value)); err == nil && parsed >= 0 {
+		return parsed
+	}
+	return defaultValue
+}
+
+// parsePercentBPS parses a percentage such as "12.5" into basis points (1250),
+// or returns a default value if it is not between 0 and 100.
+func parsePercentBPS(value string, defaultValue int) int {
+	percent, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil || percent < 0 || percent > 100 {
+		return defaultValue
+	}
+	return int(math.Round(percent * 100))
+}
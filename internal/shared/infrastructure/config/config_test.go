@@ -36,12 +36,22 @@ func TestLoad_Defaults(t *testing.T) {
 	assert.Equal(t, "db/migrations", cfg.Migration.Path)
 	assert.True(t, cfg.Redis.Enabled)
 	assert.Empty(t, cfg.FileStorage.S3PresignEndpoint)
+	assert.Equal(t, 1000, cfg.Earnings.PlatformFeeBPS)
+	assert.Equal(t, 7, cfg.Earnings.ClearanceDays)
+	assert.Equal(t, 15*time.Minute, cfg.Earnings.ReconcileInterval)
+	assert.Equal(t, "http://localhost:8080", cfg.APIBaseURL)
 }
 
 func TestConfigHelpers(t *testing.T) {
 	t.Setenv("CONFIG_HELPER_VALUE", "")
 	assert.Equal(t, "fallback", getEnv("CONFIG_HELPER_VALUE", "fallback"))
 	assert.Equal(t, time.Minute, parseDuration("not-a-duration", time.Minute))
+	assert.Equal(t, 3, parseInt("3", 7))
+	assert.Equal(t, 7, parseInt("-1", 7))
+	assert.Equal(t, 1250, parsePercentBPS("12.5", 1000))
+	assert.Equal(t, 0, parsePercentBPS("0", 1000))
+	assert.Equal(t, 1000, parsePercentBPS("150", 1000))
+	assert.Equal(t, 1000, parsePercentBPS("ten", 1000))
 	originalFromFile, hadFromFile := os.LookupEnv("FROM_FILE")
 	_ = os.Unsetenv("FROM_FILE")
 	t.Cleanup(func() {
@@ -125,6 +135,8 @@ func TestLoad_DodoAndCurrencyConfig(t *testing.T) {
 	os.Setenv("DODO_PAYMENTS_WEBHOOK_KEY", "whsec_123")
 	os.Setenv("DODO_PAYMENTS_API_URL", "https://test.dodopayments.com")
 	os.Setenv("INR_USD_RATE", "0.0119")
+	os.Setenv("EXCHANGE_RATES_FILE", "/etc/blueprint/rates.json")
+	os.Setenv("EXCHANGE_RATES_REFRESH_INTERVAL", "30m")
 
 	cfg := Load()
 
@@ -133,6 +145,8 @@ func TestLoad_DodoAndCurrencyConfig(t *testing.T) {
 	assert.Equal(t, "whsec_123", cfg.Dodo.WebhookKey)
 	assert.Equal(t, "https://test.dodopayments.com", cfg.Dodo.APIURL)
 	assert.Equal(t, "0.0119", cfg.Currency.INRUSDRate)
+	assert.Equal(t, "/etc/blueprint/rates.json", cfg.Currency.RatesFile)
+	assert.Equal(t, 30*time.Minute, cfg.Currency.RefreshInterval)
 }
 
 func TestLoad_JWTExpirationParsing(t *testing.T) {
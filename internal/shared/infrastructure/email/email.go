@@ -3,6 +3,7 @@ package email
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -22,10 +23,17 @@ type Config struct {
 }
 
 type Message struct {
-	To      []string `json:"to"`
-	Subject string   `json:"subject"`
-	HTML    string   `json:"html,omitempty"`
-	Text    string   `json:"text,omitempty"`
+	To          []string     `json:"to"`
+	Subject     string       `json:"subject"`
+	HTML        string       `json:"html,omitempty"`
+	Text        string       `json:"text,omitempty"`
+	Attachments []Attachment `json:"attachments,omitempty"`
+}
+
+// Attachment is a file sent with a message, such as a license agreement PDF.
+type Attachment struct {
+	Filename string
+	Content  []byte
 }
 
 type Sender interface {
@@ -82,6 +90,16 @@ func (s *resendSender) Send(ctx context.Context, msg Message) error {
 	if strings.TrimSpace(s.replyTo) != "" {
 		payload["reply_to"] = s.replyTo
 	}
+	if len(msg.Attachments) > 0 {
+		attachments := make([]map[string]string, len(msg.Attachments))
+		for i, attachment := range msg.Attachments {
+			attachments[i] = map[string]string{
+				"filename": attachment.Filename,
+				"content":  base64.StdEncoding.EncodeToString(attachment.Content),
+			}
+		}
+		payload["attachments"] = attachments
+	}
 
 	body, err := json.Marshal(payload)
 	if err != nil {
@@ -2,6 +2,8 @@ package email
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -82,6 +84,29 @@ func TestResendSender_Send_Success(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestResendSender_Send_Attachments(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload struct {
+			Attachments []map[string]string `json:"attachments"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		require.Len(t, payload.Attachments, 1)
+		assert.Equal(t, "agreement.pdf", payload.Attachments[0]["filename"])
+		assert.Equal(t, base64.StdEncoding.EncodeToString([]byte("%PDF-1.4")), payload.Attachments[0]["content"])
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	sender := NewSender(Config{Enabled: true, APIKey: "test-key", From: "test@example.com", APIRoot: ts.URL, HTTPClient: ts.Client()})
+	err := sender.Send(context.Background(), Message{
+		To:          []string{"recipient@example.com"},
+		Subject:     "Receipt",
+		Text:        "text body",
+		Attachments: []Attachment{{Filename: "agreement.pdf", Content: []byte("%PDF-1.4")}},
+	})
+	assert.NoError(t, err)
+}
+
 func TestResendSender_Send_Error(t *testing.T) {
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusBadRequest)
@@ -22,6 +22,43 @@ type ReceiptData struct {
 	PaymentID     string
 	LicenseID     string
 	SupportEmail  string
+	// Attachments, typically the license agreements, are sent with the receipt.
+	Attachments []Attachment
+}
+
+// NotificationEmailData is an in-app notification sent by email as it
+// happens.
+type NotificationEmailData struct {
+	RecipientName  string
+	RecipientEmail string
+	Title          string
+	Message        string
+}
+
+// NotificationDigestData batches a user's unread notifications into one
+// email.
+type NotificationDigestData struct {
+	RecipientName  string
+	RecipientEmail string
+	Items          []NotificationDigestItem
+}
+
+// SessionAlertData describes the session a security email is about.
+type SessionAlertData struct {
+	RecipientName  string
+	RecipientEmail string
+	Device         string
+	Location       string
+	IPAddress      string
+	// When is a display time, such as "Mar 3, 14:05 UTC".
+	When string
+}
+
+type NotificationDigestItem struct {
+	Title   string
+	Message string
+	// When is a display time, such as "Mar 3, 14:05 UTC".
+	When string
 }
 
 type emailCTA struct {
@@ -34,6 +71,12 @@ type emailMetaRow struct {
 	Value string
 }
 
+type emailListItem struct {
+	Title string
+	Body  string
+	Meta  string
+}
+
 type emailTemplateData struct {
 	BrandName    string
 	Preheader    string
@@ -48,6 +91,7 @@ type emailTemplateData struct {
 	PrimaryCTA   *emailCTA
 	SecondaryCTA *emailCTA
 	MetaRows     []emailMetaRow
+	Items        []emailListItem
 	FooterNote   string
 	SupportEmail string
 }
@@ -169,11 +213,150 @@ func BuildPaymentReceiptEmail(data ReceiptData, appBaseURL string) Message {
 	}
 
 	return Message{
-		To:      []string{data.BuyerEmail},
+		To:          []string{data.BuyerEmail},
+		Subject:     subject,
+		Text:        buildPaymentReceiptText(name, data, link),
+		HTML:        mustRenderTemplate("payment-receipt.html", viewData),
+		Attachments: data.Attachments,
+	}
+}
+
+func BuildNotificationEmail(data NotificationEmailData, appBaseURL string) Message {
+	name := displayNameOrFallback(data.RecipientName)
+	link := buildLink(appBaseURL, "/notifications", nil)
+	preferencesLink := buildLink(appBaseURL, "/settings/notifications", nil)
+	viewData := emailTemplateData{
+		BrandName:    "BLUEPRINT",
+		Preheader:    data.Message,
+		Eyebrow:      "Notification",
+		Title:        data.Title,
+		Greeting:     fmt.Sprintf("Hi %s,", name),
+		Intro:        []string{data.Message},
+		NoticeTitle:  "Too many emails?",
+		NoticeBody:   "Choose which notifications are emailed to you, or get them once a day, from your",
+		PrimaryCTA:   &emailCTA{Label: "Open Notifications", URL: link},
+		SecondaryCTA: &emailCTA{Label: "notification settings.", URL: preferencesLink},
+		FooterNote:   "You received this email because of your Blueprint notification settings.",
+	}
+
+	return Message{
+		To:      []string{data.RecipientEmail},
+		Subject: data.Title,
+		Text:    buildNotificationText(name, data, link, preferencesLink),
+		HTML:    mustRenderTemplate("notification.html", viewData),
+	}
+}
+
+func BuildNotificationDigestEmail(data NotificationDigestData, appBaseURL string) Message {
+	name := displayNameOrFallback(data.RecipientName)
+	link := buildLink(appBaseURL, "/notifications", nil)
+	preferencesLink := buildLink(appBaseURL, "/settings/notifications", nil)
+	subject := "Your Blueprint daily digest"
+	if len(data.Items) == 1 {
+		subject = "You have 1 unread notification on Blueprint"
+	} else if len(data.Items) > 1 {
+		subject = fmt.Sprintf("You have %d unread notifications on Blueprint", len(data.Items))
+	}
+	items := make([]emailListItem, len(data.Items))
+	for i, item := range data.Items {
+		items[i] = emailListItem{Title: item.Title, Body: item.Message, Meta: item.When}
+	}
+	viewData := emailTemplateData{
+		BrandName:    "BLUEPRINT",
+		Preheader:    "Here is what you missed on Blueprint today.",
+		Eyebrow:      "Daily digest",
+		Title:        "Here is what you missed.",
+		Greeting:     fmt.Sprintf("Hi %s,", name),
+		Intro:        []string{"These notifications are still unread in your Blueprint inbox."},
+		Items:        items,
+		NoticeTitle:  "Prefer a different schedule?",
+		NoticeBody:   "Choose which notifications are emailed to you, and whether they come right away or once a day, from your",
+		PrimaryCTA:   &emailCTA{Label: "Open Notifications", URL: link},
+		SecondaryCTA: &emailCTA{Label: "notification settings.", URL: preferencesLink},
+		FooterNote:   "You received this digest because of your Blueprint notification settings.",
+	}
+
+	return Message{
+		To:      []string{data.RecipientEmail},
 		Subject: subject,
-		Text:    buildPaymentReceiptText(name, data, link),
-		HTML:    mustRenderTemplate("payment-receipt.html", viewData),
+		Text:    buildNotificationDigestText(name, data, link, preferencesLink),
+		HTML:    mustRenderTemplate("notification.html", viewData),
+	}
+}
+
+func BuildNewSignInEmail(data SessionAlertData, appBaseURL string) Message {
+	name := displayNameOrFallback(data.RecipientName)
+	link := buildLink(appBaseURL, "/settings/sessions", nil)
+	viewData := emailTemplateData{
+		BrandName: "BLUEPRINT",
+		Preheader: fmt.Sprintf("Your Blueprint account was signed in to from %s.", data.Device),
+		Eyebrow:   "Security alert",
+		Title:     "New sign-in to your account.",
+		Greeting:  fmt.Sprintf("Hi %s,", name),
+		Intro: []string{
+			"Your Blueprint account was just signed in to from a device you have not used before.",
+			"If this was you, there is nothing else to do.",
+		},
+		NoticeTitle: "Don't recognise this sign-in?",
+		NoticeBody:  "Sign the device out from your active sessions and reset your password right away.",
+		PrimaryCTA:  &emailCTA{Label: "Review Active Sessions", URL: link},
+		MetaRows:    sessionAlertMetaRows(data),
+		FooterNote:  "You received this email because a new device signed in to your Blueprint account.",
+	}
+
+	return Message{
+		To:      []string{data.RecipientEmail},
+		Subject: "New sign-in to your Blueprint account",
+		Text:    buildNewSignInText(name, data, link),
+		HTML:    mustRenderTemplate("session-alert.html", viewData),
+	}
+}
+
+// BuildSessionRevokedEmail tells the user a session was signed out because
+// its refresh token was used after being replaced, which suggests it was
+// copied.
+func BuildSessionRevokedEmail(data SessionAlertData, appBaseURL string) Message {
+	name := displayNameOrFallback(data.RecipientName)
+	link := buildLink(appBaseURL, "/settings/sessions", nil)
+	viewData := emailTemplateData{
+		BrandName: "BLUEPRINT",
+		Preheader: "We signed a session out to protect your Blueprint account.",
+		Eyebrow:   "Security alert",
+		Title:     "We signed a session out.",
+		Greeting:  fmt.Sprintf("Hi %s,", name),
+		Intro: []string{
+			"An old sign-in token for one of your sessions was used again after it had been replaced. This can mean it was copied from your device.",
+			"To be safe we signed that session out. You may need to sign in again on that device.",
+		},
+		NoticeTitle: "Don't recognise this activity?",
+		NoticeBody:  "Reset your password and sign out any other sessions you don't recognise.",
+		PrimaryCTA:  &emailCTA{Label: "Review Active Sessions", URL: link},
+		MetaRows:    sessionAlertMetaRows(data),
+		FooterNote:  "You received this email because a session of your Blueprint account was signed out for your security.",
+	}
+
+	return Message{
+		To:      []string{data.RecipientEmail},
+		Subject: "A session of your Blueprint account was signed out",
+		Text:    buildSessionRevokedText(name, data, link),
+		HTML:    mustRenderTemplate("session-alert.html", viewData),
+	}
+}
+
+func sessionAlertMetaRows(data SessionAlertData) []emailMetaRow {
+	rows := []emailMetaRow{
+		{Label: "Device", Value: data.Device},
+		{Label: "Location", Value: data.Location},
+		{Label: "IP address", Value: data.IPAddress},
+		{Label: "Time", Value: data.When},
+	}
+	filtered := rows[:0]
+	for _, row := range rows {
+		if strings.TrimSpace(row.Value) != "" {
+			filtered = append(filtered, row)
+		}
 	}
+	return filtered
 }
 
 func displayNameOrFallback(displayName string) string {
@@ -246,6 +429,73 @@ func buildPaymentReceiptText(name string, data ReceiptData, link string) string
 	return strings.Join(lines, "\n")
 }
 
+func buildNotificationText(name string, data NotificationEmailData, link, preferencesLink string) string {
+	return fmt.Sprintf(
+		"Hi %s,\n\n%s\n\n%s\n\nOpen your notifications here:\n%s\n\nChange which notifications are emailed to you:\n%s",
+		name,
+		data.Title,
+		data.Message,
+		link,
+		preferencesLink,
+	)
+}
+
+func buildNotificationDigestText(name string, data NotificationDigestData, link, preferencesLink string) string {
+	lines := []string{
+		fmt.Sprintf("Hi %s,", name),
+		"",
+		"These notifications are still unread in your Blueprint inbox:",
+	}
+	for _, item := range data.Items {
+		lines = append(lines, "", fmt.Sprintf("%s (%s)", item.Title, item.When), item.Message)
+	}
+	lines = append(lines,
+		"",
+		"Open your notifications here:",
+		link,
+		"",
+		"Change which notifications are emailed to you:",
+		preferencesLink,
+	)
+	return strings.Join(lines, "\n")
+}
+
+func buildNewSignInText(name string, data SessionAlertData, link string) string {
+	lines := []string{
+		fmt.Sprintf("Hi %s,", name),
+		"",
+		"Your Blueprint account was just signed in to from a device you have not used before.",
+		"",
+	}
+	for _, row := range sessionAlertMetaRows(data) {
+		lines = append(lines, fmt.Sprintf("%s: %s", row.Label, row.Value))
+	}
+	lines = append(lines,
+		"",
+		"If this was you, there is nothing else to do. If not, sign the device out and reset your password:",
+		link,
+	)
+	return strings.Join(lines, "\n")
+}
+
+func buildSessionRevokedText(name string, data SessionAlertData, link string) string {
+	lines := []string{
+		fmt.Sprintf("Hi %s,", name),
+		"",
+		"An old sign-in token for one of your sessions was used again after it had been replaced, so we signed that session out.",
+		"",
+	}
+	for _, row := range sessionAlertMetaRows(data) {
+		lines = append(lines, fmt.Sprintf("%s: %s", row.Label, row.Value))
+	}
+	lines = append(lines,
+		"",
+		"If you don't recognise this activity, reset your password and review your sessions:",
+		link,
+	)
+	return strings.Join(lines, "\n")
+}
+
 func buildLink(baseURL, path string, params map[string]string) string {
 	baseURL = strings.TrimRight(strings.TrimSpace(baseURL), "/")
 	if baseURL == "" {
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestBuildVerificationEmail(t *testing.T) {
@@ -44,10 +45,13 @@ func TestBuildPaymentReceiptEmail(t *testing.T) {
 		PaymentID:     "pay_123",
 		LicenseID:     "lic_123",
 		SupportEmail:  "support@example.com",
+		Attachments:   []Attachment{{Filename: "agreement.pdf", Content: []byte("%PDF-1.4")}},
 	}, "http://localhost:4200")
 
 	assert.Equal(t, []string{"buyer@example.com"}, msg.To)
 	assert.Equal(t, "Your Blueprint purchase receipt", msg.Subject)
+	require.Len(t, msg.Attachments, 1)
+	assert.Equal(t, "agreement.pdf", msg.Attachments[0].Filename)
 	assert.Contains(t, msg.Text, "Midnight Drive")
 	assert.Contains(t, msg.Text, "support@example.com")
 	assert.Contains(t, msg.HTML, "Purchase confirmed")
@@ -58,6 +62,80 @@ func TestBuildPaymentReceiptEmail(t *testing.T) {
 	assert.Contains(t, msg.HTML, "http://localhost:4200/licenses")
 }
 
+func TestBuildNotificationEmail(t *testing.T) {
+	msg := BuildNotificationEmail(NotificationEmailData{
+		RecipientName:  "Producer",
+		RecipientEmail: "producer@example.com",
+		Title:          "New sale",
+		Message:        "You sold a Premium license for Midnight Drive.",
+	}, "http://localhost:4200")
+
+	assert.Equal(t, []string{"producer@example.com"}, msg.To)
+	assert.Equal(t, "New sale", msg.Subject)
+	assert.Contains(t, msg.Text, "Hi Producer,")
+	assert.Contains(t, msg.Text, "You sold a Premium license for Midnight Drive.")
+	assert.Contains(t, msg.Text, "http://localhost:4200/settings/notifications")
+	assert.Contains(t, msg.HTML, "Open Notifications")
+	assert.Contains(t, msg.HTML, "http://localhost:4200/notifications")
+}
+
+func TestBuildNewSignInEmail(t *testing.T) {
+	msg := BuildNewSignInEmail(SessionAlertData{
+		RecipientName:  "Producer",
+		RecipientEmail: "producer@example.com",
+		Device:         "Firefox on Windows",
+		IPAddress:      "203.0.113.7",
+		When:           "Mar 3, 14:05 UTC",
+	}, "http://localhost:4200")
+
+	assert.Equal(t, []string{"producer@example.com"}, msg.To)
+	assert.Equal(t, "New sign-in to your Blueprint account", msg.Subject)
+	assert.Contains(t, msg.Text, "Device: Firefox on Windows")
+	assert.Contains(t, msg.Text, "IP address: 203.0.113.7")
+	assert.NotContains(t, msg.Text, "Location:")
+	assert.Contains(t, msg.Text, "http://localhost:4200/settings/sessions")
+	assert.Contains(t, msg.HTML, "Review Active Sessions")
+	assert.Contains(t, msg.HTML, "Mar 3, 14:05 UTC")
+	assert.NotContains(t, msg.HTML, "Location")
+}
+
+func TestBuildSessionRevokedEmail(t *testing.T) {
+	msg := BuildSessionRevokedEmail(SessionAlertData{
+		RecipientName:  "Producer",
+		RecipientEmail: "producer@example.com",
+		Device:         "Chrome on macOS",
+		Location:       "Pune, IN",
+		When:           "Mar 3, 14:05 UTC",
+	}, "http://localhost:4200/")
+
+	assert.Equal(t, []string{"producer@example.com"}, msg.To)
+	assert.Equal(t, "A session of your Blueprint account was signed out", msg.Subject)
+	assert.Contains(t, msg.Text, "Device: Chrome on macOS")
+	assert.Contains(t, msg.Text, "Location: Pune, IN")
+	assert.Contains(t, msg.Text, "http://localhost:4200/settings/sessions")
+	assert.Contains(t, msg.HTML, "We signed a session out.")
+	assert.Contains(t, msg.HTML, "Chrome on macOS")
+}
+
+func TestBuildNotificationDigestEmail(t *testing.T) {
+	msg := BuildNotificationDigestEmail(NotificationDigestData{
+		RecipientName:  "",
+		RecipientEmail: "fan@example.com",
+		Items: []NotificationDigestItem{
+			{Title: "Price drop", Message: "Midnight Drive is now cheaper.", When: "Mar 3, 14:05 UTC"},
+			{Title: "Price drop", Message: "Sunset <Loop> is now cheaper.", When: "Mar 3, 18:40 UTC"},
+		},
+	}, "http://localhost:4200")
+
+	assert.Equal(t, []string{"fan@example.com"}, msg.To)
+	assert.Equal(t, "You have 2 unread notifications on Blueprint", msg.Subject)
+	assert.Contains(t, msg.Text, "Hi there,")
+	assert.Contains(t, msg.Text, "Price drop (Mar 3, 14:05 UTC)\nMidnight Drive is now cheaper.")
+	assert.Contains(t, msg.HTML, "Daily digest")
+	assert.Contains(t, msg.HTML, "Sunset &lt;Loop&gt; is now cheaper.")
+	assert.Contains(t, msg.HTML, "Mar 3, 18:40 UTC")
+}
+
 func TestRenderTemplate_OmitsSupportBlockWhenEmailMissing(t *testing.T) {
 	html, err := renderTemplate("payment-receipt.html", emailTemplateData{
 		BrandName:  "BLUEPRINT",
@@ -0,0 +1,76 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse_Next(t *testing.T) {
+	// A Wednesday.
+	from := time.Date(2026, 7, 15, 10, 7, 30, 0, time.UTC)
+
+	tests := []struct {
+		spec string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2026, 7, 15, 10, 8, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2026, 7, 15, 10, 15, 0, 0, time.UTC)},
+		{"5 * * * *", time.Date(2026, 7, 15, 11, 5, 0, 0, time.UTC)},
+		{"0,30 9-17 * * *", time.Date(2026, 7, 15, 10, 30, 0, 0, time.UTC)},
+		{"0 3 * * *", time.Date(2026, 7, 16, 3, 0, 0, 0, time.UTC)},
+		{"0 0 1 * *", time.Date(2026, 8, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 12 * * 1-5", time.Date(2026, 7, 15, 12, 0, 0, 0, time.UTC)},
+		{"0 12 * * 7", time.Date(2026, 7, 19, 12, 0, 0, 0, time.UTC)},
+		// Either day field matching is enough when both are restricted.
+		{"0 0 20 * 5", time.Date(2026, 7, 17, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2026, 7, 15, 11, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2026, 7, 16, 0, 0, 0, 0, time.UTC)},
+		{"@every 1h", time.Date(2026, 7, 15, 11, 0, 0, 0, time.UTC)},
+		{"@every 20m", time.Date(2026, 7, 15, 10, 20, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			schedule, err := Parse(tt.spec)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, schedule.Next(from))
+		})
+	}
+}
+
+func TestParse_NextIsStrictlyAfter(t *testing.T) {
+	schedule, err := Parse("*/15 * * * *")
+	require.NoError(t, err)
+	at := time.Date(2026, 7, 15, 10, 15, 0, 0, time.UTC)
+	assert.Equal(t, at.Add(15*time.Minute), schedule.Next(at))
+
+	never, err := Parse("0 0 30 2 *")
+	require.NoError(t, err)
+	assert.True(t, never.Next(at).IsZero())
+}
+
+func TestParse_Invalid(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+		"@every soon",
+		"@every 10s",
+		"@yearly",
+	} {
+		t.Run(spec, func(t *testing.T) {
+			_, err := Parse(spec)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package scheduler
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const (
+	outcomeSucceeded = "succeeded"
+	outcomeFailed    = "failed"
+	// outcomeSkipped counts activations left to another replica.
+	outcomeSkipped = "skipped"
+)
+
+var (
+	jobRunsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "scheduler_job_runs_total",
+		Help: "Total number of scheduled job activations by outcome.",
+	}, []string{"job", "outcome"})
+
+	jobDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "scheduler_job_duration_seconds",
+		Help:    "Duration of scheduled job runs in seconds.",
+		Buckets: []float64{0.1, 0.5, 1, 5, 15, 30, 60, 120, 300, 600},
+	}, []string{"job"})
+
+	jobLastSuccess = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "scheduler_job_last_success_timestamp_seconds",
+		Help: "Unix time of the last successful run of each scheduled job.",
+	}, []string{"job"})
+
+	jobsRunning = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "scheduler_jobs_running",
+		Help: "Number of scheduled job runs in progress on this instance.",
+	}, []string{"job"})
+)
+
+func observeRun(job, status string, duration time.Duration, finishedAt time.Time) {
+	outcome := outcomeFailed
+	if status == RunStatusSucceeded {
+		outcome = outcomeSucceeded
+		jobLastSuccess.WithLabelValues(job).Set(float64(finishedAt.Unix()))
+	}
+	jobRunsTotal.WithLabelValues(job, outcome).Inc()
+	jobDuration.WithLabelValues(job).Observe(duration.Seconds())
+}
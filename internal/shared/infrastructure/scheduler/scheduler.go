@@ -0,0 +1,207 @@
+// Package scheduler runs periodic background jobs on cron-style schedules.
+//
+// Every replica runs the same scheduler. Before a job runs, the replica must
+// win a lock for it from the Store, and each activation is recorded once, so
+// a job runs on a single replica per activation however many are deployed.
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	RunStatusRunning   = "running"
+	RunStatusSucceeded = "succeeded"
+	RunStatusFailed    = "failed"
+)
+
+// defaultJobTimeout bounds a run when the job does not set its own timeout.
+const defaultJobTimeout = 10 * time.Minute
+
+// Job is a unit of periodic work.
+type Job struct {
+	// Name identifies the job in locks, run history and metrics.
+	Name string
+	// Schedule is a cron expression or descriptor accepted by Parse.
+	Schedule string
+	// Timeout bounds a single run. Zero means defaultJobTimeout.
+	Timeout time.Duration
+	Run     func(ctx context.Context) error
+}
+
+// Run is one recorded execution of a job.
+type Run struct {
+	ID           uuid.UUID  `db:"id"`
+	JobName      string     `db:"job_name"`
+	ScheduledFor time.Time  `db:"scheduled_for"`
+	InstanceID   string     `db:"instance_id"`
+	Status       string     `db:"status"`
+	ErrorMessage *string    `db:"error_message"`
+	StartedAt    time.Time  `db:"started_at"`
+	FinishedAt   *time.Time `db:"finished_at"`
+	DurationMs   *int64     `db:"duration_ms"`
+}
+
+// Store elects the replica that runs a job and keeps the run history.
+type Store interface {
+	// Acquire tries to take the job's lock without waiting. When acquired,
+	// release must be called once the run is over.
+	Acquire(ctx context.Context, job string) (release func(), acquired bool, err error)
+	// StartRun records the run, reporting false when the activation was
+	// already run by another replica.
+	StartRun(ctx context.Context, run *Run) (bool, error)
+	FinishRun(ctx context.Context, run *Run) error
+}
+
+type scheduledJob struct {
+	Job
+	schedule Schedule
+}
+
+// Scheduler runs registered jobs until its context is cancelled.
+type Scheduler struct {
+	store      Store
+	instanceID string
+	now        func() time.Time
+	jobs       []scheduledJob
+}
+
+// New creates a scheduler recording runs as instanceID.
+func New(store Store, instanceID string) *Scheduler {
+	return &Scheduler{store: store, instanceID: instanceID, now: time.Now}
+}
+
+// Add registers a job. Jobs must be added before Start.
+func (s *Scheduler) Add(job Job) error {
+	if job.Name == "" || job.Run == nil {
+		return errors.New("scheduler job needs a name and a run function")
+	}
+	for _, existing := range s.jobs {
+		if existing.Name == job.Name {
+			return fmt.Errorf("scheduler job %q is already registered", job.Name)
+		}
+	}
+	schedule, err := Parse(job.Schedule)
+	if err != nil {
+		return fmt.Errorf("scheduler job %q: %w", job.Name, err)
+	}
+	if job.Timeout <= 0 {
+		job.Timeout = defaultJobTimeout
+	}
+	s.jobs = append(s.jobs, scheduledJob{Job: job, schedule: schedule})
+	return nil
+}
+
+// Start runs the jobs until ctx is cancelled and returns once in-flight runs
+// have finished. A job never overlaps itself: activations that pass while it
+// is still running are skipped.
+func (s *Scheduler) Start(ctx context.Context) {
+	log.Printf("scheduler started instance=%s jobs=%d", s.instanceID, len(s.jobs))
+	var wg sync.WaitGroup
+	for _, job := range s.jobs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.loop(ctx, job)
+		}()
+	}
+	wg.Wait()
+	log.Printf("scheduler stopped instance=%s", s.instanceID)
+}
+
+func (s *Scheduler) loop(ctx context.Context, job scheduledJob) {
+	for {
+		next := job.schedule.Next(s.now())
+		if next.IsZero() {
+			log.Printf("scheduler job %s has no upcoming activation", job.Name)
+			return
+		}
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		s.execute(ctx, job, next)
+	}
+}
+
+// execute runs one activation of the job if this replica wins it.
+func (s *Scheduler) execute(ctx context.Context, job scheduledJob, scheduledFor time.Time) {
+	release, acquired, err := s.store.Acquire(ctx, job.Name)
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Printf("scheduler job %s: acquire lock: %v", job.Name, err)
+		}
+		return
+	}
+	if !acquired {
+		jobRunsTotal.WithLabelValues(job.Name, outcomeSkipped).Inc()
+		return
+	}
+	defer release()
+
+	run := &Run{
+		JobName:      job.Name,
+		ScheduledFor: scheduledFor.UTC(),
+		InstanceID:   s.instanceID,
+		Status:       RunStatusRunning,
+		StartedAt:    s.now().UTC(),
+	}
+	started, err := s.store.StartRun(ctx, run)
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Printf("scheduler job %s: record run: %v", job.Name, err)
+		}
+		return
+	}
+	if !started {
+		jobRunsTotal.WithLabelValues(job.Name, outcomeSkipped).Inc()
+		return
+	}
+
+	jobsRunning.WithLabelValues(job.Name).Inc()
+	runErr := runJob(ctx, job.Job)
+	jobsRunning.WithLabelValues(job.Name).Dec()
+
+	finishedAt := s.now().UTC()
+	duration := finishedAt.Sub(run.StartedAt)
+	durationMs := duration.Milliseconds()
+	run.FinishedAt = &finishedAt
+	run.DurationMs = &durationMs
+	run.Status = RunStatusSucceeded
+	if runErr != nil {
+		message := runErr.Error()
+		run.Status = RunStatusFailed
+		run.ErrorMessage = &message
+		log.Printf("scheduler job %s failed after %s: %v", job.Name, duration, runErr)
+	}
+
+	// Record the outcome even when shutdown cancelled the run.
+	finishCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+	defer cancel()
+	if err := s.store.FinishRun(finishCtx, run); err != nil {
+		log.Printf("scheduler job %s: record outcome: %v", job.Name, err)
+	}
+	observeRun(job.Name, run.Status, duration, finishedAt)
+}
+
+// runJob calls the job under its timeout, turning a panic into an error.
+func runJob(ctx context.Context, job Job) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, job.Timeout)
+	defer cancel()
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("panic: %v", recovered)
+		}
+	}()
+	return job.Run(ctx)
+}
@@ -0,0 +1,132 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeStore struct {
+	locked   bool
+	seen     map[string]bool
+	released int
+	started  []*Run
+	finished []Run
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{seen: map[string]bool{}}
+}
+
+func (s *fakeStore) Acquire(context.Context, string) (func(), bool, error) {
+	if s.locked {
+		return nil, false, nil
+	}
+	return func() { s.released++ }, true, nil
+}
+
+func (s *fakeStore) StartRun(_ context.Context, run *Run) (bool, error) {
+	key := run.JobName + run.ScheduledFor.String()
+	if s.seen[key] {
+		return false, nil
+	}
+	s.seen[key] = true
+	s.started = append(s.started, run)
+	return true, nil
+}
+
+func (s *fakeStore) FinishRun(_ context.Context, run *Run) error {
+	s.finished = append(s.finished, *run)
+	return nil
+}
+
+func addJob(t *testing.T, s *Scheduler, run func(context.Context) error) scheduledJob {
+	t.Helper()
+	require.NoError(t, s.Add(Job{Name: "test.job", Schedule: "*/5 * * * *", Run: run}))
+	return s.jobs[len(s.jobs)-1]
+}
+
+func TestScheduler_ExecuteRecordsOutcome(t *testing.T) {
+	store := newFakeStore()
+	s := New(store, "worker-1")
+	at := time.Date(2026, 7, 15, 10, 5, 0, 0, time.UTC)
+	calls := 0
+	job := addJob(t, s, func(ctx context.Context) error {
+		calls++
+		_, hasDeadline := ctx.Deadline()
+		assert.True(t, hasDeadline)
+		return nil
+	})
+
+	s.execute(context.Background(), job, at)
+	require.Len(t, store.finished, 1)
+	run := store.finished[0]
+	assert.Equal(t, RunStatusSucceeded, run.Status)
+	assert.Equal(t, "worker-1", run.InstanceID)
+	assert.Equal(t, at, run.ScheduledFor)
+	assert.Nil(t, run.ErrorMessage)
+	require.NotNil(t, run.DurationMs)
+	assert.Equal(t, 1, store.released)
+
+	// The same activation is never run twice.
+	s.execute(context.Background(), job, at)
+	assert.Equal(t, 1, calls)
+	assert.Len(t, store.finished, 1)
+	assert.Equal(t, 2, store.released)
+
+	// Nor is a job whose lock another replica holds.
+	store.locked = true
+	s.execute(context.Background(), job, at.Add(5*time.Minute))
+	assert.Equal(t, 1, calls)
+}
+
+func TestScheduler_ExecuteRecordsFailuresAndPanics(t *testing.T) {
+	store := newFakeStore()
+	s := New(store, "worker-1")
+	at := time.Date(2026, 7, 15, 10, 5, 0, 0, time.UTC)
+
+	failing := addJob(t, s, func(context.Context) error { return errors.New("boom") })
+	s.execute(context.Background(), failing, at)
+	require.Len(t, store.finished, 1)
+	assert.Equal(t, RunStatusFailed, store.finished[0].Status)
+	assert.Equal(t, "boom", *store.finished[0].ErrorMessage)
+
+	require.NoError(t, s.Add(Job{Name: "test.panic", Schedule: "@hourly", Run: func(context.Context) error { panic("nil map") }}))
+	s.execute(context.Background(), s.jobs[1], at)
+	require.Len(t, store.finished, 2)
+	assert.Equal(t, RunStatusFailed, store.finished[1].Status)
+	assert.Equal(t, "panic: nil map", *store.finished[1].ErrorMessage)
+}
+
+func TestScheduler_Add(t *testing.T) {
+	s := New(newFakeStore(), "worker-1")
+	run := func(context.Context) error { return nil }
+
+	require.NoError(t, s.Add(Job{Name: "a", Schedule: "@hourly", Run: run}))
+	assert.Equal(t, defaultJobTimeout, s.jobs[0].Timeout)
+	assert.ErrorContains(t, s.Add(Job{Name: "a", Schedule: "@daily", Run: run}), "already registered")
+	assert.Error(t, s.Add(Job{Name: "b", Schedule: "every day", Run: run}))
+	assert.Error(t, s.Add(Job{Name: "c", Schedule: "@hourly"}))
+}
+
+func TestScheduler_StartStopsWithContext(t *testing.T) {
+	s := New(newFakeStore(), "worker-1")
+	require.NoError(t, s.Add(Job{Name: "a", Schedule: "@hourly", Run: func(context.Context) error { return nil }}))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	done := make(chan struct{})
+	go func() {
+		s.Start(ctx)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("scheduler did not stop")
+	}
+}
@@ -0,0 +1,93 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"log"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// PgStore elects job runners with Postgres advisory locks and records runs in
+// scheduled_job_runs.
+type PgStore struct {
+	db *sqlx.DB
+}
+
+func NewPgStore(db *sqlx.DB) *PgStore {
+	return &PgStore{db: db}
+}
+
+// Acquire takes a session-level advisory lock on a dedicated connection, so
+// the lock is held for the whole run without keeping a transaction open. The
+// lock goes away with the connection if the replica dies mid-run.
+func (s *PgStore) Acquire(ctx context.Context, job string) (func(), bool, error) {
+	key := "scheduler:" + job
+	conn, err := s.db.Connx(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	var locked bool
+	if err := conn.GetContext(ctx, &locked, "SELECT pg_try_advisory_lock(hashtext($1))", key); err != nil {
+		conn.Close()
+		return nil, false, err
+	}
+	if !locked {
+		conn.Close()
+		return nil, false, nil
+	}
+
+	release := func() {
+		if _, err := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock(hashtext($1))", key); err != nil {
+			log.Printf("scheduler job %s: release lock: %v", job, err)
+			// Discard the connection rather than return it to the pool
+			// still holding the lock.
+			conn.Raw(func(any) error { return driver.ErrBadConn })
+		}
+		conn.Close()
+	}
+	return release, true, nil
+}
+
+// StartRun inserts the run. Runs of the job still marked running were left
+// behind by a replica that died, since the caller holds the job's lock, so
+// they are failed first.
+func (s *PgStore) StartRun(ctx context.Context, run *Run) (bool, error) {
+	if _, err := s.db.ExecContext(ctx, `
+		UPDATE scheduled_job_runs
+		SET status = 'failed',
+		    error_message = 'abandoned by instance',
+		    finished_at = NOW()
+		WHERE job_name = $1 AND status = 'running'`, run.JobName); err != nil {
+		return false, fmt.Errorf("fail abandoned runs: %w", err)
+	}
+
+	id, err := uuid.NewV7()
+	if err != nil {
+		return false, err
+	}
+	run.ID = id
+	result, err := s.db.NamedExecContext(ctx, `
+		INSERT INTO scheduled_job_runs (id, job_name, scheduled_for, instance_id, status, started_at)
+		VALUES (:id, :job_name, :scheduled_for, :instance_id, :status, :started_at)
+		ON CONFLICT (job_name, scheduled_for) DO NOTHING`, run)
+	if err != nil {
+		return false, err
+	}
+	inserted, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return inserted == 1, nil
+}
+
+func (s *PgStore) FinishRun(ctx context.Context, run *Run) error {
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE scheduled_job_runs
+		SET status = $2, error_message = $3, finished_at = $4, duration_ms = $5
+		WHERE id = $1`,
+		run.ID, run.Status, run.ErrorMessage, run.FinishedAt, run.DurationMs)
+	return err
+}
@@ -0,0 +1,77 @@
+package scheduler_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/saransh1220/blueprint-audio/internal/shared/infrastructure/scheduler"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newMockDB(t *testing.T) (*sqlx.DB, sqlmock.Sqlmock, func()) {
+	t.Helper()
+	sqlDB, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherRegexp))
+	require.NoError(t, err)
+	return sqlx.NewDb(sqlDB, "sqlmock"), mock, func() { _ = sqlDB.Close() }
+}
+
+func TestPgStore_Acquire(t *testing.T) {
+	db, mock, cleanup := newMockDB(t)
+	defer cleanup()
+	store := scheduler.NewPgStore(db)
+
+	mock.ExpectQuery(`SELECT pg_try_advisory_lock\(hashtext\(\$1\)\)`).WithArgs("scheduler:orders.expire").
+		WillReturnRows(sqlmock.NewRows([]string{"locked"}).AddRow(true))
+	mock.ExpectExec(`SELECT pg_advisory_unlock\(hashtext\(\$1\)\)`).WithArgs("scheduler:orders.expire").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	release, acquired, err := store.Acquire(context.Background(), "orders.expire")
+	require.NoError(t, err)
+	require.True(t, acquired)
+	release()
+
+	mock.ExpectQuery(`SELECT pg_try_advisory_lock`).WithArgs("scheduler:orders.expire").
+		WillReturnRows(sqlmock.NewRows([]string{"locked"}).AddRow(false))
+	_, acquired, err = store.Acquire(context.Background(), "orders.expire")
+	require.NoError(t, err)
+	assert.False(t, acquired)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPgStore_StartAndFinishRun(t *testing.T) {
+	db, mock, cleanup := newMockDB(t)
+	defer cleanup()
+	store := scheduler.NewPgStore(db)
+	now := time.Now().UTC()
+	run := &scheduler.Run{JobName: "orders.expire", ScheduledFor: now, InstanceID: "worker-1", Status: scheduler.RunStatusRunning, StartedAt: now}
+
+	mock.ExpectExec(`UPDATE scheduled_job_runs\s+SET status = 'failed'[\s\S]*WHERE job_name = \$1 AND status = 'running'`).WithArgs("orders.expire").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO scheduled_job_runs[\s\S]*ON CONFLICT \(job_name, scheduled_for\) DO NOTHING`).
+		WithArgs(sqlmock.AnyArg(), "orders.expire", now, "worker-1", scheduler.RunStatusRunning, now).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	started, err := store.StartRun(context.Background(), run)
+	require.NoError(t, err)
+	assert.True(t, started)
+	assert.NotEqual(t, uuid.Nil, run.ID)
+
+	mock.ExpectExec(`UPDATE scheduled_job_runs`).WithArgs("orders.expire").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`INSERT INTO scheduled_job_runs`).WillReturnResult(sqlmock.NewResult(0, 0))
+	started, err = store.StartRun(context.Background(), run)
+	require.NoError(t, err)
+	assert.False(t, started, "another replica already ran this activation")
+
+	finished := now.Add(time.Second)
+	duration := int64(1000)
+	message := "boom"
+	run.Status, run.ErrorMessage, run.FinishedAt, run.DurationMs = scheduler.RunStatusFailed, &message, &finished, &duration
+	mock.ExpectExec(`UPDATE scheduled_job_runs\s+SET status = \$2, error_message = \$3, finished_at = \$4, duration_ms = \$5\s+WHERE id = \$1`).
+		WithArgs(run.ID, scheduler.RunStatusFailed, &message, &finished, &duration).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	require.NoError(t, store.FinishRun(context.Background(), run))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
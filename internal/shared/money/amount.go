@@ -0,0 +1,90 @@
+package money
+
+import (
+	"encoding/json"
+	"math"
+	"sort"
+)
+
+// Amount is an exact amount of money in the minor units of its currency:
+// cents, paise, or whole yen for currencies without minor units.
+type Amount struct {
+	Minor    int64  `json:"amount_minor"`
+	Currency string `json:"currency"`
+}
+
+func NewAmount(minor int64, currency string) Amount {
+	return Amount{Minor: minor, Currency: normalizeCode(currency)}
+}
+
+// AmountFromMajor rounds a major unit amount, such as 12.99, to the minor
+// units of currency. Unknown currencies are assumed to have cents.
+func AmountFromMajor(major float64, currency string) Amount {
+	return NewAmount(int64(math.Round(major*math.Pow10(exponent(currency)))), currency)
+}
+
+// Major returns the amount in major units. It is for display and for the
+// legacy float API fields only; arithmetic should stay in minor units.
+func (a Amount) Major() float64 {
+	return float64(a.Minor) / math.Pow10(exponent(a.Currency))
+}
+
+// Money returns the amount in the display shape used by API responses.
+func (a Amount) Money() Money {
+	return Money{AmountMinor: int(a.Minor), AmountMajor: a.Major(), Currency: a.Currency}
+}
+
+// Amounts is a total kept per currency, ordered by currency code, so sums
+// never mix currencies.
+type Amounts []Amount
+
+// Add returns the totals with amount added to its currency.
+func (t Amounts) Add(amount Amount) Amounts {
+	amount.Currency = normalizeCode(amount.Currency)
+	for i := range t {
+		if t[i].Currency == amount.Currency {
+			t[i].Minor += amount.Minor
+			return t
+		}
+	}
+	t = append(t, amount)
+	sort.Slice(t, func(i, j int) bool { return t[i].Currency < t[j].Currency })
+	return t
+}
+
+// MarshalJSON writes no totals as an empty list rather than null.
+func (t Amounts) MarshalJSON() ([]byte, error) {
+	if t == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal([]Amount(t))
+}
+
+// Get returns the total in currency, zero if there is none.
+func (t Amounts) Get(currency string) Amount {
+	currency = normalizeCode(currency)
+	for _, amount := range t {
+		if amount.Currency == currency {
+			return amount
+		}
+	}
+	return Amount{Currency: currency}
+}
+
+// LegacyMajor converts each total to major units and adds them up regardless
+// of currency, which is what the deprecated float revenue fields have always
+// reported.
+func (t Amounts) LegacyMajor() float64 {
+	var major float64
+	for _, amount := range t {
+		major += amount.Major()
+	}
+	return major
+}
+
+func exponent(code string) int {
+	if currency, ok := LookupCurrency(code); ok {
+		return currency.Exponent
+	}
+	return 2
+}
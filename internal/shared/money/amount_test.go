@@ -0,0 +1,48 @@
+package money
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAmount(t *testing.T) {
+	assert.Equal(t, Amount{Minor: 1299, Currency: CurrencyUSD}, AmountFromMajor(12.99, "usd"))
+	assert.Equal(t, Amount{Minor: 1235, Currency: CurrencyJPY}, AmountFromMajor(1234.6, CurrencyJPY))
+	// 0.1 + 0.2 is not 0.3 in float64; minor units must still come out exact.
+	assert.Equal(t, int64(30), AmountFromMajor(0.1+0.2, CurrencyEUR).Minor)
+	assert.Equal(t, Amount{Minor: 500, Currency: "XYZ"}, AmountFromMajor(5, "xyz"))
+
+	assert.Equal(t, 12.99, NewAmount(1299, CurrencyUSD).Major())
+	assert.Equal(t, 1500.0, NewAmount(1500, CurrencyJPY).Major())
+	assert.Equal(t, Money{AmountMinor: 83000, AmountMajor: 830, Currency: CurrencyINR}, NewAmount(83000, CurrencyINR).Money())
+
+	data, err := json.Marshal(NewAmount(1299, CurrencyUSD))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"amount_minor":1299,"currency":"USD"}`, string(data))
+}
+
+func TestAmounts(t *testing.T) {
+	var totals Amounts
+	totals = totals.Add(NewAmount(1000, CurrencyUSD))
+	totals = totals.Add(NewAmount(50000, "inr"))
+	totals = totals.Add(NewAmount(250, CurrencyUSD))
+
+	assert.Equal(t, Amounts{{Minor: 50000, Currency: CurrencyINR}, {Minor: 1250, Currency: CurrencyUSD}}, totals)
+	assert.Equal(t, int64(1250), totals.Get("usd").Minor)
+	assert.Equal(t, Amount{Currency: CurrencyEUR}, totals.Get(CurrencyEUR))
+	assert.Equal(t, 512.5, totals.LegacyMajor())
+	assert.Equal(t, 0.0, Amounts(nil).LegacyMajor())
+	assert.Equal(t, 1012.5, Amounts{NewAmount(1000, CurrencyJPY), NewAmount(1250, CurrencyUSD)}.LegacyMajor())
+}
+
+func TestAmountsJSON(t *testing.T) {
+	data, err := json.Marshal(struct {
+		Empty Amounts `json:"empty"`
+		Some  Amounts `json:"some"`
+	}{Some: Amounts{NewAmount(150, CurrencyJPY)}})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"empty":[],"some":[{"amount_minor":150,"currency":"JPY"}]}`, string(data))
+}
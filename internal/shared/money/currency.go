@@ -0,0 +1,140 @@
+package money
+
+import (
+	"math"
+	"sort"
+	"strings"
+)
+
+const (
+	CurrencyINR = "INR"
+	CurrencyUSD = "USD"
+	CurrencyEUR = "EUR"
+	CurrencyGBP = "GBP"
+	CurrencyCAD = "CAD"
+	CurrencyAUD = "AUD"
+	CurrencyJPY = "JPY"
+)
+
+// Currency describes how amounts in a currency are stored and priced.
+type Currency struct {
+	Code string `json:"code"`
+	// Exponent is the number of minor unit digits: 2 for cents, 0 for yen.
+	Exponent int `json:"exponent"`
+	// rounding turns a converted amount into a marketplace price.
+	rounding func(major float64) float64
+}
+
+var currencies = map[string]Currency{
+	CurrencyINR: {Code: CurrencyINR, Exponent: 2, rounding: roundWhole},
+	CurrencyUSD: {Code: CurrencyUSD, Exponent: 2, rounding: roundCharm},
+	CurrencyEUR: {Code: CurrencyEUR, Exponent: 2, rounding: roundCharm},
+	CurrencyGBP: {Code: CurrencyGBP, Exponent: 2, rounding: roundCharm},
+	CurrencyCAD: {Code: CurrencyCAD, Exponent: 2, rounding: roundCharm},
+	CurrencyAUD: {Code: CurrencyAUD, Exponent: 2, rounding: roundCharm},
+	CurrencyJPY: {Code: CurrencyJPY, Exponent: 0, rounding: roundTens},
+}
+
+// chargeCurrencies are the currencies payment providers settle orders in.
+// Buyers shown prices in any other currency are charged in USD.
+var chargeCurrencies = []string{CurrencyINR, CurrencyUSD}
+
+// currencyByCountry maps ISO 3166 country codes to the currency prices are
+// shown in. Other countries see USD.
+var currencyByCountry = map[string]string{
+	"IN": CurrencyINR,
+	"GB": CurrencyGBP,
+	"CA": CurrencyCAD,
+	"AU": CurrencyAUD,
+	"JP": CurrencyJPY,
+	"AT": CurrencyEUR, "BE": CurrencyEUR, "CY": CurrencyEUR, "DE": CurrencyEUR,
+	"EE": CurrencyEUR, "ES": CurrencyEUR, "FI": CurrencyEUR, "FR": CurrencyEUR,
+	"GR": CurrencyEUR, "HR": CurrencyEUR, "IE": CurrencyEUR, "IT": CurrencyEUR,
+	"LT": CurrencyEUR, "LU": CurrencyEUR, "LV": CurrencyEUR, "MT": CurrencyEUR,
+	"NL": CurrencyEUR, "PT": CurrencyEUR, "SI": CurrencyEUR, "SK": CurrencyEUR,
+}
+
+// LookupCurrency returns the registered currency for an ISO 4217 code.
+func LookupCurrency(code string) (Currency, bool) {
+	currency, ok := currencies[normalizeCode(code)]
+	return currency, ok
+}
+
+// IsSupported reports whether prices can be stored and shown in the currency.
+func IsSupported(code string) bool {
+	_, ok := LookupCurrency(code)
+	return ok
+}
+
+// IsChargeable reports whether orders can be charged in the currency.
+func IsChargeable(code string) bool {
+	code = normalizeCode(code)
+	for _, charge := range chargeCurrencies {
+		if code == charge {
+			return true
+		}
+	}
+	return false
+}
+
+// SupportedCurrencies returns the registered currencies ordered by code.
+func SupportedCurrencies() []Currency {
+	list := make([]Currency, 0, len(currencies))
+	for _, currency := range currencies {
+		list = append(list, currency)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Code < list[j].Code })
+	return list
+}
+
+// CurrencyForCountry returns the currency shown to visitors from a country.
+func CurrencyForCountry(country string) string {
+	if currency, ok := currencyByCountry[normalizeCode(country)]; ok {
+		return currency
+	}
+	return CurrencyUSD
+}
+
+// FromMajor returns amount, rounded to the currency's minor unit.
+func (c Currency) FromMajor(amount float64) Money {
+	scale := math.Pow10(c.Exponent)
+	minor := int(math.Round(amount * scale))
+	return Money{AmountMinor: minor, AmountMajor: float64(minor) / scale, Currency: c.Code}
+}
+
+// Round turns a converted amount into the price buyers in the currency
+// expect to see.
+func (c Currency) Round(major float64) float64 {
+	return c.rounding(major)
+}
+
+func normalizeCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// roundCharm prices at .99 below the next whole unit.
+func roundCharm(value float64) float64 {
+	if value <= 0 {
+		return 0
+	}
+	if value < 1 {
+		return 0.99
+	}
+	whole := math.Floor(value)
+	return whole + 0.99
+}
+
+func roundWhole(value float64) float64 {
+	if value <= 0 {
+		return 0
+	}
+	return math.Round(value)
+}
+
+// roundTens suits currencies without minor units and small unit values.
+func roundTens(value float64) float64 {
+	if value <= 0 {
+		return 0
+	}
+	return math.Max(10, math.Round(value/10)*10)
+}
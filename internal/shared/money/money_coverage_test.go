@@ -22,16 +22,16 @@ func TestMoneyConversionBranches(t *testing.T) {
 
 	getenv = func(string) string { return "0.01" }
 	assert.Equal(t, CurrencyUSD, DisplayFromINRMajor(10, "usd").Currency)
-	assert.Equal(t, CurrencyINR, DisplayPrice(10, "", CurrencyINR).Currency)
-	assert.Equal(t, CurrencyUSD, DisplayPrice(10, CurrencyUSD, CurrencyUSD).Currency)
-	assert.Equal(t, CurrencyUSD, DisplayPrice(100, CurrencyINR, CurrencyUSD).Currency)
-	assert.Equal(t, CurrencyINR, DisplayPrice(1, CurrencyUSD, CurrencyINR).Currency)
-
-	assert.Equal(t, 0.0, roundMarketplaceUSD(0))
-	assert.Equal(t, 0.99, roundMarketplaceUSD(0.5))
-	assert.Equal(t, 3.99, roundMarketplaceUSD(3.2))
-	assert.Equal(t, 0.0, roundMarketplaceINR(-1))
-	assert.Equal(t, 3.0, roundMarketplaceINR(3.2))
+	assert.Equal(t, CurrencyINR, DisplayPrice(NewAmount(1000, ""), CurrencyINR).Currency)
+	assert.Equal(t, CurrencyUSD, DisplayPrice(NewAmount(1000, CurrencyUSD), CurrencyUSD).Currency)
+	assert.Equal(t, CurrencyUSD, DisplayPrice(NewAmount(10000, CurrencyINR), CurrencyUSD).Currency)
+	assert.Equal(t, CurrencyINR, DisplayPrice(NewAmount(100, CurrencyUSD), CurrencyINR).Currency)
+
+	assert.Equal(t, 0.0, roundCharm(0))
+	assert.Equal(t, 0.99, roundCharm(0.5))
+	assert.Equal(t, 3.99, roundCharm(3.2))
+	assert.Equal(t, 0.0, roundWhole(-1))
+	assert.Equal(t, 3.0, roundWhole(3.2))
 
 	assert.Equal(t, 0.01, usdPerINR())
 	getenv = func(string) string { return "" }
@@ -0,0 +1,181 @@
+package money
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrUnsupportedCurrency = errors.New("unsupported currency")
+	ErrNoRate              = errors.New("no exchange rate for currency")
+	ErrInvalidRates        = errors.New("invalid exchange rates")
+)
+
+// Rates is one set of exchange rates, as units of each currency per unit of
+// Base.
+type Rates struct {
+	// SnapshotID identifies the stored snapshot the rates were loaded from.
+	// It is nil for rates that were never stored, such as the INR_USD_RATE
+	// fallback.
+	SnapshotID *uuid.UUID         `json:"snapshot_id,omitempty"`
+	Base       string             `json:"base"`
+	Rates      map[string]float64 `json:"rates"`
+	AsOf       time.Time          `json:"as_of"`
+}
+
+// RateProvider fetches the latest exchange rates from a source.
+type RateProvider interface {
+	FetchRates(ctx context.Context) (*Rates, error)
+}
+
+// Validate checks that every currency is supported and every rate positive.
+func (r *Rates) Validate() error {
+	if !IsSupported(r.Base) {
+		return fmt.Errorf("%w: base %q", ErrInvalidRates, r.Base)
+	}
+	for code, rate := range r.Rates {
+		if !IsSupported(code) {
+			return fmt.Errorf("%w: %w %q", ErrInvalidRates, ErrUnsupportedCurrency, code)
+		}
+		if rate <= 0 {
+			return fmt.Errorf("%w: %s rate %v", ErrInvalidRates, code, rate)
+		}
+	}
+	return nil
+}
+
+func (r *Rates) rate(code string) (float64, bool) {
+	if code == r.Base {
+		return 1, true
+	}
+	rate, ok := r.Rates[code]
+	return rate, ok && rate > 0
+}
+
+// Convert converts amount between currencies without rounding.
+func (r *Rates) Convert(amount float64, from, to string) (float64, error) {
+	from, to = normalizeCode(from), normalizeCode(to)
+	if from == to {
+		return amount, nil
+	}
+	fromRate, ok := r.rate(from)
+	if !ok {
+		return 0, fmt.Errorf("%w %s", ErrNoRate, from)
+	}
+	toRate, ok := r.rate(to)
+	if !ok {
+		return 0, fmt.Errorf("%w %s", ErrNoRate, to)
+	}
+	return amount / fromRate * toRate, nil
+}
+
+// CanCharge reports whether prices stored in code can be converted into every
+// currency orders are charged in.
+func (r *Rates) CanCharge(code string) bool {
+	for _, charge := range chargeCurrencies {
+		if _, err := r.Convert(1, code, charge); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// Price converts a stored price to target and rounds it to a marketplace
+// price there. Prices already in target are returned unchanged.
+func (r *Rates) Price(amount Amount, target string) (Money, error) {
+	from, ok := LookupCurrency(amount.Currency)
+	if !ok {
+		return Money{}, fmt.Errorf("%w %q", ErrUnsupportedCurrency, amount.Currency)
+	}
+	to, ok := LookupCurrency(target)
+	if !ok {
+		return Money{}, fmt.Errorf("%w %q", ErrUnsupportedCurrency, target)
+	}
+	if from.Code == to.Code {
+		return NewAmount(amount.Minor, to.Code).Money(), nil
+	}
+	converted, err := r.Convert(amount.Major(), from.Code, to.Code)
+	if err != nil {
+		return Money{}, err
+	}
+	return to.FromMajor(to.Round(converted)), nil
+}
+
+// Display prices a stored amount for display. Without a rate to the display
+// currency the price is shown in its stored currency.
+func (r *Rates) Display(amount Amount, display string) Money {
+	// Legacy records have no stored currency and were priced in INR.
+	if !IsSupported(amount.Currency) {
+		amount = NewAmount(amount.Minor, CurrencyINR)
+	}
+	price, err := r.Price(amount, display)
+	if err != nil {
+		return NewAmount(amount.Minor, amount.Currency).Money()
+	}
+	return price
+}
+
+var currentRates atomic.Pointer[Rates]
+
+// SetRates makes rates the ones prices are converted with.
+func SetRates(rates *Rates) {
+	currentRates.Store(rates)
+}
+
+// CurrentRates returns the rates prices are converted with: the last ones
+// set, or the INR_USD_RATE fallback until rates were loaded.
+func CurrentRates() *Rates {
+	if rates := currentRates.Load(); rates != nil {
+		return rates
+	}
+	return &Rates{Base: CurrencyINR, Rates: map[string]float64{CurrencyUSD: usdPerINR()}}
+}
+
+// FileRateProvider reads rates from a JSON file kept current outside the
+// app, for example by a cron job:
+//
+//	{"base": "USD", "as_of": "2026-10-01T00:00:00Z", "rates": {"INR": 83.2, "EUR": 0.92}}
+type FileRateProvider struct {
+	path string
+}
+
+func NewFileRateProvider(path string) *FileRateProvider {
+	return &FileRateProvider{path: path}
+}
+
+func (p *FileRateProvider) FetchRates(context.Context) (*Rates, error) {
+	data, err := os.ReadFile(p.path)
+	if err != nil {
+		return nil, err
+	}
+	rates := &Rates{}
+	if err := json.Unmarshal(data, rates); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRates, err)
+	}
+	rates.SnapshotID = nil
+	rates.Base = normalizeCode(rates.Base)
+	normalized := make(map[string]float64, len(rates.Rates))
+	for code, rate := range rates.Rates {
+		normalized[normalizeCode(code)] = rate
+	}
+	delete(normalized, rates.Base)
+	rates.Rates = normalized
+	if err := rates.Validate(); err != nil {
+		return nil, err
+	}
+	if rates.AsOf.IsZero() {
+		info, err := os.Stat(p.path)
+		if err != nil {
+			return nil, err
+		}
+		rates.AsOf = info.ModTime()
+	}
+	return rates, nil
+}
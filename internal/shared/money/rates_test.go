@@ -0,0 +1,142 @@
+package money
+
+import (
+	"context"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCurrencyRegistry(t *testing.T) {
+	usd, ok := LookupCurrency(" usd ")
+	require.True(t, ok)
+	assert.Equal(t, 2, usd.Exponent)
+	jpy, ok := LookupCurrency(CurrencyJPY)
+	require.True(t, ok)
+	assert.Equal(t, Money{AmountMinor: 1235, AmountMajor: 1235, Currency: CurrencyJPY}, jpy.FromMajor(1234.6))
+	assert.False(t, IsSupported("XYZ"))
+
+	codes := []string{}
+	for _, currency := range SupportedCurrencies() {
+		codes = append(codes, currency.Code)
+	}
+	assert.Equal(t, []string{"AUD", "CAD", "EUR", "GBP", "INR", "JPY", "USD"}, codes)
+
+	for country, currency := range map[string]string{"IN": "INR", "de": "EUR", "GB": "GBP", "CA": "CAD", "AU": "AUD", "JP": "JPY", "BR": "USD", "": "USD"} {
+		assert.Equal(t, currency, CurrencyForCountry(country), country)
+	}
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("CF-IPCountry", "FR")
+	assert.Equal(t, CurrencyEUR, ResolveCurrencyFromRequest(req))
+}
+
+func TestCurrencyRounding(t *testing.T) {
+	for _, tc := range []struct {
+		currency string
+		in, want float64
+	}{
+		{CurrencyEUR, 12.3, 12.99},
+		{CurrencyGBP, 0.4, 0.99},
+		{CurrencyINR, 830.4, 830},
+		{CurrencyJPY, 1234, 1230},
+		{CurrencyJPY, 3, 10},
+		{CurrencyJPY, 0, 0},
+	} {
+		currency, _ := LookupCurrency(tc.currency)
+		assert.Equal(t, tc.want, currency.Round(tc.in), "%s %v", tc.currency, tc.in)
+	}
+}
+
+func TestRatesPrice(t *testing.T) {
+	rates := &Rates{Base: CurrencyUSD, Rates: map[string]float64{CurrencyINR: 80, CurrencyEUR: 0.9, CurrencyJPY: 150}}
+
+	converted, err := rates.Convert(800, CurrencyINR, CurrencyEUR)
+	require.NoError(t, err)
+	assert.InDelta(t, 9.0, converted, 1e-9)
+
+	price, err := rates.Price(NewAmount(80000, CurrencyINR), CurrencyEUR)
+	require.NoError(t, err)
+	assert.Equal(t, Money{AmountMinor: 999, AmountMajor: 9.99, Currency: CurrencyEUR}, price)
+
+	price, err = rates.Price(NewAmount(1000, CurrencyUSD), CurrencyJPY)
+	require.NoError(t, err)
+	assert.Equal(t, Money{AmountMinor: 1500, AmountMajor: 1500, Currency: CurrencyJPY}, price)
+
+	// Prices in their own currency are not re-rounded.
+	price, err = rates.Price(NewAmount(1250, CurrencyEUR), "eur")
+	require.NoError(t, err)
+	assert.Equal(t, 1250, price.AmountMinor)
+
+	_, err = rates.Price(NewAmount(1000, CurrencyUSD), CurrencyGBP)
+	assert.ErrorIs(t, err, ErrNoRate)
+	_, err = rates.Price(NewAmount(1000, "XYZ"), CurrencyUSD)
+	assert.ErrorIs(t, err, ErrUnsupportedCurrency)
+
+	// Without a rate the stored price is shown; legacy records are INR.
+	assert.Equal(t, Money{AmountMinor: 1000, AmountMajor: 10, Currency: CurrencyUSD}, rates.Display(NewAmount(1000, CurrencyUSD), CurrencyGBP))
+	assert.Equal(t, CurrencyEUR, rates.Display(NewAmount(80000, ""), CurrencyEUR).Currency)
+}
+
+func TestRatesCanCharge(t *testing.T) {
+	assert.True(t, IsChargeable(" inr "))
+	assert.True(t, IsChargeable(CurrencyUSD))
+	assert.False(t, IsChargeable(CurrencyEUR))
+
+	fallback := &Rates{Base: CurrencyINR, Rates: map[string]float64{CurrencyUSD: 0.012}}
+	assert.True(t, fallback.CanCharge(CurrencyINR))
+	assert.True(t, fallback.CanCharge(CurrencyUSD))
+	assert.False(t, fallback.CanCharge(CurrencyEUR))
+
+	loaded := &Rates{Base: CurrencyUSD, Rates: map[string]float64{CurrencyINR: 80, CurrencyEUR: 0.9}}
+	assert.True(t, loaded.CanCharge(CurrencyEUR))
+	assert.False(t, loaded.CanCharge(CurrencyGBP))
+}
+
+func TestCurrentRates(t *testing.T) {
+	oldGetenv := getenv
+	defer func() { getenv = oldGetenv }()
+	defer SetRates(nil)
+	getenv = func(string) string { return "0.0125" }
+
+	fallback := CurrentRates()
+	assert.Nil(t, fallback.SnapshotID)
+	assert.Equal(t, Money{AmountMinor: 1299, AmountMajor: 12.99, Currency: CurrencyUSD}, DisplayPrice(NewAmount(100000, CurrencyINR), CurrencyUSD))
+
+	id := uuid.New()
+	SetRates(&Rates{SnapshotID: &id, Base: CurrencyUSD, Rates: map[string]float64{CurrencyINR: 50, CurrencyGBP: 0.5}})
+	assert.Equal(t, &id, CurrentRates().SnapshotID)
+	assert.Equal(t, Money{AmountMinor: 1099, AmountMajor: 10.99, Currency: CurrencyGBP}, DisplayPrice(NewAmount(100000, CurrencyINR), CurrencyGBP))
+}
+
+func TestFileRateProvider(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "rates.json")
+	ctx := context.Background()
+
+	_, err := NewFileRateProvider(path).FetchRates(ctx)
+	assert.Error(t, err)
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"base":"usd","as_of":"2026-10-01T00:00:00Z","rates":{"inr":83.2,"EUR":0.92,"USD":1}}`), 0o600))
+	rates, err := NewFileRateProvider(path).FetchRates(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, CurrencyUSD, rates.Base)
+	assert.Equal(t, map[string]float64{CurrencyINR: 83.2, CurrencyEUR: 0.92}, rates.Rates)
+	assert.Equal(t, 2026, rates.AsOf.Year())
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"base":"USD","rates":{"XYZ":2}}`), 0o600))
+	_, err = NewFileRateProvider(path).FetchRates(ctx)
+	assert.ErrorIs(t, err, ErrInvalidRates)
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"base":"USD","rates":{"EUR":0}}`), 0o600))
+	_, err = NewFileRateProvider(path).FetchRates(ctx)
+	assert.ErrorIs(t, err, ErrInvalidRates)
+
+	require.NoError(t, os.WriteFile(path, []byte(`{`), 0o600))
+	_, err = NewFileRateProvider(path).FetchRates(ctx)
+	assert.ErrorIs(t, err, ErrInvalidRates)
+}
@@ -0,0 +1,264 @@
+// Package pdf writes simple text documents as PDF using the standard
+// Helvetica fonts, so no font files need to be embedded. Text is encoded as
+// WinAnsi; characters outside it are written as '?'.
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// A4 page size in points.
+const (
+	PageWidth  = 595.28
+	PageHeight = 841.89
+)
+
+type Font int
+
+const (
+	Regular Font = iota
+	Bold
+)
+
+var fontNames = [...]string{Regular: "Helvetica", Bold: "Helvetica-Bold"}
+
+// Document is a PDF under construction. Coordinates are in points with the
+// origin at the top-left corner of the page.
+type Document struct {
+	title string
+	pages []*Page
+}
+
+type Page struct {
+	content bytes.Buffer
+}
+
+func New(title string) *Document {
+	return &Document{title: title}
+}
+
+func (d *Document) AddPage() *Page {
+	page := &Page{}
+	d.pages = append(d.pages, page)
+	return page
+}
+
+// Text draws text with its baseline starting at x, y.
+func (p *Page) Text(x, y float64, font Font, size float64, text string) {
+	fmt.Fprintf(&p.content, "BT /F%d %s Tf %s %s Td (%s) Tj ET\n",
+		font+1, num(size), num(x), num(PageHeight-y), escape(text))
+}
+
+// TextRight draws text so that it ends at x.
+func (p *Page) TextRight(x, y float64, font Font, size float64, text string) {
+	p.Text(x-TextWidth(font, size, text), y, font, size, text)
+}
+
+// Line draws a 0.5pt line from x1, y1 to x2, y2.
+func (p *Page) Line(x1, y1, x2, y2 float64) {
+	fmt.Fprintf(&p.content, "0.5 w %s %s m %s %s l S\n",
+		num(x1), num(PageHeight-y1), num(x2), num(PageHeight-y2))
+}
+
+// Rect fills a w by h rectangle whose top-left corner is at x, y.
+func (p *Page) Rect(x, y, w, h float64) {
+	fmt.Fprintf(&p.content, "%s %s %s %s re f\n",
+		num(x), num(PageHeight-y-h), num(w), num(h))
+}
+
+// TextWidth returns the width of text set in font at size, in points.
+func TextWidth(font Font, size float64, text string) float64 {
+	widths := &helveticaWidths
+	if font == Bold {
+		widths = &helveticaBoldWidths
+	}
+	units := 0
+	for _, b := range winAnsi(text) {
+		if b >= 32 && b <= 126 {
+			units += widths[b-32]
+		} else {
+			units += 556
+		}
+	}
+	return float64(units) * size / 1000
+}
+
+// Truncate shortens text with a trailing "..." until it fits in width.
+func Truncate(font Font, size, width float64, text string) string {
+	if TextWidth(font, size, text) <= width {
+		return text
+	}
+	runes := []rune(text)
+	for len(runes) > 0 {
+		runes = runes[:len(runes)-1]
+		candidate := strings.TrimRight(string(runes), " ") + "..."
+		if TextWidth(font, size, candidate) <= width {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// Wrap breaks text into lines no wider than width, splitting at spaces. A
+// word wider than width gets a line of its own.
+func Wrap(font Font, size, width float64, text string) []string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(text) {
+		candidate := word
+		if line != "" {
+			candidate = line + " " + word
+		}
+		if line != "" && TextWidth(font, size, candidate) > width {
+			lines = append(lines, line)
+			candidate = word
+		}
+		line = candidate
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// WriteTo writes the document. A document without pages gets one blank page.
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	if len(d.pages) == 0 {
+		d.AddPage()
+	}
+
+	// Objects 1-4 are the catalog, the page tree, the two fonts and the
+	// document info; each page then takes two objects, itself and its content.
+	const (
+		catalogObj = 1
+		pagesObj   = 2
+		fontObj    = 3
+		infoObj    = 5
+		firstPage  = 6
+	)
+	var buf bytes.Buffer
+	var offsets []int
+	startObj := func(n int) {
+		for len(offsets) < n {
+			offsets = append(offsets, 0)
+		}
+		offsets[n-1] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n", n)
+	}
+
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	startObj(catalogObj)
+	fmt.Fprintf(&buf, "<< /Type /Catalog /Pages %d 0 R >>\nendobj\n", pagesObj)
+
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPage+2*i)
+	}
+	startObj(pagesObj)
+	fmt.Fprintf(&buf, "<< /Type /Pages /Kids [%s] /Count %d >>\nendobj\n", strings.Join(kids, " "), len(d.pages))
+
+	for i, name := range fontNames {
+		startObj(fontObj + i)
+		fmt.Fprintf(&buf, "<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>\nendobj\n", name)
+	}
+
+	startObj(infoObj)
+	fmt.Fprintf(&buf, "<< /Title (%s) /Producer (Blueprint Audio) >>\nendobj\n", escape(d.title))
+
+	for i, page := range d.pages {
+		pageObj := firstPage + 2*i
+		startObj(pageObj)
+		fmt.Fprintf(&buf, "<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %s %s] /Resources << /Font << /F1 %d 0 R /F2 %d 0 R >> >> /Contents %d 0 R >>\nendobj\n",
+			pagesObj, num(PageWidth), num(PageHeight), fontObj, fontObj+1, pageObj+1)
+
+		startObj(pageObj + 1)
+		fmt.Fprintf(&buf, "<< /Length %d >>\nstream\n", page.content.Len())
+		buf.Write(page.content.Bytes())
+		buf.WriteString("endstream\nendobj\n")
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root %d 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n",
+		len(offsets)+1, catalogObj, infoObj, xref)
+
+	return buf.WriteTo(w)
+}
+
+func num(value float64) string {
+	s := fmt.Sprintf("%.2f", value)
+	s = strings.TrimRight(s, "0")
+	return strings.TrimSuffix(s, ".")
+}
+
+// winAnsi encodes text as WinAnsi. Latin-1 maps onto it directly; the few
+// typographic characters people paste into titles are mapped explicitly.
+func winAnsi(text string) []byte {
+	out := make([]byte, 0, len(text))
+	for _, r := range text {
+		switch {
+		case r == '\n' || r == '\r' || r == '\t':
+			out = append(out, ' ')
+		case r >= 32 && r <= 126, r >= 160 && r <= 255:
+			out = append(out, byte(r))
+		case r == '‘':
+			out = append(out, 0x91)
+		case r == '’':
+			out = append(out, 0x92)
+		case r == '“':
+			out = append(out, 0x93)
+		case r == '”':
+			out = append(out, 0x94)
+		case r == '–':
+			out = append(out, 0x96)
+		case r == '—':
+			out = append(out, 0x97)
+		case r == '€':
+			out = append(out, 0x80)
+		default:
+			out = append(out, '?')
+		}
+	}
+	return out
+}
+
+func escape(text string) string {
+	var b strings.Builder
+	for _, c := range winAnsi(text) {
+		switch c {
+		case '(', ')', '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// Glyph widths of printable ASCII (32-126) in 1/1000 em, from the Adobe
+// font metrics of the standard fonts.
+var helveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+var helveticaBoldWidths = [95]int{
+	278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+	975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+	333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+	611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+}
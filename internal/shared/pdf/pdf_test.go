@@ -0,0 +1,79 @@
+package pdf
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDocumentWriteTo(t *testing.T) {
+	doc := New("Statement (May)")
+	first := doc.AddPage()
+	first.Text(40, 60, Bold, 18, "Earnings statement")
+	first.Line(40, 70, 555, 70)
+	first.TextRight(555, 90, Regular, 10, "INR 1,250.00")
+	first.Rect(40, 100, 20, 10.5)
+	doc.AddPage().Text(40, 60, Regular, 10, `Night Drive (Remix) \ “Deluxe” ₹`)
+
+	var buf bytes.Buffer
+	_, err := doc.WriteTo(&buf)
+	require.NoError(t, err)
+	out := buf.Bytes()
+
+	assert.True(t, bytes.HasPrefix(out, []byte("%PDF-1.4\n")))
+	assert.True(t, bytes.HasSuffix(out, []byte("%%EOF\n")))
+	assert.Contains(t, string(out), "/Count 2")
+	assert.Contains(t, string(out), "/Title (Statement \\(May\\))")
+	assert.Contains(t, string(out), "(Earnings statement) Tj")
+	assert.Contains(t, string(out), "40 731.39 20 10.5 re f\n")
+	assert.Contains(t, string(out), "(Night Drive \\(Remix\\) \\\\ \x93Deluxe\x94 ?) Tj")
+
+	// Every xref entry must point at the object it names.
+	startxref := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(out)
+	require.NotNil(t, startxref)
+	xrefAt, err := strconv.Atoi(string(startxref[1]))
+	require.NoError(t, err)
+	require.True(t, bytes.HasPrefix(out[xrefAt:], []byte("xref\n")))
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(out[xrefAt:], -1)
+	require.Len(t, entries, 9)
+	for i, entry := range entries {
+		offset, err := strconv.Atoi(string(entry[1]))
+		require.NoError(t, err)
+		assert.True(t, bytes.HasPrefix(out[offset:], []byte(strconv.Itoa(i+1)+" 0 obj\n")), "object %d", i+1)
+	}
+}
+
+func TestDocumentWithoutPagesHasOneBlankPage(t *testing.T) {
+	var buf bytes.Buffer
+	_, err := New("Empty").WriteTo(&buf)
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), "/Count 1")
+}
+
+func TestTextWidthAndTruncate(t *testing.T) {
+	assert.InDelta(t, 5.56, TextWidth(Regular, 10, "0"), 0.001)
+	assert.Greater(t, TextWidth(Bold, 10, "Wi"), TextWidth(Regular, 10, "Wi"))
+
+	assert.Equal(t, "Short", Truncate(Regular, 10, 100, "Short"))
+	truncated := Truncate(Regular, 10, 60, "A much longer spec title")
+	assert.Regexp(t, `\.\.\.$`, truncated)
+	assert.LessOrEqual(t, TextWidth(Regular, 10, truncated), 60.0)
+	assert.Empty(t, Truncate(Regular, 10, 1, "Anything"))
+}
+
+func TestWrap(t *testing.T) {
+	lines := Wrap(Regular, 10, 80, "The licensee may distribute up to two thousand copies")
+	require.Greater(t, len(lines), 1)
+	for _, line := range lines {
+		assert.LessOrEqual(t, TextWidth(Regular, 10, line), 80.0)
+	}
+	assert.Equal(t, "The licensee may distribute up to two thousand copies", strings.Join(lines, " "))
+
+	assert.Equal(t, []string{"Supercalifragilistic", "ok"}, Wrap(Regular, 10, 20, "Supercalifragilistic ok"))
+	assert.Empty(t, Wrap(Regular, 10, 80, "   "))
+}
@@ -0,0 +1,152 @@
+package qr
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReedSolomonRemainder(t *testing.T) {
+	// "HELLO WORLD" as version 1-M alphanumeric data, from the worked example
+	// of the specification.
+	data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	ecc := reedSolomonRemainder(data, reedSolomonDivisor(10))
+	assert.Equal(t, []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}, ecc)
+}
+
+func TestFormatAndVersionBits(t *testing.T) {
+	for mask, want := range []int{
+		0b101010000010010, 0b101000100100101, 0b101111001111100, 0b101101101001011,
+		0b100010111111001, 0b100000011001110, 0b100111110010111, 0b100101010100000,
+	} {
+		assert.Equal(t, want, formatBits(mask), "mask %d", mask)
+	}
+	assert.Equal(t, 0x07C94, versionBits(7))
+	assert.Equal(t, 0x085BC, versionBits(8))
+	assert.Equal(t, 0x0A4D3, versionBits(10))
+}
+
+func TestEncodeReadsBack(t *testing.T) {
+	for _, text := range []string{
+		"LIC-1",
+		"https://api.example.com/verify/licenses/LIC-0190f5b2-6c1d-7a3e-9f00-1234567890ab",
+		strings.Repeat("x", 150),
+		strings.Repeat("y", 213),
+	} {
+		code, err := Encode(text)
+		require.NoError(t, err)
+		version := (code.Size - 17) / 4
+
+		// Finder pattern corners and the always-dark module.
+		for _, corner := range [][2]int{{0, 0}, {code.Size - 7, 0}, {0, code.Size - 7}} {
+			assert.True(t, code.Dark(corner[0], corner[1]))
+			assert.True(t, code.Dark(corner[0]+3, corner[1]+3))
+			assert.False(t, code.Dark(corner[0]+1, corner[1]+1))
+		}
+		assert.True(t, code.Dark(8, code.Size-8))
+
+		assert.Equal(t, text, readBack(t, code, version), "version %d", version)
+	}
+}
+
+func TestEncodeTooLong(t *testing.T) {
+	_, err := Encode(strings.Repeat("z", 214))
+	assert.ErrorIs(t, err, ErrTooLong)
+}
+
+// readBack decodes the symbol: it reads the mask from the format bits, undoes
+// it, checks every block's error correction and returns the byte segment.
+func readBack(t *testing.T, code *Code, version int) string {
+	t.Helper()
+	format := 0
+	for i := 0; i < 8; i++ {
+		if code.Dark(code.Size-1-i, 8) {
+			format |= 1 << i
+		}
+	}
+	for i := 8; i < 15; i++ {
+		if code.Dark(8, code.Size-15+i) {
+			format |= 1 << i
+		}
+	}
+	format ^= 0x5412
+	require.Equal(t, 0, format>>13, "error correction level M")
+	mask := (format >> 10) & 7
+
+	layout := newCode(version)
+	for y := range code.Size {
+		for x := range code.Size {
+			layout.modules[y][x] = code.Dark(x, y)
+		}
+	}
+	layout.applyMask(mask)
+	codewords := make([]byte, rawCodewords[version])
+	i := 0
+	for right := layout.size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		for vert := 0; vert < layout.size; vert++ {
+			for j := 0; j < 2; j++ {
+				x, y := right-j, vert
+				if (right+1)&2 == 0 {
+					y = layout.size - 1 - vert
+				}
+				if !layout.isFunction[y][x] && i < len(codewords)*8 {
+					if layout.modules[y][x] {
+						codewords[i/8] |= 1 << (7 - i%8)
+					}
+					i++
+				}
+			}
+		}
+	}
+
+	blocks, eccLen := numBlocks[version], eccPerBlock[version]
+	dataLen := dataCodewords(version)
+	shortLen, numShort := dataLen/blocks, blocks-dataLen%blocks
+	dataBlocks := make([][]byte, blocks)
+	pos := 0
+	for k := 0; k <= shortLen; k++ {
+		for b := range blocks {
+			if k < shortLen || b >= numShort {
+				dataBlocks[b] = append(dataBlocks[b], codewords[pos])
+				pos++
+			}
+		}
+	}
+	eccBlocks := make([][]byte, blocks)
+	for k := 0; k < eccLen; k++ {
+		for b := range blocks {
+			eccBlocks[b] = append(eccBlocks[b], codewords[pos])
+			pos++
+		}
+	}
+	var data []byte
+	for b := range blocks {
+		require.Equal(t, reedSolomonRemainder(dataBlocks[b], reedSolomonDivisor(eccLen)), eccBlocks[b], "block %d", b)
+		data = append(data, dataBlocks[b]...)
+	}
+
+	require.Equal(t, byte(0b0100), data[0]>>4, "byte mode")
+	bitAt := func(n int) int { return int(data[n/8]>>(7-n%8)) & 1 }
+	readBits := func(from, length int) int {
+		value := 0
+		for n := from; n < from+length; n++ {
+			value = value<<1 | bitAt(n)
+		}
+		return value
+	}
+	countBits := 8
+	if version >= 10 {
+		countBits = 16
+	}
+	length := readBits(4, countBits)
+	out := make([]byte, length)
+	for k := range out {
+		out[k] = byte(readBits(4+countBits+8*k, 8))
+	}
+	return string(out)
+}
@@ -40,7 +40,7 @@ var _ filedomain.FileStorage = noopStorage{}
 
 func TestCoverageSmoke_AuthModuleAndRoutes(t *testing.T) {
 	fs := fileapp.NewFileService(noopStorage{})
-	m, err := auth.NewModule(&sqlx.DB{}, "secret", time.Hour, time.Hour*720, fs, "google-client-id", false, sharedemail.NewSender(sharedemail.Config{}), "http://localhost:4200")
+	m, err := auth.NewModule(&sqlx.DB{}, nil, "secret", time.Hour, time.Hour*720, 5*time.Second, fs, "google-client-id", false, sharedemail.NewSender(sharedemail.Config{}), "http://localhost:4200")
 	require.NoError(t, err)
 	require.NotNil(t, m.Service())
 	require.NotNil(t, m.UserFinder())
@@ -16,9 +16,11 @@ var (
 
 type Claims struct {
 	UserID     uuid.UUID `json:"user_id"`
+	SessionID  uuid.UUID `json:"sid"`
 	Email      string    `json:"email"`
 	Role       string    `json:"role"`
 	SystemRole string    `json:"system_role"`
+	MFA        bool      `json:"mfa,omitempty"`
 	jwt.RegisteredClaims
 }
 